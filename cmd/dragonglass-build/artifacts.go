@@ -0,0 +1,82 @@
+// ABOUTME: Mapping of built plugin files to the paths a plugin's build command writes them to
+// ABOUTME: Parses --artifact flags over the main.js, manifest.json, and styles.css defaults
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// DefaultBuildCommand builds a plugin unless --build-command says otherwise
+const DefaultBuildCommand = "npm run build"
+
+// Files the builder writes next to the plugin files itself
+var reservedOutputs = []string{"sbom.spdx.json", BuildMetadataFile}
+
+// artifact is a file of the built plugin and where the build writes it, relative to the
+// plugin directory
+type artifact struct {
+	name string
+	path string
+
+	// The build fails when the file is missing
+	required bool
+}
+
+// parseArtifacts returns the plugin files to export: main.js and manifest.json, which are
+// required, and styles.css, which is optional, at their default paths under buildDir,
+// overridden or extended by mappings of the form name=path. Mapped files are required.
+func parseArtifacts(mappings []string, buildDir string) ([]artifact, error) {
+	artifacts := []artifact{
+		{name: "main.js", path: path.Join(buildDir, "main.js"), required: true},
+		{name: "manifest.json", path: "manifest.json", required: true},
+		{name: "styles.css", path: path.Join(buildDir, "styles.css")},
+	}
+
+	seen := make(map[string]bool)
+	for _, mapping := range mappings {
+		name, src, ok := strings.Cut(mapping, "=")
+		if !ok || name == "" || src == "" {
+			return nil, fmt.Errorf("invalid --artifact %q (expected name=path, e.g. main.js=build/main.js)", mapping)
+		}
+		if strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+			return nil, fmt.Errorf("invalid --artifact %q: the name must be a file name", mapping)
+		}
+		for _, reserved := range reservedOutputs {
+			if name == reserved {
+				return nil, fmt.Errorf("invalid --artifact %q: %s is written by dragonglass-build", mapping, name)
+			}
+		}
+		src = path.Clean(strings.ReplaceAll(src, `\`, "/"))
+		if path.IsAbs(src) || src == ".." || strings.HasPrefix(src, "../") {
+			return nil, fmt.Errorf("invalid --artifact %q: the path must be inside the plugin directory", mapping)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("invalid --artifact %q: %s is mapped more than once", mapping, name)
+		}
+		seen[name] = true
+
+		mapped := artifact{name: name, path: src, required: true}
+		replaced := false
+		for i := range artifacts {
+			if artifacts[i].name == name {
+				artifacts[i], replaced = mapped, true
+			}
+		}
+		if !replaced {
+			artifacts = append(artifacts, mapped)
+		}
+	}
+	return artifacts, nil
+}
+
+// artifactPath returns where the build writes name
+func artifactPath(artifacts []artifact, name string) string {
+	for _, a := range artifacts {
+		if a.name == name {
+			return a.path
+		}
+	}
+	return name
+}
@@ -0,0 +1,184 @@
+// ABOUTME: Pre-install audit of the dependency tree for lifecycle scripts and known-risky packages
+// ABOUTME: Reads package-lock.json so nothing in the tree runs before the report is printed
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Lifecycle scripts npm runs when installing a package
+var installScripts = []string{"preinstall", "install", "postinstall", "prepare"}
+
+// riskyPackages are releases known to have shipped malicious code, keyed by package name
+var riskyPackages = map[string]struct {
+	versions []string
+	reason   string
+}{
+	"event-stream":   {versions: []string{"3.3.6"}, reason: "depends on the compromised flatmap-stream"},
+	"flatmap-stream": {versions: []string{"0.1.1"}, reason: "steals cryptocurrency wallet keys"},
+	"ua-parser-js":   {versions: []string{"0.7.29", "0.8.0", "1.0.0"}, reason: "hijacked release installing a cryptominer and password stealer"},
+	"coa":            {versions: []string{"2.0.3", "2.0.4", "2.1.1", "2.1.3", "3.0.1", "3.1.3"}, reason: "hijacked release installing a password stealer"},
+	"rc":             {versions: []string{"1.2.9", "1.3.9", "2.3.9"}, reason: "hijacked release installing a password stealer"},
+	"node-ipc":       {versions: []string{"10.1.1", "10.1.2"}, reason: "overwrites files on machines in some regions"},
+	"eslint-scope":   {versions: []string{"3.7.2"}, reason: "hijacked release stealing npm tokens"},
+}
+
+// lockPackage is an entry of package-lock.json's "packages"
+type lockPackage struct {
+	Name             string `json:"name"`
+	Version          string `json:"version"`
+	Link             bool   `json:"link"`
+	HasInstallScript bool   `json:"hasInstallScript"`
+}
+
+// auditFinding is a dependency the audit reports
+type auditFinding struct {
+	Package string
+	Version string
+
+	// Why it is reported
+	Reason string
+
+	// The package was allowed with --allow-script, so it does not fail a strict audit
+	Allowed bool
+}
+
+// scriptAudit is the result of auditing a dependency tree
+type scriptAudit struct {
+	// Lifecycle scripts of the plugin's own package.json, which run on every install
+	RootScripts []string
+
+	// Dependencies with install scripts
+	InstallScripts []auditFinding
+
+	// Dependencies at releases known to be malicious
+	Risky []auditFinding
+}
+
+// failed reports whether a strict audit fails: any risky package, or an install script
+// of a dependency that was not allowed
+func (a *scriptAudit) failed() bool {
+	if len(a.Risky) > 0 {
+		return true
+	}
+	for _, finding := range a.InstallScripts {
+		if !finding.Allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// lockfilePackages returns the packages in a package-lock.json, keyed by install path. ok
+// is false for lockfiles from before npm 7, which do not record install scripts.
+func lockfilePackages(lockData []byte) (packages map[string]lockPackage, ok bool, err error) {
+	var lock struct {
+		LockfileVersion int                    `json:"lockfileVersion"`
+		Packages        map[string]lockPackage `json:"packages"`
+	}
+	if err := json.Unmarshal(lockData, &lock); err != nil {
+		return nil, false, fmt.Errorf("failed to parse package-lock.json: %w", err)
+	}
+	if lock.LockfileVersion < 2 || lock.Packages == nil {
+		return nil, false, nil
+	}
+	return lock.Packages, true, nil
+}
+
+// auditScripts audits the packages of a lockfile and the lifecycle scripts of the plugin's
+// package.json. Install scripts of the packages in allowed are reported but allowed.
+func auditScripts(packages map[string]lockPackage, packageData []byte, allowed []string) (*scriptAudit, error) {
+	audit := &scriptAudit{}
+
+	if packageData != nil {
+		var pkg struct {
+			Scripts map[string]string `json:"scripts"`
+		}
+		if err := json.Unmarshal(packageData, &pkg); err != nil {
+			return nil, fmt.Errorf("failed to parse package.json: %w", err)
+		}
+		for _, script := range installScripts {
+			if command, ok := pkg.Scripts[script]; ok {
+				audit.RootScripts = append(audit.RootScripts, fmt.Sprintf("%s: %s", script, command))
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(packages))
+	for path := range packages {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		entry := packages[path]
+		// The root package and links to workspace packages, which are listed at their target
+		if path == "" || entry.Link {
+			continue
+		}
+		name := lockPackageName(path, entry)
+
+		if entry.HasInstallScript {
+			audit.InstallScripts = append(audit.InstallScripts, auditFinding{
+				Package: name,
+				Version: entry.Version,
+				Reason:  "runs an install script",
+				Allowed: slices.Contains(allowed, name),
+			})
+		}
+		if risky, ok := riskyPackages[name]; ok && slices.Contains(risky.versions, entry.Version) {
+			audit.Risky = append(audit.Risky, auditFinding{Package: name, Version: entry.Version, Reason: risky.reason})
+		}
+	}
+	return audit, nil
+}
+
+// lockPackageName returns the name of the package installed at path, e.g.
+// "node_modules/a/node_modules/@scope/b" is "@scope/b"
+func lockPackageName(path string, entry lockPackage) string {
+	if entry.Name != "" {
+		return entry.Name
+	}
+	if i := strings.LastIndex(path, "node_modules/"); i >= 0 {
+		return path[i+len("node_modules/"):]
+	}
+	return path
+}
+
+// printScriptAudit writes a report of audit to w
+func printScriptAudit(w io.Writer, audit *scriptAudit) {
+	fmt.Fprintf(w, "Dependency script audit\n")
+
+	if len(audit.RootScripts) > 0 {
+		fmt.Fprintf(w, "  Plugin lifecycle scripts:\n")
+		for _, script := range audit.RootScripts {
+			fmt.Fprintf(w, "    %s\n", script)
+		}
+	}
+
+	if len(audit.InstallScripts) == 0 {
+		fmt.Fprintf(w, "  No dependency runs an install script\n")
+	} else {
+		fmt.Fprintf(w, "  Dependencies with install scripts:\n")
+		for _, finding := range audit.InstallScripts {
+			allowed := ""
+			if finding.Allowed {
+				allowed = " (allowed)"
+			}
+			fmt.Fprintf(w, "    %s@%s%s\n", finding.Package, finding.Version, allowed)
+		}
+	}
+
+	if len(audit.Risky) == 0 {
+		fmt.Fprintf(w, "  No known-risky packages\n")
+	} else {
+		fmt.Fprintf(w, "  Known-risky packages:\n")
+		for _, finding := range audit.Risky {
+			fmt.Fprintf(w, "    %s@%s: %s\n", finding.Package, finding.Version, finding.Reason)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"dagger.io/dagger"
 	"dagger.io/dagger/dag"
@@ -18,6 +19,19 @@ var (
 	directory string
 	outputDir string
 	buildDir  string
+	noCache   bool
+
+	// Command the plugin is built with, and where it writes each plugin file
+	buildCommand string
+	artifacts    []string
+
+	// Audit dependencies for install scripts and known-risky packages before installing
+	auditDeps    bool
+	strictAudit  bool
+	allowScripts []string
+
+	// Version the plugin's metadata must declare (default: the version --ref names, if any)
+	expectVersion string
 
 	// Build-time variables (injected via -ldflags)
 	Version   = "dev"
@@ -40,7 +54,10 @@ func main() {
   # Build from local directory
   dragonglass-build . --directory example-plugin  # build from ./example-plugin subdirectory
   dragonglass-build /path/to/project --directory my-plugin  # build from /path/to/project/my-plugin
-  dragonglass-build ./example-plugin  # build from ./example-plugin (no subdirectory)`,
+  dragonglass-build ./example-plugin  # build from ./example-plugin (no subdirectory)
+
+  # Build with a custom command writing its outputs elsewhere
+  dragonglass-build . --build-command "npm run package" --artifact main.js=out/plugin.js --artifact styles.css=out/plugin.css`,
 		Run: func(cmd *cobra.Command, args []string) {
 			path := args[0]
 
@@ -55,7 +72,14 @@ func main() {
 				finalDirectory = "." // Use root of the path
 			}
 
-			if err := build(context.Background(), path, ref, commit, finalDirectory, outputDir, buildDir); err != nil {
+			mapped, err := parseArtifacts(artifacts, buildDir)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			audit := auditOptions{enabled: auditDeps || strictAudit, strict: strictAudit, allowScripts: allowScripts}
+			if err := build(context.Background(), path, ref, commit, finalDirectory, outputDir, buildDir, buildCommand, mapped, expectVersion, audit, noCache); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
@@ -79,7 +103,14 @@ func main() {
 	rootCmd.Flags().StringVarP(&commit, "commit", "c", "", "Specific commit hash to use - only used for remote repositories (takes precedence over --ref)")
 	rootCmd.Flags().StringVarP(&directory, "directory", "d", "", "Subdirectory to build from (defaults to root of path for both local and remote)")
 	rootCmd.Flags().StringVarP(&outputDir, "output-dir", "o", "dist", "Directory where final built plugin artifacts will be exported")
-	rootCmd.Flags().StringVar(&buildDir, "build-dir", "", "Directory where npm run build outputs artifacts (relative to plugin directory)")
+	rootCmd.Flags().StringVar(&buildDir, "build-dir", "", "Directory where the build command outputs artifacts (relative to plugin directory)")
+	rootCmd.Flags().StringVar(&buildCommand, "build-command", DefaultBuildCommand, "Shell command that builds the plugin, run in the plugin directory after dependencies are installed")
+	rootCmd.Flags().StringArrayVar(&artifacts, "artifact", nil, "Plugin file and the path the build writes it to, as name=path relative to the plugin directory (repeatable; e.g. main.js=build/main.js)")
+	rootCmd.Flags().StringVar(&expectVersion, "expect-version", "", "Fail unless manifest.json and package.json declare this version (default: the version --ref names, e.g. v1.2.3)")
+	rootCmd.Flags().BoolVar(&auditDeps, "audit-scripts", false, "Report dependencies with install scripts and known-risky packages before installing them")
+	rootCmd.Flags().BoolVar(&strictAudit, "strict", false, "Fail the build when the audit finds a risky package or an install script not allowed with --allow-script (implies --audit-scripts)")
+	rootCmd.Flags().StringArrayVar(&allowScripts, "allow-script", nil, "Package whose install script passes a --strict audit (repeatable; e.g. esbuild)")
+	rootCmd.Flags().BoolVar(&noCache, "no-cache", false, "Install dependencies from scratch, without the npm cache volume or cached Dagger steps")
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -87,7 +118,22 @@ func main() {
 	}
 }
 
-func build(ctx context.Context, path, ref, commit, directory, outputDir, buildDir string) error {
+const (
+	// nodeImage is the container image plugins are built in
+	nodeImage = "node:22"
+
+	// npmCacheDir is npm's download cache in nodeImage, kept in a cache volume between builds
+	npmCacheDir = "/root/.npm"
+)
+
+// auditOptions controls the dependency audit run before dependencies are installed
+type auditOptions struct {
+	enabled      bool
+	strict       bool
+	allowScripts []string
+}
+
+func build(ctx context.Context, path, ref, commit, directory, outputDir, buildDir, buildCommand string, artifacts []artifact, expectVersion string, audit auditOptions, noCache bool) error {
 	fmt.Println("Building with Dagger")
 	defer dag.Close()
 
@@ -95,18 +141,32 @@ func build(ctx context.Context, path, ref, commit, directory, outputDir, buildDi
 	outputs := dag.Directory()
 
 	var workingDir *dagger.Directory
+	metadata := &BuildMetadata{
+		Version: buildMetadataVersion,
+		Source:  BuildSource{URI: path, Directory: directory, BuildDir: buildDir, BuildCommand: buildCommand},
+		Builder: BuildToolchain{Version: Version, NodeImage: nodeImage},
+	}
 
 	// Determine if path is a remote repository URL or local directory
 	if isRemoteRepository(path) {
-		var repo *dagger.Directory
+		var gitRef *dagger.GitRef
 
 		if commit != "" {
 			fmt.Printf("Building from remote repository: %s (commit: %s)\n", path, commit)
-			repo = dag.Git(path).Commit(commit).Tree()
+			gitRef = dag.Git(path).Commit(commit)
 		} else {
 			fmt.Printf("Building from remote repository: %s (ref: %s)\n", path, ref)
-			repo = dag.Git(path).Ref(ref).Tree()
+			gitRef = dag.Git(path).Ref(ref)
+			metadata.Source.Ref = ref
+		}
+		repo := gitRef.Tree()
+
+		// Record the commit the ref resolved to, so provenance names exactly what was built
+		resolved, err := gitRef.Commit(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to resolve commit: %v", err)
 		}
+		metadata.Source.Commit = resolved
 
 		if directory == "." {
 			fmt.Printf("Using repository root\n")
@@ -127,6 +187,8 @@ func build(ctx context.Context, path, ref, commit, directory, outputDir, buildDi
 		if _, err := os.Stat(absPath); os.IsNotExist(err) {
 			return fmt.Errorf("directory does not exist: %s", absPath)
 		}
+		metadata.Source.URI = absPath
+		metadata.Source.Commit = localCommit(absPath)
 
 		// For local builds, use the directory flag to specify subdirectory
 		repo := dag.Host().Directory(absPath)
@@ -139,33 +201,109 @@ func build(ctx context.Context, path, ref, commit, directory, outputDir, buildDi
 		}
 	}
 
-	installer := dag.Container().
-		From("node:22").
+	// Refuse to build a plugin whose metadata disagrees with itself or the tag being built.
+	// A manifest.json the build generates is checked once it has been built.
+	var packageData []byte
+	if pkg, err := workingDir.File("package.json").Contents(ctx); err == nil {
+		packageData = []byte(pkg)
+	}
+	versionRef := ""
+	if isRemoteRepository(path) && commit == "" {
+		versionRef = ref
+	}
+	expected := expectedVersion(expectVersion, versionRef)
+	manifestPath := artifactPath(artifacts, "manifest.json")
+	manifestData, manifestErr := workingDir.File(manifestPath).Contents(ctx)
+	if manifestErr == nil {
+		if err := checkVersions([]byte(manifestData), packageData, expected); err != nil {
+			return err
+		}
+	}
+
+	// Builds without a lockfile resolve dependencies at build time, so there is no hash
+	var lockData []byte
+	if lock, err := workingDir.File("package-lock.json").Contents(ctx); err == nil {
+		lockData = []byte(lock)
+		digest := digestBytes(lockData, "package-lock.json")
+		metadata.Lockfile = &digest
+	}
+
+	container := dag.Container().
+		From(nodeImage).
 		WithDirectory("/usr/src/plugin", workingDir).
-		WithWorkdir("/usr/src/plugin").
+		WithWorkdir("/usr/src/plugin")
+	if noCache {
+		// A changing variable keeps Dagger from reusing the cached install step
+		fmt.Printf("Installing dependencies without cache\n")
+		container = container.WithEnvVariable("DRAGONGLASS_BUILD_NO_CACHE", time.Now().UTC().Format(time.RFC3339Nano))
+	} else {
+		metadata.Builder.CacheVolume = npmCacheVolume(metadata.Lockfile)
+		fmt.Printf("Using npm cache volume: %s\n", metadata.Builder.CacheVolume)
+		container = container.WithMountedCache(npmCacheDir, dag.CacheVolume(metadata.Builder.CacheVolume))
+	}
+
+	if audit.enabled {
+		if err := auditDependencies(ctx, container, lockData, packageData, audit); err != nil {
+			return err
+		}
+	}
+
+	installer := container.
 		WithExec([]string{"bash", "-c", "test -f package-lock.json && npm ci || npm install"}).
 		WithExec([]string{"bash", "-c", "npm sbom --sbom-type application --sbom-format spdx > sbom.spdx.json"})
 		// With([]string{""npm", "sbom", "--sbom-type", "application", "--sbom-format", "spdx", ">", "sbom.spdx.json"}).Terminal()
 
+	nodeVersion, err := installer.WithExec([]string{"node", "--version"}).Stdout(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read node version: %v", err)
+	}
+	metadata.Builder.NodeVersion = strings.TrimSpace(nodeVersion)
+
+	fmt.Printf("Building with: %s\n", buildCommand)
 	builder := installer.WithEnvVariable("NODE_ENV", "production").
-		WithExec([]string{"npm", "run", "build"})
+		WithExec([]string{"bash", "-c", buildCommand})
 
-	outputs = outputs.WithFile("main.js", builder.File(filepath.Join(buildDir, "main.js"))).
-		WithFile("manifest.json", builder.File("manifest.json")).
-		WithFile("sbom.spdx.json", installer.File("sbom.spdx.json"))
+	// Collect every plugin file the build wrote, and every required one it did not
+	var outputNames, missing []string
+	for _, a := range artifacts {
+		if _, err := builder.File(a.path).Sync(ctx); err != nil {
+			if a.required {
+				missing = append(missing, fmt.Sprintf("%s (expected at %s)", a.name, a.path))
+			}
+			continue
+		}
+		outputs = outputs.WithFile(a.name, builder.File(a.path))
+		outputNames = append(outputNames, a.name)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("build did not produce %s; map each to where the build writes it with --artifact name=path", strings.Join(missing, ", "))
+	}
+	outputs = outputs.WithFile("sbom.spdx.json", installer.File("sbom.spdx.json"))
+	outputNames = append(outputNames, "sbom.spdx.json")
 
-	// Check if styles.css exists and add it conditionally
-	stylesPath := filepath.Join(buildDir, "styles.css")
-	_, stylesErr := builder.File(stylesPath).Sync(ctx)
-	if stylesErr == nil {
-		// styles.css exists, include it
-		outputs = outputs.WithFile("styles.css", builder.File(stylesPath))
+	if manifestErr != nil {
+		manifestData, err := builder.File(manifestPath).Contents(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read manifest.json: %v", err)
+		}
+		if err := checkVersions([]byte(manifestData), packageData, expected); err != nil {
+			return err
+		}
 	}
 
-	_, err := outputs.Export(ctx, outputDir)
-	if err != nil {
+	if _, err := outputs.Export(ctx, outputDir); err != nil {
+		return err
+	}
+
+	// Outputs are hashed as exported, so the digests match the files callers publish
+	if metadata.Outputs, err = outputDigests(outputDir, outputNames); err != nil {
+		return err
+	}
+	metadata.FinishedAt = time.Now().UTC()
+	if err := writeBuildMetadata(outputDir, metadata); err != nil {
 		return err
 	}
+	fmt.Printf("Wrote %s\n", filepath.Join(outputDir, BuildMetadataFile))
 	return nil
 }
 
@@ -176,3 +314,40 @@ func isRemoteRepository(path string) bool {
 		strings.HasPrefix(path, "git@") ||
 		strings.HasPrefix(path, "ssh://")
 }
+
+// auditDependencies reports the install scripts and known-risky packages in the dependency
+// tree before anything in it runs. Without a lockfile recording install scripts, one is
+// resolved in container with scripts disabled.
+func auditDependencies(ctx context.Context, container *dagger.Container, lockData, packageData []byte, opts auditOptions) error {
+	var packages map[string]lockPackage
+	ok := false
+	if lockData != nil {
+		var err error
+		if packages, ok, err = lockfilePackages(lockData); err != nil {
+			return err
+		}
+	}
+	if !ok {
+		fmt.Printf("Resolving dependencies to audit without running scripts\n")
+		resolved, err := container.
+			WithExec([]string{"npm", "install", "--package-lock-only", "--ignore-scripts"}).
+			File("package-lock.json").
+			Contents(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to resolve dependencies: %v", err)
+		}
+		if packages, _, err = lockfilePackages([]byte(resolved)); err != nil {
+			return err
+		}
+	}
+
+	report, err := auditScripts(packages, packageData, opts.allowScripts)
+	if err != nil {
+		return err
+	}
+	printScriptAudit(os.Stdout, report)
+	if opts.strict && report.failed() {
+		return fmt.Errorf("dependency audit failed; remove the risky packages, or allow trusted install scripts with --allow-script")
+	}
+	return nil
+}
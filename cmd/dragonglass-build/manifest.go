@@ -0,0 +1,57 @@
+// ABOUTME: Pre-build check that the plugin's manifest.json, package.json, and requested tag agree
+// ABOUTME: Stops artifacts whose metadata disagrees with the git tag from being built and published
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// versionTagPattern matches refs that name a release, e.g. "v1.2.3" or "1.2.3-beta.1"
+var versionTagPattern = regexp.MustCompile(`^v?\d+\.\d+\.\d+([-+][0-9A-Za-z.+-]+)?$`)
+
+// expectedVersion returns the version a build must produce: expectVersion when given,
+// otherwise the version ref names when it is a release tag, otherwise ""
+func expectedVersion(expectVersion, ref string) string {
+	if expectVersion != "" {
+		return strings.TrimPrefix(expectVersion, "v")
+	}
+	if versionTagPattern.MatchString(ref) {
+		return strings.TrimPrefix(ref, "v")
+	}
+	return ""
+}
+
+// checkVersions verifies that the version in manifest.json matches the one in
+// package.json, when it has one, and expected, when it is set
+func checkVersions(manifestData, packageData []byte, expected string) error {
+	var manifest struct {
+		ID      string `json:"id"`
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return fmt.Errorf("failed to parse manifest.json: %w", err)
+	}
+	if manifest.Version == "" {
+		return fmt.Errorf("manifest.json has no version")
+	}
+
+	var pkg struct {
+		Version string `json:"version"`
+	}
+	if packageData != nil {
+		if err := json.Unmarshal(packageData, &pkg); err != nil {
+			return fmt.Errorf("failed to parse package.json: %w", err)
+		}
+	}
+
+	if pkg.Version != "" && pkg.Version != manifest.Version {
+		return fmt.Errorf("manifest.json version %s does not match package.json version %s; bump both before tagging", manifest.Version, pkg.Version)
+	}
+	if expected != "" && manifest.Version != expected {
+		return fmt.Errorf("manifest.json version %s does not match the version being built, %s; the tag and the plugin's metadata must agree", manifest.Version, expected)
+	}
+	return nil
+}
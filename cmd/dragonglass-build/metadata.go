@@ -0,0 +1,146 @@
+// ABOUTME: Build metadata written next to the built plugin for provenance workflows
+// ABOUTME: Records the source, toolchain, lockfile hash, and output digests so callers need not re-hash
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BuildMetadataFile is written to the output directory next to the built plugin
+const BuildMetadataFile = "build-metadata.json"
+
+// buildMetadataVersion is bumped when fields change meaning or are removed
+const buildMetadataVersion = "1"
+
+// BuildMetadata describes a build in the terms provenance workflows need: the subject
+// digests of every output and the parameters the build ran with
+type BuildMetadata struct {
+	Version string         `json:"version"`
+	Source  BuildSource    `json:"source"`
+	Builder BuildToolchain `json:"builder"`
+
+	// package-lock.json the dependencies were installed from; absent without one
+	Lockfile *FileDigest `json:"lockfile,omitempty"`
+
+	// Files written to the output directory, excluding this metadata
+	Outputs []FileDigest `json:"outputs"`
+
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// BuildSource is the source a build was made from
+type BuildSource struct {
+	// Repository URL, or the absolute path of a local directory
+	URI string `json:"uri"`
+
+	// Ref requested and the commit it resolved to; the commit is empty for local
+	// directories outside a git checkout
+	Ref    string `json:"ref,omitempty"`
+	Commit string `json:"commit,omitempty"`
+
+	// Plugin directory within the source, the command it was built with, and where that
+	// command writes to within it
+	Directory    string `json:"directory"`
+	BuildDir     string `json:"build_dir,omitempty"`
+	BuildCommand string `json:"build_command"`
+}
+
+// BuildToolchain is the tooling a build ran with
+type BuildToolchain struct {
+	// dragonglass-build version
+	Version string `json:"version"`
+
+	NodeImage   string `json:"node_image"`
+	NodeVersion string `json:"node_version"`
+
+	// Dagger cache volume npm's download cache was kept in; absent with --no-cache
+	CacheVolume string `json:"cache_volume,omitempty"`
+}
+
+// FileDigest is a file with its SHA-256 digest, in the "sha256:<hex>" form
+// actions/attest-build-provenance takes as subject-digest
+type FileDigest struct {
+	Name   string `json:"name"`
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+}
+
+// digestFile returns the digest of the file at path, recorded under name
+func digestFile(path, name string) (FileDigest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return FileDigest{}, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return FileDigest{}, fmt.Errorf("failed to hash %s: %w", name, err)
+	}
+	return FileDigest{Name: name, Digest: digestOf(hash.Sum(nil)), Size: size}, nil
+}
+
+// digestBytes returns the digest of data, recorded under name
+func digestBytes(data []byte, name string) FileDigest {
+	sum := sha256.Sum256(data)
+	return FileDigest{Name: name, Digest: digestOf(sum[:]), Size: int64(len(data))}
+}
+
+func digestOf(sum []byte) string {
+	return "sha256:" + hex.EncodeToString(sum)
+}
+
+// outputDigests returns the digests of the named files in outputDir, in order
+func outputDigests(outputDir string, names []string) ([]FileDigest, error) {
+	outputs := make([]FileDigest, 0, len(names))
+	for _, name := range names {
+		digest, err := digestFile(filepath.Join(outputDir, name), name)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, digest)
+	}
+	return outputs, nil
+}
+
+// npmCacheVolume returns the key of the cache volume for npm's download cache: one per
+// lockfile, so a build never sees packages fetched for other dependency sets, and a shared
+// one for plugins without a lockfile
+func npmCacheVolume(lockfile *FileDigest) string {
+	if lockfile == nil {
+		return "dragonglass-npm"
+	}
+	return "dragonglass-npm-" + strings.TrimPrefix(lockfile.Digest, "sha256:")[:16]
+}
+
+// localCommit returns the commit checked out in the local directory path, or "" when it
+// is not in a git checkout
+func localCommit(path string) string {
+	out, err := exec.Command("git", "-C", path, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// writeBuildMetadata writes metadata to BuildMetadataFile in outputDir
+func writeBuildMetadata(outputDir string, metadata *BuildMetadata) error {
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode build metadata: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, BuildMetadataFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", BuildMetadataFile, err)
+	}
+	return nil
+}
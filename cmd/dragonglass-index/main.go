@@ -0,0 +1,262 @@
+// ABOUTME: Tooling for the plugins index of build configs in the central index repository
+// ABOUTME: Validates plugins/**/build.json and compiles the published builds into the plugin catalog
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/auth"
+	"github.com/gillisandrew/dragonglass-poc/internal/catalog"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/ghactions"
+	"github.com/gillisandrew/dragonglass-poc/internal/githubapi"
+	"github.com/gillisandrew/dragonglass-poc/internal/index"
+	"github.com/gillisandrew/dragonglass-poc/internal/registry"
+)
+
+var (
+	format  string
+	offline bool
+
+	namespace           string
+	annotationNamespace string
+	outputFile          string
+	pushRef             string
+	source              string
+	sourceCommit        string
+
+	// Build-time variables (injected via -ldflags)
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+func main() {
+	var rootCmd = &cobra.Command{
+		Use:   "dragonglass-index",
+		Short: "Maintain the plugins index",
+		Long:  "A CLI tool for the central index repository, whose plugins/<owner>/<repo>/tags/<tag>/build.json configs pin the commits plugins are built from",
+	}
+
+	var validateCmd = &cobra.Command{
+		Use:   "validate [root]",
+		Short: "Validate the build configs of the plugins index",
+		Long: `Validate every build config under plugins/ in the repository at root (default:
+the current directory). Configs must sit at plugins/<owner>/<repo>/tags/<tag>/build.json
+and match schemas/build.v1.json. Tags of a repository naming the same version, such
+as 1.2.3 and v1.2.3, must pin the same commit, and each pinned commit must exist in
+the upstream GitHub repository. Set GITHUB_TOKEN to raise the API rate limit.
+
+Exits non-zero when any error is found; warnings are reported only.`,
+		Example: `  dragonglass-index validate
+  dragonglass-index validate --offline --format json`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			root := "."
+			if len(args) == 1 {
+				root = args[0]
+			}
+			if format != "text" && format != "json" {
+				fmt.Fprintf(os.Stderr, "Error: unsupported format %q (expected text or json)\n", format)
+				os.Exit(2)
+			}
+
+			opts := index.DefaultOpts()
+			if !offline {
+				opts.WithCommitChecker(index.NewGitHubChecker(githubapi.NewClient(githubapi.DefaultOpts().WithToken(os.Getenv("GITHUB_TOKEN")))))
+			}
+			result, err := index.Validate(context.Background(), root, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(2)
+			}
+
+			if err := report(os.Stdout, result); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(2)
+			}
+			if !result.Valid() {
+				os.Exit(1)
+			}
+		},
+	}
+
+	validateCmd.Flags().StringVar(&format, "format", "text", "Output format: text or json")
+	validateCmd.Flags().BoolVar(&offline, "offline", false, "Skip checking that pinned commits exist upstream")
+
+	var catalogCmd = &cobra.Command{
+		Use:   "catalog [root]",
+		Short: "Compile the plugin catalog from the index and its published builds",
+		Long: `Compile the plugin catalog: every build config of the plugins index whose build
+was published under --namespace, found by its commit-<sha> tag, with the plugin
+metadata and manifest digest of the build. Entries without a published build are
+left out with a warning.
+
+The catalog is written to --output (default: stdout) and, with --push, pushed as an
+OCI artifact to the given tagged reference. Attest its provenance so clients can
+verify it before use. Set GITHUB_TOKEN to read and push packages.`,
+		Example: `  dragonglass-index catalog --namespace ghcr.io/owner/index --output catalog.json
+  dragonglass-index catalog --namespace ghcr.io/owner/index --push ghcr.io/owner/index/catalog:latest --format json`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			root := "."
+			if len(args) == 1 {
+				root = args[0]
+			}
+			if format != "text" && format != "json" {
+				fmt.Fprintf(os.Stderr, "Error: unsupported format %q (expected text or json)\n", format)
+				os.Exit(2)
+			}
+			if namespace == "" {
+				fmt.Fprintln(os.Stderr, "Error: --namespace is required")
+				os.Exit(2)
+			}
+			if err := publishCatalog(context.Background(), root); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	catalogCmd.Flags().StringVar(&format, "format", "text", "Output format of the summary: text or json")
+	catalogCmd.Flags().StringVar(&namespace, "namespace", "", "Registry namespace builds are published under, e.g. ghcr.io/owner/index")
+	catalogCmd.Flags().StringVar(&annotationNamespace, "annotation-namespace", config.DefaultAnnotationNamespace, "Namespace of the plugin metadata annotations")
+	catalogCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Write the catalog to this file instead of stdout")
+	catalogCmd.Flags().StringVar(&pushRef, "push", "", "Push the catalog to this tagged reference")
+	catalogCmd.Flags().StringVar(&source, "source", os.Getenv("GITHUB_REPOSITORY"), "Index repository recorded in the catalog, as owner/repo")
+	catalogCmd.Flags().StringVar(&sourceCommit, "source-commit", os.Getenv("GITHUB_SHA"), "Index commit recorded in the catalog")
+
+	// Version command
+	var versionCmd = &cobra.Command{
+		Use:   "version",
+		Short: "Print version information",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("dragonglass-index version %s\n", Version)
+			fmt.Printf("Git commit: %s\n", Commit)
+			fmt.Printf("Build time: %s\n", BuildTime)
+		},
+	}
+
+	rootCmd.AddCommand(validateCmd, catalogCmd, versionCmd)
+
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// report writes the result in the selected format. Inside GitHub Actions each problem is
+// also written as an annotation on its file.
+func report(w io.Writer, result *index.Result) error {
+	if ghactions.InActions() {
+		for _, problem := range result.Problems {
+			level := ghactions.LevelError
+			if problem.Severity == index.SeverityWarning {
+				level = ghactions.LevelWarning
+			}
+			annotation := ghactions.Annotation{Level: level, Title: "Invalid build config (" + problem.Check + ")", File: problem.Path, Message: problem.Message}
+			if err := annotation.Write(os.Stderr); err != nil {
+				return err
+			}
+		}
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			Valid bool `json:"valid"`
+			*index.Result
+		}{result.Valid(), result})
+	}
+
+	for _, problem := range result.Problems {
+		fmt.Fprintf(w, "%s: %s [%s] %s\n", problem.Path, problem.Severity, problem.Check, problem.Message)
+	}
+	fmt.Fprintf(w, "Validated %d build configs: %d errors, %d warnings\n", len(result.Entries), result.Count(index.SeverityError), result.Count(index.SeverityWarning))
+	return nil
+}
+
+// publishCatalog validates the index offline, compiles the catalog, then writes and pushes
+// it. An invalid index is not published.
+func publishCatalog(ctx context.Context, root string) error {
+	result, err := index.Validate(ctx, root, nil)
+	if err != nil {
+		return err
+	}
+	if !result.Valid() {
+		for _, problem := range result.Problems {
+			fmt.Fprintf(os.Stderr, "%s: %s [%s] %s\n", problem.Path, problem.Severity, problem.Check, problem.Message)
+		}
+		return fmt.Errorf("the index has %d errors; run dragonglass-index validate", result.Count(index.SeverityError))
+	}
+
+	registryOpts := registry.DefaultRegistryOpts()
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		registryOpts = registryOpts.WithAuthProvider(auth.NewAuthClient(auth.DefaultAuthOpts().WithToken(token)))
+	}
+	client, err := registry.NewClient(registryOpts)
+	if err != nil {
+		return fmt.Errorf("failed to create registry client: %w", err)
+	}
+
+	opts := catalog.DefaultGenerateOpts(namespace, annotationNamespace).WithSource(source, sourceCommit)
+	c, warnings, err := catalog.Generate(ctx, result.Entries, client, opts)
+	if err != nil {
+		return err
+	}
+	for _, message := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", message)
+	}
+
+	data, err := c.Marshal()
+	if err != nil {
+		return err
+	}
+	if outputFile != "" {
+		if err := os.WriteFile(outputFile, data, 0644); err != nil {
+			return fmt.Errorf("failed to write catalog: %w", err)
+		}
+	} else if pushRef == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	summary := struct {
+		Plugins   int      `json:"plugins"`
+		Warnings  []string `json:"warnings"`
+		Reference string   `json:"reference,omitempty"`
+		Digest    string   `json:"digest,omitempty"`
+	}{Plugins: len(c.Plugins), Warnings: warnings}
+	if summary.Warnings == nil {
+		summary.Warnings = []string{}
+	}
+
+	if pushRef != "" {
+		desc, err := client.Push(ctx, pushRef, &registry.Artifact{
+			ArtifactType: catalog.ArtifactType,
+			Layers:       []registry.Layer{{Title: catalog.FileName, MediaType: catalog.MediaType, Content: data}},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to push catalog: %w", err)
+		}
+		summary.Reference, summary.Digest = pushRef, desc.Digest.String()
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summary)
+	}
+	fmt.Printf("Compiled a catalog of %d plugins (%d warnings)\n", summary.Plugins, len(warnings))
+	if summary.Digest != "" {
+		fmt.Printf("Pushed %s@%s\n", summary.Reference, summary.Digest)
+	}
+	return nil
+}
@@ -3,20 +3,54 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 
+	"github.com/gillisandrew/dragonglass-poc/internal/auth"
 	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
-	"github.com/gillisandrew/dragonglass-poc/internal/cmd/auth"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/attest"
+	attestationcmd "github.com/gillisandrew/dragonglass-poc/internal/cmd/attestation"
+	authcmd "github.com/gillisandrew/dragonglass-poc/internal/cmd/auth"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/bootstrap"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/ci"
+	configcmd "github.com/gillisandrew/dragonglass-poc/internal/cmd/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/daemon"
+	envcmd "github.com/gillisandrew/dragonglass-poc/internal/cmd/env"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/explain"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/freeze"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/gc"
 	"github.com/gillisandrew/dragonglass-poc/internal/cmd/install"
+	lintcmd "github.com/gillisandrew/dragonglass-poc/internal/cmd/lint"
 	"github.com/gillisandrew/dragonglass-poc/internal/cmd/list"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/lock"
+	notifycmd "github.com/gillisandrew/dragonglass-poc/internal/cmd/notify"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/publish"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/push"
+	sbomcmd "github.com/gillisandrew/dragonglass-poc/internal/cmd/sbom"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/search"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/serve"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/stats"
 	"github.com/gillisandrew/dragonglass-poc/internal/cmd/verify"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/watch"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
 	"github.com/gillisandrew/dragonglass-poc/internal/github"
+	"github.com/gillisandrew/dragonglass-poc/internal/httpclient"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/metrics"
 	"github.com/gillisandrew/dragonglass-poc/internal/oras"
+	"github.com/gillisandrew/dragonglass-poc/internal/prompt"
 	"github.com/gillisandrew/dragonglass-poc/internal/sigstore"
+	"github.com/gillisandrew/dragonglass-poc/internal/ui"
+	"github.com/gillisandrew/dragonglass-poc/internal/userdirs"
 )
 
 var (
@@ -26,15 +60,21 @@ var (
 	BuildTime = "unknown"
 
 	// Global flags
-	defaultAnnotationNamespace = "md.obsidian.plugin.v0"
-	defaultTrustedBuilder      = "https://github.com/gillisandrew/dragonglass-poc/.github/workflows/build.yml@refs/heads/main"
-	annotationNamespace        string
-	trustedBuilder             string
-	configPath                 string
-	lockfilePath               string
-	githubToken                string
-	verbose                    bool
-	quiet                      bool
+	annotationNamespace string
+	trustedBuilder      string
+	configPath          string
+	lockfilePath        string
+	githubToken         string
+	verbose             bool
+	quiet               bool
+	registryTimeout     string
+	verificationTimeout string
+	installTimeout      string
+	credentialStore     string
+	language            string
+	plain               bool
+	noHooks             bool
+	assumeYes           bool
 )
 
 var rootCmd = &cobra.Command{
@@ -49,55 +89,167 @@ vulnerability scanning before installation.`,
 
 func init() {
 	// Global persistent flags
-	rootCmd.PersistentFlags().StringVar(&annotationNamespace, "annotation-namespace", defaultAnnotationNamespace, "Plugin annotation namespace prefix")
-	rootCmd.PersistentFlags().StringVar(&trustedBuilder, "trusted-builder", defaultTrustedBuilder, "Trusted workflow signer identity")
+	rootCmd.PersistentFlags().StringVar(&annotationNamespace, "annotation-namespace", config.DefaultAnnotationNamespace, "Plugin annotation namespace prefix")
+	rootCmd.PersistentFlags().StringVar(&trustedBuilder, "trusted-builder", config.DefaultTrustedBuilder, "Trusted workflow signer identity")
 	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Path to configuration file")
 	rootCmd.PersistentFlags().StringVar(&lockfilePath, "lockfile", "", "Path to lockfile")
 	rootCmd.PersistentFlags().StringVar(&githubToken, "github-token", "", "GitHub authentication token")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose logging (debug level)")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Enable quiet mode (warnings and errors only)")
+	rootCmd.PersistentFlags().StringVar(&registryTimeout, "registry-timeout", config.DefaultRegistryTimeout.String(), "Maximum time for a single registry request")
+	rootCmd.PersistentFlags().StringVar(&verificationTimeout, "verification-timeout", config.DefaultVerificationTimeout.String(), "Maximum time to verify a plugin's attestations")
+	rootCmd.PersistentFlags().StringVar(&installTimeout, "install-timeout", config.DefaultInstallTimeout.String(), "Maximum time to download, verify and install a plugin")
+	rootCmd.PersistentFlags().StringVar(&credentialStore, "credential-store", "auto", "Where to keep the GitHub token: auto, keyring, file, env or none")
+	rootCmd.PersistentFlags().BoolVar(&plain, "plain", false, "Plain output without colors, spinners or box drawing (default when NO_COLOR is set or stdout is not a terminal)")
+	rootCmd.PersistentFlags().BoolVar(&noHooks, "no-hooks", false, "Skip the pre_install, post_install and post_remove hooks from config")
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "Confirm prompts before replacing plugins, accepting re-pushed tags or installing despite warnings")
+	rootCmd.PersistentFlags().StringVar(&language, "lang", i18n.Auto, "Language of prompts and messages: auto (from LANG), en or de")
 }
 
-// getGitHubToken returns the GitHub token from flag or environment variables
-func getGitHubToken(flagToken string) string {
-	if flagToken != "" {
-		return flagToken
+// resolveConfig computes the effective configuration from defaults, config files,
+// DRAGONGLASS_* environment variables, and explicitly set flags
+func resolveConfig() (*config.Resolved, error) {
+	opts := config.DefaultResolverOpts().
+		WithVaultConfigPath(configPath).
+		// config set and edit create a --config file that does not exist yet
+		WithCreateVaultConfig(configcmd.CreatesConfigFile(rootCmd.Flags().Args())).
+		WithGitHubToken(githubToken)
+	if lockfilePath != "" {
+		// The vault config belongs to the vault the lockfile is in, not the working directory
+		opts = opts.WithWorkingDir(lockfile.VaultRoot(lockfilePath))
 	}
 
-	// Try environment variables as fallback
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		return token
+	flags := rootCmd.Flags()
+	if flags.Changed("trusted-builder") {
+		opts = opts.WithFlag("verification.trusted_builder", trustedBuilder)
 	}
-	if token := os.Getenv("GH_TOKEN"); token != "" {
-		return token
+	if flags.Changed("annotation-namespace") {
+		opts = opts.WithFlag("registry.annotation_namespace", annotationNamespace)
+	}
+	if flags.Changed("verbose") {
+		opts = opts.WithFlag("output.verbose", strconv.FormatBool(verbose))
+	}
+	if flags.Changed("registry-timeout") {
+		opts = opts.WithFlag("registry.timeout", registryTimeout)
+	}
+	if flags.Changed("verification-timeout") {
+		opts = opts.WithFlag("verification.timeout", verificationTimeout)
+	}
+	if flags.Changed("install-timeout") {
+		opts = opts.WithFlag("install.timeout", installTimeout)
+	}
+	if flags.Changed("credential-store") {
+		opts = opts.WithFlag("auth.credential_store", credentialStore)
+	}
+	if flags.Changed("quiet") {
+		opts = opts.WithFlag("output.quiet", strconv.FormatBool(quiet))
+	}
+	if flags.Changed("plain") {
+		opts = opts.WithFlag("output.plain", strconv.FormatBool(plain))
+	}
+	if flags.Changed("no-hooks") {
+		opts = opts.WithFlag("hooks.disabled", strconv.FormatBool(noHooks))
+	}
+	if flags.Changed("lang") {
+		opts = opts.WithFlag("output.language", language)
 	}
 
-	return ""
+	return config.Resolve(opts)
 }
 
 // createCommandContext creates a CommandContext with the current flag values
 func createCommandContext() *cmd.CommandContext {
+	// Move user config and credentials out of ~/.dragonglass before anything reads them
+	migrations, migrateErr := migrateUserDirs()
+
+	resolved, err := resolveConfig()
+	if err != nil {
+		pterm.DefaultLogger.WithTime(false).WithWriter(os.Stderr).Error("Failed to resolve configuration", pterm.DefaultLogger.Args("error", err))
+		os.Exit(1)
+	}
+	cfg := resolved.Config
+
+	// Plain output follows the environment unless a flag, variable or config file chose it
+	if resolved.Origin("output.plain") == config.SourceDefault {
+		ui.SetPlain(ui.Detect(os.Getenv, ui.IsTerminal(os.Stdout)))
+	} else {
+		ui.SetPlain(cfg.Output.Plain)
+	}
+	if !cfg.Output.Color {
+		pterm.DisableColor()
+	}
+	ui.SetEmoji(cfg.Output.Emoji)
+	ui.SetQuiet(cfg.Output.Quiet)
+
 	// Initialize logger based on flags
 	var logger *pterm.Logger
-	if quiet {
+	if cfg.Output.Quiet {
 		logger = pterm.DefaultLogger.WithTime(false).WithLevel(pterm.LogLevelWarn)
-	} else if verbose {
+	} else if cfg.Output.Verbose {
 		logger = pterm.DefaultLogger.WithTime(false).WithLevel(pterm.LogLevelDebug)
 	} else {
 		logger = pterm.DefaultLogger.WithTime(false).WithLevel(pterm.LogLevelInfo)
 	}
 
 	// Configure logger to write to stderr to keep stdout clean
-	logger = logger.WithWriter(os.Stderr)
+	logger = ui.Logger(logger.WithWriter(os.Stderr))
+
+	// Every outbound request identifies this release and is logged with --verbose
+	httpclient.SetVersion(Version)
+	httpclient.SetLogger(logger)
+
+	if migrateErr != nil {
+		logger.Warn("Failed to move files out of the legacy ~/.dragonglass directory", logger.Args("error", migrateErr))
+	}
+	for _, m := range migrations {
+		if m.Conflict {
+			logger.Warn("Ignoring legacy file, a newer copy exists", logger.Args("legacy", m.From, "file", m.To))
+		} else {
+			logger.Info("Moved file to the user config directory", logger.Args("from", m.From, "to", m.To))
+		}
+	}
+
+	// Select the credential store before any service reads a stored token
+	store, err := auth.ParseCredentialStore(cfg.Auth.CredentialStore)
+	if err != nil {
+		logger.Error("Failed to select credential store", logger.Args("error", err))
+		os.Exit(1)
+	}
+	auth.SetCredentialStore(store)
+	auth.SetClientID(cfg.Auth.OAuthClientID)
+
+	// Prompts and status messages are localized; log lines stay in English
+	locale, err := i18n.ParseLocale(cfg.Output.Language, os.Getenv)
+	if err != nil {
+		logger.Error("Failed to select language", logger.Args("error", err))
+		os.Exit(1)
+	}
+	i18n.SetLocale(locale)
 
 	// Get GitHub token for service initialization
-	token := getGitHubToken(githubToken)
+	token := resolved.GitHubToken
+
+	// In GitHub Actions, trade the job's OIDC token for a registry token when no token was given
+	if token == "" && store != auth.StoreNone && cfg.Auth.OIDCExchangeURL != "" && auth.ActionsOIDCAvailable(os.Getenv) {
+		oidcCtx, cancel := context.WithTimeout(context.Background(), cfg.Registry.Timeout.Duration())
+		cred, err := auth.ExchangeActionsOIDCToken(oidcCtx, auth.DefaultOIDCOpts().
+			WithExchangeURL(cfg.Auth.OIDCExchangeURL).
+			WithAudience(cfg.Auth.OIDCAudience))
+		cancel()
+		if err != nil {
+			logger.Warn("Failed to exchange GitHub Actions OIDC token", logger.Args("error", err))
+		} else {
+			auth.SetSessionCredential(cred)
+			token = cred.Token
+			logger.Debug("Using token from GitHub Actions OIDC exchange", logger.Args("exchange", cfg.Auth.OIDCExchangeURL))
+		}
+	}
 
 	// Initialize services with dependency injection
 	authService := github.NewService()
 
 	// Create registry service with auth dependency injection
-	registryService, err := oras.NewService("ghcr.io", authService)
+	registryService, err := oras.NewService(cfg.Registry.DefaultRegistry, authService)
 	if err != nil {
 		logger.Error("Failed to initialize registry service", logger.Args("error", err))
 		// Fall back to nil service - commands should handle gracefully
@@ -114,18 +266,43 @@ func createCommandContext() *cmd.CommandContext {
 
 	// Initialize command context with global flags and services
 	return &cmd.CommandContext{
-		AnnotationNamespace: annotationNamespace,
-		TrustedBuilder:      trustedBuilder,
+		AnnotationNamespace: cfg.Registry.AnnotationNamespace,
+		TrustedBuilder:      cfg.Verification.TrustedBuilder,
 		ConfigPath:          configPath,
 		LockfilePath:        lockfilePath,
 		GitHubToken:         token,
+		Config:              cfg,
+		ResolvedConfig:      resolved,
 		Logger:              logger,
 		AuthService:         authService,
 		RegistryService:     registryService,
 		AttestationService:  attestationService,
+		Prompt:              prompt.New(assumeYes),
+		OnExit:              func() { recordStats(logger) },
 	}
 }
 
+// recordStats adds the metrics of this run to the stats file shown by dragonglass stats
+func recordStats(logger *pterm.Logger) {
+	dirs, err := userdirs.Resolve()
+	if err == nil {
+		err = metrics.RecordStats(dirs.StatsFile(), metrics.Default.Snapshot(), time.Now().UTC())
+	}
+	if err != nil {
+		logger.Debug("Failed to record run statistics", logger.Args("error", err))
+	}
+}
+
+// migrateUserDirs moves files written by older releases to ~/.dragonglass into the OS
+// config directory
+func migrateUserDirs() ([]userdirs.Migration, error) {
+	dirs, err := userdirs.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	return dirs.Migrate(config.UserConfigFileName, auth.TokenFile)
+}
+
 // versionCmd represents the version command
 var versionCmd = &cobra.Command{
 	Use:   "version",
@@ -145,15 +322,51 @@ func main() {
 	cmdContext := createCommandContext()
 
 	// Add commands with context
-	rootCmd.AddCommand(auth.NewAuthCommand(cmdContext))
+	rootCmd.AddCommand(bootstrap.NewInitCommand(cmdContext))
+	rootCmd.AddCommand(authcmd.NewAuthCommand(cmdContext))
 	rootCmd.AddCommand(install.NewInstallCommand(cmdContext))
 	rootCmd.AddCommand(install.NewAddCommand(cmdContext))
+	rootCmd.AddCommand(install.NewSyncCommand(cmdContext))
+	rootCmd.AddCommand(install.NewRemoveCommand(cmdContext))
+	rootCmd.AddCommand(install.NewEnableCommand(cmdContext))
+	rootCmd.AddCommand(install.NewDisableCommand(cmdContext))
+	rootCmd.AddCommand(install.NewDevCommand(cmdContext))
+	rootCmd.AddCommand(gc.NewGCCommand(cmdContext))
 	rootCmd.AddCommand(verify.NewVerifyCommand(cmdContext))
+	rootCmd.AddCommand(verify.NewVerifyFileCommand(cmdContext))
+	rootCmd.AddCommand(explain.NewExplainCommand(cmdContext))
+	rootCmd.AddCommand(ci.NewCICommand(cmdContext))
+	rootCmd.AddCommand(publish.NewPublishNotifyCommand(cmdContext))
+	rootCmd.AddCommand(push.NewPushCommand(cmdContext))
+	rootCmd.AddCommand(attest.NewAttestCommand(cmdContext))
+	rootCmd.AddCommand(attestationcmd.NewAttestationCommand(cmdContext))
+	rootCmd.AddCommand(lintcmd.NewLintArtifactCommand(cmdContext))
 	rootCmd.AddCommand(list.NewListCommand(cmdContext))
+	rootCmd.AddCommand(search.NewSearchCommand(cmdContext))
+	rootCmd.AddCommand(lock.NewLockCommand(cmdContext))
+	rootCmd.AddCommand(freeze.NewFreezeCommand(cmdContext))
+	rootCmd.AddCommand(notifycmd.NewNotifyCommand(cmdContext))
+	rootCmd.AddCommand(sbomcmd.NewSBOMCommand(cmdContext))
+	rootCmd.AddCommand(sbomcmd.NewDepsCommand(cmdContext))
+	rootCmd.AddCommand(serve.NewServeCommand(cmdContext))
+	rootCmd.AddCommand(daemon.NewDaemonCommand(cmdContext))
+	rootCmd.AddCommand(watch.NewWatchCommand(cmdContext))
+	rootCmd.AddCommand(configcmd.NewConfigCommand(cmdContext))
+	rootCmd.AddCommand(envcmd.NewEnvCommand(cmdContext))
+	rootCmd.AddCommand(stats.NewStatsCommand(cmdContext))
 	rootCmd.AddCommand(versionCmd)
 
-	if err := rootCmd.Execute(); err != nil {
-		cmdContext.Logger.Error("Command execution failed", cmdContext.Logger.Args("error", err))
-		os.Exit(1)
+	// Cancel in-flight downloads and verification on Ctrl-C or SIGTERM. Once cancelled the
+	// default handlers are restored so a second Ctrl-C terminates immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		cmdContext.Fail("Command execution failed", err)
 	}
+	cmdContext.OnExit()
 }
@@ -0,0 +1,210 @@
+// ABOUTME: Static risk heuristics for plugin code, reported as findings for curators
+// ABOUTME: Flags dynamic code, process and filesystem access, network endpoints and obfuscation
+package analysis
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+)
+
+// Severity ranks how strongly a finding suggests a plugin deserves review
+type Severity string
+
+const (
+	SeverityLow    Severity = "low"
+	SeverityMedium Severity = "medium"
+	SeverityHigh   Severity = "high"
+)
+
+// Rank orders severities from 1 (low) to 3 (high); unknown severities rank 0
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityLow:
+		return 1
+	case SeverityMedium:
+		return 2
+	case SeverityHigh:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Finding is one risk indicator, deduplicated by rule and matched text
+type Finding struct {
+	Rule        string   `json:"rule"`
+	Severity    Severity `json:"severity"`
+	Description string   `json:"description"`
+
+	// Matched text, or the host for network endpoints
+	Match string `json:"match"`
+
+	// Line of the first occurrence and the number of occurrences
+	Line  int `json:"line"`
+	Count int `json:"count"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s (%s): %s at line %d", f.Rule, f.Severity, f.Match, f.Line)
+}
+
+// Report holds the findings for one file, ordered by severity, rule and first occurrence
+type Report struct {
+	Findings []Finding `json:"findings"`
+}
+
+// Highest returns the most severe finding's severity, or "" when there are none
+func (r *Report) Highest() Severity {
+	var highest Severity
+	for _, f := range r.Findings {
+		if f.Severity.Rank() > highest.Rank() {
+			highest = f.Severity
+		}
+	}
+	return highest
+}
+
+// Exceeds returns the findings at or above threshold, for policies that block on them
+func (r *Report) Exceeds(threshold Severity) []Finding {
+	var blocking []Finding
+	for _, f := range r.Findings {
+		if threshold.Rank() > 0 && f.Severity.Rank() >= threshold.Rank() {
+			blocking = append(blocking, f)
+		}
+	}
+	return blocking
+}
+
+// Summary counts findings by severity
+func (r *Report) Summary() map[Severity]int {
+	summary := map[Severity]int{}
+	for _, f := range r.Findings {
+		summary[f.Severity]++
+	}
+	return summary
+}
+
+// rule is a pattern whose matches are reported as findings. match maps the matched text
+// to the reported value; returning "" drops the match.
+type rule struct {
+	name        string
+	severity    Severity
+	description string
+	pattern     *regexp.Regexp
+	match       func(string) string
+}
+
+var rules = []rule{
+	{
+		name:        "dynamic-code",
+		severity:    SeverityHigh,
+		description: "evaluates code built at runtime",
+		pattern:     regexp.MustCompile(`\beval\s*\(|\bnew\s+Function\s*\(|\bset(?:Timeout|Interval)\s*\(\s*["'` + "`" + `]`),
+	},
+	{
+		name:        "child-process",
+		severity:    SeverityHigh,
+		description: "can run programs outside Obsidian",
+		pattern:     regexp.MustCompile(`["'](?:node:)?child_process["']`),
+	},
+	{
+		name:        "path-outside-vault",
+		severity:    SeverityHigh,
+		description: "refers to files outside the vault",
+		pattern:     regexp.MustCompile(`\b(?:os\.)?homedir\s*\(\s*\)|["'` + "`" + `](?:/etc/|/Users/|/home/|/root/|~/|[A-Za-z]:\\\\)`),
+	},
+	{
+		name:        "filesystem",
+		severity:    SeverityMedium,
+		description: "uses Node's filesystem module instead of the vault API",
+		pattern:     regexp.MustCompile(`["'](?:node:)?fs(?:/promises)?["']`),
+	},
+	{
+		name:        "obfuscation",
+		severity:    SeverityMedium,
+		description: "contains obfuscated or encoded code",
+		pattern:     regexp.MustCompile(`\b_0x[0-9a-f]{4,}\b|(?:\\x[0-9a-fA-F]{2}){16,}|\batob\s*\(|[A-Za-z0-9+/]{512,}={0,2}`),
+		match: func(s string) string {
+			if len(s) > 40 {
+				return s[:40] + "..."
+			}
+			return s
+		},
+	},
+	{
+		name:        "network",
+		severity:    SeverityLow,
+		description: "contacts a network endpoint",
+		pattern:     regexp.MustCompile(`https?://[A-Za-z0-9.-]+(?::\d+)?|\bnew\s+WebSocket\s*\(`),
+		match: func(s string) string {
+			u, err := url.Parse(s)
+			if err != nil || u.Host == "" {
+				return s
+			}
+			if ignoredHosts[u.Hostname()] {
+				return ""
+			}
+			return u.Host
+		},
+	},
+}
+
+// ignoredHosts appear in almost every bundle (XML namespaces, licence headers) and are
+// never contacted
+var ignoredHosts = map[string]bool{
+	"www.w3.org":            true,
+	"www.apache.org":        true,
+	"opensource.org":        true,
+	"github.com":            true,
+	"reactjs.org":           true,
+	"developer.mozilla.org": true,
+}
+
+// Analyze scans plugin code for risk indicators. It is a heuristic pattern scan, not a
+// sandbox: findings call for review rather than prove malice, and their absence proves
+// nothing.
+func Analyze(src []byte) *Report {
+	lineStarts := []int{0}
+	for i, c := range src {
+		if c == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	lineOf := func(offset int) int {
+		return sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > offset })
+	}
+
+	report := &Report{Findings: []Finding{}}
+	for _, r := range rules {
+		index := map[string]int{}
+		for _, loc := range r.pattern.FindAllIndex(src, -1) {
+			match := string(bytes.TrimSpace(src[loc[0]:loc[1]]))
+			if r.match != nil {
+				if match = r.match(match); match == "" {
+					continue
+				}
+			}
+			if i, ok := index[match]; ok {
+				report.Findings[i].Count++
+				continue
+			}
+			index[match] = len(report.Findings)
+			report.Findings = append(report.Findings, Finding{
+				Rule:        r.name,
+				Severity:    r.severity,
+				Description: r.description,
+				Match:       match,
+				Line:        lineOf(loc[0]),
+				Count:       1,
+			})
+		}
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return report.Findings[i].Severity.Rank() > report.Findings[j].Severity.Rank()
+	})
+	return report
+}
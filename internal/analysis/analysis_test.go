@@ -0,0 +1,89 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected []string // rule:match of each finding, in report order
+	}{
+		{
+			name: "clean plugin",
+			src:  `var o=require("obsidian");module.exports=class extends o.Plugin{onload(){this.app.vault.read(f)}};`,
+		},
+		{
+			name:     "dynamic code",
+			src:      "eval(code);\nconst f = new Function('a', body);\nsetTimeout(\"run()\", 10);",
+			expected: []string{"dynamic-code:eval(", "dynamic-code:new Function(", `dynamic-code:setTimeout("`},
+		},
+		{
+			name:     "process and filesystem access",
+			src:      `const cp = require("child_process"); const fs = require('node:fs'); fs.readFileSync(require("os").homedir() + "/.ssh/id_rsa");`,
+			expected: []string{`child-process:"child_process"`, "path-outside-vault:homedir()", `filesystem:'node:fs'`},
+		},
+		{
+			name:     "network endpoints deduplicated by host",
+			src:      "fetch(\"https://api.example.com/v1\");\nfetch(\"https://api.example.com/v2\");\n// see https://github.com/owner/repo\nnew WebSocket(u)",
+			expected: []string{"network:api.example.com", "network:new WebSocket("},
+		},
+		{
+			name:     "obfuscation markers",
+			src:      "var _0x3f2a=['\\x68\\x65\\x6c\\x6c\\x6f\\x68\\x65\\x6c\\x6c\\x6f\\x68\\x65\\x6c\\x6c\\x6f\\x21'];atob(s)",
+			expected: []string{"obfuscation:_0x3f2a", `obfuscation:\x68\x65\x6c\x6c\x6f\x68\x65\x6c\x6c\x6f...`, "obfuscation:atob("},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Analyze([]byte(tt.src))
+
+			var got []string
+			for _, f := range report.Findings {
+				got = append(got, f.Rule+":"+f.Match)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("expected findings:\n%s\ngot:\n%s", strings.Join(tt.expected, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
+
+func TestAnalyzeLocations(t *testing.T) {
+	report := Analyze([]byte("ok();\nfetch('https://a.example');\n\nfetch('https://a.example');\n"))
+	if len(report.Findings) != 1 {
+		t.Fatalf("expected 1 finding, got %v", report.Findings)
+	}
+	if f := report.Findings[0]; f.Line != 2 || f.Count != 2 {
+		t.Errorf("expected first occurrence on line 2 counted twice, got line %d count %d", f.Line, f.Count)
+	}
+}
+
+func TestReportPolicy(t *testing.T) {
+	report := Analyze([]byte(`require("fs"); fetch("https://a.example")`))
+
+	if highest := report.Highest(); highest != SeverityMedium {
+		t.Errorf("expected highest severity medium, got %q", highest)
+	}
+	if summary := report.Summary(); summary[SeverityMedium] != 1 || summary[SeverityLow] != 1 {
+		t.Errorf("unexpected summary: %v", summary)
+	}
+
+	tests := []struct {
+		threshold Severity
+		expected  int
+	}{
+		{threshold: "", expected: 0},
+		{threshold: SeverityHigh, expected: 0},
+		{threshold: SeverityMedium, expected: 1},
+		{threshold: SeverityLow, expected: 2},
+	}
+	for _, tt := range tests {
+		if got := report.Exceeds(tt.threshold); len(got) != tt.expected {
+			t.Errorf("threshold %q: expected %d blocking findings, got %v", tt.threshold, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package attestation
+
+import (
+	"fmt"
+	"testing"
+)
+
+func BenchmarkAnalyzeVulnerabilities(b *testing.B) {
+	// An SBOM for a plugin with a large dependency tree
+	packages := make([]any, 5000)
+	for i := range packages {
+		name := fmt.Sprintf("package-%d", i)
+		if i%100 == 0 {
+			name = fmt.Sprintf("vulnerable-lib-%d", i)
+		}
+		packages[i] = map[string]any{"name": name, "versionInfo": "1.0.0"}
+	}
+	sbom := map[string]any{"packages": packages}
+	verifier := &AttestationVerifier{}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if vulns := verifier.analyzeVulnerabilities(sbom); len(vulns) != 50 {
+			b.Fatalf("expected 50 vulnerabilities, got %d", len(vulns))
+		}
+	}
+}
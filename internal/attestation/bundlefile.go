@@ -0,0 +1,49 @@
+// ABOUTME: Reading attestations supplied out of band as files, e.g. Sigstore bundles from releases
+// ABOUTME: Accepts a single JSON document or JSON Lines with one bundle per line, as gh attestation download writes
+package attestation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ReadBundleFile reads the attestations in the file at path: a single Sigstore bundle or
+// raw attestation, or JSON Lines holding one per line
+func ReadBundleFile(path string) ([][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attestation file: %w", err)
+	}
+	blobs, err := splitBundles(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation file %s: %w", path, err)
+	}
+	return blobs, nil
+}
+
+// splitBundles returns data as one attestation when it is a single JSON document, or
+// as one attestation per non-empty line otherwise
+func splitBundles(data []byte) ([][]byte, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no attestations found")
+	}
+	if json.Valid(data) {
+		return [][]byte{data}, nil
+	}
+
+	var blobs [][]byte
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if !json.Valid(line) {
+			return nil, fmt.Errorf("line %d is not a JSON attestation", i+1)
+		}
+		blobs = append(blobs, line)
+	}
+	return blobs, nil
+}
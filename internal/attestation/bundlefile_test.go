@@ -0,0 +1,95 @@
+package attestation
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+)
+
+func TestReadBundleFile(t *testing.T) {
+	bundle := `{"mediaType": "application/vnd.dev.sigstore.bundle.v0.3+json", "dsseEnvelope": {}}`
+
+	tests := []struct {
+		name        string
+		contents    string
+		expectBlobs int
+		expectErr   string
+	}{
+		{name: "single bundle", contents: bundle, expectBlobs: 1},
+		{name: "indented bundle", contents: "{\n  \"mediaType\": \"bundle\"\n}\n", expectBlobs: 1},
+		{name: "json lines", contents: bundle + "\n\n" + bundle + "\n", expectBlobs: 2},
+		{name: "empty", contents: "\n", expectErr: "no attestations found"},
+		{name: "invalid line", contents: bundle + "\nnot json\n", expectErr: "line 2 is not a JSON attestation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "attestation.jsonl")
+			if err := os.WriteFile(path, []byte(tt.contents), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			blobs, err := ReadBundleFile(path)
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(blobs) != tt.expectBlobs {
+				t.Errorf("expected %d attestations, got %d", tt.expectBlobs, len(blobs))
+			}
+		})
+	}
+
+	if _, err := ReadBundleFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestVerifyBundles(t *testing.T) {
+	verifier := &AttestationVerifier{trustedBuilder: trustedBuilder}
+
+	none := verifier.VerifyBundles(fileDigest, "", nil)
+	if none.Found || none.ArtifactDigest != fileDigest {
+		t.Errorf("unexpected result %+v", none)
+	}
+	if !errors.Is(none.Err(), dgerrors.ErrAttestationNotFound) {
+		t.Errorf("expected attestations not found, got %v", none.Err())
+	}
+
+	// An in-toto statement is unsigned, so anyone can write one naming the trusted builder
+	statement := `{
+  "_type": "https://in-toto.io/Statement/v1",
+  "subject": [{"name": "main.js", "digest": {"sha256": "` + strings.TrimPrefix(fileDigest, "sha256:") + `"}}],
+  "predicateType": "https://slsa.dev/provenance/v1",
+  "predicate": {
+    "buildDefinition": {
+      "buildType": "https://actions.github.io/buildtypes/workflow/v1",
+      "externalParameters": {"workflow": {"ref": "refs/heads/main", "repository": "https://github.com/owner/repo", "path": ".github/workflows/build.yml"}}
+    },
+    "runDetails": {"builder": {"id": "` + trustedBuilder + `"}}
+  }
+}`
+	unsigned := verifier.VerifyBundles(fileDigest, "sha256:index", [][]byte{[]byte(statement)})
+	if !unsigned.Found || unsigned.IndexDigest != "sha256:index" {
+		t.Errorf("expected the supplied attestation to be found, got %+v", unsigned)
+	}
+	if unsigned.Valid || unsigned.SLSA != nil {
+		t.Errorf("expected the unsigned statement not to be read as provenance, got %+v", unsigned)
+	}
+	check, ok := unsigned.Check(CheckSignature)
+	if !ok || check.Status != CheckFailed || check.Severity != SeverityHard || !strings.Contains(check.Detail, "not a Sigstore bundle") {
+		t.Errorf("expected a hard signature failure, got %+v", check)
+	}
+	if err := unsigned.Enforce(false); !errors.Is(err, dgerrors.ErrAttestationInvalid) {
+		t.Errorf("expected the unsigned statement to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+// ABOUTME: Typed verification checks recorded on a VerificationResult
+// ABOUTME: Separates hard failures from those that only fail verification in strict mode
+package attestation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+)
+
+// AddCheck records the outcome of the named check, replacing an earlier outcome of it
+func (r *VerificationResult) AddCheck(name string, status CheckStatus, severity Severity, detail string) {
+	check := Check{Name: name, Status: status, Severity: severity, Detail: detail}
+	for i := range r.Checks {
+		if r.Checks[i].Name == name {
+			r.Checks[i] = check
+			return
+		}
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// Check returns the outcome of the named check, if it was run
+func (r *VerificationResult) Check(name string) (Check, bool) {
+	for _, check := range r.Checks {
+		if check.Name == name {
+			return check, true
+		}
+	}
+	return Check{}, false
+}
+
+// Failures returns the failed checks in the order they were recorded
+func (r *VerificationResult) Failures() []Check {
+	var failed []Check
+	for _, check := range r.Checks {
+		if check.Status == CheckFailed {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+// Err explains why the result does not establish trust using the sentinels in
+// internal/errors, so callers can match it with errors.Is: the error of the first check
+// that failed hard or soft. It returns nil when none did.
+func (r *VerificationResult) Err() error {
+	for _, check := range r.Failures() {
+		if check.Severity != SeverityWarning {
+			return r.CheckErr(check)
+		}
+	}
+	return nil
+}
+
+// Enforce returns the error of the first check that fails verification under the given
+// mode: hard failures always, soft failures only in strict mode
+func (r *VerificationResult) Enforce(strict bool) error {
+	for _, check := range r.Failures() {
+		switch {
+		case check.Severity == SeverityHard:
+			return r.CheckErr(check)
+		case check.Severity == SeveritySoft && strict:
+			return fmt.Errorf("%w (required in strict mode)", r.CheckErr(check))
+		}
+	}
+	return nil
+}
+
+// CheckErr returns the error a failed check stands for, marked with the sentinel that
+// determines the exit code
+func (r *VerificationResult) CheckErr(check Check) error {
+	switch check.Name {
+	case CheckAttestations:
+		if r.Transient {
+			return fmt.Errorf("%w: %s", dgerrors.ErrTransientVerification, check.Detail)
+		}
+		if check.Detail == "" || check.Detail == noAttestations {
+			return dgerrors.ErrAttestationNotFound
+		}
+		return fmt.Errorf("%w: %s", dgerrors.ErrAttestationNotFound, check.Detail)
+	case CheckTrustedBuilder:
+		if r.SLSA != nil {
+			return &dgerrors.UntrustedBuilderError{Builder: r.SLSA.Builder, TrustedBuilder: r.TrustedBuilder}
+		}
+	case CheckVulnerabilities:
+		return fmt.Errorf("%w: %s", dgerrors.ErrVulnBlocked, check.Detail)
+	case CheckSBOM:
+		return errors.New(check.Detail)
+	}
+	if check.Detail == "" {
+		return dgerrors.ErrAttestationInvalid
+	}
+	return fmt.Errorf("%w: %s", dgerrors.ErrAttestationInvalid, check.Detail)
+}
+
+// noAttestations is the detail of the attestations check when discovery succeeded but
+// found nothing
+const noAttestations = "no attestations found"
+
+// recordFound records whether attestations were found, ahead of the checks of their
+// contents, and clears Valid when a check failed hard. It is called once verification of
+// an artifact is complete.
+func (r *VerificationResult) recordFound() {
+	found := Check{Name: CheckAttestations, Status: CheckPassed, Severity: SeveritySoft}
+	if !r.Found {
+		found.Status = CheckFailed
+		found.Detail = noAttestations
+		if errs := r.Errors(); len(errs) > 0 {
+			found.Detail = strings.Join(errs, "; ")
+		}
+	}
+	r.Checks = append([]Check{found}, r.Checks...)
+
+	for _, check := range r.Failures() {
+		if check.Severity == SeverityHard {
+			r.Valid = false
+		}
+	}
+}
+
+// recordContentChecks records the checks of the attestations verifyBlobs parsed: of the
+// Sigstore bundles, signatureErrors are the blobs that failed verification or were not
+// bundles, and slsaErr and sbomErr are why the provenance and SBOM could not be read.
+// Without a verified bundle nothing vouches for the attestations, so the signature check
+// fails hard.
+func (r *VerificationResult) recordContentChecks(bundles int, signatureErrors []string, slsaErr, sbomErr error) {
+	switch {
+	case len(signatureErrors) > 0:
+		r.AddCheck(CheckSignature, CheckFailed, SeverityHard, strings.Join(signatureErrors, "; "))
+	case bundles == 0:
+		r.AddCheck(CheckSignature, CheckFailed, SeverityHard, "no Sigstore bundles")
+	default:
+		r.AddCheck(CheckSignature, CheckPassed, SeverityHard, "")
+	}
+
+	switch {
+	case slsaErr != nil:
+		r.AddCheck(CheckProvenance, CheckFailed, SeveritySoft, slsaErr.Error())
+	case r.SLSA == nil:
+		r.AddCheck(CheckProvenance, CheckFailed, SeveritySoft, "no SLSA provenance attestation found")
+	default:
+		r.AddCheck(CheckProvenance, CheckPassed, SeveritySoft, "")
+	}
+
+	switch {
+	case r.SLSA == nil:
+		r.AddCheck(CheckTrustedBuilder, CheckSkipped, SeveritySoft, "")
+	case r.SLSA.Valid:
+		r.AddCheck(CheckTrustedBuilder, CheckPassed, SeveritySoft, r.SLSA.Builder)
+	default:
+		r.AddCheck(CheckTrustedBuilder, CheckFailed, SeveritySoft, fmt.Sprintf("built by %q, not the trusted builder %q", r.SLSA.Builder, r.TrustedBuilder))
+	}
+
+	switch {
+	case sbomErr != nil:
+		r.AddCheck(CheckSBOM, CheckFailed, SeverityWarning, sbomErr.Error())
+	case r.SBOM == nil:
+		r.AddCheck(CheckSBOM, CheckFailed, SeverityWarning, "no SBOM attestation found")
+	default:
+		r.AddCheck(CheckSBOM, CheckPassed, SeverityWarning, "")
+	}
+
+	if r.SBOM == nil {
+		r.AddCheck(CheckVulnerabilities, CheckSkipped, SeveritySoft, "")
+	} else if high := r.HighSeverityVulnerabilities(); high > 0 {
+		r.AddCheck(CheckVulnerabilities, CheckFailed, SeveritySoft, fmt.Sprintf("%d high/critical vulnerabilities found", high))
+	} else {
+		r.AddCheck(CheckVulnerabilities, CheckPassed, SeveritySoft, "")
+	}
+}
+
+// HighSeverityVulnerabilities counts the HIGH and CRITICAL vulnerabilities in the SBOM
+func (r *VerificationResult) HighSeverityVulnerabilities() int {
+	if r.SBOM == nil {
+		return 0
+	}
+	count := 0
+	for _, vuln := range r.SBOM.Vulnerabilities {
+		if vuln.Severity == "HIGH" || vuln.Severity == "CRITICAL" {
+			count++
+		}
+	}
+	return count
+}
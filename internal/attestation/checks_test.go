@@ -0,0 +1,178 @@
+package attestation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/findings"
+)
+
+const trustedBuilder = "https://github.com/owner/repo/.github/workflows/build.yml@refs/heads/main"
+
+// resultWith returns a found result whose content checks were recorded from slsa, sbom,
+// and the given failures
+func resultWith(slsa *SLSAResult, sbom *SBOMResult, signatureErrors []string, slsaErr error) *VerificationResult {
+	result := &VerificationResult{Found: true, SLSA: slsa, SBOM: sbom, TrustedBuilder: trustedBuilder}
+	result.Valid = slsa != nil && slsa.Valid
+	result.recordContentChecks(1, signatureErrors, slsaErr, nil)
+	result.recordFound()
+	return result
+}
+
+func TestVerificationResultChecks(t *testing.T) {
+	trusted := &SLSAResult{Valid: true, Builder: trustedBuilder}
+	untrusted := &SLSAResult{Builder: "https://github.com/evil/repo/.github/workflows/build.yml@refs/heads/main"}
+	clean := &SBOMResult{Valid: true}
+	vulnerable := &SBOMResult{Valid: true, Vulnerabilities: []Vulnerability{{Severity: "CRITICAL"}, {Severity: "LOW"}}}
+
+	notFound := &VerificationResult{TrustedBuilder: trustedBuilder}
+	notFound.recordFound()
+
+	unreachable := &VerificationResult{TrustedBuilder: trustedBuilder, Transient: true}
+	unreachable.Findings.Errorf(CheckAttestations, "failed to get attestations: 503 Service Unavailable (after 3 attempts)")
+	unreachable.recordFound()
+
+	tests := []struct {
+		name         string
+		result       *VerificationResult
+		expectValid  bool
+		expectFailed []string
+		expectErr    error
+		expectLax    error
+	}{
+		{
+			name:        "valid",
+			result:      resultWith(trusted, clean, nil, nil),
+			expectValid: true,
+		},
+		{
+			name:         "not found",
+			result:       notFound,
+			expectFailed: []string{CheckAttestations},
+			expectErr:    dgerrors.ErrAttestationNotFound,
+		},
+		{
+			name:         "attestations unreachable",
+			result:       unreachable,
+			expectFailed: []string{CheckAttestations},
+			expectErr:    dgerrors.ErrTransientVerification,
+		},
+		{
+			name:         "untrusted builder",
+			result:       resultWith(untrusted, clean, nil, nil),
+			expectFailed: []string{CheckTrustedBuilder},
+			expectErr:    dgerrors.ErrUntrustedBuilder,
+		},
+		{
+			name:         "invalid provenance",
+			result:       resultWith(nil, clean, nil, fmt.Errorf("SLSA verification failed: bad predicate")),
+			expectFailed: []string{CheckProvenance},
+			expectErr:    dgerrors.ErrAttestationInvalid,
+		},
+		{
+			name:         "missing SBOM only warns",
+			result:       resultWith(trusted, nil, nil, nil),
+			expectValid:  true,
+			expectFailed: []string{CheckSBOM},
+		},
+		{
+			name:         "vulnerabilities",
+			result:       resultWith(trusted, vulnerable, nil, nil),
+			expectValid:  true,
+			expectFailed: []string{CheckVulnerabilities},
+			expectErr:    dgerrors.ErrVulnBlocked,
+		},
+		{
+			name:         "signature invalid fails outside strict mode",
+			result:       resultWith(trusted, clean, []string{"bundle 1: signature mismatch"}, nil),
+			expectFailed: []string{CheckSignature},
+			expectErr:    dgerrors.ErrAttestationInvalid,
+			expectLax:    dgerrors.ErrAttestationInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.result.Valid != tt.expectValid {
+				t.Errorf("expected valid=%v, got %v", tt.expectValid, tt.result.Valid)
+			}
+			if tt.result.Checks[0].Name != CheckAttestations {
+				t.Errorf("expected the attestations check first, got %+v", tt.result.Checks)
+			}
+
+			var failed []string
+			for _, check := range tt.result.Failures() {
+				failed = append(failed, check.Name)
+			}
+			if strings.Join(failed, ",") != strings.Join(tt.expectFailed, ",") {
+				t.Errorf("expected failed checks %v, got %v", tt.expectFailed, failed)
+			}
+
+			assertErr(t, "Err", tt.result.Err(), tt.expectErr)
+			assertErr(t, "Enforce(true)", tt.result.Enforce(true), tt.expectErr)
+			assertErr(t, "Enforce(false)", tt.result.Enforce(false), tt.expectLax)
+		})
+	}
+}
+
+func assertErr(t *testing.T, name string, err, expected error) {
+	t.Helper()
+	if expected == nil {
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+		return
+	}
+	if !errors.Is(err, expected) {
+		t.Errorf("%s: expected %v, got %v", name, expected, err)
+	}
+}
+
+func TestAddCheckReplaces(t *testing.T) {
+	result := &VerificationResult{}
+	result.AddCheck(CheckPluginPolicy, CheckPassed, SeverityHard, "")
+	result.AddCheck(CheckPluginPolicy, CheckFailed, SeverityHard, "other builder")
+
+	if len(result.Checks) != 1 {
+		t.Fatalf("expected one policy check, got %+v", result.Checks)
+	}
+	if check, ok := result.Check(CheckPluginPolicy); !ok || check.Status != CheckFailed || check.Detail != "other builder" {
+		t.Errorf("expected the later outcome, got %+v", check)
+	}
+}
+
+func TestFindingsFeedChecksAndJSON(t *testing.T) {
+	result := &VerificationResult{TrustedBuilder: trustedBuilder}
+	result.Findings.Errorf(CheckAttestations, "failed to resolve reference: %s", "manifest unknown")
+	result.Findings.Warnf(CheckSignature, "failed to parse sigstore bundle 0")
+	result.recordFound()
+	result.Findings.SetPluginID("obsidian-git")
+
+	if check, _ := result.Check(CheckAttestations); check.Detail != "failed to resolve reference: manifest unknown" {
+		t.Errorf("expected the attestations check to carry the error, got %+v", check)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rendered struct {
+		Errors   []string           `json:"errors"`
+		Warnings []string           `json:"warnings"`
+		Findings []findings.Finding `json:"findings"`
+		Checks   []Check            `json:"checks"`
+	}
+	if err := json.Unmarshal(data, &rendered); err != nil {
+		t.Fatal(err)
+	}
+	if len(rendered.Errors) != 1 || len(rendered.Warnings) != 1 || len(rendered.Checks) != 1 {
+		t.Errorf("expected one error, warning, and check, got %s", data)
+	}
+	if len(rendered.Findings) != 2 || rendered.Findings[1].PluginID != "obsidian-git" || rendered.Findings[1].Check != CheckSignature {
+		t.Errorf("expected tagged findings, got %+v", rendered.Findings)
+	}
+}
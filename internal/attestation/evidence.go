@@ -0,0 +1,94 @@
+// ABOUTME: Evidence a verified Sigstore bundle carries about who signed an attestation
+// ABOUTME: Collects the Fulcio certificate identity, workflow run, and Rekor log entries
+package attestation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/sigstore/sigstore-go/pkg/bundle"
+	"github.com/sigstore/sigstore-go/pkg/verify"
+)
+
+// RekorSearchURL is the public Rekor search page, queried by log index
+const RekorSearchURL = "https://search.sigstore.dev/"
+
+// BundleEvidence is what a Sigstore bundle establishes about an attestation: the digest
+// of the bundle itself, the identity its Fulcio certificate was issued to, and where the
+// signature was recorded in Rekor. The identity fields are empty unless the bundle was
+// cryptographically verified.
+type BundleEvidence struct {
+	// Digest of the bundle exactly as fetched, e.g. "sha256:..."
+	Digest string `json:"digest"`
+
+	// Subject of the signing certificate (for GitHub Actions, the workflow file at a ref)
+	// and the OIDC issuer that vouched for it
+	CertificateIdentity string `json:"certificateIdentity,omitempty"`
+	CertificateIssuer   string `json:"certificateIssuer,omitempty"`
+
+	// Workflow run that signed, and the source revision it ran on, from the certificate
+	// extensions
+	RunURL           string `json:"runUrl,omitempty"`
+	SourceRepository string `json:"sourceRepository,omitempty"`
+	SourceCommit     string `json:"sourceCommit,omitempty"`
+
+	// Transparency log entries recording the signature
+	RekorEntries []RekorEntry `json:"rekorEntries,omitempty"`
+}
+
+// RekorEntry locates a signature in the Rekor transparency log
+type RekorEntry struct {
+	LogIndex       int64     `json:"logIndex"`
+	IntegratedTime time.Time `json:"integratedTime"`
+}
+
+// SearchURL returns the page showing the entry on the public Rekor instance
+func (e RekorEntry) SearchURL() string {
+	return fmt.Sprintf("%s?logIndex=%d", RekorSearchURL, e.LogIndex)
+}
+
+// bundleEvidence collects the signing identity from verified, the result of verifying b
+// (nil when it was not verified), and the transparency log entries from b
+func bundleEvidence(b *bundle.Bundle, verified *verify.VerificationResult) *BundleEvidence {
+	evidence := &BundleEvidence{}
+	if verified != nil && verified.Signature != nil && verified.Signature.Certificate != nil {
+		cert := verified.Signature.Certificate
+		evidence.CertificateIdentity = cert.SubjectAlternativeName
+		evidence.CertificateIssuer = cert.CertificateIssuer
+		evidence.RunURL = cert.RunInvocationURI
+		evidence.SourceRepository = cert.SourceRepositoryURI
+		evidence.SourceCommit = cert.SourceRepositoryDigest
+	}
+
+	// Entries were already checked by the verifier; a bundle that fails to parse here
+	// simply has no entries to show
+	if entries, err := b.TlogEntries(); err == nil {
+		for _, entry := range entries {
+			evidence.RekorEntries = append(evidence.RekorEntries, RekorEntry{
+				LogIndex:       entry.LogIndex(),
+				IntegratedTime: entry.IntegratedTime().UTC(),
+			})
+		}
+	}
+	return evidence
+}
+
+// blobDigest returns the sha256 digest of an attestation blob
+func blobDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Bundles returns the attestations that arrived in Sigstore bundles, in the order they
+// were fetched
+func (r *VerificationResult) Bundles() []AttestationData {
+	var bundles []AttestationData
+	for _, att := range r.Results {
+		if att.Bundle != nil {
+			bundles = append(bundles, att)
+		}
+	}
+	return bundles
+}
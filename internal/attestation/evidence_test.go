@@ -0,0 +1,29 @@
+package attestation
+
+import "testing"
+
+func TestRekorEntrySearchURL(t *testing.T) {
+	entry := RekorEntry{LogIndex: 123456}
+	if got := entry.SearchURL(); got != "https://search.sigstore.dev/?logIndex=123456" {
+		t.Errorf("unexpected search URL %s", got)
+	}
+}
+
+func TestBlobDigest(t *testing.T) {
+	if got := blobDigest([]byte("")); got != "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("unexpected digest %s", got)
+	}
+}
+
+func TestBundles(t *testing.T) {
+	result := &VerificationResult{Results: []AttestationData{
+		{PredicateType: SLSAPredicateV1, Bundle: &BundleEvidence{Digest: "sha256:aaaa"}},
+		{PredicateType: SBOMPredicateV2},
+		{PredicateType: SBOMPredicateV3, Bundle: &BundleEvidence{Digest: "sha256:bbbb"}},
+	}}
+
+	bundles := result.Bundles()
+	if len(bundles) != 2 || bundles[0].Bundle.Digest != "sha256:aaaa" || bundles[1].Bundle.Digest != "sha256:bbbb" {
+		t.Errorf("expected the two bundled attestations in order, got %+v", bundles)
+	}
+}
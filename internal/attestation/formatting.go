@@ -5,6 +5,8 @@ package attestation
 import (
 	"fmt"
 	"strings"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
 )
 
 // GetAttestationDigest extracts the subject digest from verification results
@@ -27,7 +29,7 @@ func (v *AttestationVerifier) ValidateSubjectMatch(result *VerificationResult, a
 	attestationDigest = normalizeDigest(attestationDigest)
 
 	if attestationDigest != artifactDigest {
-		return fmt.Errorf("digest mismatch: attestation=%s, artifact=%s", attestationDigest, artifactDigest)
+		return &dgerrors.DigestMismatchError{Subject: "attestation subject", Expected: artifactDigest, Actual: attestationDigest}
 	}
 
 	return nil
@@ -56,6 +58,18 @@ func (v *AttestationVerifier) FormatVerificationResult(result *VerificationResul
 		output.WriteString("Attestations: Invalid\n")
 	}
 
+	// One line per check; failures name their severity
+	for _, check := range result.Checks {
+		line := fmt.Sprintf("   %s: %s", check.Name, check.Status)
+		if check.Status == CheckFailed {
+			line += fmt.Sprintf(" (%s)", check.Severity)
+		}
+		if check.Detail != "" {
+			line += " - " + check.Detail
+		}
+		output.WriteString(line + "\n")
+	}
+
 	// SLSA details
 	if result.SLSA != nil {
 		if result.SLSA.Valid {
@@ -90,11 +104,11 @@ func (v *AttestationVerifier) FormatVerificationResult(result *VerificationResul
 	}
 
 	// Warnings and errors
-	for _, warning := range result.Warnings {
+	for _, warning := range result.Findings.Warnings() {
 		output.WriteString(fmt.Sprintf("   %s\n", warning))
 	}
 
-	for _, err := range result.Errors {
+	for _, err := range result.Findings.Errors() {
 		output.WriteString(fmt.Sprintf("   %s\n", err))
 	}
 
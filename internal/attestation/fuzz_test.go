@@ -0,0 +1,74 @@
+package attestation
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// FuzzParseRawAttestation feeds registry-supplied statement bytes through statement parsing
+// and the SLSA or SBOM checks for their predicate type
+func FuzzParseRawAttestation(f *testing.F) {
+	const trustedBuilder = "https://github.com/gillisandrew/dragonglass-poc/.github/workflows/build.yml@refs/heads/main"
+
+	// SLSA provenance statement for a plugin built by the build workflow
+	f.Add([]byte(`{
+  "_type": "https://in-toto.io/Statement/v1",
+  "subject": [{"name": "ghcr.io/silentvoid13/templater/templater-obsidian", "digest": {"sha256": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"}}],
+  "predicateType": "https://slsa.dev/provenance/v1",
+  "predicate": {
+    "buildDefinition": {
+      "buildType": "https://actions.github.io/buildtypes/workflow/v1",
+      "externalParameters": {"workflow": {"ref": "refs/heads/main", "repository": "https://github.com/gillisandrew/dragonglass-poc", "path": ".github/workflows/build.yml"}},
+      "resolvedDependencies": [{"uri": "git+https://github.com/gillisandrew/dragonglass-poc@refs/heads/main", "digest": {"gitCommit": "77ab745aee787d519642a87ed8f68be12fdc4b0d"}}]
+    },
+    "runDetails": {
+      "builder": {"id": "https://github.com/gillisandrew/dragonglass-poc/.github/workflows/build.yml@refs/heads/main"},
+      "metadata": {"invocationId": "https://github.com/gillisandrew/dragonglass-poc/actions/runs/123/attempts/1"}
+    }
+  }
+}`))
+	// SBOM statement as attached by actions/attest-sbom
+	f.Add([]byte(`{
+  "_type": "https://in-toto.io/Statement/v1",
+  "predicateType": "https://spdx.dev/Document/v2.3",
+  "predicate": {
+    "spdxVersion": "SPDX-2.3",
+    "packages": [
+      {"name": "obsidian", "versionInfo": "1.4.11"},
+      {"name": "lodash", "versionInfo": "4.17.20", "externalRefs": [{"referenceCategory": "SECURITY", "referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:lodash:lodash:4.17.20"}]}
+    ]
+  }
+}`))
+	f.Add([]byte(`{"predicateType": "https://spdx.dev/Document/v3.0", "predicate": {"packages": [{}]}}`))
+	f.Add([]byte(`{"predicateType": "https://slsa.dev/provenance/v1", "predicate": null}`))
+
+	verifier := &AttestationVerifier{trustedBuilder: trustedBuilder}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		att, err := parseStatement(data)
+		if err != nil {
+			return
+		}
+
+		switch att.PredicateType {
+		case SLSAPredicateV1:
+			result, err := verifier.verifySLSA([]AttestationData{*att})
+			if err == nil && result.Valid && result.Builder != trustedBuilder {
+				t.Fatalf("provenance from builder %q verified", result.Builder)
+			}
+		case SBOMPredicateV2, SBOMPredicateV3:
+			_, _ = verifier.verifySBOM([]AttestationData{*att})
+		}
+	})
+}
+
+// parseStatement reads the predicate of an unsigned in-toto statement
+func parseStatement(data []byte) (*AttestationData, error) {
+	var statement struct {
+		PredicateType string `json:"predicateType"`
+		Predicate     any    `json:"predicate"`
+	}
+	if err := json.Unmarshal(data, &statement); err != nil {
+		return nil, err
+	}
+	return &AttestationData{PredicateType: statement.PredicateType, Predicate: statement.Predicate}, nil
+}
@@ -0,0 +1,110 @@
+// ABOUTME: GitHub attestation API lookup for files distributed outside OCI registries
+// ABOUTME: Fetches the Sigstore bundles a repository attested for a file digest
+package attestation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/githubapi"
+	"github.com/gillisandrew/dragonglass-poc/internal/httpclient"
+)
+
+// Attestations returned per request; the API maximum
+const attestationPageSize = 100
+
+var (
+	repositoryPattern = regexp.MustCompile(`^[A-Za-z0-9-]+/[A-Za-z0-9._-]+$`)
+	digestPattern     = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+)
+
+// VerifyFileAttestations verifies the attestations repository ("owner/name") published for
+// the file with artifactDigest ("sha256:<hex>"), e.g. a main.js attached to a GitHub
+// release. Bundles must be signed for artifactDigest, and provenance must come from the
+// trusted builder as for OCI artifacts.
+func (v *AttestationVerifier) VerifyFileAttestations(ctx context.Context, repository, artifactDigest string) (*VerificationResult, error) {
+	result := &VerificationResult{
+		Found:          false,
+		Valid:          false,
+		ArtifactDigest: artifactDigest,
+		TrustedBuilder: v.trustedBuilder,
+	}
+
+	blobs, err := v.fetchFileAttestations(ctx, repository, artifactDigest)
+	if err != nil {
+		if transientFetch(err) {
+			return nil, dgerrors.Mark(err, dgerrors.ErrTransientVerification)
+		}
+		return nil, err
+	}
+	if len(blobs) == 0 {
+		result.recordFound()
+		return result, nil
+	}
+
+	result.Found = true
+	v.verifyBlobs(result, blobs)
+	result.recordFound()
+	return result, nil
+}
+
+// fetchFileAttestations returns the bundles repository attested for artifactDigest, or
+// none when it has not attested the digest
+func (v *AttestationVerifier) fetchFileAttestations(ctx context.Context, repository, artifactDigest string) ([][]byte, error) {
+	if !repositoryPattern.MatchString(repository) {
+		return nil, fmt.Errorf("invalid repository %q, expected owner/name", repository)
+	}
+	if !digestPattern.MatchString(artifactDigest) {
+		return nil, fmt.Errorf("invalid digest %q, expected sha256:<hex>", artifactDigest)
+	}
+
+	path := fmt.Sprintf("/repos/%s/attestations/%s?per_page=%d", repository, url.PathEscape(artifactDigest), attestationPageSize)
+
+	var blobs [][]byte
+	err := httpclient.Retry(ctx, v.fetchRetryOpts(), func(ctx context.Context) error {
+		var err error
+		blobs, err = v.queryAttestations(ctx, path, repository)
+		return err
+	})
+	return blobs, err
+}
+
+// queryAttestations makes a single request for the bundles listed at path. Error
+// statuses other than 404 are returned as httpclient.StatusErrors.
+func (v *AttestationVerifier) queryAttestations(ctx context.Context, path, repository string) ([][]byte, error) {
+	resp, err := v.github.Get(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attestations for %s: %w", repository, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, githubapi.StatusError(resp, "attestation", repository)
+	}
+
+	var response struct {
+		Attestations []struct {
+			Bundle json.RawMessage `json:"bundle"`
+		} `json:"attestations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode attestation response: %w", err)
+	}
+
+	blobs := make([][]byte, 0, len(response.Attestations))
+	for _, attestation := range response.Attestations {
+		if len(attestation.Bundle) > 0 {
+			blobs = append(blobs, attestation.Bundle)
+		}
+	}
+	return blobs, nil
+}
@@ -0,0 +1,140 @@
+package attestation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/githubapi"
+	"github.com/gillisandrew/dragonglass-poc/internal/httpclient"
+)
+
+const fileDigest = "sha256:60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"
+
+func newTestAPIVerifier(t *testing.T, handler http.HandlerFunc) *AttestationVerifier {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return &AttestationVerifier{
+		token:          "test-token",
+		trustedBuilder: "https://github.com/actions/runner",
+		github:         githubapi.NewClient(githubapi.DefaultOpts().WithAPIURL(server.URL).WithToken("test-token").WithHTTPClient(server.Client())),
+		retryOpts:      httpclient.DefaultRetryOpts().WithDelay(time.Millisecond, time.Millisecond).WithRetryable(transientFetch),
+	}
+}
+
+func TestFetchFileAttestations(t *testing.T) {
+	tests := []struct {
+		name       string
+		repository string
+		digest     string
+		status     int
+		body       string
+		expected   int
+		errorMsg   string
+	}{
+		{
+			name:       "attested",
+			repository: "owner/plugin",
+			digest:     fileDigest,
+			status:     http.StatusOK,
+			body:       `{"attestations": [{"bundle": {"mediaType": "application/vnd.dev.sigstore.bundle.v0.3+json"}, "repository_id": 1}, {"bundle": {}}]}`,
+			expected:   2,
+		},
+		{name: "not attested", repository: "owner/plugin", digest: fileDigest, status: http.StatusNotFound, body: `{"message": "Not Found"}`},
+		{name: "server error", repository: "owner/plugin", digest: fileDigest, status: http.StatusInternalServerError, body: "boom", errorMsg: "500"},
+		{name: "invalid response", repository: "owner/plugin", digest: fileDigest, status: http.StatusOK, body: "[", errorMsg: "failed to decode"},
+		{name: "invalid repository", repository: "owner/plugin/extra", digest: fileDigest, errorMsg: "invalid repository"},
+		{name: "invalid digest", repository: "owner/plugin", digest: "sha256:abc", errorMsg: "invalid digest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := newTestAPIVerifier(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/repos/owner/plugin/attestations/"+fileDigest {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				if auth := r.Header.Get("Authorization"); auth != "Bearer test-token" {
+					t.Errorf("unexpected authorization %q", auth)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+
+			blobs, err := verifier.fetchFileAttestations(context.Background(), tt.repository, tt.digest)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(blobs) != tt.expected {
+				t.Errorf("expected %d bundles, got %d", tt.expected, len(blobs))
+			}
+		})
+	}
+}
+
+func TestVerifyFileAttestationsNotFound(t *testing.T) {
+	verifier := newTestAPIVerifier(t, func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	result, err := verifier.VerifyFileAttestations(context.Background(), "owner/plugin", fileDigest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Found || result.ArtifactDigest != fileDigest {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if !errors.Is(result.Err(), dgerrors.ErrAttestationNotFound) {
+		t.Errorf("expected attestations not found, got %v", result.Err())
+	}
+}
+
+func TestFetchFileAttestationsRetries(t *testing.T) {
+	tests := []struct {
+		name            string
+		failures        int
+		status          int
+		expectCalls     int
+		expectTransient bool
+	}{
+		{name: "recovers from a server error", failures: 1, status: http.StatusBadGateway, expectCalls: 2},
+		{name: "gives up on repeated server errors", failures: 5, status: http.StatusServiceUnavailable, expectCalls: 3, expectTransient: true},
+		{name: "does not retry client errors", failures: 5, status: http.StatusForbidden, expectCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			verifier := newTestAPIVerifier(t, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls <= tt.failures {
+					w.WriteHeader(tt.status)
+					return
+				}
+				_, _ = w.Write([]byte(`{"attestations": []}`))
+			})
+
+			_, err := verifier.VerifyFileAttestations(context.Background(), "owner/plugin", fileDigest)
+			if calls != tt.expectCalls {
+				t.Errorf("expected %d requests, got %d", tt.expectCalls, calls)
+			}
+			if errors.Is(err, dgerrors.ErrTransientVerification) != tt.expectTransient {
+				t.Errorf("expected transient=%v, got %v", tt.expectTransient, err)
+			}
+			if tt.failures < tt.expectCalls && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
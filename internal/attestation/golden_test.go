@@ -0,0 +1,105 @@
+package attestation
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+const (
+	goldenDigest  = "sha256:4f3c2b1a0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b"
+	goldenBuilder = "https://github.com/gillisandrew/dragonglass-poc/.github/workflows/build.yml@refs/heads/main"
+)
+
+// goldenResult returns a result recorded the way verifyBlobs and VerifyArtifact record
+// one, for provenance from builder and an optional SBOM
+func goldenResult(builder string, sbom *SBOMResult) *VerificationResult {
+	result := &VerificationResult{
+		Found:          true,
+		ArtifactDigest: goldenDigest,
+		TrustedBuilder: goldenBuilder,
+		SLSA: &SLSAResult{
+			Valid:        builder == goldenBuilder,
+			Repository:   "gillisandrew/dragonglass-poc",
+			Workflow:     ".github/workflows/build.yml",
+			Builder:      builder,
+			Digest:       goldenDigest,
+			SourceURI:    "git+https://github.com/gillisandrew/dragonglass-poc@refs/heads/main",
+			SourceCommit: "38d5d3fa799e1eec8f10b137eee8b2cbdc73b534",
+		},
+		SBOM: sbom,
+	}
+	result.Valid = result.SLSA.Valid
+	bundles := 1
+	if sbom != nil {
+		bundles++
+	}
+	result.recordContentChecks(bundles, nil, nil, nil)
+	result.recordFound()
+	return result
+}
+
+// goldenScenarios are the representative outcomes whose renderings are pinned by golden
+// files, keyed by file name stem
+func goldenScenarios() map[string]*VerificationResult {
+	return map[string]*VerificationResult{
+		"valid": goldenResult(goldenBuilder, &SBOMResult{Valid: true, Format: "SPDX-2.3", Components: 42}),
+		"untrusted-builder": goldenResult("https://github.com/someone-else/fork/.github/workflows/build.yml@refs/heads/main",
+			&SBOMResult{Valid: true, Format: "SPDX-2.3", Components: 42}),
+		"missing-sbom": goldenResult(goldenBuilder, nil),
+		"vulnerable": goldenResult(goldenBuilder, &SBOMResult{
+			Valid:      true,
+			Format:     "SPDX-2.3",
+			Components: 42,
+			Vulnerabilities: []Vulnerability{
+				{ID: "GHSA-4w2v-q235-vp99", Severity: "HIGH", Component: "axios", Version: "0.21.1", Description: "Server-Side Request Forgery", References: []string{"https://github.com/advisories/GHSA-4w2v-q235-vp99"}},
+				{ID: "CVE-2022-25883", Severity: "MEDIUM", Component: "semver", Version: "7.3.7", Description: "Regular expression denial of service"},
+			},
+		}),
+	}
+}
+
+// TestGoldenRenderings pins the text and JSON renderings of verification results, so
+// changes to either are deliberate and show up in review. Run go test with -update to
+// rewrite the golden files after an intended change.
+func TestGoldenRenderings(t *testing.T) {
+	verifier := &AttestationVerifier{}
+
+	for name, result := range goldenScenarios() {
+		t.Run(name, func(t *testing.T) {
+			assertGolden(t, name+".txt", []byte(verifier.FormatVerificationResult(result)))
+
+			data, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertGolden(t, name+".json", append(data, '\n'))
+		})
+	}
+}
+
+// assertGolden compares got with testdata/golden/name, or rewrites the file with -update
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name)
+
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run go test -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s does not match its golden file; if the change is intended, run go test -update and review the diff\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
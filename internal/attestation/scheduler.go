@@ -0,0 +1,61 @@
+// ABOUTME: Bounded-concurrency scheduler for verifying many plugins in one command
+// ABOUTME: Runs one job per plugin and isolates each job's result, including panics
+package attestation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Scheduler runs per-plugin verification jobs with at most a fixed number in flight.
+// Jobs should share one AttestationVerifier so the Sigstore trusted root is fetched once.
+type Scheduler struct {
+	concurrency int
+}
+
+// NewScheduler returns a scheduler running up to concurrency jobs at once (at least one)
+func NewScheduler(concurrency int) *Scheduler {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Scheduler{concurrency: concurrency}
+}
+
+// Run calls job for each index in [0, n) and returns the errors in index order. A job that
+// panics fails with an error instead of taking down the other jobs, and jobs that have not
+// started when ctx is cancelled fail with the context error.
+func (s *Scheduler) Run(ctx context.Context, n int, job func(ctx context.Context, i int) error) []error {
+	errs := make([]error, n)
+	slots := make(chan struct{}, s.concurrency)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			errs[i] = runJob(ctx, i, job)
+		}(i)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// runJob calls job, converting a panic into an error
+func runJob(ctx context.Context, i int, job func(ctx context.Context, i int) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("verification panicked: %v", r)
+		}
+	}()
+	return job(ctx, i)
+}
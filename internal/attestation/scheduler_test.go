@@ -0,0 +1,73 @@
+// ABOUTME: Unit tests for the bounded-concurrency verification scheduler
+// ABOUTME: Covers the concurrency limit, result ordering, panic isolation, and cancellation
+package attestation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSchedulerRun(t *testing.T) {
+	var running, peak int32
+	errs := NewScheduler(3).Run(context.Background(), 10, func(ctx context.Context, i int) error {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			previous := atomic.LoadInt32(&peak)
+			if current <= previous || atomic.CompareAndSwapInt32(&peak, previous, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		switch i {
+		case 4:
+			return fmt.Errorf("plugin %d failed", i)
+		case 7:
+			panic("boom")
+		}
+		return nil
+	})
+
+	if peak > 3 {
+		t.Errorf("expected at most 3 concurrent jobs, saw %d", peak)
+	}
+	if len(errs) != 10 {
+		t.Fatalf("expected 10 results, got %d", len(errs))
+	}
+	for i, err := range errs {
+		switch i {
+		case 4:
+			if err == nil || err.Error() != "plugin 4 failed" {
+				t.Errorf("expected job 4 error, got %v", err)
+			}
+		case 7:
+			if err == nil || !strings.Contains(err.Error(), "panicked") {
+				t.Errorf("expected job 7 panic to be reported, got %v", err)
+			}
+		default:
+			if err != nil {
+				t.Errorf("unexpected error for job %d: %v", i, err)
+			}
+		}
+	}
+}
+
+func TestSchedulerCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	errs := NewScheduler(0).Run(ctx, 3, func(ctx context.Context, i int) error {
+		return ctx.Err()
+	})
+	for i, err := range errs {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected job %d to be cancelled, got %v", i, err)
+		}
+	}
+}
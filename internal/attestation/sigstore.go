@@ -4,19 +4,18 @@ package attestation
 
 import (
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/sigstore/sigstore-go/pkg/bundle"
 	"github.com/sigstore/sigstore-go/pkg/fulcio/certificate"
-	"github.com/sigstore/sigstore-go/pkg/root"
 	"github.com/sigstore/sigstore-go/pkg/verify"
 )
 
 // parseSignstoreBundle extracts and cryptographically verifies attestation data from a sigstore bundle
 func (v *AttestationVerifier) parseSignstoreBundle(bundle *bundle.Bundle, artifactDigest string) (*AttestationData, error) {
 	// Perform full sigstore cryptographic verification
+	var verificationResult *verify.VerificationResult
 	if v.verifier != nil {
 		// Prepare artifact digest for verification
 		var artifactOpt verify.ArtifactPolicyOption
@@ -69,13 +68,10 @@ func (v *AttestationVerifier) parseSignstoreBundle(bundle *bundle.Bundle, artifa
 
 		// Perform cryptographic verification of the sigstore bundle
 		// This validates signatures, certificates, SCTs, and transparency log entries
-		verificationResult, err := v.verifier.Verify(bundle, policyBuilder)
+		verificationResult, err = v.verifier.Verify(bundle, policyBuilder)
 		if err != nil {
 			return nil, fmt.Errorf("sigstore bundle verification failed: %w", err)
 		}
-
-		// Verification succeeded - we now have cryptographically verified attestation
-		_ = verificationResult // Successful verification
 	}
 
 	// Extract DSSE envelope from bundle
@@ -93,50 +89,6 @@ func (v *AttestationVerifier) parseSignstoreBundle(bundle *bundle.Bundle, artifa
 	return &AttestationData{
 		PredicateType: statement.PredicateType,
 		Predicate:     statement.Predicate,
+		Bundle:        bundleEvidence(bundle, verificationResult),
 	}, nil
 }
-
-// parseRawAttestation parses raw JSON attestation data
-func (v *AttestationVerifier) parseRawAttestation(data []byte) (*AttestationData, error) {
-	var rawAttestation struct {
-		PredicateType string `json:"predicateType"`
-		Predicate     any    `json:"predicate"`
-	}
-
-	if err := json.Unmarshal(data, &rawAttestation); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal raw attestation: %w", err)
-	}
-
-	return &AttestationData{
-		PredicateType: rawAttestation.PredicateType,
-		Predicate:     rawAttestation.Predicate,
-	}, nil
-}
-
-// newSigstoreVerifier creates a sigstore verifier with production trust roots (Fulcio, Rekor)
-func newSigstoreVerifier() (*verify.Verifier, error) {
-	// Fetch the production trust root from Sigstore TUF repository
-	// This includes Fulcio CA certificates and Rekor public keys
-	trustedMaterial, err := root.FetchTrustedRoot()
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch sigstore trusted root: %w", err)
-	}
-
-	// Create verifier with production sigstore configuration
-	// This uses the public Fulcio CA and Rekor transparency log for GitHub Actions
-	verifierConfig := []verify.VerifierOption{
-		// Require SCT (certificate transparency) verification
-		verify.WithSignedCertificateTimestamps(1),
-		// Require transparency log verification
-		verify.WithTransparencyLog(1),
-		// Use integrated timestamps from Rekor for certificate validation
-		verify.WithIntegratedTimestamps(1),
-	}
-
-	verifier, err := verify.NewVerifier(trustedMaterial, verifierConfig...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create sigstore verifier with production trust roots: %w", err)
-	}
-
-	return verifier, nil
-}
@@ -1,5 +1,5 @@
 // ABOUTME: SLSA provenance verification using in-toto attestation primitives
-// ABOUTME: Validates SLSA provenance attestations and extracts build metadata
+// ABOUTME: Validates SLSA v1 and v0.2 provenance attestations and extracts build metadata
 package attestation
 
 import (
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 
+	v02 "github.com/in-toto/attestation/go/predicates/provenance/v02"
 	v1 "github.com/in-toto/attestation/go/predicates/provenance/v1"
 	"google.golang.org/protobuf/encoding/protojson"
 )
@@ -21,8 +22,16 @@ func (v *AttestationVerifier) verifySLSA(attestations []AttestationData) (*SLSAR
 		return result, nil
 	}
 
-	// Process the first SLSA attestation
+	// Process the first SLSA attestation, preferring SLSA v1 when a builder also attested
+	// in the older format
 	att := attestations[0]
+	for _, candidate := range attestations {
+		if candidate.PredicateType == SLSAPredicateV1 {
+			att = candidate
+			break
+		}
+	}
+	result.PredicateType = att.PredicateType
 
 	// Parse SLSA provenance predicate using protojson
 	predicateBytes, err := json.Marshal(att.Predicate)
@@ -30,6 +39,10 @@ func (v *AttestationVerifier) verifySLSA(attestations []AttestationData) (*SLSAR
 		return result, fmt.Errorf("failed to marshal predicate: %w", err)
 	}
 
+	if att.PredicateType == SLSAPredicateV02 {
+		return v.verifySLSAV02(result, predicateBytes)
+	}
+
 	var provenance v1.Provenance
 	if err := protojson.Unmarshal(predicateBytes, &provenance); err != nil {
 		return result, fmt.Errorf("failed to unmarshal SLSA provenance: %w", err)
@@ -58,17 +71,13 @@ func (v *AttestationVerifier) verifySLSA(attestations []AttestationData) (*SLSAR
 		// Extract repository information from metadata for informational purposes
 		metadata := runDetails.GetMetadata()
 		if metadata != nil {
-			invocationId := metadata.GetInvocationId()
-			if strings.Contains(invocationId, "github.com/") {
-				// Extract repository from URL path: https://github.com/owner/repo/actions/runs/123
-				parts := strings.Split(invocationId, "/")
-				for i, part := range parts {
-					if part == "github.com" && i+2 < len(parts) {
-						result.Repository = fmt.Sprintf("%s/%s", parts[i+1], parts[i+2])
-						break
-					}
-				}
+			if startedOn := metadata.GetStartedOn(); startedOn != nil {
+				started := startedOn.AsTime()
+				result.BuildStartedOn = &started
 			}
+
+			// Extract repository from URL path: https://github.com/owner/repo/actions/runs/123
+			result.Repository = githubRepository(metadata.GetInvocationId())
 		}
 	}
 
@@ -90,5 +99,69 @@ func (v *AttestationVerifier) verifySLSA(attestations []AttestationData) (*SLSAR
 		}
 	}
 
+	// Record the source revision the build checked out
+	if buildDef := provenance.GetBuildDefinition(); buildDef != nil {
+		for _, dep := range buildDef.GetResolvedDependencies() {
+			if commit := dep.GetDigest()["gitCommit"]; commit != "" {
+				result.SourceURI = dep.GetUri()
+				result.SourceCommit = commit
+				break
+			}
+		}
+	}
+
 	return result, nil
 }
+
+// verifySLSAV02 maps an SLSA v0.2 provenance predicate onto result: the builder from
+// builder.id, and the source repository, revision, and workflow from
+// invocation.configSource
+func (v *AttestationVerifier) verifySLSAV02(result *SLSAResult, predicate []byte) (*SLSAResult, error) {
+	// Builders filled the v0.2 format inconsistently, so fields outside the schema are ignored
+	var provenance v02.Provenance
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(predicate, &provenance); err != nil {
+		return result, fmt.Errorf("failed to unmarshal SLSA v0.2 provenance: %w", err)
+	}
+	if provenance.GetBuilder().GetId() == "" {
+		return result, fmt.Errorf("SLSA v0.2 provenance has no builder.id")
+	}
+	if provenance.GetBuildType() == "" {
+		return result, fmt.Errorf("SLSA v0.2 provenance has no buildType")
+	}
+
+	result.Builder = provenance.GetBuilder().GetId()
+	result.Valid = v.trustedBuilder != "" && result.Builder == v.trustedBuilder
+
+	if source := provenance.GetInvocation().GetConfigSource(); source != nil {
+		result.SourceURI = source.GetUri()
+		result.Workflow = source.GetEntryPoint()
+		result.Repository = githubRepository(source.GetUri())
+		// v0.2 builders record the commit as a sha1 digest
+		result.SourceCommit = source.GetDigest()["sha1"]
+		if result.SourceCommit == "" {
+			result.SourceCommit = source.GetDigest()["gitCommit"]
+		}
+	}
+	if startedOn := provenance.GetMetadata().GetBuildStartedOn(); startedOn != nil {
+		started := startedOn.AsTime()
+		result.BuildStartedOn = &started
+	}
+	return result, nil
+}
+
+// githubRepository returns the "owner/repo" of a GitHub URL, such as
+// https://github.com/owner/repo/actions/runs/123 or
+// git+https://github.com/owner/repo@refs/heads/main, or "" for other URLs
+func githubRepository(url string) string {
+	if !strings.Contains(url, "github.com/") {
+		return ""
+	}
+	parts := strings.Split(url, "/")
+	for i, part := range parts {
+		if part == "github.com" && i+2 < len(parts) {
+			repo, _, _ := strings.Cut(parts[i+2], "@")
+			return fmt.Sprintf("%s/%s", parts[i+1], strings.TrimSuffix(repo, ".git"))
+		}
+	}
+	return ""
+}
@@ -3,14 +3,21 @@
 package attestation
 
 import (
+	"encoding/json"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/findings"
+
 	v1 "github.com/in-toto/attestation/go/predicates/provenance/v1"
 )
 
 const (
 	// Predicate types for different attestation formats
 	SLSAPredicateV1 = "https://slsa.dev/provenance/v1"
-	SBOMPredicateV2 = "https://spdx.dev/Document/v2.3"
-	SBOMPredicateV3 = "https://spdx.dev/Document/v3.0"
+	// Emitted by older builders; mapped onto the fields of SLSA v1 with a warning
+	SLSAPredicateV02 = "https://slsa.dev/provenance/v0.2"
+	SBOMPredicateV2  = "https://spdx.dev/Document/v2.3"
+	SBOMPredicateV3  = "https://spdx.dev/Document/v3.0"
 )
 
 // AttestationType represents the type of attestation
@@ -21,26 +28,128 @@ const (
 	AttestationTypeSBOM AttestationType = "sbom"
 )
 
+// Verification checks, in the order they are recorded
+const (
+	// Attestations were found for the artifact
+	CheckAttestations = "attestations"
+	// Every Sigstore bundle verifies against the artifact's digest
+	CheckSignature = "signature"
+	// A well-formed SLSA provenance attestation is present
+	CheckProvenance = "provenance"
+	// The provenance names the trusted builder
+	CheckTrustedBuilder = "trusted-builder"
+	// An SBOM attestation is present
+	CheckSBOM = "sbom"
+	// The SBOM lists no high or critical vulnerabilities
+	CheckVulnerabilities = "vulnerabilities"
+	// The provenance matches the builder and repository a plugin's policy pins
+	CheckPluginPolicy = "policy"
+)
+
+// CheckStatus is the outcome of a single check
+type CheckStatus string
+
+const (
+	CheckPassed  CheckStatus = "passed"
+	CheckFailed  CheckStatus = "failed"
+	CheckSkipped CheckStatus = "skipped"
+)
+
+// Severity says what a failed check means for verification
+type Severity string
+
+const (
+	// Fails verification in every mode: the attestations are forged, tampered with, or
+	// contradict an explicit policy
+	SeverityHard Severity = "hard"
+	// Fails verification in strict mode and is a warning otherwise: trust could not be
+	// established, but nothing contradicts it
+	SeveritySoft Severity = "soft"
+	// Never fails verification and is reported as a warning
+	SeverityWarning Severity = "warning"
+)
+
+// Check is the result of one verification check
+type Check struct {
+	Name     string      `json:"name"`
+	Status   CheckStatus `json:"status"`
+	Severity Severity    `json:"severity"`
+	Detail   string      `json:"detail,omitempty"`
+}
+
 // VerificationResult contains comprehensive verification results for all attestation types
 type VerificationResult struct {
-	Found          bool              `json:"found"`
-	Valid          bool              `json:"valid"`
-	Errors         []string          `json:"errors"`
-	Warnings       []string          `json:"warnings"`
+	Found bool `json:"found"`
+
+	// Provenance from the trusted builder was verified and no check failed hard
+	Valid bool `json:"valid"`
+
+	// Outcome of each check; strict mode decisions, errors, and exit codes are derived
+	// from these
+	Checks []Check `json:"checks"`
+
+	// Errors and warnings raised while verifying, tagged with the check that raised them.
+	// Rendered as the errors, warnings, and findings fields.
+	Findings findings.Collector `json:"-"`
+
 	SLSA           *SLSAResult       `json:"slsa,omitempty"`
 	SBOM           *SBOMResult       `json:"sbom,omitempty"`
 	Results        []AttestationData `json:"rawResults,omitempty"`
 	ArtifactDigest string            `json:"artifactDigest"`
+	TrustedBuilder string            `json:"trustedBuilder,omitempty"`
+
+	// Image index the artifact's manifest was selected from, for multi-arch artifacts
+	IndexDigest string `json:"indexDigest,omitempty"`
+
+	// Attestations could not be fetched because the registry or attestation API kept
+	// failing, so a failed attestations check says nothing about the artifact
+	Transient bool `json:"transient,omitempty"`
+
+	// Attestations exactly as fetched, kept so they can be archived for audits
+	Blobs [][]byte `json:"-"`
+}
+
+// Errors returns the messages of the errors raised while verifying
+func (r *VerificationResult) Errors() []string {
+	return findings.Messages(r.Findings.Errors())
+}
+
+// Warnings returns the messages of the warnings raised while verifying
+func (r *VerificationResult) Warnings() []string {
+	return findings.Messages(r.Findings.Warnings())
+}
+
+// MarshalJSON renders the findings both as the errors and warnings message lists and
+// as structured findings naming their plugin and check
+func (r *VerificationResult) MarshalJSON() ([]byte, error) {
+	type fields VerificationResult
+	return json.Marshal(struct {
+		*fields
+		Errors   []string           `json:"errors"`
+		Warnings []string           `json:"warnings"`
+		Findings []findings.Finding `json:"findings"`
+	}{(*fields)(r), r.Errors(), r.Warnings(), r.Findings.All()})
 }
 
 // SLSAResult contains SLSA-specific verification details
 type SLSAResult struct {
+	// SLSAPredicateV1, or SLSAPredicateV02 for provenance in the older format
+	PredicateType string `json:"predicateType,omitempty"`
+
 	Valid      bool           `json:"valid"`
 	Repository string         `json:"repository"`
 	Workflow   string         `json:"workflow"`
 	Builder    string         `json:"builder"`
 	Digest     string         `json:"digest"`
 	Provenance *v1.Provenance `json:"provenance,omitempty"`
+
+	// Source revision the build checked out, from the provenance's resolved dependencies,
+	// e.g. "git+https://github.com/owner/repo@refs/heads/main" at a commit SHA
+	SourceURI    string `json:"sourceUri,omitempty"`
+	SourceCommit string `json:"sourceCommit,omitempty"`
+
+	// When the build started, from the provenance's run details
+	BuildStartedOn *time.Time `json:"buildStartedOn,omitempty"`
 }
 
 // SBOMResult contains SBOM-specific verification and vulnerability details
@@ -65,4 +174,8 @@ type Vulnerability struct {
 type AttestationData struct {
 	PredicateType string `json:"predicateType"`
 	Predicate     any    `json:"predicate"`
+
+	// Signing identity and transparency log entries, when the attestation arrived in a
+	// Sigstore bundle
+	Bundle *BundleEvidence `json:"bundle,omitempty"`
 }
@@ -5,16 +5,20 @@ package attestation
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"time"
 
 	"github.com/sigstore/sigstore-go/pkg/bundle"
 	"github.com/sigstore/sigstore-go/pkg/verify"
 	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/errcode"
 
+	"github.com/gillisandrew/dragonglass-poc/internal/githubapi"
+	"github.com/gillisandrew/dragonglass-poc/internal/httpclient"
 	"github.com/gillisandrew/dragonglass-poc/internal/oci"
+	"github.com/gillisandrew/dragonglass-poc/internal/sigstore"
 )
 
 // AttestationVerifier handles verification of multiple attestation types using OCI attestation discovery
@@ -23,16 +27,20 @@ type AttestationVerifier struct {
 	httpClient     *http.Client
 	verifier       *verify.Verifier
 	trustedBuilder string
+
+	// GitHub REST API client for attestations of files outside OCI registries
+	github *githubapi.Client
+
+	// Retries of attestation fetches that fail transiently (default: DefaultRetryOpts)
+	retryOpts *httpclient.RetryOpts
 }
 
 // NewAttestationVerifier creates a new attestation verifier with sigstore verification
 func NewAttestationVerifier(token string, trustedBuilder string) (*AttestationVerifier, error) {
-	httpClient := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	httpClient := httpclient.New(httpclient.DefaultOpts())
 
-	// Initialize sigstore verifier with production trust roots
-	sigstoreVerifier, err := newSigstoreVerifier()
+	// Share the process-wide sigstore verifier so the trusted root is fetched once
+	sigstoreVerifier, err := sigstore.SharedVerifier()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create sigstore verifier: %w", err)
 	}
@@ -42,22 +50,21 @@ func NewAttestationVerifier(token string, trustedBuilder string) (*AttestationVe
 		httpClient:     httpClient,
 		verifier:       sigstoreVerifier,
 		trustedBuilder: trustedBuilder,
+		github:         githubapi.NewClient(githubapi.DefaultOpts().WithToken(token).WithHTTPClient(httpClient)),
+		retryOpts:      httpclient.DefaultRetryOpts().WithRetryable(transientFetch),
 	}, nil
 }
 
 // VerifyAttestations discovers and verifies all attestations for an OCI artifact
 func (v *AttestationVerifier) VerifyAttestations(ctx context.Context, imageRef string) (*VerificationResult, error) {
-	result := &VerificationResult{
-		Found:    false,
-		Valid:    false,
-		Errors:   []string{},
-		Warnings: []string{},
-	}
+	result := v.newResult()
+	// Every return below hands back result, so its checks are complete once it returns
+	defer result.recordFound()
 
 	// Parse the image reference
 	ref, err := registry.ParseReference(imageRef)
 	if err != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("invalid image reference: %v", err))
+		result.Findings.Errorf(CheckAttestations, "invalid image reference: %v", err)
 		return result, nil
 	}
 
@@ -65,91 +72,188 @@ func (v *AttestationVerifier) VerifyAttestations(ctx context.Context, imageRef s
 	ghcrRegistry := &oci.GHCRRegistry{Token: v.token}
 	repo, err := ghcrRegistry.GetRepositoryFromRef(imageRef)
 	if err != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("failed to create repository: %v", err))
+		result.Findings.Errorf(CheckAttestations, "failed to create repository: %v", err)
 		return result, nil
 	}
 
-	// Resolve the reference to get the actual digest
-	desc, err := repo.Resolve(ctx, ref.Reference)
+	// Resolve the reference to get the actual digest, selecting a manifest from an image index
+	desc, indexDesc, err := oci.ResolveManifest(ctx, repo, ref.Reference)
 	if err != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("failed to resolve reference: %v", err))
+		result.Findings.Errorf(CheckAttestations, "failed to resolve reference: %v", err)
 		return result, nil
 	}
 
-	result.ArtifactDigest = desc.Digest.String()
+	v.verifyResolved(ctx, result, &oci.Artifact{Repository: repo, Descriptor: desc, Index: indexDesc})
+	return result, nil
+}
 
-	// Get OCI attestations using our existing OCI implementation
-	_, attestationReaders, err := repo.GetSLSAAttestations(ctx, desc)
-	if err != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("failed to get attestations: %v", err))
-		return result, nil
+// VerifyArtifact discovers and verifies the attestations of an artifact already resolved
+// for the operation, reading them through the artifact's repository client
+func (v *AttestationVerifier) VerifyArtifact(ctx context.Context, artifact *oci.Artifact) (*VerificationResult, error) {
+	result := v.newResult()
+
+	v.verifyResolved(ctx, result, artifact)
+	result.recordFound()
+	return result, nil
+}
+
+// VerifyBundles verifies attestations supplied out of band, such as Sigstore bundles
+// distributed as release assets, against artifactDigest and, for an artifact selected from
+// an image index, indexDigest (optional). No registry is consulted, so only blobs are
+// verified, and as for registry referrers every blob must be a signed Sigstore bundle.
+func (v *AttestationVerifier) VerifyBundles(artifactDigest, indexDigest string, blobs [][]byte) *VerificationResult {
+	result := v.newResult()
+	result.ArtifactDigest = artifactDigest
+	result.IndexDigest = indexDigest
+
+	if len(blobs) > 0 {
+		result.Found = true
+		v.verifyBlobs(result, blobs)
+	}
+	result.recordFound()
+	return result
+}
+
+// fetchRetryOpts returns the retries of attestation fetches
+func (v *AttestationVerifier) fetchRetryOpts() *httpclient.RetryOpts {
+	if v.retryOpts == nil {
+		return httpclient.DefaultRetryOpts().WithRetryable(transientFetch)
+	}
+	return v.retryOpts
+}
+
+// transientFetch reports whether a failed attestation fetch may succeed when repeated,
+// including registry responses with a transient status
+func transientFetch(err error) bool {
+	var respErr *errcode.ErrorResponse
+	if errors.As(err, &respErr) {
+		return httpclient.TransientStatus(respErr.StatusCode)
+	}
+	return httpclient.Transient(err)
+}
+
+// newResult returns an empty result for the verifier's trusted builder
+func (v *AttestationVerifier) newResult() *VerificationResult {
+	return &VerificationResult{
+		Found:          false,
+		Valid:          false,
+		TrustedBuilder: v.trustedBuilder,
+	}
+}
+
+// verifyResolved fetches and verifies the attestations naming the artifact's manifest or
+// index, recording the outcome in result
+func (v *AttestationVerifier) verifyResolved(ctx context.Context, result *VerificationResult, artifact *oci.Artifact) {
+	result.ArtifactDigest = artifact.Descriptor.Digest.String()
+
+	// Get OCI attestations using our existing OCI implementation. Publishers of multi-arch
+	// artifacts may attest the index rather than the manifest, so both are searched.
+	if artifact.Index != nil {
+		result.IndexDigest = artifact.Index.Digest.String()
+	}
+	var attestationReaders []io.ReadCloser
+	for _, subject := range artifact.Subjects() {
+		var readers []io.ReadCloser
+		err := httpclient.Retry(ctx, v.fetchRetryOpts(), func(ctx context.Context) error {
+			var err error
+			_, readers, err = artifact.Repository.GetAttestations(ctx, subject)
+			return err
+		})
+		if err != nil {
+			result.Transient = transientFetch(err)
+			result.Findings.Errorf(CheckAttestations, "failed to get attestations: %v", err)
+			return
+		}
+		attestationReaders = append(attestationReaders, readers...)
 	}
 
 	if len(attestationReaders) == 0 {
-		return result, nil
+		return
 	}
 
 	result.Found = true
 
-	// Parse all attestations from readers
-	attestations := []AttestationData{}
+	// Read all attestations
+	blobs := make([][]byte, 0, len(attestationReaders))
 	for i, reader := range attestationReaders {
 		defer func(r io.ReadCloser, index int) {
 			if err := r.Close(); err != nil {
-				result.Warnings = append(result.Warnings, fmt.Sprintf("failed to close attestation reader %d: %v", index, err))
+				result.Findings.Warnf(CheckAttestations, "failed to close attestation reader %d: %v", index, err)
 			}
 		}(reader, i)
 
 		data, err := io.ReadAll(reader)
 		if err != nil {
-			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to read attestation %d: %v", i, err))
+			result.Findings.Warnf(CheckAttestations, "failed to read attestation %d: %v", i, err)
 			continue
 		}
+		blobs = append(blobs, data)
+	}
 
-		// Try to parse as sigstore bundle first
+	v.verifyBlobs(result, blobs)
+}
+
+// verifyBlobs parses attestations, verifying Sigstore bundles against
+// result.SubjectDigests, and records the SLSA and SBOM verification results. Blobs that
+// are not Sigstore bundles are unsigned, so anyone able to push a referrer could have
+// written them; they fail the signature check instead of being read.
+func (v *AttestationVerifier) verifyBlobs(result *VerificationResult, blobs [][]byte) {
+	result.Blobs = blobs
+
+	attestations := []AttestationData{}
+	bundles := 0
+	var signatureErrors []string
+	for i, data := range blobs {
 		var sigstoreBundle bundle.Bundle
-		if err := json.Unmarshal(data, &sigstoreBundle); err == nil {
-			// Extract attestation from bundle with cryptographic verification
-			if attestationData, err := v.parseSignstoreBundle(&sigstoreBundle, result.ArtifactDigest); err == nil {
-				attestations = append(attestations, *attestationData)
-			} else {
-				result.Warnings = append(result.Warnings, fmt.Sprintf("failed to parse sigstore bundle %d: %v", i, err))
-			}
+		if err := json.Unmarshal(data, &sigstoreBundle); err != nil {
+			result.Findings.Errorf(CheckSignature, "attestation %d is not a Sigstore bundle, unsigned attestations are not accepted: %v", i, err)
+			signatureErrors = append(signatureErrors, fmt.Sprintf("attestation %d: not a Sigstore bundle", i))
+			continue
+		}
+
+		// Extract attestation from bundle with cryptographic verification
+		bundles++
+		if attestationData, err := v.parseBundleForSubjects(&sigstoreBundle, result.SubjectDigests()); err == nil {
+			attestationData.Bundle.Digest = blobDigest(data)
+			attestations = append(attestations, *attestationData)
 		} else {
-			// Try parsing as raw JSON attestation
-			if attestationData, err := v.parseRawAttestation(data); err == nil {
-				attestations = append(attestations, *attestationData)
-			} else {
-				result.Warnings = append(result.Warnings, fmt.Sprintf("failed to parse attestation %d: %v", i, err))
-			}
+			result.Findings.Warnf(CheckSignature, "failed to parse sigstore bundle %d: %v", i, err)
+			signatureErrors = append(signatureErrors, fmt.Sprintf("bundle %d: %v", i, err))
 		}
 	}
 
+	result.Results = attestations
+
 	// Process attestations by type
 	slsaAttestations := []AttestationData{}
 	sbomAttestations := []AttestationData{}
 
 	for _, att := range attestations {
 		switch att.PredicateType {
-		case SLSAPredicateV1:
+		case SLSAPredicateV1, SLSAPredicateV02:
 			slsaAttestations = append(slsaAttestations, att)
 		case SBOMPredicateV2, SBOMPredicateV3:
 			sbomAttestations = append(sbomAttestations, att)
 		default:
-			result.Warnings = append(result.Warnings, fmt.Sprintf("unknown predicate type: %s", att.PredicateType))
+			result.Findings.Warnf(CheckAttestations, "unknown predicate type: %s", att.PredicateType)
 		}
 	}
 
 	// Verify SLSA attestations
+	var slsaErr, sbomErr error
 	if len(slsaAttestations) > 0 {
 		slsaResult, err := v.verifySLSA(slsaAttestations)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("SLSA verification failed: %v", err))
+			slsaErr = fmt.Errorf("SLSA verification failed: %v", err)
+			result.Findings.Errorf(CheckProvenance, "%v", slsaErr)
 		} else {
 			result.SLSA = slsaResult
 			if slsaResult.Valid {
 				result.Valid = true
 			}
+			if slsaResult.PredicateType == SLSAPredicateV02 {
+				result.Findings.Warnf(CheckProvenance, "provenance uses the older SLSA v0.2 format, read as SLSA v1; ask the publisher to upgrade their builder")
+			}
 		}
 	}
 
@@ -157,11 +261,48 @@ func (v *AttestationVerifier) VerifyAttestations(ctx context.Context, imageRef s
 	if len(sbomAttestations) > 0 {
 		sbomResult, err := v.verifySBOM(sbomAttestations)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("SBOM verification failed: %v", err))
+			sbomErr = fmt.Errorf("SBOM verification failed: %v", err)
+			result.Findings.Errorf(CheckSBOM, "%v", sbomErr)
 		} else {
 			result.SBOM = sbomResult
 		}
 	}
 
-	return result, nil
+	result.recordContentChecks(bundles, signatureErrors, slsaErr, sbomErr)
+}
+
+// parseBundleForSubjects verifies a Sigstore bundle against each subject digest in turn and
+// returns the attestation of the first it was signed for
+func (v *AttestationVerifier) parseBundleForSubjects(b *bundle.Bundle, digests []string) (*AttestationData, error) {
+	var err error
+	for _, digest := range digests {
+		var attestationData *AttestationData
+		if attestationData, err = v.parseSignstoreBundle(b, digest); err == nil {
+			return attestationData, nil
+		}
+	}
+	return nil, err
+}
+
+// SubjectDigests returns the digests an attestation subject may name: the artifact, and
+// for multi-arch artifacts the image index it was selected from
+func (r *VerificationResult) SubjectDigests() []string {
+	if r.IndexDigest == "" {
+		return []string{r.ArtifactDigest}
+	}
+	return []string{r.ArtifactDigest, r.IndexDigest}
+}
+
+// SBOMPredicate returns the predicate of the first SPDX SBOM attestation, or nil when the
+// artifact has none
+func (r *VerificationResult) SBOMPredicate() map[string]any {
+	for _, att := range r.Results {
+		if att.PredicateType != SBOMPredicateV2 && att.PredicateType != SBOMPredicateV3 {
+			continue
+		}
+		if predicate, ok := att.Predicate.(map[string]any); ok {
+			return predicate
+		}
+	}
+	return nil
 }
@@ -4,9 +4,19 @@ package attestation
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"slices"
 	"strings"
 	"testing"
 	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/findings"
+	"github.com/gillisandrew/dragonglass-poc/internal/mock"
+	"github.com/gillisandrew/dragonglass-poc/internal/oci"
 )
 
 func TestNewAttestationVerifier(t *testing.T) {
@@ -43,6 +53,9 @@ func TestVerifySLSA(t *testing.T) {
 		expectValid   bool
 		expectBuilder string
 		expectRepo    string
+		expectCommit  string
+		expectStarted string
+		expectVersion string
 		expectError   bool
 	}{
 		{
@@ -59,11 +72,20 @@ func TestVerifySLSA(t *testing.T) {
 									"repository": "github.com/owner/repo",
 								},
 							},
+							"resolvedDependencies": []interface{}{
+								map[string]interface{}{
+									"uri":    "git+https://github.com/owner/repo@refs/heads/main",
+									"digest": map[string]interface{}{"gitCommit": "77ab745aee787d519642a87ed8f68be12fdc4b0d"},
+								},
+							},
 						},
 						"runDetails": map[string]interface{}{
 							"builder": map[string]interface{}{
 								"id": "https://github.com/actions/runner",
 							},
+							"metadata": map[string]interface{}{
+								"startedOn": "2026-03-04T05:06:07Z",
+							},
 						},
 					},
 				},
@@ -71,8 +93,79 @@ func TestVerifySLSA(t *testing.T) {
 			expectValid:   true,
 			expectBuilder: "https://github.com/actions/runner",
 			expectRepo:    "github.com/owner/repo",
+			expectCommit:  "77ab745aee787d519642a87ed8f68be12fdc4b0d",
+			expectStarted: "2026-03-04T05:06:07Z",
+			expectVersion: SLSAPredicateV1,
 			expectError:   false,
 		},
+		{
+			name: "SLSA v0.2 from trusted builder",
+			attestations: []AttestationData{
+				{
+					PredicateType: SLSAPredicateV02,
+					Predicate: map[string]interface{}{
+						"builder":   map[string]interface{}{"id": "https://github.com/actions/runner"},
+						"buildType": "https://github.com/slsa-framework/slsa-github-generator/generic@v1",
+						"invocation": map[string]interface{}{
+							"configSource": map[string]interface{}{
+								"uri":        "git+https://github.com/owner/repo@refs/heads/main",
+								"digest":     map[string]interface{}{"sha1": "77ab745aee787d519642a87ed8f68be12fdc4b0d"},
+								"entryPoint": ".github/workflows/release.yml",
+							},
+						},
+						"metadata": map[string]interface{}{
+							"buildInvocationId": "https://github.com/owner/repo/actions/runs/123/attempts/1",
+							"buildStartedOn":    "2026-03-04T05:06:07Z",
+						},
+						"builderSpecific": "ignored",
+					},
+				},
+			},
+			expectValid:   true,
+			expectBuilder: "https://github.com/actions/runner",
+			expectRepo:    "owner/repo",
+			expectCommit:  "77ab745aee787d519642a87ed8f68be12fdc4b0d",
+			expectStarted: "2026-03-04T05:06:07Z",
+			expectVersion: SLSAPredicateV02,
+		},
+		{
+			name: "SLSA v0.2 without builder",
+			attestations: []AttestationData{
+				{
+					PredicateType: SLSAPredicateV02,
+					Predicate:     map[string]interface{}{"buildType": "https://example.com/build"},
+				},
+			},
+			expectVersion: SLSAPredicateV02,
+			expectError:   true,
+		},
+		{
+			name: "SLSA v1 preferred over v0.2",
+			attestations: []AttestationData{
+				{
+					PredicateType: SLSAPredicateV02,
+					Predicate: map[string]interface{}{
+						"builder":   map[string]interface{}{"id": "https://example.com/old-builder"},
+						"buildType": "https://example.com/build",
+					},
+				},
+				{
+					PredicateType: SLSAPredicateV1,
+					Predicate: map[string]interface{}{
+						"buildDefinition": map[string]interface{}{
+							"buildType":          "https://example.com/build",
+							"externalParameters": map[string]interface{}{"workflow": map[string]interface{}{"repository": "owner/repo"}},
+						},
+						"runDetails": map[string]interface{}{
+							"builder": map[string]interface{}{"id": "https://github.com/actions/runner"},
+						},
+					},
+				},
+			},
+			expectValid:   true,
+			expectBuilder: "https://github.com/actions/runner",
+			expectVersion: SLSAPredicateV1,
+		},
 		{
 			name: "untrusted builder",
 			attestations: []AttestationData{
@@ -145,10 +238,41 @@ func TestVerifySLSA(t *testing.T) {
 			if tt.expectRepo != "" && result.Repository != tt.expectRepo {
 				t.Errorf("Expected repository %s, got %s", tt.expectRepo, result.Repository)
 			}
+
+			if result.SourceCommit != tt.expectCommit {
+				t.Errorf("Expected source commit %q, got %q", tt.expectCommit, result.SourceCommit)
+			}
+
+			var started string
+			if result.BuildStartedOn != nil {
+				started = result.BuildStartedOn.UTC().Format(time.RFC3339)
+			}
+			if started != tt.expectStarted {
+				t.Errorf("Expected build start %q, got %q", tt.expectStarted, started)
+			}
+
+			if tt.expectVersion != "" && result.PredicateType != tt.expectVersion {
+				t.Errorf("Expected predicate type %q, got %q", tt.expectVersion, result.PredicateType)
+			}
 		})
 	}
 }
 
+func TestGitHubRepository(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/owner/repo/actions/runs/123":       "owner/repo",
+		"git+https://github.com/owner/repo@refs/heads/main":    "owner/repo",
+		"git+https://github.com/owner/repo.git@refs/tags/v1.0": "owner/repo",
+		"https://gitlab.com/owner/repo":                        "",
+		"https://github.com/owner":                             "",
+	}
+	for url, expected := range tests {
+		if got := githubRepository(url); got != expected {
+			t.Errorf("%s: expected %q, got %q", url, expected, got)
+		}
+	}
+}
+
 func TestVerifySBOM(t *testing.T) {
 	verifier := &AttestationVerifier{
 		token: "test-token",
@@ -476,6 +600,37 @@ func TestValidateSubjectMatch(t *testing.T) {
 			if !tt.expectError && err != nil {
 				t.Errorf("Unexpected error: %v", err)
 			}
+
+			if tt.name == "mismatched digests" && !errors.Is(err, dgerrors.ErrDigestMismatch) {
+				t.Errorf("Expected ErrDigestMismatch, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSubjectDigests(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   *VerificationResult
+		expected []string
+	}{
+		{
+			name:     "single manifest",
+			result:   &VerificationResult{ArtifactDigest: "sha256:manifest"},
+			expected: []string{"sha256:manifest"},
+		},
+		{
+			name:     "manifest selected from an index",
+			result:   &VerificationResult{ArtifactDigest: "sha256:manifest", IndexDigest: "sha256:index"},
+			expected: []string{"sha256:manifest", "sha256:index"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.SubjectDigests(); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
 		})
 	}
 }
@@ -488,6 +643,7 @@ func TestFormatVerificationResult(t *testing.T) {
 	tests := []struct {
 		name     string
 		result   *VerificationResult
+		findings []findings.Finding
 		contains []string
 	}{
 		{
@@ -529,10 +685,12 @@ func TestFormatVerificationResult(t *testing.T) {
 		{
 			name: "invalid with vulnerabilities",
 			result: &VerificationResult{
-				Found:    true,
-				Valid:    false,
-				Errors:   []string{"validation failed"},
-				Warnings: []string{"minor issue"},
+				Found: true,
+				Valid: false,
+				Checks: []Check{
+					{Name: CheckAttestations, Status: CheckPassed, Severity: SeveritySoft},
+					{Name: CheckVulnerabilities, Status: CheckFailed, Severity: SeveritySoft, Detail: "1 high/critical vulnerabilities found"},
+				},
 				SBOM: &SBOMResult{
 					Valid:      true,
 					Format:     "SPDX-2.3",
@@ -548,11 +706,17 @@ func TestFormatVerificationResult(t *testing.T) {
 					},
 				},
 			},
+			findings: []findings.Finding{
+				{Check: CheckSBOM, Severity: findings.SeverityError, Message: "validation failed"},
+				{Severity: findings.SeverityWarning, Message: "minor issue"},
+			},
 			contains: []string{
 				"Attestations: Invalid",
+				"attestations: passed",
+				"vulnerabilities: failed (soft) - 1 high/critical vulnerabilities found",
 				"Vulnerabilities: 1 found",
 				"CVE-2024-TEST (HIGH)",
-				"validation failed",
+				"sbom: validation failed",
 				"minor issue",
 			},
 		},
@@ -560,6 +724,9 @@ func TestFormatVerificationResult(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			for _, f := range tt.findings {
+				tt.result.Findings.Add(f)
+			}
 			output := verifier.FormatVerificationResult(tt.result)
 
 			for _, expected := range tt.contains {
@@ -597,7 +764,129 @@ func TestVerifyAttestations_Integration(t *testing.T) {
 		t.Error("Expected attestation not found for invalid reference")
 	}
 
-	if len(result.Errors) == 0 {
+	if len(result.Errors()) == 0 {
 		t.Error("Expected errors for invalid reference")
 	}
 }
+
+// pushReferrer attaches a bundle referrer carrying data for predicateType to subject
+func pushReferrer(t *testing.T, registry *mock.Registry, subject ocispec.Descriptor, predicateType string, data []byte) {
+	t.Helper()
+	const bundleMediaType = "application/vnd.dev.sigstore.bundle.v0.3+json"
+	layer := registry.Push(bundleMediaType, data)
+	config := registry.Push(ocispec.MediaTypeEmptyJSON, []byte("{}"))
+	if _, err := registry.PushManifest(ocispec.Manifest{
+		ArtifactType: bundleMediaType,
+		Config:       config,
+		Layers:       []ocispec.Descriptor{layer},
+		Subject:      &subject,
+		Annotations:  map[string]string{"dev.sigstore.bundle.predicateType": predicateType},
+	}); err != nil {
+		t.Fatalf("failed to push referrer: %v", err)
+	}
+}
+
+func TestVerifyArtifactUnsignedReferrer(t *testing.T) {
+	registry := mock.NewRegistry(t)
+	subject, err := registry.PushManifest(ocispec.Manifest{
+		Config: registry.Push(ocispec.MediaTypeEmptyJSON, []byte("{}")),
+		Layers: []ocispec.Descriptor{registry.Push("application/javascript", []byte("module.exports = {}"))},
+	})
+	if err != nil {
+		t.Fatalf("failed to push plugin manifest: %v", err)
+	}
+
+	// Anyone able to push a referrer can write an in-toto statement naming the trusted builder
+	statement := `{
+  "_type": "https://in-toto.io/Statement/v1",
+  "subject": [{"name": "plugin", "digest": {"sha256": "` + subject.Digest.Encoded() + `"}}],
+  "predicateType": "https://slsa.dev/provenance/v1",
+  "predicate": {
+    "buildDefinition": {
+      "buildType": "https://actions.github.io/buildtypes/workflow/v1",
+      "externalParameters": {"workflow": {"ref": "refs/heads/main", "repository": "https://github.com/owner/repo", "path": ".github/workflows/build.yml"}}
+    },
+    "runDetails": {"builder": {"id": "` + trustedBuilder + `"}}
+  }
+}`
+	pushReferrer(t, registry, subject, SLSAPredicateV1, []byte(statement))
+
+	verifier := &AttestationVerifier{trustedBuilder: trustedBuilder}
+	result, err := verifier.VerifyArtifact(context.Background(), &oci.Artifact{
+		Repository: &oci.Repository{Repository: registry.Repository("owner/plugin")},
+		Descriptor: subject,
+	})
+	if err != nil {
+		t.Fatalf("VerifyArtifact() error = %v", err)
+	}
+
+	if !result.Found {
+		t.Fatalf("expected the referrer to be found, got %+v", result)
+	}
+	if result.Valid || result.SLSA != nil {
+		t.Errorf("expected the unsigned statement not to be read as provenance, got %+v", result)
+	}
+	check, ok := result.Check(CheckSignature)
+	if !ok || check.Status != CheckFailed || check.Severity != SeverityHard {
+		t.Errorf("expected a hard signature failure, got %+v", check)
+	}
+	if err := result.Enforce(false); !errors.Is(err, dgerrors.ErrAttestationInvalid) {
+		t.Errorf("expected the unsigned referrer to be rejected, got %v", err)
+	}
+}
+
+func TestVerifyArtifactSLSAV02Referrer(t *testing.T) {
+	registry := mock.NewRegistry(t)
+	subject, err := registry.PushManifest(ocispec.Manifest{
+		Config: registry.Push(ocispec.MediaTypeEmptyJSON, []byte("{}")),
+		Layers: []ocispec.Descriptor{registry.Push("application/javascript", []byte("module.exports = {}"))},
+	})
+	if err != nil {
+		t.Fatalf("failed to push plugin manifest: %v", err)
+	}
+
+	statement := `{
+  "_type": "https://in-toto.io/Statement/v0.1",
+  "subject": [{"name": "plugin", "digest": {"sha256": "` + subject.Digest.Encoded() + `"}}],
+  "predicateType": "https://slsa.dev/provenance/v0.2",
+  "predicate": {
+    "builder": {"id": "https://github.com/actions/runner"},
+    "buildType": "https://github.com/slsa-framework/slsa-github-generator/generic@v1",
+    "invocation": {"configSource": {"uri": "git+https://github.com/owner/repo@refs/heads/main", "digest": {"sha1": "77ab745aee787d519642a87ed8f68be12fdc4b0d"}, "entryPoint": ".github/workflows/release.yml"}},
+    "metadata": {"buildInvocationId": "https://github.com/owner/repo/actions/runs/123/attempts/1", "buildStartedOn": "2026-03-04T05:06:07Z"}
+  }
+}`
+	// The bundle is well formed; without a Sigstore verifier its signature is not checked
+	sigstoreBundle := `{
+  "mediaType": "application/vnd.dev.sigstore.bundle.v0.3+json",
+  "verificationMaterial": {"publicKey": {"hint": "test"}},
+  "dsseEnvelope": {
+    "payload": "` + base64.StdEncoding.EncodeToString([]byte(statement)) + `",
+    "payloadType": "application/vnd.in-toto+json",
+    "signatures": [{"sig": "` + base64.StdEncoding.EncodeToString([]byte("signature")) + `"}]
+  }
+}`
+	pushReferrer(t, registry, subject, SLSAPredicateV02, []byte(sigstoreBundle))
+
+	verifier := &AttestationVerifier{trustedBuilder: "https://github.com/actions/runner"}
+	result, err := verifier.VerifyArtifact(context.Background(), &oci.Artifact{
+		Repository: &oci.Repository{Repository: registry.Repository("owner/plugin")},
+		Descriptor: subject,
+	})
+	if err != nil {
+		t.Fatalf("VerifyArtifact() error = %v", err)
+	}
+
+	if !result.Valid || result.SLSA == nil {
+		t.Fatalf("expected the v0.2 provenance referrer to verify, got %+v", result)
+	}
+	if result.SLSA.PredicateType != SLSAPredicateV02 || result.SLSA.Repository != "owner/repo" || result.SLSA.SourceCommit != "77ab745aee787d519642a87ed8f68be12fdc4b0d" {
+		t.Errorf("unexpected provenance %+v", result.SLSA)
+	}
+	if !slices.ContainsFunc(result.Warnings(), func(w string) bool { return strings.Contains(w, "older SLSA v0.2 format") }) {
+		t.Errorf("expected a warning about the v0.2 format, got %v", result.Warnings())
+	}
+	if err := result.Enforce(false); err != nil {
+		t.Errorf("expected the v0.2 provenance referrer to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+// ABOUTME: Append-only JSON Lines log of security decisions taken in a vault
+// ABOUTME: Records overridden safeguards and verification outcomes, per plugin and version
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Actions
+const (
+	ActionDowngrade = "downgrade"
+	ActionVerify    = "verify"
+)
+
+// Decisions
+const (
+	DecisionAllowed = "allowed"
+	DecisionRefused = "refused"
+	DecisionPassed  = "passed"
+	DecisionFailed  = "failed"
+)
+
+// Event is one line of the audit log
+type Event struct {
+	Time        time.Time `json:"time"`
+	Action      string    `json:"action"`
+	Decision    string    `json:"decision"`
+	PluginID    string    `json:"plugin_id"`
+	FromVersion string    `json:"from_version,omitempty"`
+	FromDigest  string    `json:"from_digest,omitempty"`
+	ToVersion   string    `json:"to_version,omitempty"`
+	ToDigest    string    `json:"to_digest,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+}
+
+// Append writes event as a single line at the end of the log at path, creating the log
+// and its directory when they do not exist
+func Append(path string, event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return file.Close()
+}
+
+// Load reads every event in the log at path. A missing log has no events.
+func Load(path string) ([]Event, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log line %d: %w", line, err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return events, nil
+}
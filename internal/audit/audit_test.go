@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".dragonglass", "audit.jsonl")
+
+	if events, err := Load(path); err != nil || len(events) != 0 {
+		t.Fatalf("expected missing log to have no events, got %v (%v)", events, err)
+	}
+
+	when := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	events := []Event{
+		{Time: when, Action: ActionDowngrade, Decision: DecisionRefused, PluginID: "tasknotes", FromVersion: "1.2.0", ToVersion: "1.1.0", Reason: "version 1.1.0 is lower than the installed 1.2.0"},
+		{Time: when.Add(time.Minute), Action: ActionDowngrade, Decision: DecisionAllowed, PluginID: "tasknotes", FromVersion: "1.2.0", ToVersion: "1.1.0"},
+	}
+	for _, event := range events {
+		if err := Append(path, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("expected one line per event, got %d", lines)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(loaded))
+	}
+	for i, event := range loaded {
+		if !event.Time.Equal(events[i].Time) || event.Decision != events[i].Decision || event.Reason != events[i].Reason {
+			t.Errorf("event %d: expected %+v, got %+v", i, events[i], event)
+		}
+	}
+}
+
+func TestLoadRejectsCorruptLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := os.WriteFile(path, []byte("{\"action\":\"downgrade\"}\nnot json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected an error naming line 2, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+// ABOUTME: Opens URLs in the user's default browser for the device flow
+// ABOUTME: Reports why a browser could not be opened so callers can fall back to printing the URL
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// NoBrowserEnv disables opening a browser when set to any non-empty value
+const NoBrowserEnv = "DRAGONGLASS_NO_BROWSER"
+
+// OpenBrowser opens url in the default browser. It returns an error without trying when
+// no desktop session is available, such as over SSH, in CI, or in a container.
+func OpenBrowser(url string) error {
+	if reason := headlessReason(runtime.GOOS, os.Getenv); reason != "" {
+		return errors.New(reason)
+	}
+
+	name, args := browserCommand(runtime.GOOS, url)
+	command := exec.Command(name, args...)
+	if err := command.Start(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", name, err)
+	}
+
+	// Reap the launcher in the background; some block until the browser exits
+	go func() { _ = command.Wait() }()
+	return nil
+}
+
+// browserCommand returns the platform's command for opening url
+func browserCommand(goos, url string) (string, []string) {
+	switch goos {
+	case "darwin":
+		return "open", []string{url}
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", url}
+	default:
+		return "xdg-open", []string{url}
+	}
+}
+
+// headlessReason explains why no browser should be opened, or returns "" when one can be
+func headlessReason(goos string, getenv func(string) string) string {
+	switch {
+	case getenv(NoBrowserEnv) != "":
+		return NoBrowserEnv + " is set"
+	case getenv("CI") != "":
+		return "running in CI"
+	case getenv("SSH_CONNECTION") != "" && goos != "windows":
+		return "running over SSH"
+	case goos != "darwin" && goos != "windows" && getenv("DISPLAY") == "" && getenv("WAYLAND_DISPLAY") == "":
+		return "no graphical display"
+	}
+	return ""
+}
@@ -13,12 +13,43 @@ import (
 	"time"
 
 	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/httpclient"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
+	"github.com/gillisandrew/dragonglass-poc/internal/ui"
 )
 
+// DefaultClientID is the client ID of the dragonglass GitHub App. Builds of a fork can
+// replace it with -ldflags "-X github.com/gillisandrew/dragonglass-poc/internal/auth.DefaultClientID=<id>".
+var DefaultClientID = "Iv23liqsBDwGGj4wPGbZ"
+
+// clientID overrides DefaultClientID when set
+var clientID string
+
+// SetClientID selects the GitHub App or OAuth app client ID used by the device flow,
+// from auth.oauth_client_id or DRAGONGLASS_OAUTH_CLIENT_ID. Empty means DefaultClientID.
+func SetClientID(id string) {
+	clientID = id
+}
+
+// ClientID returns the OAuth client ID used by the device flow
+func ClientID() string {
+	if clientID != "" {
+		return clientID
+	}
+	return DefaultClientID
+}
+
+// CheckClientID fails when no client ID is configured, which happens in builds without
+// a default unless auth.oauth_client_id is set
+func CheckClientID() error {
+	if ClientID() == "" {
+		return fmt.Errorf("no OAuth client ID configured; register a GitHub App or OAuth app with device flow enabled and set auth.oauth_client_id or DRAGONGLASS_OAUTH_CLIENT_ID")
+	}
+	return nil
+}
+
 const (
-	// GitHub App configuration for dragonglass
-	// Note: These would typically be from environment or config in production
-	ClientID       = "Iv23liqsBDwGGj4wPGbZ" // GitHub App client ID for dragonglass
 	DeviceCodeURL  = "https://github.com/login/device/code"
 	AccessTokenURL = "https://github.com/login/oauth/access_token"
 
@@ -31,8 +62,21 @@ type DeviceCodeResponse struct {
 	DeviceCode      string `json:"device_code"`
 	UserCode        string `json:"user_code"`
 	VerificationURI string `json:"verification_uri"`
-	ExpiresIn       int    `json:"expires_in"`
-	Interval        int    `json:"interval"`
+
+	// Verification URL with the user code already filled in (optional)
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+
+	ExpiresIn int `json:"expires_in"`
+	Interval  int `json:"interval"`
+}
+
+// BrowserURL returns the page the user should open, preferring the one that
+// carries the user code so it does not have to be typed
+func (d *DeviceCodeResponse) BrowserURL() string {
+	if d.VerificationURIComplete != "" {
+		return d.VerificationURIComplete
+	}
+	return d.VerificationURI
 }
 
 type AccessTokenResponse struct {
@@ -49,13 +93,17 @@ func StartDeviceFlow(scopes string) (*DeviceCodeResponse, error) {
 		scopes = DefaultScopes
 	}
 
+	if err := CheckClientID(); err != nil {
+		return nil, err
+	}
+
 	// Prepare form data
 	data := url.Values{}
-	data.Set("client_id", ClientID)
+	data.Set("client_id", ClientID())
 	data.Set("scope", scopes)
 
 	// Make request to GitHub
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := httpclient.NewWithTimeout(10 * time.Second)
 	resp, err := client.PostForm(DeviceCodeURL, data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to request device code: %w", err)
@@ -95,11 +143,12 @@ func StartDeviceFlow(scopes string) (*DeviceCodeResponse, error) {
 
 	// Build response struct
 	deviceResp := DeviceCodeResponse{
-		DeviceCode:      values.Get("device_code"),
-		UserCode:        values.Get("user_code"),
-		VerificationURI: values.Get("verification_uri"),
-		ExpiresIn:       expiresIn,
-		Interval:        interval,
+		DeviceCode:              values.Get("device_code"),
+		UserCode:                values.Get("user_code"),
+		VerificationURI:         values.Get("verification_uri"),
+		VerificationURIComplete: values.Get("verification_uri_complete"),
+		ExpiresIn:               expiresIn,
+		Interval:                interval,
 	}
 
 	return &deviceResp, nil
@@ -107,7 +156,7 @@ func StartDeviceFlow(scopes string) (*DeviceCodeResponse, error) {
 
 // PollForAccessToken polls GitHub for the access token
 func PollForAccessToken(ctx context.Context, deviceCode string, interval int) (*AccessTokenResponse, error) {
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := httpclient.NewWithTimeout(10 * time.Second)
 	ticker := time.NewTicker(time.Duration(interval) * time.Second)
 	defer ticker.Stop()
 
@@ -118,7 +167,7 @@ func PollForAccessToken(ctx context.Context, deviceCode string, interval int) (*
 		case <-ticker.C:
 			// Prepare form data
 			data := url.Values{}
-			data.Set("client_id", ClientID)
+			data.Set("client_id", ClientID())
 			data.Set("device_code", deviceCode)
 			data.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
 
@@ -175,8 +224,8 @@ func PollForAccessToken(ctx context.Context, deviceCode string, interval int) (*
 
 // RunDeviceFlow executes the complete device flow authentication
 func RunDeviceFlow(scopes string) (*AccessTokenResponse, error) {
-	pterm.Info.Println("Starting GitHub device flow authentication")
-	pterm.Info.Printfln("Requesting scopes: %s", pterm.LightMagenta(scopes))
+	ui.Info(i18n.T("auth.device.start"))
+	ui.Info(i18n.T("auth.device.scopes", pterm.LightMagenta(scopes)))
 	pterm.Println()
 
 	// Step 1: Get device code
@@ -186,55 +235,93 @@ func RunDeviceFlow(scopes string) (*AccessTokenResponse, error) {
 	}
 
 	// Step 2: Show user instructions with pterm
-	pterm.Info.Println("Please complete the following steps:")
+	ui.Info(i18n.T("auth.device.steps"))
 	pterm.Println()
 
-	// Create a styled box for the user code
-	codeBox := pterm.DefaultBox.WithTitle("Code").WithTitleTopCenter()
-	codeBox.Println(pterm.LightCyan(deviceCode.UserCode))
+	// Frame the user code so it stands out
+	ui.Box(i18n.T("auth.device.code_title"), pterm.LightCyan(deviceCode.UserCode))
 	pterm.Println()
 
 	// Show instructions as a list
-	instructions := pterm.DefaultBulletList.WithItems([]pterm.BulletListItem{
-		{Level: 0, Text: pterm.Sprintf("Copy the code above: %s", pterm.LightCyan(deviceCode.UserCode))},
-		{Level: 0, Text: pterm.Sprintf("Visit: %s", pterm.LightBlue(deviceCode.VerificationURI))},
-		{Level: 0, Text: "Enter the code when prompted"},
-	})
-	instructions.Render()
+	browserURL := deviceCode.BrowserURL()
+	var steps []string
+	if deviceCode.VerificationURIComplete != "" {
+		steps = []string{
+			i18n.T("auth.device.visit", pterm.LightBlue(browserURL)),
+			i18n.T("auth.device.confirm_code", pterm.LightCyan(deviceCode.UserCode)),
+		}
+	} else {
+		steps = []string{
+			i18n.T("auth.device.copy_code", pterm.LightCyan(deviceCode.UserCode)),
+			i18n.T("auth.device.visit", pterm.LightBlue(browserURL)),
+			i18n.T("auth.device.enter_code"),
+		}
+	}
+	ui.Steps(steps)
 	pterm.Println()
 
-	// Show expiration and polling status
-	pterm.Warning.Printfln("Code expires in %d minutes", deviceCode.ExpiresIn/60)
-	spinner, _ := pterm.DefaultSpinner.Start("Polling for authorization...")
+	// Opening the browser is a convenience; the printed URL always works
+	if err := OpenBrowser(browserURL); err != nil {
+		ui.Info(i18n.T("auth.device.browser_failed", err))
+	} else {
+		ui.Info(i18n.T("auth.device.browser_opened"))
+	}
 
-	// Step 3: Poll for access token
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(deviceCode.ExpiresIn)*time.Second)
+	// Step 3: Poll for access token, counting down the code's remaining validity
+	expiresAt := time.Now().Add(time.Duration(deviceCode.ExpiresIn) * time.Second)
+	ctx, cancel := context.WithDeadline(context.Background(), expiresAt)
 	defer cancel()
 
+	status := ui.StartProgress(pollingStatus(time.Until(expiresAt)))
+	done := make(chan struct{})
+	go func() {
+		countdown := time.NewTicker(time.Second)
+		defer countdown.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-countdown.C:
+				status.Update(pollingStatus(time.Until(expiresAt)))
+			}
+		}
+	}()
+
 	token, err := PollForAccessToken(ctx, deviceCode.DeviceCode, deviceCode.Interval)
-	spinner.Stop()
+	close(done)
+	status.Stop()
 	if err != nil {
-		pterm.Error.Println("Failed to get access token")
+		pterm.Error.Println(i18n.T("auth.device.token_failed"))
 		return nil, fmt.Errorf("failed to get access token: %w", err)
 	}
 
-	pterm.Success.Println("Authentication successful!")
-	pterm.Info.Printfln("Received token with scopes: %s", pterm.LightGreen(token.Scope))
+	ui.Success(i18n.T("auth.device.success"))
+	ui.Info(i18n.T("auth.device.token_scopes", pterm.LightGreen(token.Scope)))
 
 	return token, nil
 }
 
+// pollingStatus describes the wait for authorization along with the code's remaining validity
+func pollingStatus(remaining time.Duration) string {
+	if remaining < 0 {
+		remaining = 0
+	}
+	remaining = remaining.Round(time.Second)
+	minutes := int(remaining / time.Minute)
+	seconds := int((remaining % time.Minute) / time.Second)
+	return i18n.T("auth.device.waiting", minutes, seconds)
+}
+
 // ValidateTokenScopes checks if the token has the required scopes
 func ValidateTokenScopes(token string, requiredScopes []string) error {
 	// Create request to get token info
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := httpclient.NewWithTimeout(10 * time.Second)
 	req, err := http.NewRequest("GET", "https://api.github.com/user", nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("User-Agent", "dragonglass-cli")
 
 	resp, err := client.Do(req)
 	if err != nil {
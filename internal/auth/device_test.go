@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBrowserURL(t *testing.T) {
+	code := &DeviceCodeResponse{VerificationURI: "https://github.com/login/device"}
+	if got := code.BrowserURL(); got != "https://github.com/login/device" {
+		t.Errorf("expected verification URI, got %s", got)
+	}
+
+	code.VerificationURIComplete = "https://github.com/login/device?user_code=ABCD-1234"
+	if got := code.BrowserURL(); got != code.VerificationURIComplete {
+		t.Errorf("expected complete verification URI, got %s", got)
+	}
+}
+
+func TestPollingStatus(t *testing.T) {
+	tests := []struct {
+		remaining time.Duration
+		expected  string
+	}{
+		{remaining: 15 * time.Minute, expected: "Waiting for authorization... code expires in 15:00"},
+		{remaining: 61*time.Second + 400*time.Millisecond, expected: "Waiting for authorization... code expires in 1:01"},
+		{remaining: -time.Second, expected: "Waiting for authorization... code expires in 0:00"},
+	}
+
+	for _, tt := range tests {
+		if got := pollingStatus(tt.remaining); got != tt.expected {
+			t.Errorf("pollingStatus(%v) = %q, expected %q", tt.remaining, got, tt.expected)
+		}
+	}
+}
+
+func TestHeadlessReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		goos     string
+		env      map[string]string
+		headless bool
+	}{
+		{name: "linux desktop", goos: "linux", env: map[string]string{"DISPLAY": ":0"}},
+		{name: "wayland", goos: "linux", env: map[string]string{"WAYLAND_DISPLAY": "wayland-0"}},
+		{name: "linux without display", goos: "linux", headless: true},
+		{name: "macOS", goos: "darwin"},
+		{name: "ssh session", goos: "darwin", env: map[string]string{"SSH_CONNECTION": "10.0.0.1 22 10.0.0.2 22"}, headless: true},
+		{name: "ci", goos: "windows", env: map[string]string{"CI": "true"}, headless: true},
+		{name: "opted out", goos: "darwin", env: map[string]string{NoBrowserEnv: "1"}, headless: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := headlessReason(tt.goos, func(name string) string { return tt.env[name] })
+			if (reason != "") != tt.headless {
+				t.Errorf("expected headless %v, got reason %q", tt.headless, reason)
+			}
+		})
+	}
+}
+
+func TestBrowserCommand(t *testing.T) {
+	url := "https://github.com/login/device"
+	for goos, expected := range map[string]string{"darwin": "open", "windows": "rundll32", "linux": "xdg-open", "freebsd": "xdg-open"} {
+		if name, args := browserCommand(goos, url); name != expected || args[len(args)-1] != url {
+			t.Errorf("%s: expected %s with the URL, got %s %v", goos, expected, name, args)
+		}
+	}
+}
+
+func TestClientID(t *testing.T) {
+	defaultID := DefaultClientID
+	t.Cleanup(func() {
+		DefaultClientID = defaultID
+		SetClientID("")
+	})
+
+	if got := ClientID(); got != DefaultClientID {
+		t.Errorf("expected default client ID %s, got %s", DefaultClientID, got)
+	}
+
+	SetClientID("Ov23liCustomClient01")
+	if got := ClientID(); got != "Ov23liCustomClient01" {
+		t.Errorf("expected configured client ID, got %s", got)
+	}
+
+	// A build without a default needs the client ID configured
+	DefaultClientID = ""
+	SetClientID("")
+	if err := CheckClientID(); err == nil {
+		t.Error("expected an error without a client ID")
+	}
+	if _, err := StartDeviceFlow(""); err == nil || !strings.Contains(err.Error(), "auth.oauth_client_id") {
+		t.Errorf("expected device flow to refuse to start, got %v", err)
+	}
+}
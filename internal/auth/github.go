@@ -7,6 +7,11 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/httpclient"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
+	"github.com/gillisandrew/dragonglass-poc/internal/ui"
 )
 
 const (
@@ -27,6 +32,10 @@ type AuthOpts struct {
 
 	// Override token (for testing or custom auth)
 	Token string
+
+	// Use the override token without checking it against the GitHub user API. Actions
+	// GITHUB_TOKENs are installation tokens that cannot read /user.
+	SkipValidation bool
 }
 
 // DefaultAuthOpts returns the default authentication options
@@ -43,6 +52,12 @@ func (opts *AuthOpts) WithToken(token string) *AuthOpts {
 	return opts
 }
 
+// WithSkipValidation uses the override token without validating it first
+func (opts *AuthOpts) WithSkipValidation(skip bool) *AuthOpts {
+	opts.SkipValidation = skip
+	return opts
+}
+
 // WithHost sets a custom GitHub hostname
 func (opts *AuthOpts) WithHost(host string) *AuthOpts {
 	opts.GitHubHost = host
@@ -79,6 +94,9 @@ func (c *AuthClient) IsAuthenticated() bool {
 	if err != nil || cred.Token == "" {
 		return false
 	}
+	if cred.Store == StoreSession {
+		return true
+	}
 
 	// Validate the stored token
 	return c.ValidateToken(cred.Token) == nil
@@ -94,7 +112,7 @@ func IsAuthenticated() bool {
 func GetAuthenticatedUser() (string, error) {
 	cred, err := GetStoredCredential()
 	if err != nil {
-		return "", fmt.Errorf("not authenticated: %w", err)
+		return "", fmt.Errorf("%w: %w", dgerrors.ErrNotAuthenticated, err)
 	}
 
 	// Return stored username if available
@@ -114,18 +132,17 @@ func GetAuthenticatedUser() (string, error) {
 // ValidateToken checks if the provided token is valid
 func (c *AuthClient) ValidateToken(token string) error {
 	if token == "" {
-		return fmt.Errorf("no authentication token provided")
+		return dgerrors.Mark(fmt.Errorf("no authentication token provided"), dgerrors.ErrNotAuthenticated)
 	}
 
 	// Create HTTP client with the token
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := httpclient.NewWithTimeout(10 * time.Second)
 	req, err := http.NewRequest("GET", fmt.Sprintf("https://api.%s/user", c.opts.GitHubHost), nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("User-Agent", "dragonglass-cli")
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -136,7 +153,7 @@ func (c *AuthClient) ValidateToken(token string) error {
 	}()
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		return fmt.Errorf("token is invalid or expired")
+		return dgerrors.Mark(fmt.Errorf("token is invalid or expired"), dgerrors.ErrNotAuthenticated)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -159,17 +176,20 @@ func RequireAuth() error {
 		return nil
 	}
 
-	fmt.Printf("🔐 Authentication required to access GitHub Container Registry\n")
-	fmt.Printf("📦 Dragonglass needs permission to read packages from ghcr.io\n\n")
-	fmt.Printf("Please run: dragonglass auth\n\n")
+	ui.Status(ui.IconLock, i18n.T("auth.required"))
+	ui.Status(ui.IconPackage, i18n.T("auth.required_detail"))
+	ui.Status("", i18n.T("auth.run_auth"))
 
-	return fmt.Errorf("not authenticated - please run 'dragonglass auth' first")
+	return fmt.Errorf("%w - please run 'dragonglass auth' first", dgerrors.ErrNotAuthenticated)
 }
 
 // GetToken retrieves authentication token (respects token override)
 func (c *AuthClient) GetToken() (string, error) {
 	// Return override token if provided
 	if c.opts.Token != "" {
+		if c.opts.SkipValidation {
+			return c.opts.Token, nil
+		}
 		if err := c.ValidateToken(c.opts.Token); err != nil {
 			return "", fmt.Errorf("provided token is invalid: %w", err)
 		}
@@ -178,11 +198,16 @@ func (c *AuthClient) GetToken() (string, error) {
 
 	cred, err := GetStoredCredential()
 	if err != nil {
-		return "", fmt.Errorf("no stored credentials found: %w", err)
+		return "", dgerrors.Mark(fmt.Errorf("no stored credentials found: %w", err), dgerrors.ErrNotAuthenticated)
 	}
 
 	if cred.Token == "" {
-		return "", fmt.Errorf("no authentication token found")
+		return "", dgerrors.Mark(fmt.Errorf("no authentication token found"), dgerrors.ErrNotAuthenticated)
+	}
+
+	// Exchanged tokens are typically installation tokens that cannot read /user
+	if cred.Store == StoreSession {
+		return cred.Token, nil
 	}
 
 	// Validate token before returning
@@ -208,13 +233,10 @@ func (c *AuthClient) GetHTTPClient() (*http.Client, error) {
 		return nil, fmt.Errorf("failed to get authentication token: %w", err)
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := httpclient.New(httpclient.DefaultOpts())
 
 	// Return a transport that adds auth headers
 	originalTransport := client.Transport
-	if originalTransport == nil {
-		originalTransport = http.DefaultTransport
-	}
 
 	client.Transport = &authenticatedTransport{
 		token:     token,
@@ -240,14 +262,13 @@ func (t *authenticatedTransport) RoundTrip(req *http.Request) (*http.Response, e
 	// Clone request to avoid modifying original
 	authReq := req.Clone(req.Context())
 	authReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.token))
-	authReq.Header.Set("User-Agent", "dragonglass-cli")
 
 	return t.transport.RoundTrip(authReq)
 }
 
 // Authenticate performs the complete authentication flow using device flow
 func Authenticate() error {
-	fmt.Printf("🚀 Starting dragonglass authentication...\n\n")
+	ui.Status(ui.IconStart, i18n.T("auth.starting"))
 
 	// Run device flow authentication
 	tokenResp, err := RunDeviceFlow(DefaultRequiredScopes)
@@ -268,20 +289,19 @@ func Authenticate() error {
 		return fmt.Errorf("failed to store authentication token: %w", err)
 	}
 
-	fmt.Printf("🎉 Authentication complete! You can now access GitHub Container Registry.\n")
+	ui.Status(ui.IconSuccess, i18n.T("auth.complete"))
 	return nil
 }
 
 // getUsernameFromToken extracts username from GitHub token
 func getUsernameFromToken(token string) (string, error) {
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := httpclient.NewWithTimeout(10 * time.Second)
 	req, err := http.NewRequest("GET", "https://api.github.com/user", nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("User-Agent", "dragonglass-cli")
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -43,6 +43,18 @@ func TestGetToken(t *testing.T) {
 	}
 }
 
+func TestGetTokenSkipValidation(t *testing.T) {
+	client := NewAuthClient(DefaultAuthOpts().WithToken("ghs_actions-token").WithSkipValidation(true))
+
+	token, err := client.GetToken()
+	if err != nil {
+		t.Fatalf("expected override token to be returned without validation, got %v", err)
+	}
+	if token != "ghs_actions-token" {
+		t.Errorf("expected override token, got %s", token)
+	}
+}
+
 func TestIsAuthenticated(t *testing.T) {
 	// Test with likely unauthenticated test environment
 	authenticated := IsAuthenticated()
@@ -0,0 +1,221 @@
+// ABOUTME: GitHub Actions OIDC token exchange for registry access without stored secrets
+// ABOUTME: Trades the runner's ID token for an access token at an RFC 8693 exchange endpoint
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/httpclient"
+)
+
+const (
+	// Set by the Actions runner when the job has the id-token: write permission
+	ActionsIDTokenURLEnv   = "ACTIONS_ID_TOKEN_REQUEST_URL"
+	ActionsIDTokenTokenEnv = "ACTIONS_ID_TOKEN_REQUEST_TOKEN"
+
+	// Audience requested for the ID token unless configured otherwise
+	DefaultOIDCAudience = "dragonglass"
+
+	// Source recorded on credentials obtained through the exchange
+	OIDCSource = "actions-oidc"
+
+	tokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	idTokenType            = "urn:ietf:params:oauth:token-type:id_token"
+)
+
+// OIDCOpts configures the Actions OIDC token exchange
+type OIDCOpts struct {
+	// Token exchange endpoint accepting RFC 8693 requests
+	ExchangeURL string
+
+	// Audience of the requested ID token (default: "dragonglass")
+	Audience string
+
+	// HTTP client used for both requests (default: 30 second timeout)
+	HTTPClient *http.Client
+
+	// Environment lookup (default: os.Getenv)
+	Getenv func(string) string
+}
+
+// DefaultOIDCOpts returns options that read the runner environment
+func DefaultOIDCOpts() *OIDCOpts {
+	return &OIDCOpts{
+		Audience:   DefaultOIDCAudience,
+		HTTPClient: httpclient.New(httpclient.DefaultOpts()),
+		Getenv:     os.Getenv,
+	}
+}
+
+// WithExchangeURL sets the token exchange endpoint
+func (opts *OIDCOpts) WithExchangeURL(exchangeURL string) *OIDCOpts {
+	opts.ExchangeURL = exchangeURL
+	return opts
+}
+
+// WithAudience sets the requested ID token audience
+func (opts *OIDCOpts) WithAudience(audience string) *OIDCOpts {
+	opts.Audience = audience
+	return opts
+}
+
+// WithHTTPClient sets the HTTP client
+func (opts *OIDCOpts) WithHTTPClient(client *http.Client) *OIDCOpts {
+	opts.HTTPClient = client
+	return opts
+}
+
+// WithGetenv sets the function used to read environment variables
+func (opts *OIDCOpts) WithGetenv(getenv func(string) string) *OIDCOpts {
+	opts.Getenv = getenv
+	return opts
+}
+
+// ActionsOIDCAvailable reports whether the runner can issue an ID token for this job
+func ActionsOIDCAvailable(getenv func(string) string) bool {
+	return getenv(ActionsIDTokenURLEnv) != "" && getenv(ActionsIDTokenTokenEnv) != ""
+}
+
+// ExchangeActionsOIDCToken requests an ID token from the Actions runner and exchanges it
+// for an access token. The result is meant for SetSessionCredential.
+func ExchangeActionsOIDCToken(ctx context.Context, opts *OIDCOpts) (*StoredCredential, error) {
+	if opts == nil {
+		opts = DefaultOIDCOpts()
+	}
+	if opts.ExchangeURL == "" {
+		return nil, fmt.Errorf("no OIDC token exchange URL configured (set auth.oidc_exchange_url)")
+	}
+	if !ActionsOIDCAvailable(opts.Getenv) {
+		return nil, dgerrors.Mark(fmt.Errorf("%s is not set; grant the job the id-token: write permission", ActionsIDTokenURLEnv), dgerrors.ErrNotAuthenticated)
+	}
+
+	idToken, err := requestActionsIDToken(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := exchangeIDToken(ctx, opts, idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StoredCredential{
+		Token:     accessToken,
+		CreatedAt: time.Now(),
+		Source:    OIDCSource,
+	}, nil
+}
+
+// RequestActionsIDToken requests an ID token for this job with the configured audience,
+// e.g. "sigstore" for keyless signing
+func RequestActionsIDToken(ctx context.Context, opts *OIDCOpts) (string, error) {
+	if opts == nil {
+		opts = DefaultOIDCOpts()
+	}
+	if !ActionsOIDCAvailable(opts.Getenv) {
+		return "", dgerrors.Mark(fmt.Errorf("%s is not set; grant the job the id-token: write permission", ActionsIDTokenURLEnv), dgerrors.ErrNotAuthenticated)
+	}
+	return requestActionsIDToken(ctx, opts)
+}
+
+// requestActionsIDToken asks the runner for an ID token with the configured audience
+func requestActionsIDToken(ctx context.Context, opts *OIDCOpts) (string, error) {
+	requestURL, err := url.Parse(opts.Getenv(ActionsIDTokenURLEnv))
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", ActionsIDTokenURLEnv, err)
+	}
+	audience := opts.Audience
+	if audience == "" {
+		audience = DefaultOIDCAudience
+	}
+	query := requestURL.Query()
+	query.Set("audience", audience)
+	requestURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create ID token request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+opts.Getenv(ActionsIDTokenTokenEnv))
+	req.Header.Set("Accept", "application/json")
+
+	var body struct {
+		Value string `json:"value"`
+	}
+	if err := doJSON(opts.HTTPClient, req, &body); err != nil {
+		return "", fmt.Errorf("failed to request Actions ID token: %w", err)
+	}
+	if body.Value == "" {
+		return "", fmt.Errorf("failed to request Actions ID token: response has no value")
+	}
+	return body.Value, nil
+}
+
+// exchangeIDToken trades an ID token for an access token (RFC 8693)
+func exchangeIDToken(ctx context.Context, opts *OIDCOpts, idToken string) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", tokenExchangeGrantType)
+	form.Set("subject_token", idToken)
+	form.Set("subject_token_type", idTokenType)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, opts.ExchangeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token exchange request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := doJSON(opts.HTTPClient, req, &body); err != nil {
+		if body.Error != "" {
+			return "", dgerrors.Mark(fmt.Errorf("token exchange rejected the ID token: %s - %s", body.Error, body.ErrorDescription), dgerrors.ErrNotAuthenticated)
+		}
+		return "", fmt.Errorf("failed to exchange ID token: %w", err)
+	}
+	if body.AccessToken == "" {
+		return "", fmt.Errorf("failed to exchange ID token: response has no access_token")
+	}
+	return body.AccessToken, nil
+}
+
+// doJSON sends req and decodes the JSON response into out, which is also filled for
+// error responses so callers can report OAuth error fields
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	if client == nil {
+		client = httpclient.New(httpclient.DefaultOpts())
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close() // Ignore error on close
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	decodeErr := json.Unmarshal(data, out)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response: %d", resp.StatusCode)
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("failed to decode response: %w", decodeErr)
+	}
+	return nil
+}
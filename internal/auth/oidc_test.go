@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+)
+
+func TestExchangeActionsOIDCToken(t *testing.T) {
+	runner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer request-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("audience") != "dragonglass" || r.URL.Query().Get("api-version") != "2.0" {
+			t.Errorf("unexpected ID token query %s", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"value": "id-token"}`))
+	}))
+	defer runner.Close()
+
+	exchange := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if r.Form.Get("grant_type") != tokenExchangeGrantType || r.Form.Get("subject_token_type") != idTokenType {
+			t.Errorf("unexpected exchange form %v", r.Form)
+		}
+		if r.Form.Get("subject_token") != "id-token" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "invalid_grant", "error_description": "repository not allowed"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token": "ghs_exchanged", "token_type": "bearer"}`))
+	}))
+	defer exchange.Close()
+
+	env := map[string]string{
+		ActionsIDTokenURLEnv:   runner.URL + "/token?api-version=2.0",
+		ActionsIDTokenTokenEnv: "request-token",
+	}
+	opts := DefaultOIDCOpts().
+		WithExchangeURL(exchange.URL).
+		WithGetenv(func(name string) string { return env[name] })
+
+	cred, err := ExchangeActionsOIDCToken(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cred.Token != "ghs_exchanged" || cred.Source != OIDCSource {
+		t.Errorf("unexpected credential %+v", cred)
+	}
+
+	if _, err := exchangeIDToken(context.Background(), opts, "forged"); !errors.Is(err, dgerrors.ErrNotAuthenticated) {
+		t.Errorf("expected rejected exchange to be ErrNotAuthenticated, got %v", err)
+	}
+
+	env[ActionsIDTokenTokenEnv] = ""
+	if _, err := ExchangeActionsOIDCToken(context.Background(), opts); !errors.Is(err, dgerrors.ErrNotAuthenticated) {
+		t.Errorf("expected ErrNotAuthenticated without the id-token permission, got %v", err)
+	}
+}
+
+func TestSessionCredential(t *testing.T) {
+	useStore(t, StoreNone)
+	SetSessionCredential(&StoredCredential{Token: "ghs_exchanged", Source: OIDCSource})
+	t.Cleanup(func() { SetSessionCredential(nil) })
+
+	cred, err := GetStoredCredential()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cred.Store != StoreSession || cred.Source != OIDCSource {
+		t.Errorf("unexpected credential %+v", cred)
+	}
+
+	// Session tokens are returned without the /user validation installation tokens fail
+	token, err := GetToken()
+	if err != nil || token != "ghs_exchanged" {
+		t.Errorf("expected session token, got %q (err %v)", token, err)
+	}
+}
@@ -0,0 +1,84 @@
+// ABOUTME: OAuth scope requirements of dragonglass commands and checks against stored tokens
+// ABOUTME: Detects missing scopes and computes the union requested when signing in again
+package auth
+
+import (
+	"slices"
+	"strings"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+)
+
+// WritePackagesScope allows pushing packages to ghcr.io; it includes read:packages
+const WritePackagesScope = "write:packages"
+
+// commandScopes lists the scopes commands need beyond DefaultRequiredScopes
+var commandScopes = map[string][]string{
+	"push":   {WritePackagesScope},
+	"attest": {WritePackagesScope},
+}
+
+// impliedScopes lists the scopes GitHub grants along with a scope
+var impliedScopes = map[string][]string{
+	WritePackagesScope: {PackageReadScope},
+}
+
+// CommandScopes returns the scopes the named command needs
+func CommandScopes(command string) []string {
+	return UniqueScopes(append(ParseScopes(DefaultRequiredScopes), commandScopes[command]...))
+}
+
+// ParseScopes splits a comma or space separated scope list as GitHub reports it
+func ParseScopes(scopes string) []string {
+	return UniqueScopes(strings.FieldsFunc(scopes, func(r rune) bool {
+		return r == ',' || r == ' '
+	}))
+}
+
+// UniqueScopes returns scopes without blanks and duplicates, keeping the first occurrence
+func UniqueScopes(scopes []string) []string {
+	unique := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if scope = strings.TrimSpace(scope); scope != "" && !slices.Contains(unique, scope) {
+			unique = append(unique, scope)
+		}
+	}
+	return unique
+}
+
+// MissingScopes returns the required scopes that granted neither lists nor implies
+func MissingScopes(granted string, required []string) []string {
+	have := ParseScopes(granted)
+	for _, scope := range have {
+		have = append(have, impliedScopes[scope]...)
+	}
+
+	var missing []string
+	for _, scope := range UniqueScopes(required) {
+		if !slices.Contains(have, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// UnionScopes returns granted plus required as a comma separated list, the scopes to
+// request when signing in again so that no scope already granted is lost
+func UnionScopes(granted string, required []string) string {
+	return strings.Join(UniqueScopes(append(ParseScopes(granted), required...)), ",")
+}
+
+// CheckScopes reports an InsufficientScopeError when the stored credential was not
+// granted required. Tokens whose scopes are unknown, such as those from the environment
+// or an Actions OIDC exchange, pass; the registry rejects them if they lack a scope.
+// A missing credential also passes and is reported when the token is used.
+func CheckScopes(required []string) error {
+	cred, err := GetStoredCredential()
+	if err != nil || cred.Store == StoreEnv || cred.Store == StoreSession || cred.Scopes == "" {
+		return nil
+	}
+	if missing := MissingScopes(cred.Scopes, required); len(missing) > 0 {
+		return &dgerrors.InsufficientScopeError{Missing: missing, Granted: cred.Scopes}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+)
+
+func TestMissingScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  string
+		required []string
+		missing  []string
+	}{
+		{name: "granted", granted: "read:packages", required: []string{"read:packages"}},
+		{name: "missing write", granted: "read:packages", required: []string{"read:packages", "write:packages"}, missing: []string{"write:packages"}},
+		{name: "write implies read", granted: "write:packages", required: []string{"read:packages"}},
+		{name: "space separated", granted: "repo write:packages", required: []string{"write:packages", "repo"}},
+		{name: "nothing granted", granted: "", required: []string{"read:packages"}, missing: []string{"read:packages"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MissingScopes(tt.granted, tt.required); !slices.Equal(got, tt.missing) {
+				t.Errorf("expected missing %v, got %v", tt.missing, got)
+			}
+		})
+	}
+}
+
+func TestUnionScopes(t *testing.T) {
+	if got := UnionScopes("read:packages, repo", []string{"write:packages", "repo"}); got != "read:packages,repo,write:packages" {
+		t.Errorf("expected existing scopes kept and new ones appended, got %q", got)
+	}
+	if got := UnionScopes("", []string{"read:packages"}); got != "read:packages" {
+		t.Errorf("expected required scopes, got %q", got)
+	}
+}
+
+func TestCommandScopes(t *testing.T) {
+	if got := CommandScopes("push"); !slices.Equal(got, []string{PackageReadScope, WritePackagesScope}) {
+		t.Errorf("expected push to need read and write, got %v", got)
+	}
+	if got := CommandScopes("install"); !slices.Equal(got, []string{PackageReadScope}) {
+		t.Errorf("expected install to need read only, got %v", got)
+	}
+}
+
+func TestCheckScopes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	useStore(t, StoreFile)
+
+	// Without a credential the check defers to the missing token error
+	if err := CheckScopes(CommandScopes("push")); err != nil {
+		t.Errorf("expected no error without a credential, got %v", err)
+	}
+
+	if err := StoreToken("gho_token", DefaultRequiredScopes, "octocat"); err != nil {
+		t.Fatal(err)
+	}
+	var scopeErr *dgerrors.InsufficientScopeError
+	if err := CheckScopes(CommandScopes("push")); !errors.As(err, &scopeErr) || !slices.Equal(scopeErr.Missing, []string{WritePackagesScope}) {
+		t.Errorf("expected write:packages to be missing, got %v", err)
+	}
+	if err := CheckScopes(CommandScopes("install")); err != nil {
+		t.Errorf("expected read scope to suffice, got %v", err)
+	}
+
+	// Scopes of environment tokens are unknown
+	useStore(t, StoreEnv)
+	t.Setenv("GITHUB_TOKEN", "ghs_token")
+	if err := CheckScopes(CommandScopes("push")); err != nil {
+		t.Errorf("expected environment tokens to pass, got %v", err)
+	}
+}
@@ -1,5 +1,5 @@
-// ABOUTME: Secure credential storage for GitHub tokens with OS keychain fallback
-// ABOUTME: Handles token persistence and retrieval across CLI sessions
+// ABOUTME: Secure credential storage for GitHub tokens in the OS keychain or a private file
+// ABOUTME: Handles token persistence and retrieval across CLI sessions for the selected store
 package auth
 
 import (
@@ -7,9 +7,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/zalando/go-keyring"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
+	"github.com/gillisandrew/dragonglass-poc/internal/ui"
+	"github.com/gillisandrew/dragonglass-poc/internal/userdirs"
 )
 
 const (
@@ -17,17 +23,98 @@ const (
 	KeyringService = "dragonglass-cli"
 	KeyringAccount = "github-token"
 
-	// Fallback file storage
-	ConfigDir = ".dragonglass"
+	// Fallback file storage in the user config directory
 	TokenFile = "credentials.json"
 )
 
+// CredentialStore selects where the GitHub token is persisted between runs
+type CredentialStore string
+
+const (
+	// StoreAuto uses the OS keychain and falls back to the credentials file
+	StoreAuto CredentialStore = "auto"
+
+	// StoreKeyring uses only the OS keychain and fails when it is unavailable
+	StoreKeyring CredentialStore = "keyring"
+
+	// StoreFile uses only credentials.json in the user config directory (mode 0600)
+	StoreFile CredentialStore = "file"
+
+	// StoreEnv reads the token from DRAGONGLASS_GITHUB_TOKEN, GITHUB_TOKEN or GH_TOKEN
+	// and never persists anything
+	StoreEnv CredentialStore = "env"
+
+	// StoreNone neither reads nor persists a token; only --github-token and
+	// registry.credentials supply credentials
+	StoreNone CredentialStore = "none"
+
+	// StoreSession marks a credential obtained for this process only, such as an Actions
+	// OIDC exchange; it cannot be selected
+	StoreSession CredentialStore = "session"
+)
+
+// TokenEnvVars are the environment variables read by StoreEnv, in precedence order
+var TokenEnvVars = []string{"DRAGONGLASS_GITHUB_TOKEN", "GITHUB_TOKEN", "GH_TOKEN"}
+
+// CredentialStores lists every supported store, in the order shown to users
+var CredentialStores = []CredentialStore{StoreAuto, StoreKeyring, StoreFile, StoreEnv, StoreNone}
+
+var (
+	activeStore       = StoreAuto
+	sessionCredential *StoredCredential
+)
+
+// ParseCredentialStore validates a store name (empty means StoreAuto)
+func ParseCredentialStore(name string) (CredentialStore, error) {
+	if name == "" {
+		return StoreAuto, nil
+	}
+	names := make([]string, 0, len(CredentialStores))
+	for _, store := range CredentialStores {
+		if string(store) == name {
+			return store, nil
+		}
+		names = append(names, string(store))
+	}
+	return "", fmt.Errorf("invalid credential store: %s (must be one of %s)", name, strings.Join(names, ", "))
+}
+
+// SetCredentialStore selects the store used by StoreToken, GetStoredCredential and ClearStoredToken
+func SetCredentialStore(store CredentialStore) {
+	activeStore = store
+}
+
+// ActiveCredentialStore returns the store selected for this process
+func ActiveCredentialStore() CredentialStore {
+	return activeStore
+}
+
+// SetSessionCredential makes cred the credential for the rest of the process, ahead of
+// the active store. It is never persisted; nil removes it.
+func SetSessionCredential(cred *StoredCredential) {
+	if cred == nil {
+		sessionCredential = nil
+		return
+	}
+	session := *cred
+	session.Store = StoreSession
+	sessionCredential = &session
+}
+
+// Persistent reports whether the store keeps tokens between runs
+func (s CredentialStore) Persistent() bool {
+	return s == StoreAuto || s == StoreKeyring || s == StoreFile
+}
+
 type StoredCredential struct {
 	Token     string    `json:"token"`
 	Scopes    string    `json:"scopes"`
 	Username  string    `json:"username,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	Source    string    `json:"source"`
+
+	// Store the credential was read from ("keyring", "file" or "env"); not persisted
+	Store CredentialStore `json:"-"`
 }
 
 // StoreToken securely stores the authentication token
@@ -40,49 +127,90 @@ func StoreToken(token, scopes, username string) error {
 		Source:    "device-flow",
 	}
 
-	// Try to store in OS keychain first
-	if err := storeInKeychain(credential); err == nil {
-		fmt.Printf("🔐 Token stored securely in OS keychain\n")
-		return nil
-	}
-
-	// Fallback to encrypted file storage
-	if err := storeInFile(credential); err != nil {
-		return fmt.Errorf("failed to store token: %w", err)
+	switch activeStore {
+	case StoreKeyring:
+		if err := storeInKeychain(credential); err != nil {
+			return fmt.Errorf("failed to store token in OS keychain: %w", err)
+		}
+		ui.Status(ui.IconLock, i18n.T("auth.stored_keychain"))
+	case StoreFile:
+		if err := storeInFile(credential); err != nil {
+			return fmt.Errorf("failed to store token: %w", err)
+		}
+		ui.Status(ui.IconLock, i18n.T("auth.stored_file"))
+	case StoreEnv, StoreNone:
+		return fmt.Errorf("credential store %q does not persist tokens; export %s instead or choose --credential-store file", activeStore, TokenEnvVars[0])
+	default:
+		// Try to store in OS keychain first
+		if err := storeInKeychain(credential); err == nil {
+			ui.Status(ui.IconLock, i18n.T("auth.stored_keychain"))
+			return nil
+		}
+
+		// Fallback to file storage
+		if err := storeInFile(credential); err != nil {
+			return fmt.Errorf("failed to store token: %w", err)
+		}
+
+		ui.Status(ui.IconLock, i18n.T("auth.stored_file_fallback"))
 	}
-
-	fmt.Printf("🔐 Token stored in encrypted file (keychain unavailable)\n")
 	return nil
 }
 
-// GetStoredCredential retrieves the stored authentication credential
+// GetStoredCredential retrieves the authentication credential from the active store
 func GetStoredCredential() (*StoredCredential, error) {
-	// Try keychain first
-	if cred, err := getFromKeychain(); err == nil {
-		return cred, nil
+	if sessionCredential != nil {
+		session := *sessionCredential
+		return &session, nil
 	}
 
-	// Try file storage
-	return getFromFile()
+	switch activeStore {
+	case StoreKeyring:
+		return getFromKeychain()
+	case StoreFile:
+		return getFromFile()
+	case StoreEnv:
+		return getFromEnv(os.LookupEnv)
+	case StoreNone:
+		return nil, dgerrors.Mark(fmt.Errorf("credential store %q does not read stored tokens (pass --github-token)", activeStore), dgerrors.ErrNotAuthenticated)
+	default:
+		// Try keychain first
+		if cred, err := getFromKeychain(); err == nil {
+			return cred, nil
+		}
+
+		// Try file storage
+		return getFromFile()
+	}
 }
 
-// ClearStoredToken removes the stored authentication token
+// ClearStoredToken removes the stored authentication token from the active store
 func ClearStoredToken() error {
-	// Clear from keychain
-	_ = keyring.Delete(KeyringService, KeyringAccount)
-
-	// Clear from file
-	configPath, err := getConfigPath()
-	if err != nil {
-		return nil // If we can't get path, nothing to clear
+	if activeStore == StoreAuto || activeStore == StoreKeyring {
+		_ = keyring.Delete(KeyringService, KeyringAccount)
 	}
 
-	tokenPath := filepath.Join(configPath, TokenFile)
-	_ = os.Remove(tokenPath)
+	if activeStore == StoreAuto || activeStore == StoreFile {
+		tokenPath, err := CredentialsPath()
+		if err != nil {
+			return nil // If we can't get path, nothing to clear
+		}
+		_ = os.Remove(tokenPath)
+	}
 
 	return nil
 }
 
+// getFromEnv reads the token from the first populated TokenEnvVars entry
+func getFromEnv(lookupEnv func(string) (string, bool)) (*StoredCredential, error) {
+	for _, name := range TokenEnvVars {
+		if token, ok := lookupEnv(name); ok && token != "" {
+			return &StoredCredential{Token: token, Source: name, Store: StoreEnv}, nil
+		}
+	}
+	return nil, dgerrors.Mark(fmt.Errorf("credential store %q requires one of %s", StoreEnv, strings.Join(TokenEnvVars, ", ")), dgerrors.ErrNotAuthenticated)
+}
+
 // storeInKeychain stores credential in OS keychain
 func storeInKeychain(cred StoredCredential) error {
 	data, err := json.Marshal(cred)
@@ -104,24 +232,23 @@ func getFromKeychain() (*StoredCredential, error) {
 	if err := json.Unmarshal([]byte(data), &cred); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal credential: %w", err)
 	}
+	cred.Store = StoreKeyring
 
 	return &cred, nil
 }
 
-// storeInFile stores credential in encrypted file
+// storeInFile stores credential in a file readable only by the current user
 func storeInFile(cred StoredCredential) error {
-	configPath, err := getConfigPath()
+	tokenPath, err := CredentialsPath()
 	if err != nil {
 		return fmt.Errorf("failed to get config path: %w", err)
 	}
 
 	// Create config directory if it doesn't exist
-	if err := os.MkdirAll(configPath, 0700); err != nil {
+	if err := os.MkdirAll(filepath.Dir(tokenPath), 0700); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	tokenPath := filepath.Join(configPath, TokenFile)
-
 	// Marshal credential to JSON
 	data, err := json.MarshalIndent(cred, "", "  ")
 	if err != nil {
@@ -138,13 +265,11 @@ func storeInFile(cred StoredCredential) error {
 
 // getFromFile retrieves credential from file
 func getFromFile() (*StoredCredential, error) {
-	configPath, err := getConfigPath()
+	tokenPath, err := CredentialsPath()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get config path: %w", err)
 	}
 
-	tokenPath := filepath.Join(configPath, TokenFile)
-
 	data, err := os.ReadFile(tokenPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read token file: %w", err)
@@ -154,16 +279,17 @@ func getFromFile() (*StoredCredential, error) {
 	if err := json.Unmarshal(data, &cred); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal credential: %w", err)
 	}
+	cred.Store = StoreFile
 
 	return &cred, nil
 }
 
-// getConfigPath returns the configuration directory path
-func getConfigPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
+// CredentialsPath returns the file used by the file credential store
+func CredentialsPath() (string, error) {
+	dirs, err := userdirs.Resolve()
 	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
+		return "", err
 	}
 
-	return filepath.Join(homeDir, ConfigDir), nil
+	return filepath.Join(dirs.Config, TokenFile), nil
 }
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+)
+
+// useStore selects store for the duration of the test
+func useStore(t *testing.T, store CredentialStore) {
+	t.Helper()
+	previous := ActiveCredentialStore()
+	SetCredentialStore(store)
+	t.Cleanup(func() { SetCredentialStore(previous) })
+}
+
+func TestParseCredentialStore(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected CredentialStore
+		wantErr  bool
+	}{
+		{name: "", expected: StoreAuto},
+		{name: "keyring", expected: StoreKeyring},
+		{name: "file", expected: StoreFile},
+		{name: "env", expected: StoreEnv},
+		{name: "none", expected: StoreNone},
+		{name: "vault", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := ParseCredentialStore(tt.name)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for %q", tt.name)
+				}
+				return
+			}
+			if err != nil || store != tt.expected {
+				t.Errorf("expected %s, got %s (err %v)", tt.expected, store, err)
+			}
+		})
+	}
+}
+
+func TestGetFromEnv(t *testing.T) {
+	env := map[string]string{"GITHUB_TOKEN": "ghs_actions", "GH_TOKEN": "gho_cli"}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	cred, err := getFromEnv(lookup)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cred.Token != "ghs_actions" || cred.Source != "GITHUB_TOKEN" || cred.Store != StoreEnv {
+		t.Errorf("unexpected credential %+v", cred)
+	}
+
+	_, err = getFromEnv(func(string) (string, bool) { return "", false })
+	if !errors.Is(err, dgerrors.ErrNotAuthenticated) {
+		t.Errorf("expected ErrNotAuthenticated, got %v", err)
+	}
+}
+
+func TestNonPersistentStores(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	for _, store := range []CredentialStore{StoreEnv, StoreNone} {
+		t.Run(string(store), func(t *testing.T) {
+			useStore(t, store)
+
+			if err := StoreToken("gho_token", DefaultRequiredScopes, "octocat"); err == nil {
+				t.Error("expected StoreToken to refuse to persist")
+			}
+			tokenPath, err := CredentialsPath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(tokenPath); !os.IsNotExist(err) {
+				t.Errorf("expected no credentials file, got %v", err)
+			}
+		})
+	}
+
+	useStore(t, StoreNone)
+	t.Setenv("GITHUB_TOKEN", "ghs_ignored")
+	if _, err := GetStoredCredential(); !errors.Is(err, dgerrors.ErrNotAuthenticated) {
+		t.Errorf("expected none store to read nothing, got %v", err)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	useStore(t, StoreFile)
+
+	if err := StoreToken("gho_token", DefaultRequiredScopes, "octocat"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tokenPath, err := CredentialsPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(tokenPath, home) {
+		t.Errorf("expected credentials under %s, got %s", home, tokenPath)
+	}
+	info, err := os.Stat(tokenPath)
+	if err != nil {
+		t.Fatalf("expected credentials file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	cred, err := GetStoredCredential()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cred.Token != "gho_token" || cred.Username != "octocat" || cred.Store != StoreFile {
+		t.Errorf("unexpected credential %+v", cred)
+	}
+
+	if err := ClearStoredToken(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(tokenPath); !os.IsNotExist(err) {
+		t.Errorf("expected credentials file to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+// ABOUTME: Loads the published catalog by digest, caching it on disk between refreshes
+// ABOUTME: Re-resolves the catalog reference once the TTL passes and falls back to a stale copy offline
+package catalog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Fetcher reads the published catalog from the registry
+type Fetcher interface {
+	// Resolve returns the manifest digest reference currently points at
+	Resolve(ctx context.Context, reference string) (string, error)
+
+	// Fetch returns the catalog pinned by digest, having verified its provenance
+	Fetch(ctx context.Context, reference, digest string) ([]byte, error)
+}
+
+// Opts configures Load
+type Opts struct {
+	// Catalog artifact reference, usually a tag such as ":latest"
+	Reference string
+
+	// How long a fetched catalog is used before the reference is resolved again
+	TTL time.Duration
+
+	// Directory fetched catalogs are cached in; empty disables the cache
+	CacheDir string
+
+	Fetcher Fetcher
+
+	// Resolve the reference even when the cached catalog is within the TTL
+	Refresh bool
+
+	// Returns the current time (default time.Now)
+	Now func() time.Time
+}
+
+// DefaultOpts returns options for the catalog at reference
+func DefaultOpts(reference string, fetcher Fetcher) *Opts {
+	return &Opts{Reference: reference, TTL: 6 * time.Hour, Fetcher: fetcher, Now: time.Now}
+}
+
+// WithTTL sets how long a fetched catalog is used before it is refreshed
+func (opts *Opts) WithTTL(ttl time.Duration) *Opts {
+	opts.TTL = ttl
+	return opts
+}
+
+// WithCacheDir sets the directory fetched catalogs are cached in
+func (opts *Opts) WithCacheDir(dir string) *Opts {
+	opts.CacheDir = dir
+	return opts
+}
+
+// WithRefresh forces the reference to be resolved regardless of the TTL
+func (opts *Opts) WithRefresh(refresh bool) *Opts {
+	opts.Refresh = refresh
+	return opts
+}
+
+// Loaded is a catalog and where it came from
+type Loaded struct {
+	*Catalog
+
+	// Manifest digest of the catalog artifact
+	Digest string
+
+	// When the digest was last resolved from the reference
+	FetchedAt time.Time
+
+	// The reference could not be resolved, so a cached catalog past its TTL was used
+	Stale bool
+}
+
+// cacheEntry is the on-disk form of a fetched catalog
+type cacheEntry struct {
+	Reference string          `json:"reference"`
+	Digest    string          `json:"digest"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Load returns the catalog at the reference. A cached catalog is used while within the TTL;
+// after that the reference is resolved again and the catalog only fetched when its digest
+// changed. When resolving fails, an expired cached catalog is returned marked stale.
+func Load(ctx context.Context, opts *Opts) (*Loaded, error) {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	now := opts.Now()
+
+	cached, err := readCache(opts)
+	if err != nil {
+		// A corrupt cache is refetched rather than failing the command
+		cached = nil
+	}
+	if cached != nil && !opts.Refresh && now.Sub(cached.FetchedAt) < opts.TTL {
+		return cached.load(false)
+	}
+
+	digest, err := opts.Fetcher.Resolve(ctx, opts.Reference)
+	if err != nil {
+		if cached != nil {
+			return cached.load(true)
+		}
+		return nil, fmt.Errorf("failed to resolve catalog %s: %w", opts.Reference, err)
+	}
+
+	if cached == nil || cached.Digest != digest {
+		data, err := opts.Fetcher.Fetch(ctx, opts.Reference, digest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch catalog %s: %w", opts.Reference, err)
+		}
+		if _, err := Parse(data); err != nil {
+			return nil, err
+		}
+		cached = &cacheEntry{Reference: opts.Reference, Digest: digest, Data: data}
+	}
+	cached.FetchedAt = now
+
+	if err := writeCache(opts, cached); err != nil {
+		return nil, err
+	}
+	return cached.load(false)
+}
+
+func (e *cacheEntry) load(stale bool) (*Loaded, error) {
+	c, err := Parse(e.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &Loaded{Catalog: c, Digest: e.Digest, FetchedAt: e.FetchedAt, Stale: stale}, nil
+}
+
+// cachePath returns the cache file of the reference, named by its hash as references hold
+// characters file names cannot
+func cachePath(opts *Opts) string {
+	sum := sha256.Sum256([]byte(opts.Reference))
+	return filepath.Join(opts.CacheDir, hex.EncodeToString(sum[:8])+".json")
+}
+
+func readCache(opts *Opts) (*cacheEntry, error) {
+	if opts.CacheDir == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(cachePath(opts))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog cache: %w", err)
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse catalog cache: %w", err)
+	}
+	if entry.Reference != opts.Reference {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+func writeCache(opts *Opts, entry *cacheEntry) error {
+	if opts.CacheDir == "" {
+		return nil
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode catalog cache: %w", err)
+	}
+	if err := os.MkdirAll(opts.CacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create catalog cache directory: %w", err)
+	}
+	// Written to a temporary file and renamed so concurrent commands never read a partial cache
+	tmp, err := os.CreateTemp(opts.CacheDir, ".catalog-*")
+	if err != nil {
+		return fmt.Errorf("failed to write catalog cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write catalog cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write catalog cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), cachePath(opts)); err != nil {
+		return fmt.Errorf("failed to write catalog cache: %w", err)
+	}
+	return nil
+}
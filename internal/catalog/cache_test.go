@@ -0,0 +1,107 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeFetcher struct {
+	digest     string
+	data       []byte
+	resolveErr error
+	resolves   int
+	fetches    int
+}
+
+func (f *fakeFetcher) Resolve(_ context.Context, _ string) (string, error) {
+	f.resolves++
+	return f.digest, f.resolveErr
+}
+
+func (f *fakeFetcher) Fetch(_ context.Context, _ string, digest string) ([]byte, error) {
+	f.fetches++
+	if digest != f.digest {
+		return nil, errors.New("unexpected digest")
+	}
+	return f.data, nil
+}
+
+func TestLoad(t *testing.T) {
+	data, err := testCatalog().Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetcher := &fakeFetcher{digest: digestA, data: data}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	opts := DefaultOpts("ghcr.io/idx/catalog:latest", fetcher).WithTTL(time.Hour).WithCacheDir(t.TempDir())
+	opts.Now = func() time.Time { return now }
+
+	load := func(wantResolves, wantFetches int, wantStale bool) *Loaded {
+		t.Helper()
+		loaded, err := Load(context.Background(), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fetcher.resolves != wantResolves || fetcher.fetches != wantFetches {
+			t.Errorf("expected %d resolves and %d fetches, got %d and %d", wantResolves, wantFetches, fetcher.resolves, fetcher.fetches)
+		}
+		if loaded.Stale != wantStale {
+			t.Errorf("expected stale %v, got %v", wantStale, loaded.Stale)
+		}
+		return loaded
+	}
+
+	// First load fetches; within the TTL the cache is used as is
+	if loaded := load(1, 1, false); loaded.Digest != digestA || len(loaded.Plugins) != 3 {
+		t.Errorf("unexpected catalog: %+v", loaded)
+	}
+	now = now.Add(30 * time.Minute)
+	load(1, 1, false)
+
+	// Past the TTL the reference is resolved again, but an unchanged digest is not refetched
+	now = now.Add(time.Hour)
+	if loaded := load(2, 1, false); !loaded.FetchedAt.Equal(now) {
+		t.Errorf("expected the fetch time to be refreshed, got %v", loaded.FetchedAt)
+	}
+
+	// Refresh ignores the TTL, and a new digest is fetched
+	c := testCatalog()
+	c.Plugins = c.Plugins[:1]
+	if fetcher.data, err = c.Marshal(); err != nil {
+		t.Fatal(err)
+	}
+	fetcher.digest = digestB
+	opts.WithRefresh(true)
+	if loaded := load(3, 2, false); loaded.Digest != digestB || len(loaded.Plugins) != 1 {
+		t.Errorf("expected the new catalog, got %+v", loaded)
+	}
+	opts.WithRefresh(false)
+
+	// Offline past the TTL, the cached catalog is used and marked stale
+	now = now.Add(2 * time.Hour)
+	fetcher.resolveErr = errors.New("network unreachable")
+	if loaded := load(4, 2, true); loaded.Digest != digestB {
+		t.Errorf("expected the cached catalog, got %+v", loaded)
+	}
+
+	// Without a cache, failing to resolve is an error
+	opts.WithCacheDir("")
+	if _, err := Load(context.Background(), opts); err == nil {
+		t.Error("expected an error without a cached catalog")
+	}
+}
+
+func TestLoadRejectsInvalidCatalog(t *testing.T) {
+	fetcher := &fakeFetcher{digest: digestA, data: []byte(`{"version": "1", "plugins": [{"id": "x", "image": "y", "releases": [{"digest": "latest"}]}]}`)}
+	opts := DefaultOpts("ghcr.io/idx/catalog:latest", fetcher).WithCacheDir(t.TempDir())
+	if _, err := Load(context.Background(), opts); err == nil {
+		t.Fatal("expected an invalid catalog to be rejected")
+	}
+
+	// Nothing was cached, so the next load fetches again
+	if _, err := Load(context.Background(), opts); err == nil || fetcher.fetches != 2 {
+		t.Errorf("expected a second fetch, got %d fetches", fetcher.fetches)
+	}
+}
@@ -0,0 +1,246 @@
+// ABOUTME: Plugin catalog compiled from the plugins index and published as a signed OCI artifact
+// ABOUTME: Lists every published plugin with its releases pinned by digest, for search and add by name
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/semver"
+)
+
+const (
+	// Version is the catalog format version
+	Version = "1"
+
+	// ArtifactType and MediaType identify the catalog artifact and its single layer
+	ArtifactType = "application/vnd.dragonglass.catalog"
+	MediaType    = "application/vnd.dragonglass.catalog.v1+json"
+
+	// FileName is the title of the catalog layer
+	FileName = "catalog.json"
+)
+
+// ErrNotFound reports a name no plugin in the catalog has
+var ErrNotFound = errors.New("plugin not found in catalog")
+
+var (
+	digestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+	commitPattern = regexp.MustCompile(`^[a-f0-9]{40}$`)
+)
+
+// Catalog lists the plugins built from the plugins index
+type Catalog struct {
+	Version     string    `json:"version"`
+	GeneratedAt time.Time `json:"generated_at"`
+
+	// Index repository the catalog was compiled from, as owner/repo, and its commit
+	Source       string `json:"source,omitempty"`
+	SourceCommit string `json:"source_commit,omitempty"`
+
+	// Sorted by ID
+	Plugins []Plugin `json:"plugins"`
+}
+
+// Plugin is a plugin and its published releases
+type Plugin struct {
+	ID          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Author      string `json:"author,omitempty"`
+
+	// Upstream GitHub repository of the newest release, as owner/repo
+	Repository string `json:"repository"`
+
+	// OCI repository releases are published to, e.g. "ghcr.io/owner/index/plugin-id"
+	Image string `json:"image"`
+
+	// Newest first
+	Releases []Release `json:"releases"`
+}
+
+// Release is a published build of a plugin version
+type Release struct {
+	Version string `json:"version"`
+
+	// Upstream GitHub repository, index tag, and commit of the build config that pinned
+	// the build
+	Repository string `json:"repository,omitempty"`
+	Tag        string `json:"tag"`
+	Commit     string `json:"commit"`
+
+	// Manifest digest of the published artifact
+	Digest string `json:"digest"`
+}
+
+// Reference returns the image reference of the release in image, pinned by digest
+func (r Release) Reference(image string) string {
+	return image + "@" + r.Digest
+}
+
+// Parse reads a catalog and checks that every release is pinned by digest
+func Parse(data []byte) (*Catalog, error) {
+	var c Catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse catalog: %w", err)
+	}
+	if c.Version != Version {
+		return nil, fmt.Errorf("unsupported catalog version %q (expected %q)", c.Version, Version)
+	}
+	for _, p := range c.Plugins {
+		if p.ID == "" || p.Image == "" {
+			return nil, fmt.Errorf("catalog plugin %q has no id or image", p.ID)
+		}
+		if strings.Count(p.Repository, "/") != 1 {
+			return nil, fmt.Errorf("catalog plugin %s has invalid repository %q", p.ID, p.Repository)
+		}
+		for _, r := range p.Releases {
+			if !digestPattern.MatchString(r.Digest) {
+				return nil, fmt.Errorf("catalog release %s %s has invalid digest %q", p.ID, r.Version, r.Digest)
+			}
+			if !commitPattern.MatchString(r.Commit) {
+				return nil, fmt.Errorf("catalog release %s %s has invalid commit %q", p.ID, r.Version, r.Commit)
+			}
+		}
+	}
+	return &c, nil
+}
+
+// Marshal encodes the catalog as it is published
+func (c *Catalog) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode catalog: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// Search returns the plugins matching every word of query in their ID, name, description,
+// or repository, best matches first: an exact ID, then matches in the ID or name. An empty
+// query matches every plugin.
+func (c *Catalog) Search(query string) []Plugin {
+	words := strings.Fields(strings.ToLower(query))
+
+	type match struct {
+		plugin Plugin
+		score  int
+	}
+	var matches []match
+	for _, p := range c.Plugins {
+		id, name := strings.ToLower(p.ID), strings.ToLower(p.Name)
+		text := strings.Join([]string{id, name, strings.ToLower(p.Description), strings.ToLower(p.Repository)}, "\n")
+
+		score := 0
+		matched := true
+		for _, word := range words {
+			switch {
+			case !strings.Contains(text, word):
+				matched = false
+			case id == word:
+				score += 3
+			case strings.Contains(id, word) || strings.Contains(name, word):
+				score += 2
+			default:
+				score++
+			}
+		}
+		if matched {
+			matches = append(matches, match{plugin: p, score: score})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int { return b.score - a.score })
+	plugins := make([]Plugin, len(matches))
+	for i, m := range matches {
+		plugins[i] = m.plugin
+	}
+	return plugins
+}
+
+// Lookup returns the plugin with ID name, or failing that the one built from the GitHub
+// repository name (owner/repo); both compare case-insensitively
+func (c *Catalog) Lookup(name string) (*Plugin, error) {
+	for i, p := range c.Plugins {
+		if strings.EqualFold(p.ID, name) {
+			return &c.Plugins[i], nil
+		}
+	}
+	var found *Plugin
+	for i, p := range c.Plugins {
+		if strings.EqualFold(p.Repository, name) {
+			if found != nil {
+				return nil, fmt.Errorf("repository %s publishes several plugins (%s, %s); use a plugin ID", name, found.ID, p.ID)
+			}
+			found = &c.Plugins[i]
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return found, nil
+}
+
+// Release returns the release of version, with or without a leading "v", or the newest
+// release when version is empty
+func (p *Plugin) Release(version string) (*Release, error) {
+	if len(p.Releases) == 0 {
+		return nil, fmt.Errorf("%s has no published releases", p.ID)
+	}
+	if version == "" {
+		return &p.Releases[0], nil
+	}
+	version = strings.TrimPrefix(version, "v")
+	for i, r := range p.Releases {
+		if strings.TrimPrefix(r.Version, "v") == version || strings.TrimPrefix(r.Tag, "v") == version {
+			return &p.Releases[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s has no published release %s", p.ID, version)
+}
+
+// Latest returns the newest release's version, or "" when there is none
+func (p *Plugin) Latest() string {
+	if len(p.Releases) == 0 {
+		return ""
+	}
+	return p.Releases[0].Version
+}
+
+// ParseName splits a plugin name argument such as "dataview", "dataview@0.5.70", or
+// "blacksmithgu/obsidian-dataview" into the name and version. ok is false for OCI image
+// references, whose first path component names a registry host.
+func ParseName(arg string) (name, version string, ok bool) {
+	first, _, hasSlash := strings.Cut(arg, "/")
+	if hasSlash && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return "", "", false
+	}
+	name, version, _ = strings.Cut(arg, "@")
+	if name == "" || strings.Contains(name, ":") {
+		return "", "", false
+	}
+	return name, version, true
+}
+
+// sortReleases orders releases newest first: semantic versions by precedence, ahead of
+// anything else, which is ordered by tag
+func sortReleases(releases []Release) {
+	slices.SortStableFunc(releases, func(a, b Release) int {
+		va, errA := semver.Parse(a.Version)
+		vb, errB := semver.Parse(b.Version)
+		switch {
+		case errA == nil && errB == nil:
+			return vb.Compare(va)
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			return strings.Compare(b.Tag, a.Tag)
+		}
+	})
+}
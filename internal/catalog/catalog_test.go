@@ -0,0 +1,407 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/index"
+)
+
+const (
+	commitA = "38d5d3fa799e1eec8f10b137eee8b2cbdc73b534"
+	commitB = "7a04a26631376a505929d44bf238646bb5c20f5d"
+	commitC = "0c4f2e1b1d7a9e8f6a5b4c3d2e1f0a9b8c7d6e5f"
+	digestA = "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	digestB = "sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	digestC = "sha256:cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc"
+)
+
+func testCatalog() *Catalog {
+	return &Catalog{
+		Version: Version,
+		Plugins: []Plugin{
+			{
+				ID: "calendar", Name: "Calendar", Description: "Daily notes in a calendar view",
+				Repository: "liamcain/obsidian-calendar-plugin", Image: "ghcr.io/idx/calendar",
+				Releases: []Release{{Version: "1.5.10", Tag: "1.5.10", Commit: commitA, Digest: digestA}},
+			},
+			{
+				ID: "dataview", Name: "Dataview", Description: "Query notes like a database",
+				Repository: "blacksmithgu/obsidian-dataview", Image: "ghcr.io/idx/dataview",
+				Releases: []Release{
+					{Version: "0.5.70", Tag: "0.5.70", Commit: commitB, Digest: digestB},
+					{Version: "0.5.68", Tag: "v0.5.68", Commit: commitC, Digest: digestC},
+				},
+			},
+			{
+				ID: "periodic-notes", Name: "Periodic Notes", Description: "Weekly and monthly notes, with calendar support",
+				Repository: "liamcain/obsidian-periodic-notes", Image: "ghcr.io/idx/periodic-notes",
+			},
+		},
+	}
+}
+
+func TestParse(t *testing.T) {
+	data, err := testCatalog().Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Plugins) != 3 || c.Plugins[1].Releases[1].Digest != digestC {
+		t.Errorf("catalog did not round-trip: %+v", c)
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(*Catalog)
+		wantErr string
+	}{
+		{name: "version", mutate: func(c *Catalog) { c.Version = "2" }, wantErr: "unsupported catalog version"},
+		{name: "digest", mutate: func(c *Catalog) { c.Plugins[0].Releases[0].Digest = "latest" }, wantErr: "invalid digest"},
+		{name: "commit", mutate: func(c *Catalog) { c.Plugins[0].Releases[0].Commit = "main" }, wantErr: "invalid commit"},
+		{name: "image", mutate: func(c *Catalog) { c.Plugins[0].Image = "" }, wantErr: "no id or image"},
+		{name: "repository", mutate: func(c *Catalog) { c.Plugins[0].Repository = "calendar" }, wantErr: "invalid repository"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCatalog()
+			tt.mutate(c)
+			data, err := c.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Parse(data); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "", want: []string{"calendar", "dataview", "periodic-notes"}},
+		{query: "calendar", want: []string{"calendar", "periodic-notes"}},
+		{query: "CALENDAR weekly", want: []string{"periodic-notes"}},
+		{query: "blacksmithgu", want: []string{"dataview"}},
+		{query: "kanban", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var ids []string
+			for _, p := range testCatalog().Search(tt.query) {
+				ids = append(ids, p.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, ids)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	c := testCatalog()
+	c.Plugins = append(c.Plugins, Plugin{ID: "periodic-extra", Repository: "liamcain/obsidian-periodic-notes", Image: "ghcr.io/idx/periodic-extra"})
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr error
+	}{
+		{name: "Dataview", want: "dataview"},
+		{name: "blacksmithgu/obsidian-dataview", want: "dataview"},
+		{name: "liamcain/obsidian-periodic-notes"},
+		{name: "kanban", wantErr: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := c.Lookup(tt.name)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", p.ID)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.ID != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, p.ID)
+			}
+		})
+	}
+}
+
+func TestRelease(t *testing.T) {
+	dataview := testCatalog().Plugins[1]
+
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{version: "", want: digestB},
+		{version: "v0.5.70", want: digestB},
+		{version: "0.5.68", want: digestC},
+		{version: "0.5.69", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			r, err := dataview.Release(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && r.Digest != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, r.Digest)
+			}
+		})
+	}
+
+	empty := testCatalog().Plugins[2]
+	if _, err := empty.Release(""); err == nil {
+		t.Error("expected an error for a plugin without releases")
+	}
+}
+
+func TestParseName(t *testing.T) {
+	tests := []struct {
+		arg     string
+		name    string
+		version string
+		ok      bool
+	}{
+		{arg: "dataview", name: "dataview", ok: true},
+		{arg: "dataview@0.5.70", name: "dataview", version: "0.5.70", ok: true},
+		{arg: "blacksmithgu/obsidian-dataview@v0.5.70", name: "blacksmithgu/obsidian-dataview", version: "v0.5.70", ok: true},
+		{arg: "ghcr.io/owner/repo/plugin:v1.0.0"},
+		{arg: "localhost/plugin"},
+		{arg: "registry:5000/plugin"},
+		{arg: "plugin:v1.0.0"},
+		{arg: "@1.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			name, version, ok := ParseName(tt.arg)
+			if name != tt.name || version != tt.version || ok != tt.ok {
+				t.Errorf("expected (%q, %q, %v), got (%q, %q, %v)", tt.name, tt.version, tt.ok, name, version, ok)
+			}
+		})
+	}
+}
+
+func TestSortReleases(t *testing.T) {
+	releases := []Release{
+		{Version: "1.0.0", Tag: "1.0.0"},
+		{Version: "nightly", Tag: "nightly"},
+		{Version: "1.10.0", Tag: "1.10.0"},
+		{Version: "1.2.0-beta.1", Tag: "1.2.0-beta.1"},
+		{Version: "1.2.0", Tag: "1.2.0"},
+	}
+	sortReleases(releases)
+
+	var versions []string
+	for _, r := range releases {
+		versions = append(versions, r.Version)
+	}
+	want := []string{"1.10.0", "1.2.0", "1.2.0-beta.1", "1.0.0", "nightly"}
+	if !slices.Equal(versions, want) {
+		t.Errorf("expected %v, got %v", want, versions)
+	}
+}
+
+type fakePublications struct {
+	tags      map[string][]string
+	manifests map[string]map[string]string
+}
+
+func (f *fakePublications) ListRepositories(_ context.Context, namespace string) ([]string, error) {
+	var repositories []string
+	for repository := range f.tags {
+		repositories = append(repositories, repository)
+	}
+	slices.Sort(repositories)
+	return repositories, nil
+}
+
+func (f *fakePublications) ListTags(_ context.Context, repository string) ([]string, error) {
+	return f.tags[repository], nil
+}
+
+func (f *fakePublications) GetManifest(_ context.Context, imageRef string) (*ocispec.Manifest, map[string]string, string, error) {
+	annotations, ok := f.manifests[imageRef]
+	if !ok {
+		return nil, nil, "", errors.New("manifest unknown")
+	}
+	return &ocispec.Manifest{Annotations: annotations}, annotations, annotations["digest"], nil
+}
+
+func annotations(id, name, version, digest string) map[string]string {
+	return map[string]string{
+		"vnd.obsidian.plugin.id":      id,
+		"vnd.obsidian.plugin.name":    name,
+		"vnd.obsidian.plugin.version": version,
+		"digest":                      digest,
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	publications := &fakePublications{
+		tags: map[string][]string{
+			"ghcr.io/idx/dataview": {"v0.5.68", "commit-" + commitC, "v0.5.70", "commit-" + commitB},
+			"ghcr.io/idx/calendar": {"latest"},
+		},
+		manifests: map[string]map[string]string{
+			"ghcr.io/idx/dataview:commit-" + commitB: annotations("dataview", "Dataview", "0.5.70", digestB),
+			"ghcr.io/idx/dataview:commit-" + commitC: annotations("dataview", "Dataview (old)", "0.5.68", digestC),
+		},
+	}
+	entries := []index.Entry{
+		{Path: "plugins/blacksmithgu/obsidian-dataview/tags/0.5.68/build.json", Repository: "blacksmithgu/obsidian-dataview", Tag: "0.5.68", Config: index.BuildConfig{Commit: commitC}},
+		{Path: "plugins/blacksmithgu/obsidian-dataview/tags/v0.5.68/build.json", Repository: "blacksmithgu/obsidian-dataview", Tag: "v0.5.68", Config: index.BuildConfig{Commit: commitC}},
+		{Path: "plugins/blacksmithgu/obsidian-dataview/tags/0.5.70/build.json", Repository: "blacksmithgu/obsidian-dataview", Tag: "0.5.70", Config: index.BuildConfig{Commit: commitB}},
+		{Path: "plugins/liamcain/obsidian-calendar-plugin/tags/1.5.10/build.json", Repository: "liamcain/obsidian-calendar-plugin", Tag: "1.5.10", Config: index.BuildConfig{Commit: commitA}},
+	}
+
+	generatedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	opts := DefaultGenerateOpts("ghcr.io/idx", "vnd.obsidian.plugin").WithSource("owner/idx", commitA)
+	opts.Now = func() time.Time { return generatedAt }
+
+	c, warnings, err := Generate(context.Background(), entries, publications, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "plugins/liamcain/obsidian-calendar-plugin/") {
+		t.Errorf("expected the unpublished calendar entry to be reported, got %v", warnings)
+	}
+	if !c.GeneratedAt.Equal(generatedAt) || c.Source != "owner/idx" || c.SourceCommit != commitA {
+		t.Errorf("unexpected catalog header: %+v", c)
+	}
+	if len(c.Plugins) != 1 {
+		t.Fatalf("expected 1 plugin, got %+v", c.Plugins)
+	}
+	p := c.Plugins[0]
+	if p.ID != "dataview" || p.Name != "Dataview" || p.Image != "ghcr.io/idx/dataview" || p.Repository != "blacksmithgu/obsidian-dataview" {
+		t.Errorf("unexpected plugin: %+v", p)
+	}
+	want := []Release{
+		{Version: "0.5.70", Repository: "blacksmithgu/obsidian-dataview", Tag: "0.5.70", Commit: commitB, Digest: digestB},
+		{Version: "0.5.68", Repository: "blacksmithgu/obsidian-dataview", Tag: "0.5.68", Commit: commitC, Digest: digestC},
+	}
+	if !slices.Equal(p.Releases, want) {
+		t.Errorf("expected releases %v, got %v", want, p.Releases)
+	}
+
+	// The generated catalog must be one clients accept
+	data, err := c.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(data); err != nil {
+		t.Errorf("generated catalog does not parse: %v", err)
+	}
+}
+
+func TestGenerateForeignRelease(t *testing.T) {
+	// Another repository's build claiming the same plugin ID lands in the same image
+	publications := &fakePublications{
+		tags: map[string][]string{
+			"ghcr.io/idx/dataview": {"commit-" + commitB, "commit-" + commitC},
+		},
+		manifests: map[string]map[string]string{
+			"ghcr.io/idx/dataview:commit-" + commitB: annotations("dataview", "Dataview", "0.5.70", digestB),
+			"ghcr.io/idx/dataview:commit-" + commitC: annotations("dataview", "Dataview", "0.5.71", digestC),
+		},
+	}
+	entries := []index.Entry{
+		{Path: "plugins/blacksmithgu/obsidian-dataview/tags/0.5.70/build.json", Repository: "blacksmithgu/obsidian-dataview", Tag: "0.5.70", Config: index.BuildConfig{Commit: commitB}},
+		{Path: "plugins/blacksmith-gu/obsidian-dataview/tags/0.5.71/build.json", Repository: "blacksmith-gu/obsidian-dataview", Tag: "0.5.71", Config: index.BuildConfig{Commit: commitC}},
+	}
+
+	c, warnings, err := Generate(context.Background(), entries, publications, DefaultGenerateOpts("ghcr.io/idx", "vnd.obsidian.plugin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "built from blacksmithgu/obsidian-dataview") {
+		t.Errorf("expected the older release's repository to be reported, got %v", warnings)
+	}
+	if len(c.Plugins) != 1 || c.Plugins[0].Repository != "blacksmith-gu/obsidian-dataview" {
+		t.Errorf("expected the newest release to name the repository, got %+v", c.Plugins)
+	}
+}
+
+func TestCheckOwnership(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		image    string
+		release  string
+		proof    Proof
+		problems int
+	}{
+		{name: "index build", source: "owner/idx", image: "ghcr.io/owner/idx/dataview", proof: ProofIndex},
+		{name: "index build, case differs", source: "Owner/Idx", image: "ghcr.io/owner/idx/dataview", proof: ProofIndex},
+		{name: "self-published", source: "owner/idx", image: "ghcr.io/blacksmithgu/obsidian-dataview/dataview", proof: ProofPublisher},
+		{name: "foreign publisher", source: "owner/idx", image: "ghcr.io/blacksmith-gu/dataview", problems: 1},
+		{name: "sibling of the index", source: "owner/idx", image: "ghcr.io/owner/idx-evil/dataview", problems: 1},
+		{name: "no source", image: "ghcr.io/owner/idx/dataview", problems: 1},
+		{name: "release of another repository", source: "owner/idx", image: "ghcr.io/owner/idx/dataview", release: "blacksmith-gu/obsidian-dataview", proof: ProofIndex, problems: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Catalog{Version: Version, Source: tt.source}
+			p := &Plugin{ID: "dataview", Repository: "blacksmithgu/obsidian-dataview", Image: tt.image}
+			release := &Release{Version: "0.5.70", Repository: tt.release, Commit: commitA, Digest: digestA}
+
+			ownership := c.CheckOwnership(p, release)
+			if ownership.Proof != tt.proof || len(ownership.Problems) != tt.problems {
+				t.Errorf("expected proof %q with %d problems, got %q with %v", tt.proof, tt.problems, ownership.Proof, ownership.Problems)
+			}
+			if ownership.Verified() != (tt.proof != "" && tt.problems == 0) {
+				t.Errorf("unexpected verified %v", ownership.Verified())
+			}
+		})
+	}
+}
+
+func TestCheckProvenance(t *testing.T) {
+	tests := []struct {
+		name       string
+		repository string
+		problems   int
+	}{
+		{name: "owner/repo", repository: "blacksmithgu/obsidian-dataview"},
+		{name: "URL", repository: "https://github.com/blacksmithgu/obsidian-dataview"},
+		{name: "host and .git suffix, case differs", repository: "github.com/BlacksmithGU/obsidian-dataview.git"},
+		{name: "another repository", repository: "blacksmith-gu/obsidian-dataview", problems: 1},
+		{name: "another repository by URL", repository: "https://github.com/blacksmith-gu/obsidian-dataview", problems: 1},
+		{name: "no repository", problems: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{ID: "dataview", Repository: "blacksmithgu/obsidian-dataview", Image: "ghcr.io/owner/idx/dataview"}
+			ownership := &Ownership{Proof: ProofIndex}
+			ownership.CheckProvenance(p, tt.repository)
+			if len(ownership.Problems) != tt.problems {
+				t.Errorf("expected %d problems, got %v", tt.problems, ownership.Problems)
+			}
+			if ownership.Verified() != (tt.problems == 0) {
+				t.Errorf("unexpected verified %v", ownership.Verified())
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+// ABOUTME: Compiles the catalog from plugins index entries and the artifacts the build workflow published
+// ABOUTME: Matches each pinned commit to the commit-<sha> tag of its build and reads the plugin metadata
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/index"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+)
+
+// commitTagPrefix prefixes the tag the build workflow gives each build, naming its source
+// commit
+const commitTagPrefix = "commit-"
+
+// Publications is the part of the registry client catalog generation reads from
+type Publications interface {
+	ListRepositories(ctx context.Context, namespace string) ([]string, error)
+	ListTags(ctx context.Context, repository string) ([]string, error)
+	GetManifest(ctx context.Context, imageRef string) (*ocispec.Manifest, map[string]string, string, error)
+}
+
+// GenerateOpts configures catalog generation
+type GenerateOpts struct {
+	// Registry namespace the build workflow publishes to, e.g. "ghcr.io/owner/index"
+	Namespace string
+
+	// Namespace of the plugin metadata annotations
+	AnnotationNamespace string
+
+	// Index repository and commit the catalog is compiled from
+	Source       string
+	SourceCommit string
+
+	// Returns the generation time (default time.Now)
+	Now func() time.Time
+}
+
+// DefaultGenerateOpts returns options for artifacts published under namespace
+func DefaultGenerateOpts(namespace, annotationNamespace string) *GenerateOpts {
+	return &GenerateOpts{Namespace: namespace, AnnotationNamespace: annotationNamespace, Now: time.Now}
+}
+
+// WithSource sets the index repository and commit recorded in the catalog
+func (opts *GenerateOpts) WithSource(source, commit string) *GenerateOpts {
+	opts.Source = source
+	opts.SourceCommit = commit
+	return opts
+}
+
+// Generate compiles the catalog of the entries whose builds were published under the
+// namespace. Entries without a published build are left out and reported in warnings, as
+// are releases of a plugin built from another upstream repository than its newest.
+func Generate(ctx context.Context, entries []index.Entry, publications Publications, opts *GenerateOpts) (catalog *Catalog, warnings []string, err error) {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	repositories, err := publications.ListRepositories(ctx, opts.Namespace)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list repositories under %s: %w", opts.Namespace, err)
+	}
+
+	// Builds are found by their commit-<sha> tags; a commit may have been built into
+	// several repositories, e.g. by a monorepo with one plugin per directory
+	published := make(map[string][]string)
+	for _, repository := range repositories {
+		tags, err := publications.ListTags(ctx, repository)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, tag := range tags {
+			if commit, ok := strings.CutPrefix(tag, commitTagPrefix); ok {
+				published[commit] = append(published[commit], repository)
+			}
+		}
+	}
+
+	parser := plugin.NewManifestParser(&plugin.PluginOpts{AnnotationNamespace: opts.AnnotationNamespace})
+	plugins := make(map[string]*Plugin)
+	described := make(map[string]*plugin.Metadata)
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		images := published[entry.Config.Commit]
+		if len(images) == 0 {
+			warnings = append(warnings, fmt.Sprintf("%s: no build of commit %s is published", entry.Path, entry.Config.Commit))
+			continue
+		}
+
+		for _, image := range images {
+			// Index tags naming the same version pin the same build once
+			key := image + "@" + entry.Config.Commit
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			manifest, annotations, digest, err := publications.GetManifest(ctx, image+":"+commitTagPrefix+entry.Config.Commit)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to fetch the build of %s: %w", entry.Path, err)
+			}
+			metadata, err := parser.ParseMetadata(manifest, annotations)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: build in %s has no plugin metadata: %v", entry.Path, image, err))
+				continue
+			}
+
+			p, ok := plugins[image]
+			if !ok {
+				p = &Plugin{Image: image}
+				plugins[image] = p
+			}
+			p.Releases = append(p.Releases, Release{
+				Version:    metadata.Version,
+				Repository: entry.Repository,
+				Tag:        entry.Tag,
+				Commit:     entry.Config.Commit,
+				Digest:     digest,
+			})
+			described[digest] = metadata
+		}
+	}
+
+	catalog = &Catalog{
+		Version:      Version,
+		GeneratedAt:  opts.Now().UTC(),
+		Source:       opts.Source,
+		SourceCommit: opts.SourceCommit,
+		Plugins:      []Plugin{},
+	}
+	for _, p := range plugins {
+		sortReleases(p.Releases)
+		// Names, descriptions, and the upstream repository come from the newest release
+		newest := described[p.Releases[0].Digest]
+		p.ID, p.Name, p.Description, p.Author = newest.ID, newest.Name, newest.Description, newest.Author
+		p.Repository = p.Releases[0].Repository
+
+		// Builds of another repository under the same plugin ID are kept, as clients check
+		// each release's repository, but the index should not have accepted them
+		for _, r := range p.Releases[1:] {
+			if !strings.EqualFold(r.Repository, p.Repository) {
+				warnings = append(warnings, fmt.Sprintf("%s: release %s is built from %s, not %s", p.Image, r.Version, r.Repository, p.Repository))
+			}
+		}
+		catalog.Plugins = append(catalog.Plugins, *p)
+	}
+	slices.SortFunc(catalog.Plugins, func(a, b Plugin) int {
+		return strings.Compare(a.ID+"\n"+a.Image, b.ID+"\n"+b.Image)
+	})
+	return catalog, warnings, nil
+}
@@ -0,0 +1,86 @@
+// ABOUTME: Ownership checks tying a catalog plugin's OCI repository to its upstream GitHub repository
+// ABOUTME: Flags entries whose publisher is neither the upstream owner nor the index, to catch typosquats
+package catalog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Proof is how a plugin's OCI repository is shown to belong to its upstream repository
+type Proof string
+
+const (
+	// ProofPublisher: the OCI repository is under the upstream repository's owner
+	ProofPublisher Proof = "publisher"
+
+	// ProofIndex: the OCI repository is under the index the catalog was compiled from, whose
+	// entry for the upstream repository pinned the build
+	ProofIndex Proof = "index"
+)
+
+// Ownership is the outcome of CheckOwnership
+type Ownership struct {
+	// Empty when ownership could not be shown
+	Proof Proof
+
+	// Why the release may not come from the plugin's upstream repository
+	Problems []string
+}
+
+// Verified reports whether ownership was shown without problems
+func (o *Ownership) Verified() bool {
+	return o.Proof != "" && len(o.Problems) == 0
+}
+
+// CheckOwnership checks that release of p was published by the owner of p's upstream
+// repository, or by the index the catalog was compiled from, and built from that repository
+func (c *Catalog) CheckOwnership(p *Plugin, release *Release) *Ownership {
+	ownership := &Ownership{}
+
+	upstreamOwner, _, _ := strings.Cut(p.Repository, "/")
+	if release.Repository != "" && !strings.EqualFold(release.Repository, p.Repository) {
+		ownership.Problems = append(ownership.Problems, fmt.Sprintf("release %s was built from %s, not %s", release.Version, release.Repository, p.Repository))
+	}
+
+	// The image path without its registry host, e.g. "owner/index/plugin-id"
+	_, path, _ := strings.Cut(p.Image, "/")
+	imageOwner, _, _ := strings.Cut(path, "/")
+	switch {
+	case upstreamOwner != "" && strings.EqualFold(imageOwner, upstreamOwner):
+		ownership.Proof = ProofPublisher
+	case c.Source != "" && strings.HasPrefix(strings.ToLower(path), strings.ToLower(c.Source)+"/"):
+		ownership.Proof = ProofIndex
+	default:
+		index := c.Source
+		if index == "" {
+			index = "the index"
+		}
+		ownership.Problems = append(ownership.Problems, fmt.Sprintf("%s is published by %s, which is neither %s, the owner of %s, nor %s", p.Image, imageOwner, upstreamOwner, p.Repository, index))
+	}
+	return ownership
+}
+
+// CheckProvenance records a problem when repository, the source repository named by the
+// verified provenance of p's release, is not p's upstream repository. Provenance is signed
+// by the builder, so unlike the catalog's data it cannot be edited by whoever compiled or
+// served the catalog.
+func (o *Ownership) CheckProvenance(p *Plugin, repository string) {
+	switch path := repositoryPath(repository); {
+	case path == "":
+		o.Problems = append(o.Problems, fmt.Sprintf("the provenance of %s names no source repository", p.Image))
+	case !strings.EqualFold(path, p.Repository):
+		o.Problems = append(o.Problems, fmt.Sprintf("the provenance of %s shows it was built from %s, not %s", p.Image, path, p.Repository))
+	}
+}
+
+// repositoryPath returns the owner/repo path of a GitHub repository given as a path or
+// URL, e.g. "https://github.com/owner/repo" or "github.com/owner/repo.git"
+func repositoryPath(repository string) string {
+	path := strings.TrimPrefix(repository, "git+")
+	if _, rest, ok := strings.Cut(path, "://"); ok {
+		path = rest
+	}
+	path = strings.TrimPrefix(path, "github.com/")
+	return strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git")
+}
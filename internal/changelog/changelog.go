@@ -0,0 +1,177 @@
+// ABOUTME: Release notes of plugin versions from the GitHub Releases API
+// ABOUTME: Looks up the release for a version tag in the source repository and trims its notes for display
+package changelog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/githubapi"
+)
+
+// Defaults for trimming release notes
+const (
+	DefaultMaxLines = 15
+	DefaultMaxChars = 1200
+)
+
+var (
+	// githubRepositoryPattern matches the repository URLs provenance records for GitHub
+	// sources, e.g. "https://github.com/owner/repo"
+	githubRepositoryPattern = regexp.MustCompile(`^https://github\.com/([A-Za-z0-9-]+/[A-Za-z0-9._-]+)$`)
+
+	// htmlCommentPattern matches the comments release templates leave in notes
+	htmlCommentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// Release is the published release of a plugin version
+type Release struct {
+	Tag         string     `json:"tag"`
+	Name        string     `json:"name,omitempty"`
+	URL         string     `json:"url"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+
+	// Release notes, trimmed for display
+	Notes string `json:"notes,omitempty"`
+
+	// Whether Notes was cut short; the full notes are at URL
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Opts configures a Client
+type Opts struct {
+	// Limits release notes are trimmed to
+	MaxLines int
+	MaxChars int
+}
+
+// DefaultOpts returns options trimming notes to DefaultMaxLines and DefaultMaxChars
+func DefaultOpts() *Opts {
+	return &Opts{
+		MaxLines: DefaultMaxLines,
+		MaxChars: DefaultMaxChars,
+	}
+}
+
+// WithLimits sets the number of lines and characters release notes are trimmed to
+func (opts *Opts) WithLimits(maxLines, maxChars int) *Opts {
+	opts.MaxLines = maxLines
+	opts.MaxChars = maxChars
+	return opts
+}
+
+// Client fetches release notes from GitHub
+type Client struct {
+	github *githubapi.Client
+	opts   *Opts
+}
+
+// NewClient returns a client fetching releases with github, configured by opts
+func NewClient(github *githubapi.Client, opts *Opts) *Client {
+	if opts == nil {
+		opts = DefaultOpts()
+	}
+	return &Client{github: github, opts: opts}
+}
+
+// Release returns the release of version in the source repository at repositoryURL, as
+// recorded in the plugin's provenance. Both the version and v<version> are tried as
+// tags. It returns nil when the repository is not on GitHub or has no release for the
+// version.
+func (c *Client) Release(ctx context.Context, repositoryURL, version string) (*Release, error) {
+	match := githubRepositoryPattern.FindStringSubmatch(repositoryURL)
+	if match == nil || version == "" {
+		return nil, nil
+	}
+	repository := match[1]
+
+	tags := []string{version}
+	if !strings.HasPrefix(version, "v") {
+		tags = append(tags, "v"+version)
+	}
+	for _, tag := range tags {
+		release, err := c.fetch(ctx, repository, tag)
+		if err != nil || release != nil {
+			return release, err
+		}
+	}
+	return nil, nil
+}
+
+// fetch returns the release tagged tag in repository ("owner/name"), or nil when there is
+// none
+func (c *Client) fetch(ctx context.Context, repository, tag string) (*Release, error) {
+	resp, err := c.github.Get(ctx, fmt.Sprintf("/repos/%s/releases/tags/%s", repository, url.PathEscape(tag)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release %s of %s: %w", tag, repository, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, githubapi.StatusError(resp, "releases", repository)
+	}
+
+	var response struct {
+		TagName     string     `json:"tag_name"`
+		Name        string     `json:"name"`
+		HTMLURL     string     `json:"html_url"`
+		Body        string     `json:"body"`
+		PublishedAt *time.Time `json:"published_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode release response: %w", err)
+	}
+
+	notes, truncated := Trim(response.Body, c.opts.MaxLines, c.opts.MaxChars)
+	return &Release{
+		Tag:         response.TagName,
+		Name:        response.Name,
+		URL:         response.HTMLURL,
+		PublishedAt: response.PublishedAt,
+		Notes:       notes,
+		Truncated:   truncated,
+	}, nil
+}
+
+// Trim shortens release notes for display: HTML comments, trailing whitespace, and runs
+// of blank lines are dropped, and the notes are cut to maxLines lines and maxChars
+// characters (no limit when zero). It reports whether anything was cut.
+func Trim(notes string, maxLines, maxChars int) (string, bool) {
+	notes = htmlCommentPattern.ReplaceAllString(strings.ReplaceAll(notes, "\r\n", "\n"), "")
+
+	var lines []string
+	for _, line := range strings.Split(notes, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" && (len(lines) == 0 || lines[len(lines)-1] == "") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	truncated := false
+	if maxLines > 0 && len(lines) > maxLines {
+		lines, truncated = lines[:maxLines], true
+	}
+	trimmed := strings.TrimRight(strings.Join(lines, "\n"), "\n")
+
+	if runes := []rune(trimmed); maxChars > 0 && len(runes) > maxChars {
+		trimmed, truncated = strings.TrimRight(string(runes[:maxChars]), " \t\n"), true
+	}
+	if truncated {
+		trimmed += "\n…"
+	}
+	return trimmed, truncated
+}
@@ -0,0 +1,106 @@
+package changelog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/githubapi"
+)
+
+func TestRelease(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("expected token to be sent, got %q", r.Header.Get("Authorization"))
+		}
+		switch r.URL.Path {
+		case "/repos/owner/plugin/releases/tags/v1.2.0":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"tag_name": "v1.2.0",
+				"name":     "Version 1.2.0",
+				"html_url": "https://github.com/owner/plugin/releases/tag/v1.2.0",
+				"body":     "<!-- template -->\r\n## Changes\r\n\r\n\r\n- Added a thing\r\n",
+			})
+		case "/repos/owner/plugin/releases/tags/2.0.0":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(githubapi.NewClient(githubapi.DefaultOpts().WithAPIURL(server.URL).WithToken("secret")), DefaultOpts())
+
+	tests := []struct {
+		name       string
+		repository string
+		version    string
+		wantTag    string
+		wantNotes  string
+		wantErr    string
+		wantCalls  int
+	}{
+		{name: "v-prefixed tag", repository: "https://github.com/owner/plugin", version: "1.2.0", wantTag: "v1.2.0", wantNotes: "## Changes\n\n- Added a thing", wantCalls: 2},
+		{name: "no release", repository: "https://github.com/owner/plugin", version: "1.1.0", wantCalls: 2},
+		{name: "not on GitHub", repository: "https://gitlab.com/owner/plugin", version: "1.2.0"},
+		{name: "API error", repository: "https://github.com/owner/plugin", version: "2.0.0", wantErr: "500", wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+			release, err := client.Release(context.Background(), tt.repository, tt.version)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if len(requests) != tt.wantCalls {
+				t.Errorf("expected %d requests, got %v", tt.wantCalls, requests)
+			}
+			if tt.wantTag == "" {
+				if release != nil {
+					t.Errorf("expected no release, got %+v", release)
+				}
+				return
+			}
+			if release == nil || release.Tag != tt.wantTag || release.Notes != tt.wantNotes {
+				t.Errorf("expected %s with notes %q, got %+v", tt.wantTag, tt.wantNotes, release)
+			}
+		})
+	}
+}
+
+func TestTrim(t *testing.T) {
+	tests := []struct {
+		name          string
+		notes         string
+		maxLines      int
+		maxChars      int
+		want          string
+		wantTruncated bool
+	}{
+		{name: "unchanged", notes: "- one\n- two", want: "- one\n- two"},
+		{name: "blank lines collapsed", notes: "\n\n# Title\n\n\n\n- one  \n\n", want: "# Title\n\n- one"},
+		{name: "comments dropped", notes: "<!-- keep\nthis out -->- one", want: "- one"},
+		{name: "line limit", notes: "a\nb\nc", maxLines: 2, want: "a\nb\n…", wantTruncated: true},
+		{name: "character limit", notes: "abcdef", maxChars: 4, want: "abcd\n…", wantTruncated: true},
+		{name: "empty", notes: "\r\n  \r\n", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := Trim(tt.notes, tt.maxLines, tt.maxChars)
+			if got != tt.want || truncated != tt.wantTruncated {
+				t.Errorf("expected %q (truncated %v), got %q (truncated %v)", tt.want, tt.wantTruncated, got, truncated)
+			}
+		})
+	}
+}
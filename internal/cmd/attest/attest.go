@@ -0,0 +1,191 @@
+// ABOUTME: Attest command attaching an SPDX SBOM attestation to a pushed plugin artifact
+// ABOUTME: Signs keyless from GitHub Actions or with a local key, and uploads it as an OCI referrer
+package attest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/auth"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/push"
+	"github.com/gillisandrew/dragonglass-poc/internal/intoto"
+	"github.com/gillisandrew/dragonglass-poc/internal/registry"
+	"github.com/gillisandrew/dragonglass-poc/internal/sigstore"
+)
+
+// Ways an attestation is signed
+const (
+	SigningKeyless = "keyless"
+	SigningKey     = "key"
+	SigningNone    = "none"
+)
+
+// AttestOptions configures the attest command
+type AttestOptions struct {
+	// Pushed artifact to attest, by tag or digest
+	ImageRef string
+
+	// SPDX 2.3 JSON document
+	SBOMPath string
+
+	// PEM private key to sign with instead of keyless signing (optional)
+	KeyPath string
+
+	// Upload an unsigned envelope when no signing identity is available
+	Unsigned bool
+}
+
+func NewAttestCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "attest <image-ref>",
+		Short: "Attach a signed SBOM attestation to a pushed plugin",
+		Long: `Create an in-toto statement carrying an SPDX SBOM for a pushed plugin artifact,
+sign it, and upload it to the registry as an OCI referrer of the artifact.
+
+In GitHub Actions with the id-token: write permission, the statement is signed
+keyless through Sigstore (Fulcio and Rekor) and uploaded as a Sigstore bundle,
+like actions/attest-sbom does. This is the form dragonglass verifies.
+
+With --key, the statement is signed with a local ECDSA or Ed25519 key and
+uploaded as a DSSE envelope. --unsigned uploads an unsigned envelope when no
+signing identity is available. Neither is trusted by dragonglass verification.
+
+The digest of the uploaded attestation is printed on stdout.
+
+Example:
+  dragonglass attest ghcr.io/me/my-plugin:1.2.3 --sbom dist/sbom.spdx.json
+  dragonglass attest ghcr.io/me/my-plugin@sha256:... --sbom sbom.spdx.json --key cosign.pem`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := AttestOptions{ImageRef: args[0]}
+			opts.SBOMPath, _ = cmd.Flags().GetString("sbom")
+			opts.KeyPath, _ = cmd.Flags().GetString("key")
+			opts.Unsigned, _ = cmd.Flags().GetBool("unsigned")
+
+			if err := runAttest(cmd.Context(), ctx, opts); err != nil {
+				ctx.Fail("Attestation failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().String("sbom", "", "SPDX 2.3 JSON document to attest")
+	cmd.Flags().String("key", "", "Sign with this PEM private key instead of keyless signing")
+	cmd.Flags().Bool("unsigned", false, "Upload an unsigned attestation when no signing identity is available")
+	_ = cmd.MarkFlagRequired("sbom")
+	return cmd
+}
+
+func runAttest(opCtx context.Context, ctx *cmd.CommandContext, opts AttestOptions) error {
+	sbom, err := os.ReadFile(opts.SBOMPath)
+	if err != nil {
+		return fmt.Errorf("failed to read SBOM: %w", err)
+	}
+
+	host, repository, _, err := registry.ParseImageReference(opts.ImageRef)
+	if err != nil {
+		return err
+	}
+
+	if err := push.RequireScopes(ctx, "attest"); err != nil {
+		return err
+	}
+
+	client, err := push.NewRegistryClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	subject, err := client.Resolve(opCtx, opts.ImageRef)
+	if err != nil {
+		return err
+	}
+
+	// Like actions/attest-sbom, the subject is named after the repository without a tag
+	statement, err := intoto.NewSBOMStatement(host+"/"+repository, subject.Digest.String(), sbom)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return fmt.Errorf("failed to encode statement: %w", err)
+	}
+
+	data, mediaType, signing, err := signPayload(opCtx, payload, opts)
+	if err != nil {
+		return err
+	}
+	if signing != SigningKeyless {
+		ctx.Logger.Warn("Attestation is not keyless signed; dragonglass verification will not trust it", ctx.Logger.Args("signing", signing))
+	}
+
+	desc, err := client.AttachAttestation(opCtx, opts.ImageRef, subject, mediaType, statement.PredicateType, data)
+	if err != nil {
+		return err
+	}
+	ctx.Logger.Info("SBOM attestation attached", ctx.Logger.Args("subject", subject.Digest.String(), "signing", signing, "digest", desc.Digest.String()))
+
+	fmt.Println(desc.Digest.String())
+	return nil
+}
+
+// signPayload signs an encoded statement with the key in opts when set, and otherwise
+// keyless through Sigstore when the job has an Actions OIDC identity. It returns the
+// attestation, its media type, and how it was signed.
+func signPayload(ctx context.Context, payload []byte, opts AttestOptions) ([]byte, string, string, error) {
+	if opts.KeyPath != "" {
+		signer, err := intoto.LoadSigner(opts.KeyPath)
+		if err != nil {
+			return nil, "", "", err
+		}
+		envelope, err := signer.SignPayload(payload)
+		if err != nil {
+			return nil, "", "", err
+		}
+		data, err := json.Marshal(envelope)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("failed to encode attestation: %w", err)
+		}
+		return data, registry.MediaTypeDSSEEnvelope, SigningKey, nil
+	}
+
+	if auth.ActionsOIDCAvailable(os.Getenv) {
+		idToken, err := auth.RequestActionsIDToken(ctx, auth.DefaultOIDCOpts().WithAudience(sigstore.SigningAudience))
+		if err != nil {
+			return nil, "", "", err
+		}
+		data, err := sigstore.SignKeyless(ctx, payload, intoto.PayloadType, idToken)
+		if err != nil {
+			return nil, "", "", err
+		}
+		return data, registry.MediaTypeSigstoreBundle, SigningKeyless, nil
+	}
+
+	if !opts.Unsigned {
+		return nil, "", "", fmt.Errorf("no signing identity is available (run in GitHub Actions with the id-token: write permission, pass --key, or pass --unsigned)")
+	}
+	data, err := unsignedEnvelope(payload)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return data, registry.MediaTypeDSSEEnvelope, SigningNone, nil
+}
+
+// unsignedEnvelope wraps payload in a DSSE envelope without signatures
+func unsignedEnvelope(payload []byte) ([]byte, error) {
+	envelope := intoto.Envelope{
+		PayloadType: intoto.PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []intoto.Signature{},
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode attestation: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,90 @@
+package attest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/auth"
+	"github.com/gillisandrew/dragonglass-poc/internal/intoto"
+	"github.com/gillisandrew/dragonglass-poc/internal/registry"
+)
+
+func TestSignPayload(t *testing.T) {
+	// Keep the test from picking up a real Actions OIDC identity
+	t.Setenv(auth.ActionsIDTokenURLEnv, "")
+	t.Setenv(auth.ActionsIDTokenTokenEnv, "")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	statement, err := intoto.NewSBOMStatement("ghcr.io/me/my-plugin", "sha256:abc123", []byte(`{"spdxVersion": "SPDX-2.3"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		opts       AttestOptions
+		signing    string
+		signatures int
+		errorMsg   string
+	}{
+		{name: "local key", opts: AttestOptions{KeyPath: keyPath}, signing: SigningKey, signatures: 1},
+		{name: "unsigned", opts: AttestOptions{Unsigned: true}, signing: SigningNone},
+		{name: "no identity", opts: AttestOptions{}, errorMsg: "no signing identity is available"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, mediaType, signing, err := signPayload(context.Background(), payload, tt.opts)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if signing != tt.signing || mediaType != registry.MediaTypeDSSEEnvelope {
+				t.Errorf("expected %s signed DSSE envelope, got %s %s", tt.signing, signing, mediaType)
+			}
+
+			var envelope intoto.Envelope
+			if err := json.Unmarshal(data, &envelope); err != nil {
+				t.Fatal(err)
+			}
+			if envelope.PayloadType != intoto.PayloadType || len(envelope.Signatures) != tt.signatures {
+				t.Errorf("unexpected envelope: %+v", envelope)
+			}
+			if tt.signatures > 0 {
+				if _, err := envelope.Verify(key.Public()); err != nil {
+					t.Errorf("expected the envelope to be signed by the key: %v", err)
+				}
+			}
+		})
+	}
+}
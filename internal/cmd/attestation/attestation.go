@@ -0,0 +1,116 @@
+// ABOUTME: Attestation command group for working with a plugin's published attestations
+// ABOUTME: Downloads the raw provenance and SBOM evidence of an artifact for archiving
+package attestation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	dgattestation "github.com/gillisandrew/dragonglass-poc/internal/attestation"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/verify"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/evidence"
+)
+
+func NewAttestationCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "attestation",
+		Short: "Work with the attestations published for a plugin",
+	}
+
+	cmd.AddCommand(newDownloadCommand(ctx))
+	return cmd
+}
+
+func newDownloadCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "download <image-ref> --output <dir>",
+		Short: "Download a plugin's provenance and SBOM attestations for audit",
+		Long: `Download the attestations published for a plugin artifact and write them to a
+directory, so security teams can archive the evidence that the plugin was
+verified at a point in time.
+
+Each attestation is written exactly as fetched (Sigstore bundles as
+NN-<kind>.sigstore.json). The DSSE envelope inside each bundle is extracted to
+NN-<kind>.intoto.json and the SPDX document of each SBOM to NN-sbom.spdx.json.
+summary.json records the artifact digest, the verification outcome under the
+current policy, and the SHA-256 of every file written.
+
+The evidence is written even when verification fails, and the command then
+exits with the verification failure.
+
+Example:
+  dragonglass attestation download ghcr.io/owner/repo:plugin-name-v1.0.0 --output evidence/`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			outputDir, _ := cmd.Flags().GetString("output")
+
+			if err := runDownload(cmd.Context(), ctx, args[0], outputDir); err != nil {
+				ctx.Fail("Attestation download failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", "", "Directory to write the attestations and summary.json to")
+	_ = cmd.MarkFlagRequired("output")
+	return cmd
+}
+
+func runDownload(opCtx context.Context, ctx *cmd.CommandContext, imageRef, outputDir string) (err error) {
+	cfg := ctx.Config
+
+	verifier, err := verify.NewAttestationVerifier(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Create context bounded by verification.timeout
+	opCtx, cancel := context.WithTimeout(opCtx, cfg.Verification.Timeout.Duration())
+	defer cancel()
+	defer func() { err = cfg.WrapTimeout(opCtx, "verification.timeout", err) }()
+
+	ctx.Logger.Info("Fetching attestations", ctx.Logger.Args("imageRef", imageRef))
+	result, err := verifier.VerifyAttestations(opCtx, imageRef)
+	if err != nil {
+		return fmt.Errorf("failed to fetch attestations: %w", err)
+	}
+	if !result.Found {
+		return fmt.Errorf("%w for %s", dgerrors.ErrAttestationNotFound, imageRef)
+	}
+
+	summary := Summarize(imageRef, result, time.Now().UTC())
+	if err := evidence.Write(outputDir, result.Blobs, summary); err != nil {
+		return err
+	}
+	ctx.Logger.Info("Wrote attestation evidence", ctx.Logger.Args("output", outputDir, "files", len(summary.Files), "verified", summary.Verified))
+
+	return result.Err()
+}
+
+// Summarize describes the verification result for imageRef at downloadedAt for the
+// evidence summary
+func Summarize(imageRef string, result *dgattestation.VerificationResult, downloadedAt time.Time) *evidence.Summary {
+	summary := &evidence.Summary{
+		Ref:            imageRef,
+		ArtifactDigest: result.ArtifactDigest,
+		DownloadedAt:   downloadedAt,
+		Verified:       result.Err() == nil,
+		TrustedBuilder: result.TrustedBuilder,
+		Errors:         result.Errors(),
+		Warnings:       result.Warnings(),
+	}
+	if result.SLSA != nil {
+		summary.Builder = result.SLSA.Builder
+		summary.SourceRepository = result.SLSA.Repository
+		summary.Workflow = result.SLSA.Workflow
+	}
+	if result.SBOM != nil {
+		summary.SBOMComponents = result.SBOM.Components
+		summary.Vulnerabilities = len(result.SBOM.Vulnerabilities)
+	}
+	return summary
+}
@@ -3,11 +3,18 @@
 package auth
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 
+	dgauth "github.com/gillisandrew/dragonglass-poc/internal/auth"
 	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
-	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
+	"github.com/gillisandrew/dragonglass-poc/internal/ui"
 )
 
 func NewAuthCommand(ctx *cmd.CommandContext) *cobra.Command {
@@ -18,9 +25,27 @@ func NewAuthCommand(ctx *cmd.CommandContext) *cobra.Command {
 This command will guide you through the OAuth device flow process
 and securely store your authentication credentials.
 
-The authentication uses the same proven flow as the GitHub CLI (gh).`,
+The authentication uses the same proven flow as the GitHub CLI (gh).
+
+Credentials are kept in the store selected by --credential-store (or
+auth.credential_store / DRAGONGLASS_CREDENTIAL_STORE):
+  auto     OS keychain, falling back to credentials.json in the user config dir
+  keyring  OS keychain only
+  file     credentials.json in the user config dir only (mode 0600)
+
+Run 'dragonglass env' to see the credentials file path.
+  env      DRAGONGLASS_GITHUB_TOKEN, GITHUB_TOKEN or GH_TOKEN; nothing is persisted
+  none     nothing is read or persisted; only --github-token applies
+
+Publishing with push or attest needs the write:packages scope. Add it to an
+existing sign-in with --scope write:packages; the scopes already granted are
+requested again and the current credential is kept until the new one is issued.
+
+Set auth.oauth_client_id (or DRAGONGLASS_OAUTH_CLIENT_ID) to sign in through your own
+GitHub App or OAuth app instead of the dragonglass GitHub App.`,
 		Run: func(cmd *cobra.Command, args []string) {
-			err := runAuthCommand(ctx)
+			scopes, _ := cmd.Flags().GetStringSlice("scope")
+			err := runAuthCommand(ctx, scopes)
 			if err != nil {
 				ctx.Logger.Error("Authentication failed", ctx.Logger.Args("error", err))
 				return
@@ -28,40 +53,53 @@ The authentication uses the same proven flow as the GitHub CLI (gh).`,
 		},
 	}
 
+	cmd.Flags().StringSlice("scope", nil, "Additional OAuth scope to request, e.g. write:packages for push and attest (repeatable)")
 	cmd.AddCommand(newStatusCommand(ctx))
 	cmd.AddCommand(newLogoutCommand(ctx))
 
 	return cmd
 }
 
-func runAuthCommand(ctx *cmd.CommandContext) error {
+func runAuthCommand(ctx *cmd.CommandContext, scopes []string) error {
 	authService := ctx.AuthService
+	store := dgauth.ActiveCredentialStore()
+
+	// Signed in without a requested scope: sign in again for the union of scopes,
+	// keeping the current credential until the new token is issued
+	authenticated := authService.IsAuthenticated()
+	var scopeErr *dgerrors.InsufficientScopeError
+	escalate := authenticated && errors.As(dgauth.CheckScopes(scopes), &scopeErr)
+	if escalate {
+		ui.Info(i18n.T("auth.scope.adding", strings.Join(scopeErr.Missing, ", ")))
+	}
 
 	// Check if already authenticated
-	if authService.IsAuthenticated() {
+	if authenticated && !escalate {
 		username, err := authService.GetUser()
 		if err != nil {
 			// Don't fail completely if we can't get username details
-			username = "authenticated user"
+			username = i18n.T("auth.unknown_user")
 		}
 
-		// Load configuration to show registry
-		configOpts := config.DefaultConfigOpts()
-		if ctx.ConfigPath != "" {
-			configOpts = configOpts.WithConfigPath(ctx.ConfigPath)
-		}
-		configManager := config.NewConfigManager(configOpts)
-		cfg, _, err := configManager.LoadConfig()
-		if err != nil {
-			cfg = config.DefaultConfig()
-		}
-
-		pterm.Success.Printfln("Already authenticated as %s", pterm.LightCyan(username))
-		pterm.Info.Printfln("Registry configured: %s", pterm.LightBlue(cfg.Registry.DefaultRegistry))
-		pterm.Info.Println("Use 'dragonglass auth status' to view details")
+		ui.Success(i18n.T("auth.already", pterm.LightCyan(username)))
+		ui.Info(i18n.T("auth.registry", pterm.LightBlue(ctx.Config.Registry.DefaultRegistry)))
+		ui.Info(i18n.T("auth.status_hint"))
 		return nil
 	}
 
+	// Refuse before the device flow rather than discarding the token afterwards
+	if !store.Persistent() {
+		return fmt.Errorf("credential store %q does not persist tokens; export %s or use --credential-store file", store, dgauth.TokenEnvVars[0])
+	}
+
+	if err := dgauth.CheckClientID(); err != nil {
+		return err
+	}
+
+	if len(scopes) > 0 {
+		return authService.RequestScopes(scopes)
+	}
+
 	// Run device flow authentication
 	return authService.Authenticate()
 }
@@ -73,10 +111,15 @@ func newStatusCommand(ctx *cmd.CommandContext) *cobra.Command {
 		Long:  `Display current authentication status and user information.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			authService := ctx.AuthService
+			store := dgauth.ActiveCredentialStore()
 
 			if !authService.IsAuthenticated() {
-				pterm.Warning.Printfln("Not authenticated with GitHub")
-				pterm.Info.Println("Run 'dragonglass auth' to authenticate")
+				ui.Warning(i18n.T("auth.not_authenticated", store))
+				if store.Persistent() {
+					ui.Info(i18n.T("auth.run_auth_hint"))
+				} else {
+					ui.Info(i18n.T("auth.set_env_hint", dgauth.TokenEnvVars[0]))
+				}
 				return
 			}
 
@@ -109,7 +152,8 @@ func newStatusCommand(ctx *cmd.CommandContext) *cobra.Command {
 					"token", maskedToken,
 					"scopes", cred.Scopes,
 					"registry", "ghcr.io",
-					"storage", cred.Source,
+					"store", describeStore(store, cred.Store),
+					"source", cred.Source,
 					"created", cred.CreatedAt.Format("2006-01-02 15:04:05"),
 				),
 			)
@@ -125,15 +169,20 @@ func newLogoutCommand(ctx *cmd.CommandContext) *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			authService := ctx.AuthService
 
+			if store := dgauth.ActiveCredentialStore(); !store.Persistent() {
+				ui.Info(i18n.T("auth.logout_not_persistent", store))
+				return
+			}
+
 			if !authService.IsAuthenticated() {
-				pterm.Warning.Println("Not currently authenticated")
+				ui.Warning(i18n.T("auth.not_currently"))
 				return
 			}
 
 			// Get username before logout for confirmation
 			username, _ := authService.GetUser()
 			if username == "" {
-				username = "authenticated user"
+				username = i18n.T("auth.unknown_user")
 			}
 
 			// Clear stored credentials
@@ -142,12 +191,20 @@ func newLogoutCommand(ctx *cmd.CommandContext) *cobra.Command {
 				return
 			}
 
-			pterm.Success.Printfln("Successfully logged out %s", username)
-			pterm.Info.Println("All stored credentials have been removed")
+			ui.Success(i18n.T("auth.logged_out", username))
+			ui.Info(i18n.T("auth.credentials_removed"))
 		},
 	}
 }
 
+// describeStore names the active store and, for auto, the backend that held the token
+func describeStore(active dgauth.CredentialStore, used string) string {
+	if used == "" || used == string(active) {
+		return string(active)
+	}
+	return fmt.Sprintf("%s (%s)", active, used)
+}
+
 func maskToken(token string) string {
 	if len(token) <= 8 {
 		return "********"
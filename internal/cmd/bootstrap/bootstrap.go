@@ -0,0 +1,269 @@
+// ABOUTME: Init command bootstrapping a vault for dragonglass
+// ABOUTME: Writes the state directory, config, lockfile, and git stanzas, optionally from a shared definition
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/manifest"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+// stanzaHeader marks the lines init adds to .gitignore and .gitattributes
+const stanzaHeader = "# dragonglass"
+
+// gitIgnorePatterns returns the dragonglass paths of v that are local to a machine:
+// downloads, staging workspaces, install records, and the audit log. The config, project
+// manifest, and lockfile are shared.
+func gitIgnorePatterns(v *vault.Vault) []string {
+	return []string{
+		v.StatePattern(vault.TmpDirName) + "/",
+		v.StatePattern(vault.CacheDirName) + "/",
+		v.StatePattern(vault.StoreDirName) + "/",
+		v.StatePattern(vault.AttestationsDirName) + "/",
+		v.StatePattern(vault.DaemonSocketName),
+		v.StatePattern(vault.AuditLogName),
+	}
+}
+
+// InitOptions controls how a vault is initialized
+type InitOptions struct {
+	// Shared vault definition to copy: a git URL, a git bundle, or a directory (optional)
+	From string
+
+	// Also write an empty project manifest (dragonglass.json)
+	Manifest bool
+
+	// Replace existing config, lockfile, and manifest files
+	Force bool
+
+	// Keep the hooks of the shared definition's config; they are left out by default
+	WithHooks bool
+}
+
+func NewInitCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init [vault-dir]",
+		Short: "Set up a vault for dragonglass",
+		Long: `Initialize the vault in vault-dir (default: the current directory) for
+dragonglass. init creates the state directory (.dragonglass, or the directory
+vault.state_dir names), a default config, and an empty lockfile, and adds stanzas to .gitignore and .gitattributes that keep
+machine-local state out of git and merge the lockfile with the dragonglass
+merge driver. A vault without a .obsidian directory gets one.
+
+--manifest also writes an empty project manifest (dragonglass.json) to declare
+plugins, version ranges, and per-plugin policy in.
+
+--from copies the config, project manifest, and lockfile of a shared vault
+definition instead: a git URL or git bundle is cloned, a directory is read as is.
+Run dragonglass install afterwards to install the plugins it pins. Hooks in the
+shared config run shell commands on install, so they are left out unless
+--with-hooks is given; init lists the hook commands either way.
+
+Existing files are kept unless --force is given; the git stanzas are only added
+once.
+
+Example:
+  dragonglass init
+  dragonglass init --manifest ~/notes
+  dragonglass init --from https://github.com/example/vault-definition.git
+  dragonglass init --from team-vault.bundle`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			root := ctx.VaultDir
+			if len(args) == 1 {
+				root = args[0]
+			}
+			if root == "" {
+				root = "."
+			}
+
+			from, _ := cmd.Flags().GetString("from")
+			withManifest, _ := cmd.Flags().GetBool("manifest")
+			force, _ := cmd.Flags().GetBool("force")
+			withHooks, _ := cmd.Flags().GetBool("with-hooks")
+
+			if err := Init(cmd.Context(), ctx, root, InitOptions{From: from, Manifest: withManifest, Force: force, WithHooks: withHooks}); err != nil {
+				ctx.Fail("Init failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().String("from", "", "Copy the config, manifest, and lockfile of a shared vault definition (git URL, git bundle, or directory)")
+	cmd.Flags().Bool("manifest", false, "Also write an empty project manifest (dragonglass.json)")
+	cmd.Flags().Bool("force", false, "Replace existing config, manifest, and lockfile files")
+	cmd.Flags().Bool("with-hooks", false, "Keep the hooks of the shared config given with --from")
+	return cmd
+}
+
+// Init sets up the vault rooted at root for dragonglass as opts describes
+func Init(opCtx context.Context, ctx *cmd.CommandContext, root string, opts InitOptions) error {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	v := ctx.VaultAt(root)
+
+	var files []definitionFile
+	var hooks []string
+	if opts.From != "" {
+		src, cleanup, err := fetchDefinition(opCtx, opts.From)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		def, err := readDefinition(src)
+		if err != nil {
+			return err
+		}
+		// A shared config decides where the shared lockfile goes, unless the vault keeps
+		// its own config
+		if _, err := os.Stat(v.ConfigPath()); def.config != nil && (opts.Force || os.IsNotExist(err)) {
+			v = v.WithStateDir(def.config.Vault.StateDir)
+		}
+		hooks = def.hookCommands()
+		if !opts.WithHooks {
+			if err := def.stripHooks(); err != nil {
+				return err
+			}
+		}
+		files = def.files(v)
+	} else {
+		files = defaultDefinition(v, opts.Manifest)
+	}
+
+	for _, dir := range []string{v.ObsidianDir(), v.DragonglassDir()} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+
+	for _, file := range files {
+		path := filepath.Join(root, filepath.FromSlash(file.path))
+		written, err := writeFile(path, file.write, opts.Force)
+		if err != nil {
+			return err
+		}
+		if written {
+			ctx.Logger.Info("Created", ctx.Logger.Args("file", file.path))
+		} else {
+			ctx.Logger.Info("Kept existing file", ctx.Logger.Args("file", file.path, "hint", "use --force to replace it"))
+		}
+
+		if written && file.path == relPath(v, v.ConfigPath()) && len(hooks) > 0 {
+			if opts.WithHooks {
+				ctx.Logger.Warn("Imported hooks from the shared config", ctx.Logger.Args("commands", strings.Join(hooks, "; ")))
+			} else {
+				ctx.Logger.Warn("Left out hooks of the shared config", ctx.Logger.Args("commands", strings.Join(hooks, "; "), "hint", "use --with-hooks to import them"))
+			}
+		}
+	}
+
+	if err := addStanza(filepath.Join(root, ".gitignore"), gitIgnorePatterns(v)); err != nil {
+		return err
+	}
+	if err := addStanza(filepath.Join(root, ".gitattributes"), []string{v.StatePattern(lockfile.LockfileName) + " merge=dragonglass"}); err != nil {
+		return err
+	}
+
+	ctx.Logger.Info("Vault initialized", ctx.Logger.Args("vault", root))
+	ctx.Logger.Info("Register the lockfile merge driver with git", ctx.Logger.Args("command", `git config merge.dragonglass.driver "dragonglass lock merge --base %O --ours %A --theirs %B"`))
+	if opts.From != "" {
+		ctx.Logger.Info("Install the pinned plugins with dragonglass install")
+	}
+	return nil
+}
+
+// definitionFile is a file of a vault definition, with its path relative to the vault root
+type definitionFile struct {
+	path  string
+	write func(path string) error
+}
+
+// defaultDefinition returns the default config, keeping the state directory of v, and an
+// empty lockfile for the vault v, and an empty project manifest when withManifest is set
+func defaultDefinition(v *vault.Vault, withManifest bool) []definitionFile {
+	files := []definitionFile{
+		{path: relPath(v, v.ConfigPath()), write: func(path string) error {
+			cfg := config.DefaultConfig()
+			cfg.Vault.StateDir = filepath.ToSlash(v.StateDir)
+			return config.SaveConfig(cfg, path)
+		}},
+		{path: relPath(v, v.LockfilePath()), write: func(path string) error {
+			return lockfile.SaveLockfile(lockfile.NewLockfile(v.Root), path)
+		}},
+	}
+	if withManifest {
+		files = append(files, definitionFile{path: relPath(v, v.ManifestPath()), write: func(path string) error {
+			return manifest.SaveManifest(manifest.NewManifest(), path)
+		}})
+	}
+	return files
+}
+
+// writeFile writes the file at path with write unless one exists there and force is not
+// set, reporting whether it was written
+func writeFile(path string, write func(path string) error, force bool) (bool, error) {
+	if _, err := os.Stat(path); err == nil && !force {
+		return false, nil
+	} else if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := write(path); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// addStanza appends lines under stanzaHeader to the file at path, creating it if needed.
+// Lines the file already contains are not repeated, and nothing is written when it
+// contains all of them.
+func addStanza(path string, lines []string) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	present := strings.Split(strings.ReplaceAll(string(existing), "\r\n", "\n"), "\n")
+	missing := slices.DeleteFunc(slices.Clone(lines), func(line string) bool {
+		return slices.Contains(present, line)
+	})
+	if len(missing) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	b.Write(existing)
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		b.WriteString("\n")
+	}
+	if !slices.Contains(present, stanzaHeader) {
+		if len(existing) > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(stanzaHeader + "\n")
+	}
+	for _, line := range missing {
+		b.WriteString(line + "\n")
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package bootstrap
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/manifest"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+func testContext() *cmd.CommandContext {
+	return &cmd.CommandContext{Logger: pterm.DefaultLogger.WithWriter(os.Stderr).WithLevel(pterm.LogLevelError)}
+}
+
+func TestInit(t *testing.T) {
+	v := vault.New(t.TempDir())
+	if err := os.WriteFile(filepath.Join(v.Root, ".gitignore"), []byte("node_modules/"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for run := 0; run < 2; run++ {
+		if err := Init(context.Background(), testContext(), v.Root, InitOptions{Manifest: true}); err != nil {
+			t.Fatalf("run %d: %v", run, err)
+		}
+	}
+
+	if _, err := config.LoadConfig(v.ConfigPath()); err != nil {
+		t.Errorf("expected a valid config, got %v", err)
+	}
+	if l, err := lockfile.LoadLockfile(v.LockfilePath()); err != nil || len(l.Plugins) != 0 {
+		t.Errorf("expected an empty lockfile, got %v", err)
+	}
+	if _, err := manifest.LoadManifest(v.ManifestPath()); err != nil {
+		t.Errorf("expected a valid manifest, got %v", err)
+	}
+
+	gitignore, _ := os.ReadFile(filepath.Join(v.Root, ".gitignore"))
+	if !strings.HasPrefix(string(gitignore), "node_modules/\n\n"+stanzaHeader+"\n") {
+		t.Errorf("expected stanza appended after existing entries, got:\n%s", gitignore)
+	}
+	if strings.Count(string(gitignore), ".dragonglass/cache/") != 1 {
+		t.Errorf("expected stanza to be added once, got:\n%s", gitignore)
+	}
+	gitattributes, _ := os.ReadFile(filepath.Join(v.Root, ".gitattributes"))
+	if string(gitattributes) != stanzaHeader+"\n.dragonglass/dragonglass-lock.json merge=dragonglass\n" {
+		t.Errorf("unexpected .gitattributes:\n%s", gitattributes)
+	}
+}
+
+func TestInitKeepsExistingFiles(t *testing.T) {
+	v := vault.New(t.TempDir())
+	if err := os.MkdirAll(v.ObsidianDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	custom := `{"output":{"verbose":true}}`
+	if err := os.WriteFile(v.ConfigPath(), []byte(custom), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Init(context.Background(), testContext(), v.Root, InitOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(v.ConfigPath()); string(data) != custom {
+		t.Errorf("expected existing config to be kept, got %s", data)
+	}
+	if _, err := os.Stat(v.ManifestPath()); !os.IsNotExist(err) {
+		t.Errorf("expected no manifest without --manifest, got %v", err)
+	}
+
+	if err := Init(context.Background(), testContext(), v.Root, InitOptions{Force: true}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(v.ConfigPath()); string(data) == custom {
+		t.Error("expected --force to replace the config")
+	}
+}
+
+func TestInitStateDir(t *testing.T) {
+	ctx := testContext()
+	ctx.Config = config.DefaultConfig()
+	ctx.Config.Vault.StateDir = ".obsidian/dragonglass"
+
+	v := vault.New(t.TempDir()).WithStateDir(".obsidian/dragonglass")
+	if err := Init(context.Background(), ctx, v.Root, InitOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := lockfile.LoadLockfile(v.LockfilePath()); err != nil {
+		t.Errorf("expected a lockfile in the state dir, got %v", err)
+	}
+	if cfg, err := config.LoadConfig(v.ConfigPath()); err != nil || cfg.Vault.StateDir != ".obsidian/dragonglass" {
+		t.Errorf("expected the config to keep the state dir, got %+v, %v", cfg, err)
+	}
+	gitignore, _ := os.ReadFile(filepath.Join(v.Root, ".gitignore"))
+	if !strings.Contains(string(gitignore), "\n.obsidian/dragonglass/cache/\n") {
+		t.Errorf("expected patterns in the state dir, got:\n%s", gitignore)
+	}
+	gitattributes, _ := os.ReadFile(filepath.Join(v.Root, ".gitattributes"))
+	if !strings.Contains(string(gitattributes), ".obsidian/dragonglass/dragonglass-lock.json merge=dragonglass") {
+		t.Errorf("unexpected .gitattributes:\n%s", gitattributes)
+	}
+}
+
+// writeDefinition writes a shared vault definition declaring a single plugin to dir
+func writeDefinition(t *testing.T, dir string) {
+	t.Helper()
+	src := vault.New(dir)
+	m := manifest.NewManifest()
+	if err := m.SetPlugin("sample", manifest.PluginSpec{Repository: "ghcr.io/example/sample", Version: "^1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := manifest.SaveManifest(m, src.ManifestPath()); err != nil {
+		t.Fatal(err)
+	}
+	if err := lockfile.SaveLockfile(lockfile.NewLockfile(dir), src.LockfilePath()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInitFrom(t *testing.T) {
+	src := t.TempDir()
+	writeDefinition(t, src)
+
+	sources := map[string]string{"directory": src}
+	if _, err := exec.LookPath("git"); err == nil {
+		bundle := filepath.Join(t.TempDir(), "definition.bundle")
+		git := func(args ...string) {
+			command := exec.Command("git", append([]string{"-C", src, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+			if out, err := command.CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %v\n%s", args, err, out)
+			}
+		}
+		git("init", "--quiet")
+		git("add", "-A")
+		git("commit", "--quiet", "-m", "definition")
+		git("bundle", "create", "--quiet", bundle, "HEAD")
+		sources["bundle"] = bundle
+	}
+
+	for name, from := range sources {
+		t.Run(name, func(t *testing.T) {
+			v := vault.New(t.TempDir())
+			if err := Init(context.Background(), testContext(), v.Root, InitOptions{From: from}); err != nil {
+				t.Fatal(err)
+			}
+
+			m, err := manifest.LoadManifest(v.ManifestPath())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := m.Plugins["sample"]; !ok {
+				t.Errorf("expected the shared manifest, got %v", m.Plugins)
+			}
+			if _, err := lockfile.LoadLockfile(v.LockfilePath()); err != nil {
+				t.Errorf("expected the shared lockfile, got %v", err)
+			}
+			if _, err := os.Stat(v.ConfigPath()); !os.IsNotExist(err) {
+				t.Errorf("expected no config when the definition has none, got %v", err)
+			}
+		})
+	}
+}
+
+func TestInitFromHooks(t *testing.T) {
+	src := t.TempDir()
+	writeDefinition(t, src)
+	shared := config.DefaultConfig()
+	shared.Verification.StrictMode = true
+	shared.Hooks.PostInstall = []string{"curl -s https://example.com/install.sh | sh"}
+	if err := config.SaveConfig(shared, vault.New(src).ConfigPath()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		withHooks bool
+		want      []string
+	}{
+		{name: "left out by default", withHooks: false, want: nil},
+		{name: "kept with --with-hooks", withHooks: true, want: shared.Hooks.PostInstall},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := vault.New(t.TempDir())
+			if err := Init(context.Background(), testContext(), v.Root, InitOptions{From: src, WithHooks: tt.withHooks}); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := config.LoadConfig(v.ConfigPath())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cfg.Verification.StrictMode {
+				t.Errorf("expected the rest of the shared config, got %+v", cfg.Verification)
+			}
+			if got := cfg.Hooks.Commands("post_install"); !slices.Equal(got, tt.want) {
+				t.Errorf("expected post_install hooks %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestInitFromInvalidDefinition(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(dir string) error
+		wantErr string
+	}{
+		{
+			name:    "empty",
+			setup:   func(string) error { return nil },
+			wantErr: "no vault definition found",
+		},
+		{
+			name: "invalid manifest",
+			setup: func(dir string) error {
+				return os.WriteFile(filepath.Join(dir, manifest.ManifestFileName), []byte(`{"version":"1","plugins":{"sample":{}}}`), 0644)
+			},
+			wantErr: "invalid vault definition dragonglass.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := t.TempDir()
+			if err := tt.setup(src); err != nil {
+				t.Fatal(err)
+			}
+
+			v := vault.New(t.TempDir())
+			err := Init(context.Background(), testContext(), v.Root, InitOptions{From: src})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if _, err := os.Stat(v.ManifestPath()); !os.IsNotExist(err) {
+				t.Errorf("expected nothing to be copied, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAddStanza(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+	}{
+		{name: "new file", want: "# dragonglass\na\nb\n"},
+		{name: "appends after entries", existing: "x", want: "x\n\n# dragonglass\na\nb\n"},
+		{name: "adds missing lines only", existing: "# dragonglass\na\n", want: "# dragonglass\na\nb\n"},
+		{name: "complete", existing: "b\na\n", want: "b\na\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".gitignore")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := addStanza(path, []string{"a", "b"}); err != nil {
+				t.Fatal(err)
+			}
+			if data, _ := os.ReadFile(path); string(data) != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, data)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+// ABOUTME: Shared vault definitions init --from bootstraps a vault from
+// ABOUTME: Clones git URLs and bundles, then reads and validates the config, manifest, and lockfile
+package bootstrap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/manifest"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+// Files a vault definition can hold
+const (
+	roleConfig = iota
+	roleManifest
+	roleLockfile
+	roleSignature
+)
+
+// definition is a shared vault definition: the contents of the files it has, by role,
+// and its config when it has one
+type definition struct {
+	config   *config.Config
+	contents map[int][]byte
+}
+
+// files returns the files of d to write into the vault v, with their paths relative to
+// its root
+func (d *definition) files(v *vault.Vault) []definitionFile {
+	paths := map[int]string{
+		roleConfig:    v.ConfigPath(),
+		roleManifest:  v.ManifestPath(),
+		roleLockfile:  v.LockfilePath(),
+		roleSignature: lockfile.SignaturePath(v.LockfilePath()),
+	}
+
+	var files []definitionFile
+	for _, role := range []int{roleConfig, roleManifest, roleLockfile, roleSignature} {
+		data, ok := d.contents[role]
+		if !ok {
+			continue
+		}
+		files = append(files, definitionFile{path: relPath(v, paths[role]), write: func(path string) error {
+			return os.WriteFile(path, data, 0644)
+		}})
+	}
+	return files
+}
+
+// hookCommands returns the hook commands of the definition's config, each prefixed with
+// its hook, e.g. "post_install: ./notify.sh"
+func (d *definition) hookCommands() []string {
+	if d.config == nil {
+		return nil
+	}
+	var commands []string
+	for _, hook := range []struct {
+		name     string
+		commands []string
+	}{
+		{"pre_install", d.config.Hooks.PreInstall},
+		{"post_install", d.config.Hooks.PostInstall},
+		{"post_remove", d.config.Hooks.PostRemove},
+	} {
+		for _, command := range hook.commands {
+			commands = append(commands, hook.name+": "+command)
+		}
+	}
+	return commands
+}
+
+// stripHooks removes the hooks section from the definition's config. Hooks run shell
+// commands on install, so a vault only runs those its owner put in its config.
+func (d *definition) stripHooks() error {
+	data, ok := d.contents[roleConfig]
+	if !ok {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("failed to parse shared config: %w", err)
+	}
+	if _, ok := fields["hooks"]; !ok {
+		return nil
+	}
+	delete(fields, "hooks")
+
+	data, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal shared config: %w", err)
+	}
+	d.contents[roleConfig] = data
+	d.config.Hooks = config.HooksConfig{}
+	return nil
+}
+
+// fetchDefinition returns a directory holding the vault definition from names: from
+// itself when it is a directory, otherwise a clone of the git URL or bundle it names.
+// cleanup removes the clone.
+func fetchDefinition(ctx context.Context, from string) (string, func(), error) {
+	info, err := os.Stat(from)
+	if err == nil && info.IsDir() {
+		return from, func() {}, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "dragonglass-init-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(tmpDir) }
+
+	dir := filepath.Join(tmpDir, "definition")
+	args := []string{"clone", "--quiet"}
+	if info == nil {
+		// Only the latest revision of a remote is needed; bundles are cloned whole
+		args = append(args, "--depth", "1")
+	}
+	args = append(args, "--", from, dir)
+
+	var stderr bytes.Buffer
+	clone := exec.CommandContext(ctx, "git", args...)
+	clone.Stderr = &stderr
+	if err := clone.Run(); err != nil {
+		cleanup()
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", nil, fmt.Errorf("failed to clone %s: git is required for --from with a git URL or bundle", from)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", nil, fmt.Errorf("failed to clone %s: %s", from, msg)
+		}
+		return "", nil, fmt.Errorf("failed to clone %s: %w", from, err)
+	}
+
+	return dir, cleanup, nil
+}
+
+// readDefinition reads the vault definition in dir: its config, project manifest,
+// lockfile, and lockfile signature, each if present. The lockfile is looked for in the
+// state directory the definition's config names, then where older releases kept it.
+// Every file is validated before any is returned. A definition needs at least a lockfile
+// or a project manifest.
+func readDefinition(dir string) (*definition, error) {
+	src := vault.New(dir)
+	def := &definition{contents: make(map[int][]byte)}
+
+	read := func(role int, path string, validate func(path string) error) error {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		rel := relPath(src, path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		if validate != nil {
+			if err := validate(path); err != nil {
+				return fmt.Errorf("invalid vault definition %s: %w", rel, err)
+			}
+		}
+		def.contents[role] = data
+		return nil
+	}
+
+	if err := read(roleConfig, src.ConfigPath(), func(path string) (err error) {
+		def.config, err = config.LoadConfig(path)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if def.config != nil {
+		src = src.WithStateDir(def.config.Vault.StateDir)
+	}
+
+	if err := read(roleManifest, src.ManifestPath(), func(path string) error {
+		_, err := manifest.LoadManifest(path)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	lockfilePath := src.LockfilePath()
+	for _, candidate := range []string{lockfilePath, vault.LockfilePath(dir), vault.LegacyLockfilePath(dir)} {
+		if _, err := os.Stat(candidate); err == nil {
+			lockfilePath = candidate
+			break
+		}
+	}
+	if err := read(roleLockfile, lockfilePath, func(path string) error {
+		_, err := lockfile.LoadLockfile(path)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if err := read(roleSignature, lockfile.SignaturePath(lockfilePath), nil); err != nil {
+		return nil, err
+	}
+
+	_, hasLockfile := def.contents[roleLockfile]
+	_, hasManifest := def.contents[roleManifest]
+	if !hasLockfile && !hasManifest {
+		return nil, fmt.Errorf("no vault definition found (expected %s or %s)", relPath(src, src.LockfilePath()), manifest.ManifestFileName)
+	}
+	return def, nil
+}
+
+// relPath returns path relative to the root of v, with forward slashes
+func relPath(v *vault.Vault, path string) string {
+	rel, err := filepath.Rel(v.Root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
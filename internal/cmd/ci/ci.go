@@ -0,0 +1,368 @@
+// ABOUTME: CI command group for running dragonglass non-interactively in pipelines
+// ABOUTME: Verifies plugins with GitHub Actions annotations, job summaries, and exit codes
+package ci
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/verify"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/ghactions"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+)
+
+func NewCICommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ci",
+		Short: "Run dragonglass checks in CI pipelines",
+		Long: `Commands designed for CI pipelines such as GitHub Actions. They never prompt,
+authenticate with GITHUB_TOKEN, and exit with the documented exit codes.`,
+	}
+
+	cmd.AddCommand(newVerifyCommand(ctx))
+	return cmd
+}
+
+// VerifyOptions configures the ci verify command
+type VerifyOptions struct {
+	// Reference to verify; when empty every plugin in the lockfile is verified
+	ImageRef string
+
+	// Job summary file; defaults to $GITHUB_STEP_SUMMARY
+	SummaryPath string
+
+	// Fail on missing or invalid attestations and blocked vulnerabilities
+	Strict bool
+}
+
+func newVerifyCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify [OCI_IMAGE_REFERENCE]",
+		Short: "Verify plugins and report the results to GitHub Actions",
+		Long: `Verify a plugin, or every plugin in the lockfile, without prompting.
+Failures and warnings are printed as GitHub Actions annotations and a markdown
+table is appended to the job summary. Lockfile entries must still resolve to the
+locked manifest digest, so the command can gate pull requests that bump plugin
+versions.
+
+The exit code follows the same contract as other commands: 3 when no token is
+available, 4 when verification fails, 5 when a vulnerability blocks a plugin, and
+6 when the registry is unavailable.
+
+Example:
+  dragonglass ci verify
+  dragonglass ci verify --lockfile vault/.dragonglass/dragonglass-lock.json
+  dragonglass ci verify ghcr.io/owner/repo:plugin-name-v1.0.0`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := VerifyOptions{}
+			opts.SummaryPath, _ = cmd.Flags().GetString("summary")
+			opts.Strict, _ = cmd.Flags().GetBool("strict")
+			if len(args) == 1 {
+				opts.ImageRef = args[0]
+			}
+
+			if err := runVerify(cmd.Context(), ctx, opts); err != nil {
+				ctx.Fail("CI verification failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().String("summary", "", "Append the job summary to this file (default $"+ghactions.SummaryEnv+")")
+	cmd.Flags().Bool("strict", true, "Fail on missing attestations and blocked vulnerabilities")
+	return cmd
+}
+
+// target is a plugin reference to verify, with its lockfile position when it came from one
+type target struct {
+	ID       string
+	ImageRef string
+	Digest   string
+	File     string
+	Line     int
+
+	// The lockfile pins an unverified dev build, which always fails
+	Dev bool
+}
+
+// outcome is the verification result for a single target
+type outcome struct {
+	target
+	result *verify.Result
+	err    error
+}
+
+func runVerify(opCtx context.Context, ctx *cmd.CommandContext, opts VerifyOptions) error {
+	if ctx.GitHubToken == "" {
+		return dgerrors.Mark(fmt.Errorf("no GitHub token found (set GITHUB_TOKEN for the step)"), dgerrors.ErrNotAuthenticated)
+	}
+
+	targets, err := collectTargets(ctx, opts.ImageRef)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		ctx.Logger.Warn("No plugins to verify")
+		return nil
+	}
+
+	// Apply the CI policy to a copy so the shared context is left untouched
+	cfg := *ctx.Config
+	cfg.Verification.StrictMode = opts.Strict
+	ciCtx := *ctx
+	ciCtx.Config = &cfg
+
+	// One verifier is shared so the Sigstore trusted root is fetched once for all targets
+	verifier, err := verify.NewAttestationVerifier(&ciCtx)
+	if err != nil {
+		return err
+	}
+
+	outcomes := make([]outcome, len(targets))
+	scheduler := attestation.NewScheduler(cfg.Verification.Concurrency)
+	errs := scheduler.Run(opCtx, len(targets), func(jobCtx context.Context, i int) error {
+		t := targets[i]
+		if t.Dev {
+			return fmt.Errorf("plugin %s is an unverified dev build (remove it with dev uninstall)", t.ID)
+		}
+		ctx.Logger.Info("Verifying plugin", ctx.Logger.Args("imageRef", t.ImageRef))
+		verifyOpts := verify.DefaultVerifyOpts().
+			WithSkipTokenValidation(true).
+			WithExpectedDigest(t.Digest).
+			WithVerifier(verifier)
+		result, err := verify.Verify(jobCtx, &ciCtx, t.ImageRef, verifyOpts)
+		outcomes[i] = outcome{target: t, result: result, err: err}
+		return err
+	})
+
+	// Annotations are written in lockfile order once every target has finished
+	var failures []error
+	for i, o := range outcomes {
+		err := errs[i]
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
+		o.target, o.err = targets[i], err
+
+		if err := annotate(os.Stdout, o); err != nil {
+			return fmt.Errorf("failed to write annotations: %w", err)
+		}
+		if err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", o.ImageRef, err))
+		}
+		outcomes[i] = o
+	}
+
+	summaryPath := opts.SummaryPath
+	if summaryPath == "" {
+		summaryPath = os.Getenv(ghactions.SummaryEnv)
+	}
+	if summaryPath != "" {
+		if err := ghactions.AppendSummary(summaryPath, renderSummary(outcomes)); err != nil {
+			return err
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d plugins failed verification: %w", len(failures), len(targets), errors.Join(failures...))
+	}
+
+	ctx.Logger.Info("All plugins verified", ctx.Logger.Args("count", len(targets)))
+	return nil
+}
+
+// collectTargets returns imageRef, or every plugin in the lockfile when imageRef is empty
+func collectTargets(ctx *cmd.CommandContext, imageRef string) ([]target, error) {
+	if imageRef != "" {
+		return []target{{ImageRef: imageRef}}, nil
+	}
+
+	lockfilePath, err := ctx.ResolveLockfilePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate lockfile (pass a reference or --lockfile): %w", err)
+	}
+
+	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lockfile: %w", err)
+	}
+
+	data, err := os.ReadFile(lockfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+
+	// Annotation paths are relative to the workspace, which is the working directory in Actions
+	file := lockfilePath
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, lockfilePath); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+	}
+
+	var targets []target
+	for pluginID, entry := range lockfileData.Plugins {
+		targets = append(targets, target{
+			ID:       pluginID,
+			ImageRef: entry.LockedReference(),
+			Digest:   entry.OCIDigest,
+			Dev:      entry.IsDev(),
+			File:     filepath.ToSlash(file),
+			Line:     keyLine(data, pluginID),
+		})
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].ID < targets[j].ID })
+
+	return targets, nil
+}
+
+// keyLine returns the 1-based line on which key first appears as a JSON object key, or 0
+func keyLine(data []byte, key string) int {
+	quoted := []byte(fmt.Sprintf("%q", key))
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if rest, ok := bytes.CutPrefix(text, quoted); ok && bytes.HasPrefix(bytes.TrimSpace(rest), []byte(":")) {
+			return line
+		}
+	}
+	return 0
+}
+
+// annotate prints an error annotation for a failed outcome and a warning annotation for
+// each finding that did not fail verification
+func annotate(w io.Writer, o outcome) error {
+	name := o.ID
+	if name == "" {
+		name = o.ImageRef
+	}
+
+	if o.err != nil {
+		message := fmt.Sprintf("%s: %v", o.ImageRef, o.err)
+		if hint := dgerrors.Hint(o.err); hint != "" {
+			message += " (" + hint + ")"
+		}
+		title := "Plugin verification failed: "
+		if errors.Is(o.err, dgerrors.ErrTagMoved) {
+			title = "Plugin tag re-pushed: "
+		}
+		annotation := ghactions.Annotation{
+			Level:   ghactions.LevelError,
+			Title:   title + name,
+			File:    o.File,
+			Line:    o.Line,
+			Message: message,
+		}
+		if err := annotation.Write(w); err != nil {
+			return err
+		}
+	}
+
+	if o.result == nil {
+		return nil
+	}
+	for _, warning := range o.result.Warnings {
+		annotation := ghactions.Annotation{
+			Level:   ghactions.LevelWarning,
+			Title:   "Plugin verification warning: " + name,
+			File:    o.File,
+			Line:    o.Line,
+			Message: fmt.Sprintf("%s: %s", o.ImageRef, warning),
+		}
+		if err := annotation.Write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderSummary formats the outcomes as a markdown section for the job summary
+func renderSummary(outcomes []outcome) string {
+	table := ghactions.Table{
+		Header: []string{"Plugin", "Version", "Reference", "Provenance", "SBOM", "Vulnerabilities", "Result"},
+	}
+
+	failed := 0
+	for _, o := range outcomes {
+		name, version := o.ID, "-"
+		provenance, sbom, vulns := "-", "-", "-"
+
+		if r := o.result; r != nil {
+			if r.Metadata != nil {
+				if name == "" {
+					name = r.Metadata.Name
+				}
+				version = r.Metadata.Version
+			}
+			if a := r.Attestation; a != nil {
+				provenance = passFail(a.SLSA != nil && a.SLSA.Valid)
+				if a.SBOM != nil {
+					sbom = passFail(a.SBOM.Valid)
+					vulns = countVulnerabilities(a.SBOM.Vulnerabilities)
+				}
+			}
+		}
+		if name == "" {
+			name = o.ImageRef
+		}
+
+		status := "✅ verified"
+		if o.err != nil {
+			failed++
+			status = "❌ " + o.err.Error()
+		} else if o.result != nil && len(o.result.Warnings) > 0 {
+			status = fmt.Sprintf("⚠️ verified with %d warning(s)", len(o.result.Warnings))
+		}
+
+		table.Rows = append(table.Rows, []string{name, version, "`" + o.ImageRef + "`", provenance, sbom, vulns, status})
+	}
+
+	var b strings.Builder
+	b.WriteString("### Dragonglass plugin verification\n\n")
+	if failed > 0 {
+		fmt.Fprintf(&b, "%d of %d plugins failed verification.\n\n", failed, len(outcomes))
+	} else {
+		fmt.Fprintf(&b, "All %d plugins passed verification.\n\n", len(outcomes))
+	}
+	b.WriteString(table.Markdown())
+	b.WriteString("\n")
+	return b.String()
+}
+
+func passFail(ok bool) string {
+	if ok {
+		return "pass"
+	}
+	return "fail"
+}
+
+// countVulnerabilities summarizes vulnerabilities as a total with the blocking severities
+func countVulnerabilities(vulns []attestation.Vulnerability) string {
+	if len(vulns) == 0 {
+		return "none"
+	}
+
+	critical, high := 0, 0
+	for _, vuln := range vulns {
+		switch strings.ToUpper(vuln.Severity) {
+		case "CRITICAL":
+			critical++
+		case "HIGH":
+			high++
+		}
+	}
+	return fmt.Sprintf("%d (%d critical, %d high)", len(vulns), critical, high)
+}
@@ -0,0 +1,104 @@
+package ci
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/verify"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+	"github.com/gillisandrew/dragonglass-poc/internal/warnings"
+)
+
+func TestKeyLine(t *testing.T) {
+	data := []byte(`{
+  "lockfileVersion": "1",
+  "plugins": {
+    "calendar": {
+      "name": "calendar"
+    },
+    "dataview": {
+      "name": "Dataview"
+    }
+  }
+}`)
+
+	tests := []struct {
+		key      string
+		expected int
+	}{
+		{key: "calendar", expected: 4},
+		{key: "dataview", expected: 7},
+		{key: "missing", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := keyLine(data, tt.key); got != tt.expected {
+				t.Errorf("expected line %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	o := outcome{
+		target: target{ID: "dataview", ImageRef: "ghcr.io/owner/dataview:1.0.0", File: "dragonglass-lock.json", Line: 7},
+		result: &verify.Result{Warnings: []warnings.Warning{warnings.New(warnings.CategorySBOM, "no SBOM attestation")}},
+		err:    &dgerrors.UntrustedBuilderError{Builder: "https://example.com/other", TrustedBuilder: "https://example.com/trusted"},
+	}
+
+	var out bytes.Buffer
+	if err := annotate(&out, o); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected an error and a warning annotation, got %q", out.String())
+	}
+	if !strings.HasPrefix(lines[0], "::error file=dragonglass-lock.json,line=7,title=Plugin verification failed%3A dataview::") {
+		t.Errorf("unexpected error annotation %q", lines[0])
+	}
+	if !strings.Contains(lines[0], "check verification.trusted_builder") {
+		t.Errorf("expected error annotation to include the hint, got %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "::warning ") || !strings.HasSuffix(lines[1], "no SBOM attestation") {
+		t.Errorf("unexpected warning annotation %q", lines[1])
+	}
+}
+
+func TestRenderSummary(t *testing.T) {
+	outcomes := []outcome{
+		{
+			target: target{ID: "calendar", ImageRef: "ghcr.io/owner/calendar:1.0.0"},
+			result: &verify.Result{
+				Metadata: &plugin.Metadata{Name: "Calendar", Version: "1.0.0"},
+				Attestation: &attestation.VerificationResult{
+					SLSA: &attestation.SLSAResult{Valid: true},
+					SBOM: &attestation.SBOMResult{Valid: true, Vulnerabilities: []attestation.Vulnerability{{Severity: "HIGH"}, {Severity: "LOW"}}},
+				},
+			},
+		},
+		{
+			target: target{ImageRef: "ghcr.io/owner/broken:0.1.0"},
+			result: &verify.Result{ImageRef: "ghcr.io/owner/broken:0.1.0"},
+			err:    errors.New("attestations not found"),
+		},
+	}
+
+	summary := renderSummary(outcomes)
+
+	for _, expected := range []string{
+		"1 of 2 plugins failed verification.",
+		"| calendar | 1.0.0 | `ghcr.io/owner/calendar:1.0.0` | pass | pass | 2 (0 critical, 1 high) | ✅ verified |",
+		"| ghcr.io/owner/broken:0.1.0 | - | `ghcr.io/owner/broken:0.1.0` | - | - | - | ❌ attestations not found |",
+	} {
+		if !strings.Contains(summary, expected) {
+			t.Errorf("expected summary to contain %q, got:\n%s", expected, summary)
+		}
+	}
+}
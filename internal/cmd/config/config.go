@@ -0,0 +1,286 @@
+// ABOUTME: Config command for inspecting and editing dragonglass settings
+// ABOUTME: Provides get, set, list, and edit subcommands with schema validation
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	appconfig "github.com/gillisandrew/dragonglass-poc/internal/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
+)
+
+func NewConfigCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect and edit dragonglass configuration",
+		Long: `Inspect and edit dragonglass configuration.
+
+Settings are resolved from command line flags, DRAGONGLASS_* environment
+variables, the vault config (.obsidian/dragonglass-config.json), the user
+config (config.json in the user config dir, see 'dragonglass env'), and
+built-in defaults, in that order.
+
+Example:
+  dragonglass config list --origin
+  dragonglass config get verification.strict_mode
+  dragonglass config set verification.strict_mode true
+  dragonglass config set registry.default_registry ghcr.io --user`,
+	}
+
+	cmd.AddCommand(newGetCommand(ctx))
+	cmd.AddCommand(newSetCommand(ctx))
+	cmd.AddCommand(newListCommand(ctx))
+	cmd.AddCommand(newEditCommand(ctx))
+
+	return cmd
+}
+
+func newGetCommand(ctx *cmd.CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the effective value of a setting",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			setting, ok := appconfig.LookupSetting(args[0])
+			if !ok {
+				ctx.Logger.Error("Unknown configuration key", ctx.Logger.Args("key", args[0], "available", strings.Join(settingKeys(), ", ")))
+				os.Exit(1)
+			}
+
+			fmt.Println(setting.Get(ctx.Config))
+		},
+	}
+}
+
+func newSetCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Store a setting in the vault or user config file",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			user, _ := cmd.Flags().GetBool("user")
+
+			configPath, err := targetConfigPath(ctx, user)
+			if err != nil {
+				ctx.Logger.Error("Failed to locate config file", ctx.Logger.Args("error", err))
+				os.Exit(1)
+			}
+
+			if err := appconfig.SetFileValue(configPath, args[0], args[1]); err != nil {
+				ctx.Logger.Error("Failed to set configuration", ctx.Logger.Args("error", err))
+				os.Exit(1)
+			}
+
+			ctx.Logger.Info("Configuration updated", ctx.Logger.Args("key", args[0], "value", args[1], "file", configPath))
+
+			// Warn when a higher-precedence layer will mask the new value
+			if ctx.ResolvedConfig != nil {
+				origin := ctx.ResolvedConfig.Origin(args[0])
+				if origin == appconfig.SourceEnv || origin == appconfig.SourceFlag || (user && origin == appconfig.SourceVault) {
+					ctx.Logger.Warn("Setting is overridden by a higher-precedence source", ctx.Logger.Args("key", args[0], "origin", origin))
+				}
+			}
+		},
+	}
+
+	cmd.Flags().Bool("user", false, "Write to the user config instead of the vault config")
+	return cmd
+}
+
+func newListCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List effective settings",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			showOrigin, _ := cmd.Flags().GetBool("origin")
+
+			header := []string{i18n.T("config.header.key"), i18n.T("config.header.value")}
+			if showOrigin {
+				header = append(header, i18n.T("config.header.origin"))
+			}
+			tableData := pterm.TableData{header}
+
+			for _, setting := range appconfig.Settings() {
+				row := []string{setting.Key, setting.Get(ctx.Config)}
+				if showOrigin {
+					row = append(row, describeOrigin(ctx.ResolvedConfig, setting))
+				}
+				tableData = append(tableData, row)
+			}
+
+			pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+		},
+	}
+
+	cmd.Flags().Bool("origin", false, "Show where each effective value comes from")
+	return cmd
+}
+
+func newEditCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Open the config file in $EDITOR and validate on save",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			user, _ := cmd.Flags().GetBool("user")
+
+			configPath, err := targetConfigPath(ctx, user)
+			if err != nil {
+				ctx.Logger.Error("Failed to locate config file", ctx.Logger.Args("error", err))
+				os.Exit(1)
+			}
+
+			if err := editConfigFile(configPath); err != nil {
+				ctx.Logger.Error("Failed to edit configuration", ctx.Logger.Args("error", err))
+				os.Exit(1)
+			}
+
+			ctx.Logger.Info("Configuration saved", ctx.Logger.Args("file", configPath))
+		},
+	}
+
+	cmd.Flags().Bool("user", false, "Edit the user config instead of the vault config")
+	return cmd
+}
+
+// CreatesConfigFile reports whether args, the command line without its global flags, run
+// config set or config edit, which create a --config file that does not exist yet
+func CreatesConfigFile(args []string) bool {
+	return len(args) >= 2 && args[0] == "config" && (args[1] == "set" || args[1] == "edit")
+}
+
+// targetConfigPath returns the config file that set and edit should write to
+func targetConfigPath(ctx *cmd.CommandContext, user bool) (string, error) {
+	if user {
+		return appconfig.GetUserConfigPath()
+	}
+	if ctx.ConfigPath != "" {
+		return ctx.ConfigPath, nil
+	}
+
+	v, err := ctx.DiscoverVault()
+	if err != nil {
+		return "", fmt.Errorf("not inside an Obsidian vault (use --user for the user config): %w", err)
+	}
+
+	return v.ConfigPath(), nil
+}
+
+// describeOrigin formats the layer that supplied a setting for display
+func describeOrigin(resolved *appconfig.Resolved, setting appconfig.Setting) string {
+	if resolved == nil {
+		return string(appconfig.SourceDefault)
+	}
+
+	origin := resolved.Origin(setting.Key)
+	switch origin {
+	case appconfig.SourceEnv:
+		return fmt.Sprintf("%s (%s)", origin, setting.EnvVar)
+	case appconfig.SourceVault:
+		return fmt.Sprintf("%s (%s)", origin, resolved.VaultConfigPath)
+	case appconfig.SourceUser:
+		return fmt.Sprintf("%s (%s)", origin, resolved.UserConfigPath)
+	default:
+		return string(origin)
+	}
+}
+
+// editConfigFile opens a copy of the config file in the user's editor and only replaces
+// the original once the edited copy passes validation
+func editConfigFile(configPath string) error {
+	original, err := os.ReadFile(configPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+		original = []byte(fmt.Sprintf("{\n  \"version\": %q\n}\n", appconfig.DefaultConfig().Version))
+	}
+
+	tmpFile, err := os.CreateTemp("", "dragonglass-config-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmpFile.Write(original); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	for {
+		if err := runEditor(tmpPath); err != nil {
+			return err
+		}
+
+		validationErr := appconfig.ValidateFile(tmpPath)
+		if validationErr == nil {
+			break
+		}
+
+		pterm.Error.Println(i18n.T("config.invalid", validationErr))
+		retry, _ := pterm.DefaultInteractiveConfirm.WithDefaultValue(true).Show(i18n.T("config.reopen"))
+		if !retry {
+			return fmt.Errorf("changes discarded: %w", validationErr)
+		}
+	}
+
+	edited, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to read edited config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	if err := os.WriteFile(configPath, edited, appconfig.DefaultConfigPerms); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// runEditor opens path in $VISUAL or $EDITOR, falling back to vi
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// Editors are commonly configured with arguments, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	editorCmd := exec.Command(parts[0], append(parts[1:], path)...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+
+	if err := editorCmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", parts[0], err)
+	}
+
+	return nil
+}
+
+func settingKeys() []string {
+	var keys []string
+	for _, setting := range appconfig.Settings() {
+		keys = append(keys, setting.Key)
+	}
+	return keys
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	appconfig "github.com/gillisandrew/dragonglass-poc/internal/config"
+)
+
+func TestCreatesConfigFile(t *testing.T) {
+	tests := []struct {
+		args     string
+		expected bool
+	}{
+		{args: "config set verification.strict_mode true", expected: true},
+		{args: "config edit", expected: true},
+		{args: "config get verification.strict_mode"},
+		{args: "config list"},
+		{args: "list"},
+		{args: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			if got := CreatesConfigFile(strings.Fields(tt.args)); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSetNewConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.json")
+
+	// Resolving the global flags lets config set through although the file does not exist
+	resolved, err := appconfig.Resolve(appconfig.DefaultResolverOpts().
+		WithUserConfigPath("").
+		WithVaultConfigPath(path).
+		WithCreateVaultConfig(CreatesConfigFile([]string{"config", "set"})).
+		WithLookupEnv(func(string) (string, bool) { return "", false }))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := &cmd.CommandContext{
+		ConfigPath:     path,
+		Config:         resolved.Config,
+		ResolvedConfig: resolved,
+		Logger:         pterm.DefaultLogger.WithWriter(io.Discard),
+	}
+	command := NewConfigCommand(ctx)
+	command.SetArgs([]string{"set", "verification.strict_mode", "true"})
+	if err := command.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg, err := appconfig.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("expected config set to create %s: %v", path, err)
+	}
+	if !cfg.Verification.StrictMode {
+		t.Error("expected strict mode to be stored in the new config file")
+	}
+}
@@ -3,9 +3,18 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/pterm/pterm"
 
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
 	"github.com/gillisandrew/dragonglass-poc/internal/domain"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/prompt"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+	"github.com/gillisandrew/dragonglass-poc/internal/warnings"
 )
 
 // CommandContext holds global configuration that can be passed to commands
@@ -14,9 +23,138 @@ type CommandContext struct {
 	TrustedBuilder      string
 	ConfigPath          string
 	LockfilePath        string
+	VaultDir            string
 	GitHubToken         string
+	Config              *config.Config
+	ResolvedConfig      *config.Resolved
 	Logger              *pterm.Logger
 	AuthService         domain.AuthService
 	RegistryService     domain.RegistryService
 	AttestationService  domain.AttestationService
+
+	// Verification warnings that fail install and add (--warnings-as-errors)
+	WarningPolicy warnings.Policy
+
+	// Replace installed plugins with lower versions or older builds (--allow-downgrade)
+	AllowDowngrade bool
+
+	// Asks before destructive or trust-impacting operations; nil proceeds without asking,
+	// as library callers and the daemon do
+	Prompt *prompt.Prompter
+
+	// Called by Fail before the process exits, e.g. to record run statistics (optional)
+	OnExit func()
+}
+
+// DiscoverVault returns the vault containing VaultDir, the vault owning LockfilePath when
+// VaultDir is empty, or the vault containing the working directory when both are, with its
+// state in the directory vault.state_dir names
+func (c *CommandContext) DiscoverVault() (*vault.Vault, error) {
+	if c.VaultDir == "" && c.LockfilePath != "" {
+		return c.VaultAt(lockfile.VaultRoot(c.LockfilePath)), nil
+	}
+
+	var v *vault.Vault
+	var err error
+	if c.VaultDir != "" {
+		v, err = vault.Discover(c.VaultDir)
+	} else {
+		v, err = vault.DiscoverFromWorkingDir()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.VaultAt(v.Root), nil
+}
+
+// VaultAt returns the layout of the vault rooted at root, with its state in the directory
+// vault.state_dir names
+func (c *CommandContext) VaultAt(root string) *vault.Vault {
+	v := vault.New(root)
+	if c.Config != nil {
+		v = v.WithStateDir(c.Config.Vault.StateDir)
+	}
+	return v
+}
+
+// ResolveLockfilePath returns the lockfile for the vault DiscoverVault finds.
+// The --lockfile flag takes precedence and must name an existing lockfile; lockfiles left
+// in .obsidian by older releases, or in .dragonglass when vault.state_dir names another
+// directory, are moved to the state directory.
+func (c *CommandContext) ResolveLockfilePath() (string, error) {
+	return c.resolveLockfilePath(false)
+}
+
+// ResolveNewLockfilePath is ResolveLockfilePath for commands that create the lockfile,
+// such as add: --lockfile may also name one yet to be created in an existing vault.
+func (c *CommandContext) ResolveNewLockfilePath() (string, error) {
+	return c.resolveLockfilePath(true)
+}
+
+func (c *CommandContext) resolveLockfilePath(create bool) (string, error) {
+	if c.LockfilePath != "" {
+		if _, err := os.Stat(c.LockfilePath); err == nil {
+			return c.LockfilePath, nil
+		} else if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to access lockfile %s: %w", c.LockfilePath, err)
+		}
+		if !create {
+			return "", fmt.Errorf("lockfile not found: %s", c.LockfilePath)
+		}
+		// The state directory is created with the lockfile, but the vault must exist
+		root := lockfile.VaultRoot(c.LockfilePath)
+		if info, err := os.Stat(c.VaultAt(root).ObsidianDir()); err != nil || !info.IsDir() {
+			return "", fmt.Errorf("lockfile not found: %s (no vault at %s)", c.LockfilePath, root)
+		}
+		return c.LockfilePath, nil
+	}
+
+	v, err := c.DiscoverVault()
+	if err != nil {
+		return "", err
+	}
+
+	location, err := v.ResolveLockfile("")
+	if err != nil {
+		return "", err
+	}
+
+	if location.MigratedFrom != "" {
+		c.Logger.Info("Moved legacy lockfile to canonical location", c.Logger.Args("from", location.MigratedFrom, "to", location.Path))
+	}
+	if location.IgnoredLegacy != "" {
+		c.Logger.Warn("Ignoring legacy lockfile, canonical lockfile takes precedence", c.Logger.Args("legacy", location.IgnoredLegacy, "lockfile", location.Path))
+	}
+
+	return location.Path, nil
+}
+
+// Confirm asks the user to confirm an operation summarized by summary, returning an error
+// when it must not go ahead. Without a Prompt it always goes ahead.
+func (c *CommandContext) Confirm(summary []string, question string) error {
+	if c.Prompt == nil {
+		return nil
+	}
+	return c.Prompt.Confirm(summary, question)
+}
+
+// Fail logs err and exits with the code internal/errors assigns to it. Errors caused by
+// cancellation (Ctrl-C or SIGTERM) are reported as an interruption rather than a failure,
+// and known failures are logged with a hint on how to resolve them.
+func (c *CommandContext) Fail(message string, err error) {
+	code := dgerrors.ExitCode(err)
+	if c.OnExit != nil {
+		c.OnExit()
+	}
+	if code == dgerrors.ExitInterrupted {
+		c.Logger.Warn("Interrupted, cancelled in-flight operations")
+		os.Exit(code)
+	}
+
+	args := []any{"error", err}
+	if hint := dgerrors.Hint(err); hint != "" {
+		args = append(args, "hint", hint)
+	}
+	c.Logger.Error(message, c.Logger.Args(args...))
+	os.Exit(code)
 }
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+func TestLockfileFlag(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".obsidian"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	v := vault.New(root)
+	if err := os.MkdirAll(filepath.Dir(v.LockfilePath()), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(v.LockfilePath(), []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	newVault := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(newVault, ".obsidian"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	newLockfile := vault.New(newVault).LockfilePath()
+	missingVault := filepath.Join(t.TempDir(), "no-such-vault", ".dragonglass", "dragonglass-lock.json")
+
+	tests := []struct {
+		name     string
+		lockfile string
+		create   bool
+		wantErr  string
+	}{
+		{name: "existing lockfile", lockfile: v.LockfilePath()},
+		{name: "lockfile yet to be created", lockfile: newLockfile, wantErr: "lockfile not found: " + newLockfile},
+		{name: "existing lockfile for a command that creates it", lockfile: v.LockfilePath(), create: true},
+		{name: "lockfile yet to be created by a command that creates it", lockfile: newLockfile, create: true},
+		{name: "vault does not exist", lockfile: missingVault, create: true, wantErr: "lockfile not found: " + missingVault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &CommandContext{LockfilePath: tt.lockfile, Config: config.DefaultConfig()}
+			resolve := ctx.ResolveLockfilePath
+			if tt.create {
+				resolve = ctx.ResolveNewLockfilePath
+			}
+			path, err := resolve()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if path != tt.lockfile {
+				t.Errorf("expected %s, got %s", tt.lockfile, path)
+			}
+		})
+	}
+
+	// The vault is the one owning the lockfile, not the one containing the working directory
+	found, err := (&CommandContext{LockfilePath: v.LockfilePath(), Config: config.DefaultConfig()}).DiscoverVault()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found.Root != root {
+		t.Errorf("expected vault %s, got %s", root, found.Root)
+	}
+}
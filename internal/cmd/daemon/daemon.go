@@ -0,0 +1,283 @@
+// ABOUTME: Daemon command serving install, verify, list, and remove over JSON-RPC
+// ABOUTME: Lets editor integrations drive dragonglass through a local unix socket
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/install"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/serve"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/verify"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/metrics"
+	"github.com/gillisandrew/dragonglass-poc/internal/rpc"
+	"github.com/gillisandrew/dragonglass-poc/internal/server"
+)
+
+// DaemonOptions configures the daemon command
+type DaemonOptions struct {
+	// Unix socket to listen on (default: .dragonglass/daemon.sock in the vault)
+	SocketPath string
+}
+
+func NewDaemonCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Serve dragonglass to editor integrations over a unix socket",
+		Long: `Run a long-lived process that accepts JSON-RPC 2.0 requests on a unix socket,
+one JSON object per line, so an Obsidian companion plugin can drive dragonglass.
+
+Methods:
+  verify   {"ref": REF, "analyze": false}   verify a plugin without installing it
+  install  {"ref": REF, "force": false}     verify, install, and lock a plugin
+  list     {}                               plugins pinned in the lockfile
+  remove   {"id": ID}                       remove a plugin from the vault and lockfile
+  metrics  {}                               verification and download metrics since start
+
+While a request runs, its log messages are streamed as "progress" notifications
+carrying the request id. Failed requests return error code -32000 with the
+exit code and hint the CLI would report. Installs and removals run one at a
+time; closing the connection cancels its in-flight requests.
+
+Example:
+  dragonglass daemon
+  dragonglass daemon --socket /tmp/dragonglass.sock`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := DaemonOptions{}
+			opts.SocketPath, _ = cmd.Flags().GetString("socket")
+
+			if err := runDaemon(cmd.Context(), ctx, opts); err != nil {
+				ctx.Fail("Daemon failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().String("socket", "", "Unix socket to listen on (default .dragonglass/daemon.sock in the vault)")
+	return cmd
+}
+
+func runDaemon(opCtx context.Context, ctx *cmd.CommandContext, opts DaemonOptions) error {
+	socketPath := opts.SocketPath
+	if socketPath == "" {
+		v, err := ctx.DiscoverVault()
+		if err != nil {
+			return fmt.Errorf("failed to find vault (pass --socket to run outside one): %w", err)
+		}
+		socketPath = v.DaemonSocketPath()
+	}
+
+	listener, err := listenUnix(socketPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(socketPath)
+
+	s := &service{ctx: ctx}
+	srv := rpc.NewServer()
+	srv.Register("verify", s.verify)
+	srv.Register("install", s.install)
+	srv.Register("list", s.list)
+	srv.Register("remove", s.remove)
+	srv.Register("metrics", s.metrics)
+
+	ctx.Logger.Info("Daemon listening", ctx.Logger.Args("socket", socketPath))
+	if err := srv.Serve(opCtx, listener); err != nil {
+		return err
+	}
+
+	ctx.Logger.Info("Daemon stopped")
+	return nil
+}
+
+// listenUnix listens on socketPath, replacing a socket left behind by a daemon that did not
+// shut down cleanly. Only the current user may connect.
+func listenUnix(socketPath string) (net.Listener, error) {
+	if conn, err := net.Dial("unix", socketPath); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("a daemon is already listening on %s", socketPath)
+	}
+	if err := os.Remove(socketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	// The socket is created private rather than restricted after the fact, which would
+	// leave a window in which other users could connect
+	listener, err := listenPrivate(socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+	return listener, nil
+}
+
+// service implements the daemon's methods
+type service struct {
+	ctx *cmd.CommandContext
+
+	// Serializes methods that modify the vault and lockfile
+	mu sync.Mutex
+}
+
+// requestContext returns a copy of the command context whose log output is streamed to
+// the client as progress events
+func (s *service) requestContext(method string, progress func(event any)) *cmd.CommandContext {
+	s.ctx.Logger.Debug("Handling request", s.ctx.Logger.Args("method", method))
+
+	reqCtx := *s.ctx
+	reqCtx.Logger = s.ctx.Logger.
+		WithWriter(&progressWriter{progress: progress}).
+		WithFormatter(pterm.LogFormatterJSON)
+	// Requests carry their own intent, e.g. force, and nobody is at the terminal to ask
+	reqCtx.Prompt = nil
+	return &reqCtx
+}
+
+type verifyParams struct {
+	Ref     string `json:"ref"`
+	Analyze bool   `json:"analyze"`
+}
+
+func (s *service) verify(opCtx context.Context, params json.RawMessage, progress func(any)) (any, error) {
+	var p verifyParams
+	if err := rpc.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Ref == "" {
+		return nil, rpc.InvalidParams("ref is required")
+	}
+
+	reqCtx := s.requestContext("verify", progress)
+	result, err := verify.Verify(opCtx, reqCtx, p.Ref, verify.DefaultVerifyOpts().WithAnalyze(p.Analyze))
+	response := serve.NewVerifyResponse(result)
+	response.Ref = p.Ref
+	response.CheckedAt = time.Now().UTC()
+	if err != nil {
+		return nil, failed(err, response)
+	}
+	response.Verified = true
+	return response, nil
+}
+
+type installParams struct {
+	Ref   string `json:"ref"`
+	Force bool   `json:"force"`
+}
+
+func (s *service) install(opCtx context.Context, params json.RawMessage, progress func(any)) (any, error) {
+	var p installParams
+	if err := rpc.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Ref == "" {
+		return nil, rpc.InvalidParams("ref is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reqCtx := s.requestContext("install", progress)
+	if err := install.Add(opCtx, reqCtx, p.Ref, p.Force); err != nil {
+		return nil, failed(err, nil)
+	}
+
+	// Report the plugin as it was pinned
+	lockfileData, err := loadLockfile(reqCtx)
+	if err != nil {
+		return nil, failed(err, nil)
+	}
+	for _, plugin := range server.LockedPlugins(lockfileData) {
+		if plugin.OCIReference == p.Ref {
+			return plugin, nil
+		}
+	}
+	return nil, failed(fmt.Errorf("installed %s but it is missing from the lockfile", p.Ref), nil)
+}
+
+func (s *service) list(opCtx context.Context, params json.RawMessage, progress func(any)) (any, error) {
+	lockfileData, err := loadLockfile(s.requestContext("list", progress))
+	if err != nil {
+		return nil, failed(err, nil)
+	}
+	return map[string][]server.Plugin{"plugins": server.LockedPlugins(lockfileData)}, nil
+}
+
+type removeParams struct {
+	ID    string `json:"id"`
+	Purge bool   `json:"purge,omitempty"`
+}
+
+func (s *service) remove(opCtx context.Context, params json.RawMessage, progress func(any)) (any, error) {
+	var p removeParams
+	if err := rpc.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.ID == "" {
+		return nil, rpc.InvalidParams("id is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := install.Remove(opCtx, s.requestContext("remove", progress), p.ID, install.RemoveOptions{Purge: p.Purge}); err != nil {
+		return nil, failed(err, nil)
+	}
+	return map[string]string{"removed": p.ID}, nil
+}
+
+func (s *service) metrics(opCtx context.Context, params json.RawMessage, progress func(any)) (any, error) {
+	return metrics.Default.Snapshot(), nil
+}
+
+func loadLockfile(ctx *cmd.CommandContext) (*lockfile.Lockfile, error) {
+	lockfilePath, err := ctx.ResolveLockfilePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lockfile: %w", err)
+	}
+	return lockfileData, nil
+}
+
+// failedData is the data of a failed request's error
+type failedData struct {
+	ExitCode int    `json:"exit_code"`
+	Hint     string `json:"hint,omitempty"`
+
+	// How far verification got (verify only)
+	Result *server.VerifyResponse `json:"result,omitempty"`
+}
+
+// failed reports err with the exit code and hint the CLI would give for it
+func failed(err error, result *server.VerifyResponse) error {
+	return &rpc.Error{
+		Code:    rpc.CodeFailed,
+		Message: err.Error(),
+		Data: failedData{
+			ExitCode: dgerrors.ExitCode(err),
+			Hint:     dgerrors.Hint(err),
+			Result:   result,
+		},
+	}
+}
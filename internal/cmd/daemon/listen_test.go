@@ -0,0 +1,36 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListenUnixPrivate(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "daemon.sock")
+
+	// The socket is owner-only as soon as it exists, not only after listenUnix restricts it
+	listener, err := listenPrivate(socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(socketPath)
+	listener.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		t.Errorf("expected a socket only its owner can use, got %v", perm)
+	}
+
+	listener, err = listenUnix(socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	if info, err := os.Stat(socketPath); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("expected a 0600 socket, got %v, %v", info, err)
+	}
+}
@@ -0,0 +1,19 @@
+// ABOUTME: Private unix socket listener for the daemon on platforms with a umask
+// ABOUTME: Creates the socket with owner-only permissions so it is never reachable by others
+//go:build !windows
+
+package daemon
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenPrivate listens on socketPath with a umask that leaves the socket accessible to
+// the current user only. The umask is process-wide; the daemon starts listening before
+// it runs anything else that creates files.
+func listenPrivate(socketPath string) (net.Listener, error) {
+	previous := syscall.Umask(0o177)
+	defer syscall.Umask(previous)
+	return net.Listen("unix", socketPath)
+}
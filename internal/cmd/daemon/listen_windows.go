@@ -0,0 +1,11 @@
+// ABOUTME: Unix socket listener for the daemon on Windows, which has no umask
+// ABOUTME: The socket inherits the access control list of the vault's state directory
+package daemon
+
+import "net"
+
+// listenPrivate listens on socketPath. Windows has no umask; access to the socket follows
+// the access control list of the directory holding it.
+func listenPrivate(socketPath string) (net.Listener, error) {
+	return net.Listen("unix", socketPath)
+}
@@ -0,0 +1,74 @@
+// ABOUTME: Progress events streamed to daemon clients from a request's log output
+// ABOUTME: Parses the JSON log lines a request writes into structured events
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// ProgressEvent is a log message written while a request runs, sent to the client as the
+// event of a "progress" notification
+type ProgressEvent struct {
+	// "debug", "info", "warn" or "error"
+	Level   string         `json:"level"`
+	Message string         `json:"message"`
+	Fields  map[string]any `json:"fields,omitempty"`
+}
+
+// progressWriter receives a request's logger output, one JSON object per line, and sends
+// each line to the client as a ProgressEvent
+type progressWriter struct {
+	progress func(event any)
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		end := bytes.IndexByte(w.buf, '\n')
+		if end < 0 {
+			return len(p), nil
+		}
+		line := bytes.TrimSpace(w.buf[:end])
+		w.buf = w.buf[end+1:]
+		if len(line) > 0 {
+			w.progress(parseLogLine(line))
+		}
+	}
+}
+
+// parseLogLine converts a JSON log line into an event. Lines that are not JSON objects
+// are passed through as info messages.
+func parseLogLine(line []byte) ProgressEvent {
+	var fields map[string]any
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return ProgressEvent{Level: "info", Message: string(line)}
+	}
+
+	event := ProgressEvent{}
+	event.Level, _ = fields["level"].(string)
+	event.Level = strings.ToLower(event.Level)
+	event.Message, _ = fields["msg"].(string)
+	for _, key := range []string{"level", "msg", "timestamp", "caller"} {
+		delete(fields, key)
+	}
+
+	// Error values have no exported fields and are encoded as {}; they say nothing
+	for key, value := range fields {
+		if m, ok := value.(map[string]any); ok && len(m) == 0 {
+			delete(fields, key)
+		}
+	}
+	if len(fields) > 0 {
+		event.Fields = fields
+	}
+	return event
+}
@@ -0,0 +1,46 @@
+package daemon
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/pterm/pterm"
+)
+
+func TestProgressWriter(t *testing.T) {
+	var events []ProgressEvent
+	w := &progressWriter{progress: func(event any) { events = append(events, event.(ProgressEvent)) }}
+	logger := pterm.DefaultLogger.WithWriter(w).WithFormatter(pterm.LogFormatterJSON).WithLevel(pterm.LogLevelInfo)
+
+	logger.Debug("Hidden below the log level")
+	logger.Info("Verifying plugin", logger.Args("imageRef", "ghcr.io/owner/plugin:1.0.0", "count", 2))
+	logger.Warn("Verification failed", logger.Args("error", errors.New("boom")))
+
+	expected := []ProgressEvent{
+		{Level: "info", Message: "Verifying plugin", Fields: map[string]any{"imageRef": "ghcr.io/owner/plugin:1.0.0", "count": float64(2)}},
+		{Level: "warn", Message: "Verification failed"},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events %+v, got %+v", expected, events)
+	}
+}
+
+func TestProgressWriterPartialLines(t *testing.T) {
+	var events []ProgressEvent
+	w := &progressWriter{progress: func(event any) { events = append(events, event.(ProgressEvent)) }}
+
+	for _, chunk := range []string{`{"level":"INFO","ms`, `g":"Downloading"}` + "\nplain text\n", "\n"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []ProgressEvent{
+		{Level: "info", Message: "Downloading"},
+		{Level: "info", Message: "plain text"},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events %+v, got %+v", expected, events)
+	}
+}
@@ -0,0 +1,179 @@
+// ABOUTME: Env command for printing where dragonglass keeps config, caches, and credentials
+// ABOUTME: Reports user-level directories, the credential store, and the active vault's files
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/auth"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
+	"github.com/gillisandrew/dragonglass-poc/internal/userdirs"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+// Environment lists the paths dragonglass reads and writes for the current user and vault
+type Environment struct {
+	ConfigDir  string `json:"config_dir"`
+	UserConfig string `json:"user_config"`
+	CacheDir   string `json:"cache_dir"`
+	TUFCache   string `json:"tuf_cache"`
+
+	Credentials Credentials `json:"credentials"`
+
+	// Directory used by older releases, only reported while it still exists
+	LegacyDir string `json:"legacy_dir,omitempty"`
+
+	// Nil outside a vault
+	Vault *VaultPaths `json:"vault"`
+}
+
+// Credentials describes where the GitHub token is stored
+type Credentials struct {
+	Store string `json:"store"`
+
+	// Credentials file, for the auto and file stores
+	File string `json:"file,omitempty"`
+
+	// OS keychain service, for the auto and keyring stores
+	KeyringService string `json:"keyring_service,omitempty"`
+}
+
+// VaultPaths are the files of the vault containing the working directory
+type VaultPaths struct {
+	Root           string `json:"root"`
+	Config         string `json:"config"`
+	Lockfile       string `json:"lockfile"`
+	DragonglassDir string `json:"dragonglass_dir"`
+}
+
+func NewEnvCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "env",
+		Short: "Print the directories and files dragonglass uses",
+		Long: `Print where dragonglass keeps its user config, caches, and credentials,
+and the files of the vault containing the working directory.
+
+User-level state lives in the OS config and cache directories
+($XDG_CONFIG_HOME and $XDG_CACHE_HOME on Linux, ~/Library on macOS).
+Files left in ~/.dragonglass by older releases are moved there automatically.
+
+Example:
+  dragonglass env
+  dragonglass env --json`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			asJSON, _ := cmd.Flags().GetBool("json")
+
+			if err := runEnvCommand(ctx, asJSON || ctx.Config.Output.Format == "json"); err != nil {
+				ctx.Fail("Env command failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("json", false, "Print the environment as JSON")
+	return cmd
+}
+
+func runEnvCommand(ctx *cmd.CommandContext, asJSON bool) error {
+	dirs, err := userdirs.Resolve()
+	if err != nil {
+		return fmt.Errorf("failed to resolve user directories: %w", err)
+	}
+
+	// Outside a vault only the user-level paths are reported
+	v, err := ctx.DiscoverVault()
+	if err != nil {
+		ctx.Logger.Debug("No active vault", ctx.Logger.Args("error", err))
+		v = nil
+	}
+
+	env := collectEnvironment(dirs, auth.ActiveCredentialStore(), v, ctx.ConfigPath, ctx.LockfilePath)
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(env)
+	}
+
+	return pterm.DefaultTable.WithData(env.rows()).Render()
+}
+
+// collectEnvironment gathers the paths for dirs, the credential store, and v (nil outside
+// a vault). configPath and lockfilePath are the --config and --lockfile overrides.
+func collectEnvironment(dirs *userdirs.Dirs, store auth.CredentialStore, v *vault.Vault, configPath, lockfilePath string) *Environment {
+	env := &Environment{
+		ConfigDir:   dirs.Config,
+		UserConfig:  filepath.Join(dirs.Config, config.UserConfigFileName),
+		CacheDir:    dirs.Cache,
+		TUFCache:    dirs.TUFCacheDir(),
+		Credentials: Credentials{Store: string(store)},
+	}
+
+	if store == auth.StoreAuto || store == auth.StoreFile {
+		env.Credentials.File = filepath.Join(dirs.Config, auth.TokenFile)
+	}
+	if store == auth.StoreAuto || store == auth.StoreKeyring {
+		env.Credentials.KeyringService = auth.KeyringService
+	}
+
+	if _, err := os.Stat(dirs.Legacy); err == nil {
+		env.LegacyDir = dirs.Legacy
+	}
+
+	if v != nil {
+		env.Vault = &VaultPaths{
+			Root:           v.Root,
+			Config:         v.ConfigPath(),
+			Lockfile:       v.LockfilePath(),
+			DragonglassDir: v.DragonglassDir(),
+		}
+		if configPath != "" {
+			env.Vault.Config = configPath
+		}
+		if lockfilePath != "" {
+			env.Vault.Lockfile = lockfilePath
+		}
+	}
+
+	return env
+}
+
+// rows renders the environment as a two-column table
+func (e *Environment) rows() pterm.TableData {
+	credentials := e.Credentials.Store
+	if e.Credentials.KeyringService != "" {
+		credentials += ", " + i18n.T("env.keyring_service", e.Credentials.KeyringService)
+	}
+	if e.Credentials.File != "" {
+		credentials += ", " + i18n.T("env.credentials_file", e.Credentials.File)
+	}
+
+	rows := pterm.TableData{
+		{i18n.T("env.config_dir"), e.ConfigDir},
+		{i18n.T("env.user_config"), e.UserConfig},
+		{i18n.T("env.cache_dir"), e.CacheDir},
+		{i18n.T("env.tuf_cache"), e.TUFCache},
+		{i18n.T("env.credential_store"), credentials},
+	}
+	if e.LegacyDir != "" {
+		rows = append(rows, []string{i18n.T("env.legacy_dir"), e.LegacyDir})
+	}
+
+	if e.Vault == nil {
+		return append(rows, []string{i18n.T("env.vault"), i18n.T("env.no_vault")})
+	}
+	return append(rows, pterm.TableData{
+		{i18n.T("env.vault"), e.Vault.Root},
+		{i18n.T("env.vault_config"), e.Vault.Config},
+		{i18n.T("env.lockfile"), e.Vault.Lockfile},
+		{i18n.T("env.dragonglass_dir"), e.Vault.DragonglassDir},
+	}...)
+}
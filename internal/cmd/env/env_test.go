@@ -0,0 +1,82 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/auth"
+	"github.com/gillisandrew/dragonglass-poc/internal/userdirs"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+func TestCollectEnvironment(t *testing.T) {
+	home := t.TempDir()
+	dirs, err := userdirs.ResolveFor("linux", func(string) string { return "" }, home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		store          auth.CredentialStore
+		vault          *vault.Vault
+		lockfile       string
+		file           string
+		keyringService string
+	}{
+		{name: "auto store outside a vault", store: auth.StoreAuto, file: filepath.Join(home, ".config", "dragonglass", "credentials.json"), keyringService: auth.KeyringService},
+		{name: "keyring store", store: auth.StoreKeyring, keyringService: auth.KeyringService},
+		{name: "env store in a vault", store: auth.StoreEnv, vault: vault.New("/vault")},
+		{name: "lockfile override", store: auth.StoreNone, vault: vault.New("/vault"), lockfile: "/elsewhere/lock.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := collectEnvironment(dirs, tt.store, tt.vault, "", tt.lockfile)
+
+			if env.UserConfig != filepath.Join(home, ".config", "dragonglass", "config.json") {
+				t.Errorf("unexpected user config %s", env.UserConfig)
+			}
+			if env.TUFCache != filepath.Join(home, ".cache", "dragonglass", "tuf") {
+				t.Errorf("unexpected TUF cache %s", env.TUFCache)
+			}
+			if env.Credentials.File != tt.file || env.Credentials.KeyringService != tt.keyringService {
+				t.Errorf("unexpected credentials %+v", env.Credentials)
+			}
+
+			if tt.vault == nil {
+				if env.Vault != nil {
+					t.Errorf("expected no vault, got %+v", env.Vault)
+				}
+				return
+			}
+			expected := tt.vault.LockfilePath()
+			if tt.lockfile != "" {
+				expected = tt.lockfile
+			}
+			if env.Vault == nil || env.Vault.Root != "/vault" || env.Vault.Lockfile != expected {
+				t.Errorf("unexpected vault paths %+v", env.Vault)
+			}
+		})
+	}
+}
+
+func TestCollectEnvironmentLegacyDir(t *testing.T) {
+	home := t.TempDir()
+	dirs, err := userdirs.ResolveFor("linux", func(string) string { return "" }, home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if env := collectEnvironment(dirs, auth.StoreAuto, nil, "", ""); env.LegacyDir != "" {
+		t.Errorf("expected no legacy dir, got %s", env.LegacyDir)
+	}
+
+	if err := os.Mkdir(dirs.Legacy, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if env := collectEnvironment(dirs, auth.StoreAuto, nil, "", ""); env.LegacyDir != dirs.Legacy {
+		t.Errorf("expected legacy dir %s, got %q", dirs.Legacy, env.LegacyDir)
+	}
+}
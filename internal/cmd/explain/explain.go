@@ -0,0 +1,319 @@
+// ABOUTME: Explain command reconstructing why an installed plugin is trusted
+// ABOUTME: Walks from the locked digest through attestation bundles and signing identities to the policy
+package explain
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/verify"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/evidence"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/manifest"
+	"github.com/gillisandrew/dragonglass-poc/internal/output"
+	"github.com/gillisandrew/dragonglass-poc/internal/ui"
+)
+
+// Explanation is the trust chain of an installed plugin: the artifact the lockfile pins,
+// the signed attestations about it, who signed them, where the signatures were logged,
+// and the policy rules they were checked against
+type Explanation struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+
+	Artifact     Artifact            `json:"artifact"`
+	Attestations []Attestation       `json:"attestations"`
+	Provenance   *Provenance         `json:"provenance,omitempty"`
+	Policy       Policy              `json:"policy"`
+	Checks       []attestation.Check `json:"checks"`
+
+	// Whether the chain holds under the current policy, and where it broke if not
+	Verified bool   `json:"verified"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Artifact is the plugin artifact the lockfile pins
+type Artifact struct {
+	Reference   string     `json:"reference"`
+	Digest      string     `json:"digest"`
+	Integrity   string     `json:"integrity,omitempty"`
+	InstalledAt *time.Time `json:"installed_at,omitempty"`
+}
+
+// Attestation is one signed attestation about the artifact and the evidence of its bundle
+type Attestation struct {
+	Kind          string `json:"kind"`
+	PredicateType string `json:"predicate_type"`
+	attestation.BundleEvidence
+}
+
+// Provenance is what the verified SLSA provenance says about the build
+type Provenance struct {
+	Builder      string     `json:"builder"`
+	Workflow     string     `json:"workflow,omitempty"`
+	Repository   string     `json:"repository,omitempty"`
+	SourceCommit string     `json:"source_commit,omitempty"`
+	BuiltAt      *time.Time `json:"built_at,omitempty"`
+}
+
+// Policy is the verification policy in effect for the plugin, including the overrides
+// the vault manifest declares for it
+type Policy struct {
+	StrictMode     bool                     `json:"strict_mode"`
+	TrustedBuilder string                   `json:"trusted_builder,omitempty"`
+	PluginPolicy   *manifest.PolicyOverride `json:"plugin_policy,omitempty"`
+
+	// Hash of the policy now, and of the one the lockfile entry was accepted under
+	Hash       string `json:"hash"`
+	LockedHash string `json:"locked_hash,omitempty"`
+}
+
+func NewExplainCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "explain <plugin-id>",
+		Short: "Explain why an installed plugin is trusted",
+		Long: `Reconstruct and print the trust chain of a plugin in the lockfile. The
+attestations of the locked digest are fetched and verified again, and each link
+is shown: the artifact digest, every attestation bundle, the certificate
+identity it was signed with, the workflow run that signed it, its Rekor
+transparency log entry, and the policy checks it passed or failed.
+
+Use it during incident response to see exactly what vouched for a plugin, or to
+learn what dragonglass guarantees. The command fails like verify when the chain
+no longer holds, after printing as much of it as could be established.
+--output json, yaml, or go-template='{{...}}' prints the chain for scripts.
+
+Example:
+  dragonglass explain obsidian-git
+  dragonglass explain obsidian-git -o go-template='{{range .attestations}}{{.runUrl}}{{end}}'`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			renderer, err := ctx.Renderer(cmd)
+			if err != nil {
+				ctx.Fail("Explain failed", err)
+			}
+			if err := runExplainCommand(cmd.Context(), ctx, args[0], renderer); err != nil {
+				ctx.Fail("Explain failed", err)
+			}
+		},
+	}
+
+	output.AddFlag(cmd)
+	return cmd
+}
+
+func runExplainCommand(opCtx context.Context, ctx *cmd.CommandContext, pluginID string, renderer *output.Renderer) error {
+	lockfilePath, err := ctx.ResolveLockfilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+
+	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load lockfile: %w", err)
+	}
+
+	entry, ok := lockfileData.GetPlugin(pluginID)
+	if !ok {
+		return dgerrors.Mark(fmt.Errorf("plugin %s not found in lockfile", pluginID), dgerrors.ErrNotInLockfile)
+	}
+	if entry.IsDev() {
+		return fmt.Errorf("plugin %s is an unverified dev build of %s and has no attestations to explain", pluginID, entry.OCIReference)
+	}
+
+	ref, err := registry.ParseReference(entry.OCIReference)
+	if err != nil {
+		return fmt.Errorf("invalid OCI reference %s: %w", entry.OCIReference, err)
+	}
+	pinned := ref.Registry + "/" + ref.Repository + "@" + entry.OCIDigest
+
+	policy, err := verify.DeclaredPolicy(ctx, pluginID)
+	if err != nil {
+		return err
+	}
+
+	ctx.Logger.Info("Verifying the locked artifact", ctx.Logger.Args("id", pluginID, "digest", entry.OCIDigest))
+	result, verifyErr := verify.Verify(opCtx, ctx, pinned, verify.DefaultVerifyOpts().WithExpectedDigest(entry.OCIDigest))
+
+	explanation := newExplanation(ctx, pluginID, entry, policy, result, verifyErr)
+	if renderer.Text() {
+		renderText(explanation)
+	} else if err := renderer.Render(os.Stdout, explanation); err != nil {
+		return err
+	}
+	return verifyErr
+}
+
+// newExplanation assembles the trust chain of entry from the result of verifying it
+// again, where err is why verification failed
+func newExplanation(ctx *cmd.CommandContext, pluginID string, entry lockfile.PluginEntry, pluginPolicy *manifest.PolicyOverride, result *verify.Result, err error) Explanation {
+	pluginCtx, pluginCfg := verify.ApplyPolicy(ctx, ctx.Config, pluginPolicy)
+
+	e := Explanation{
+		ID:      pluginID,
+		Name:    entry.Name,
+		Version: entry.Version,
+		Artifact: Artifact{
+			Reference:   entry.OCIReference,
+			Digest:      entry.OCIDigest,
+			Integrity:   entry.Integrity,
+			InstalledAt: entry.InstalledAt,
+		},
+		Attestations: []Attestation{},
+		Policy: Policy{
+			StrictMode:     pluginCfg.Verification.StrictMode,
+			TrustedBuilder: pluginCtx.TrustedBuilder,
+			PluginPolicy:   pluginPolicy,
+			Hash:           pluginCfg.Verification.PolicyHash(),
+			LockedHash:     entry.VerificationState.PolicyHash,
+		},
+		Checks:   []attestation.Check{},
+		Verified: err == nil,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	if result == nil || result.Attestation == nil {
+		return e
+	}
+	for _, att := range result.Attestation.Bundles() {
+		e.Attestations = append(e.Attestations, Attestation{
+			Kind:           evidence.Kind(att.PredicateType),
+			PredicateType:  att.PredicateType,
+			BundleEvidence: *att.Bundle,
+		})
+	}
+	if slsa := result.Attestation.SLSA; slsa != nil {
+		e.Provenance = &Provenance{
+			Builder:      slsa.Builder,
+			Workflow:     slsa.Workflow,
+			Repository:   slsa.Repository,
+			SourceCommit: slsa.SourceCommit,
+			BuiltAt:      slsa.BuildStartedOn,
+		}
+	}
+	if result.Attestation.Checks != nil {
+		e.Checks = result.Attestation.Checks
+	}
+	return e
+}
+
+// renderText prints each link of the chain as a section, from the artifact to the policy
+func renderText(e Explanation) {
+	pterm.DefaultSection.Println(i18n.T("explain.section.artifact", e.ID, e.Version))
+	pterm.DefaultTable.WithData(withoutEmpty(pterm.TableData{
+		{i18n.T("explain.reference"), e.Artifact.Reference},
+		{i18n.T("explain.digest"), e.Artifact.Digest},
+		{i18n.T("explain.integrity"), e.Artifact.Integrity},
+		{i18n.T("explain.installed"), formatTime(e.Artifact.InstalledAt)},
+	})).Render()
+
+	if len(e.Attestations) == 0 {
+		ui.Warning(i18n.T("explain.no_bundles"))
+	}
+	for i, att := range e.Attestations {
+		pterm.DefaultSection.Println(i18n.T("explain.section.attestation", i+1, att.Kind))
+		rows := pterm.TableData{
+			{i18n.T("explain.predicate_type"), att.PredicateType},
+			{i18n.T("explain.bundle_digest"), att.Digest},
+			{i18n.T("explain.certificate_identity"), att.CertificateIdentity},
+			{i18n.T("explain.certificate_issuer"), att.CertificateIssuer},
+			{i18n.T("explain.run"), att.RunURL},
+			{i18n.T("explain.source"), joinNonEmpty(att.SourceRepository, att.SourceCommit)},
+		}
+		for _, entry := range att.RekorEntries {
+			rows = append(rows,
+				[]string{i18n.T("explain.rekor_entry"), i18n.T("explain.rekor_logged", entry.LogIndex, entry.IntegratedTime.Format(time.RFC3339))},
+				[]string{i18n.T("explain.rekor_url"), entry.SearchURL()},
+			)
+		}
+		pterm.DefaultTable.WithData(withoutEmpty(rows)).Render()
+	}
+
+	if p := e.Provenance; p != nil {
+		pterm.DefaultSection.Println(i18n.T("explain.section.provenance"))
+		pterm.DefaultTable.WithData(withoutEmpty(pterm.TableData{
+			{i18n.T("explain.builder"), p.Builder},
+			{i18n.T("explain.workflow"), p.Workflow},
+			{i18n.T("explain.source"), joinNonEmpty(p.Repository, p.SourceCommit)},
+			{i18n.T("explain.built"), formatTime(p.BuiltAt)},
+		})).Render()
+	}
+
+	pterm.DefaultSection.Println(i18n.T("explain.section.policy"))
+	rows := pterm.TableData{
+		{i18n.T("explain.strict_mode"), strconv.FormatBool(e.Policy.StrictMode)},
+		{i18n.T("explain.trusted_builder"), e.Policy.TrustedBuilder},
+	}
+	if pp := e.Policy.PluginPolicy; pp != nil {
+		rows = append(rows,
+			[]string{i18n.T("explain.pinned_builder"), pp.TrustedBuilder},
+			[]string{i18n.T("explain.pinned_repository"), pp.SourceRepository},
+		)
+	}
+	rows = append(rows, []string{i18n.T("explain.policy_hash"), e.Policy.Hash})
+	if e.Policy.LockedHash != "" && e.Policy.LockedHash != e.Policy.Hash {
+		rows = append(rows, []string{i18n.T("explain.locked_policy_hash"), e.Policy.LockedHash})
+	}
+	for _, check := range e.Checks {
+		rows = append(rows, []string{i18n.T("explain.check", check.Name), checkOutcome(check)})
+	}
+	pterm.DefaultTable.WithData(withoutEmpty(rows)).Render()
+
+	if e.Verified {
+		ui.Success(i18n.T("explain.verified"))
+	}
+}
+
+// checkOutcome describes a check as its status and severity, followed by the detail
+func checkOutcome(check attestation.Check) string {
+	outcome := fmt.Sprintf("%s (%s)", check.Status, check.Severity)
+	if check.Detail != "" {
+		outcome += ": " + check.Detail
+	}
+	return outcome
+}
+
+// withoutEmpty drops rows whose value is empty, so absent links are not shown as blanks
+func withoutEmpty(rows pterm.TableData) pterm.TableData {
+	kept := pterm.TableData{}
+	for _, row := range rows {
+		if row[1] != "" {
+			kept = append(kept, row)
+		}
+	}
+	return kept
+}
+
+// joinNonEmpty formats a repository and commit as "repository@commit", leaving out
+// whichever is missing
+func joinNonEmpty(repository, commit string) string {
+	switch {
+	case repository == "":
+		return commit
+	case commit == "":
+		return repository
+	default:
+		return repository + "@" + commit
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,124 @@
+package explain
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/verify"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/manifest"
+)
+
+func TestNewExplanation(t *testing.T) {
+	ctx := &cmd.CommandContext{
+		Config:         config.DefaultConfig(),
+		TrustedBuilder: "https://github.com/gillisandrew/dragonglass-poc/.github/workflows/build.yml@refs/heads/main",
+		Logger:         pterm.DefaultLogger.WithWriter(os.Stderr).WithLevel(pterm.LogLevelError),
+	}
+	entry := lockfile.PluginEntry{
+		Name:         "Sample",
+		Version:      "1.2.0",
+		OCIReference: "ghcr.io/example/sample:1.2.0",
+		OCIDigest:    "sha256:aaaa",
+		VerificationState: lockfile.VerificationState{
+			PolicyHash: "sha256:old",
+		},
+	}
+	strict := true
+	pinned := &manifest.PolicyOverride{StrictMode: &strict, SourceRepository: "example/sample"}
+
+	logged := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	result := &verify.Result{Attestation: &attestation.VerificationResult{
+		Results: []attestation.AttestationData{
+			{PredicateType: attestation.SLSAPredicateV1, Bundle: &attestation.BundleEvidence{
+				Digest:              "sha256:bbbb",
+				CertificateIdentity: "https://github.com/example/sample/.github/workflows/release.yml@refs/tags/1.2.0",
+				RunURL:              "https://github.com/example/sample/actions/runs/42/attempts/1",
+				RekorEntries:        []attestation.RekorEntry{{LogIndex: 1234, IntegratedTime: logged}},
+			}},
+			{PredicateType: attestation.SBOMPredicateV2},
+		},
+		SLSA:   &attestation.SLSAResult{Builder: "builder", Repository: "example/sample", SourceCommit: "abc123"},
+		Checks: []attestation.Check{{Name: attestation.CheckSignature, Status: attestation.CheckPassed, Severity: attestation.SeverityHard}},
+	}}
+
+	e := newExplanation(ctx, "sample", entry, pinned, result, nil)
+	if !e.Verified || e.Error != "" {
+		t.Errorf("expected a verified explanation, got %+v", e)
+	}
+	if e.Artifact.Digest != "sha256:aaaa" || e.Artifact.Reference != entry.OCIReference {
+		t.Errorf("unexpected artifact %+v", e.Artifact)
+	}
+	if len(e.Attestations) != 1 {
+		t.Fatalf("expected only the bundled attestation, got %+v", e.Attestations)
+	}
+	att := e.Attestations[0]
+	if att.Kind != "provenance" || att.Digest != "sha256:bbbb" || att.RekorEntries[0].SearchURL() != "https://search.sigstore.dev/?logIndex=1234" {
+		t.Errorf("unexpected attestation %+v", att)
+	}
+	if e.Provenance == nil || e.Provenance.SourceCommit != "abc123" {
+		t.Errorf("unexpected provenance %+v", e.Provenance)
+	}
+	if !e.Policy.StrictMode || e.Policy.TrustedBuilder != ctx.TrustedBuilder || e.Policy.LockedHash != "sha256:old" || e.Policy.Hash == "" {
+		t.Errorf("unexpected policy %+v", e.Policy)
+	}
+	if len(e.Checks) != 1 {
+		t.Errorf("expected the checks to be carried over, got %+v", e.Checks)
+	}
+
+	failed := newExplanation(ctx, "sample", entry, nil, nil, errors.New("failed to fetch manifest"))
+	if failed.Verified || failed.Error != "failed to fetch manifest" || failed.Attestations == nil || failed.Checks == nil {
+		t.Errorf("expected a failed explanation with empty lists, got %+v", failed)
+	}
+	if failed.Policy.StrictMode {
+		t.Error("expected the vault policy without plugin overrides")
+	}
+}
+
+func TestCheckOutcome(t *testing.T) {
+	tests := []struct {
+		check    attestation.Check
+		expected string
+	}{
+		{
+			check:    attestation.Check{Name: attestation.CheckSignature, Status: attestation.CheckPassed, Severity: attestation.SeverityHard},
+			expected: "passed (hard)",
+		},
+		{
+			check:    attestation.Check{Name: attestation.CheckSBOM, Status: attestation.CheckFailed, Severity: attestation.SeveritySoft, Detail: "no SBOM attestation"},
+			expected: "failed (soft): no SBOM attestation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.check.Name, func(t *testing.T) {
+			if got := checkOutcome(tt.check); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestJoinNonEmpty(t *testing.T) {
+	tests := []struct {
+		repository, commit, expected string
+	}{
+		{"https://github.com/example/sample", "abc123", "https://github.com/example/sample@abc123"},
+		{"https://github.com/example/sample", "", "https://github.com/example/sample"},
+		{"", "abc123", "abc123"},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := joinNonEmpty(tt.repository, tt.commit); got != tt.expected {
+			t.Errorf("joinNonEmpty(%q, %q) = %q, expected %q", tt.repository, tt.commit, got, tt.expected)
+		}
+	}
+}
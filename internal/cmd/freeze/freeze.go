@@ -0,0 +1,84 @@
+// ABOUTME: Freeze command locking a vault's plugin set between review windows
+// ABOUTME: Records the frozen state in the lockfile so it travels with the vault
+package freeze
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+)
+
+func NewFreezeCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "freeze [on|off]",
+		Short: "Lock the vault's plugins until the freeze is lifted",
+		Long: `Freeze the vault so its pinned plugins cannot change. While frozen, add and
+sync refuse to run unless --unfreeze-once is given, and the daemon refuses to
+install or remove plugins. install keeps working: it installs exactly what the
+lockfile pins, without resolving dragonglass.json ranges or adopting re-pushed tags.
+
+The frozen state is recorded in the lockfile, so committing the lockfile freezes
+the vault for everyone who shares it. Without an argument, the current state is
+shown.
+
+Example:
+  dragonglass freeze on
+  dragonglass freeze
+  dragonglass freeze off`,
+		Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{"on", "off"},
+		Run: func(cmd *cobra.Command, args []string) {
+			state := ""
+			if len(args) == 1 {
+				state = args[0]
+			}
+
+			if err := runFreeze(ctx, state, time.Now()); err != nil {
+				ctx.Fail("Freeze failed", err)
+			}
+		},
+	}
+
+	return cmd
+}
+
+// runFreeze freezes the vault for "on", lifts the freeze for "off", and reports the
+// current state for ""
+func runFreeze(ctx *cmd.CommandContext, state string, now time.Time) error {
+	lockfilePath, err := ctx.ResolveLockfilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+
+	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load lockfile: %w", err)
+	}
+
+	switch state {
+	case "on":
+		lockfileData.Freeze(now)
+	case "off":
+		lockfileData.Unfreeze()
+	case "":
+	default:
+		return fmt.Errorf("unknown freeze state %q, expected on or off", state)
+	}
+
+	if lockfileData.Dirty() {
+		if err := lockfile.SaveLockfile(lockfileData, lockfilePath); err != nil {
+			return fmt.Errorf("failed to save lockfile: %w", err)
+		}
+	}
+
+	if lockfileData.Frozen() {
+		ctx.Logger.Info("Vault is frozen", ctx.Logger.Args("since", lockfileData.Metadata.FrozenAt.Format(time.RFC3339), "lockfile", lockfilePath))
+	} else {
+		ctx.Logger.Info("Vault is not frozen", ctx.Logger.Args("lockfile", lockfilePath))
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package freeze
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+func TestRunFreeze(t *testing.T) {
+	v := vault.New(t.TempDir())
+	if err := os.MkdirAll(v.ObsidianDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	ctx := &cmd.CommandContext{
+		VaultDir: v.Root,
+		Logger:   pterm.DefaultLogger.WithWriter(os.Stderr).WithLevel(pterm.LogLevelError),
+	}
+	frozenAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		state    string
+		expected bool
+		errorMsg string
+	}{
+		{state: "", expected: false},
+		{state: "on", expected: true},
+		{state: "on", expected: true},
+		{state: "", expected: true},
+		{state: "off", expected: false},
+		{state: "maybe", errorMsg: "unknown freeze state"},
+	}
+
+	for _, tt := range tests {
+		err := runFreeze(ctx, tt.state, frozenAt)
+		if tt.errorMsg != "" {
+			if err == nil {
+				t.Errorf("%q: expected error containing %q", tt.state, tt.errorMsg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.state, err)
+		}
+
+		loaded, err := lockfile.LoadLockfile(v.LockfilePath())
+		if err != nil {
+			t.Fatalf("failed to load lockfile: %v", err)
+		}
+		if loaded.Frozen() != tt.expected {
+			t.Errorf("%q: expected frozen %v, got %v", tt.state, tt.expected, loaded.Frozen())
+		}
+	}
+
+	// Showing the state of a vault without a lockfile does not create one
+	other := vault.New(t.TempDir())
+	if err := os.MkdirAll(other.ObsidianDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := runFreeze(&cmd.CommandContext{VaultDir: other.Root, Logger: ctx.Logger}, "", frozenAt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(other.LockfilePath()); !os.IsNotExist(err) {
+		t.Errorf("expected no lockfile, got %v", err)
+	}
+}
@@ -0,0 +1,293 @@
+// ABOUTME: Gc command reclaiming disk space from vault state no lockfile entry references
+// ABOUTME: Removes stale install workspaces and unreferenced cache and store blobs
+package gc
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/ui"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+// StagingGracePeriod is how old a workspace or staging directory must be before it is
+// collected, so an install running alongside gc keeps its workspace
+const StagingGracePeriod = time.Hour
+
+// Reasons an entry is collected
+const (
+	ReasonStaleStaging     = "stale staging directory"
+	ReasonStaleWorkspace   = "stale workspace"
+	ReasonUnreferencedBlob = "unreferenced blob"
+)
+
+// Entry is a file or directory gc collects
+type Entry struct {
+	Path   string
+	Reason string
+	Size   uint64
+}
+
+func NewGCCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove cached and stored data no lockfile entry references",
+		Long: `Reclaim disk space in the vault by removing data that nothing refers to:
+
+  - workspaces left in .dragonglass/tmp by operations that were killed, and
+    staging directories older releases left in .obsidian/plugins, once they
+    are an hour old
+  - blobs in .dragonglass/cache and .dragonglass/store whose digest is not the
+    manifest, config, or a layer of any plugin pinned in the lockfile
+
+Installed plugins, their settings, and install attestations are never removed.
+Pass --dry-run to list what would be removed and how many bytes it would reclaim.
+
+Example:
+  dragonglass gc --dry-run
+  dragonglass gc`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			if err := runGC(ctx, dryRun, time.Now()); err != nil {
+				ctx.Fail("Garbage collection failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "List what would be removed without removing it")
+	return cmd
+}
+
+func runGC(ctx *cmd.CommandContext, dryRun bool, now time.Time) error {
+	v, err := ctx.DiscoverVault()
+	if err != nil {
+		return fmt.Errorf("failed to find vault: %w", err)
+	}
+
+	lockfilePath, err := ctx.ResolveLockfilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+
+	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load lockfile: %w", err)
+	}
+
+	entries, err := Plan(v, lockfileData, now)
+	if err != nil {
+		return err
+	}
+
+	var reclaimed uint64
+	for _, entry := range entries {
+		rel, err := filepath.Rel(v.Root, entry.Path)
+		if err != nil {
+			rel = entry.Path
+		}
+		if dryRun {
+			ctx.Logger.Info("Would remove", ctx.Logger.Args("path", rel, "reason", entry.Reason, "size", ui.FormatBytes(entry.Size)))
+		} else {
+			if err := os.RemoveAll(entry.Path); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", rel, err)
+			}
+			ctx.Logger.Debug("Removed", ctx.Logger.Args("path", rel, "reason", entry.Reason, "size", ui.FormatBytes(entry.Size)))
+		}
+		reclaimed += entry.Size
+	}
+
+	if dryRun {
+		ctx.Logger.Info("Garbage collection dry run", ctx.Logger.Args("entries", len(entries), "reclaimable", ui.FormatBytes(reclaimed), "bytes", reclaimed))
+	} else {
+		ctx.Logger.Info("Garbage collection complete", ctx.Logger.Args("removed", len(entries), "reclaimed", ui.FormatBytes(reclaimed), "bytes", reclaimed))
+	}
+	return nil
+}
+
+// Plan returns what gc collects in v at now, given the plugins lockfileData pins, sorted
+// by path
+func Plan(v *vault.Vault, lockfileData *lockfile.Lockfile, now time.Time) ([]Entry, error) {
+	staging, err := staleStaging(v.PluginsDir(), now)
+	if err != nil {
+		return nil, err
+	}
+	workspaces, err := staleWorkspaces(v.TmpDir(), now)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := ReferencedDigests(lockfileData)
+	var entries []Entry
+	entries = append(entries, staging...)
+	entries = append(entries, workspaces...)
+	for _, dir := range []string{v.CacheDir(), v.StoreDir()} {
+		blobs, err := unreferencedBlobs(dir, referenced)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, blobs...)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+// ReferencedDigests returns the digests of every manifest and blob the lockfile pins
+func ReferencedDigests(lockfileData *lockfile.Lockfile) map[string]bool {
+	referenced := map[string]bool{}
+	for _, entry := range lockfileData.Plugins {
+		// A dev build's digest names its files, not anything in the cache
+		if entry.IsDev() {
+			continue
+		}
+		referenced[entry.OCIDigest] = true
+		if entry.OCIBlobs != nil {
+			referenced[entry.OCIBlobs.Config] = true
+			for _, layer := range entry.OCIBlobs.Layers {
+				referenced[layer] = true
+			}
+		}
+	}
+	delete(referenced, "")
+	return referenced
+}
+
+// staleStaging returns the staging directories older releases created in pluginsDir that
+// were last modified before the grace period
+func staleStaging(pluginsDir string, now time.Time) ([]Entry, error) {
+	dirEntries, err := os.ReadDir(pluginsDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugins directory: %w", err)
+	}
+
+	var entries []Entry
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if !dirEntry.IsDir() || !strings.HasPrefix(name, ".") || !strings.Contains(name, ".partial-") {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if now.Sub(info.ModTime()) < StagingGracePeriod {
+			continue
+		}
+
+		path := filepath.Join(pluginsDir, name)
+		size, err := diskUsage(path)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{Path: path, Reason: ReasonStaleStaging, Size: size})
+	}
+	return entries, nil
+}
+
+// staleWorkspaces returns the workspaces in tmpDir that were last modified before the
+// grace period
+func staleWorkspaces(tmpDir string, now time.Time) ([]Entry, error) {
+	dirEntries, err := os.ReadDir(tmpDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read temporary directory: %w", err)
+	}
+
+	var entries []Entry
+	for _, dirEntry := range dirEntries {
+		info, err := dirEntry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", dirEntry.Name(), err)
+		}
+		if now.Sub(info.ModTime()) < StagingGracePeriod {
+			continue
+		}
+
+		path := filepath.Join(tmpDir, dirEntry.Name())
+		size, err := diskUsage(path)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{Path: path, Reason: ReasonStaleWorkspace, Size: size})
+	}
+	return entries, nil
+}
+
+// unreferencedBlobs returns the blobs in dir, stored in the OCI image layout as
+// blobs/<algorithm>/<encoded>, whose digest is not referenced
+func unreferencedBlobs(dir string, referenced map[string]bool) ([]Entry, error) {
+	blobsDir := filepath.Join(dir, "blobs")
+	algorithms, err := os.ReadDir(blobsDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", blobsDir, err)
+	}
+
+	var entries []Entry
+	for _, algorithm := range algorithms {
+		if !algorithm.IsDir() {
+			continue
+		}
+		blobs, err := os.ReadDir(filepath.Join(blobsDir, algorithm.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", blobsDir, err)
+		}
+		for _, blob := range blobs {
+			if blob.IsDir() || referenced[algorithm.Name()+":"+blob.Name()] {
+				continue
+			}
+			info, err := blob.Info()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read blob %s: %w", blob.Name(), err)
+			}
+			entries = append(entries, Entry{
+				Path:   filepath.Join(blobsDir, algorithm.Name(), blob.Name()),
+				Reason: ReasonUnreferencedBlob,
+				Size:   uint64(info.Size()),
+			})
+		}
+	}
+	return entries, nil
+}
+
+// diskUsage returns the total size of the regular files under path
+func diskUsage(path string) (uint64, error) {
+	var size uint64
+	err := filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size += uint64(info.Size())
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure %s: %w", path, err)
+	}
+	return size, nil
+}
@@ -0,0 +1,115 @@
+package gc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+func TestRunGC(t *testing.T) {
+	v := vault.New(t.TempDir())
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	write := func(path string, size int, modTime time.Time) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(filepath.Dir(path), modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	installed := filepath.Join(v.PluginDir("sample"), "main.js")
+	stale := filepath.Join(v.PluginsDir(), ".sample.partial-111", "main.js")
+	fresh := filepath.Join(v.PluginsDir(), ".sample.partial-222", "main.js")
+	crashed := filepath.Join(v.TmpDir(), "11111111-1111-1111-1111-111111111111", "sample", "main.js")
+	running := filepath.Join(v.TmpDir(), "22222222-2222-2222-2222-222222222222", "sample", "main.js")
+	referenced := filepath.Join(v.StoreDir(), "blobs", "sha256", "aaa")
+	orphan := filepath.Join(v.CacheDir(), "blobs", "sha256", "bbb")
+	write(installed, 10, now.Add(-48*time.Hour))
+	write(stale, 100, now.Add(-2*time.Hour))
+	write(fresh, 100, now.Add(-time.Minute))
+	write(crashed, 40, now.Add(-2*time.Hour))
+	write(running, 40, now.Add(-time.Minute))
+	// Workspaces are aged by the workspace directory itself
+	for path, modTime := range map[string]time.Time{crashed: now.Add(-2 * time.Hour), running: now.Add(-time.Minute)} {
+		if err := os.Chtimes(filepath.Dir(filepath.Dir(path)), modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(referenced, 50, now)
+	write(orphan, 25, now)
+
+	lockfileData := lockfile.NewLockfile(v.Root)
+	if err := lockfileData.AddPlugin("sample", lockfile.PluginEntry{
+		Name:         "Sample",
+		OCIReference: "ghcr.io/owner/sample:1.0.0",
+		OCIDigest:    "sha256:manifest",
+		OCIBlobs:     &lockfile.BlobDigests{Config: "sha256:config", Layers: []string{"sha256:aaa"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(v.DragonglassDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := lockfile.SaveLockfile(lockfileData, v.LockfilePath()); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Plan(v, lockfileData, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crashedWorkspace := filepath.Dir(filepath.Dir(crashed))
+	if len(entries) != 3 || entries[0].Path != orphan || entries[1].Path != crashedWorkspace || entries[2].Path != filepath.Dir(stale) {
+		t.Fatalf("expected orphaned blob, crashed workspace, and stale staging directory, got %+v", entries)
+	}
+	if entries[0].Size != 25 || entries[0].Reason != ReasonUnreferencedBlob ||
+		entries[1].Size != 40 || entries[1].Reason != ReasonStaleWorkspace ||
+		entries[2].Size != 100 || entries[2].Reason != ReasonStaleStaging {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+
+	ctx := &cmd.CommandContext{
+		VaultDir:     v.Root,
+		LockfilePath: v.LockfilePath(),
+		Logger:       pterm.DefaultLogger.WithWriter(os.Stderr).WithLevel(pterm.LogLevelError),
+	}
+	if err := os.MkdirAll(v.ObsidianDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runGC(ctx, true, now); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{stale, crashed, orphan} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected dry run to keep %s, got %v", path, err)
+		}
+	}
+
+	if err := runGC(ctx, false, now); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{stale, crashed, orphan} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got %v", path, err)
+		}
+	}
+	for _, path := range []string{installed, fresh, running, referenced} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s to be kept, got %v", path, err)
+		}
+	}
+}
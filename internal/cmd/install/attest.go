@@ -0,0 +1,113 @@
+// ABOUTME: Signed in-toto attestations recording each plugin install into the vault
+// ABOUTME: Signs with a configured local key, or keyless with the Actions OIDC identity
+package install
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/auth"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/intoto"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/sigstore"
+)
+
+// attestInstall records that entry was installed as pluginID at installedAt, when
+// install.attestations is enabled. The attestation is written to
+// .dragonglass/attestations as a DSSE envelope (local key) or Sigstore bundle (keyless).
+func attestInstall(ctx context.Context, cmdCtx *cmd.CommandContext, pluginID string, entry lockfile.PluginEntry, installedAt time.Time) error {
+	cfg := cmdCtx.Config
+	if !cfg.Install.Attestations {
+		return nil
+	}
+
+	v, err := cmdCtx.DiscoverVault()
+	if err != nil {
+		return fmt.Errorf("failed to find vault: %w", err)
+	}
+
+	state := entry.VerificationState
+	statement, err := intoto.NewInstallStatement(entry.OCIDigest, intoto.InstallPredicate{
+		Vault: filepath.Base(v.Root),
+		Plugin: intoto.InstallPlugin{
+			ID:           pluginID,
+			Name:         entry.Name,
+			Version:      entry.Version,
+			OCIReference: entry.OCIReference,
+		},
+		InstalledAt: installedAt,
+		Policy: intoto.InstallPolicy{
+			Hash:           state.PolicyHash,
+			StrictMode:     cfg.Verification.StrictMode,
+			TrustedBuilder: cmdCtx.TrustedBuilder,
+		},
+		Verification: intoto.InstallVerification{
+			ProvenanceVerified: state.ProvenanceVerified,
+			SBOMVerified:       state.SBOMVerified,
+			BuilderID:          state.BuilderID,
+			SLSALevel:          state.SLSALevel,
+		},
+		Files: entry.Files,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create install attestation: %w", err)
+	}
+
+	data, ext, err := signInstallStatement(ctx, cfg, statement)
+	if err != nil {
+		return err
+	}
+
+	path, err := intoto.Save(v.AttestationsDir(), pluginID, installedAt, data, ext)
+	if err != nil {
+		return err
+	}
+
+	cmdCtx.Logger.Debug("Recorded install attestation", cmdCtx.Logger.Args("id", pluginID, "path", makeRelativePath(path)))
+	return nil
+}
+
+// signInstallStatement signs statement with install.attestation_key when set, and
+// otherwise keyless through Sigstore when the job has an Actions OIDC identity. It returns
+// the signed attestation and the file extension it is stored with.
+func signInstallStatement(ctx context.Context, cfg *config.Config, statement *intoto.Statement) ([]byte, string, error) {
+	if cfg.Install.AttestationKey != "" {
+		signer, err := intoto.LoadSigner(cfg.Install.AttestationKey)
+		if err != nil {
+			return nil, "", err
+		}
+		envelope, err := signer.Sign(statement)
+		if err != nil {
+			return nil, "", err
+		}
+		data, err := json.MarshalIndent(envelope, "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode install attestation: %w", err)
+		}
+		return data, intoto.EnvelopeExt, nil
+	}
+
+	if !auth.ActionsOIDCAvailable(os.Getenv) {
+		return nil, "", fmt.Errorf("install.attestations is enabled but no signing identity is available (set install.attestation_key, or run in GitHub Actions with the id-token: write permission)")
+	}
+
+	idToken, err := auth.RequestActionsIDToken(ctx, auth.DefaultOIDCOpts().WithAudience(sigstore.SigningAudience))
+	if err != nil {
+		return nil, "", err
+	}
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode install attestation: %w", err)
+	}
+	data, err := sigstore.SignKeyless(ctx, payload, intoto.PayloadType, idToken)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, intoto.BundleExt, nil
+}
@@ -0,0 +1,50 @@
+// ABOUTME: Audit log records of plugin verification outcomes from install and re-verification
+// ABOUTME: Writes the newest entry of a plugin's verification history as a verify event
+package install
+
+import (
+	"strings"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/audit"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+// AuditVerification records the newest verification in the history of entry in the audit
+// log of v. Entries that were never verified, such as dev builds, are skipped.
+func AuditVerification(v *vault.Vault, pluginID string, entry lockfile.PluginEntry) error {
+	if len(entry.VerificationHistory) == 0 {
+		return nil
+	}
+	record := entry.VerificationHistory[len(entry.VerificationHistory)-1]
+
+	event := audit.Event{
+		Time:      record.VerifiedAt,
+		Action:    audit.ActionVerify,
+		Decision:  audit.DecisionPassed,
+		PluginID:  pluginID,
+		ToVersion: record.Version,
+		ToDigest:  record.OCIDigest,
+	}
+	if !record.Passed() {
+		event.Decision = audit.DecisionFailed
+		event.Reason = verificationFailure(record, entry.VerificationState)
+	}
+	return audit.Append(v.AuditLogPath(), event)
+}
+
+// verificationFailure summarises why the verification in record did not pass
+func verificationFailure(record lockfile.VerificationRecord, state lockfile.VerificationState) string {
+	var reasons []string
+	if !record.ProvenanceVerified {
+		reasons = append(reasons, "provenance not verified")
+	}
+	if !record.SBOMVerified {
+		reasons = append(reasons, "SBOM not verified")
+	}
+	// The state holds the messages when record is the current verification
+	if state.VerifiedAt != nil && state.VerifiedAt.Equal(record.VerifiedAt) {
+		reasons = append(reasons, state.Errors...)
+	}
+	return strings.Join(reasons, "; ")
+}
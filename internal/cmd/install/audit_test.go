@@ -0,0 +1,64 @@
+package install
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/audit"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+func TestAuditVerification(t *testing.T) {
+	verifiedAt := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		state          lockfile.VerificationState
+		expectDecision string
+		expectReason   string
+	}{
+		{name: "never verified"},
+		{
+			name:           "passed",
+			state:          lockfile.VerificationState{ProvenanceVerified: true, SBOMVerified: true, VerifiedAt: &verifiedAt},
+			expectDecision: audit.DecisionPassed,
+		},
+		{
+			name:           "failed",
+			state:          lockfile.VerificationState{ProvenanceVerified: true, Errors: []string{"digest mismatch"}, VerifiedAt: &verifiedAt},
+			expectDecision: audit.DecisionFailed,
+			expectReason:   "SBOM not verified; digest mismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := vault.New(t.TempDir())
+			lf := lockfile.NewLockfile(v.Root)
+			entry := lockfile.PluginEntry{Name: "TaskNotes", Version: "1.2.0", OCIDigest: "sha256:next", VerificationState: tt.state}
+			if err := lf.AddPlugin("tasknotes", entry); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := AuditVerification(v, "tasknotes", lf.Plugins["tasknotes"]); err != nil {
+				t.Fatal(err)
+			}
+
+			events, err := audit.Load(v.AuditLogPath())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.expectDecision == "" {
+				if len(events) != 0 {
+					t.Errorf("expected no audit events, got %+v", events)
+				}
+				return
+			}
+			if len(events) != 1 || events[0].Action != audit.ActionVerify || events[0].Decision != tt.expectDecision ||
+				events[0].Reason != tt.expectReason || events[0].ToDigest != "sha256:next" || !events[0].Time.Equal(verifiedAt) {
+				t.Errorf("expected one %s verify event, got %+v", tt.expectDecision, events)
+			}
+		})
+	}
+}
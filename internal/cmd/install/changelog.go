@@ -0,0 +1,40 @@
+// ABOUTME: Release notes shown when sync updates a locked plugin to a newer version
+// ABOUTME: Fetched from the source repository the locked version's provenance names
+package install
+
+import (
+	"context"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/changelog"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/ui"
+)
+
+// showChangelog prints the release notes of version, which the locked plugin pluginID is
+// being updated to. The source repository comes from the locked version's provenance, so
+// nothing is shown for plugins installed without one. Release notes are informational:
+// failing to fetch them is only logged.
+func showChangelog(ctx context.Context, cmdCtx *cmd.CommandContext, client *changelog.Client, pluginID string, entry lockfile.PluginEntry, version string) {
+	if client == nil || ui.Quiet() || entry.VerificationState.SourceRepository == "" {
+		return
+	}
+
+	release, err := client.Release(ctx, entry.VerificationState.SourceRepository, version)
+	if err != nil {
+		cmdCtx.Logger.Debug("Could not fetch release notes", cmdCtx.Logger.Args("id", pluginID, "version", version, "error", err))
+		return
+	}
+	if release == nil {
+		cmdCtx.Logger.Debug("No release notes published", cmdCtx.Logger.Args("id", pluginID, "version", version, "repository", entry.VerificationState.SourceRepository))
+		return
+	}
+
+	text := release.Notes
+	if text != "" {
+		text += "\n\n"
+	}
+	text += i18n.T("install.changelog.link", release.URL)
+	ui.Box(i18n.T("install.changelog.title", pluginID, entry.Version, version), text)
+}
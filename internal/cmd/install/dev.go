@@ -0,0 +1,369 @@
+// ABOUTME: Dev commands installing plugins built locally from a git ref with dragonglass-build
+// ABOUTME: Dev builds are recorded in the lockfile as unverified and only dev uninstall removes them
+package install
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/hooks"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+	"github.com/gillisandrew/dragonglass-poc/internal/workspace"
+)
+
+// DefaultBuilder is the build tool dev install runs, looked up in PATH
+const DefaultBuilder = "dragonglass-build"
+
+// commitPattern matches full commit SHAs, which dragonglass-build checks out with --commit
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// DevInstallOptions controls how a plugin is built and installed from source
+type DevInstallOptions struct {
+	// Directory of the plugin within the repository (default: its root)
+	Directory string
+
+	// Directory npm run build writes main.js to, relative to the plugin directory
+	BuildDir string
+
+	// Build tool to run (default DefaultBuilder)
+	Builder string
+
+	// Replace a plugin installed from the registry, or one dragonglass did not install
+	Force bool
+
+	// Install even though the vault is frozen
+	UnfreezeOnce bool
+}
+
+func NewDevCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Install unverified plugin builds from source while developing them",
+	}
+
+	cmd.AddCommand(newDevInstallCommand(ctx))
+	cmd.AddCommand(newDevUninstallCommand(ctx))
+	cmd.AddCommand(newDevWatchCommand(ctx))
+	return cmd
+}
+
+func newDevInstallCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install <git-url>[#<ref>]",
+		Short: "Build a plugin from a git ref and install it unverified",
+		Long: `Build a plugin from a branch, tag, or commit of its repository with the
+dragonglass-build Dagger pipeline and install the result into the vault.
+
+Nothing about a dev build is verified: it has no provenance or SBOM
+attestation. The lockfile marks it as a dev build, so it is never listed as
+verified, install and sync leave it alone, and ci verify fails while it is
+pinned. Replace it with a released version by running dev uninstall and then
+add, or add --force.
+
+dragonglass-build must be in PATH (or given with --builder) and needs a
+container runtime for Dagger. A local directory can be built instead of a git
+URL.
+
+Example:
+  dragonglass dev install https://github.com/owner/repo#main --directory plugin
+  dragonglass dev install https://github.com/owner/repo#0123456789abcdef0123456789abcdef01234567
+  dragonglass dev install ../my-plugin`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := DevInstallOptions{}
+			opts.Directory, _ = cmd.Flags().GetString("directory")
+			opts.BuildDir, _ = cmd.Flags().GetString("build-dir")
+			opts.Builder, _ = cmd.Flags().GetString("builder")
+			opts.Force, _ = cmd.Flags().GetBool("force")
+			opts.UnfreezeOnce, _ = cmd.Flags().GetBool("unfreeze-once")
+
+			pluginID, err := DevInstall(cmd.Context(), ctx, args[0], opts)
+			if err != nil {
+				ctx.Fail("Dev install failed", err)
+			}
+
+			ctx.Logger.Warn("Installed an unverified dev build", ctx.Logger.Args("id", pluginID, "source", args[0]))
+		},
+	}
+
+	cmd.Flags().StringP("directory", "d", "", "Directory of the plugin within the repository (default: its root)")
+	cmd.Flags().String("build-dir", "", "Directory npm run build writes main.js to, relative to the plugin directory")
+	cmd.Flags().String("builder", DefaultBuilder, "Build tool to run")
+	cmd.Flags().BoolP("force", "f", false, "Replace a plugin installed from the registry")
+	cmd.Flags().Bool("unfreeze-once", false, "Allow this install even though the vault is frozen")
+	return cmd
+}
+
+func newDevUninstallCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "uninstall <plugin-id>",
+		Short: "Remove a dev build from the vault",
+		Long: `Remove a plugin installed with dev install from the vault and the lockfile.
+
+Plugins installed from the registry are refused; use remove for them. The
+plugin's settings (data.json) are kept unless --purge is given.
+
+Example:
+  dragonglass dev uninstall sample-plugin`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			purge, _ := cmd.Flags().GetBool("purge")
+			unfreezeOnce, _ := cmd.Flags().GetBool("unfreeze-once")
+
+			if err := DevUninstall(cmd.Context(), ctx, args[0], RemoveOptions{Purge: purge, UnfreezeOnce: unfreezeOnce}); err != nil {
+				ctx.Fail("Dev uninstall failed", err)
+			}
+
+			ctx.Logger.Info("Dev build removed successfully", ctx.Logger.Args("id", args[0]))
+		},
+	}
+
+	cmd.Flags().Bool("purge", false, "Also delete the plugin's settings (data.json)")
+	cmd.Flags().Bool("unfreeze-once", false, "Allow this removal even though the vault is frozen")
+	return cmd
+}
+
+// DevInstall builds the plugin at source ("<git-url>#<ref>" or a local directory) with
+// the build tool and installs it as an unverified dev build. It returns the plugin's ID.
+func DevInstall(opCtx context.Context, ctx *cmd.CommandContext, source string, opts DevInstallOptions) (string, error) {
+	builderPath, err := lookupBuilder(opts.Builder)
+	if err != nil {
+		return "", err
+	}
+
+	repository, ref := parseDevSource(source)
+	dev := lockfile.DevSource{Repository: repository, Ref: ref, Directory: opts.Directory}
+	return installDevBuild(opCtx, ctx, source, dev, opts, func(outputDir string) error {
+		ctx.Logger.Info("Building plugin from source", ctx.Logger.Args("repository", repository, "ref", ref, "directory", opts.Directory))
+		return runBuild(opCtx, exec.CommandContext(opCtx, builderPath, builderArgs(repository, ref, opts.Directory, opts.BuildDir, outputDir)...))
+	})
+}
+
+// installDevBuild runs build to write a built plugin into an output directory, then
+// installs it as a dev build of source recorded with dev. It returns the plugin's ID.
+func installDevBuild(opCtx context.Context, ctx *cmd.CommandContext, source string, dev lockfile.DevSource, opts DevInstallOptions, build func(outputDir string) error) (string, error) {
+	v, err := ctx.DiscoverVault()
+	if err != nil {
+		return "", fmt.Errorf("failed to find vault: %w", err)
+	}
+
+	// Installing the first dev build creates the lockfile
+	lockfilePath, err := ctx.ResolveNewLockfilePath()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+
+	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to load lockfile: %w", err)
+	}
+
+	if err := checkFrozen(ctx, lockfileData, opts.UnfreezeOnce); err != nil {
+		return "", err
+	}
+
+	// Best effort: a workspace that cannot be swept now is retried by the next install
+	_, _ = workspace.Sweep(v.TmpDir(), workspace.StaleAfter, time.Now())
+	ws, err := workspace.New(v.TmpDir())
+	if err != nil {
+		return "", err
+	}
+	defer ws.Remove()
+
+	outputDir := ws.Path("dist")
+	if err := os.Mkdir(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create build output directory: %w", err)
+	}
+	if err := build(outputDir); err != nil {
+		return "", fmt.Errorf("failed to build %s: %w", source, err)
+	}
+	dev.BuiltAt = time.Now().UTC()
+
+	dist, err := plugin.LoadDist(outputDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read build output: %w", err)
+	}
+	metadata := dist.Metadata
+	if metadata.ID == "" || metadata.Name == "" {
+		return "", fmt.Errorf("built %s has no id or name", plugin.ManifestFile)
+	}
+
+	t, err := newTarget(v, plugin.KindPlugin, metadata.ID, metadata.Name)
+	if err != nil {
+		return "", err
+	}
+
+	// A dev build never silently takes the place of a verified plugin
+	installed, managed := lockfileData.GetPlugin(metadata.ID)
+	switch {
+	case managed && !installed.IsDev() && !opts.Force:
+		return "", fmt.Errorf("plugin %s is installed from %s (use --force to replace it with a dev build)", metadata.ID, installed.OCIReference)
+	case !managed && t.installed() && !opts.Force:
+		return "", fmt.Errorf("plugin already installed: %s (use --force to overwrite)", makeRelativePath(t.path))
+	}
+
+	entry := lockfile.PluginEntry{
+		Name:         metadata.Name,
+		Version:      metadata.Version,
+		OCIReference: source,
+		Metadata: lockfile.PluginMetadata{
+			Author:      metadata.Author,
+			Description: metadata.Description,
+			Repository:  metadata.AuthorURL,
+		},
+		Disabled: installed.Disabled,
+		Dev:      &dev,
+	}
+
+	if err := preInstallHook(opCtx, ctx, metadata.ID, entry); err != nil {
+		return "", err
+	}
+
+	if err := writeSyncIgnore(ctx.Config, v); err != nil {
+		return "", err
+	}
+
+	files, err := installArtifact(opCtx, v.TmpDir(), t, ctx.Config.Install.WriteAttempts, func(stagingDir string) error {
+		for _, name := range []string{plugin.MainFile, plugin.ManifestFile, plugin.StylesFile} {
+			if err := copyBuildFile(outputDir, stagingDir, name); err != nil {
+				return err
+			}
+		}
+		if err := plugin.CheckArtifactHealth(plugin.KindPlugin, stagingDir, metadata.ID, metadata.Name).Err(); err != nil {
+			return fmt.Errorf("plugin failed health check: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	entry.InstalledAt = &dev.BuiltAt
+	entry.Files = files
+	entry.Integrity = lockfile.Integrity(files)
+	entry.OCIDigest = devDigest(entry.Integrity)
+	if err := updateLockfile(lockfileData, lockfilePath, metadata.ID, entry); err != nil {
+		return "", fmt.Errorf("failed to update lockfile: %w", err)
+	}
+
+	postHook(opCtx, ctx, hooks.PostInstall, metadata.ID, entry)
+	return metadata.ID, nil
+}
+
+// DevUninstall removes the dev build pluginID from the vault and the lockfile. Plugins
+// installed from the registry are refused.
+func DevUninstall(opCtx context.Context, ctx *cmd.CommandContext, pluginID string, opts RemoveOptions) error {
+	lockfilePath, err := ctx.ResolveLockfilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+
+	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load lockfile: %w", err)
+	}
+
+	entry, ok := lockfileData.GetPlugin(pluginID)
+	if !ok {
+		return dgerrors.Mark(fmt.Errorf("plugin %s not found in lockfile", pluginID), dgerrors.ErrNotInLockfile)
+	}
+	if !entry.IsDev() {
+		return fmt.Errorf("plugin %s is not a dev build (use remove to uninstall it)", pluginID)
+	}
+
+	return Remove(opCtx, ctx, pluginID, opts)
+}
+
+// parseDevSource splits "<git-url>#<ref>" into the repository and ref; ref is empty when
+// source names none
+func parseDevSource(source string) (repository, ref string) {
+	if i := strings.LastIndex(source, "#"); i >= 0 {
+		return source[:i], source[i+1:]
+	}
+	return source, ""
+}
+
+// builderArgs returns the dragonglass-build arguments building ref of repository into
+// outputDir. Full commit SHAs are checked out with --commit, other refs with --ref.
+func builderArgs(repository, ref, directory, buildDir, outputDir string) []string {
+	args := []string{repository, "--output-dir", outputDir}
+	switch {
+	case commitPattern.MatchString(ref):
+		args = append(args, "--commit", ref)
+	case ref != "":
+		args = append(args, "--ref", ref)
+	}
+	if directory != "" {
+		args = append(args, "--directory", directory)
+	}
+	if buildDir != "" {
+		args = append(args, "--build-dir", buildDir)
+	}
+	return args
+}
+
+// devDigest returns the digest recorded for a dev build in place of an OCI digest: the
+// integrity of its files as a hex sha256 digest
+func devDigest(integrity string) string {
+	sum, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(integrity, lockfile.IntegrityPrefix))
+	if err != nil {
+		return ""
+	}
+	return "sha256:" + hex.EncodeToString(sum)
+}
+
+// lookupBuilder returns the path of the build tool (DefaultBuilder when empty)
+func lookupBuilder(builder string) (string, error) {
+	if builder == "" {
+		builder = DefaultBuilder
+	}
+	path, err := exec.LookPath(builder)
+	if err != nil {
+		return "", fmt.Errorf("failed to find %s: install it to build plugins from source (it needs a container runtime for Dagger): %w", builder, err)
+	}
+	return path, nil
+}
+
+// runBuild runs a build command. Build tools report progress on stdout, which is kept free
+// for command output.
+func runBuild(opCtx context.Context, build *exec.Cmd) error {
+	build.Stdout = os.Stderr
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		if opCtx.Err() != nil {
+			return opCtx.Err()
+		}
+		return fmt.Errorf("%s failed: %w", filepath.Base(build.Path), err)
+	}
+	return nil
+}
+
+// copyBuildFile copies name from the directory a build wrote it to into dir; a missing
+// stylesheet is skipped
+func copyBuildFile(builtDir, dir, name string) error {
+	data, err := os.ReadFile(filepath.Join(builtDir, name))
+	if errors.Is(err, os.ErrNotExist) && name == plugin.StylesFile {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read built %s: %w", name, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		return fmt.Errorf("failed to copy %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package install
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+)
+
+func TestParseDevSource(t *testing.T) {
+	tests := []struct {
+		source     string
+		repository string
+		ref        string
+	}{
+		{source: "https://github.com/owner/repo#main", repository: "https://github.com/owner/repo", ref: "main"},
+		{source: "git@github.com:owner/repo.git#v1.2.0", repository: "git@github.com:owner/repo.git", ref: "v1.2.0"},
+		{source: "https://github.com/owner/repo", repository: "https://github.com/owner/repo"},
+		{source: "../my-plugin", repository: "../my-plugin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			repository, ref := parseDevSource(tt.source)
+			if repository != tt.repository || ref != tt.ref {
+				t.Errorf("expected %q, %q, got %q, %q", tt.repository, tt.ref, repository, ref)
+			}
+		})
+	}
+}
+
+func TestBuilderArgs(t *testing.T) {
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+	tests := []struct {
+		name      string
+		ref       string
+		directory string
+		buildDir  string
+		expected  []string
+	}{
+		{name: "branch", ref: "main", expected: []string{"repo", "--output-dir", "out", "--ref", "main"}},
+		{name: "commit", ref: sha, expected: []string{"repo", "--output-dir", "out", "--commit", sha}},
+		{name: "short sha is a ref", ref: "0123456", expected: []string{"repo", "--output-dir", "out", "--ref", "0123456"}},
+		{name: "no ref", expected: []string{"repo", "--output-dir", "out"}},
+		{
+			name:      "directory and build dir",
+			ref:       "main",
+			directory: "plugin",
+			buildDir:  "build",
+			expected:  []string{"repo", "--output-dir", "out", "--ref", "main", "--directory", "plugin", "--build-dir", "build"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := builderArgs("repo", tt.ref, tt.directory, tt.buildDir, "out"); !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDevDigest(t *testing.T) {
+	integrity := lockfile.Integrity(map[string]string{"main.js": "sha256:abc"})
+	digest := devDigest(integrity)
+	if len(digest) != len("sha256:")+64 || digest[:7] != "sha256:" {
+		t.Errorf("expected a hex sha256 digest, got %q", digest)
+	}
+	if devDigest(integrity) != digest {
+		t.Error("expected the digest to be stable")
+	}
+}
+
+func TestSourceSnapshot(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"main.ts", "src/view.ts", "node_modules/dep/index.js", ".git/HEAD"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	before, err := sourceSnapshot(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := slices.Sorted(maps.Keys(before)); !slices.Equal(got, []string{"main.ts", filepath.Join("src", "view.ts")}) {
+		t.Errorf("expected only sources to be watched, got %v", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "node_modules", "dep", "index.js"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := sourceSnapshot(root); !maps.Equal(before, after) {
+		t.Error("expected changes in node_modules to be ignored")
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "src", "view.ts"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := sourceSnapshot(root); maps.Equal(before, after) {
+		t.Error("expected a changed source to be detected")
+	}
+}
@@ -0,0 +1,247 @@
+// ABOUTME: Dev watch command rebuilding a local plugin whenever its sources change
+// ABOUTME: Polls the source tree, builds with Dagger or a local npm run build, and reinstalls the dev build
+package install
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+)
+
+const (
+	// DefaultWatchInterval is how often dev watch checks the sources for changes
+	DefaultWatchInterval = time.Second
+
+	// MinWatchInterval keeps dev watch from spinning on large source trees
+	MinWatchInterval = 100 * time.Millisecond
+)
+
+// DevWatchOptions controls the dev watch loop
+type DevWatchOptions struct {
+	DevInstallOptions
+
+	// Build with npm run build on this machine instead of the Dagger pipeline
+	Local bool
+
+	// How often the sources are checked for changes
+	Interval time.Duration
+}
+
+func newDevWatchCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watch <directory>",
+		Short: "Rebuild and reinstall a local plugin whenever its sources change",
+		Long: `Build the plugin in a local directory, install it into the vault as a dev
+build, then rebuild and reinstall it each time a source file changes, until
+interrupted.
+
+Builds run through the dragonglass-build Dagger pipeline, as with dev install.
+--local runs npm run build in the plugin directory instead, which is much
+faster but uses the Node.js installed on this machine; dependencies are
+installed first when node_modules is missing. Each build replaces the installed
+files at once, so a hot-reload plugin in Obsidian picks up every rebuild.
+
+Changes are detected by polling file modification times. node_modules and
+hidden directories are not watched, and a rebuild starts once the sources
+have stopped changing for one interval. A failed build is logged and the
+previous build stays installed.
+
+Example:
+  dragonglass dev watch ./my-plugin
+  dragonglass dev watch . --directory plugin --local`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := DevWatchOptions{}
+			opts.Directory, _ = cmd.Flags().GetString("directory")
+			opts.BuildDir, _ = cmd.Flags().GetString("build-dir")
+			opts.Builder, _ = cmd.Flags().GetString("builder")
+			opts.Force, _ = cmd.Flags().GetBool("force")
+			opts.UnfreezeOnce, _ = cmd.Flags().GetBool("unfreeze-once")
+			opts.Local, _ = cmd.Flags().GetBool("local")
+			opts.Interval, _ = cmd.Flags().GetDuration("interval")
+
+			if err := DevWatch(cmd.Context(), ctx, args[0], opts); err != nil {
+				ctx.Fail("Dev watch failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().StringP("directory", "d", "", "Directory of the plugin within the source directory (default: its root)")
+	cmd.Flags().String("build-dir", "", "Directory npm run build writes main.js to, relative to the plugin directory")
+	cmd.Flags().String("builder", DefaultBuilder, "Build tool to run (without --local)")
+	cmd.Flags().Bool("local", false, "Build with npm run build on this machine instead of the Dagger pipeline")
+	cmd.Flags().Duration("interval", DefaultWatchInterval, "How often to check the sources for changes")
+	cmd.Flags().BoolP("force", "f", false, "Replace a plugin installed from the registry")
+	cmd.Flags().Bool("unfreeze-once", false, "Allow installs even though the vault is frozen")
+	return cmd
+}
+
+// DevWatch installs a dev build of the plugin in dir and rebuilds it whenever its sources
+// change, until opCtx is cancelled
+func DevWatch(opCtx context.Context, ctx *cmd.CommandContext, dir string, opts DevWatchOptions) error {
+	if opts.Interval < MinWatchInterval {
+		return fmt.Errorf("interval %s is shorter than the minimum of %s", opts.Interval, MinWatchInterval)
+	}
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	pluginDir := filepath.Join(root, opts.Directory)
+
+	var build func(outputDir string) error
+	if opts.Local {
+		build = func(outputDir string) error {
+			return localBuild(opCtx, ctx, pluginDir, opts.BuildDir, outputDir)
+		}
+	} else {
+		builderPath, err := lookupBuilder(opts.Builder)
+		if err != nil {
+			return err
+		}
+		build = func(outputDir string) error {
+			return runBuild(opCtx, exec.CommandContext(opCtx, builderPath, builderArgs(root, "", opts.Directory, opts.BuildDir, outputDir)...))
+		}
+	}
+
+	dev := lockfile.DevSource{Repository: root, Directory: opts.Directory}
+	rebuild := func() {
+		started := time.Now()
+		ctx.Logger.Info("Building plugin", ctx.Logger.Args("directory", pluginDir, "local", opts.Local))
+		pluginID, err := installDevBuild(opCtx, ctx, root, dev, opts.DevInstallOptions, build)
+		switch {
+		case opCtx.Err() != nil:
+		case err != nil:
+			ctx.Logger.Error("Build failed, keeping the previous build installed", ctx.Logger.Args("error", err))
+		default:
+			ctx.Logger.Info("Dev build installed", ctx.Logger.Args("id", pluginID, "took", time.Since(started).Round(time.Millisecond)))
+		}
+	}
+
+	rebuild()
+	last, err := sourceSnapshot(pluginDir)
+	if err != nil {
+		return err
+	}
+	ctx.Logger.Info("Watching for changes", ctx.Logger.Args("directory", pluginDir, "interval", opts.Interval))
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	pending := false
+	for {
+		select {
+		case <-opCtx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := sourceSnapshot(pluginDir)
+		if err != nil {
+			return err
+		}
+		if !maps.Equal(current, last) {
+			// Wait for the sources to settle, e.g. while an editor saves several files
+			ctx.Logger.Debug("Sources changed", ctx.Logger.Args("directory", pluginDir))
+			last, pending = current, true
+			continue
+		}
+		if !pending {
+			continue
+		}
+
+		rebuild()
+		pending = false
+		// Files the build itself wrote are not changes to rebuild for
+		if last, err = sourceSnapshot(pluginDir); err != nil {
+			return err
+		}
+	}
+}
+
+// fileState is what a poll compares to detect that a file changed
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// sourceSnapshot returns the state of every file under root, keyed by path relative to
+// it. node_modules and hidden directories are skipped.
+func sourceSnapshot(root string) (map[string]fileState, error) {
+	snapshot := make(map[string]fileState)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// A file removed while walking is picked up by the next poll
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path != root && (d.Name() == "node_modules" || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		snapshot[rel] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+	return snapshot, nil
+}
+
+// localBuild runs npm run build in pluginDir, installing dependencies first when
+// node_modules is missing, and copies the built plugin into outputDir
+func localBuild(opCtx context.Context, ctx *cmd.CommandContext, pluginDir, buildDir, outputDir string) error {
+	if _, err := os.Stat(filepath.Join(pluginDir, "node_modules")); errors.Is(err, os.ErrNotExist) {
+		ctx.Logger.Info("Installing dependencies", ctx.Logger.Args("directory", pluginDir))
+		install := exec.CommandContext(opCtx, "npm", "install")
+		install.Dir = pluginDir
+		if err := runBuild(opCtx, install); err != nil {
+			return err
+		}
+	}
+
+	build := exec.CommandContext(opCtx, "npm", "run", "build")
+	build.Dir = pluginDir
+	if err := runBuild(opCtx, build); err != nil {
+		return err
+	}
+
+	builtDir := filepath.Join(pluginDir, buildDir)
+	for _, file := range []struct{ dir, name string }{
+		{builtDir, plugin.MainFile},
+		{pluginDir, plugin.ManifestFile},
+		{builtDir, plugin.StylesFile},
+	} {
+		if err := copyBuildFile(file.dir, outputDir, file.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
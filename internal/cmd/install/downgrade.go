@@ -0,0 +1,48 @@
+// ABOUTME: Downgrade protection for plugins replaced by add, install, and sync
+// ABOUTME: Refuses lower versions and older builds unless allowed, recording each decision
+package install
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/audit"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+// checkDowngrade refuses to replace installed with next when that rolls the plugin back
+// (see lockfile.Downgrade), unless cmdCtx.AllowDowngrade is set. Refused and allowed
+// downgrades are recorded in the vault's audit log.
+func checkDowngrade(cmdCtx *cmd.CommandContext, v *vault.Vault, pluginID string, installed, next lockfile.PluginEntry) error {
+	reason := lockfile.Downgrade(installed, next)
+	if reason == "" {
+		return nil
+	}
+
+	event := audit.Event{
+		Time:        time.Now().UTC(),
+		Action:      audit.ActionDowngrade,
+		Decision:    audit.DecisionRefused,
+		PluginID:    pluginID,
+		FromVersion: installed.Version,
+		FromDigest:  installed.OCIDigest,
+		ToVersion:   next.Version,
+		ToDigest:    next.OCIDigest,
+		Reason:      reason,
+	}
+	if cmdCtx.AllowDowngrade {
+		event.Decision = audit.DecisionAllowed
+	}
+	if err := audit.Append(v.AuditLogPath(), event); err != nil {
+		return err
+	}
+
+	if !cmdCtx.AllowDowngrade {
+		return dgerrors.Mark(fmt.Errorf("refusing to downgrade %s: %s", pluginID, reason), dgerrors.ErrDowngrade)
+	}
+	cmdCtx.Logger.Warn("Downgrading plugin", cmdCtx.Logger.Args("plugin", pluginID, "reason", reason))
+	return nil
+}
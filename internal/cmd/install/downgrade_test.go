@@ -0,0 +1,64 @@
+package install
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/audit"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+func TestCheckDowngrade(t *testing.T) {
+	installed := lockfile.PluginEntry{Version: "1.2.0", OCIDigest: "sha256:installed"}
+
+	tests := []struct {
+		name           string
+		next           string
+		allow          bool
+		expectRefused  bool
+		expectDecision string
+	}{
+		{name: "upgrade", next: "1.3.0"},
+		{name: "downgrade refused", next: "1.1.0", expectRefused: true, expectDecision: audit.DecisionRefused},
+		{name: "downgrade allowed", next: "1.1.0", allow: true, expectDecision: audit.DecisionAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := vault.New(t.TempDir())
+			ctx := &cmd.CommandContext{
+				Logger:         pterm.DefaultLogger.WithWriter(os.Stderr).WithLevel(pterm.LogLevelError),
+				AllowDowngrade: tt.allow,
+			}
+			next := lockfile.PluginEntry{Version: tt.next, OCIDigest: "sha256:next"}
+
+			err := checkDowngrade(ctx, v, "tasknotes", installed, next)
+			if tt.expectRefused != errors.Is(err, dgerrors.ErrDowngrade) {
+				t.Fatalf("expected refused=%v, got %v", tt.expectRefused, err)
+			}
+			if !tt.expectRefused && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			events, err := audit.Load(v.AuditLogPath())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.expectDecision == "" {
+				if len(events) != 0 {
+					t.Errorf("expected no audit events, got %+v", events)
+				}
+				return
+			}
+			if len(events) != 1 || events[0].Decision != tt.expectDecision || events[0].FromVersion != "1.2.0" || events[0].ToDigest != "sha256:next" {
+				t.Errorf("expected one %s event, got %+v", tt.expectDecision, events)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// ABOUTME: Enable and disable commands switching installed plugins on and off in Obsidian
+// ABOUTME: Edits community-plugins.json and records the state in the lockfile, keeping the files
+package install
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+)
+
+func NewEnableCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enable <plugin-id>",
+		Short: "Switch an installed plugin back on in Obsidian",
+		Long: `Enable a plugin installed by dragonglass that was switched off with
+dragonglass disable. The plugin is added to .obsidian/community-plugins.json and
+the lockfile no longer records it as disabled. Nothing is downloaded or verified
+again. Reload the vault in Obsidian to load the plugin.
+
+Example:
+  dragonglass enable sample-plugin`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := SetEnabled(ctx, args[0], true); err != nil {
+				ctx.Fail("Enable failed", err)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func NewDisableCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disable <plugin-id>",
+		Short: "Switch an installed plugin off without removing it",
+		Long: `Disable a plugin installed by dragonglass while keeping it installed and
+verified. The plugin is removed from .obsidian/community-plugins.json, so Obsidian
+stops loading it, and the lockfile records it as disabled. Its files, settings, and
+pinned digest are kept, and dragonglass enable switches it back on. Reload the vault
+in Obsidian to unload the plugin.
+
+Example:
+  dragonglass disable sample-plugin`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := SetEnabled(ctx, args[0], false); err != nil {
+				ctx.Fail("Disable failed", err)
+			}
+		},
+	}
+
+	return cmd
+}
+
+// SetEnabled switches pluginID on or off in Obsidian's community-plugins.json and records
+// the state in the lockfile. Only plugins in the lockfile can be switched, and only
+// installed ones enabled.
+func SetEnabled(ctx *cmd.CommandContext, pluginID string, enabled bool) error {
+	v, err := ctx.DiscoverVault()
+	if err != nil {
+		return fmt.Errorf("failed to find vault: %w", err)
+	}
+
+	lockfilePath, err := ctx.ResolveLockfilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+
+	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load lockfile: %w", err)
+	}
+
+	entry, ok := lockfileData.GetPlugin(pluginID)
+	if !ok {
+		return dgerrors.Mark(fmt.Errorf("plugin %s not found in lockfile", pluginID), dgerrors.ErrNotInLockfile)
+	}
+	if entry.Kind != "" && entry.Kind != plugin.KindPlugin {
+		return fmt.Errorf("%s is a %s; themes and snippets are switched on in Obsidian's appearance settings", pluginID, entry.Kind)
+	}
+	if enabled && !pluginInstalled(v.PluginDir(pluginID)) {
+		return fmt.Errorf("plugin %s is not installed (run 'dragonglass install' first)", pluginID)
+	}
+
+	changed, err := v.SetPluginEnabled(pluginID, enabled)
+	if err != nil {
+		return err
+	}
+
+	tx := lockfileData.Begin(lockfilePath)
+	defer tx.Rollback()
+	if err := tx.SetPluginDisabled(pluginID, !enabled); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save lockfile: %w", err)
+	}
+
+	state := "disabled"
+	if enabled {
+		state = "enabled"
+	}
+	if changed {
+		ctx.Logger.Info("Plugin "+state+"; reload the vault in Obsidian to apply", ctx.Logger.Args("id", pluginID))
+	} else {
+		ctx.Logger.Info("Plugin already "+state, ctx.Logger.Args("id", pluginID))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package install
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+func TestSetEnabled(t *testing.T) {
+	v := vault.New(t.TempDir())
+	pluginDir := v.PluginDir("test-plugin")
+	if err := os.MkdirAll(pluginDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pluginDir, "main.js"), []byte("module.exports = {};"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.SetPluginEnabled("other-plugin", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.SetPluginEnabled("test-plugin", true); err != nil {
+		t.Fatal(err)
+	}
+
+	lockfileData := lockfile.NewLockfile(v.Root)
+	for _, id := range []string{"test-plugin", "missing-plugin"} {
+		if err := lockfileData.AddPlugin(id, lockfile.PluginEntry{
+			Name:         id,
+			OCIReference: "ghcr.io/owner/" + id + ":1.0.0",
+			OCIDigest:    "sha256:abc",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lockfile.SaveLockfile(lockfileData, v.LockfilePath()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &cmd.CommandContext{
+		VaultDir:     v.Root,
+		LockfilePath: v.LockfilePath(),
+		Logger:       pterm.DefaultLogger.WithWriter(os.Stderr).WithLevel(pterm.LogLevelError),
+	}
+
+	steps := []struct {
+		id           string
+		enabled      bool
+		wantErr      error
+		wantEnabled  []string
+		wantDisabled bool
+	}{
+		{id: "unlocked-plugin", enabled: false, wantErr: dgerrors.ErrNotInLockfile, wantEnabled: []string{"other-plugin", "test-plugin"}},
+		{id: "test-plugin", enabled: false, wantEnabled: []string{"other-plugin"}, wantDisabled: true},
+		{id: "test-plugin", enabled: false, wantEnabled: []string{"other-plugin"}, wantDisabled: true},
+		{id: "test-plugin", enabled: true, wantEnabled: []string{"other-plugin", "test-plugin"}},
+	}
+
+	for _, step := range steps {
+		err := SetEnabled(ctx, step.id, step.enabled)
+		if step.wantErr != nil {
+			if !errors.Is(err, step.wantErr) {
+				t.Fatalf("%s enabled=%v: expected %v, got %v", step.id, step.enabled, step.wantErr, err)
+			}
+		} else if err != nil {
+			t.Fatalf("%s enabled=%v: unexpected error: %v", step.id, step.enabled, err)
+		}
+
+		enabled, err := v.EnabledPlugins()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(enabled, step.wantEnabled) {
+			t.Errorf("%s enabled=%v: expected enabled plugins %v, got %v", step.id, step.enabled, step.wantEnabled, enabled)
+		}
+		saved, err := lockfile.LoadLockfile(v.LockfilePath())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry, _ := saved.GetPlugin("test-plugin"); entry.Disabled != step.wantDisabled {
+			t.Errorf("%s enabled=%v: expected disabled=%v in the lockfile", step.id, step.enabled, step.wantDisabled)
+		}
+	}
+
+	// Files are kept while disabled
+	if _, err := os.Stat(filepath.Join(pluginDir, "main.js")); err != nil {
+		t.Errorf("expected plugin files to be kept, got %v", err)
+	}
+
+	// A plugin that is locked but not on disk cannot be enabled
+	if err := SetEnabled(ctx, "missing-plugin", true); err == nil {
+		t.Error("expected enabling a plugin that is not installed to fail")
+	}
+}
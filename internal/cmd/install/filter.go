@@ -0,0 +1,103 @@
+// ABOUTME: Plugin ID filters for installing a subset of the lockfile
+// ABOUTME: Matches IDs against --only and --exclude glob patterns
+package install
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/manifest"
+)
+
+// PluginFilter selects plugins by ID. Patterns use glob syntax: * matches any run of
+// characters, ? one character, and [...] a character class.
+type PluginFilter struct {
+	// Plugins to include; empty includes every plugin
+	Only []string
+
+	// Plugins to leave out, even when Only includes them
+	Exclude []string
+}
+
+// addFilterFlags registers --only and --exclude on command
+func addFilterFlags(command *cobra.Command) {
+	command.Flags().StringSlice("only", nil, "Install only plugins whose ID matches one of these glob patterns (repeatable)")
+	command.Flags().StringSlice("exclude", nil, "Skip plugins whose ID matches one of these glob patterns (repeatable)")
+}
+
+// filterFromFlags reads the filter from the flags addFilterFlags registered
+func filterFromFlags(command *cobra.Command) (PluginFilter, error) {
+	only, _ := command.Flags().GetStringSlice("only")
+	exclude, _ := command.Flags().GetStringSlice("exclude")
+	filter := PluginFilter{Only: only, Exclude: exclude}
+	return filter, filter.Validate()
+}
+
+// Validate rejects malformed patterns
+func (f PluginFilter) Validate() error {
+	for _, patterns := range []struct {
+		flag     string
+		patterns []string
+	}{{"only", f.Only}, {"exclude", f.Exclude}} {
+		for _, pattern := range patterns.patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid --%s pattern %q: %w", patterns.flag, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Empty reports whether the filter selects every plugin
+func (f PluginFilter) Empty() bool {
+	return len(f.Only) == 0 && len(f.Exclude) == 0
+}
+
+// Matches reports whether the filter selects pluginID
+func (f PluginFilter) Matches(pluginID string) bool {
+	if len(f.Only) > 0 && !matchAny(f.Only, pluginID) {
+		return false
+	}
+	return !matchAny(f.Exclude, pluginID)
+}
+
+// Unmatched returns the --only patterns that select none of ids, which usually means a
+// misspelled plugin ID
+func (f PluginFilter) Unmatched(ids []string) []string {
+	var unmatched []string
+	for _, pattern := range f.Only {
+		if !matchAny([]string{pattern}, ids...) {
+			unmatched = append(unmatched, pattern)
+		}
+	}
+	return unmatched
+}
+
+// manifest returns a copy of m declaring only the plugins the filter selects
+func (f PluginFilter) manifest(m *manifest.Manifest) *manifest.Manifest {
+	if m == nil || f.Empty() {
+		return m
+	}
+	filtered := &manifest.Manifest{Version: m.Version, Plugins: make(map[string]manifest.PluginSpec, len(m.Plugins))}
+	for pluginID, spec := range m.Plugins {
+		if f.Matches(pluginID) {
+			filtered.Plugins[pluginID] = spec
+		}
+	}
+	return filtered
+}
+
+// matchAny reports whether any of patterns matches any of ids. Patterns were checked by
+// Validate, so match errors cannot occur.
+func matchAny(patterns []string, ids ...string) bool {
+	for _, pattern := range patterns {
+		for _, id := range ids {
+			if ok, _ := path.Match(pattern, id); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
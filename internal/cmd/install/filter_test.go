@@ -0,0 +1,90 @@
+package install
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/manifest"
+)
+
+func TestPluginFilterMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  PluginFilter
+		matches []string
+		skips   []string
+	}{
+		{name: "empty", matches: []string{"dataview", "calendar"}},
+		{
+			name:    "only",
+			filter:  PluginFilter{Only: []string{"dataview", "cal*"}},
+			matches: []string{"dataview", "calendar"},
+			skips:   []string{"excalidraw"},
+		},
+		{
+			name:    "exclude",
+			filter:  PluginFilter{Exclude: []string{"heavy-*"}},
+			matches: []string{"dataview"},
+			skips:   []string{"heavy-graph"},
+		},
+		{
+			name:    "exclude wins over only",
+			filter:  PluginFilter{Only: []string{"*"}, Exclude: []string{"calendar"}},
+			matches: []string{"dataview"},
+			skips:   []string{"calendar"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, id := range tt.matches {
+				if !tt.filter.Matches(id) {
+					t.Errorf("expected %s to match", id)
+				}
+			}
+			for _, id := range tt.skips {
+				if tt.filter.Matches(id) {
+					t.Errorf("expected %s to be filtered out", id)
+				}
+			}
+		})
+	}
+}
+
+func TestPluginFilterValidate(t *testing.T) {
+	if err := (PluginFilter{Only: []string{"data*"}, Exclude: []string{"[ab]?"}}).Validate(); err != nil {
+		t.Errorf("expected valid patterns, got %v", err)
+	}
+	err := PluginFilter{Exclude: []string{"[abc"}}.Validate()
+	if err == nil || !strings.Contains(err.Error(), `invalid --exclude pattern "[abc"`) {
+		t.Errorf("expected invalid --exclude pattern error, got %v", err)
+	}
+}
+
+func TestPluginFilterUnmatched(t *testing.T) {
+	filter := PluginFilter{Only: []string{"dataview", "calender", "cal*"}}
+	got := filter.Unmatched([]string{"dataview", "calendar"})
+	if !slices.Equal(got, []string{"calender"}) {
+		t.Errorf("expected [calender], got %v", got)
+	}
+}
+
+func TestPluginFilterManifest(t *testing.T) {
+	m := &manifest.Manifest{Version: "1", Plugins: map[string]manifest.PluginSpec{
+		"dataview":    {Repository: "ghcr.io/example/dataview", Version: "^1.0.0"},
+		"heavy-graph": {Repository: "ghcr.io/example/heavy-graph", Version: "^2.0.0"},
+	}}
+
+	if got := (PluginFilter{}).manifest(m); got != m {
+		t.Error("expected an empty filter to return the manifest unchanged")
+	}
+
+	got := PluginFilter{Exclude: []string{"heavy-*"}}.manifest(m)
+	if len(got.Plugins) != 1 || got.Plugins["dataview"].Version != "^1.0.0" || got.Version != "1" {
+		t.Errorf("expected only dataview, got %+v", got)
+	}
+	if len(m.Plugins) != 2 {
+		t.Error("expected the original manifest to be left alone")
+	}
+}
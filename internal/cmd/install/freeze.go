@@ -0,0 +1,26 @@
+// ABOUTME: Freeze guard for commands that change which plugins a vault pins
+// ABOUTME: Frozen vaults refuse add, remove, and sync unless the change is allowed once
+package install
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+)
+
+// checkFrozen refuses to change a frozen vault's plugins unless unfreezeOnce is set
+func checkFrozen(ctx *cmd.CommandContext, lockfileData *lockfile.Lockfile, unfreezeOnce bool) error {
+	if !lockfileData.Frozen() {
+		return nil
+	}
+
+	frozenAt := lockfileData.Metadata.FrozenAt.Format(time.RFC3339)
+	if unfreezeOnce {
+		ctx.Logger.Warn("Vault is frozen, allowing this change once (--unfreeze-once)", ctx.Logger.Args("frozenAt", frozenAt))
+		return nil
+	}
+	return fmt.Errorf("%w since %s", dgerrors.ErrVaultFrozen, frozenAt)
+}
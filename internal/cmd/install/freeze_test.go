@@ -0,0 +1,50 @@
+package install
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+)
+
+func TestCheckFrozen(t *testing.T) {
+	ctx := &cmd.CommandContext{Logger: pterm.DefaultLogger.WithWriter(os.Stderr).WithLevel(pterm.LogLevelError)}
+
+	frozen := lockfile.NewLockfile("/vault")
+	frozen.Freeze(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	tests := []struct {
+		name         string
+		lockfile     *lockfile.Lockfile
+		unfreezeOnce bool
+		expectFrozen bool
+	}{
+		{name: "not frozen", lockfile: lockfile.NewLockfile("/vault")},
+		{name: "frozen", lockfile: frozen, expectFrozen: true},
+		{name: "frozen, unfrozen once", lockfile: frozen, unfreezeOnce: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkFrozen(ctx, tt.lockfile, tt.unfreezeOnce)
+			if tt.expectFrozen {
+				if !errors.Is(err, dgerrors.ErrVaultFrozen) {
+					t.Fatalf("expected ErrVaultFrozen, got %v", err)
+				}
+				if err.Error() != "vault is frozen since 2026-01-02T03:04:05Z" {
+					t.Errorf("unexpected message %q", err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
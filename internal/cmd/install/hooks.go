@@ -0,0 +1,51 @@
+// ABOUTME: Runs the configured hooks around plugin installs and removals in the vault
+// ABOUTME: A failing pre_install hook blocks the install; post hook failures are warnings
+package install
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/hooks"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+)
+
+// preInstallHook runs the pre_install hooks for entry before its files are written. A
+// failing hook rejects the install.
+func preInstallHook(ctx context.Context, cmdCtx *cmd.CommandContext, pluginID string, entry lockfile.PluginEntry) error {
+	err := runHook(ctx, cmdCtx, hooks.PreInstall, pluginID, entry)
+
+	var hookErr *hooks.HookError
+	if errors.As(err, &hookErr) {
+		return dgerrors.Mark(err, dgerrors.ErrHookRejected)
+	}
+	return err
+}
+
+// postHook runs hook name after the change it reports has been made, so a failure is
+// logged rather than returned
+func postHook(ctx context.Context, cmdCtx *cmd.CommandContext, name, pluginID string, entry lockfile.PluginEntry) {
+	if err := runHook(ctx, cmdCtx, name, pluginID, entry); err != nil {
+		cmdCtx.Logger.Warn("Hook failed", cmdCtx.Logger.Args("hook", name, "id", pluginID, "error", err))
+	}
+}
+
+func runHook(ctx context.Context, cmdCtx *cmd.CommandContext, name, pluginID string, entry lockfile.PluginEntry) error {
+	commands := cmdCtx.Config.Hooks.Commands(name)
+	if len(commands) == 0 {
+		return nil
+	}
+
+	v, err := cmdCtx.DiscoverVault()
+	if err != nil {
+		return fmt.Errorf("failed to find vault: %w", err)
+	}
+
+	cmdCtx.Logger.Debug("Running hooks", cmdCtx.Logger.Args("hook", name, "id", pluginID, "count", len(commands)))
+	event := hooks.NewEvent(name, v.Root, pluginID, entry, time.Now().UTC())
+	return hooks.Run(ctx, commands, event, hooks.DefaultRunnerOpts())
+}
@@ -5,9 +5,12 @@ package install
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,12 +19,22 @@ import (
 
 	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
 	"github.com/gillisandrew/dragonglass-poc/internal/auth"
+	"github.com/gillisandrew/dragonglass-poc/internal/catalog"
 	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/search"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/verify"
 	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/hooks"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
 	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/manifest"
+	"github.com/gillisandrew/dragonglass-poc/internal/metrics"
 	"github.com/gillisandrew/dragonglass-poc/internal/oci"
 	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
 	"github.com/gillisandrew/dragonglass-poc/internal/registry"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+	"github.com/gillisandrew/dragonglass-poc/internal/warnings"
 )
 
 func NewInstallCommand(ctx *cmd.CommandContext) *cobra.Command {
@@ -32,17 +45,57 @@ func NewInstallCommand(ctx *cmd.CommandContext) *cobra.Command {
 This command reads the dragonglass-lock.json file and installs all plugins listed,
 verifying their integrity against the stored digests.
 
+If the vault contains a dragonglass.json manifest, version ranges declared there
+(e.g. "^1.2.0") are resolved first: the highest verified version within range is
+installed and pinned in the lockfile. Plugins already locked to a version inside
+their declared range are left untouched.
+
+If a locked tag has been re-pushed and now resolves to a different digest, the
+install fails. Pass --accept-retag to verify the new artifact and pin its digest.
+
+--force and --accept-retag ask for confirmation first; pass --yes to confirm without
+a prompt.
+
+A lockfile signed with 'dragonglass lock sign' is checked before anything is
+installed. With verification.require_signed_lockfile set, unsigned or changed
+lockfiles are rejected and the manifest is not resolved.
+
+With --warnings-as-errors, verification warnings about plugins resolved from the
+manifest or re-verified with --accept-retag fail the install with exit code 7.
+
+Replacing a locked plugin with a lower version, or with an older build of the same
+version, is refused unless --allow-downgrade is given; either way the decision is
+recorded in .dragonglass/audit.jsonl.
+
+--only and --exclude install a subset of the plugins, selected by ID with glob
+patterns (*, ?, [...]); both are repeatable or comma-separated, and --exclude wins.
+Plugins left out are neither installed nor checked, and the lockfile keeps them.
+
 Example:
   dragonglass install
-  dragonglass install --force`,
+  dragonglass install --force
+  dragonglass install --accept-retag
+  dragonglass install --only dataview,calendar
+  dragonglass install --exclude 'excalidraw*'
+  dragonglass install --warnings-as-errors --ignore-warning metadata`,
 		Args: cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			force, _ := cmd.Flags().GetBool("force")
+			acceptRetag, _ := cmd.Flags().GetBool("accept-retag")
+			policy, err := warnings.PolicyFromFlags(cmd)
+			if err != nil {
+				ctx.Fail("Install failed", err)
+			}
+			ctx.WarningPolicy = policy
+			ctx.AllowDowngrade, _ = cmd.Flags().GetBool("allow-downgrade")
+			filter, err := filterFromFlags(cmd)
+			if err != nil {
+				ctx.Fail("Install failed", err)
+			}
 			ctx.Logger.Info("Installing plugins from lockfile")
 
-			if err := runInstallFromLockfile(ctx, force); err != nil {
-				ctx.Logger.Error("Install failed", ctx.Logger.Args("error", err))
-				os.Exit(1)
+			if err := runInstallFromLockfile(cmd.Context(), ctx, force, acceptRetag, filter); err != nil {
+				ctx.Fail("Install failed", err)
 			}
 
 			ctx.Logger.Info("All plugins installed successfully")
@@ -50,29 +103,75 @@ Example:
 	}
 
 	cmd.Flags().BoolP("force", "f", false, "Overwrite existing plugin files if they exist")
+	cmd.Flags().Bool("accept-retag", false, "Verify and adopt the new digest of locked tags that were re-pushed")
+	cmd.Flags().Bool("allow-downgrade", false, "Allow replacing plugins with lower versions or older builds")
+	addFilterFlags(cmd)
+	warnings.AddFlags(cmd)
 	return cmd
 }
 
 func NewAddCommand(ctx *cmd.CommandContext) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "add [OCI_IMAGE_REFERENCE]",
+		Use:   "add [OCI_IMAGE_REFERENCE | NAME[@VERSION]]",
 		Short: "Add a verified plugin from OCI registry",
 		Long: `Add a verified Obsidian plugin from an OCI registry.
 The plugin will be downloaded, verified for provenance and vulnerabilities,
 and installed to the .obsidian/plugins/ directory.
 
+A plugin ID or GitHub repository (owner/repo) instead of an image reference is
+looked up in the plugin catalog (see dragonglass search) and added from the
+digest of its newest release, or of @VERSION. An entry whose OCI repository is
+owned by neither the upstream repository's owner nor the index, or whose release
+was built from another repository, is reported and needs confirming; strict mode
+refuses it. A release whose verified provenance names another repository than the
+catalog entry is always refused.
+
 Example:
   dragonglass add ghcr.io/owner/repo:plugin-name-v1.0.0
-  dragonglass add --force ghcr.io/owner/repo:plugin-name-v1.0.0`,
+  dragonglass add --force ghcr.io/owner/repo:plugin-name-v1.0.0
+  dragonglass add dataview@0.5.70
+
+Frozen vaults (see dragonglass freeze) refuse new plugins unless
+--unfreeze-once is given.
+
+Replacing an installed plugin with --force, or a plugin dragonglass did not install,
+and installing despite verification warnings ask for confirmation first; pass --yes
+to confirm without a prompt.
+
+With --warnings-as-errors, verification warnings (e.g. a missing SBOM) fail the
+command with exit code 7 before anything is installed.
+
+Mutable tags such as latest, main, or nightly make the vault irreproducible and
+are refused unless --allow-mutable-tag is given. The lockfile then pins the
+digest the tag resolves to now; install restores that digest, and sync verifies
+and pins whatever the tag points to later.
+
+Adding a plugin that is already locked at a higher version, or from a newer build of
+the same version, is refused unless --allow-downgrade is given. A higher version
+built before the installed one is not a downgrade. Refused and allowed downgrades
+are recorded in .dragonglass/audit.jsonl.`,
 		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			imageRef := args[0]
 			force, _ := cmd.Flags().GetBool("force")
+			unfreezeOnce, _ := cmd.Flags().GetBool("unfreeze-once")
+			allowMutable, _ := cmd.Flags().GetBool("allow-mutable-tag")
+			policy, err := warnings.PolicyFromFlags(cmd)
+			if err != nil {
+				ctx.Fail("Add failed", err)
+			}
+			ctx.WarningPolicy = policy
+			ctx.AllowDowngrade, _ = cmd.Flags().GetBool("allow-downgrade")
+			var catalogEntry *catalog.Plugin
+			if name, version, ok := catalog.ParseName(imageRef); ok {
+				if imageRef, catalogEntry, err = resolveCatalogName(cmd.Context(), ctx, name, version); err != nil {
+					ctx.Fail("Add failed", err)
+				}
+			}
 			ctx.Logger.Info("Adding plugin", ctx.Logger.Args("imageRef", imageRef))
 
-			if err := runAddCommand(imageRef, ctx, force); err != nil {
-				ctx.Logger.Error("Add failed", ctx.Logger.Args("error", err))
-				os.Exit(1)
+			if err := runAddCommand(cmd.Context(), imageRef, ctx, force, unfreezeOnce, allowMutable, catalogEntry); err != nil {
+				ctx.Fail("Add failed", err)
 			}
 
 			ctx.Logger.Info("Plugin added successfully")
@@ -80,42 +179,181 @@ Example:
 	}
 
 	cmd.Flags().BoolP("force", "f", false, "Overwrite existing plugin files if they exist")
+	cmd.Flags().Bool("unfreeze-once", false, "Allow this change even though the vault is frozen")
+	cmd.Flags().Bool("allow-mutable-tag", false, "Allow a tag such as latest that is re-pushed, pinning the digest it resolves to now")
+	cmd.Flags().Bool("allow-downgrade", false, "Allow replacing an installed plugin with a lower version or older build")
+	warnings.AddFlags(cmd)
 	return cmd
 }
 
-func runAddCommand(imageRef string, ctx *cmd.CommandContext, force bool) error {
-	// Find dragonglass directory and set proper lockfile path
-	dragonglassDir, err := findDragonglassDirectory()
+// resolveCatalogName returns the digest reference of the named plugin's release of version,
+// or its newest release when version is empty, and the plugin's catalog entry
+func resolveCatalogName(opCtx context.Context, ctx *cmd.CommandContext, name, version string) (string, *catalog.Plugin, error) {
+	loaded, err := search.LoadCatalog(opCtx, ctx, false)
+	if err != nil {
+		return "", nil, err
+	}
+	p, err := loaded.Lookup(name)
+	if err != nil {
+		return "", nil, err
+	}
+	release, err := p.Release(version)
 	if err != nil {
-		return fmt.Errorf("failed to find dragonglass directory: %w", err)
+		return "", nil, err
 	}
+	ctx.Logger.Info("Resolved plugin from catalog", ctx.Logger.Args("name", name, "id", p.ID, "version", release.Version, "digest", release.Digest))
+
+	// A short name is only as trustworthy as the catalog entry behind it, so an entry whose
+	// publisher does not match its upstream repository is refused in strict mode and
+	// otherwise needs confirming
+	ownership := loaded.CheckOwnership(p, release)
+	if !ownership.Verified() {
+		for _, problem := range ownership.Problems {
+			ctx.Logger.Warn("Catalog entry may not belong to its upstream repository", ctx.Logger.Args("id", p.ID, "repository", p.Repository, "problem", problem))
+		}
+		if ctx.Config.Verification.StrictMode {
+			return "", nil, dgerrors.Mark(fmt.Errorf("ownership of %s by %s could not be verified: %s", p.Image, p.Repository, strings.Join(ownership.Problems, "; ")), dgerrors.ErrAttestationInvalid)
+		}
+		if err := ctx.Confirm(ownership.Problems, i18n.T("install.confirm.ownership", p.ID, p.Image, p.Repository)); err != nil {
+			return "", nil, err
+		}
+	} else {
+		ctx.Logger.Debug("Verified catalog entry ownership", ctx.Logger.Args("id", p.ID, "proof", ownership.Proof))
+	}
+	return release.Reference(p.Image), p, nil
+}
 
-	lockfilePath := filepath.Join(dragonglassDir, "dragonglass-lock.json")
-	cfg := config.DefaultConfig()
-	lockfileData := lockfile.NewLockfile(lockfilePath)
+// checkCatalogProvenance refuses a release resolved from catalog entry p whose verified
+// provenance names another source repository than the catalog does. The catalog's own data
+// cannot show this, as whoever compiled or served the catalog could have written it.
+func checkCatalogProvenance(p *catalog.Plugin, result *attestation.VerificationResult) error {
+	// Without provenance there is nothing to compare; the verification policy decides
+	// whether that is allowed
+	if result.SLSA == nil {
+		return nil
+	}
+	ownership := &catalog.Ownership{}
+	ownership.CheckProvenance(p, result.SLSA.Repository)
+	if len(ownership.Problems) > 0 {
+		return dgerrors.Mark(fmt.Errorf("%s does not belong to %s: %s", p.Image, p.Repository, strings.Join(ownership.Problems, "; ")), dgerrors.ErrAttestationInvalid)
+	}
+	return nil
+}
+
+// Add verifies imageRef, installs it into the vault, and pins it in the lockfile, as the
+// add command does. Mutable tags are refused.
+func Add(opCtx context.Context, ctx *cmd.CommandContext, imageRef string, force bool) error {
+	return runAddCommand(opCtx, imageRef, ctx, force, false, false, nil)
+}
+
+func runAddCommand(opCtx context.Context, imageRef string, ctx *cmd.CommandContext, force, unfreezeOnce, allowMutable bool, catalogEntry *catalog.Plugin) error {
+	if registry.IsMutableReference(imageRef) {
+		ctx.Logger.Warn("Reference names a mutable tag; the vault will not be reproducible from it", ctx.Logger.Args("imageRef", imageRef))
+		if !allowMutable {
+			return fmt.Errorf("%s names a mutable tag; pin a version or digest, or pass --allow-mutable-tag to track it", imageRef)
+		}
+	}
+
+	// Adding the first plugin creates the lockfile
+	lockfilePath, err := ctx.ResolveNewLockfilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+
+	// Load the existing lockfile so previously added plugins are kept
+	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load lockfile: %w", err)
+	}
 
-	return addPlugin(imageRef, cfg, lockfileData, lockfilePath, ctx, force)
+	if err := checkFrozen(ctx, lockfileData, unfreezeOnce); err != nil {
+		return err
+	}
+
+	mode := keepInstalled
+	if force {
+		mode = confirmReplace
+	}
+	return addPlugin(opCtx, imageRef, ctx.Config, lockfileData, lockfilePath, ctx, mode, catalogEntry)
 }
 
-func runInstallFromLockfile(ctx *cmd.CommandContext, force bool) error {
-	// Find dragonglass directory and load lockfile
-	dragonglassDir, err := findDragonglassDirectory()
+func runInstallFromLockfile(opCtx context.Context, ctx *cmd.CommandContext, force, acceptRetag bool, filter PluginFilter) error {
+	v, err := ctx.DiscoverVault()
 	if err != nil {
-		return fmt.Errorf("failed to find dragonglass directory: %w", err)
+		return fmt.Errorf("failed to find vault: %w", err)
 	}
 
-	lockfilePath := filepath.Join(dragonglassDir, "dragonglass-lock.json")
+	if err := writeSyncIgnore(ctx.Config, v); err != nil {
+		return err
+	}
+
+	lockfilePath, err := ctx.ResolveLockfilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+
+	// Load the project manifest if the vault declares one
+	manifestPath := v.ManifestPath()
+	projectManifest, err := manifest.LoadManifest(manifestPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to load %s: %w", manifest.ManifestFileName, err)
+	}
 
-	// Check if lockfile exists
-	if _, err := os.Stat(lockfilePath); os.IsNotExist(err) {
+	// Check if lockfile exists (not required when a manifest declares the plugins)
+	if _, err := os.Stat(lockfilePath); os.IsNotExist(err) && projectManifest == nil {
 		return fmt.Errorf("lockfile not found at %s (run 'dragonglass add' to add plugins first)", lockfilePath)
 	}
 
-	// Load existing lockfile
-	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	// Load existing lockfile, and check the vault admin signed exactly the plugin set loaded
+	lockfileData, data, err := lockfile.ReadLockfile(lockfilePath)
 	if err != nil {
 		return fmt.Errorf("failed to load lockfile: %w", err)
 	}
+	if err := checkLockfileSignature(ctx, lockfilePath, data); err != nil {
+		return err
+	}
+
+	// A signed lockfile is installed as signed; changing it would invalidate the signature
+	if ctx.Config.Verification.RequireSignedLockfile {
+		if acceptRetag {
+			return fmt.Errorf("--accept-retag would change the signed lockfile; re-add the plugin and sign the lockfile again instead")
+		}
+		if projectManifest != nil {
+			ctx.Logger.Info("Lockfile must be signed, installing from the lockfile without resolving the manifest")
+			projectManifest = nil
+		}
+	}
+
+	// A frozen vault installs exactly what the lockfile pins
+	if lockfileData.Frozen() {
+		if acceptRetag {
+			return checkFrozen(ctx, lockfileData, false)
+		}
+		if projectManifest != nil {
+			ctx.Logger.Info("Vault is frozen, installing from the lockfile without resolving the manifest")
+			projectManifest = nil
+		}
+	}
+
+	// Warn about --only patterns that select nothing, most likely a misspelled ID
+	known := slices.Collect(maps.Keys(lockfileData.Plugins))
+	if projectManifest != nil {
+		known = append(known, slices.Collect(maps.Keys(projectManifest.Plugins))...)
+	}
+	for _, pattern := range filter.Unmatched(known) {
+		ctx.Logger.Warn("--only pattern matches no plugin", ctx.Logger.Args("pattern", pattern))
+	}
+	projectManifest = filter.manifest(projectManifest)
+
+	// Resolve manifest version ranges not already pinned by the lockfile
+	resolved := map[string]bool{}
+	if projectManifest != nil {
+		ctx.Logger.Debug("Resolving plugins declared in manifest", ctx.Logger.Args("path", makeRelativePath(manifestPath), "count", len(projectManifest.Plugins)))
+		resolved, err = resolveManifest(opCtx, ctx, projectManifest, ctx.Config, lockfileData, lockfilePath, false, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to resolve manifest: %w", err)
+		}
+	}
 
 	if len(lockfileData.Plugins) == 0 {
 		ctx.Logger.Info("No plugins found in lockfile")
@@ -124,38 +362,63 @@ func runInstallFromLockfile(ctx *cmd.CommandContext, force bool) error {
 
 	ctx.Logger.Info("Found plugins in lockfile", ctx.Logger.Args("count", len(lockfileData.Plugins)))
 
-	// Find Obsidian directory for installation
-	obsidianDir, err := findObsidianDirectory()
-	if err != nil {
-		return fmt.Errorf("failed to find Obsidian directory: %w", err)
+	// Ask once before --force replaces the plugins that are already installed
+	if force {
+		if err := confirmReinstall(ctx, v, lockfileData, resolved, filter); err != nil {
+			return err
+		}
 	}
 
 	// Install each plugin from lockfile
 	installedCount := 0
 	skippedCount := 0
+	filteredCount := 0
 
 	for pluginID, pluginEntry := range lockfileData.Plugins {
+		if resolved[pluginID] {
+			installedCount++
+			continue
+		}
+		if !filter.Matches(pluginID) {
+			ctx.Logger.Debug("Skipping plugin (filtered out)", ctx.Logger.Args("id", pluginID))
+			filteredCount++
+			continue
+		}
+		if pluginEntry.IsDev() {
+			ctx.Logger.Warn("Skipping dev build, which cannot be installed from the lockfile", ctx.Logger.Args("id", pluginID, "source", pluginEntry.OCIReference, "hint", "run dev install again to rebuild it"))
+			skippedCount++
+			continue
+		}
+
+		if err := opCtx.Err(); err != nil {
+			return err
+		}
+
 		ctx.Logger.Info("Processing plugin", ctx.Logger.Args("name", pluginEntry.Name, "id", pluginID))
 
-		pluginDir := filepath.Join(obsidianDir, "plugins", pluginID)
+		t, err := entryTarget(v, pluginID, pluginEntry)
+		if err != nil {
+			return fmt.Errorf("failed to install plugin %s: %w", pluginID, err)
+		}
 
-		// Check if plugin directory already exists
-		if _, err := os.Stat(pluginDir); err == nil {
+		// Check if the plugin is already installed; with --force it is replaced once the
+		// new files are fully downloaded, keeping its settings
+		if t.installed() {
 			if !force {
+				if err := checkIntegrity(t, pluginEntry); err != nil {
+					return fmt.Errorf("installed plugin %s does not match the lockfile (use --force to reinstall it): %w", pluginID, err)
+				}
 				ctx.Logger.Debug("Skipping plugin (already exists)", ctx.Logger.Args("id", pluginID, "hint", "use --force to overwrite"))
 				skippedCount++
 				continue
 			}
-			ctx.Logger.Debug("Removing existing plugin directory", ctx.Logger.Args("path", makeRelativePath(pluginDir)))
-			if err := os.RemoveAll(pluginDir); err != nil {
-				return fmt.Errorf("failed to remove existing plugin directory %s: %w", makeRelativePath(pluginDir), err)
-			}
+			ctx.Logger.Debug("Replacing existing plugin directory", ctx.Logger.Args("path", makeRelativePath(t.path)))
 		}
 
 		// Install plugin from OCI reference
 		ctx.Logger.Debug("Installing from OCI reference", ctx.Logger.Args("reference", pluginEntry.OCIReference, "digest", pluginEntry.OCIDigest))
 
-		if err := installPluginFromLockfileEntry(pluginEntry.OCIReference, pluginDir, pluginID, pluginEntry, ctx); err != nil {
+		if err := installLockedPlugin(opCtx, ctx, lockfileData, lockfilePath, pluginID, pluginEntry, t, acceptRetag); err != nil {
 			return fmt.Errorf("failed to install plugin %s: %w", pluginID, err)
 		}
 
@@ -163,50 +426,162 @@ func runInstallFromLockfile(ctx *cmd.CommandContext, force bool) error {
 		installedCount++
 	}
 
-	ctx.Logger.Info("Installation summary", ctx.Logger.Args("installed", installedCount, "skipped", skippedCount))
+	ctx.Logger.Info("Installation summary", ctx.Logger.Args("installed", installedCount, "skipped", skippedCount, "filtered", filteredCount))
 
 	return nil
 }
 
-func installPluginFromLockfileEntry(imageRef, pluginDir, pluginID string, pluginEntry lockfile.PluginEntry, cmdCtx *cmd.CommandContext) error {
+// installLockedPlugin installs a lockfile entry at its pinned digest. When the entry's tag
+// was re-pushed, the new artifact is verified and pinned if acceptRetag is set and the
+// install fails otherwise. Entries for mutable tags are fetched by digest, so their tag
+// moving is expected and never fails the install.
+func installLockedPlugin(opCtx context.Context, ctx *cmd.CommandContext, lockfileData *lockfile.Lockfile, lockfilePath, pluginID string, pluginEntry lockfile.PluginEntry, t target, acceptRetag bool) error {
+	err := installPluginFromLockfileEntry(opCtx, pluginEntry.LockedReference(), t, pluginID, pluginEntry, ctx)
+
+	var moved *dgerrors.TagMovedError
+	if !errors.As(err, &moved) {
+		return err
+	}
+
+	ctx.Logger.Warn("TAG MOVED: locked tag now points to a different artifact",
+		ctx.Logger.Args("id", pluginID, "reference", moved.Reference, "locked", moved.Expected, "current", moved.Actual))
+	if !acceptRetag {
+		return err
+	}
+
+	summary := []string{i18n.T("install.confirm.retag_locked", moved.Expected), i18n.T("install.confirm.retag_current", moved.Actual)}
+	if err := ctx.Confirm(summary, i18n.T("install.confirm.retag", moved.Reference)); err != nil {
+		return err
+	}
+
+	ctx.Logger.Info("Verifying re-pushed artifact (--accept-retag)", ctx.Logger.Args("reference", moved.Reference))
+	return addPlugin(opCtx, pluginEntry.OCIReference, ctx.Config, lockfileData, lockfilePath, ctx, replaceInstalled, nil)
+}
+
+// confirmReinstall asks before install --force replaces the plugins of the lockfile that
+// filter selects and are already installed, except those just installed from the manifest
+func confirmReinstall(ctx *cmd.CommandContext, v *vault.Vault, lockfileData *lockfile.Lockfile, resolved map[string]bool, filter PluginFilter) error {
+	targets := make(map[string]target, len(lockfileData.Plugins))
+	for pluginID, entry := range lockfileData.Plugins {
+		if resolved[pluginID] || !filter.Matches(pluginID) || entry.IsDev() {
+			continue
+		}
+		if t, err := entryTarget(v, pluginID, entry); err == nil && t.installed() {
+			targets[pluginID] = t
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	summary := make([]string, 0, len(targets))
+	for _, pluginID := range slices.Sorted(maps.Keys(targets)) {
+		entry := lockfileData.Plugins[pluginID]
+		summary = append(summary, i18n.T("install.confirm.replace_item", entry.Name, entry.Version, makeRelativePath(targets[pluginID].path)))
+	}
+	return ctx.Confirm(summary, i18n.T("install.confirm.replace"))
+}
+
+func installPluginFromLockfileEntry(ctx context.Context, imageRef string, t target, pluginID string, pluginEntry lockfile.PluginEntry, cmdCtx *cmd.CommandContext) (err error) {
+	cfg := cmdCtx.Config
+
 	// Create registry client with plugin options
-	registryOpts := registry.DefaultRegistryOpts().WithPluginOpts(&plugin.PluginOpts{
-		AnnotationNamespace: cmdCtx.AnnotationNamespace,
-	})
+	registryOpts := registry.DefaultRegistryOpts().
+		WithTimeout(cfg.Registry.Timeout.Duration()).
+		WithCredentials(cfg.Registry.CredentialLookup()).
+		WithDockerCredentials(registry.DockerCredentialStore(cfg.Registry.DockerCredentials)).
+		WithPluginOpts(&plugin.PluginOpts{
+			AnnotationNamespace: cmdCtx.AnnotationNamespace,
+		})
+	if cmdCtx.GitHubToken != "" {
+		registryOpts = registryOpts.WithAuthProvider(auth.NewAuthClient(auth.DefaultAuthOpts().WithToken(cmdCtx.GitHubToken)))
+	}
 	client, err := registry.NewClient(registryOpts)
 	if err != nil {
 		return fmt.Errorf("failed to create registry client: %w", err)
 	}
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	// Create context bounded by install.timeout
+	ctx, cancel := context.WithTimeout(ctx, cfg.Install.Timeout.Duration())
 	defer cancel()
+	defer func() { err = cfg.WrapTimeout(ctx, "install.timeout", err) }()
 
-	// Fetch manifest using image reference
-	manifest, _, manifestDigest, err := client.GetManifest(ctx, imageRef)
+	// Resolve the reference once; extraction reads through the same repository client
+	artifact, err := client.OpenArtifact(ctx, imageRef)
 	if err != nil {
 		return fmt.Errorf("failed to fetch manifest: %w", err)
 	}
+	manifest, manifestDigest := artifact.Manifest, artifact.Digest()
 
 	// Verify digest matches what's in lockfile
 	if manifestDigest != pluginEntry.OCIDigest {
-		return fmt.Errorf("digest mismatch: expected %s, got %s", pluginEntry.OCIDigest, manifestDigest)
+		return registry.ManifestMismatch(imageRef, pluginEntry.OCIDigest, manifestDigest)
+	}
+
+	// Verify the blobs the manifest references match those recorded at install
+	if err := pluginEntry.CheckBlobs(manifestBlobs(manifest)); err != nil {
+		return err
 	}
 
-	// Extract plugin files
-	if err := extractPluginFilesFromManifest(ctx, imageRef, manifest, pluginDir); err != nil {
-		// Clean up on failure
-		_ = os.RemoveAll(pluginDir)
-		return fmt.Errorf("failed to extract plugin files: %w", err)
+	if err := preInstallHook(ctx, cmdCtx, pluginID, pluginEntry); err != nil {
+		return err
 	}
 
-	// Create manifest.json from lockfile metadata
-	if err := createPluginManifestFromLockfile(pluginDir, pluginID, pluginEntry); err != nil {
-		// Clean up on failure
-		_ = os.RemoveAll(pluginDir)
-		return fmt.Errorf("failed to create plugin manifest: %w", err)
+	v, err := cmdCtx.DiscoverVault()
+	if err != nil {
+		return fmt.Errorf("failed to find vault: %w", err)
 	}
 
+	// Extract plugin files, create manifest.json from lockfile metadata, and check the
+	// result is loadable; nothing is left in the vault if any step fails or is cancelled
+	files, err := installArtifact(ctx, v.TmpDir(), t, cfg.Install.WriteAttempts, func(stagingDir string) error {
+		if err := artifact.ExtractPluginFiles(ctx, stagingDir); err != nil {
+			return fmt.Errorf("failed to extract plugin files: %w", err)
+		}
+		if pluginEntry.Kind != plugin.KindSnippet {
+			if err := createPluginManifestFromLockfile(stagingDir, pluginID, pluginEntry); err != nil {
+				return fmt.Errorf("failed to create plugin manifest: %w", err)
+			}
+		}
+		if err := plugin.CheckArtifactHealth(pluginEntry.Kind, stagingDir, pluginID, pluginEntry.Name).Err(); err != nil {
+			return fmt.Errorf("plugin failed health check: %w", err)
+		}
+		// Files are staged in a directory for every kind, snippets included
+		return checkIntegrity(target{path: stagingDir}, pluginEntry)
+	})
+	if err != nil {
+		return err
+	}
+
+	installed := pluginEntry
+	installed.Files = files
+	installed.Integrity = lockfile.Integrity(files)
+	if err := attestInstall(ctx, cmdCtx, pluginID, installed, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to record install attestation: %w", err)
+	}
+
+	postHook(ctx, cmdCtx, hooks.PostInstall, pluginID, installed)
+	return nil
+}
+
+// checkIntegrity checks the files installed at t against the integrity value the lockfile
+// entry records, naming the files that differ from those recorded on a mismatch
+func checkIntegrity(t target, pluginEntry lockfile.PluginEntry) error {
+	if pluginEntry.Integrity == "" {
+		return nil
+	}
+	files, err := t.hash()
+	if err != nil {
+		return err
+	}
+	if err := pluginEntry.CheckIntegrity(files); err != nil {
+		// manifest.json is written from lockfile metadata and does not count towards integrity
+		changed := slices.DeleteFunc(lockfile.ChangedFiles(pluginEntry.Files, files), func(name string) bool { return name == plugin.ManifestFile })
+		if len(changed) > 0 {
+			return fmt.Errorf("%w (changed: %s)", err, strings.Join(changed, ", "))
+		}
+		return err
+	}
 	return nil
 }
 
@@ -226,6 +601,9 @@ func createPluginManifestFromLockfile(pluginDir, pluginID string, pluginEntry lo
 	if pluginEntry.Metadata.Repository != "" {
 		manifestData["authorUrl"] = pluginEntry.Metadata.Repository
 	}
+	if pluginEntry.Kind == plugin.KindTheme {
+		themeManifest(manifestData)
+	}
 
 	file, err := os.Create(manifestPath)
 	if err != nil {
@@ -244,27 +622,52 @@ func createPluginManifestFromLockfile(pluginDir, pluginID string, pluginEntry lo
 	return nil
 }
 
-func addPlugin(imageRef string, cfg *config.Config, lockfileData *lockfile.Lockfile, lockfilePath string, cmdCtx *cmd.CommandContext, force bool) error {
+// replaceMode is how addPlugin treats a plugin that is already installed
+type replaceMode int
+
+const (
+	// Refuse to replace it, pointing at --force
+	keepInstalled replaceMode = iota
+	// Replace it once the user confirms (--force)
+	confirmReplace
+	// Replace it without asking, for updates the user already chose
+	replaceInstalled
+)
+
+// addPlugin verifies and installs imageRef and pins it in lockfileData. A reference
+// resolved from a catalog name passes its catalog entry (optional), whose repository the
+// release's provenance must name.
+func addPlugin(ctx context.Context, imageRef string, cfg *config.Config, lockfileData *lockfile.Lockfile, lockfilePath string, cmdCtx *cmd.CommandContext, mode replaceMode, catalogEntry *catalog.Plugin) (err error) {
 	// Step 1: Create registry client with plugin options
 	cmdCtx.Logger.Debug("Creating registry client")
-	registryOpts := registry.DefaultRegistryOpts().WithPluginOpts(&plugin.PluginOpts{
-		AnnotationNamespace: cmdCtx.AnnotationNamespace,
-	})
+	registryOpts := registry.DefaultRegistryOpts().
+		WithTimeout(cfg.Registry.Timeout.Duration()).
+		WithCredentials(cfg.Registry.CredentialLookup()).
+		WithDockerCredentials(registry.DockerCredentialStore(cfg.Registry.DockerCredentials)).
+		WithPluginOpts(&plugin.PluginOpts{
+			AnnotationNamespace: cmdCtx.AnnotationNamespace,
+		})
+	if cmdCtx.GitHubToken != "" {
+		registryOpts = registryOpts.WithAuthProvider(auth.NewAuthClient(auth.DefaultAuthOpts().WithToken(cmdCtx.GitHubToken)))
+	}
 	client, err := registry.NewClient(registryOpts)
 	if err != nil {
 		return fmt.Errorf("failed to create registry client: %w", err)
 	}
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	// Create context bounded by install.timeout
+	ctx, cancel := context.WithTimeout(ctx, cfg.Install.Timeout.Duration())
 	defer cancel()
+	defer func() { err = cfg.WrapTimeout(ctx, "install.timeout", err) }()
 
-	// Step 2: Fetch and parse manifest
+	// Step 2: Resolve the reference and fetch its manifest once; verification and
+	// extraction share the resolved artifact and its repository client
 	cmdCtx.Logger.Debug("Fetching manifest from registry")
-	manifest, annotations, manifestDigest, err := client.GetManifest(ctx, imageRef)
+	artifact, err := client.OpenArtifact(ctx, imageRef)
 	if err != nil {
 		return fmt.Errorf("failed to fetch manifest: %w", err)
 	}
+	manifest, annotations, manifestDigest := artifact.Manifest, artifact.Manifest.Annotations, artifact.Digest()
 
 	// Step 3: Parse plugin metadata
 	cmdCtx.Logger.Debug("Parsing plugin metadata")
@@ -288,6 +691,13 @@ func addPlugin(imageRef string, cfg *config.Config, lockfileData *lockfile.Lockf
 		"isDesktopOnly", pluginMetadata.IsDesktopOnly,
 	))
 
+	// Per-plugin overrides in the vault manifest take precedence over the configuration
+	policy, err := verify.DeclaredPolicy(cmdCtx, pluginMetadata.ID)
+	if err != nil {
+		return err
+	}
+	cmdCtx, cfg = verify.ApplyPolicy(cmdCtx, cfg, policy)
+
 	// Step 4: Validate metadata
 	validation := parser.ValidateMetadata(pluginMetadata)
 	if !validation.Valid {
@@ -299,137 +709,170 @@ func addPlugin(imageRef string, cfg *config.Config, lockfileData *lockfile.Lockf
 
 	// Step 5: Perform verification (SLSA, etc.)
 	cmdCtx.Logger.Debug("Verifying attestations")
-	token, err := auth.GetToken()
+	verifier, err := verify.NewAttestationVerifier(cmdCtx)
 	if err != nil {
-		return fmt.Errorf("failed to get authentication token: %w", err)
+		return err
 	}
 
-	verifier, err := attestation.NewAttestationVerifier(token, cmdCtx.TrustedBuilder)
+	attestationResult, err := verifyPluginAttestations(ctx, cfg, verifier, artifact)
 	if err != nil {
-		return fmt.Errorf("failed to create attestation verifier: %w", err)
+		return err
 	}
-
-	attestationResult, err := verifier.VerifyAttestations(ctx, imageRef)
-	if err != nil {
-		return fmt.Errorf("failed to verify attestations: %w", err)
+	attestationResult.Findings.SetPluginID(pluginMetadata.ID)
+	if err := verify.CheckPolicy(policy, attestationResult); err != nil {
+		return err
 	}
-
-	// Check verification results
-	if cfg.Verification.StrictMode && (!attestationResult.Found || !attestationResult.Valid) {
-		if !attestationResult.Found {
-			return fmt.Errorf("attestations not found (required in strict mode)")
+	if catalogEntry != nil {
+		if err := checkCatalogProvenance(catalogEntry, attestationResult); err != nil {
+			return err
 		}
-		if !attestationResult.Valid {
-			return fmt.Errorf("attestation verification failed (required in strict mode)")
+	}
+
+	found := append(verify.MetadataWarnings(validation), verify.AttestationWarnings(attestationResult)...)
+	for _, warning := range found {
+		cmdCtx.Logger.Warn("Verification warning", cmdCtx.Logger.Args("category", warning.Category, "warning", warning.Message))
+	}
+	if err := cmdCtx.WarningPolicy.Check(found); err != nil {
+		return err
+	}
+	if counted := cmdCtx.WarningPolicy.Counted(found); len(counted) > 0 && !cfg.Verification.StrictMode {
+		if err := cmdCtx.Confirm(warnings.Messages(counted), i18n.T("install.confirm.warnings", pluginMetadata.ID, pluginMetadata.Version, len(counted))); err != nil {
+			return err
 		}
 	}
 
-	// Step 6: Discover Obsidian directory
-	cmdCtx.Logger.Debug("Finding Obsidian directory")
-	obsidianDir, err := findObsidianDirectory()
+	// Step 6: Discover the vault
+	cmdCtx.Logger.Debug("Finding vault")
+	v, err := cmdCtx.DiscoverVault()
 	if err != nil {
-		return fmt.Errorf("failed to find Obsidian directory: %w", err)
+		return fmt.Errorf("failed to find vault: %w", err)
 	}
 
-	pluginDir := filepath.Join(obsidianDir, "plugins", pluginMetadata.ID)
-	cmdCtx.Logger.Debug("Plugin installation target", cmdCtx.Logger.Args("path", makeRelativePath(pluginDir)))
+	if err := writeSyncIgnore(cfg, v); err != nil {
+		return err
+	}
+
+	t, err := newTarget(v, pluginMetadata.Kind, pluginMetadata.ID, pluginMetadata.Name)
+	if err != nil {
+		return err
+	}
+	cmdCtx.Logger.Debug("Plugin installation target", cmdCtx.Logger.Args("path", makeRelativePath(t.path)))
 
-	// Step 7: Check for conflicts
-	if _, err := os.Stat(pluginDir); err == nil {
-		if !force {
-			return fmt.Errorf("plugin directory already exists: %s (use --force to overwrite)", makeRelativePath(pluginDir))
+	// Step 7: Check for conflicts; settings kept from a removed install are not one
+	if t.installed() {
+		if mode == keepInstalled {
+			return fmt.Errorf("plugin already installed: %s (use --force to overwrite)", makeRelativePath(t.path))
 		}
-		cmdCtx.Logger.Debug("Removing existing plugin directory", cmdCtx.Logger.Args("path", makeRelativePath(pluginDir)))
-		if err := os.RemoveAll(pluginDir); err != nil {
-			return fmt.Errorf("failed to remove existing plugin directory: %w", err)
+		if err := confirmReplacement(cmdCtx, lockfileData, mode, pluginMetadata, t.path); err != nil {
+			return err
 		}
+		cmdCtx.Logger.Debug("Replacing existing plugin directory", cmdCtx.Logger.Args("path", makeRelativePath(t.path)))
 	}
 
-	// Step 8: Extract plugin files
-	cmdCtx.Logger.Debug("Extracting plugin files")
-	if err := extractPluginFilesFromManifest(ctx, imageRef, manifest, pluginDir); err != nil {
-		// Clean up on failure
-		_ = os.RemoveAll(pluginDir) // Ignore cleanup error
-		return fmt.Errorf("failed to extract plugin files: %w", err)
+	now := time.Now().UTC()
+	entry := lockfile.PluginEntry{
+		Name:              pluginMetadata.Name,
+		Version:           pluginMetadata.Version,
+		Kind:              pluginMetadata.Kind,
+		OCIReference:      imageRef,
+		OCIDigest:         manifestDigest,
+		MutableTag:        registry.IsMutableReference(imageRef),
+		VerificationState: VerificationState(attestationResult, cfg.Verification, now),
+		Metadata: lockfile.PluginMetadata{
+			Author:      pluginMetadata.Author,
+			Description: pluginMetadata.Description,
+			Repository:  pluginMetadata.AuthorURL,
+		},
 	}
 
-	// Step 9: Create manifest.json from metadata
-	cmdCtx.Logger.Debug("Creating plugin manifest")
-	if err := createPluginManifest(pluginDir, pluginMetadata); err != nil {
-		// Clean up on failure
-		_ = os.RemoveAll(pluginDir) // Ignore cleanup error
-		return fmt.Errorf("failed to create plugin manifest: %w", err)
+	// Refuse to roll an installed plugin back unless the user asked for it; a dev build is
+	// replaced whatever its version. A plugin switched off with disable stays off.
+	if installed, ok := lockfileData.GetPlugin(pluginMetadata.ID); ok {
+		entry.Disabled = installed.Disabled
+		if !installed.IsDev() {
+			if err := checkDowngrade(cmdCtx, v, pluginMetadata.ID, installed, entry); err != nil {
+				return err
+			}
+		}
 	}
 
-	// Step 10: Update lockfile
-	cmdCtx.Logger.Debug("Updating lockfile")
-	if err := updateLockfile(lockfileData, lockfilePath, pluginMetadata, imageRef, manifestDigest); err != nil {
-		return fmt.Errorf("failed to update lockfile: %w", err)
+	// Step 8: Let pre_install hooks inspect the verified plugin and reject it
+	if err := preInstallHook(ctx, cmdCtx, pluginMetadata.ID, entry); err != nil {
+		return err
 	}
 
-	cmdCtx.Logger.Info("Installation completed successfully", cmdCtx.Logger.Args("plugin", pluginMetadata.Name, "id", pluginMetadata.ID, "location", makeRelativePath(pluginDir)))
+	// Steps 9-10: Extract plugin files, create manifest.json from metadata, and check the
+	// result is loadable. The existing directory is only replaced once all succeed, so an
+	// interrupted or corrupted install leaves no partial plugin behind.
+	files, err := installArtifact(ctx, v.TmpDir(), t, cfg.Install.WriteAttempts, func(stagingDir string) error {
+		cmdCtx.Logger.Debug("Extracting plugin files")
+		if err := artifact.ExtractPluginFiles(ctx, stagingDir); err != nil {
+			return fmt.Errorf("failed to extract plugin files: %w", err)
+		}
 
-	return nil
-}
+		// A snippet is a bare stylesheet without a manifest
+		if pluginMetadata.Kind != plugin.KindSnippet {
+			cmdCtx.Logger.Debug("Creating plugin manifest")
+			if err := createPluginManifest(stagingDir, pluginMetadata); err != nil {
+				return fmt.Errorf("failed to create plugin manifest: %w", err)
+			}
+		}
 
-// findObsidianDirectory searches for .obsidian directory from current directory up
-func findObsidianDirectory() (string, error) {
-	currentDir, err := os.Getwd()
+		cmdCtx.Logger.Debug("Checking plugin health")
+		if err := plugin.CheckArtifactHealth(pluginMetadata.Kind, stagingDir, pluginMetadata.ID, pluginMetadata.Name).Err(); err != nil {
+			return fmt.Errorf("plugin failed health check: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to get current directory: %w", err)
+		return err
 	}
 
-	// Search up the directory tree for .obsidian
-	for {
-		obsidianPath := filepath.Join(currentDir, ".obsidian")
-		if info, err := os.Stat(obsidianPath); err == nil && info.IsDir() {
-			return obsidianPath, nil
-		}
-
-		parent := filepath.Dir(currentDir)
-		if parent == currentDir {
-			break // reached root
+	// Step 11: Update lockfile
+	cmdCtx.Logger.Debug("Updating lockfile")
+	blobs := manifestBlobs(manifest)
+	entry.InstalledAt = &now
+	entry.Files = files
+	entry.Integrity = lockfile.Integrity(files)
+	entry.OCIBlobs = &blobs
+	if err := updateLockfile(lockfileData, lockfilePath, pluginMetadata.ID, entry); err != nil {
+		return fmt.Errorf("failed to update lockfile: %w", err)
+	}
+	if recorded, ok := lockfileData.GetPlugin(pluginMetadata.ID); ok {
+		if err := AuditVerification(v, pluginMetadata.ID, recorded); err != nil {
+			cmdCtx.Logger.Warn("Could not record verification in the audit log", cmdCtx.Logger.Args("plugin", pluginMetadata.ID, "error", err))
 		}
-		currentDir = parent
 	}
 
-	return "", fmt.Errorf(".obsidian directory not found in current path or parent directories")
-}
-
-// findDragonglassDirectory searches for or creates .dragonglass directory from current directory up
-func findDragonglassDirectory() (string, error) {
-	currentDir, err := os.Getwd()
-	if err != nil {
-		return "", fmt.Errorf("failed to get current directory: %w", err)
+	// Step 12: Record a signed install attestation
+	if err := attestInstall(ctx, cmdCtx, pluginMetadata.ID, entry, now); err != nil {
+		return fmt.Errorf("failed to record install attestation: %w", err)
 	}
 
-	// Search up the directory tree for .dragonglass or create it at the same level as .obsidian
-	for {
-		// Check if .obsidian exists to determine if this is an Obsidian vault
-		obsidianPath := filepath.Join(currentDir, ".obsidian")
-		if info, err := os.Stat(obsidianPath); err == nil && info.IsDir() {
-			// Found .obsidian, so create/use .dragonglass at the same level
-			dragonglassPath := filepath.Join(currentDir, ".dragonglass")
+	// Step 13: Notify post_install hooks
+	postHook(ctx, cmdCtx, hooks.PostInstall, pluginMetadata.ID, entry)
 
-			// Create .dragonglass directory if it doesn't exist
-			if err := os.MkdirAll(dragonglassPath, 0755); err != nil {
-				return "", fmt.Errorf("failed to create .dragonglass directory: %w", err)
-			}
+	cmdCtx.Logger.Info("Installation completed successfully", cmdCtx.Logger.Args("plugin", pluginMetadata.Name, "id", pluginMetadata.ID, "location", makeRelativePath(t.path)))
 
-			return dragonglassPath, nil
-		}
+	return nil
+}
 
-		parent := filepath.Dir(currentDir)
-		if parent == currentDir {
-			break // reached root
-		}
-		currentDir = parent
+// createPluginManifest creates the manifest.json file required by Obsidian
+// confirmReplacement asks before the plugin installed in pluginDir is replaced with the
+// verified plugin described by metadata: always when dragonglass did not install it, and
+// for plugins in the lockfile when the replacement was forced
+func confirmReplacement(cmdCtx *cmd.CommandContext, lockfileData *lockfile.Lockfile, mode replaceMode, metadata *plugin.Metadata, pluginDir string) error {
+	installed, managed := lockfileData.GetPlugin(metadata.ID)
+	switch {
+	case !managed:
+		return cmdCtx.Confirm(nil, i18n.T("install.confirm.adopt", metadata.ID, makeRelativePath(pluginDir), metadata.Version))
+	case mode == confirmReplace:
+		return cmdCtx.Confirm(nil, i18n.T("install.confirm.replace_one", metadata.ID, installed.Version, metadata.Version))
+	default:
+		return nil
 	}
-
-	return "", fmt.Errorf(".obsidian directory not found in current path or parent directories (required to determine vault location)")
 }
 
-// createPluginManifest creates the manifest.json file required by Obsidian
 func createPluginManifest(pluginDir string, metadata *plugin.Metadata) error {
 	manifestPath := filepath.Join(pluginDir, "manifest.json")
 
@@ -451,6 +894,9 @@ func createPluginManifest(pluginDir string, metadata *plugin.Metadata) error {
 	if metadata.IsDesktopOnly {
 		manifestData["isDesktopOnly"] = true
 	}
+	if metadata.Kind == plugin.KindTheme {
+		themeManifest(manifestData)
+	}
 
 	file, err := os.Create(manifestPath)
 	if err != nil {
@@ -469,64 +915,138 @@ func createPluginManifest(pluginDir string, metadata *plugin.Metadata) error {
 	return nil
 }
 
+// themeManifest turns plugin manifest data into a theme's manifest, which Obsidian
+// identifies by name and which has no id or description
+func themeManifest(manifestData map[string]interface{}) {
+	delete(manifestData, "id")
+	delete(manifestData, "description")
+	delete(manifestData, "isDesktopOnly")
+}
+
 // updateLockfile adds the installed plugin to the lockfile
-func updateLockfile(lockfileData *lockfile.Lockfile, lockfilePath string, metadata *plugin.Metadata, imageRef, digest string) error {
+func updateLockfile(lockfileData *lockfile.Lockfile, lockfilePath string, pluginID string, entry lockfile.PluginEntry) error {
 	if lockfileData == nil {
 		return fmt.Errorf("lockfile data is nil")
 	}
 
-	// Create plugin entry
-	entry := lockfile.PluginEntry{
-		Name:         metadata.Name,
-		Version:      metadata.Version,
-		OCIReference: imageRef,
-		OCIDigest:    digest,
-		VerificationState: lockfile.VerificationState{
-			ProvenanceVerified: true,  // We verified SLSA above
-			SBOMVerified:       false, // Not implemented yet
-			VulnScanPassed:     true,  // Assume passed for now
-		},
-		Metadata: lockfile.PluginMetadata{
-			Author:      metadata.Author,
-			Description: metadata.Description,
-			Repository:  metadata.AuthorURL,
-		},
-	}
+	tx := lockfileData.Begin(lockfilePath)
+	defer tx.Rollback()
 
-	// Add to lockfile
-	if err := lockfileData.AddPlugin(metadata.ID, entry); err != nil {
+	if err := tx.AddPlugin(pluginID, entry); err != nil {
 		return fmt.Errorf("failed to add plugin to lockfile: %w", err)
 	}
 
-	// Save lockfile
-	if err := lockfile.SaveLockfile(lockfileData, lockfilePath); err != nil {
+	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to save lockfile: %w", err)
 	}
 
 	return nil
 }
 
-// extractPluginFilesFromManifest extracts main.js and styles.css from OCI manifest layers
-func extractPluginFilesFromManifest(ctx context.Context, imageRef string, manifest *ocispec.Manifest, targetDir string) error {
-	// Get GitHub token for OCI authentication
-	token, err := auth.GetToken()
-	if err != nil {
-		return fmt.Errorf("failed to get authentication token: %w", err)
+// manifestBlobs returns the config and layer digests a manifest references, in order
+func manifestBlobs(manifest *ocispec.Manifest) lockfile.BlobDigests {
+	blobs := lockfile.BlobDigests{
+		Config: manifest.Config.Digest.String(),
+		Layers: make([]string, 0, len(manifest.Layers)),
 	}
+	for _, layer := range manifest.Layers {
+		blobs.Layers = append(blobs.Layers, layer.Digest.String())
+	}
+	return blobs
+}
 
-	// Create OCI registry client
-	ghcrRegistry := &oci.GHCRRegistry{Token: token}
-	repo, err := ghcrRegistry.GetRepositoryFromRef(imageRef)
-	if err != nil {
-		return fmt.Errorf("failed to create OCI repository: %w", err)
+// VerificationState records the outcome of attestation verification for the lockfile
+func VerificationState(result *attestation.VerificationResult, policy config.VerificationConfig, verifiedAt time.Time) lockfile.VerificationState {
+	state := lockfile.VerificationState{
+		ProvenanceVerified: result.Found && result.Valid,
+		VerifiedAt:         &verifiedAt,
+		PolicyHash:         policy.PolicyHash(),
+		Warnings:           result.Warnings(),
+		Errors:             result.Errors(),
 	}
 
-	// Extract plugin files using the OCI client
-	if err := repo.ExtractPluginFiles(ctx, manifest, targetDir); err != nil {
-		return fmt.Errorf("failed to extract plugin files: %w", err)
+	if result.SLSA != nil {
+		state.BuilderID = result.SLSA.Builder
+		state.BuiltAt = result.SLSA.BuildStartedOn
+		if result.SLSA.SourceCommit != "" {
+			state.SourceRepository = lockfile.SourceRepositoryURL(result.SLSA.SourceURI)
+			state.SourceCommit = result.SLSA.SourceCommit
+		}
+		// Provenance signed on GitHub-hosted runners meets SLSA Build L2; higher levels
+		// depend on builder isolation that the attestation alone does not prove
+		switch {
+		case result.SLSA.Valid:
+			state.SLSALevel = 2
+		case result.SLSA.Builder != "":
+			state.SLSALevel = 1
+		}
 	}
 
-	return nil
+	if result.SBOM != nil {
+		state.SBOMVerified = result.SBOM.Valid
+		state.SBOMFormat = result.SBOM.Format
+
+		counts := &lockfile.VulnerabilityCounts{}
+		for _, vuln := range result.SBOM.Vulnerabilities {
+			switch strings.ToUpper(vuln.Severity) {
+			case "CRITICAL":
+				counts.Critical++
+			case "HIGH":
+				counts.High++
+			case "MEDIUM":
+				counts.Medium++
+			case "LOW":
+				counts.Low++
+			default:
+				counts.Unknown++
+			}
+		}
+		state.Vulnerabilities = counts
+		state.VulnScanPassed = result.SBOM.Valid && !policy.SkipVulnScan && counts.Critical == 0 && counts.High == 0
+	}
+
+	return state
+}
+
+// verifyAttestations verifies imageRef within verification.timeout
+func verifyAttestations(ctx context.Context, cfg *config.Config, verifier *attestation.AttestationVerifier, imageRef string) (*attestation.VerificationResult, error) {
+	return withVerificationTimeout(ctx, cfg, func(verifyCtx context.Context) (*attestation.VerificationResult, error) {
+		return verifier.VerifyAttestations(verifyCtx, imageRef)
+	})
+}
+
+// withVerificationTimeout runs verify within verification.timeout. Running out of that
+// budget is reported against verification.timeout unless the enclosing install deadline
+// expired first.
+func withVerificationTimeout(ctx context.Context, cfg *config.Config, verify func(context.Context) (*attestation.VerificationResult, error)) (*attestation.VerificationResult, error) {
+	verifyCtx, cancel := context.WithTimeout(ctx, cfg.Verification.Timeout.Duration())
+	defer cancel()
+
+	result, err := verify(verifyCtx)
+	if err != nil && ctx.Err() == nil {
+		err = cfg.WrapTimeout(verifyCtx, "verification.timeout", err)
+	}
+	return result, err
+}
+
+// verifyPluginAttestations verifies the resolved artifact before it is installed,
+// refusing attestations that fail hard and, in strict mode, those that fail soft, and
+// records the outcome in the verification metrics
+func verifyPluginAttestations(ctx context.Context, cfg *config.Config, verifier *attestation.AttestationVerifier, artifact *oci.Artifact) (result *attestation.VerificationResult, err error) {
+	defer func(start time.Time) { metrics.RecordVerification(err, time.Since(start)) }(time.Now())
+
+	result, err = withVerificationTimeout(ctx, cfg, func(verifyCtx context.Context) (*attestation.VerificationResult, error) {
+		return verifier.VerifyArtifact(verifyCtx, artifact)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify attestations: %w", err)
+	}
+
+	// Hard failures always block the install, soft ones only in strict mode
+	if err := result.Enforce(cfg.Verification.StrictMode); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // makeRelativePath converts an absolute path to relative from current working directory
@@ -0,0 +1,105 @@
+// ABOUTME: Remove command deleting installed plugins from the vault and the lockfile
+// ABOUTME: Only plugins pinned in the lockfile can be removed; their settings are kept unless purged
+package install
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/hooks"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+)
+
+// RemoveOptions controls how a plugin is removed
+type RemoveOptions struct {
+	// Also delete the plugin's settings (data.json)
+	Purge bool
+
+	// Remove even though the vault is frozen
+	UnfreezeOnce bool
+}
+
+func NewRemoveCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <plugin-id>",
+		Short: "Remove an installed plugin",
+		Long: `Remove a plugin installed by dragonglass from the vault and the lockfile.
+
+The plugin's settings (data.json) are kept, so adding the plugin again restores
+them. Pass --purge to delete them too. Frozen vaults (see dragonglass freeze)
+refuse removals unless --unfreeze-once is given.
+
+Example:
+  dragonglass remove sample-plugin
+  dragonglass remove --purge sample-plugin`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			purge, _ := cmd.Flags().GetBool("purge")
+			unfreezeOnce, _ := cmd.Flags().GetBool("unfreeze-once")
+
+			if err := Remove(cmd.Context(), ctx, args[0], RemoveOptions{Purge: purge, UnfreezeOnce: unfreezeOnce}); err != nil {
+				ctx.Fail("Remove failed", err)
+			}
+
+			ctx.Logger.Info("Plugin removed successfully", ctx.Logger.Args("id", args[0]))
+		},
+	}
+
+	cmd.Flags().Bool("purge", false, "Also delete the plugin's settings (data.json)")
+	cmd.Flags().Bool("unfreeze-once", false, "Allow this removal even though the vault is frozen")
+	return cmd
+}
+
+// Remove deletes pluginID's directory from the vault and its entry from the lockfile, then
+// runs the post_remove hooks. The plugin's settings are kept unless opts.Purge is set.
+// Frozen vaults refuse removals unless opts.UnfreezeOnce is set.
+func Remove(opCtx context.Context, ctx *cmd.CommandContext, pluginID string, opts RemoveOptions) error {
+	v, err := ctx.DiscoverVault()
+	if err != nil {
+		return fmt.Errorf("failed to find vault: %w", err)
+	}
+
+	lockfilePath, err := ctx.ResolveLockfilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+
+	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load lockfile: %w", err)
+	}
+
+	if err := checkFrozen(ctx, lockfileData, opts.UnfreezeOnce); err != nil {
+		return err
+	}
+
+	entry, ok := lockfileData.GetPlugin(pluginID)
+	if !ok {
+		return dgerrors.Mark(fmt.Errorf("plugin %s not found in lockfile", pluginID), dgerrors.ErrNotInLockfile)
+	}
+
+	tx := lockfileData.Begin(lockfilePath)
+	defer tx.Rollback()
+
+	t, err := entryTarget(v, pluginID, entry)
+	if err != nil {
+		return err
+	}
+	ctx.Logger.Info("Removing plugin", ctx.Logger.Args("id", pluginID, "path", makeRelativePath(t.path)))
+	if err := t.remove(opts.Purge); err != nil {
+		return fmt.Errorf("failed to remove plugin %s: %w", makeRelativePath(t.path), err)
+	}
+	if err := tx.RemovePlugin(pluginID); err != nil {
+		return fmt.Errorf("failed to remove plugin from lockfile: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save lockfile: %w", err)
+	}
+
+	postHook(opCtx, ctx, hooks.PostRemove, pluginID, entry)
+	return nil
+}
@@ -0,0 +1,116 @@
+package install
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+func TestRemove(t *testing.T) {
+	v := vault.New(t.TempDir())
+	pluginDir := v.PluginDir("test-plugin")
+	if err := os.MkdirAll(pluginDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pluginDir, "main.js"), []byte("module.exports = {};"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lockfileData := lockfile.NewLockfile(v.Root)
+	if err := lockfileData.AddPlugin("test-plugin", lockfile.PluginEntry{
+		Name:         "Test",
+		OCIReference: "ghcr.io/owner/test-plugin:1.0.0",
+		OCIDigest:    "sha256:abc",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(v.DragonglassDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := lockfile.SaveLockfile(lockfileData, v.LockfilePath()); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Hooks.PostRemove = []string{"cat > removed.json"}
+
+	t.Chdir(v.Root)
+	ctx := &cmd.CommandContext{
+		Config:       cfg,
+		LockfilePath: v.LockfilePath(),
+		Logger:       pterm.DefaultLogger.WithWriter(os.Stderr).WithLevel(pterm.LogLevelError),
+	}
+
+	if err := Remove(context.Background(), ctx, "other-plugin", RemoveOptions{}); !errors.Is(err, dgerrors.ErrNotInLockfile) {
+		t.Errorf("expected ErrNotInLockfile for an unlocked plugin, got %v", err)
+	}
+
+	if err := Remove(context.Background(), ctx, "test-plugin", RemoveOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(pluginDir); !os.IsNotExist(err) {
+		t.Errorf("expected plugin directory to be removed, got %v", err)
+	}
+	saved, err := lockfile.LoadLockfile(v.LockfilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := saved.GetPlugin("test-plugin"); ok {
+		t.Error("expected plugin to be removed from the lockfile")
+	}
+
+	event, err := os.ReadFile(filepath.Join(v.Root, "removed.json"))
+	if err != nil {
+		t.Fatalf("expected post_remove hook to run: %v", err)
+	}
+	if !strings.Contains(string(event), `"id":"test-plugin"`) {
+		t.Errorf("expected post_remove event for test-plugin, got %s", event)
+	}
+}
+
+func TestRemoveFrozen(t *testing.T) {
+	v := vault.New(t.TempDir())
+	pluginDir := v.PluginDir("test-plugin")
+	if err := os.MkdirAll(pluginDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	lockfileData := lockfile.NewLockfile(v.Root)
+	if err := lockfileData.AddPlugin("test-plugin", lockfile.PluginEntry{
+		Name:         "Test",
+		OCIReference: "ghcr.io/owner/test-plugin:1.0.0",
+		OCIDigest:    "sha256:abc",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	lockfileData.Freeze(time.Now())
+	if err := lockfile.SaveLockfile(lockfileData, v.LockfilePath()); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir(v.Root)
+	ctx := &cmd.CommandContext{
+		Config:       config.DefaultConfig(),
+		LockfilePath: v.LockfilePath(),
+		Logger:       pterm.DefaultLogger.WithWriter(os.Stderr).WithLevel(pterm.LogLevelError),
+	}
+
+	if err := Remove(context.Background(), ctx, "test-plugin", RemoveOptions{}); !errors.Is(err, dgerrors.ErrVaultFrozen) {
+		t.Fatalf("expected ErrVaultFrozen, got %v", err)
+	}
+	if _, err := os.Stat(pluginDir); err != nil {
+		t.Errorf("expected plugin directory to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+// ABOUTME: Version range resolution for plugins declared in the dragonglass.json manifest
+// ABOUTME: Picks the highest verified release within range and pins it in the lockfile
+package install
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
+	"github.com/gillisandrew/dragonglass-poc/internal/auth"
+	"github.com/gillisandrew/dragonglass-poc/internal/changelog"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/verify"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/githubapi"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/manifest"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+	"github.com/gillisandrew/dragonglass-poc/internal/registry"
+	"github.com/gillisandrew/dragonglass-poc/internal/semver"
+)
+
+// candidateVerifier reports whether the artifact at imageRef passes attestation verification
+type candidateVerifier func(imageRef string) (bool, error)
+
+// selectVersion returns the highest tag in range whose artifact verifies successfully.
+// Tags that are not semantic versions (run IDs, commit-* aliases) are ignored.
+func selectVersion(repository string, tags []string, constraint *semver.Constraint, verify candidateVerifier) (string, *semver.Version, error) {
+	tagByVersion := make(map[string]string, len(tags))
+	versions := make([]*semver.Version, 0, len(tags))
+	for _, tag := range tags {
+		v, err := semver.Parse(tag)
+		if err != nil {
+			continue
+		}
+		existing, seen := tagByVersion[v.String()]
+		if !seen {
+			versions = append(versions, v)
+		}
+		// Prefer the canonical "v" prefixed tag when both spellings exist
+		if !seen || !strings.HasPrefix(existing, "v") {
+			tagByVersion[v.String()] = tag
+		}
+	}
+
+	candidates := constraint.Satisfying(versions)
+	if len(candidates) == 0 {
+		return "", nil, fmt.Errorf("no published version of %s satisfies %q", repository, constraint.String())
+	}
+
+	for _, candidate := range candidates {
+		tag := tagByVersion[candidate.String()]
+		verified, err := verify(repository + ":" + tag)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to verify %s:%s: %w", repository, tag, err)
+		}
+		if verified {
+			return tag, candidate, nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("no verified version of %s satisfies %q (%d unverified candidates)", repository, constraint.String(), len(candidates))
+}
+
+// lockedRepository returns the repository portion of a lockfile OCI reference
+func lockedRepository(ociReference string) string {
+	host, repository, _, err := registry.ParseImageReference(ociReference)
+	if err != nil {
+		return ""
+	}
+	return host + "/" + repository
+}
+
+// satisfiedByLockfile reports whether the locked entry already fulfils the declaration
+func satisfiedByLockfile(entry lockfile.PluginEntry, spec manifest.PluginSpec, constraint *semver.Constraint) bool {
+	return lockedRepository(entry.OCIReference) == spec.Repository && constraint.CheckString(entry.Version)
+}
+
+// resolveManifest installs and pins manifest declarations. Without upgrade, plugins whose
+// locked version already satisfies the declared range are left alone; with upgrade, each
+// declaration is re-resolved to the highest verified version in range. Locked plugins
+// whose policy holds them, or whose minimum update interval has not passed since now,
+// are never re-resolved. It returns the IDs of plugins that were (re)installed.
+func resolveManifest(ctx context.Context, cmdCtx *cmd.CommandContext, m *manifest.Manifest, cfg *config.Config, lockfileData *lockfile.Lockfile, lockfilePath string, upgrade bool, now time.Time) (installed map[string]bool, err error) {
+	installed = make(map[string]bool)
+
+	pluginIDs := make([]string, 0, len(m.Plugins))
+	for pluginID := range m.Plugins {
+		pluginIDs = append(pluginIDs, pluginID)
+	}
+	sort.Strings(pluginIDs)
+
+	var (
+		client    *registry.Client
+		releases  *changelog.Client
+		token     string
+		verifiers = make(map[string]*attestation.AttestationVerifier)
+	)
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Install.Timeout.Duration())
+	defer cancel()
+	defer func() { err = cfg.WrapTimeout(ctx, "install.timeout", err) }()
+
+	for _, pluginID := range pluginIDs {
+		spec := m.Plugins[pluginID]
+		constraint, err := spec.Constraint()
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", pluginID, err)
+		}
+
+		entry, locked := lockfileData.GetPlugin(pluginID)
+		if locked && entry.IsDev() {
+			cmdCtx.Logger.Warn("Dev build installed, not resolving the declared plugin", cmdCtx.Logger.Args("id", pluginID, "source", entry.OCIReference, "hint", "run dev uninstall to return to the declared version"))
+			continue
+		}
+		if locked && !upgrade && satisfiedByLockfile(entry, spec, constraint) {
+			cmdCtx.Logger.Debug("Lockfile satisfies manifest range", cmdCtx.Logger.Args("id", pluginID, "version", entry.Version, "range", spec.Version))
+			continue
+		}
+		if locked && spec.Policy != nil && spec.Policy.Hold {
+			if !satisfiedByLockfile(entry, spec, constraint) {
+				cmdCtx.Logger.Warn("Plugin is held at a version outside its declared range", cmdCtx.Logger.Args("id", pluginID, "version", entry.Version, "range", spec.Version))
+			} else {
+				cmdCtx.Logger.Info("Plugin is held, not updating", cmdCtx.Logger.Args("id", pluginID, "version", entry.Version))
+			}
+			continue
+		}
+		if locked && satisfiedByLockfile(entry, spec, constraint) && !spec.Policy.UpdateDue(entry.InstalledAt, now) {
+			cmdCtx.Logger.Info("Plugin was updated recently, not updating", cmdCtx.Logger.Args("id", pluginID, "version", entry.Version, "minUpdateInterval", spec.Policy.MinUpdateInterval.Duration()))
+			continue
+		}
+
+		pluginCtx, pluginCfg := verify.ApplyPolicy(cmdCtx, cfg, spec.Policy)
+
+		// Lazily create clients so a fully-locked vault needs no network access here
+		if client == nil {
+			registryOpts := registry.DefaultRegistryOpts().
+				WithTimeout(cfg.Registry.Timeout.Duration()).
+				WithCredentials(cfg.Registry.CredentialLookup()).
+				WithDockerCredentials(registry.DockerCredentialStore(cfg.Registry.DockerCredentials)).
+				WithPluginOpts(&plugin.PluginOpts{
+					AnnotationNamespace: cmdCtx.AnnotationNamespace,
+				})
+			if client, err = registry.NewClient(registryOpts); err != nil {
+				return nil, fmt.Errorf("failed to create registry client: %w", err)
+			}
+			if token, err = auth.GetToken(); err != nil {
+				return nil, fmt.Errorf("failed to get authentication token: %w", err)
+			}
+			if cfg.Install.Changelog {
+				releases = changelog.NewClient(githubapi.NewClient(githubapi.DefaultOpts().WithToken(token)), changelog.DefaultOpts())
+			}
+		}
+
+		verifier, ok := verifiers[pluginCtx.TrustedBuilder]
+		if !ok {
+			if verifier, err = attestation.NewAttestationVerifier(token, pluginCtx.TrustedBuilder); err != nil {
+				return nil, fmt.Errorf("failed to create attestation verifier: %w", err)
+			}
+			verifiers[pluginCtx.TrustedBuilder] = verifier
+		}
+
+		cmdCtx.Logger.Info("Resolving version range", cmdCtx.Logger.Args("id", pluginID, "repository", spec.Repository, "range", spec.Version))
+
+		tags, err := client.ListTags(ctx, spec.Repository)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", pluginID, err)
+		}
+
+		tag, version, err := selectVersion(spec.Repository, tags, constraint, func(imageRef string) (bool, error) {
+			result, err := verifyAttestations(ctx, pluginCfg, verifier, imageRef)
+			if err != nil {
+				return false, err
+			}
+			if !result.Valid {
+				cmdCtx.Logger.Debug("Skipping unverified candidate", cmdCtx.Logger.Args("reference", imageRef))
+				return false, nil
+			}
+			if err := verify.CheckPolicy(spec.Policy, result); err != nil {
+				cmdCtx.Logger.Debug("Skipping candidate outside plugin policy", cmdCtx.Logger.Args("reference", imageRef, "reason", err))
+				return false, nil
+			}
+			return result.Found, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", pluginID, err)
+		}
+
+		if locked && lockedRepository(entry.OCIReference) == spec.Repository && entry.Version == version.String() {
+			cmdCtx.Logger.Debug("Plugin already at highest verified version in range", cmdCtx.Logger.Args("id", pluginID, "version", entry.Version))
+			continue
+		}
+
+		cmdCtx.Logger.Info("Resolved version", cmdCtx.Logger.Args("id", pluginID, "version", version.String(), "range", spec.Version))
+		if locked && entry.Version != version.String() {
+			showChangelog(ctx, cmdCtx, releases, pluginID, entry, version.String())
+		}
+
+		imageRef := spec.Repository + ":" + tag
+		if err := addPlugin(ctx, imageRef, pluginCfg, lockfileData, lockfilePath, pluginCtx, replaceInstalled, nil); err != nil {
+			return nil, fmt.Errorf("failed to install %s: %w", imageRef, err)
+		}
+
+		if _, ok := lockfileData.GetPlugin(pluginID); !ok {
+			return nil, fmt.Errorf("manifest declares plugin %s but %s contains a different plugin ID", pluginID, imageRef)
+		}
+
+		installed[pluginID] = true
+	}
+
+	return installed, nil
+}
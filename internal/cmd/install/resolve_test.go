@@ -0,0 +1,181 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/manifest"
+	"github.com/gillisandrew/dragonglass-poc/internal/semver"
+)
+
+func TestSelectVersion(t *testing.T) {
+	repository := "ghcr.io/owner/repo/tasknotes"
+	tags := []string{"17823456", "commit-abc123", "v3.22.0", "v3.23.0", "v3.23.4", "v3.24.0-beta.1", "v4.0.0"}
+
+	tests := []struct {
+		name        string
+		constraint  string
+		unverified  []string
+		verifyErr   bool
+		expectTag   string
+		expectError string
+	}{
+		{
+			name:       "highest in caret range",
+			constraint: "^3.22.0",
+			expectTag:  "v3.23.4",
+		},
+		{
+			name:       "skips unverified candidates",
+			constraint: "^3.22.0",
+			unverified: []string{"v3.23.4"},
+			expectTag:  "v3.23.0",
+		},
+		{
+			name:        "no version in range",
+			constraint:  "^5.0.0",
+			expectError: "no published version",
+		},
+		{
+			name:        "no verified version in range",
+			constraint:  "~3.22.0",
+			unverified:  []string{"v3.22.0"},
+			expectError: "no verified version",
+		},
+		{
+			name:        "verification error aborts resolution",
+			constraint:  "^4.0.0",
+			verifyErr:   true,
+			expectError: "failed to verify",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			constraint, err := semver.ParseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("failed to parse constraint: %v", err)
+			}
+
+			verify := func(imageRef string) (bool, error) {
+				if tt.verifyErr {
+					return false, fmt.Errorf("registry unavailable")
+				}
+				for _, tag := range tt.unverified {
+					if strings.HasSuffix(imageRef, ":"+tag) {
+						return false, nil
+					}
+				}
+				return true, nil
+			}
+
+			tag, _, err := selectVersion(repository, tags, constraint, verify)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tag != tt.expectTag {
+				t.Errorf("expected tag %s, got %s", tt.expectTag, tag)
+			}
+		})
+	}
+}
+
+func TestSatisfiedByLockfile(t *testing.T) {
+	spec := manifest.PluginSpec{Repository: "ghcr.io/owner/repo/tasknotes", Version: "^3.23.0"}
+	constraint, err := spec.Constraint()
+	if err != nil {
+		t.Fatalf("failed to parse constraint: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		entry    lockfile.PluginEntry
+		expected bool
+	}{
+		{
+			name:     "locked version in range",
+			entry:    lockfile.PluginEntry{Version: "3.23.4", OCIReference: "ghcr.io/owner/repo/tasknotes:v3.23.4"},
+			expected: true,
+		},
+		{
+			name:     "locked version out of range",
+			entry:    lockfile.PluginEntry{Version: "3.22.0", OCIReference: "ghcr.io/owner/repo/tasknotes:v3.22.0"},
+			expected: false,
+		},
+		{
+			name:     "locked from a different repository",
+			entry:    lockfile.PluginEntry{Version: "3.23.4", OCIReference: "ghcr.io/fork/repo/tasknotes:v3.23.4"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := satisfiedByLockfile(tt.entry, spec, constraint); got != tt.expected {
+				t.Errorf("expected %t, got %t", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestResolveManifestRespectsUpdatePolicy(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	installedAt := now.Add(-time.Hour)
+
+	m := manifest.NewManifest()
+	m.Plugins["held"] = manifest.PluginSpec{
+		Repository: "ghcr.io/owner/repo/held",
+		Version:    "^2.0.0",
+		Policy:     &manifest.PolicyOverride{Hold: true},
+	}
+	m.Plugins["recent"] = manifest.PluginSpec{
+		Repository: "ghcr.io/owner/repo/recent",
+		Version:    "^1.0.0",
+		Policy:     &manifest.PolicyOverride{MinUpdateInterval: config.Duration(7 * 24 * time.Hour)},
+	}
+
+	lockfileData := lockfile.NewLockfile("/vault")
+	lockfileData.Plugins["held"] = lockfile.PluginEntry{Version: "1.0.0", OCIReference: "ghcr.io/owner/repo/held:1.0.0"}
+	lockfileData.Plugins["recent"] = lockfile.PluginEntry{Version: "1.0.0", OCIReference: "ghcr.io/owner/repo/recent:1.0.0", InstalledAt: &installedAt}
+
+	cmdCtx := &cmd.CommandContext{Logger: pterm.DefaultLogger.WithWriter(os.Stderr).WithLevel(pterm.LogLevelError)}
+
+	// Neither plugin may be re-resolved, so no registry access is needed even on upgrade
+	installed, err := resolveManifest(context.Background(), cmdCtx, m, config.DefaultConfig(), lockfileData, "", true, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(installed) != 0 {
+		t.Errorf("expected held and recently updated plugins to be left alone, got %v", installed)
+	}
+}
+
+func TestUndeclaredPlugins(t *testing.T) {
+	m := manifest.NewManifest()
+	m.Plugins["tasknotes"] = manifest.PluginSpec{Repository: "ghcr.io/owner/repo/tasknotes"}
+
+	lockfileData := lockfile.NewLockfile("/vault")
+	lockfileData.Plugins["tasknotes"] = lockfile.PluginEntry{Name: "TaskNotes"}
+	lockfileData.Plugins["zeta"] = lockfile.PluginEntry{Name: "Zeta"}
+	lockfileData.Plugins["alpha"] = lockfile.PluginEntry{Name: "Alpha"}
+
+	got := undeclaredPlugins(m, lockfileData)
+	if strings.Join(got, ",") != "alpha,zeta" {
+		t.Errorf("expected [alpha zeta], got %v", got)
+	}
+}
@@ -0,0 +1,91 @@
+// ABOUTME: Preservation of a plugin's Obsidian settings (data.json) across updates and removal
+// ABOUTME: Carries settings into replacement installs and keeps them on removal unless purged
+package install
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+)
+
+// pluginInstalled reports whether pluginDir holds an installed plugin, as opposed to
+// nothing or only the settings kept when the plugin was removed
+func pluginInstalled(pluginDir string) bool {
+	entries, err := os.ReadDir(pluginDir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.Name() != plugin.SettingsFileName {
+			return true
+		}
+	}
+	return false
+}
+
+// carrySettings copies the settings of the plugin installed in pluginDir into stagingDir,
+// so they survive the staging directory replacing it. The original is left in place
+// until the swap, so a failed install loses nothing.
+func carrySettings(pluginDir, stagingDir string) error {
+	src, err := os.Open(filepath.Join(pluginDir, plugin.SettingsFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read plugin settings: %w", err)
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read plugin settings: %w", err)
+	}
+
+	dst, err := os.OpenFile(filepath.Join(stagingDir, plugin.SettingsFileName), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to preserve plugin settings: %w", err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return fmt.Errorf("failed to preserve plugin settings: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		return fmt.Errorf("failed to preserve plugin settings: %w", err)
+	}
+	return nil
+}
+
+// removePluginDir removes the plugin installed in pluginDir. Its settings are kept, so
+// reinstalling the plugin restores them, unless purge is set or there are none.
+func removePluginDir(pluginDir string, purge bool) error {
+	if purge {
+		return os.RemoveAll(pluginDir)
+	}
+
+	entries, err := os.ReadDir(pluginDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	kept := false
+	for _, entry := range entries {
+		if entry.Name() == plugin.SettingsFileName && entry.Type().IsRegular() {
+			kept = true
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(pluginDir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	if !kept {
+		return os.Remove(pluginDir)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package install
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+)
+
+func TestInstallArtifactKeepsSettings(t *testing.T) {
+	pluginDir := filepath.Join(t.TempDir(), "plugins", "sample")
+	if err := os.MkdirAll(pluginDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"main.js": "old", plugin.SettingsFileName: `{"theme":"dark"}`} {
+		if err := os.WriteFile(filepath.Join(pluginDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := installArtifact(context.Background(), filepath.Join(t.TempDir(), "tmp"), target{path: pluginDir}, 1, func(dir string) error {
+		return os.WriteFile(filepath.Join(dir, "main.js"), []byte("new"), 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := files[plugin.SettingsFileName]; ok || len(files) != 1 {
+		t.Errorf("expected only main.js to be recorded, got %v", files)
+	}
+	settings, err := os.ReadFile(filepath.Join(pluginDir, plugin.SettingsFileName))
+	if err != nil || string(settings) != `{"theme":"dark"}` {
+		t.Errorf("expected settings to survive the update, got %q (%v)", settings, err)
+	}
+	if info, err := os.Stat(filepath.Join(pluginDir, plugin.SettingsFileName)); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected settings to keep mode 0600, got %v (%v)", info.Mode(), err)
+	}
+	if main, _ := os.ReadFile(filepath.Join(pluginDir, "main.js")); string(main) != "new" {
+		t.Errorf("expected main.js to be replaced, got %q", main)
+	}
+}
+
+func TestRemovePluginDir(t *testing.T) {
+	tests := []struct {
+		name         string
+		settings     bool
+		purge        bool
+		wantSettings bool
+	}{
+		{name: "keeps settings", settings: true, wantSettings: true},
+		{name: "purge deletes settings", settings: true, purge: true},
+		{name: "no settings removes directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pluginDir := filepath.Join(t.TempDir(), "sample")
+			if err := os.MkdirAll(filepath.Join(pluginDir, "lang"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(pluginDir, "main.js"), []byte("code"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.settings {
+				if err := os.WriteFile(filepath.Join(pluginDir, plugin.SettingsFileName), []byte("{}"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := removePluginDir(pluginDir, tt.purge); err != nil {
+				t.Fatal(err)
+			}
+
+			if pluginInstalled(pluginDir) {
+				t.Error("expected plugin to no longer be installed")
+			}
+			_, err := os.Stat(filepath.Join(pluginDir, plugin.SettingsFileName))
+			if tt.wantSettings && err != nil {
+				t.Errorf("expected settings to be kept, got %v", err)
+			}
+			if !tt.wantSettings {
+				if _, err := os.Stat(pluginDir); !os.IsNotExist(err) {
+					t.Errorf("expected plugin directory to be removed, got %v", err)
+				}
+			}
+		})
+	}
+
+	if err := removePluginDir(filepath.Join(t.TempDir(), "missing"), false); err != nil {
+		t.Errorf("expected removing a missing plugin to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+// ABOUTME: Lockfile signature check run before installing a vault's plugin set
+// ABOUTME: Rejects unsigned or changed lockfiles when verification.require_signed_lockfile is set
+package install
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/lock"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+)
+
+// checkLockfileSignature verifies the signature of the lockfile at lockfilePath over data,
+// the contents read from it, before anything is installed. A missing or invalid signature
+// fails when verification.require_signed_lockfile is set; otherwise an invalid one is
+// reported as a warning and an unsigned lockfile is accepted.
+func checkLockfileSignature(ctx *cmd.CommandContext, lockfilePath string, data []byte) error {
+	required := ctx.Config.Verification.RequireSignedLockfile
+	if !required {
+		if _, err := os.Stat(lockfile.SignaturePath(lockfilePath)); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+	}
+
+	statement, err := lock.VerifySignatureData(lockfilePath, data, ctx.Config.Verification)
+	if err != nil {
+		if required {
+			return err
+		}
+		ctx.Logger.Warn("Lockfile signature is not valid", ctx.Logger.Args("error", err.Error()))
+		return nil
+	}
+
+	ctx.Logger.Info("Lockfile signature verified", ctx.Logger.Args("signedAt", statement.Predicate.SignedAt.Format(time.RFC3339)))
+	return nil
+}
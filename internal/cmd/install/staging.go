@@ -0,0 +1,143 @@
+// ABOUTME: Staged plugin directory installation for interruptible installs
+// ABOUTME: Swaps complete plugins into place and retries when a sync service changes them mid-write
+package install
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+	"github.com/gillisandrew/dragonglass-poc/internal/workspace"
+)
+
+// syncIgnorePatterns returns the paths of v sync services should not upload: the
+// workspaces plugins are staged in, dragonglass's download cache and store, and the
+// daemon socket
+func syncIgnorePatterns(v *vault.Vault) []string {
+	return []string{
+		v.StatePattern(vault.TmpDirName) + "/",
+		v.StatePattern(vault.CacheDirName) + "/",
+		v.StatePattern(vault.StoreDirName) + "/",
+		v.StatePattern(vault.DaemonSocketName),
+	}
+}
+
+// installArtifact populates the install target t via populate without ever leaving a
+// partially written artifact behind. Files are written to a private workspace under
+// tmpRoot, which replaces any existing installation only after populate succeeds and ctx
+// is still live, so concurrent installs never share files. On failure or cancellation the
+// workspace is removed and the existing installation is kept. Workspaces left under
+// tmpRoot by a crashed process are swept first. The existing plugin's settings (data.json)
+// are carried over into the new installation.
+//
+// The installed files are hashed again after the swap. If they no longer match what was
+// written, something (typically a sync service replaying an older copy) changed them, and
+// the artifact is written again, up to attempts times. The verified file digests are
+// returned.
+func installArtifact(ctx context.Context, tmpRoot string, t target, attempts int, populate func(stagingDir string) error) (map[string]string, error) {
+	// Best effort: a workspace that cannot be swept now is retried by the next install
+	_, _ = workspace.Sweep(tmpRoot, workspace.StaleAfter, time.Now())
+
+	var changed []string
+	for attempt := 0; attempt < max(attempts, 1); attempt++ {
+		written, err := stageArtifact(ctx, tmpRoot, t, populate)
+		if err != nil {
+			return nil, err
+		}
+
+		installed, err := t.hash()
+		if err != nil {
+			return nil, err
+		}
+		if changed = lockfile.ChangedFiles(written, installed); len(changed) == 0 {
+			return installed, nil
+		}
+	}
+
+	return nil, fmt.Errorf("plugin files changed while being installed after %d attempts (%v); a sync service may be writing to %s", max(attempts, 1), changed, makeRelativePath(t.path))
+}
+
+// stageArtifact performs a single staged install and returns the digests of the files
+// as they were written. Every kind is populated into a staging directory; for a snippet
+// only its stylesheet is then moved into place.
+func stageArtifact(ctx context.Context, tmpRoot string, t target, populate func(stagingDir string) error) (map[string]string, error) {
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", makeRelativePath(filepath.Dir(t.path)), err)
+	}
+
+	ws, err := workspace.New(tmpRoot)
+	if err != nil {
+		return nil, err
+	}
+	defer ws.Remove()
+
+	name := filepath.Base(t.path)
+	stagingDir := ws.Path(name)
+	if t.kind == plugin.KindSnippet {
+		stagingDir = ws.Path("snippet")
+	}
+	if err := os.Mkdir(stagingDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	if err := populate(stagingDir); err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	written, err := plugin.HashFiles(stagingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.kind == plugin.KindSnippet {
+		if err := os.Rename(filepath.Join(stagingDir, plugin.SnippetFile), ws.Path(name)); err != nil {
+			return nil, fmt.Errorf("failed to stage snippet: %w", err)
+		}
+	} else if err := carrySettings(t.path, stagingDir); err != nil {
+		return nil, err
+	}
+
+	if err := ws.Promote(name, t.path); err != nil {
+		return nil, fmt.Errorf("failed to install %s: %w", makeRelativePath(t.path), err)
+	}
+
+	return written, nil
+}
+
+// writeSyncIgnore writes the vault's sync ignore hint when install.sync_ignore is enabled.
+// An existing file is left alone so it can be adapted to the sync service in use.
+func writeSyncIgnore(cfg *config.Config, v *vault.Vault) error {
+	if !cfg.Install.SyncIgnore {
+		return nil
+	}
+
+	path := v.SyncIgnorePath()
+	if _, err := os.Stat(path); err == nil || !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", v.StateDir, err)
+	}
+
+	content := "# Written by dragonglass: paths that sync services should not upload.\n" +
+		"# Add these to your sync service's ignore or excluded folders list.\n"
+	for _, pattern := range syncIgnorePatterns(v) {
+		content += pattern + "\n"
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write sync ignore hint: %w", err)
+	}
+	return nil
+}
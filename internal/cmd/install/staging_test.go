@@ -0,0 +1,182 @@
+package install
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+	"github.com/gillisandrew/dragonglass-poc/internal/workspace"
+)
+
+func TestInstallArtifact(t *testing.T) {
+	writeMain := func(content string) func(string) error {
+		return func(dir string) error {
+			return os.WriteFile(filepath.Join(dir, "main.js"), []byte(content), 0644)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		existing string
+		cancel   bool
+		populate func(string) error
+		wantErr  error
+		wantMain string
+	}{
+		{
+			name:     "fresh install",
+			populate: writeMain("new"),
+			wantMain: "new",
+		},
+		{
+			name:     "replaces existing plugin",
+			existing: "old",
+			populate: writeMain("new"),
+			wantMain: "new",
+		},
+		{
+			name:     "failure keeps existing plugin",
+			existing: "old",
+			populate: func(dir string) error {
+				_ = writeMain("partial")(dir)
+				return errors.New("download failed")
+			},
+			wantErr:  errors.New("download failed"),
+			wantMain: "old",
+		},
+		{
+			name:     "cancellation leaves no partial plugin",
+			cancel:   true,
+			populate: writeMain("partial"),
+			wantErr:  context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pluginsDir := t.TempDir()
+			pluginDir := filepath.Join(pluginsDir, "test-plugin")
+			tmpRoot := filepath.Join(t.TempDir(), "tmp")
+
+			if tt.existing != "" {
+				if err := os.MkdirAll(pluginDir, 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := writeMain(tt.existing)(pluginDir); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			populate := tt.populate
+			if tt.cancel {
+				populate = func(dir string) error {
+					cancel()
+					return tt.populate(dir)
+				}
+			}
+
+			files, err := installArtifact(ctx, tmpRoot, target{path: pluginDir}, 3, populate)
+			if tt.wantErr != nil {
+				if err == nil || (err.Error() != tt.wantErr.Error() && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if files["main.js"] != digest.FromString(tt.wantMain).String() {
+				t.Errorf("expected verified main.js digest, got %v", files)
+			}
+
+			data, err := os.ReadFile(filepath.Join(pluginDir, "main.js"))
+			switch {
+			case tt.wantMain == "" && !os.IsNotExist(err):
+				t.Errorf("expected no plugin directory, got main.js err=%v", err)
+			case tt.wantMain != "" && string(data) != tt.wantMain:
+				t.Errorf("expected main.js %q, got %q (err=%v)", tt.wantMain, data, err)
+			}
+
+			entries, err := os.ReadDir(pluginsDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range entries {
+				if entry.Name() != "test-plugin" {
+					t.Errorf("unexpected entry in plugins directory: %s", entry.Name())
+				}
+			}
+			if entries, _ := os.ReadDir(tmpRoot); len(entries) != 0 {
+				t.Errorf("workspace left behind: %s", entries[0].Name())
+			}
+		})
+	}
+}
+
+func TestInstallArtifactSweepsStaleWorkspaces(t *testing.T) {
+	tmpRoot := filepath.Join(t.TempDir(), "tmp")
+	stale := filepath.Join(tmpRoot, "crashed")
+	if err := os.MkdirAll(filepath.Join(stale, "test-plugin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * workspace.StaleAfter)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	pluginDir := filepath.Join(t.TempDir(), "test-plugin")
+	_, err := installArtifact(context.Background(), tmpRoot, target{path: pluginDir}, 1, func(dir string) error {
+		return os.WriteFile(filepath.Join(dir, "main.js"), []byte("new"), 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("expected stale workspace to be swept, got %v", err)
+	}
+}
+
+func TestWriteSyncIgnore(t *testing.T) {
+	v := vault.New(t.TempDir())
+	cfg := config.DefaultConfig()
+
+	if err := writeSyncIgnore(cfg, v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(v.SyncIgnorePath()); !os.IsNotExist(err) {
+		t.Fatalf("expected no hint while install.sync_ignore is off, got err=%v", err)
+	}
+
+	cfg.Install.SyncIgnore = true
+	if err := writeSyncIgnore(cfg, v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(v.SyncIgnorePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pattern := range syncIgnorePatterns(v) {
+		if !strings.Contains(string(data), pattern+"\n") {
+			t.Errorf("expected hint to list %s, got:\n%s", pattern, data)
+		}
+	}
+
+	// A customised hint is kept
+	if err := os.WriteFile(v.SyncIgnorePath(), []byte("custom\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeSyncIgnore(cfg, v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(v.SyncIgnorePath()); string(data) != "custom\n" {
+		t.Errorf("expected existing hint to be kept, got %q", data)
+	}
+}
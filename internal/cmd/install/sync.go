@@ -0,0 +1,251 @@
+// ABOUTME: Sync command reconciling the vault with the dragonglass.json manifest
+// ABOUTME: Installs missing plugins, upgrades within declared ranges, and optionally prunes
+package install
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/hooks"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/manifest"
+	"github.com/gillisandrew/dragonglass-poc/internal/registry"
+)
+
+// SyncOptions controls how the vault is reconciled against the manifest
+type SyncOptions struct {
+	// Remove lockfile-managed plugins that the manifest no longer declares
+	Prune bool
+
+	// Verify and adopt the new digest of locked tags that were re-pushed
+	AcceptRetag bool
+
+	// Sync even though the vault is frozen
+	UnfreezeOnce bool
+
+	// Delete the settings (data.json) of pruned plugins instead of keeping them
+	Purge bool
+}
+
+func NewSyncCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Reconcile the vault with dragonglass.json",
+		Long: `Reconcile the vault with the plugins declared in dragonglass.json.
+
+Each declared plugin is resolved to the highest verified version inside its
+version range and installed if it differs from the lockfile. Plugins recorded in
+the lockfile but missing from disk are reinstalled. With --prune, plugins managed
+by dragonglass that are no longer declared are removed from the vault and lockfile;
+their settings (data.json) are kept unless --purge is given.
+A reinstalled plugin whose tag was re-pushed fails unless --accept-retag is given.
+Plugins whose manifest policy sets hold are never upgraded, and those with a
+min_update_interval are upgraded at most once per interval. Plugins added from a
+mutable tag (dragonglass add --allow-mutable-tag) are always re-resolved, and the
+artifact the tag now points to is verified and pinned.
+Frozen vaults (see dragonglass freeze) refuse to sync unless --unfreeze-once is
+given; dragonglass install still restores the pinned plugins.
+A plugin that would resolve to a lower version than the locked one, e.g. after its
+range was narrowed, fails unless --allow-downgrade is given.
+Before a plugin is updated, the release notes of the new version are shown from the
+source repository its provenance names (install.changelog turns this off).
+
+Example:
+  dragonglass sync
+  dragonglass sync --prune`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			prune, _ := cmd.Flags().GetBool("prune")
+			acceptRetag, _ := cmd.Flags().GetBool("accept-retag")
+			unfreezeOnce, _ := cmd.Flags().GetBool("unfreeze-once")
+			purge, _ := cmd.Flags().GetBool("purge")
+			ctx.AllowDowngrade, _ = cmd.Flags().GetBool("allow-downgrade")
+			ctx.Logger.Info("Syncing vault with manifest")
+
+			if err := runSync(cmd.Context(), ctx, SyncOptions{Prune: prune, AcceptRetag: acceptRetag, UnfreezeOnce: unfreezeOnce, Purge: purge}); err != nil {
+				ctx.Fail("Sync failed", err)
+			}
+
+			ctx.Logger.Info("Vault is in sync with manifest")
+		},
+	}
+
+	cmd.Flags().Bool("prune", false, "Remove plugins that are no longer declared in the manifest")
+	cmd.Flags().Bool("accept-retag", false, "Verify and adopt the new digest of locked tags that were re-pushed")
+	cmd.Flags().Bool("unfreeze-once", false, "Allow this sync even though the vault is frozen")
+	cmd.Flags().Bool("purge", false, "Also delete the settings (data.json) of pruned plugins")
+	cmd.Flags().Bool("allow-downgrade", false, "Allow replacing plugins with lower versions or older builds")
+	return cmd
+}
+
+func runSync(opCtx context.Context, ctx *cmd.CommandContext, opts SyncOptions) error {
+	v, err := ctx.DiscoverVault()
+	if err != nil {
+		return fmt.Errorf("failed to find vault: %w", err)
+	}
+
+	manifestPath := v.ManifestPath()
+	projectManifest, err := manifest.LoadManifest(manifestPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no %s found at %s", manifest.ManifestFileName, manifestPath)
+		}
+		return fmt.Errorf("failed to load %s: %w", manifest.ManifestFileName, err)
+	}
+
+	lockfilePath, err := ctx.ResolveLockfilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+
+	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load lockfile: %w", err)
+	}
+
+	if err := checkFrozen(ctx, lockfileData, opts.UnfreezeOnce); err != nil {
+		return err
+	}
+
+	// Step 1: Resolve and install declared plugins, upgrading within range
+	upgraded, err := resolveManifest(opCtx, ctx, projectManifest, ctx.Config, lockfileData, lockfilePath, true, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to resolve manifest: %w", err)
+	}
+
+	// Step 2: Remove plugins that are no longer declared
+	removed := map[string]lockfile.PluginEntry{}
+	if opts.Prune {
+		tx := lockfileData.Begin(lockfilePath)
+		defer tx.Rollback()
+
+		for _, pluginID := range undeclaredPlugins(projectManifest, lockfileData) {
+			t, err := entryTarget(v, pluginID, lockfileData.Plugins[pluginID])
+			if err != nil {
+				return err
+			}
+			ctx.Logger.Info("Removing undeclared plugin", ctx.Logger.Args("id", pluginID, "path", makeRelativePath(t.path)))
+
+			if err := t.remove(opts.Purge); err != nil {
+				return fmt.Errorf("failed to remove plugin %s: %w", makeRelativePath(t.path), err)
+			}
+			removed[pluginID] = lockfileData.Plugins[pluginID]
+			if err := tx.RemovePlugin(pluginID); err != nil {
+				return fmt.Errorf("failed to remove plugin from lockfile: %w", err)
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to save lockfile: %w", err)
+		}
+		for pluginID, entry := range removed {
+			postHook(opCtx, ctx, hooks.PostRemove, pluginID, entry)
+		}
+	}
+
+	// Step 3: Re-resolve plugins tracking mutable tags
+	refreshed, err := refreshMutableTags(opCtx, ctx, projectManifest, lockfileData, lockfilePath, upgraded, time.Now())
+	if err != nil {
+		return err
+	}
+	for pluginID := range refreshed {
+		upgraded[pluginID] = true
+	}
+
+	// Step 4: Install locked plugins that are missing from disk
+	restored := 0
+	for pluginID, pluginEntry := range lockfileData.Plugins {
+		if upgraded[pluginID] || pluginEntry.IsDev() {
+			continue
+		}
+
+		t, err := entryTarget(v, pluginID, pluginEntry)
+		if err != nil {
+			return fmt.Errorf("failed to install plugin %s: %w", pluginID, err)
+		}
+		if t.installed() {
+			continue
+		}
+
+		if err := opCtx.Err(); err != nil {
+			return err
+		}
+
+		ctx.Logger.Info("Installing missing plugin", ctx.Logger.Args("name", pluginEntry.Name, "id", pluginID))
+		if err := installLockedPlugin(opCtx, ctx, lockfileData, lockfilePath, pluginID, pluginEntry, t, opts.AcceptRetag); err != nil {
+			return fmt.Errorf("failed to install plugin %s: %w", pluginID, err)
+		}
+		restored++
+	}
+
+	ctx.Logger.Info("Sync summary", ctx.Logger.Args("upgraded", len(upgraded), "restored", restored, "removed", len(removed)))
+
+	return nil
+}
+
+// refreshMutableTags re-resolves the locked plugins whose reference is a mutable tag, and
+// verifies, installs, and pins the artifact the tag now points to when it moved. Plugins
+// in skip, and those the manifest holds or updated too recently, are left alone. It
+// returns the IDs of plugins that were updated.
+func refreshMutableTags(opCtx context.Context, ctx *cmd.CommandContext, m *manifest.Manifest, lockfileData *lockfile.Lockfile, lockfilePath string, skip map[string]bool, now time.Time) (map[string]bool, error) {
+	refreshed := make(map[string]bool)
+
+	var pluginIDs []string
+	for pluginID, entry := range lockfileData.Plugins {
+		if entry.MutableTag && !skip[pluginID] && m.Plugins[pluginID].Policy.UpdateDue(entry.InstalledAt, now) {
+			pluginIDs = append(pluginIDs, pluginID)
+		}
+	}
+	if len(pluginIDs) == 0 {
+		return refreshed, nil
+	}
+	sort.Strings(pluginIDs)
+
+	cfg := ctx.Config
+	client, err := registry.NewClient(registry.DefaultRegistryOpts().
+		WithTimeout(cfg.Registry.Timeout.Duration()).
+		WithCredentials(cfg.Registry.CredentialLookup()).
+		WithDockerCredentials(registry.DockerCredentialStore(cfg.Registry.DockerCredentials)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registry client: %w", err)
+	}
+
+	for _, pluginID := range pluginIDs {
+		entry := lockfileData.Plugins[pluginID]
+		desc, err := client.Resolve(opCtx, entry.OCIReference)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", pluginID, err)
+		}
+		if desc.Digest.String() == entry.OCIDigest {
+			ctx.Logger.Debug("Mutable tag has not moved", ctx.Logger.Args("id", pluginID, "reference", entry.OCIReference))
+			continue
+		}
+
+		ctx.Logger.Info("Mutable tag moved, verifying the new artifact", ctx.Logger.Args("id", pluginID, "reference", entry.OCIReference, "locked", entry.OCIDigest, "current", desc.Digest.String()))
+		if err := addPlugin(opCtx, entry.OCIReference, cfg, lockfileData, lockfilePath, ctx, replaceInstalled, nil); err != nil {
+			return nil, fmt.Errorf("failed to install %s: %w", entry.OCIReference, err)
+		}
+		refreshed[pluginID] = true
+	}
+	return refreshed, nil
+}
+
+// undeclaredPlugins returns lockfile plugin IDs that the manifest does not declare, sorted.
+// Dev builds are never declared and are left out.
+func undeclaredPlugins(m *manifest.Manifest, lockfileData *lockfile.Lockfile) []string {
+	var undeclared []string
+	for pluginID, entry := range lockfileData.Plugins {
+		if _, declared := m.Plugins[pluginID]; !declared && !entry.IsDev() {
+			undeclared = append(undeclared, pluginID)
+		}
+	}
+	sort.Strings(undeclared)
+	return undeclared
+}
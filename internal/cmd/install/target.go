@@ -0,0 +1,59 @@
+// ABOUTME: Install locations of plugins, themes, and CSS snippets in a vault
+// ABOUTME: Plugins and themes are directories; a snippet is a single stylesheet
+package install
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+// target is where an artifact is installed in the vault
+type target struct {
+	kind plugin.Kind
+	path string
+}
+
+// newTarget returns where the artifact id of kind, named name, is installed. A theme's
+// name becomes its directory, so one that would escape the themes directory is refused.
+func newTarget(v *vault.Vault, kind plugin.Kind, id, name string) (target, error) {
+	if kind == plugin.KindTheme && !plugin.ValidThemeName(name) {
+		return target{}, fmt.Errorf("theme name %q cannot be used as a directory name", name)
+	}
+	return target{kind: kind, path: v.InstallPath(kind, id, name)}, nil
+}
+
+// entryTarget returns where the lockfile entry for id is installed
+func entryTarget(v *vault.Vault, id string, entry lockfile.PluginEntry) (target, error) {
+	return newTarget(v, entry.Kind, id, entry.Name)
+}
+
+// installed reports whether the artifact is installed, as opposed to absent or only
+// represented by the settings kept when a plugin was removed
+func (t target) installed() bool {
+	if t.kind == plugin.KindSnippet {
+		info, err := os.Stat(t.path)
+		return err == nil && info.Mode().IsRegular()
+	}
+	return pluginInstalled(t.path)
+}
+
+// remove removes the installed artifact. A plugin's settings are kept unless purge is set.
+func (t target) remove(purge bool) error {
+	if t.kind == plugin.KindSnippet {
+		if err := os.Remove(t.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	return removePluginDir(t.path, purge)
+}
+
+// hash returns the digests of the installed files, keyed as the lockfile records them
+func (t target) hash() (map[string]string, error) {
+	return plugin.HashInstalled(t.kind, t.path)
+}
@@ -0,0 +1,86 @@
+package install
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+func TestEntryTarget(t *testing.T) {
+	v := vault.New(t.TempDir())
+
+	tests := []struct {
+		name    string
+		entry   lockfile.PluginEntry
+		want    string
+		wantErr bool
+	}{
+		{name: "plugin", entry: lockfile.PluginEntry{Name: "Sample"}, want: v.PluginDir("sample")},
+		{name: "theme", entry: lockfile.PluginEntry{Name: "Minimal", Kind: plugin.KindTheme}, want: v.ThemeDir("Minimal")},
+		{name: "snippet", entry: lockfile.PluginEntry{Name: "Wide", Kind: plugin.KindSnippet}, want: v.SnippetPath("sample")},
+		{name: "theme name escaping the vault", entry: lockfile.PluginEntry{Name: "../plugins", Kind: plugin.KindTheme}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := entryTarget(v, "sample", tt.entry)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", target.path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if target.path != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, target.path)
+			}
+		})
+	}
+}
+
+func TestSnippetTarget(t *testing.T) {
+	v := vault.New(t.TempDir())
+	snippet, err := newTarget(v, plugin.KindSnippet, "wide", "Wide")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snippet.installed() {
+		t.Fatal("expected snippet not to be installed yet")
+	}
+
+	files, err := installArtifact(context.Background(), v.TmpDir(), snippet, 1, func(dir string) error {
+		return os.WriteFile(filepath.Join(dir, plugin.SnippetFile), []byte("body { width: 100%; }"), 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := files[plugin.SnippetFile]; !ok || len(files) != 1 {
+		t.Errorf("expected only %s to be recorded, got %v", plugin.SnippetFile, files)
+	}
+	if data, err := os.ReadFile(v.SnippetPath("wide")); err != nil || string(data) != "body { width: 100%; }" {
+		t.Errorf("expected snippet installed as wide.css, got %q (%v)", data, err)
+	}
+	if !snippet.installed() {
+		t.Error("expected snippet to be installed")
+	}
+	if err := checkIntegrity(snippet, lockfile.PluginEntry{Files: files, Integrity: lockfile.Integrity(files)}); err != nil {
+		t.Errorf("expected installed snippet to match its integrity, got %v", err)
+	}
+
+	if err := snippet.remove(false); err != nil {
+		t.Fatal(err)
+	}
+	if snippet.installed() {
+		t.Error("expected snippet to be removed")
+	}
+	if err := snippet.remove(false); err != nil {
+		t.Errorf("expected removing a missing snippet to succeed, got %v", err)
+	}
+}
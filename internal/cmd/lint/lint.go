@@ -0,0 +1,140 @@
+// ABOUTME: Lint-artifact command checking a plugin dist directory or pushed artifact before publish
+// ABOUTME: Prints a compatibility report with stable finding codes and fails on errors
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/push"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
+	"github.com/gillisandrew/dragonglass-poc/internal/lint"
+	"github.com/gillisandrew/dragonglass-poc/internal/registry"
+	"github.com/gillisandrew/dragonglass-poc/internal/ui"
+)
+
+// LintOptions configures the lint-artifact command
+type LintOptions struct {
+	// Dist directory, or image reference of a pushed artifact
+	Target string
+
+	// Print the report as JSON
+	JSON bool
+}
+
+func NewLintArtifactCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint-artifact <dist-dir|image-ref>",
+		Short: "Check a plugin artifact against install requirements",
+		Long: `Check a built plugin directory or a pushed artifact against what dragonglass
+needs to install it, and print a compatibility report.
+
+A directory is checked for main.js and manifest.json, manifest.json fields, and
+file sizes. An image reference is checked for plugin annotations under the
+configured annotation namespace, layer titles and media types, layer sizes, and
+whether the tag names the manifest version.
+
+Each finding has a stable code. Errors make the artifact incompatible and the
+command exit non-zero; warnings do not.
+
+Example:
+  dragonglass lint-artifact ./dist
+  dragonglass lint-artifact ghcr.io/me/my-plugin:1.2.3 --json`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := LintOptions{Target: args[0]}
+			opts.JSON, _ = cmd.Flags().GetBool("json")
+			opts.JSON = opts.JSON || ctx.Config.Output.Format == "json"
+
+			if err := runLint(cmd.Context(), ctx, opts); err != nil {
+				ctx.Fail("Artifact lint failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("json", false, "Print the report as JSON")
+	return cmd
+}
+
+func runLint(opCtx context.Context, ctx *cmd.CommandContext, opts LintOptions) error {
+	report, err := lintTarget(opCtx, ctx, opts.Target)
+	if err != nil {
+		return err
+	}
+
+	if err := printReport(report, opts.JSON); err != nil {
+		return err
+	}
+
+	if !report.Compatible() {
+		return fmt.Errorf("%s is not installable: %d error(s)", report.Target, report.Errors())
+	}
+	ctx.Logger.Info("Artifact is installable", ctx.Logger.Args("target", report.Target, "warnings", len(report.Findings)))
+	return nil
+}
+
+// lintTarget checks target as a dist directory when it exists on disk, and as an image
+// reference otherwise
+func lintTarget(opCtx context.Context, ctx *cmd.CommandContext, target string) (*lint.Report, error) {
+	if info, err := os.Stat(target); err == nil {
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%s is not a directory", target)
+		}
+		return lint.CheckDist(target), nil
+	}
+
+	_, _, tag, err := registry.ParseImageReference(target)
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither a directory nor an image reference: %w", target, err)
+	}
+	if strings.Contains(tag, ":") {
+		// Pinned by digest, so there is no tag to compare with the version
+		tag = ""
+	}
+
+	client, err := push.NewRegistryClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	manifest, _, digest, err := client.GetManifest(opCtx, target)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Logger.Debug("Fetched manifest", ctx.Logger.Args("reference", target, "digest", digest))
+
+	return lint.CheckManifest(target, manifest, ctx.AnnotationNamespace, tag), nil
+}
+
+func printReport(report *lint.Report, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	if len(report.Findings) == 0 {
+		ui.Success(i18n.T("lint.no_findings", report.Target))
+		return nil
+	}
+	return pterm.DefaultTable.WithHasHeader().WithData(reportRows(report)).Render()
+}
+
+func reportRows(report *lint.Report) pterm.TableData {
+	rows := pterm.TableData{{
+		i18n.T("lint.header.code"),
+		i18n.T("lint.header.severity"),
+		i18n.T("lint.header.file"),
+		i18n.T("lint.header.message"),
+	}}
+	for _, f := range report.Findings {
+		rows = append(rows, []string{f.Code, string(f.Severity), f.File, f.Message})
+	}
+	return rows
+}
@@ -0,0 +1,41 @@
+package lint
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/lint"
+)
+
+func TestLintTargetDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(`{"id": "my-plugin", "name": "My Plugin", "version": "1.2.3"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := lintTarget(context.Background(), &cmd.CommandContext{}, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Compatible() || report.Findings[0].Code != lint.CodeMissingFile {
+		t.Errorf("expected a missing main.js error, got %+v", report.Findings)
+	}
+
+	_, err = lintTarget(context.Background(), &cmd.CommandContext{}, filepath.Join(dir, "manifest.json"))
+	if err == nil || !strings.Contains(err.Error(), "is not a directory") {
+		t.Errorf("expected a not a directory error, got %v", err)
+	}
+}
+
+func TestReportRows(t *testing.T) {
+	report := &lint.Report{Findings: []lint.Finding{{Code: lint.CodeTagMismatch, Severity: lint.SeverityWarning, Message: "tag"}}}
+
+	rows := reportRows(report)
+	if len(rows) != 2 || rows[1][0] != lint.CodeTagMismatch || rows[1][1] != "warning" {
+		t.Errorf("unexpected rows %v", rows)
+	}
+}
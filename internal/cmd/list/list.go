@@ -4,54 +4,132 @@ package list
 
 import (
 	"fmt"
+	"net/url"
 	"os"
-	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 
 	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
-	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
 	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/output"
+	"github.com/gillisandrew/dragonglass-poc/internal/semver"
 )
 
+var (
+	filterValues = []string{"verified", "unverified", "dev", "error"}
+	sortValues   = []string{"name", "version", "installed"}
+)
+
+// ListOptions selects and orders the plugins shown by the list command
+type ListOptions struct {
+	// Only show this plugin (empty for all)
+	PluginID string
+
+	// One of filterValues (empty for no filtering)
+	Filter string
+
+	// One of sortValues
+	Sort string
+
+	// Print the full lockfile entry for each plugin
+	Details bool
+
+	// List the tags published under this registry namespace instead of the lockfile
+	Remote string
+}
+
 func NewListCommand(ctx *cmd.CommandContext) *cobra.Command {
-	return &cobra.Command{
-		Use:   "list",
+	cmd := &cobra.Command{
+		Use:   "list [plugin-id]",
 		Short: "List installed verified plugins",
 		Long: `List all plugins installed through dragonglass in the current vault.
 Displays plugin names, versions, installation status, and verification details
-from the lockfile.`,
+from the lockfile. The trust column summarizes each plugin's SLSA level, builder,
+and vulnerability count, e.g. "L3 / gh-actions / 0 vulns".
+
+Pass a plugin ID to show a single plugin, and --details to print the full
+lockfile entry including verification state, digests, and file hashes.
+--output selects text, json, yaml, or a Go template executed for each plugin
+with the JSON field names, e.g. go-template='{{.id}} {{.version}}'; it
+defaults to output.format.
+
+With --remote, the lockfile is not read. Instead every tag of the plugin
+repositories under a GHCR namespace is listed with whether provenance and SBOM
+referrers are attached to it. The referrers are not verified; use
+dragonglass verify for that.
+
+Example:
+  dragonglass list --filter unverified
+  dragonglass list --sort installed
+  dragonglass list obsidian-git --details
+  dragonglass list -o go-template='{{.id}}@{{.version}}'
+  dragonglass list --remote ghcr.io/gillisandrew/dragonglass-poc`,
+		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := runListCommand(ctx); err != nil {
-				ctx.Logger.Error("List command failed", ctx.Logger.Args("error", err))
-				os.Exit(1)
+			opts := ListOptions{}
+			opts.Filter, _ = cmd.Flags().GetString("filter")
+			opts.Sort, _ = cmd.Flags().GetString("sort")
+			opts.Details, _ = cmd.Flags().GetBool("details")
+			opts.Remote, _ = cmd.Flags().GetString("remote")
+			if len(args) == 1 {
+				opts.PluginID = args[0]
+			}
+			renderer, err := ctx.Renderer(cmd)
+			if err != nil {
+				ctx.Fail("List command failed", err)
+			}
+
+			if opts.Remote != "" {
+				if opts.PluginID != "" || opts.Filter != "" || opts.Details {
+					ctx.Fail("List command failed", fmt.Errorf("--remote cannot be combined with a plugin ID, --filter, or --details"))
+				}
+				if err := runRemoteList(cmd.Context(), ctx, opts.Remote, renderer); err != nil {
+					ctx.Fail("List command failed", err)
+				}
+				return
+			}
+
+			if err := runListCommand(ctx, opts, renderer); err != nil {
+				ctx.Fail("List command failed", err)
 			}
 		},
 	}
+
+	cmd.Flags().String("filter", "", "Only show plugins that are "+strings.Join(filterValues, ", "))
+	cmd.Flags().String("sort", "name", "Sort plugins by "+strings.Join(sortValues, ", "))
+	cmd.Flags().Bool("details", false, "Print the full lockfile entry for each plugin")
+	cmd.Flags().String("remote", "", "List published tags and their provenance and SBOM referrers under a GHCR namespace, e.g. ghcr.io/owner")
+	output.AddFlag(cmd)
+	return cmd
 }
 
-func runListCommand(ctx *cmd.CommandContext) error {
-	// Load configuration
-	configOpts := config.DefaultConfigOpts()
-	if ctx.ConfigPath != "" {
-		configOpts = configOpts.WithConfigPath(ctx.ConfigPath)
+// pluginListing is a lockfile entry together with its ID, as shown by the list command
+type pluginListing struct {
+	ID string `json:"id"`
+	lockfile.PluginEntry
+}
+
+func runListCommand(ctx *cmd.CommandContext, opts ListOptions, renderer *output.Renderer) error {
+	if err := validateChoice("filter", opts.Filter, filterValues); err != nil {
+		return err
 	}
-	configManager := config.NewConfigManager(configOpts)
-	cfg, _, err := configManager.LoadConfig()
-	if err != nil {
-		ctx.Logger.Warn("Failed to load configuration, using defaults", ctx.Logger.Args("error", err))
-		cfg = config.DefaultConfig()
+	if err := validateChoice("sort", opts.Sort, sortValues); err != nil {
+		return err
 	}
 
-	// Find dragonglass directory and load lockfile (same logic as install/add commands)
-	dragonglassDir, err := findDragonglassDirectory()
+	// Locate the lockfile (same logic as install/add commands)
+	lockfilePath, err := ctx.ResolveLockfilePath()
 	if err != nil {
-		return fmt.Errorf("failed to find dragonglass directory: %w", err)
+		return fmt.Errorf("failed to locate lockfile: %w", err)
 	}
 
-	lockfilePath := filepath.Join(dragonglassDir, "dragonglass-lock.json")
-
 	// Check if lockfile exists
 	if _, err := os.Stat(lockfilePath); os.IsNotExist(err) {
 		return fmt.Errorf("no lockfile found at %s (run 'dragonglass add' to add plugins first)", lockfilePath)
@@ -63,81 +141,335 @@ func runListCommand(ctx *cmd.CommandContext) error {
 		return fmt.Errorf("failed to load lockfile: %w", err)
 	}
 
-	if len(lockfileData.Plugins) == 0 {
-		ctx.Logger.Info("No verified plugins installed in this vault")
+	plugins, err := selectPlugins(lockfileData, opts)
+	if err != nil {
+		return err
+	}
+
+	if !renderer.Text() {
+		return render(renderer, plugins, opts.Details)
+	}
+
+	if len(plugins) == 0 {
+		if opts.Filter != "" {
+			ctx.Logger.Info("No plugins match the filter", ctx.Logger.Args("filter", opts.Filter))
+		} else {
+			ctx.Logger.Info("No verified plugins installed in this vault")
+		}
 		return nil
 	}
 
-	if cfg.Output.Format == "json" {
-		ctx.Logger.Warn("JSON output not yet implemented", ctx.Logger.Args("pluginCount", len(lockfileData.Plugins)))
+	if opts.Details {
+		for _, p := range plugins {
+			renderDetails(p)
+		}
 		return nil
 	}
 
 	// Build table data
 	tableData := pterm.TableData{
-		{"ID", "NAME", "VERSION", "VERIFIED", "STATUS", "OCI REFERENCE"},
+		{
+			i18n.T("list.header.id"),
+			i18n.T("list.header.name"),
+			i18n.T("list.header.version"),
+			i18n.T("list.header.verified"),
+			i18n.T("list.header.status"),
+			i18n.T("list.header.trust"),
+			i18n.T("list.header.oci_reference"),
+		},
 	}
 
-	for pluginID, plugin := range lockfileData.Plugins {
-		status := "OK"
-		if len(plugin.VerificationState.Errors) > 0 {
-			status = "ERROR"
-		} else if len(plugin.VerificationState.Warnings) > 0 {
-			status = "WARNING"
-		}
-
-		verifiedStatus := "No"
-		if plugin.VerificationState.ProvenanceVerified && plugin.VerificationState.SBOMVerified {
-			verifiedStatus = "Yes"
-		}
-
+	for _, p := range plugins {
 		tableData = append(tableData, []string{
-			pluginID,
-			plugin.Name,
-			plugin.Version,
-			verifiedStatus,
-			status,
-			plugin.OCIReference,
+			p.ID,
+			p.Name,
+			p.Version,
+			yesNo(p.PluginEntry.Verified()),
+			pluginStatus(p.PluginEntry),
+			trustSummary(p.VerificationState),
+			p.OCIReference,
 		})
 	}
 
 	// Render table with pterm
 	pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
 
-	ctx.Logger.Info("Plugin list summary", ctx.Logger.Args("total", len(lockfileData.Plugins), "lockfile", lockfilePath))
+	ctx.Logger.Info("Plugin list summary", ctx.Logger.Args("shown", len(plugins), "total", len(lockfileData.Plugins), "lockfile", lockfilePath))
 
 	return nil
 }
 
-// findDragonglassDirectory searches for or creates .dragonglass directory from current directory up
-func findDragonglassDirectory() (string, error) {
-	currentDir, err := os.Getwd()
-	if err != nil {
-		return "", fmt.Errorf("failed to get current directory: %w", err)
+// selectPlugins applies the plugin ID, filter, and sort options to the lockfile entries
+func selectPlugins(lockfileData *lockfile.Lockfile, opts ListOptions) ([]pluginListing, error) {
+	var plugins []pluginListing
+
+	if opts.PluginID != "" {
+		entry, ok := lockfileData.GetPlugin(opts.PluginID)
+		if !ok {
+			return nil, dgerrors.Mark(fmt.Errorf("plugin %s not found in lockfile", opts.PluginID), dgerrors.ErrNotInLockfile)
+		}
+		plugins = append(plugins, pluginListing{ID: opts.PluginID, PluginEntry: entry})
+	} else {
+		for pluginID, entry := range lockfileData.Plugins {
+			plugins = append(plugins, pluginListing{ID: pluginID, PluginEntry: entry})
+		}
 	}
 
-	// Search up the directory tree for .dragonglass or create it at the same level as .obsidian
-	for {
-		// Check if .obsidian exists to determine if this is an Obsidian vault
-		obsidianPath := filepath.Join(currentDir, ".obsidian")
-		if info, err := os.Stat(obsidianPath); err == nil && info.IsDir() {
-			// Found .obsidian, so create/use .dragonglass at the same level
-			dragonglassPath := filepath.Join(currentDir, ".dragonglass")
+	filtered := plugins[:0]
+	for _, p := range plugins {
+		if matchesFilter(p.PluginEntry, opts.Filter) {
+			filtered = append(filtered, p)
+		}
+	}
 
-			// Create .dragonglass directory if it doesn't exist
-			if err := os.MkdirAll(dragonglassPath, 0755); err != nil {
-				return "", fmt.Errorf("failed to create .dragonglass directory: %w", err)
+	sortPlugins(filtered, opts.Sort)
+	return filtered, nil
+}
+
+func matchesFilter(entry lockfile.PluginEntry, filter string) bool {
+	switch filter {
+	case "verified":
+		return entry.Verified()
+	case "unverified":
+		return !entry.Verified()
+	case "dev":
+		return entry.IsDev()
+	case "error":
+		return pluginStatus(entry) == "ERROR"
+	default:
+		return true
+	}
+}
+
+// sortPlugins orders plugins by the given key, falling back to ID for a stable order
+func sortPlugins(plugins []pluginListing, by string) {
+	sort.SliceStable(plugins, func(i, j int) bool {
+		a, b := plugins[i], plugins[j]
+		switch by {
+		case "version":
+			if c := compareVersions(a.Version, b.Version); c != 0 {
+				return c < 0
+			}
+		case "installed":
+			// Most recently installed first; entries without a timestamp last
+			ta, tb := installedAt(a.PluginEntry), installedAt(b.PluginEntry)
+			if !ta.Equal(tb) {
+				return ta.After(tb)
 			}
+		default:
+			if na, nb := strings.ToLower(a.Name), strings.ToLower(b.Name); na != nb {
+				return na < nb
+			}
+		}
+		return a.ID < b.ID
+	})
+}
+
+// compareVersions compares semantic versions, falling back to string order for others
+func compareVersions(a, b string) int {
+	va, errA := semver.Parse(a)
+	vb, errB := semver.Parse(b)
+	if errA == nil && errB == nil {
+		return va.Compare(vb)
+	}
+	return strings.Compare(a, b)
+}
 
-			return dragonglassPath, nil
+func installedAt(entry lockfile.PluginEntry) time.Time {
+	if entry.InstalledAt != nil {
+		return *entry.InstalledAt
+	}
+	return time.Time{}
+}
+
+func pluginStatus(entry lockfile.PluginEntry) string {
+	switch {
+	case entry.IsDev():
+		return "DEV"
+	case len(entry.VerificationState.Errors) > 0:
+		return "ERROR"
+	case len(entry.VerificationState.Warnings) > 0:
+		return "WARNING"
+	default:
+		return "OK"
+	}
+}
+
+// trustSummary condenses the recorded verification state into the SLSA level, builder,
+// and vulnerability count, e.g. "L3 / gh-actions / 0 vulns"
+func trustSummary(state lockfile.VerificationState) string {
+	vulns := i18n.T("list.trust.not_scanned")
+	if v := state.Vulnerabilities; v != nil {
+		vulns = i18n.T("list.trust.vulns", v.Total())
+	}
+	return fmt.Sprintf("L%d / %s / %s", state.SLSALevel, builderShortName(state.BuilderID), vulns)
+}
+
+// builderShortName names the CI system behind a builder identity, e.g. "gh-actions" for a
+// GitHub Actions workflow URL. Other builders are shown by host.
+func builderShortName(builderID string) string {
+	switch {
+	case builderID == "":
+		return "-"
+	case strings.HasPrefix(builderID, "https://github.com/"):
+		return "gh-actions"
+	case strings.HasPrefix(builderID, "https://gitlab.com/"):
+		return "gitlab-ci"
+	}
+	if u, err := url.Parse(builderID); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return builderID
+}
+
+func validateChoice(flag, value string, allowed []string) error {
+	if value == "" {
+		return nil
+	}
+	for _, a := range allowed {
+		if value == a {
+			return nil
 		}
+	}
+	return fmt.Errorf("invalid --%s %q (must be one of %s)", flag, value, strings.Join(allowed, ", "))
+}
 
-		parent := filepath.Dir(currentDir)
-		if parent == currentDir {
-			break // reached root
+// render prints plugins to stdout; without details only the summary columns are included
+func render(renderer *output.Renderer, plugins []pluginListing, details bool) error {
+	if details {
+		if plugins == nil {
+			plugins = []pluginListing{}
 		}
-		currentDir = parent
+		return renderer.Render(os.Stdout, plugins)
 	}
 
-	return "", fmt.Errorf(".obsidian directory not found in current path or parent directories (required to determine vault location)")
+	type summary struct {
+		ID           string `json:"id"`
+		Name         string `json:"name"`
+		Version      string `json:"version"`
+		Verified     bool   `json:"verified"`
+		Status       string `json:"status"`
+		Enabled      bool   `json:"enabled"`
+		OCIReference string `json:"oci_reference"`
+	}
+	summaries := make([]summary, 0, len(plugins))
+	for _, p := range plugins {
+		summaries = append(summaries, summary{
+			ID:           p.ID,
+			Name:         p.Name,
+			Version:      p.Version,
+			Verified:     p.PluginEntry.Verified(),
+			Status:       pluginStatus(p.PluginEntry),
+			Enabled:      !p.Disabled,
+			OCIReference: p.OCIReference,
+		})
+	}
+	return renderer.Render(os.Stdout, summaries)
+}
+
+// renderDetails prints the full lockfile entry for a plugin as a key/value table
+func renderDetails(p pluginListing) {
+	state := p.VerificationState
+
+	rows := pterm.TableData{
+		{i18n.T("list.detail.id"), p.ID},
+		{i18n.T("list.detail.name"), p.Name},
+		{i18n.T("list.detail.version"), p.Version},
+		{i18n.T("list.detail.status"), pluginStatus(p.PluginEntry)},
+		{i18n.T("list.detail.enabled"), yesNo(!p.Disabled)},
+		{i18n.T("list.detail.oci_reference"), p.OCIReference},
+		{i18n.T("list.detail.oci_digest"), p.OCIDigest},
+	}
+	if blobs := p.OCIBlobs; blobs != nil {
+		rows = append(rows, []string{i18n.T("list.detail.config_digest"), blobs.Config})
+		for i, layer := range blobs.Layers {
+			rows = append(rows, []string{i18n.T("list.detail.layer_digest", i), layer})
+		}
+	}
+	rows = append(rows, pterm.TableData{
+		{i18n.T("list.detail.first_installed"), formatTime(p.FirstInstalledAt)},
+		{i18n.T("list.detail.installed"), formatTime(p.InstalledAt)},
+		{i18n.T("list.detail.provenance_verified"), yesNo(state.ProvenanceVerified)},
+		{i18n.T("list.detail.builder"), state.BuilderID},
+		{i18n.T("list.detail.slsa_level"), strconv.Itoa(state.SLSALevel)},
+	}...)
+	if state.SourceCommit != "" {
+		rows = append(rows,
+			[]string{i18n.T("list.detail.source_repository"), state.SourceRepository},
+			[]string{i18n.T("list.detail.source_commit"), state.SourceCommit},
+		)
+		if tree := state.SourceTreeURL(); tree != "" {
+			rows = append(rows, []string{i18n.T("list.detail.source_tree"), tree})
+		}
+	}
+	rows = append(rows, pterm.TableData{
+		{i18n.T("list.detail.sbom_verified"), yesNo(state.SBOMVerified)},
+		{i18n.T("list.detail.sbom_format"), state.SBOMFormat},
+		{i18n.T("list.detail.vuln_scan_passed"), yesNo(state.VulnScanPassed)},
+	}...)
+	if v := state.Vulnerabilities; v != nil {
+		rows = append(rows, []string{i18n.T("list.detail.vulnerabilities"), i18n.T("list.detail.vulnerability_counts", v.Critical, v.High, v.Medium, v.Low)})
+	}
+	rows = append(rows,
+		[]string{i18n.T("list.detail.verified"), formatTime(state.VerifiedAt)},
+		[]string{i18n.T("list.detail.policy_hash"), state.PolicyHash},
+	)
+
+	// Newest first, like a log
+	for i := len(p.VerificationHistory) - 1; i >= 0; i-- {
+		record := p.VerificationHistory[i]
+		rows = append(rows, []string{i18n.T("list.detail.history", record.VerifiedAt.Format(time.RFC3339)), historyOutcome(record)})
+	}
+
+	fileNames := make([]string, 0, len(p.Files))
+	for name := range p.Files {
+		fileNames = append(fileNames, name)
+	}
+	sort.Strings(fileNames)
+	for _, name := range fileNames {
+		rows = append(rows, []string{i18n.T("list.detail.file", name), p.Files[name]})
+	}
+
+	for _, warning := range state.Warnings {
+		rows = append(rows, []string{i18n.T("list.detail.warning"), warning})
+	}
+	for _, e := range state.Errors {
+		rows = append(rows, []string{i18n.T("list.detail.error"), e})
+	}
+
+	if p.Metadata.Author != "" {
+		rows = append(rows, []string{i18n.T("list.detail.author"), p.Metadata.Author})
+	}
+	if p.Metadata.Description != "" {
+		rows = append(rows, []string{i18n.T("list.detail.description"), p.Metadata.Description})
+	}
+
+	pterm.DefaultSection.Println(p.Name)
+	pterm.DefaultTable.WithData(rows).Render()
+}
+
+// historyOutcome describes a past verification for the details table
+func historyOutcome(record lockfile.VerificationRecord) string {
+	outcome := i18n.T("list.history.passed", record.Version)
+	if !record.Passed() {
+		outcome = i18n.T("list.history.failed", record.Version)
+	}
+	if v := record.Vulnerabilities; v != nil && v.Total() > 0 {
+		outcome += ", " + i18n.T("list.detail.vulnerability_counts", v.Critical, v.High, v.Medium, v.Low)
+	}
+	return outcome
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return i18n.T("list.yes")
+	}
+	return i18n.T("list.no")
 }
@@ -0,0 +1,155 @@
+package list
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/output"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+func testLockfile() *lockfile.Lockfile {
+	older := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	lf := lockfile.NewLockfile("/vault")
+	lf.Plugins["calendar"] = lockfile.PluginEntry{
+		Name:              "Calendar",
+		Version:           "1.10.0",
+		VerificationState: lockfile.VerificationState{ProvenanceVerified: true, SBOMVerified: true},
+		InstalledAt:       &older,
+	}
+	lf.Plugins["dataview"] = lockfile.PluginEntry{
+		Name:              "Dataview",
+		Version:           "1.9.0",
+		VerificationState: lockfile.VerificationState{ProvenanceVerified: true},
+		InstalledAt:       &newer,
+	}
+	lf.Plugins["broken"] = lockfile.PluginEntry{
+		Name:              "broken plugin",
+		Version:           "0.1.0",
+		VerificationState: lockfile.VerificationState{Errors: []string{"attestations not found"}},
+	}
+	return lf
+}
+
+func ids(plugins []pluginListing) string {
+	var out []string
+	for _, p := range plugins {
+		out = append(out, p.ID)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestSelectPlugins(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     ListOptions
+		expected string
+	}{
+		{name: "sort by name ignores case", opts: ListOptions{Sort: "name"}, expected: "broken,calendar,dataview"},
+		{name: "sort by version uses semver", opts: ListOptions{Sort: "version"}, expected: "broken,dataview,calendar"},
+		{name: "sort by installed puts newest first", opts: ListOptions{Sort: "installed"}, expected: "dataview,calendar,broken"},
+		{name: "filter verified", opts: ListOptions{Filter: "verified", Sort: "name"}, expected: "calendar"},
+		{name: "filter unverified", opts: ListOptions{Filter: "unverified", Sort: "name"}, expected: "broken,dataview"},
+		{name: "filter error", opts: ListOptions{Filter: "error", Sort: "name"}, expected: "broken"},
+		{name: "single plugin", opts: ListOptions{PluginID: "dataview", Sort: "name"}, expected: "dataview"},
+		{name: "single plugin excluded by filter", opts: ListOptions{PluginID: "dataview", Filter: "verified", Sort: "name"}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugins, err := selectPlugins(testLockfile(), tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := ids(plugins); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSelectPluginsUnknownID(t *testing.T) {
+	_, err := selectPlugins(testLockfile(), ListOptions{PluginID: "missing"})
+	if !errors.Is(err, dgerrors.ErrNotInLockfile) {
+		t.Errorf("expected ErrNotInLockfile, got %v", err)
+	}
+}
+
+func TestValidateChoice(t *testing.T) {
+	if err := validateChoice("filter", "", filterValues); err != nil {
+		t.Errorf("expected empty value to be accepted, got %v", err)
+	}
+	if err := validateChoice("sort", "installed", sortValues); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validateChoice("sort", "size", sortValues); err == nil || !strings.Contains(err.Error(), "name, version, installed") {
+		t.Errorf("expected error listing allowed values, got %v", err)
+	}
+}
+
+func TestTrustSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    lockfile.VerificationState
+		expected string
+	}{
+		{
+			name: "github actions",
+			state: lockfile.VerificationState{
+				SLSALevel:       3,
+				BuilderID:       "https://github.com/owner/repo/.github/workflows/build.yml@refs/heads/main",
+				Vulnerabilities: &lockfile.VulnerabilityCounts{},
+			},
+			expected: "L3 / gh-actions / 0 vulns",
+		},
+		{
+			name: "other builder",
+			state: lockfile.VerificationState{
+				SLSALevel:       2,
+				BuilderID:       "https://builder.example.com/v1",
+				Vulnerabilities: &lockfile.VulnerabilityCounts{High: 1, Low: 2},
+			},
+			expected: "L2 / builder.example.com / 3 vulns",
+		},
+		{name: "unverified", state: lockfile.VerificationState{}, expected: "L0 / - / not scanned"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trustSummary(tt.state); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestListMissingLockfile(t *testing.T) {
+	// A --lockfile that does not exist fails even inside a vault, rather than listing nothing
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".obsidian"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	missing := vault.New(root).LockfilePath()
+
+	renderer, err := output.New("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := &cmd.CommandContext{LockfilePath: missing, Logger: pterm.DefaultLogger.WithWriter(io.Discard)}
+	err = runListCommand(ctx, ListOptions{Sort: "name"}, renderer)
+	if err == nil || !strings.Contains(err.Error(), "lockfile not found: "+missing) {
+		t.Errorf("expected a missing --lockfile to fail, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+// ABOUTME: Remote listing of the plugin tags published under a GHCR namespace
+// ABOUTME: Shows which tags have provenance and SBOM referrers so curators can triage them
+package list
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/auth"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
+	"github.com/gillisandrew/dragonglass-poc/internal/output"
+	"github.com/gillisandrew/dragonglass-poc/internal/registry"
+)
+
+// referrerTagPattern matches the tags registries without the referrers API use to index
+// referrers; they are not plugin versions
+var referrerTagPattern = regexp.MustCompile(`^sha256-[a-f0-9]{64}(\..*)?$`)
+
+// remoteRegistry is the part of the registry client the remote listing uses
+type remoteRegistry interface {
+	ListRepositories(ctx context.Context, namespace string) ([]string, error)
+	ListTags(ctx context.Context, repository string) ([]string, error)
+	ReferrerStatus(ctx context.Context, imageRef string) (registry.ReferrerStatus, error)
+}
+
+// remoteListing is a published tag and the referrers attached to it
+type remoteListing struct {
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+	registry.ReferrerStatus
+
+	// Why the tag's referrers could not be listed
+	Error string `json:"error,omitempty"`
+}
+
+func runRemoteList(opCtx context.Context, ctx *cmd.CommandContext, namespace string, renderer *output.Renderer) error {
+	cfg := ctx.Config
+
+	registryOpts := registry.DefaultRegistryOpts().
+		WithTimeout(cfg.Registry.Timeout.Duration()).
+		WithCredentials(cfg.Registry.CredentialLookup()).
+		WithDockerCredentials(registry.DockerCredentialStore(cfg.Registry.DockerCredentials))
+	if ctx.GitHubToken != "" {
+		registryOpts = registryOpts.WithAuthProvider(auth.NewAuthClient(auth.DefaultAuthOpts().WithToken(ctx.GitHubToken)))
+	}
+	client, err := registry.NewClient(registryOpts)
+	if err != nil {
+		return fmt.Errorf("failed to create registry client: %w", err)
+	}
+
+	listings, err := listRemote(opCtx, ctx, client, namespace)
+	if err != nil {
+		return err
+	}
+
+	if !renderer.Text() {
+		if listings == nil {
+			listings = []remoteListing{}
+		}
+		return renderer.Render(os.Stdout, listings)
+	}
+
+	if len(listings) == 0 {
+		ctx.Logger.Info("No plugin tags published under namespace", ctx.Logger.Args("namespace", namespace))
+		return nil
+	}
+
+	tableData := pterm.TableData{
+		{
+			i18n.T("list.header.repository"),
+			i18n.T("list.header.tag"),
+			i18n.T("list.header.digest"),
+			i18n.T("list.header.provenance"),
+			i18n.T("list.header.sbom"),
+		},
+	}
+	provenance, sbom := 0, 0
+	for _, l := range listings {
+		if l.Error != "" {
+			tableData = append(tableData, []string{l.Repository, l.Tag, "-", i18n.T("list.remote.error"), i18n.T("list.remote.error")})
+			continue
+		}
+		if l.Provenance {
+			provenance++
+		}
+		if l.SBOM {
+			sbom++
+		}
+		tableData = append(tableData, []string{l.Repository, l.Tag, shortDigest(l.Digest), yesNo(l.Provenance), yesNo(l.SBOM)})
+	}
+	pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+
+	ctx.Logger.Info("Remote listing summary", ctx.Logger.Args("namespace", namespace, "tags", len(listings), "provenance", provenance, "sbom", sbom))
+	return nil
+}
+
+// listRemote enumerates the tags of every repository under namespace with the referrers
+// attached to each. A tag whose referrers cannot be listed is reported with its error
+// rather than failing the listing.
+func listRemote(opCtx context.Context, ctx *cmd.CommandContext, client remoteRegistry, namespace string) ([]remoteListing, error) {
+	repositories, err := client.ListRepositories(opCtx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories under %s: %w", namespace, err)
+	}
+
+	var listings []remoteListing
+	for _, repository := range repositories {
+		tags, err := client.ListTags(opCtx, repository)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			if referrerTagPattern.MatchString(tag) {
+				continue
+			}
+			listing := remoteListing{Repository: repository, Tag: tag}
+			status, err := client.ReferrerStatus(opCtx, repository+":"+tag)
+			if err != nil {
+				if opCtx.Err() != nil {
+					return nil, opCtx.Err()
+				}
+				ctx.Logger.Warn("Failed to list referrers", ctx.Logger.Args("repository", repository, "tag", tag, "error", err))
+				listing.Error = err.Error()
+			} else {
+				listing.ReferrerStatus = status
+			}
+			listings = append(listings, listing)
+		}
+	}
+	return listings, nil
+}
+
+// shortDigest abbreviates a sha256 digest to its first 12 hex characters
+func shortDigest(digest string) string {
+	const prefix = "sha256:"
+	if len(digest) > len(prefix)+12 && digest[:len(prefix)] == prefix {
+		return digest[len(prefix) : len(prefix)+12]
+	}
+	return digest
+}
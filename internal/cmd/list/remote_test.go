@@ -0,0 +1,81 @@
+package list
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/registry"
+)
+
+type fakeRemote struct {
+	tags     map[string][]string
+	statuses map[string]registry.ReferrerStatus
+}
+
+func (f *fakeRemote) ListRepositories(_ context.Context, namespace string) ([]string, error) {
+	if namespace != "ghcr.io/owner" {
+		return nil, errors.New("unknown namespace")
+	}
+	return []string{"ghcr.io/owner/alpha", "ghcr.io/owner/beta"}, nil
+}
+
+func (f *fakeRemote) ListTags(_ context.Context, repository string) ([]string, error) {
+	return f.tags[repository], nil
+}
+
+func (f *fakeRemote) ReferrerStatus(_ context.Context, imageRef string) (registry.ReferrerStatus, error) {
+	status, ok := f.statuses[imageRef]
+	if !ok {
+		return registry.ReferrerStatus{}, errors.New("manifest unknown")
+	}
+	return status, nil
+}
+
+func TestListRemote(t *testing.T) {
+	ctx := &cmd.CommandContext{Logger: pterm.DefaultLogger.WithWriter(os.Stderr).WithLevel(pterm.LogLevelError)}
+	client := &fakeRemote{
+		tags: map[string][]string{
+			"ghcr.io/owner/alpha": {"1.0.0", "sha256-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+			"ghcr.io/owner/beta":  {"2.0.0", "broken"},
+		},
+		statuses: map[string]registry.ReferrerStatus{
+			"ghcr.io/owner/alpha:1.0.0": {Digest: "sha256:aaa", Provenance: true, SBOM: true},
+			"ghcr.io/owner/beta:2.0.0":  {Digest: "sha256:bbb"},
+		},
+	}
+
+	listings, err := listRemote(context.Background(), ctx, client, "ghcr.io/owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listings) != 3 {
+		t.Fatalf("expected referrer index tags to be skipped, got %+v", listings)
+	}
+	if l := listings[0]; l.Tag != "1.0.0" || !l.Provenance || !l.SBOM {
+		t.Errorf("expected alpha 1.0.0 with provenance and SBOM, got %+v", l)
+	}
+	if l := listings[1]; l.Tag != "2.0.0" || l.Provenance || l.SBOM || l.Error != "" {
+		t.Errorf("expected beta 2.0.0 without referrers, got %+v", l)
+	}
+	if l := listings[2]; l.Tag != "broken" || l.Error == "" {
+		t.Errorf("expected the failing tag to be reported with its error, got %+v", l)
+	}
+
+	if _, err := listRemote(context.Background(), ctx, client, "ghcr.io/other"); err == nil {
+		t.Error("expected an unknown namespace to fail")
+	}
+}
+
+func TestShortDigest(t *testing.T) {
+	if got := shortDigest("sha256:0123456789abcdef0123"); got != "0123456789ab" {
+		t.Errorf("expected 0123456789ab, got %s", got)
+	}
+	if got := shortDigest("sha512:abc"); got != "sha512:abc" {
+		t.Errorf("expected other digests unchanged, got %s", got)
+	}
+}
@@ -0,0 +1,139 @@
+// ABOUTME: Lock command group for maintaining the lockfile outside of installs
+// ABOUTME: Provides a semantic three-way merge usable as a git merge driver, signing, and verification
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+)
+
+func NewLockCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Maintain the dragonglass lockfile",
+	}
+
+	cmd.AddCommand(newMergeCommand(ctx))
+	cmd.AddCommand(newSignCommand(ctx))
+	cmd.AddCommand(newVerifyCommand(ctx))
+	return cmd
+}
+
+// MergeOptions configures the lock merge command
+type MergeOptions struct {
+	// Lockfile from the current branch (git's %A)
+	OursPath string
+
+	// Lockfile from the branch being merged (git's %B)
+	TheirsPath string
+
+	// Lockfile from the common ancestor (git's %O); may be missing or empty
+	BasePath string
+
+	// Where to write the result (default: OursPath, as git expects)
+	OutputPath string
+}
+
+func newMergeCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge",
+		Short: "Merge two lockfiles changed from a common base",
+		Long: `Merge lockfiles changed on diverging branches plugin by plugin instead of line
+by line. Plugins added on either side are kept, and a plugin removed on one side
+stays removed unless the other side changed it. When both sides pin a plugin to
+different artifacts, a verified entry wins over an unverified one, then the higher
+version wins. Each entry keeps its own verification state.
+
+Two different artifacts with the same version cannot be resolved automatically:
+our entry is kept, the conflict is reported, and the command exits non-zero so git
+marks the lockfile as conflicted.
+
+To use it as a git merge driver:
+  git config merge.dragonglass.driver "dragonglass lock merge --base %O --ours %A --theirs %B"
+  echo ".dragonglass/dragonglass-lock.json merge=dragonglass" >> .gitattributes
+
+Example:
+  dragonglass lock merge --base base.json --ours ours.json --theirs theirs.json`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := MergeOptions{}
+			opts.OursPath, _ = cmd.Flags().GetString("ours")
+			opts.TheirsPath, _ = cmd.Flags().GetString("theirs")
+			opts.BasePath, _ = cmd.Flags().GetString("base")
+			opts.OutputPath, _ = cmd.Flags().GetString("output")
+
+			if err := runMerge(ctx, opts); err != nil {
+				ctx.Fail("Lockfile merge failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().String("ours", "", "Lockfile from the current branch; receives the result unless --output is set")
+	cmd.Flags().String("theirs", "", "Lockfile from the branch being merged")
+	cmd.Flags().String("base", "", "Lockfile from the common ancestor (optional)")
+	cmd.Flags().String("output", "", "Write the merged lockfile here instead of --ours")
+	_ = cmd.MarkFlagRequired("ours")
+	_ = cmd.MarkFlagRequired("theirs")
+	return cmd
+}
+
+func runMerge(ctx *cmd.CommandContext, opts MergeOptions) error {
+	ours, err := loadMergeInput(opts.OursPath)
+	if err != nil {
+		return fmt.Errorf("failed to load our lockfile: %w", err)
+	}
+	theirs, err := loadMergeInput(opts.TheirsPath)
+	if err != nil {
+		return fmt.Errorf("failed to load their lockfile: %w", err)
+	}
+
+	var base *lockfile.Lockfile
+	if opts.BasePath != "" {
+		if base, err = loadMergeInput(opts.BasePath); err != nil {
+			return fmt.Errorf("failed to load base lockfile: %w", err)
+		}
+	}
+
+	merged, conflicts := lockfile.Merge(base, ours, theirs)
+
+	outputPath := opts.OutputPath
+	if outputPath == "" {
+		outputPath = opts.OursPath
+	}
+	if err := lockfile.SaveLockfile(merged, outputPath); err != nil {
+		return err
+	}
+
+	ctx.Logger.Info("Merged lockfile", ctx.Logger.Args("plugins", len(merged.Plugins), "conflicts", len(conflicts), "output", outputPath))
+
+	if len(conflicts) > 0 {
+		descriptions := make([]string, len(conflicts))
+		for i, conflict := range conflicts {
+			ctx.Logger.Warn("Conflicting plugin pins (kept ours)", ctx.Logger.Args("id", conflict.PluginID, "ours", conflict.Ours.OCIDigest, "theirs", conflict.Theirs.OCIDigest))
+			descriptions[i] = conflict.String()
+		}
+		return fmt.Errorf("%d plugins could not be merged automatically: %s", len(conflicts), strings.Join(descriptions, "; "))
+	}
+
+	return nil
+}
+
+// loadMergeInput loads a lockfile given to the merge driver. git passes an empty file for
+// a side where the lockfile does not exist, which is read as a lockfile without plugins.
+func loadMergeInput(path string) (*lockfile.Lockfile, error) {
+	info, err := os.Stat(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err != nil || info.Size() == 0 {
+		return lockfile.NewLockfile(""), nil
+	}
+	return lockfile.LoadLockfile(path)
+}
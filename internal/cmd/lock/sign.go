@@ -0,0 +1,196 @@
+// ABOUTME: Lockfile signing by a vault admin and signature verification before installs
+// ABOUTME: Signs with a local key as a DSSE envelope, or keyless with the Actions OIDC identity
+package lock
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/auth"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/intoto"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/sigstore"
+)
+
+func newSignCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign",
+		Short: "Sign the lockfile as the vault's reviewed plugin set",
+		Long: `Sign the vault's lockfile so installs can check the plugin set was approved by
+a vault admin and not changed since. The signature is written next to the
+lockfile as dragonglass-lock.json.sig and should be committed with it.
+
+With --key the lockfile is signed with a local ECDSA or Ed25519 private key;
+members verify it against the matching public key in verification.lockfile_key.
+Without --key it is signed keyless through Sigstore with the GitHub Actions OIDC
+identity of the running workflow (requires the id-token: write permission);
+members verify it against verification.lockfile_signer.
+
+Any change to the lockfile invalidates the signature, so sign again after adding,
+updating or removing plugins. Installs refuse unsigned or changed lockfiles when
+verification.require_signed_lockfile is set.
+
+Example:
+  dragonglass lock sign --key admin.pem`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			keyPath, _ := cmd.Flags().GetString("key")
+
+			path, err := SignLockfile(cmd.Context(), ctx, keyPath, time.Now().UTC())
+			if err != nil {
+				ctx.Fail("Lockfile signing failed", err)
+			}
+
+			ctx.Logger.Info("Signed lockfile", ctx.Logger.Args("signature", path))
+		},
+	}
+
+	cmd.Flags().String("key", "", "PEM private key to sign with (default: keyless with the GitHub Actions identity)")
+	return cmd
+}
+
+// SignLockfile signs the vault's lockfile at signedAt with the private key at keyPath, or
+// keyless when keyPath is empty, and returns the path of the signature written
+func SignLockfile(opCtx context.Context, ctx *cmd.CommandContext, keyPath string, signedAt time.Time) (string, error) {
+	lockfilePath, err := ctx.ResolveLockfilePath()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+
+	data, err := os.ReadFile(lockfilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read lockfile: %w", err)
+	}
+	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to load lockfile: %w", err)
+	}
+
+	statement := intoto.NewLockfileStatement(filepath.Base(lockfilePath), data, intoto.LockfilePredicate{
+		Vault:    filepath.Base(lockfile.VaultRoot(lockfilePath)),
+		Plugins:  len(lockfileData.Plugins),
+		SignedAt: signedAt,
+	})
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode lockfile statement: %w", err)
+	}
+
+	signature, err := signPayload(opCtx, keyPath, payload)
+	if err != nil {
+		return "", err
+	}
+
+	path := lockfile.SignaturePath(lockfilePath)
+	if err := os.WriteFile(path, append(signature, '\n'), lockfile.DefaultLockfilePerms); err != nil {
+		return "", fmt.Errorf("failed to write lockfile signature: %w", err)
+	}
+	return path, nil
+}
+
+// signPayload signs payload with the key at keyPath as a DSSE envelope, or keyless as a
+// Sigstore bundle when keyPath is empty
+func signPayload(ctx context.Context, keyPath string, payload []byte) ([]byte, error) {
+	if keyPath != "" {
+		signer, err := intoto.LoadSigner(keyPath)
+		if err != nil {
+			return nil, err
+		}
+		envelope, err := signer.SignPayload(payload)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.MarshalIndent(envelope, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode lockfile signature: %w", err)
+		}
+		return data, nil
+	}
+
+	if !auth.ActionsOIDCAvailable(os.Getenv) {
+		return nil, fmt.Errorf("no signing identity is available (pass --key, or run in GitHub Actions with the id-token: write permission)")
+	}
+
+	idToken, err := auth.RequestActionsIDToken(ctx, auth.DefaultOIDCOpts().WithAudience(sigstore.SigningAudience))
+	if err != nil {
+		return nil, err
+	}
+	return sigstore.SignKeyless(ctx, payload, intoto.PayloadType, idToken)
+}
+
+// VerifySignature checks the lockfile at lockfilePath carries a signature by the key in
+// verification.lockfile_key or the identity in verification.lockfile_signer, and that the
+// lockfile has not changed since it was signed. Failures match ErrLockfileSignature.
+func VerifySignature(lockfilePath string, policy config.VerificationConfig) (*intoto.LockfileStatement, error) {
+	data, err := os.ReadFile(lockfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+	return VerifySignatureData(lockfilePath, data, policy)
+}
+
+// VerifySignatureData checks the signature of the lockfile at lockfilePath as
+// VerifySignature does, over data already read from it
+func VerifySignatureData(lockfilePath string, data []byte, policy config.VerificationConfig) (*intoto.LockfileStatement, error) {
+	signature, err := os.ReadFile(lockfile.SignaturePath(lockfilePath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s is not signed", dgerrors.ErrLockfileSignature, filepath.Base(lockfilePath))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile signature: %w", err)
+	}
+
+	payload, err := verifySignaturePayload(signature, data, policy)
+	if err != nil {
+		return nil, dgerrors.Mark(fmt.Errorf("invalid lockfile signature: %w", err), dgerrors.ErrLockfileSignature)
+	}
+
+	statement, err := intoto.CheckLockfileStatement(payload, data)
+	if err != nil {
+		return nil, dgerrors.Mark(fmt.Errorf("invalid lockfile signature: %w", err), dgerrors.ErrLockfileSignature)
+	}
+	return statement, nil
+}
+
+// verifySignaturePayload verifies a DSSE envelope against verification.lockfile_key or a
+// Sigstore bundle against verification.lockfile_signer and returns the signed payload
+func verifySignaturePayload(signature, data []byte, policy config.VerificationConfig) ([]byte, error) {
+	var format struct {
+		MediaType string `json:"mediaType"`
+	}
+	if err := json.Unmarshal(signature, &format); err != nil {
+		return nil, fmt.Errorf("failed to parse signature: %w", err)
+	}
+
+	if format.MediaType != "" {
+		if policy.LockfileSigner == "" {
+			return nil, fmt.Errorf("lockfile is signed keyless but verification.lockfile_signer is not set")
+		}
+		digest := sha256.Sum256(data)
+		return sigstore.VerifyBundle(signature, digest[:], policy.LockfileSigner)
+	}
+
+	if policy.LockfileKey == "" {
+		return nil, fmt.Errorf("lockfile is signed with a key but verification.lockfile_key is not set")
+	}
+	publicKey, err := intoto.LoadPublicKey(policy.LockfileKey)
+	if err != nil {
+		return nil, err
+	}
+	var envelope intoto.Envelope
+	if err := json.Unmarshal(signature, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse signature: %w", err)
+	}
+	return envelope.VerifyPayload(publicKey)
+}
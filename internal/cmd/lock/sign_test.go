@@ -0,0 +1,126 @@
+package lock
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+// writeKeyPair writes a PEM private key and its public key into dir
+func writeKeyPair(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	private, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privatePath := filepath.Join(dir, name+".pem")
+	publicPath := filepath.Join(dir, name+".pub")
+	if err := os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: private}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return privatePath, publicPath
+}
+
+func TestSignAndVerifyLockfile(t *testing.T) {
+	v := vault.New(t.TempDir())
+	ctx := &cmd.CommandContext{
+		LockfilePath: v.LockfilePath(),
+		Logger:       pterm.DefaultLogger.WithWriter(os.Stderr).WithLevel(pterm.LogLevelError),
+	}
+
+	lf := lockfile.NewLockfile(v.Root)
+	if err := lf.AddPlugin("test-plugin", lockfile.PluginEntry{Name: "test-plugin", Version: "1.0.0", OCIReference: "ghcr.io/owner/test-plugin:1.0.0", OCIDigest: "sha256:abc123"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := lockfile.SaveLockfile(lf, v.LockfilePath()); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := t.TempDir()
+	adminKey, adminPublic := writeKeyPair(t, keys, "admin")
+	_, otherPublic := writeKeyPair(t, keys, "other")
+	policy := config.VerificationConfig{LockfileKey: adminPublic}
+
+	// Unsigned lockfiles are rejected
+	if _, err := VerifySignature(v.LockfilePath(), policy); !errors.Is(err, dgerrors.ErrLockfileSignature) {
+		t.Fatalf("expected ErrLockfileSignature for unsigned lockfile, got %v", err)
+	}
+
+	path, err := SignLockfile(context.Background(), ctx, adminKey, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected signing error: %v", err)
+	}
+	if path != lockfile.SignaturePath(v.LockfilePath()) {
+		t.Errorf("expected signature at %s, got %s", lockfile.SignaturePath(v.LockfilePath()), path)
+	}
+
+	statement, err := VerifySignature(v.LockfilePath(), policy)
+	if err != nil {
+		t.Fatalf("unexpected verification error: %v", err)
+	}
+	if statement.Predicate.Plugins != 1 || statement.Predicate.Vault != filepath.Base(v.Root) {
+		t.Errorf("unexpected predicate: %+v", statement.Predicate)
+	}
+
+	tests := []struct {
+		name     string
+		policy   config.VerificationConfig
+		errorMsg string
+	}{
+		{name: "other key", policy: config.VerificationConfig{LockfileKey: otherPublic}, errorMsg: "no valid signature"},
+		{name: "no key configured", policy: config.VerificationConfig{LockfileSigner: "https://github.com/acme/vault/.github/workflows/sign.yml@refs/heads/main"}, errorMsg: "verification.lockfile_key is not set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VerifySignature(v.LockfilePath(), tt.policy)
+			if !errors.Is(err, dgerrors.ErrLockfileSignature) || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("expected ErrLockfileSignature containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+
+	// The signature is checked over the data read, not the file as it is by then
+	if _, err := VerifySignatureData(v.LockfilePath(), []byte("{}\n"), policy); !errors.Is(err, dgerrors.ErrLockfileSignature) || !strings.Contains(err.Error(), "changed after signing") {
+		t.Errorf("expected other lockfile data to be rejected, got %v", err)
+	}
+
+	// Changing the lockfile after signing invalidates the signature
+	if err := lf.RemovePlugin("test-plugin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := lockfile.SaveLockfile(lf, v.LockfilePath()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifySignature(v.LockfilePath(), policy); !errors.Is(err, dgerrors.ErrLockfileSignature) || !strings.Contains(err.Error(), "changed after signing") {
+		t.Errorf("expected tampered lockfile to be rejected, got %v", err)
+	}
+}
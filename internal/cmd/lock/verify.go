@@ -0,0 +1,101 @@
+// ABOUTME: Lock verify command checking installed plugin files against the lockfile
+// ABOUTME: Recomputes each plugin's integrity value and reports plugins changed since install
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+)
+
+func newVerifyCommand(ctx *cmd.CommandContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify",
+		Short: "Check installed plugin files against the lockfile",
+		Long: `Check that the files of every plugin in the lockfile are installed exactly as
+they were when the plugin was added. Each plugin's files are hashed again and
+compared against the integrity value the lockfile records for it, catching files
+edited, added, or removed since install. Settings (data.json) are not checked.
+
+Entries recorded before integrity values existed are skipped with a warning;
+reinstall them with install --force to record one. Exits with the digest
+mismatch code when any plugin is missing or changed.
+
+Example:
+  dragonglass lock verify`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := VerifyInstalled(ctx); err != nil {
+				ctx.Fail("Lockfile verification failed", err)
+			}
+		},
+	}
+}
+
+// VerifyInstalled checks the installed files of every plugin in the vault's lockfile
+// against the integrity value recorded for it, logging the outcome for each plugin
+func VerifyInstalled(ctx *cmd.CommandContext) error {
+	v, err := ctx.DiscoverVault()
+	if err != nil {
+		return fmt.Errorf("failed to find vault: %w", err)
+	}
+
+	lockfilePath, err := ctx.ResolveLockfilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+
+	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load lockfile: %w", err)
+	}
+
+	ids := make([]string, 0, len(lockfileData.Plugins))
+	for id := range lockfileData.Plugins {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	var failed []string
+	for _, id := range ids {
+		entry := lockfileData.Plugins[id]
+		if entry.Integrity == "" {
+			ctx.Logger.Warn("No integrity recorded", ctx.Logger.Args("id", id, "hint", "reinstall with install --force to record one"))
+			continue
+		}
+
+		path := v.InstallPath(entry.Kind, id, entry.Name)
+		files, err := plugin.HashInstalled(entry.Kind, path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if len(files) == 0 {
+			ctx.Logger.Error("Plugin not installed", ctx.Logger.Args("id", id, "path", path))
+			failed = append(failed, id)
+			continue
+		}
+
+		if err := entry.CheckIntegrity(files); err != nil {
+			changed := slices.DeleteFunc(lockfile.ChangedFiles(entry.Files, files), func(name string) bool { return name == plugin.ManifestFile })
+			ctx.Logger.Error("Plugin files changed since install", ctx.Logger.Args("id", id, "changed", strings.Join(changed, ", "), "error", err))
+			failed = append(failed, id)
+			continue
+		}
+
+		ctx.Logger.Info("Verified", ctx.Logger.Args("id", id, "integrity", entry.Integrity))
+	}
+
+	if len(failed) > 0 {
+		return dgerrors.Mark(fmt.Errorf("%d of %d plugins do not match the lockfile: %s", len(failed), len(ids), strings.Join(failed, ", ")), dgerrors.ErrDigestMismatch)
+	}
+	return nil
+}
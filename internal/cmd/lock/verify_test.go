@@ -0,0 +1,89 @@
+package lock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+	"github.com/gillisandrew/dragonglass-poc/internal/vault"
+)
+
+func TestVerifyInstalled(t *testing.T) {
+	v := vault.New(t.TempDir())
+	if err := os.MkdirAll(v.DragonglassDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	ctx := &cmd.CommandContext{
+		VaultDir:     v.Root,
+		LockfilePath: v.LockfilePath(),
+		Logger:       pterm.DefaultLogger.WithWriter(os.Stderr).WithLevel(pterm.LogLevelError),
+	}
+
+	install := func(id string) lockfile.PluginEntry {
+		t.Helper()
+		dir := v.PluginDir(id)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range map[string]string{"main.js": "console.log('" + id + "')", plugin.SettingsFileName: "{}"} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		files, err := plugin.HashFiles(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return lockfile.PluginEntry{Name: id, OCIReference: "ghcr.io/owner/" + id + ":1.0.0", Files: files, Integrity: lockfile.Integrity(files)}
+	}
+
+	lf := lockfile.NewLockfile(v.Root)
+	for _, id := range []string{"intact", "tampered"} {
+		if err := lf.AddPlugin(id, install(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lf.AddPlugin("legacy", lockfile.PluginEntry{Name: "legacy", OCIReference: "ghcr.io/owner/legacy:1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := lockfile.SaveLockfile(lf, v.LockfilePath()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Settings changing as the plugin is used do not count
+	if err := os.WriteFile(filepath.Join(v.PluginDir("tampered"), plugin.SettingsFileName), []byte(`{"changed":true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyInstalled(ctx); err != nil {
+		t.Fatalf("expected installed plugins to verify, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(v.PluginDir("tampered"), "main.js"), []byte("steal()"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := VerifyInstalled(ctx)
+	if !errors.Is(err, dgerrors.ErrDigestMismatch) || !strings.Contains(err.Error(), "1 of 3 plugins") || !strings.Contains(err.Error(), "tampered") {
+		t.Errorf("expected tampered plugin to be reported, got %v", err)
+	}
+
+	if err := os.RemoveAll(v.PluginDir("intact")); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyInstalled(ctx); err == nil || !strings.Contains(err.Error(), "intact, tampered") {
+		t.Errorf("expected missing plugin to be reported, got %v", err)
+	}
+
+	// A --lockfile that does not exist fails rather than verifying an empty vault
+	missing := filepath.Join(v.Root, "elsewhere", "dragonglass-lock.json")
+	if err := VerifyInstalled(&cmd.CommandContext{LockfilePath: missing, Logger: ctx.Logger}); err == nil || !strings.Contains(err.Error(), "lockfile not found: "+missing) {
+		t.Errorf("expected a missing --lockfile to fail, got %v", err)
+	}
+}
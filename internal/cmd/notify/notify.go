@@ -0,0 +1,411 @@
+// ABOUTME: Notify command for alerting webhooks about plugin updates and new vulnerabilities
+// ABOUTME: Checks every locked plugin and posts one report per target; meant for cron and scheduled jobs
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
+	"github.com/gillisandrew/dragonglass-poc/internal/changelog"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/install"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/verify"
+	"github.com/gillisandrew/dragonglass-poc/internal/githubapi"
+	"github.com/gillisandrew/dragonglass-poc/internal/httpclient"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/notify"
+	"github.com/gillisandrew/dragonglass-poc/internal/output"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+	"github.com/gillisandrew/dragonglass-poc/internal/registry"
+)
+
+// DeliveryTimeout bounds each webhook request
+const DeliveryTimeout = 30 * time.Second
+
+func NewNotifyCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notify",
+		Short: "Send webhook alerts for plugin updates and new vulnerabilities",
+		Long: `Check every plugin in the lockfile for newer published versions and for
+vulnerabilities that were not known at install, and post a report to each
+target under notify.targets (Slack, Discord, or generic JSON webhooks).
+
+Update alerts include the new version's release notes from the GitHub release in
+the plugin's source repository, unless --changelog=false is given.
+
+Alerts are remembered in .dragonglass/notify-state.json next to the lockfile, so
+running the command from cron or a scheduled workflow only reports each update
+and each new vulnerability once. Nothing is sent when there is nothing new.
+
+With --dry-run, --output json, yaml, or go-template='{{...}}' prints the report
+as the generic JSON webhook payload would carry it.
+
+Example:
+  dragonglass notify
+  dragonglass notify --dry-run
+  dragonglass notify --dry-run -o go-template='{{range .alerts}}{{.plugin_id}} {{end}}'
+  dragonglass notify --updates=false`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := DefaultCheckOpts()
+			opts.Updates, _ = cmd.Flags().GetBool("updates")
+			opts.Vulnerabilities, _ = cmd.Flags().GetBool("vulnerabilities")
+			opts.Changelog, _ = cmd.Flags().GetBool("changelog")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			renderer, err := ctx.Renderer(cmd)
+			if err != nil {
+				ctx.Fail("Notify command failed", err)
+			}
+
+			if err := runNotifyCommand(cmd.Context(), ctx, opts, dryRun, renderer); err != nil {
+				ctx.Fail("Notify command failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("updates", true, "Alert when a newer version of a plugin is published")
+	cmd.Flags().Bool("changelog", true, "Include the release notes of newer versions from their source repository")
+	cmd.Flags().Bool("vulnerabilities", true, "Re-verify plugins and alert on vulnerabilities not known at install")
+	cmd.Flags().Bool("dry-run", false, "Print the report instead of sending it, and leave the notification state unchanged")
+	output.AddFlag(cmd)
+	return cmd
+}
+
+// CheckOpts selects which checks Check runs
+type CheckOpts struct {
+	// Look up newer published versions
+	Updates bool
+
+	// Attach the release notes of newer versions to update alerts
+	Changelog bool
+
+	// Re-verify plugins and compare their vulnerabilities with what was last known
+	Vulnerabilities bool
+}
+
+// DefaultCheckOpts returns options that run every check
+func DefaultCheckOpts() *CheckOpts {
+	return &CheckOpts{Updates: true, Changelog: true, Vulnerabilities: true}
+}
+
+// WithChangelog enables or disables release notes on update alerts
+func (opts *CheckOpts) WithChangelog(changelog bool) *CheckOpts {
+	opts.Changelog = changelog
+	return opts
+}
+
+// WithUpdates enables or disables the update check
+func (opts *CheckOpts) WithUpdates(updates bool) *CheckOpts {
+	opts.Updates = updates
+	return opts
+}
+
+// WithVulnerabilities enables or disables the vulnerability check
+func (opts *CheckOpts) WithVulnerabilities(vulnerabilities bool) *CheckOpts {
+	opts.Vulnerabilities = vulnerabilities
+	return opts
+}
+
+func runNotifyCommand(opCtx context.Context, ctx *cmd.CommandContext, opts *CheckOpts, dryRun bool, renderer *output.Renderer) error {
+	if len(ctx.Config.Notify.Targets) == 0 && !dryRun {
+		return fmt.Errorf("no notification targets configured (add them under notify.targets, or pass --dry-run)")
+	}
+
+	lockfilePath, err := ctx.ResolveLockfilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load lockfile: %w", err)
+	}
+
+	statePath := filepath.Join(filepath.Dir(lockfilePath), notify.StateFileName)
+	state, err := notify.LoadState(statePath)
+	if err != nil {
+		return err
+	}
+
+	alerts, checkErr := Check(opCtx, ctx, lockfileData, state, opts)
+	if errors.Is(checkErr, context.Canceled) {
+		return checkErr
+	}
+
+	report := &notify.Report{
+		Vault:  lockfileData.Metadata.VaultPath,
+		Time:   time.Now().UTC(),
+		Alerts: alerts,
+	}
+	if report.Vault == "" {
+		report.Vault = lockfile.VaultRoot(lockfilePath)
+	}
+
+	switch {
+	case len(alerts) == 0:
+		ctx.Logger.Info("Nothing new to report", ctx.Logger.Args("plugins", len(lockfileData.Plugins)))
+	case dryRun:
+		if err := printReport(renderer, report); err != nil {
+			return err
+		}
+	default:
+		if err := Deliver(opCtx, ctx, report); err != nil {
+			return err
+		}
+		state.Record(alerts)
+		if err := state.Save(statePath); err != nil {
+			return err
+		}
+	}
+
+	return checkErr
+}
+
+// printReport writes the report to stdout as text, or with renderer as the generic JSON
+// payload
+func printReport(renderer *output.Renderer, report *notify.Report) error {
+	if !renderer.Text() {
+		return renderer.Render(os.Stdout, report)
+	}
+	_, err := fmt.Fprintln(os.Stdout, report.Text())
+	return err
+}
+
+// Check looks for newer versions and new vulnerabilities of every plugin in the lockfile,
+// skipping what state says was already announced. Plugins that could not be checked are
+// reported in the returned error alongside the alerts for the others.
+func Check(opCtx context.Context, ctx *cmd.CommandContext, lockfileData *lockfile.Lockfile, state *notify.State, opts *CheckOpts) ([]notify.Alert, error) {
+	if opts == nil {
+		opts = DefaultCheckOpts()
+	}
+
+	// Dev builds are neither published nor attested, so there is nothing to check them for
+	pluginIDs := make([]string, 0, len(lockfileData.Plugins))
+	for pluginID, entry := range lockfileData.Plugins {
+		if !entry.IsDev() {
+			pluginIDs = append(pluginIDs, pluginID)
+		}
+	}
+	sort.Strings(pluginIDs)
+	if len(pluginIDs) == 0 {
+		return nil, nil
+	}
+
+	cfg := ctx.Config
+
+	var client *registry.Client
+	if opts.Updates {
+		registryOpts := registry.DefaultRegistryOpts().
+			WithTimeout(cfg.Registry.Timeout.Duration()).
+			WithCredentials(cfg.Registry.CredentialLookup()).
+			WithDockerCredentials(registry.DockerCredentialStore(cfg.Registry.DockerCredentials)).
+			WithPluginOpts(&plugin.PluginOpts{
+				AnnotationNamespace: ctx.AnnotationNamespace,
+			})
+		var err error
+		if client, err = registry.NewClient(registryOpts); err != nil {
+			return nil, fmt.Errorf("failed to create registry client: %w", err)
+		}
+	}
+
+	var releases *changelog.Client
+	if opts.Updates && opts.Changelog {
+		releases = changelog.NewClient(githubapi.NewClient(githubapi.DefaultOpts().WithToken(ctx.GitHubToken)), changelog.DefaultOpts())
+	}
+
+	// One verifier is shared so the Sigstore trusted root is fetched once for all plugins
+	var verifier *attestation.AttestationVerifier
+	if opts.Vulnerabilities {
+		var err error
+		if verifier, err = verify.NewAttestationVerifier(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// Alerts are collected per plugin and reported in lockfile order once all checks finish
+	found := make([][]notify.Alert, len(pluginIDs))
+	scheduler := attestation.NewScheduler(cfg.Verification.Concurrency)
+	errs := scheduler.Run(opCtx, len(pluginIDs), func(jobCtx context.Context, i int) error {
+		pluginID := pluginIDs[i]
+		entry := lockfileData.Plugins[pluginID]
+
+		if opts.Updates {
+			alert, err := checkUpdate(jobCtx, client, state, pluginID, entry)
+			if err != nil {
+				return err
+			}
+			if alert != nil {
+				alert.Release = fetchRelease(jobCtx, ctx, releases, pluginID, entry, alert.LatestVersion)
+				found[i] = append(found[i], *alert)
+			}
+		}
+
+		if opts.Vulnerabilities {
+			alert, err := checkVulnerabilities(jobCtx, ctx, verifier, state, pluginID, entry)
+			if err != nil {
+				return err
+			}
+			if alert != nil {
+				found[i] = append(found[i], *alert)
+			}
+		}
+		return nil
+	})
+
+	var (
+		alerts   []notify.Alert
+		failures []error
+	)
+	for i, pluginAlerts := range found {
+		if err := errs[i]; err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil, err
+			}
+			ctx.Logger.Warn("Could not check plugin", ctx.Logger.Args("id", pluginIDs[i], "error", err))
+			failures = append(failures, fmt.Errorf("%s: %w", pluginIDs[i], err))
+		}
+		alerts = append(alerts, pluginAlerts...)
+	}
+
+	if len(failures) > 0 {
+		return alerts, fmt.Errorf("%d of %d plugins could not be checked: %w", len(failures), len(pluginIDs), errors.Join(failures...))
+	}
+	return alerts, nil
+}
+
+// checkUpdate returns an alert when a version newer than the locked one is published and
+// has not been announced yet. For a mutable tag the update is the tag moving to another
+// digest, announced as tag@digest.
+func checkUpdate(opCtx context.Context, client *registry.Client, state *notify.State, pluginID string, entry lockfile.PluginEntry) (*notify.Alert, error) {
+	host, repository, tag, err := registry.ParseImageReference(entry.OCIReference)
+	if err != nil {
+		return nil, fmt.Errorf("invalid locked reference %s: %w", entry.OCIReference, err)
+	}
+
+	if entry.MutableTag {
+		desc, err := client.Resolve(opCtx, entry.OCIReference)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", entry.OCIReference, err)
+		}
+		current := tag + "@" + desc.Digest.String()
+		if desc.Digest.String() == entry.OCIDigest || state.Announced(pluginID, current) {
+			return nil, nil
+		}
+		alert := newAlert(notify.KindUpdate, pluginID, entry)
+		alert.LatestVersion = current
+		return &alert, nil
+	}
+
+	tags, err := client.ListTags(opCtx, host+"/"+repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	latest, ok := notify.LatestVersion(tags, entry.Version)
+	if !ok || state.Announced(pluginID, latest) {
+		return nil, nil
+	}
+
+	alert := newAlert(notify.KindUpdate, pluginID, entry)
+	alert.LatestVersion = latest
+	return &alert, nil
+}
+
+// fetchRelease returns the release notes of version from the source repository the
+// locked entry's provenance names, or nil when there are none. Mutable tags have no
+// version to look up, and failures are only logged: an update is worth announcing
+// without its notes.
+func fetchRelease(opCtx context.Context, ctx *cmd.CommandContext, releases *changelog.Client, pluginID string, entry lockfile.PluginEntry, version string) *changelog.Release {
+	if releases == nil || entry.MutableTag {
+		return nil
+	}
+	release, err := releases.Release(opCtx, entry.VerificationState.SourceRepository, version)
+	if err != nil {
+		ctx.Logger.Debug("Could not fetch release notes", ctx.Logger.Args("id", pluginID, "version", version, "error", err))
+		return nil
+	}
+	return release
+}
+
+// checkVulnerabilities re-verifies the locked artifact and returns an alert when its SBOM
+// now reports more vulnerabilities than were last known
+func checkVulnerabilities(opCtx context.Context, ctx *cmd.CommandContext, verifier *attestation.AttestationVerifier, state *notify.State, pluginID string, entry lockfile.PluginEntry) (*notify.Alert, error) {
+	verifyOpts := verify.DefaultVerifyOpts().
+		WithExpectedDigest(entry.OCIDigest).
+		WithVerifier(verifier)
+	result, err := verify.Verify(opCtx, ctx, entry.LockedReference(), verifyOpts)
+
+	// Verification blocked by vulnerabilities still reports them
+	if result == nil || result.Attestation == nil || result.Attestation.SBOM == nil {
+		if err != nil {
+			return nil, err
+		}
+		ctx.Logger.Debug("No SBOM to scan", ctx.Logger.Args("id", pluginID))
+		return nil, nil
+	}
+
+	current := install.VerificationState(result.Attestation, ctx.Config.Verification, time.Now().UTC()).Vulnerabilities
+	added := notify.NewVulnerabilities(state.Baseline(entry.OCIDigest, entry.VerificationState.Vulnerabilities), current)
+	if added == nil {
+		return nil, nil
+	}
+
+	alert := newAlert(notify.KindVulnerability, pluginID, entry)
+	alert.Vulnerabilities = current
+	alert.NewVulnerabilities = added
+	return &alert, nil
+}
+
+func newAlert(kind, pluginID string, entry lockfile.PluginEntry) notify.Alert {
+	return notify.Alert{
+		Kind:         kind,
+		PluginID:     pluginID,
+		Name:         entry.Name,
+		Version:      entry.Version,
+		OCIReference: entry.OCIReference,
+		OCIDigest:    entry.OCIDigest,
+	}
+}
+
+// Deliver posts report to every configured target. Every target is attempted; the error
+// lists the ones that failed.
+func Deliver(opCtx context.Context, ctx *cmd.CommandContext, report *notify.Report) error {
+	client := httpclient.NewWithTimeout(DeliveryTimeout)
+	targets := ctx.Config.Notify
+
+	var failures []error
+	for _, name := range targets.TargetNames() {
+		target := targets.Targets[name]
+		url, err := target.WebhookURL(name, os.LookupEnv)
+		if err != nil {
+			failures = append(failures, err)
+			continue
+		}
+
+		count := len(report.Filter(target.Events).Alerts)
+		if count == 0 {
+			ctx.Logger.Debug("No alerts for target", ctx.Logger.Args("target", name))
+			continue
+		}
+
+		err = notify.Send(opCtx, client, notify.Target{Name: name, Type: target.Type, URL: url, Events: target.Events}, report)
+		if err != nil {
+			ctx.Logger.Warn("Notification failed", ctx.Logger.Args("target", name, "error", err))
+			failures = append(failures, err)
+			continue
+		}
+		ctx.Logger.Info("Notification sent", ctx.Logger.Args("target", name, "alerts", count))
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to notify %d of %d targets: %w", len(failures), len(targets.Targets), errors.Join(failures...))
+	}
+	return nil
+}
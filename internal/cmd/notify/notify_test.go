@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/notify"
+)
+
+func TestDeliver(t *testing.T) {
+	received := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received[r.URL.Path] = string(body)
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("TEAM_WEBHOOK", server.URL+"/team")
+
+	cfg := config.DefaultConfig()
+	cfg.Notify.Targets = map[string]config.NotifyTarget{
+		"team":    {Type: "slack", URLEnv: "TEAM_WEBHOOK"},
+		"updates": {Type: "generic", URL: server.URL + "/updates", Events: []string{"update"}},
+		"down":    {Type: "discord", URL: server.URL + "/down"},
+		"unset":   {Type: "slack", URLEnv: "UNSET_WEBHOOK"},
+	}
+	ctx := &cmd.CommandContext{
+		Config: cfg,
+		Logger: pterm.DefaultLogger.WithWriter(os.Stderr).WithLevel(pterm.LogLevelError),
+	}
+
+	report := &notify.Report{
+		Vault: "/vaults/notes",
+		Time:  time.Now().UTC(),
+		Alerts: []notify.Alert{
+			{Kind: notify.KindVulnerability, PluginID: "tasks", Version: "7.0.0"},
+		},
+	}
+
+	err := Deliver(context.Background(), ctx, report)
+	if err == nil || !strings.Contains(err.Error(), "2 of 4 targets") {
+		t.Fatalf("expected the down and unset targets to fail, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "UNSET_WEBHOOK is not set") {
+		t.Errorf("expected the missing variable to be reported, got %v", err)
+	}
+
+	if !strings.Contains(received["/team"], "tasks 7.0.0 has new vulnerabilities") {
+		t.Errorf("expected the team target to receive the alert, got %q", received["/team"])
+	}
+	if _, ok := received["/updates"]; ok {
+		t.Error("expected no request to a target not subscribed to vulnerability alerts")
+	}
+}
@@ -0,0 +1,19 @@
+// ABOUTME: Renderer selection for commands that report structured results
+// ABOUTME: Picks the renderer from the --output flag, falling back to the output.format setting
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/output"
+)
+
+// Renderer returns the renderer command's --output flag (see output.AddFlag) selects, or
+// the one output.format selects when the flag is not given
+func (c *CommandContext) Renderer(command *cobra.Command) (*output.Renderer, error) {
+	spec, _ := command.Flags().GetString("output")
+	if spec == "" && c.Config != nil {
+		spec = c.Config.Output.Format
+	}
+	return output.New(spec)
+}
@@ -0,0 +1,178 @@
+// ABOUTME: Publish-notify command turning GitHub release webhooks into plugins index updates
+// ABOUTME: Verifies the published artifact and emits the index entry and pull request payload
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/verify"
+	"github.com/gillisandrew/dragonglass-poc/internal/ghactions"
+	"github.com/gillisandrew/dragonglass-poc/internal/registry"
+	"github.com/gillisandrew/dragonglass-poc/internal/webhook"
+)
+
+// NotifyOptions configures the publish-notify command
+type NotifyOptions struct {
+	// Webhook payload file ("-" for stdin)
+	PayloadPath string
+
+	// Plugin ID the artifact is published under (default: repository name)
+	PluginID string
+
+	// Commit the release was built from, when the payload does not name one
+	Commit string
+
+	// Plugins index checkout to write the build config into (optional)
+	IndexDir string
+}
+
+func NewPublishNotifyCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "publish-notify",
+		Short: "Verify a published release and emit a plugins index update",
+		Long: `Consume a GitHub release or workflow_run webhook payload, verify the artifact
+the build workflow published for it, and print the plugins index entry together
+with a pull request branch, title, and body as JSON.
+
+The artifact is expected at <registry>/<owner>/<repo>/<plugin-id>:v<version>,
+where the version is the release tag without a leading "v". Verification always
+runs in strict mode. Payloads that do not announce a successful publish, such as
+draft releases or failed runs, are skipped without error.
+
+Example:
+  dragonglass publish-notify --payload "$GITHUB_EVENT_PATH" --plugin-id dataview
+  dragonglass publish-notify --payload event.json --commit <sha> --index-dir .`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := NotifyOptions{}
+			opts.PayloadPath, _ = cmd.Flags().GetString("payload")
+			opts.PluginID, _ = cmd.Flags().GetString("plugin-id")
+			opts.Commit, _ = cmd.Flags().GetString("commit")
+			opts.IndexDir, _ = cmd.Flags().GetString("index-dir")
+
+			if err := runPublishNotify(cmd.Context(), ctx, opts); err != nil {
+				ctx.Fail("Publish notification failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().String("payload", "", "Webhook payload file, or - for stdin")
+	cmd.Flags().String("plugin-id", "", "Plugin ID the artifact is published under (default: repository name)")
+	cmd.Flags().String("commit", "", "Commit the release was built from, when the payload does not name one")
+	cmd.Flags().String("index-dir", "", "Write the build config into this plugins index checkout")
+	_ = cmd.MarkFlagRequired("payload")
+	return cmd
+}
+
+func runPublishNotify(opCtx context.Context, ctx *cmd.CommandContext, opts NotifyOptions) error {
+	data, err := readPayload(opts.PayloadPath)
+	if err != nil {
+		return err
+	}
+
+	event, err := webhook.ParsePayload(data)
+	if errors.Is(err, webhook.ErrIgnored) {
+		ctx.Logger.Info("Skipping webhook event", ctx.Logger.Args("reason", err))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	commit := event.Commit
+	if opts.Commit != "" {
+		commit = opts.Commit
+	}
+	if commit == "" {
+		return fmt.Errorf("the %s payload does not name a commit for %s (pass --commit)", event.Name, event.Tag)
+	}
+	entry, err := webhook.NewIndexEntry(commit)
+	if err != nil {
+		return err
+	}
+
+	pluginID := opts.PluginID
+	if pluginID == "" {
+		pluginID = path.Base(event.Repository)
+	}
+
+	registryHost := ctx.Config.Registry.DefaultRegistry
+	if registryHost == "" {
+		registryHost = registry.DefaultRegistry
+	}
+	imageRef := event.ImageReference(registryHost, pluginID)
+
+	// Only verified artifacts may enter the index
+	cfg := *ctx.Config
+	cfg.Verification.StrictMode = true
+	notifyCtx := *ctx
+	notifyCtx.Config = &cfg
+
+	ctx.Logger.Info("Verifying published plugin", ctx.Logger.Args("repository", event.Repository, "tag", event.Tag, "imageRef", imageRef))
+	verifyOpts := verify.DefaultVerifyOpts().WithSkipTokenValidation(ghactions.InActions())
+	result, err := verify.Verify(opCtx, &notifyCtx, imageRef, verifyOpts)
+	if err != nil {
+		return fmt.Errorf("failed to verify %s: %w", imageRef, err)
+	}
+
+	if result.Metadata.Version != event.Version() {
+		return fmt.Errorf("artifact %s is version %s, but the release tag is %s", imageRef, result.Metadata.Version, event.Tag)
+	}
+
+	notification := webhook.NewNotification(event, entry, imageRef, result.ManifestDigest)
+
+	if opts.IndexDir != "" {
+		if err := writeIndexEntry(opts.IndexDir, notification); err != nil {
+			return err
+		}
+		ctx.Logger.Info("Index entry written", ctx.Logger.Args("path", filepath.Join(opts.IndexDir, notification.Path)))
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(notification)
+}
+
+func readPayload(payloadPath string) ([]byte, error) {
+	if payloadPath == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read webhook payload: %w", err)
+		}
+		return data, nil
+	}
+
+	data, err := os.ReadFile(payloadPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook payload: %w", err)
+	}
+	return data, nil
+}
+
+// writeIndexEntry writes the build config into a plugins index checkout
+func writeIndexEntry(indexDir string, notification *webhook.Notification) error {
+	entryPath := filepath.Join(indexDir, filepath.FromSlash(notification.Path))
+	if err := os.MkdirAll(filepath.Dir(entryPath), 0755); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(notification.Entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize index entry: %w", err)
+	}
+
+	if err := os.WriteFile(entryPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write index entry: %w", err)
+	}
+	return nil
+}
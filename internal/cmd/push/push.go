@@ -0,0 +1,222 @@
+// ABOUTME: Push command publishing a built plugin directory as an OCI artifact
+// ABOUTME: Lays out layers and annotations like the build workflow, optionally attaching an SBOM
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/auth"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/ghactions"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+	"github.com/gillisandrew/dragonglass-poc/internal/registry"
+	"github.com/gillisandrew/dragonglass-poc/internal/ui"
+)
+
+// PushOptions configures the push command
+type PushOptions struct {
+	// Directory holding main.js, manifest.json and optionally styles.css
+	DistDir string
+
+	// Tagged reference to push to
+	ImageRef string
+
+	// SPDX document to attach as a referrer (optional)
+	SBOMPath string
+
+	// Extra manifest annotations as key=value
+	Annotations []string
+}
+
+func NewPushCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "push <dist-dir> <image-ref>",
+		Short: "Publish a built plugin to an OCI registry",
+		Long: `Push a built plugin directory to an OCI registry without the Dagger builder.
+
+The directory must contain main.js and manifest.json; styles.css is included
+when present. manifest.json is validated and published as manifest annotations
+under the configured annotation namespace, and the files are pushed as layers
+with the media types the build workflow uses, so the artifact installs like any
+other. The image reference must include a tag.
+
+With --sbom, an SPDX JSON document (for example from "npm sbom --sbom-format
+spdx") is attached to the pushed manifest as an OCI referrer. The attached SBOM
+is unsigned and informational; verification still requires signed attestations.
+
+The pinned reference (image@digest) is printed on stdout.
+
+Example:
+  dragonglass push ./dist ghcr.io/me/my-plugin:1.2.3
+  dragonglass push ./dist ghcr.io/me/my-plugin:1.2.3 --sbom dist/sbom.spdx.json
+  dragonglass push ./dist ghcr.io/me/my-plugin:1.2.3 --annotation org.opencontainers.image.source=https://github.com/me/my-plugin`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := PushOptions{DistDir: args[0], ImageRef: args[1]}
+			opts.SBOMPath, _ = cmd.Flags().GetString("sbom")
+			opts.Annotations, _ = cmd.Flags().GetStringArray("annotation")
+
+			if err := runPush(cmd.Context(), ctx, opts); err != nil {
+				ctx.Fail("Push failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().String("sbom", "", "SPDX JSON document to attach to the pushed artifact")
+	cmd.Flags().StringArray("annotation", nil, "Extra manifest annotation as key=value (repeatable)")
+	return cmd
+}
+
+func runPush(opCtx context.Context, ctx *cmd.CommandContext, opts PushOptions) error {
+	extra, err := parseAnnotations(opts.Annotations)
+	if err != nil {
+		return err
+	}
+
+	var sbom []byte
+	if opts.SBOMPath != "" {
+		if sbom, err = os.ReadFile(opts.SBOMPath); err != nil {
+			return fmt.Errorf("failed to read SBOM: %w", err)
+		}
+	}
+
+	dist, err := plugin.LoadDist(opts.DistDir)
+	if err != nil {
+		return err
+	}
+	if err := validateDist(ctx, dist, opts.ImageRef); err != nil {
+		return err
+	}
+
+	if err := RequireScopes(ctx, "push"); err != nil {
+		return err
+	}
+
+	client, err := NewRegistryClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	artifact := registry.NewPluginArtifact(dist, ctx.AnnotationNamespace, extra)
+	ctx.Logger.Info("Pushing plugin", ctx.Logger.Args("id", dist.Metadata.ID, "version", dist.Metadata.Version, "reference", opts.ImageRef, "layers", len(artifact.Layers)))
+	desc, err := client.Push(opCtx, opts.ImageRef, artifact)
+	if err != nil {
+		return err
+	}
+	ctx.Logger.Info("Plugin pushed", ctx.Logger.Args("reference", opts.ImageRef, "digest", desc.Digest.String()))
+
+	if sbom != nil {
+		sbomDesc, err := client.AttachSBOM(opCtx, opts.ImageRef, desc, sbom)
+		if err != nil {
+			return err
+		}
+		ctx.Logger.Info("SBOM attached", ctx.Logger.Args("digest", sbomDesc.Digest.String()))
+	}
+
+	fmt.Println(pinnedReference(opts.ImageRef, desc.Digest.String()))
+	return nil
+}
+
+// validateDist rejects manifests that would not install, and warns when the tag does not
+// name the manifest version
+func validateDist(ctx *cmd.CommandContext, dist *plugin.Dist, imageRef string) error {
+	parser := plugin.NewManifestParser(plugin.DefaultPluginOpts().WithStrictValidation(true))
+	result := parser.ValidateMetadata(dist.Metadata)
+	for _, warning := range result.Warnings {
+		ctx.Logger.Warn("manifest.json: "+warning, ctx.Logger.Args("dir", dist.Dir))
+	}
+	if !result.Valid {
+		messages := make([]string, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			messages = append(messages, e.Error())
+		}
+		return fmt.Errorf("invalid manifest.json in %s: %s", dist.Dir, strings.Join(messages, "; "))
+	}
+
+	_, _, tag, err := registry.ParseImageReference(imageRef)
+	if err != nil {
+		return err
+	}
+	if tag != dist.Metadata.Version && tag != "v"+dist.Metadata.Version {
+		ctx.Logger.Warn("Tag does not match the manifest version", ctx.Logger.Args("tag", tag, "version", dist.Metadata.Version))
+	}
+	return nil
+}
+
+// parseAnnotations parses key=value pairs
+func parseAnnotations(pairs []string) (map[string]string, error) {
+	annotations := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid annotation %q (expected key=value)", pair)
+		}
+		annotations[key] = value
+	}
+	return annotations, nil
+}
+
+// pinnedReference replaces the tag of imageRef with digest
+func pinnedReference(imageRef, digest string) string {
+	host, repository, _, err := registry.ParseImageReference(imageRef)
+	if err != nil {
+		return imageRef + "@" + digest
+	}
+	return host + "/" + repository + "@" + digest
+}
+
+// RequireScopes makes sure the stored GitHub credential was granted the scopes command
+// needs. In a terminal the user is offered to sign in again with the missing scopes added;
+// otherwise the InsufficientScopeError is returned. Tokens passed by flag or environment
+// are not checked, since their scopes are unknown.
+func RequireScopes(ctx *cmd.CommandContext, command string) error {
+	if ctx.GitHubToken != "" {
+		return nil
+	}
+
+	required := auth.CommandScopes(command)
+	err := auth.CheckScopes(required)
+	var scopeErr *dgerrors.InsufficientScopeError
+	if !errors.As(err, &scopeErr) || !ui.IsTerminal(os.Stdin) {
+		return err
+	}
+
+	ui.Warning(i18n.T("auth.scope.missing", strings.Join(scopeErr.Missing, ", ")))
+	if escalate, _ := pterm.DefaultInteractiveConfirm.WithDefaultValue(true).Show(i18n.T("auth.scope.prompt")); !escalate {
+		return err
+	}
+	if err := ctx.AuthService.RequestScopes(required); err != nil {
+		return fmt.Errorf("failed to add scopes: %w", err)
+	}
+	return nil
+}
+
+// NewRegistryClient creates a client for publishing. The GitHub token needs the
+// write:packages scope to push to ghcr.io.
+func NewRegistryClient(ctx *cmd.CommandContext) (*registry.Client, error) {
+	cfg := ctx.Config
+	registryOpts := registry.DefaultRegistryOpts().
+		WithTimeout(cfg.Registry.Timeout.Duration()).
+		WithCredentials(cfg.Registry.CredentialLookup()).
+		WithDockerCredentials(registry.DockerCredentialStore(cfg.Registry.DockerCredentials))
+	if ctx.GitHubToken != "" {
+		// The Actions GITHUB_TOKEN cannot be validated against the user API
+		authOpts := auth.DefaultAuthOpts().WithToken(ctx.GitHubToken).WithSkipValidation(ghactions.InActions())
+		registryOpts = registryOpts.WithAuthProvider(auth.NewAuthClient(authOpts))
+	}
+
+	client, err := registry.NewClient(registryOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registry client: %w", err)
+	}
+	return client, nil
+}
@@ -0,0 +1,91 @@
+package push
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+)
+
+func TestParseAnnotations(t *testing.T) {
+	tests := []struct {
+		name     string
+		pairs    []string
+		expected map[string]string
+		errorMsg string
+	}{
+		{name: "none", expected: map[string]string{}},
+		{
+			name:     "value with equals sign",
+			pairs:    []string{"org.opencontainers.image.source=https://example.com/?a=b", "empty="},
+			expected: map[string]string{"org.opencontainers.image.source": "https://example.com/?a=b", "empty": ""},
+		},
+		{name: "missing value", pairs: []string{"key"}, errorMsg: "expected key=value"},
+		{name: "missing key", pairs: []string{"=value"}, errorMsg: "expected key=value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAnnotations(tt.pairs)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			for key, value := range tt.expected {
+				if got[key] != value {
+					t.Errorf("%s: expected %q, got %q", key, value, got[key])
+				}
+			}
+		})
+	}
+}
+
+func TestPinnedReference(t *testing.T) {
+	got := pinnedReference("ghcr.io/me/my-plugin:1.2.3", "sha256:abc")
+	if got != "ghcr.io/me/my-plugin@sha256:abc" {
+		t.Errorf("unexpected pinned reference %q", got)
+	}
+}
+
+func TestValidateDist(t *testing.T) {
+	ctx := &cmd.CommandContext{Logger: pterm.DefaultLogger.WithWriter(io.Discard)}
+
+	tests := []struct {
+		name     string
+		metadata plugin.Metadata
+		errorMsg string
+	}{
+		{name: "valid", metadata: plugin.Metadata{ID: "my-plugin", Name: "My Plugin", Version: "1.2.3"}},
+		{name: "invalid version", metadata: plugin.Metadata{ID: "my-plugin", Name: "My Plugin", Version: "1.2"}, errorMsg: "plugin version must be valid semantic version"},
+		{name: "invalid id", metadata: plugin.Metadata{ID: "My Plugin", Name: "My Plugin", Version: "1.2.3"}, errorMsg: "plugin ID must contain only"},
+		{name: "missing name", metadata: plugin.Metadata{ID: "my-plugin", Version: "1.2.3"}, errorMsg: "plugin name cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dist := &plugin.Dist{Dir: "dist", Metadata: &tt.metadata}
+			err := validateDist(ctx, dist, "ghcr.io/me/my-plugin:1.2.3")
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
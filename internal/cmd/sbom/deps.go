@@ -0,0 +1,263 @@
+// ABOUTME: Deps command printing the dependency tree of an installed plugin from its SBOM
+// ABOUTME: Marks direct and transitive packages and annotates each with its license and vulnerabilities
+package sbom
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/verify"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/output"
+	"github.com/gillisandrew/dragonglass-poc/internal/sbom"
+	"github.com/gillisandrew/dragonglass-poc/internal/ui"
+)
+
+// DepsReport is the dependency graph of an installed plugin, as listed in the SBOM
+// attestation of the artifact the lockfile pins
+type DepsReport struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Digest  string `json:"digest"`
+
+	// Whether the SBOM records dependency relationships; without them no package is
+	// classified as direct or transitive
+	Relationships bool `json:"relationships"`
+
+	// Keys of the packages the SBOM describes, i.e. the plugin itself
+	Roots    []string     `json:"roots"`
+	Packages []DepPackage `json:"packages"`
+}
+
+// DepPackage is a package the plugin bundles
+type DepPackage struct {
+	// name@version, as referenced by depends_on and roots
+	Key     string `json:"key"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+	License string `json:"license,omitempty"`
+
+	// "direct" or "transitive"; omitted when the SBOM records no path to the package
+	Relationship string   `json:"relationship,omitempty"`
+	DependsOn    []string `json:"depends_on,omitempty"`
+
+	Vulnerabilities []attestation.Vulnerability `json:"vulnerabilities,omitempty"`
+}
+
+func NewDepsCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deps <plugin-id>",
+		Short: "Show the dependency tree of an installed plugin",
+		Long: `Print the packages an installed plugin bundles, from the SBOM attestation of
+the artifact the lockfile pins. The attestation is fetched and verified again.
+
+Packages the plugin depends on directly are the top level of the tree, with
+their own dependencies below them; a package reached again is not expanded a
+second time. When the SBOM records no dependency relationships, packages are
+listed without the direct/transitive distinction. Each package shows its
+license and any vulnerabilities found for its version.
+
+--output json, yaml, or go-template='{{...}}' prints the packages as a flat list
+for ingestion, each with its relationship and the keys of its dependencies.
+
+Example:
+  dragonglass deps obsidian-git
+  dragonglass deps obsidian-git -o json`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			renderer, err := ctx.Renderer(cmd)
+			if err != nil {
+				ctx.Fail("Deps failed", err)
+			}
+			if err := runDepsCommand(cmd.Context(), ctx, args[0], renderer); err != nil {
+				ctx.Fail("Deps failed", err)
+			}
+		},
+	}
+
+	output.AddFlag(cmd)
+	return cmd
+}
+
+func runDepsCommand(opCtx context.Context, ctx *cmd.CommandContext, pluginID string, renderer *output.Renderer) error {
+	lockfilePath, err := ctx.ResolveLockfilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+
+	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load lockfile: %w", err)
+	}
+
+	entry, ok := lockfileData.GetPlugin(pluginID)
+	if !ok {
+		return dgerrors.Mark(fmt.Errorf("plugin %s not found in lockfile", pluginID), dgerrors.ErrNotInLockfile)
+	}
+	if entry.IsDev() {
+		return fmt.Errorf("plugin %s is an unverified dev build of %s and has no SBOM attestation", pluginID, entry.OCIReference)
+	}
+
+	ref, err := registry.ParseReference(entry.OCIReference)
+	if err != nil {
+		return fmt.Errorf("invalid OCI reference %s: %w", entry.OCIReference, err)
+	}
+	pinned := ref.Registry + "/" + ref.Repository + "@" + entry.OCIDigest
+
+	verifier, err := verify.NewAttestationVerifier(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctx.Logger.Info("Fetching SBOM attestation", ctx.Logger.Args("id", pluginID, "digest", entry.OCIDigest))
+	result, err := verifier.VerifyAttestations(opCtx, pinned)
+	if err != nil {
+		return fmt.Errorf("failed to fetch attestations: %w", err)
+	}
+	predicate := result.SBOMPredicate()
+	if predicate == nil {
+		return fmt.Errorf("plugin %s has no SBOM attestation", pluginID)
+	}
+
+	var vulnerabilities []attestation.Vulnerability
+	if result.SBOM != nil {
+		vulnerabilities = result.SBOM.Vulnerabilities
+	}
+	report := newDepsReport(pluginID, entry, sbom.ParseSPDXGraph(predicate), vulnerabilities)
+
+	if !renderer.Text() {
+		return renderer.Render(os.Stdout, report)
+	}
+	renderDepsText(report)
+	return nil
+}
+
+// newDepsReport lists the packages of graph with the vulnerabilities found for their
+// versions
+func newDepsReport(pluginID string, entry lockfile.PluginEntry, graph *sbom.Graph, vulnerabilities []attestation.Vulnerability) *DepsReport {
+	report := &DepsReport{
+		ID:            pluginID,
+		Name:          entry.Name,
+		Version:       entry.Version,
+		Digest:        entry.OCIDigest,
+		Relationships: graph.Relationships,
+		Roots:         append([]string{}, graph.Roots...),
+		Packages:      make([]DepPackage, 0, len(graph.Dependencies)),
+	}
+
+	for _, dep := range graph.Dependencies {
+		pkg := DepPackage{
+			Key:          dep.Key(),
+			Name:         dep.Name,
+			Version:      dep.Version,
+			PURL:         dep.PURL,
+			License:      dep.License,
+			Relationship: dep.Relationship,
+			DependsOn:    dep.DependsOn,
+		}
+		for _, vuln := range vulnerabilities {
+			if vuln.Component == dep.Name && (vuln.Version == "" || vuln.Version == dep.Version) {
+				pkg.Vulnerabilities = append(pkg.Vulnerabilities, vuln)
+			}
+		}
+		report.Packages = append(report.Packages, pkg)
+	}
+	return report
+}
+
+// renderDepsText prints the tree of packages below the plugin, then any package the SBOM
+// records no path to
+func renderDepsText(report *DepsReport) {
+	pterm.DefaultSection.Println(i18n.T("deps.section.tree", report.ID, report.Version))
+
+	packages := make(map[string]DepPackage, len(report.Packages))
+	for _, pkg := range report.Packages {
+		packages[pkg.Key] = pkg
+	}
+
+	items := pterm.LeveledList{{Level: 0, Text: report.ID + "@" + report.Version}}
+	expanded := make(map[string]bool)
+	var walk func(keys []string, level int)
+	walk = func(keys []string, level int) {
+		for _, key := range keys {
+			pkg, ok := packages[key]
+			if !ok {
+				continue
+			}
+			text := depLabel(pkg)
+			if expanded[key] && len(pkg.DependsOn) > 0 {
+				text += " " + i18n.T("deps.repeated")
+			}
+			items = append(items, pterm.LeveledListItem{Level: level, Text: text})
+			if !expanded[key] {
+				expanded[key] = true
+				walk(pkg.DependsOn, level+1)
+			}
+		}
+	}
+
+	var direct, transitive, unrelated []string
+	for _, pkg := range report.Packages {
+		switch pkg.Relationship {
+		case sbom.RelationshipDirect:
+			direct = append(direct, pkg.Key)
+		case sbom.RelationshipTransitive:
+			transitive = append(transitive, pkg.Key)
+		default:
+			unrelated = append(unrelated, pkg.Key)
+		}
+	}
+	walk(direct, 1)
+	if len(direct) > 0 {
+		ui.Tree(items)
+	}
+
+	if len(unrelated) > 0 {
+		if report.Relationships {
+			pterm.DefaultSection.Println(i18n.T("deps.section.unrelated"))
+		} else {
+			ui.Warning(i18n.T("deps.no_relationships"))
+		}
+		for _, key := range unrelated {
+			pterm.Println(depLabel(packages[key]))
+		}
+	}
+
+	vulnerable := 0
+	for _, pkg := range report.Packages {
+		if len(pkg.Vulnerabilities) > 0 {
+			vulnerable++
+		}
+	}
+	ui.Info(i18n.T("deps.summary", len(report.Packages), len(direct), len(transitive), vulnerable))
+}
+
+// depLabel formats pkg as name@version with its license and vulnerabilities
+func depLabel(pkg DepPackage) string {
+	license := pkg.License
+	if license == "" {
+		license = i18n.T("deps.no_license")
+	}
+	label := fmt.Sprintf("%s (%s)", pkg.Key, license)
+
+	if len(pkg.Vulnerabilities) > 0 {
+		ids := make([]string, len(pkg.Vulnerabilities))
+		for i, vuln := range pkg.Vulnerabilities {
+			ids[i] = fmt.Sprintf("%s %s", vuln.ID, vuln.Severity)
+		}
+		label += " " + i18n.T("deps.vulnerable", strings.Join(ids, ", "))
+	}
+	return label
+}
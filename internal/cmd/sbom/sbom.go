@@ -0,0 +1,187 @@
+// ABOUTME: SBOM command group for exporting the composition of a vault
+// ABOUTME: Merges the SBOM attestations of locked plugins into one SPDX or CycloneDX document
+package sbom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/verify"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/sbom"
+)
+
+func NewSBOMCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sbom",
+		Short: "Work with software bills of materials",
+	}
+
+	cmd.AddCommand(newExportCommand(ctx))
+	return cmd
+}
+
+// ExportOptions configures the sbom export command
+type ExportOptions struct {
+	// One of sbom.Formats
+	Format string
+
+	// File to write the document to (default: stdout)
+	OutputPath string
+}
+
+func newExportCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export a vault-level SBOM of every locked plugin",
+		Long: `Merge the SBOM attestations of every plugin in the lockfile into a single
+document describing the vault, for software composition analysis tools.
+
+Each plugin appears as a package identified by its pinned OCI digest, with
+its build provenance from the lockfile, and contains the components listed in
+its SBOM attestation. Plugins published without an SBOM are still listed.
+
+Example:
+  dragonglass sbom export --format spdx --output vault.spdx.json
+  dragonglass sbom export --format cyclonedx > vault.cdx.json`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := ExportOptions{}
+			opts.Format, _ = cmd.Flags().GetString("format")
+			opts.OutputPath, _ = cmd.Flags().GetString("output")
+
+			if err := runExport(cmd.Context(), ctx, opts); err != nil {
+				ctx.Fail("SBOM export failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().String("format", sbom.FormatSPDX, "Document format: "+strings.Join(sbom.Formats, ", "))
+	cmd.Flags().StringP("output", "o", "", "Write the SBOM to this file instead of stdout")
+	return cmd
+}
+
+func runExport(opCtx context.Context, ctx *cmd.CommandContext, opts ExportOptions) error {
+	if !slices.Contains(sbom.Formats, opts.Format) {
+		return fmt.Errorf("unsupported SBOM format %q (must be one of %s)", opts.Format, strings.Join(sbom.Formats, ", "))
+	}
+
+	v, err := ctx.DiscoverVault()
+	if err != nil {
+		return fmt.Errorf("failed to find vault: %w", err)
+	}
+
+	lockfilePath, err := ctx.ResolveLockfilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+
+	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load lockfile: %w", err)
+	}
+
+	pluginIDs := make([]string, 0, len(lockfileData.Plugins))
+	for pluginID, entry := range lockfileData.Plugins {
+		if entry.IsDev() {
+			ctx.Logger.Warn("Leaving out dev build, which has no SBOM attestation", ctx.Logger.Args("id", pluginID))
+			continue
+		}
+		pluginIDs = append(pluginIDs, pluginID)
+	}
+	sort.Strings(pluginIDs)
+
+	// One verifier is shared so the Sigstore trusted root is fetched once for all plugins
+	verifier, err := verify.NewAttestationVerifier(ctx)
+	if err != nil {
+		return err
+	}
+
+	plugins := make([]sbom.Plugin, len(pluginIDs))
+	scheduler := attestation.NewScheduler(ctx.Config.Verification.Concurrency)
+	errs := scheduler.Run(opCtx, len(pluginIDs), func(jobCtx context.Context, i int) error {
+		pluginID := pluginIDs[i]
+		entry := lockfileData.Plugins[pluginID]
+		plugins[i] = sbom.Plugin{
+			ID:                 pluginID,
+			Name:               entry.Name,
+			Version:            entry.Version,
+			OCIReference:       entry.OCIReference,
+			OCIDigest:          entry.OCIDigest,
+			BuilderID:          entry.VerificationState.BuilderID,
+			ProvenanceVerified: entry.VerificationState.ProvenanceVerified,
+		}
+
+		components, err := pluginComponents(jobCtx, verifier, entry)
+		if err != nil {
+			return fmt.Errorf("%s: %w", pluginID, err)
+		}
+		if components == nil {
+			ctx.Logger.Warn("Plugin has no SBOM attestation", ctx.Logger.Args("id", pluginID))
+		}
+		plugins[i].Components = components
+		return nil
+	})
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	doc := &sbom.Document{
+		Name:    filepath.Base(v.Root),
+		Created: time.Now(),
+		Plugins: plugins,
+	}
+
+	var out io.Writer = os.Stdout
+	if opts.OutputPath != "" {
+		file, err := os.Create(opts.OutputPath)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", opts.OutputPath, err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := sbom.Write(out, doc, opts.Format); err != nil {
+		return err
+	}
+
+	if opts.OutputPath != "" {
+		ctx.Logger.Info("Exported vault SBOM", ctx.Logger.Args("format", opts.Format, "plugins", len(plugins), "output", opts.OutputPath))
+	}
+	return nil
+}
+
+// pluginComponents fetches the SBOM attestation for the artifact the lockfile pins and
+// returns its components, or nil when the plugin has no SBOM attestation
+func pluginComponents(ctx context.Context, verifier *attestation.AttestationVerifier, entry lockfile.PluginEntry) ([]sbom.Component, error) {
+	ref, err := registry.ParseReference(entry.OCIReference)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OCI reference %s: %w", entry.OCIReference, err)
+	}
+	pinned := ref.Registry + "/" + ref.Repository + "@" + entry.OCIDigest
+
+	result, err := verifier.VerifyAttestations(ctx, pinned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attestations: %w", err)
+	}
+
+	predicate := result.SBOMPredicate()
+	if predicate == nil {
+		return nil, nil
+	}
+	return sbom.ParseSPDXPackages(predicate), nil
+}
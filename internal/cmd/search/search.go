@@ -0,0 +1,201 @@
+// ABOUTME: Search command for finding plugins in the published plugin catalog
+// ABOUTME: Loads the catalog by digest, verifying its provenance, for search and add by name
+package search
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
+	"github.com/gillisandrew/dragonglass-poc/internal/auth"
+	"github.com/gillisandrew/dragonglass-poc/internal/catalog"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
+	"github.com/gillisandrew/dragonglass-poc/internal/output"
+	"github.com/gillisandrew/dragonglass-poc/internal/registry"
+	"github.com/gillisandrew/dragonglass-poc/internal/userdirs"
+)
+
+func NewSearchCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search [query]",
+		Short: "Search the plugin catalog",
+		Long: `Search the catalog of plugins built from the plugins index. Every word of the
+query must appear in a plugin's ID, name, description, or GitHub repository;
+without a query every plugin is listed.
+
+The catalog is fetched by digest from catalog.reference and its provenance is
+verified against catalog.trusted_builder before use. It is cached in the user
+cache directory and the reference is checked for a newer catalog once
+catalog.ttl has passed; --refresh checks now. Offline, an expired cached
+catalog is used with a warning.
+
+Install a result by name with dragonglass add <id>[@version].
+
+Example:
+  dragonglass search calendar
+  dragonglass search --refresh -o json`,
+		Run: func(cmd *cobra.Command, args []string) {
+			refresh, _ := cmd.Flags().GetBool("refresh")
+			renderer, err := ctx.Renderer(cmd)
+			if err != nil {
+				ctx.Fail("Search failed", err)
+			}
+			if err := runSearchCommand(cmd.Context(), ctx, strings.Join(args, " "), refresh, renderer); err != nil {
+				ctx.Fail("Search failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("refresh", false, "Check for a newer catalog even if the cached one has not expired")
+	output.AddFlag(cmd)
+	return cmd
+}
+
+func runSearchCommand(opCtx context.Context, ctx *cmd.CommandContext, query string, refresh bool, renderer *output.Renderer) error {
+	loaded, err := LoadCatalog(opCtx, ctx, refresh)
+	if err != nil {
+		return err
+	}
+	plugins := loaded.Search(query)
+
+	if !renderer.Text() {
+		if plugins == nil {
+			plugins = []catalog.Plugin{}
+		}
+		return renderer.Render(os.Stdout, plugins)
+	}
+
+	if len(plugins) == 0 {
+		ctx.Logger.Info("No plugins in the catalog match the query", ctx.Logger.Args("query", query))
+		return nil
+	}
+
+	tableData := pterm.TableData{
+		{
+			i18n.T("search.header.id"),
+			i18n.T("search.header.name"),
+			i18n.T("search.header.latest"),
+			i18n.T("search.header.repository"),
+			i18n.T("search.header.description"),
+		},
+	}
+	for _, p := range plugins {
+		tableData = append(tableData, []string{p.ID, p.Name, p.Latest(), p.Repository, truncate(p.Description, 60)})
+	}
+	pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+	return nil
+}
+
+// LoadCatalog returns the catalog at catalog.reference, from the user cache while it is
+// within catalog.ttl. A fetched catalog must carry provenance from catalog.trusted_builder.
+func LoadCatalog(opCtx context.Context, ctx *cmd.CommandContext, refresh bool) (*catalog.Loaded, error) {
+	cfg := ctx.Config
+
+	registryOpts := registry.DefaultRegistryOpts().
+		WithTimeout(cfg.Registry.Timeout.Duration()).
+		WithCredentials(cfg.Registry.CredentialLookup()).
+		WithDockerCredentials(registry.DockerCredentialStore(cfg.Registry.DockerCredentials))
+	if ctx.GitHubToken != "" {
+		registryOpts = registryOpts.WithAuthProvider(auth.NewAuthClient(auth.DefaultAuthOpts().WithToken(ctx.GitHubToken)))
+	}
+	client, err := registry.NewClient(registryOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registry client: %w", err)
+	}
+
+	opts := catalog.DefaultOpts(cfg.Catalog.Reference, &registryFetcher{ctx: ctx, client: client}).
+		WithTTL(cfg.Catalog.TTL.Duration()).
+		WithRefresh(refresh)
+	if dirs, err := userdirs.Resolve(); err == nil {
+		opts = opts.WithCacheDir(dirs.CatalogCacheDir())
+	} else {
+		ctx.Logger.Warn("Catalog will not be cached", ctx.Logger.Args("error", err))
+	}
+
+	loaded, err := catalog.Load(opCtx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if loaded.Stale {
+		ctx.Logger.Warn("Could not check for a newer catalog; using the cached copy", ctx.Logger.Args("reference", cfg.Catalog.Reference, "fetchedAt", loaded.FetchedAt))
+	}
+	ctx.Logger.Debug("Loaded plugin catalog", ctx.Logger.Args("reference", cfg.Catalog.Reference, "digest", loaded.Digest, "plugins", len(loaded.Plugins)))
+	return loaded, nil
+}
+
+// registryFetcher reads the catalog from its registry, verifying its provenance
+type registryFetcher struct {
+	ctx    *cmd.CommandContext
+	client *registry.Client
+}
+
+func (f *registryFetcher) Resolve(ctx context.Context, reference string) (string, error) {
+	desc, err := f.client.Resolve(ctx, reference)
+	if err != nil {
+		return "", err
+	}
+	return desc.Digest.String(), nil
+}
+
+func (f *registryFetcher) Fetch(ctx context.Context, reference, digest string) ([]byte, error) {
+	pinned, err := pinReference(reference, digest)
+	if err != nil {
+		return nil, err
+	}
+	artifact, err := f.client.OpenArtifact(ctx, pinned)
+	if err != nil {
+		return nil, err
+	}
+	if artifact.Manifest.ArtifactType != catalog.ArtifactType {
+		return nil, fmt.Errorf("%s is not a plugin catalog (artifact type %q)", reference, artifact.Manifest.ArtifactType)
+	}
+
+	token := f.ctx.GitHubToken
+	if token == "" {
+		if token, err = auth.GetToken(); err != nil {
+			return nil, fmt.Errorf("failed to get authentication token for catalog verification: %w", err)
+		}
+	}
+	verifier, err := attestation.NewAttestationVerifier(token, f.ctx.Config.Catalog.TrustedBuilder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attestation verifier: %w", err)
+	}
+	result, err := verifier.VerifyArtifact(ctx, artifact)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify catalog: %w", err)
+	}
+	if err := result.Err(); err != nil {
+		return nil, fmt.Errorf("catalog %s failed verification: %w", pinned, err)
+	}
+
+	return f.client.FetchLayer(ctx, pinned, artifact.Manifest, catalog.FileName)
+}
+
+// pinReference replaces the tag or digest of reference with digest
+func pinReference(reference, digest string) (string, error) {
+	repository := reference
+	if i := strings.LastIndex(repository, "@"); i >= 0 {
+		repository = repository[:i]
+	} else if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+	if repository == "" {
+		return "", fmt.Errorf("invalid catalog reference %q", reference)
+	}
+	return repository + "@" + digest, nil
+}
+
+// truncate shortens s to n runes for the table, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,39 @@
+package search
+
+import "testing"
+
+const digest = "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+
+func TestPinReference(t *testing.T) {
+	tests := []struct {
+		reference string
+		want      string
+		wantErr   bool
+	}{
+		{reference: "ghcr.io/owner/index/catalog:latest", want: "ghcr.io/owner/index/catalog@" + digest},
+		{reference: "ghcr.io/owner/index/catalog", want: "ghcr.io/owner/index/catalog@" + digest},
+		{reference: "localhost:5000/catalog:v1", want: "localhost:5000/catalog@" + digest},
+		{reference: "ghcr.io/owner/catalog@sha256:bbbb", want: "ghcr.io/owner/catalog@" + digest},
+		{reference: ":latest", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			got, err := pinReference(tt.reference, digest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("short", 10); got != "short" {
+		t.Errorf("expected short text unchanged, got %q", got)
+	}
+	if got := truncate("Täglich Notizen", 8); got != "Täglich…" {
+		t.Errorf("expected a cut at 8 runes, got %q", got)
+	}
+}
@@ -0,0 +1,164 @@
+// ABOUTME: Serve command running the read-only verification HTTP API
+// ABOUTME: Centralizes Sigstore verification so thin clients only need HTTP
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/verify"
+	"github.com/gillisandrew/dragonglass-poc/internal/metrics"
+	"github.com/gillisandrew/dragonglass-poc/internal/server"
+	"github.com/gillisandrew/dragonglass-poc/internal/warnings"
+)
+
+// shutdownTimeout bounds how long in-flight requests may finish after an interrupt
+const shutdownTimeout = 10 * time.Second
+
+// ServeOptions configures the serve command
+type ServeOptions struct {
+	// Address to listen on, e.g. ":8080"
+	Listen string
+
+	// How long verification results are reused for the same reference
+	CacheTTL time.Duration
+
+	// Serve GET /metrics
+	Metrics bool
+}
+
+func NewServeCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a read-only verification API over HTTP",
+		Long: `Run an HTTP server that verifies plugins on behalf of clients, so teams can
+centralize verification and clients need neither Sigstore nor a GitHub token.
+
+Endpoints (GET only):
+  /verify?ref=REF  verify REF against the configured policy; 200 when it passes,
+                   422 when it fails the policy, 502 when the registry is down
+  /plugins         plugins pinned in the lockfile and their verification state
+  /metrics         verification, download and cache metrics for Prometheus
+  /healthz         liveness check
+
+Results are cached per reference for --cache-ttl. Verifications run at most
+verification.concurrency at a time.
+
+Example:
+  dragonglass serve --listen :8080
+  curl 'localhost:8080/verify?ref=ghcr.io/owner/repo:plugin-name-v1.0.0'`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := ServeOptions{}
+			opts.Listen, _ = cmd.Flags().GetString("listen")
+			opts.CacheTTL, _ = cmd.Flags().GetDuration("cache-ttl")
+			opts.Metrics, _ = cmd.Flags().GetBool("metrics")
+
+			if err := runServe(cmd.Context(), ctx, opts); err != nil {
+				ctx.Fail("Server failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().String("listen", ":8080", "Address to listen on")
+	cmd.Flags().Duration("cache-ttl", server.DefaultCacheTTL, "How long to reuse a verification result (0 disables caching)")
+	cmd.Flags().Bool("metrics", true, "Serve Prometheus metrics on /metrics")
+	return cmd
+}
+
+func runServe(opCtx context.Context, ctx *cmd.CommandContext, opts ServeOptions) error {
+	// One verifier is shared so the Sigstore trusted root is fetched once for all requests
+	verifier, err := verify.NewAttestationVerifier(ctx)
+	if err != nil {
+		return err
+	}
+
+	lockfilePath, err := ctx.ResolveLockfilePath()
+	if err != nil {
+		ctx.Logger.Warn("No lockfile found; /plugins is disabled", ctx.Logger.Args("error", err))
+		lockfilePath = ""
+	}
+
+	serverOpts := server.DefaultServerOpts().
+		WithVerify(verifyFunc(ctx, verifier)).
+		WithLockfilePath(lockfilePath).
+		WithCacheTTL(opts.CacheTTL).
+		WithConcurrency(ctx.Config.Verification.Concurrency)
+	if opts.Metrics {
+		serverOpts = serverOpts.WithMetrics(metrics.Default)
+	}
+	srv := server.New(serverOpts)
+
+	listener, err := net.Listen("tcp", opts.Listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", opts.Listen, err)
+	}
+
+	httpServer := &http.Server{
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-opCtx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	ctx.Logger.Info("Serving verification API", ctx.Logger.Args("address", listener.Addr().String(), "lockfile", lockfilePath))
+	if err := httpServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server stopped: %w", err)
+	}
+
+	ctx.Logger.Info("Server stopped")
+	return nil
+}
+
+// verifyFunc verifies references with the verify command's checks and policy
+func verifyFunc(ctx *cmd.CommandContext, verifier *attestation.AttestationVerifier) server.VerifyFunc {
+	return func(reqCtx context.Context, ref string) (*server.VerifyResponse, error) {
+		ctx.Logger.Info("Verifying plugin", ctx.Logger.Args("imageRef", ref))
+
+		opts := verify.DefaultVerifyOpts().
+			WithSkipTokenValidation(true).
+			WithVerifier(verifier)
+		result, err := verify.Verify(reqCtx, ctx, ref, opts)
+		if err != nil {
+			ctx.Logger.Warn("Verification failed", ctx.Logger.Args("imageRef", ref, "error", err))
+		}
+		return NewVerifyResponse(result), err
+	}
+}
+
+// NewVerifyResponse describes a verify.Result, which may be partial when verification failed
+func NewVerifyResponse(result *verify.Result) *server.VerifyResponse {
+	response := &server.VerifyResponse{}
+	if result == nil {
+		return response
+	}
+
+	response.ManifestDigest = result.ManifestDigest
+	response.Warnings = warnings.Messages(result.Warnings)
+	if result.Metadata != nil {
+		response.PluginID = result.Metadata.ID
+		response.Name = result.Metadata.Name
+		response.Version = result.Metadata.Version
+	}
+	if a := result.Attestation; a != nil {
+		if a.SLSA != nil {
+			response.ProvenanceVerified = a.SLSA.Valid
+			response.BuilderID = a.SLSA.Builder
+		}
+		response.SBOMVerified = a.SBOM != nil && a.SBOM.Valid
+	}
+	return response
+}
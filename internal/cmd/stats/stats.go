@@ -0,0 +1,110 @@
+// ABOUTME: Stats command printing verification, download, and cache metrics of past runs
+// ABOUTME: Reads the stats file each CLI run adds its metrics to when it exits
+package stats
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
+	"github.com/gillisandrew/dragonglass-poc/internal/metrics"
+	"github.com/gillisandrew/dragonglass-poc/internal/output"
+	"github.com/gillisandrew/dragonglass-poc/internal/ui"
+	"github.com/gillisandrew/dragonglass-poc/internal/userdirs"
+)
+
+// Stats is the structured output of the stats command
+type Stats struct {
+	metrics.Snapshot
+	CacheHitRatio float64 `json:"cache_hit_ratio"`
+}
+
+func NewStatsCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Print verification and download statistics of past runs",
+		Long: `Print how many verifications passed, failed the policy, or errored, the mean
+verification time, bytes downloaded from registries, and the verification cache
+hit ratio, added up over every dragonglass run since the statistics were reset.
+--output json, yaml, or go-template='{{...}}' prints them for scripts.
+
+Statistics are kept in stats.json in the user cache directory. The serve
+command exposes the same metrics for its own process on /metrics, and the
+daemon through its metrics method.
+
+Example:
+  dragonglass stats
+  dragonglass stats --reset`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			reset, _ := cmd.Flags().GetBool("reset")
+			renderer, err := ctx.Renderer(cmd)
+			if err != nil {
+				ctx.Fail("Stats command failed", err)
+			}
+
+			if err := runStatsCommand(ctx, reset, renderer); err != nil {
+				ctx.Fail("Stats command failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("reset", false, "Clear the recorded statistics")
+	output.AddFlag(cmd)
+	return cmd
+}
+
+func runStatsCommand(ctx *cmd.CommandContext, reset bool, renderer *output.Renderer) error {
+	dirs, err := userdirs.Resolve()
+	if err != nil {
+		return fmt.Errorf("failed to resolve user directories: %w", err)
+	}
+	path := dirs.StatsFile()
+
+	if reset {
+		if err := metrics.ResetStats(path); err != nil {
+			return err
+		}
+		ui.Success(i18n.T("stats.reset"))
+		return nil
+	}
+
+	snapshot, err := metrics.LoadStats(path)
+	if err != nil {
+		return err
+	}
+
+	if !renderer.Text() {
+		return renderer.Render(os.Stdout, Stats{Snapshot: snapshot, CacheHitRatio: snapshot.CacheHitRatio()})
+	}
+
+	if snapshot.Empty() {
+		ui.Info(i18n.T("stats.none"))
+		return nil
+	}
+	return pterm.DefaultTable.WithData(rows(snapshot)).Render()
+}
+
+// rows renders the statistics as a two-column table
+func rows(s metrics.Snapshot) pterm.TableData {
+	rows := pterm.TableData{}
+	if s.Since != nil {
+		rows = append(rows, []string{i18n.T("stats.since"), s.Since.Local().Format(time.DateTime)})
+	}
+
+	lookups := s.CacheLookups[metrics.CacheHit] + s.CacheLookups[metrics.CacheMiss]
+	latency := time.Duration(s.VerificationSeconds.Mean() * float64(time.Second))
+	return append(rows, pterm.TableData{
+		{i18n.T("stats.verified"), fmt.Sprint(s.Verifications[metrics.OutcomeVerified])},
+		{i18n.T("stats.failed"), fmt.Sprint(s.Verifications[metrics.OutcomeFailed])},
+		{i18n.T("stats.errors"), fmt.Sprint(s.Verifications[metrics.OutcomeError])},
+		{i18n.T("stats.mean_latency"), latency.Round(time.Millisecond).String()},
+		{i18n.T("stats.downloaded"), ui.FormatBytes(s.DownloadBytes)},
+		{i18n.T("stats.cache_hit_ratio"), i18n.T("stats.cache_lookups", s.CacheHitRatio()*100, lookups)},
+	}...)
+}
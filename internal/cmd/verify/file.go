@@ -0,0 +1,96 @@
+// ABOUTME: verify-file command for plugin files distributed outside OCI registries
+// ABOUTME: Checks a file's digest against the attestations its repository published on GitHub
+package verify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/metrics"
+)
+
+func NewVerifyFileCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-file <file> --repo <owner/name>",
+		Short: "Verify a plugin file against its GitHub artifact attestations",
+		Long: `Verify a plugin file, such as a main.js downloaded from a GitHub release,
+that was not published as an OCI artifact.
+
+The file's SHA-256 digest is looked up in the GitHub attestation API for the
+repository given with --repo. Attestations must be signed for that digest, and
+provenance must come from the trusted builder. Strict mode and the
+vulnerability policy apply as for dragonglass verify.
+
+Example:
+  dragonglass verify-file ./main.js --repo owner/obsidian-plugin`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			repository, _ := cmd.Flags().GetString("repo")
+			ctx.Logger.Info("Verifying file", ctx.Logger.Args("file", args[0], "repo", repository))
+
+			result, err := VerifyFile(cmd.Context(), ctx, args[0], repository, nil)
+			if err != nil {
+				ctx.Fail("Verification failed", err)
+			}
+
+			ctx.Logger.Info("File verification completed successfully", ctx.Logger.Args("digest", result.ArtifactDigest))
+		},
+	}
+
+	cmd.Flags().String("repo", "", "GitHub repository that attested the file (owner/name)")
+	_ = cmd.MarkFlagRequired("repo")
+	return cmd
+}
+
+// VerifyFile checks the attestations repository published for the file at path against
+// the verification policy in ctx.Config. A nil verifier is created from ctx.
+func VerifyFile(opCtx context.Context, ctx *cmd.CommandContext, path, repository string, verifier *attestation.AttestationVerifier) (result *attestation.VerificationResult, err error) {
+	cfg := ctx.Config
+	defer func(start time.Time) { metrics.RecordVerification(err, time.Since(start)) }(time.Now())
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	fileDigest, err := digest.SHA256.FromReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	ctx.Logger.Debug("Computed file digest", ctx.Logger.Args("digest", fileDigest.String()))
+
+	if verifier == nil {
+		if verifier, err = NewAttestationVerifier(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// Create context bounded by verification.timeout
+	opCtx, cancel := context.WithTimeout(opCtx, cfg.Verification.Timeout.Duration())
+	defer cancel()
+	defer func() { err = cfg.WrapTimeout(opCtx, "verification.timeout", err) }()
+
+	result, err = verifier.VerifyFileAttestations(opCtx, repository, fileDigest.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify attestations: %w", err)
+	}
+
+	for _, warning := range result.Findings.Warnings() {
+		ctx.Logger.Debug("Attestation verification debug info", ctx.Logger.Args("check", warning.Check, "info", warning.Message))
+	}
+	logChecks(ctx, result)
+
+	warnings, err := checkAttestationPolicy(cfg.Verification.StrictMode, result)
+	for _, warning := range warnings {
+		ctx.Logger.Warn("Verification warning", ctx.Logger.Args("warning", warning))
+	}
+	return result, err
+}
@@ -0,0 +1,87 @@
+// ABOUTME: Per-plugin policy overrides declared in the vault's dragonglass.json manifest
+// ABOUTME: Applies them over the vault configuration and checks provenance against them
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/manifest"
+)
+
+// DeclaredPolicy returns the policy overrides the vault manifest declares for pluginID,
+// or nil when there is no vault, no manifest, or no overrides for the plugin
+func DeclaredPolicy(ctx *cmd.CommandContext, pluginID string) (*manifest.PolicyOverride, error) {
+	v, err := ctx.DiscoverVault()
+	if err != nil {
+		return nil, nil
+	}
+
+	projectManifest, err := manifest.LoadManifest(v.ManifestPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", manifest.ManifestFileName, err)
+	}
+	return projectManifest.Plugins[pluginID].Policy, nil
+}
+
+// ApplyPolicy returns the command context and config to use for a plugin once its
+// policy overrides are applied. The inputs are never modified.
+func ApplyPolicy(ctx *cmd.CommandContext, cfg *config.Config, policy *manifest.PolicyOverride) (*cmd.CommandContext, *config.Config) {
+	if policy == nil {
+		return ctx, cfg
+	}
+
+	pluginCtx := *ctx
+	pluginCfg := *cfg
+	if policy.TrustedBuilder != "" {
+		pluginCtx.TrustedBuilder = policy.TrustedBuilder
+	}
+	if policy.StrictMode != nil {
+		pluginCfg.Verification.StrictMode = *policy.StrictMode
+	}
+	return &pluginCtx, &pluginCfg
+}
+
+// CheckPolicy requires the provenance in result to name the builder and source repository
+// policy pins, and records the outcome as the result's hard policy check. Unlike the
+// vault-wide trusted builder, a pinned identity is enforced outside strict mode too.
+func CheckPolicy(policy *manifest.PolicyOverride, result *attestation.VerificationResult) error {
+	if policy == nil || (policy.TrustedBuilder == "" && policy.SourceRepository == "") {
+		return nil
+	}
+	if result == nil {
+		return dgerrors.Mark(fmt.Errorf("plugin policy pins its provenance but no SLSA provenance was found"), dgerrors.ErrAttestationNotFound)
+	}
+
+	err := policyErr(policy, result.SLSA)
+	if err != nil {
+		result.AddCheck(attestation.CheckPluginPolicy, attestation.CheckFailed, attestation.SeverityHard, err.Error())
+		result.Valid = false
+	} else {
+		result.AddCheck(attestation.CheckPluginPolicy, attestation.CheckPassed, attestation.SeverityHard, "")
+	}
+	return err
+}
+
+// policyErr explains how slsa differs from the provenance policy pins
+func policyErr(policy *manifest.PolicyOverride, slsa *attestation.SLSAResult) error {
+	if slsa == nil {
+		return dgerrors.Mark(fmt.Errorf("plugin policy pins its provenance but no SLSA provenance was found"), dgerrors.ErrAttestationNotFound)
+	}
+	if policy.TrustedBuilder != "" && slsa.Builder != policy.TrustedBuilder {
+		return &dgerrors.UntrustedBuilderError{Builder: slsa.Builder, TrustedBuilder: policy.TrustedBuilder}
+	}
+	if policy.SourceRepository != "" && !strings.EqualFold(slsa.Repository, policy.SourceRepository) {
+		return dgerrors.Mark(fmt.Errorf("provenance source repository %q does not match policy source_repository %q", slsa.Repository, policy.SourceRepository), dgerrors.ErrAttestationInvalid)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package verify
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/manifest"
+)
+
+func TestApplyPolicy(t *testing.T) {
+	baseCtx := &cmd.CommandContext{TrustedBuilder: "https://example.com/default"}
+	baseCfg := config.DefaultConfig()
+	strict := baseCfg.Verification.StrictMode
+
+	t.Run("no policy returns inputs", func(t *testing.T) {
+		gotCtx, gotCfg := ApplyPolicy(baseCtx, baseCfg, nil)
+		if gotCtx != baseCtx || gotCfg != baseCfg {
+			t.Error("expected inputs to be returned unchanged")
+		}
+	})
+
+	t.Run("overrides are applied to copies", func(t *testing.T) {
+		relaxed := !strict
+		policy := &manifest.PolicyOverride{
+			StrictMode:     &relaxed,
+			TrustedBuilder: "https://example.com/other",
+		}
+
+		gotCtx, gotCfg := ApplyPolicy(baseCtx, baseCfg, policy)
+		if gotCtx.TrustedBuilder != "https://example.com/other" {
+			t.Errorf("expected trusted builder override, got %s", gotCtx.TrustedBuilder)
+		}
+		if gotCfg.Verification.StrictMode != relaxed {
+			t.Errorf("expected strict mode %t, got %t", relaxed, gotCfg.Verification.StrictMode)
+		}
+		if baseCtx.TrustedBuilder != "https://example.com/default" || baseCfg.Verification.StrictMode != strict {
+			t.Error("expected base context and config to be left untouched")
+		}
+	})
+}
+
+func TestCheckPolicy(t *testing.T) {
+	builder := "https://github.com/owner/repo/.github/workflows/release.yml@refs/heads/main"
+	provenance := &attestation.VerificationResult{SLSA: &attestation.SLSAResult{Builder: builder, Repository: "Owner/Repo"}}
+
+	tests := []struct {
+		name    string
+		policy  *manifest.PolicyOverride
+		result  *attestation.VerificationResult
+		wantErr error
+	}{
+		{name: "no policy", result: &attestation.VerificationResult{}},
+		{name: "update policy only", policy: &manifest.PolicyOverride{Hold: true}, result: &attestation.VerificationResult{}},
+		{name: "matching builder and repository", policy: &manifest.PolicyOverride{TrustedBuilder: builder, SourceRepository: "owner/repo"}, result: provenance},
+		{name: "missing provenance", policy: &manifest.PolicyOverride{SourceRepository: "owner/repo"}, result: &attestation.VerificationResult{}, wantErr: dgerrors.ErrAttestationNotFound},
+		{name: "other builder", policy: &manifest.PolicyOverride{TrustedBuilder: "https://example.com/other"}, result: provenance, wantErr: dgerrors.ErrUntrustedBuilder},
+		{name: "other repository", policy: &manifest.PolicyOverride{SourceRepository: "fork/repo"}, result: provenance, wantErr: dgerrors.ErrAttestationInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckPolicy(tt.policy, tt.result)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+
+			// Pinned provenance is recorded as a hard check
+			pinned := tt.policy != nil && (tt.policy.TrustedBuilder != "" || tt.policy.SourceRepository != "")
+			check, recorded := tt.result.Check(attestation.CheckPluginPolicy)
+			if recorded != pinned {
+				t.Fatalf("expected policy check recorded=%v, got %+v", pinned, tt.result.Checks)
+			}
+			if recorded && (check.Severity != attestation.SeverityHard || (check.Status == attestation.CheckFailed) != (tt.wantErr != nil)) {
+				t.Errorf("unexpected policy check %+v", check)
+			}
+		})
+	}
+}
@@ -6,69 +6,241 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/gillisandrew/dragonglass-poc/internal/analysis"
 	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
 	"github.com/gillisandrew/dragonglass-poc/internal/auth"
 	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
-	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/metrics"
+	"github.com/gillisandrew/dragonglass-poc/internal/oci"
+	"github.com/gillisandrew/dragonglass-poc/internal/output"
 	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
 	"github.com/gillisandrew/dragonglass-poc/internal/registry"
+	"github.com/gillisandrew/dragonglass-poc/internal/warnings"
 )
 
 func NewVerifyCommand(ctx *cmd.CommandContext) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "verify [OCI_IMAGE_REFERENCE]",
 		Short: "Verify a plugin without installing it",
 		Long: `Verify an Obsidian plugin's provenance and security without installation.
 This command downloads and verifies SLSA attestations, SBOM data, and
 vulnerability information, then displays the results.
 
+With --analyze, main.js is also scanned for risk indicators: runtime code
+evaluation, child processes, filesystem access and paths outside the vault,
+network endpoints, and obfuscation. Findings are reported for review, and
+verification fails when any reaches verification.block_risk.
+
+With --warnings-as-errors, warnings fail verification with exit code 7.
+--ignore-warning exempts a category: metadata, attestation, sbom, or
+vulnerability.
+
+--expected-digest and --expected-version assert that the reference resolves to
+the given manifest digest and that the artifact is annotated with the given
+version, failing with exit code 4 otherwise. Release pipelines use them to check
+the registry serves what they published.
+
+--attestation-file verifies the Sigstore bundles in the given files, such as
+those attached to a GitHub release or written by gh attestation download,
+instead of discovering attestations in the registry. Each file holds a bundle
+or JSON Lines with one bundle per line. Bundles must be signed for the
+reference's manifest digest (or its image index), unsigned statements are
+rejected, and the rest of the policy applies unchanged.
+
+--output json, yaml, or go-template='{{...}}' prints the verification result,
+including why it failed, on stdout; e.g. go-template='{{.attestation.slsa.builder}}'.
+
 Example:
-  dragonglass verify ghcr.io/owner/repo:plugin-name-v1.0.0`,
+  dragonglass verify ghcr.io/owner/repo:plugin-name-v1.0.0
+  dragonglass verify --analyze ghcr.io/owner/repo:plugin-name-v1.0.0
+  dragonglass verify --warnings-as-errors --ignore-warning sbom ghcr.io/owner/repo:plugin-name-v1.0.0
+  dragonglass verify --expected-digest sha256:4f3c... --expected-version 1.0.0 ghcr.io/owner/repo:plugin-name-v1.0.0
+  dragonglass verify --attestation-file provenance.sigstore.json --attestation-file sbom.sigstore.json ghcr.io/owner/repo:plugin-name-v1.0.0`,
 		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			imageRef := args[0]
+			analyze, _ := cmd.Flags().GetBool("analyze")
+			expectedDigest, _ := cmd.Flags().GetString("expected-digest")
+			expectedVersion, _ := cmd.Flags().GetString("expected-version")
+			attestationFiles, _ := cmd.Flags().GetStringSlice("attestation-file")
+			policy, err := warnings.PolicyFromFlags(cmd)
+			if err != nil {
+				ctx.Fail("Verification failed", err)
+			}
+			renderer, err := ctx.Renderer(cmd)
+			if err != nil {
+				ctx.Fail("Verification failed", err)
+			}
 			ctx.Logger.Info("Verifying plugin", ctx.Logger.Args("imageRef", imageRef))
 
-			if err := verifyPlugin(imageRef, ctx); err != nil {
-				ctx.Logger.Error("Verification failed", ctx.Logger.Args("error", err))
-				os.Exit(1)
+			result, err := Verify(cmd.Context(), ctx, imageRef, DefaultVerifyOpts().
+				WithAnalyze(analyze).
+				WithExpectedDigest(expectedDigest).
+				WithExpectedVersion(expectedVersion).
+				WithAttestationFiles(attestationFiles))
+			if err == nil {
+				err = policy.Check(result.Warnings)
 			}
 
-			ctx.Logger.Info("Plugin verification completed successfully")
+			// Structured output reports failures too, so scripts can tell why
+			if !renderer.Text() {
+				if renderErr := renderer.Render(os.Stdout, newReport(imageRef, result, err)); renderErr != nil {
+					ctx.Fail("Verification failed", renderErr)
+				}
+			}
+			if err != nil {
+				ctx.Fail("Verification failed", err)
+			}
+
+			ctx.Logger.Info("Plugin verification completed successfully", ctx.Logger.Args("warnings", len(result.Warnings)))
 		},
 	}
+
+	cmd.Flags().Bool("analyze", false, "Scan main.js for risky code patterns and report the findings")
+	cmd.Flags().String("expected-digest", "", "Fail unless the reference resolves to this manifest digest")
+	cmd.Flags().String("expected-version", "", "Fail unless the artifact is annotated with this plugin version")
+	cmd.Flags().StringSlice("attestation-file", nil, "Verify the Sigstore bundles in this file instead of discovering attestations in the registry (repeatable)")
+	warnings.AddFlags(cmd)
+	output.AddFlag(cmd)
+	return cmd
 }
 
-func verifyPlugin(imageRef string, ctx *cmd.CommandContext) error {
-	ctx.Logger.Debug("Creating registry client")
+// VerifyOpts adjusts how Verify authenticates and what it checks beyond the configured policy
+type VerifyOpts struct {
+	// Use the GitHub token without validating it against the user API (for CI tokens)
+	SkipTokenValidation bool
+
+	// Manifest digest the reference must resolve to, e.g. from a lockfile (optional)
+	ExpectedDigest string
+
+	// Plugin version the artifact's metadata must declare, with or without a "v"
+	// prefix (optional)
+	ExpectedVersion string
 
-	// Load configuration
-	configOpts := config.DefaultConfigOpts()
-	if ctx.ConfigPath != "" {
-		configOpts = configOpts.WithConfigPath(ctx.ConfigPath)
+	// Attestation verifier shared across calls, so the Sigstore trusted root is fetched
+	// once when verifying several plugins (optional)
+	Verifier *attestation.AttestationVerifier
+
+	// Download main.js and scan it for risk indicators
+	Analyze bool
+
+	// Files holding the artifact's attestations, verified instead of those discovered in
+	// the registry (optional)
+	AttestationFiles []string
+}
+
+// DefaultVerifyOpts returns the options used by the verify command
+func DefaultVerifyOpts() *VerifyOpts {
+	return &VerifyOpts{}
+}
+
+// WithSkipTokenValidation uses the GitHub token without validating it first
+func (opts *VerifyOpts) WithSkipTokenValidation(skip bool) *VerifyOpts {
+	opts.SkipTokenValidation = skip
+	return opts
+}
+
+// WithExpectedDigest requires the reference to resolve to the given manifest digest
+func (opts *VerifyOpts) WithExpectedDigest(digest string) *VerifyOpts {
+	opts.ExpectedDigest = digest
+	return opts
+}
+
+// WithExpectedVersion requires the artifact's metadata to declare the given version
+func (opts *VerifyOpts) WithExpectedVersion(version string) *VerifyOpts {
+	opts.ExpectedVersion = version
+	return opts
+}
+
+// WithVerifier verifies attestations with a shared verifier instead of creating one
+func (opts *VerifyOpts) WithVerifier(verifier *attestation.AttestationVerifier) *VerifyOpts {
+	opts.Verifier = verifier
+	return opts
+}
+
+// WithAnalyze scans the plugin's main.js for risk indicators
+func (opts *VerifyOpts) WithAnalyze(analyze bool) *VerifyOpts {
+	opts.Analyze = analyze
+	return opts
+}
+
+// WithAttestationFiles verifies the attestations in files instead of discovering them
+func (opts *VerifyOpts) WithAttestationFiles(files []string) *VerifyOpts {
+	opts.AttestationFiles = files
+	return opts
+}
+
+// Result describes what Verify found. It is returned even when verification fails so
+// callers can report how far verification got.
+type Result struct {
+	ImageRef       string                          `json:"image_ref"`
+	ManifestDigest string                          `json:"manifest_digest,omitempty"`
+	Metadata       *plugin.Metadata                `json:"metadata,omitempty"`
+	Attestation    *attestation.VerificationResult `json:"attestation,omitempty"`
+
+	// Findings that did not fail verification: metadata warnings, a missing SBOM, and
+	// metadata, attestation, and vulnerability failures tolerated outside strict mode
+	Warnings []warnings.Warning `json:"warnings"`
+
+	// Risk indicators found in main.js (only with VerifyOpts.Analyze)
+	Analysis *analysis.Report `json:"analysis,omitempty"`
+}
+
+// report is the verify command's structured output: the result and whether it passed
+type report struct {
+	*Result
+	Verified bool   `json:"verified"`
+	Error    string `json:"error,omitempty"`
+}
+
+// newReport describes the outcome of verifying imageRef, where err is why it failed
+func newReport(imageRef string, result *Result, err error) report {
+	if result == nil {
+		result = &Result{ImageRef: imageRef}
+	}
+	if result.Warnings == nil {
+		result.Warnings = []warnings.Warning{}
 	}
-	configManager := config.NewConfigManager(configOpts)
-	cfg, _, err := configManager.LoadConfig()
+	r := report{Result: result, Verified: err == nil}
 	if err != nil {
-		ctx.Logger.Warn("Failed to load configuration, using defaults", ctx.Logger.Args("error", err))
-		cfg = config.DefaultConfig()
+		r.Error = err.Error()
+	}
+	return r
+}
+
+// Verify checks the metadata, attestations, and vulnerabilities of imageRef against the
+// verification policy in ctx.Config and any overrides the vault manifest declares for it
+func Verify(opCtx context.Context, ctx *cmd.CommandContext, imageRef string, opts *VerifyOpts) (result *Result, err error) {
+	if opts == nil {
+		opts = DefaultVerifyOpts()
 	}
+	result = &Result{ImageRef: imageRef}
+	defer func(start time.Time) { metrics.RecordVerification(err, time.Since(start)) }(time.Now())
+
+	ctx.Logger.Debug("Creating registry client")
+
+	cfg := ctx.Config
 
 	ctx.Logger.Debug("Verification configuration", ctx.Logger.Args("strict", cfg.Verification.StrictMode))
 
 	// Debug: Log token availability
 	if ctx.GitHubToken != "" {
-		ctx.Logger.Debug("GitHub token provided via flag", ctx.Logger.Args("tokenLength", len(ctx.GitHubToken)))
+		ctx.Logger.Debug("GitHub token provided via flag or environment", ctx.Logger.Args("tokenLength", len(ctx.GitHubToken)))
 	} else {
-		ctx.Logger.Debug("No GitHub token provided via flag, will attempt to use stored credentials")
+		ctx.Logger.Debug("No GitHub token provided via flag or environment, will attempt to use stored credentials")
 	}
 
 	// Configure registry client
-	registryOpts := registry.DefaultRegistryOpts()
+	registryOpts := registry.DefaultRegistryOpts().
+		WithTimeout(cfg.Registry.Timeout.Duration()).
+		WithCredentials(cfg.Registry.CredentialLookup()).
+		WithDockerCredentials(registry.DockerCredentialStore(cfg.Registry.DockerCredentials))
 	if cfg.Registry.DefaultRegistry != "" {
 		registryOpts = registryOpts.WithRegistryHost(cfg.Registry.DefaultRegistry)
 	}
@@ -85,26 +257,15 @@ func verifyPlugin(imageRef string, ctx *cmd.CommandContext) error {
 	}
 	registryOpts = registryOpts.WithPluginOpts(pluginOpts)
 
-	// Configure auth - always try to provide an auth provider
+	// Configure auth - the token is resolved from the flag or environment at startup
 	var authProvider *auth.AuthClient
 	if ctx.GitHubToken != "" {
 		ctx.Logger.Debug("Configuring registry with provided GitHub token")
-		authOpts := auth.DefaultAuthOpts().WithToken(ctx.GitHubToken)
+		authOpts := auth.DefaultAuthOpts().WithToken(ctx.GitHubToken).WithSkipValidation(opts.SkipTokenValidation)
 		authProvider = auth.NewAuthClient(authOpts)
 	} else {
-		// Try to get token from environment variables as fallback (useful in CI)
-		if ghToken := os.Getenv("GITHUB_TOKEN"); ghToken != "" {
-			ctx.Logger.Debug("Using GITHUB_TOKEN environment variable")
-			authOpts := auth.DefaultAuthOpts().WithToken(ghToken)
-			authProvider = auth.NewAuthClient(authOpts)
-		} else if ghToken := os.Getenv("GH_TOKEN"); ghToken != "" {
-			ctx.Logger.Debug("Using GH_TOKEN environment variable")
-			authOpts := auth.DefaultAuthOpts().WithToken(ghToken)
-			authProvider = auth.NewAuthClient(authOpts)
-		} else {
-			ctx.Logger.Debug("Using default registry authentication (stored credentials)")
-			// Default auth adapter - will try stored credentials
-		}
+		ctx.Logger.Debug("Using default registry authentication (stored credentials)")
+		// Default auth adapter - will try stored credentials
 	}
 
 	if authProvider != nil {
@@ -114,19 +275,27 @@ func verifyPlugin(imageRef string, ctx *cmd.CommandContext) error {
 	// Create registry client
 	client, err := registry.NewClient(registryOpts)
 	if err != nil {
-		return fmt.Errorf("failed to create registry client: %w", err)
+		return result, fmt.Errorf("failed to create registry client: %w", err)
 	}
 
-	// Create context with timeout
-	opCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Create context bounded by verification.timeout
+	opCtx, cancel := context.WithTimeout(opCtx, cfg.Verification.Timeout.Duration())
 	defer cancel()
+	defer func() { err = cfg.WrapTimeout(opCtx, "verification.timeout", err) }()
 
 	ctx.Logger.Debug("Fetching manifest from registry")
 
-	// Get manifest and annotations
-	manifest, annotations, _, err := client.GetManifest(opCtx, imageRef)
+	// Resolve the reference and get its manifest once; attestations are read through the
+	// same repository client
+	artifact, err := client.OpenArtifact(opCtx, imageRef)
 	if err != nil {
-		return fmt.Errorf("failed to fetch manifest: %w", err)
+		return result, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	manifest, annotations, manifestDigest := artifact.Manifest, artifact.Manifest.Annotations, artifact.Digest()
+	result.ManifestDigest = manifestDigest
+
+	if opts.ExpectedDigest != "" && manifestDigest != opts.ExpectedDigest {
+		return result, registry.ManifestMismatch(imageRef, opts.ExpectedDigest, manifestDigest)
 	}
 
 	ctx.Logger.Info("Manifest retrieved successfully",
@@ -143,8 +312,20 @@ func verifyPlugin(imageRef string, ctx *cmd.CommandContext) error {
 	parser := plugin.NewManifestParser(pluginOpts)
 	pluginMetadata, err := parser.ParseMetadata(manifest, annotations)
 	if err != nil {
-		return fmt.Errorf("failed to parse plugin metadata: %w", err)
+		return result, fmt.Errorf("failed to parse plugin metadata: %w", err)
+	}
+	result.Metadata = pluginMetadata
+
+	if opts.ExpectedVersion != "" && !versionMatches(opts.ExpectedVersion, pluginMetadata.Version) {
+		return result, fmt.Errorf("%w: expected %s, %s is annotated with %s", dgerrors.ErrVersionMismatch, opts.ExpectedVersion, imageRef, pluginMetadata.Version)
+	}
+
+	// Per-plugin overrides in the vault manifest take precedence over the configuration
+	policy, err := DeclaredPolicy(ctx, pluginMetadata.ID)
+	if err != nil {
+		return result, err
 	}
+	ctx, cfg = ApplyPolicy(ctx, cfg, policy)
 
 	// Display plugin information
 	ctx.Logger.Info("Plugin Information",
@@ -177,7 +358,7 @@ func verifyPlugin(imageRef string, ctx *cmd.CommandContext) error {
 		if !cfg.Verification.StrictMode {
 			ctx.Logger.Warn("Continuing in non-strict mode despite validation errors")
 		} else {
-			return fmt.Errorf("metadata validation failed in strict mode")
+			return result, fmt.Errorf("metadata validation failed in strict mode")
 		}
 	}
 
@@ -186,64 +367,201 @@ func verifyPlugin(imageRef string, ctx *cmd.CommandContext) error {
 			ctx.Logger.Warn("Metadata validation warning", ctx.Logger.Args("warning", warning))
 		}
 	}
+	result.Warnings = append(result.Warnings, MetadataWarnings(validation)...)
 
 	ctx.Logger.Info("Basic verification completed")
 
-	// Get GitHub token for attestation verification
-	ctx.Logger.Debug("Getting authentication token")
-	token, err := auth.GetToken()
-	if err != nil {
-		return fmt.Errorf("failed to get authentication token for attestation verification: %w", err)
+	verifier := opts.Verifier
+	if verifier == nil || (policy != nil && policy.TrustedBuilder != "") {
+		if verifier, err = NewAttestationVerifier(ctx); err != nil {
+			return result, err
+		}
 	}
 
 	// Verify all attestations (SLSA, SBOM, etc.)
 	ctx.Logger.Debug("Verifying attestations (SLSA, SBOM, etc.)")
-	verifier, err := attestation.NewAttestationVerifier(token, ctx.TrustedBuilder)
+
+	attestationResult, err := verifyArtifactAttestations(opCtx, ctx, verifier, artifact, opts.AttestationFiles)
 	if err != nil {
-		return fmt.Errorf("failed to create attestation verifier: %w", err)
+		return result, err
+	}
+	attestationResult.Findings.SetPluginID(pluginMetadata.ID)
+	result.Attestation = attestationResult
+
+	// Display debug warnings first
+	for _, warning := range attestationResult.Findings.Warnings() {
+		ctx.Logger.Debug("Attestation verification debug info", ctx.Logger.Args("plugin", warning.PluginID, "check", warning.Check, "info", warning.Message))
 	}
 
-	attestationResult, err := verifier.VerifyAttestations(opCtx, imageRef)
+	// Display attestation verification results
+	logChecks(ctx, attestationResult)
+
+	policyWarnings, err := checkAttestationPolicy(cfg.Verification.StrictMode, attestationResult)
+	result.Warnings = append(result.Warnings, policyWarnings...)
 	if err != nil {
-		return fmt.Errorf("failed to verify attestations: %w", err)
+		return result, err
+	}
+	if err := CheckPolicy(policy, attestationResult); err != nil {
+		return result, err
 	}
 
-	// Display debug warnings first
-	if len(attestationResult.Warnings) > 0 {
-		for _, warning := range attestationResult.Warnings {
-			ctx.Logger.Debug("Attestation verification debug info", ctx.Logger.Args("info", warning))
+	if opts.Analyze {
+		ctx.Logger.Debug("Analyzing plugin code")
+		mainJS, err := client.FetchLayer(opCtx, imageRef, manifest, "main.js")
+		if err != nil {
+			return result, fmt.Errorf("failed to download plugin code for analysis: %w", err)
+		}
+		result.Analysis = analysis.Analyze(mainJS)
+		if err := checkAnalysis(ctx, cfg.Verification.BlockRisk, result.Analysis); err != nil {
+			return result, err
 		}
 	}
 
-	// Display attestation verification results
+	return result, nil
+}
+
+// versionMatches reports whether actual is the expected version, ignoring a "v" prefix
+// on either
+func versionMatches(expected, actual string) bool {
+	return strings.TrimPrefix(expected, "v") == strings.TrimPrefix(actual, "v")
+}
+
+// logChecks logs the outcome of each attestation check, failures as warnings
+func logChecks(ctx *cmd.CommandContext, attestationResult *attestation.VerificationResult) {
 	ctx.Logger.Info("Attestation verification results", ctx.Logger.Args("found", attestationResult.Found, "valid", attestationResult.Valid))
+	for _, check := range attestationResult.Checks {
+		args := ctx.Logger.Args("check", check.Name, "status", check.Status, "severity", check.Severity, "detail", check.Detail)
+		if check.Status == attestation.CheckFailed {
+			ctx.Logger.Warn("Attestation check failed", args)
+		} else {
+			ctx.Logger.Debug("Attestation check", args)
+		}
+	}
+}
+
+// checkAttestationPolicy applies the verification policy to attestationResult: checks
+// that fail hard always fail verification, those that fail soft (missing or invalid
+// provenance, an untrusted builder, high/critical vulnerabilities) only in strict mode,
+// and the rest are returned as AttestationWarnings
+func checkAttestationPolicy(strict bool, attestationResult *attestation.VerificationResult) ([]warnings.Warning, error) {
+	if err := attestationResult.Enforce(strict); err != nil {
+		return nil, err
+	}
+	return AttestationWarnings(attestationResult), nil
+}
 
-	// Check if attestation verification should block installation
-	if cfg.Verification.StrictMode && (!attestationResult.Found || !attestationResult.Valid) {
-		if !attestationResult.Found {
-			return fmt.Errorf("attestations not found (required in strict mode)")
+// MetadataWarnings returns the metadata findings that did not fail verification: the
+// warnings of validation, and its errors, which only get this far outside strict mode
+func MetadataWarnings(validation *plugin.ValidationResult) []warnings.Warning {
+	var found []warnings.Warning
+	for _, err := range validation.Errors {
+		found = append(found, warnings.New(warnings.CategoryMetadata, "%s", err.Error()))
+	}
+	for _, warning := range validation.Warnings {
+		found = append(found, warnings.New(warnings.CategoryMetadata, "%s", warning))
+	}
+	return found
+}
+
+// AttestationWarnings returns the failed checks of attestationResult as warnings: the
+// first failure establishing trust (missing or invalid attestations, an untrusted
+// builder), a missing SBOM, and high/critical vulnerabilities. Outside strict mode these
+// are all that can have failed without failing verification. Warnings the verifier raised
+// about the provenance itself, such as provenance in the older SLSA v0.2 format, are
+// included too.
+func AttestationWarnings(attestationResult *attestation.VerificationResult) []warnings.Warning {
+	var found []warnings.Warning
+	trustReported := false
+	for _, check := range attestationResult.Failures() {
+		switch check.Name {
+		case attestation.CheckSBOM:
+			found = append(found, warnings.New(warnings.CategorySBOM, "%s", check.Detail))
+		case attestation.CheckVulnerabilities:
+			found = append(found, warnings.New(warnings.CategoryVulnerability, "%s", check.Detail))
+		default:
+			if !trustReported {
+				found = append(found, warnings.New(warnings.CategoryAttestation, "%s", attestationResult.CheckErr(check).Error()))
+				trustReported = true
+			}
 		}
-		if !attestationResult.Valid {
-			return fmt.Errorf("attestation verification failed (required in strict mode)")
+	}
+	for _, finding := range attestationResult.Findings.Warnings() {
+		if finding.Check == attestation.CheckProvenance {
+			found = append(found, warnings.New(warnings.CategoryAttestation, "%s", finding.Message))
 		}
 	}
+	return found
+}
 
-	// Additional SBOM-specific security checks
-	if attestationResult.SBOM != nil && len(attestationResult.SBOM.Vulnerabilities) > 0 {
-		highSeverityVulns := 0
-		for _, vuln := range attestationResult.SBOM.Vulnerabilities {
-			if vuln.Severity == "HIGH" || vuln.Severity == "CRITICAL" {
-				highSeverityVulns++
-			}
+// verifyArtifactAttestations verifies the attestations in files when any are given, and
+// those the registry holds for artifact otherwise
+func verifyArtifactAttestations(opCtx context.Context, ctx *cmd.CommandContext, verifier *attestation.AttestationVerifier, artifact *oci.Artifact, files []string) (*attestation.VerificationResult, error) {
+	if len(files) == 0 {
+		result, err := verifier.VerifyArtifact(opCtx, artifact)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify attestations: %w", err)
 		}
+		return result, nil
+	}
 
-		if highSeverityVulns > 0 {
-			ctx.Logger.Warn("High/critical severity vulnerabilities found", ctx.Logger.Args("count", highSeverityVulns))
-			if cfg.Verification.StrictMode {
-				return fmt.Errorf("%d high/critical vulnerabilities found (blocked in strict mode)", highSeverityVulns)
-			}
+	var blobs [][]byte
+	for _, file := range files {
+		found, err := attestation.ReadBundleFile(file)
+		if err != nil {
+			return nil, err
 		}
+		ctx.Logger.Debug("Read attestations from file", ctx.Logger.Args("file", file, "attestations", len(found)))
+		blobs = append(blobs, found...)
+	}
+
+	var indexDigest string
+	if artifact.Index != nil {
+		indexDigest = artifact.Index.Digest.String()
+	}
+	return verifier.VerifyBundles(artifact.Descriptor.Digest.String(), indexDigest, blobs), nil
+}
+
+// checkAnalysis reports code analysis findings and fails when any reaches blockRisk
+func checkAnalysis(ctx *cmd.CommandContext, blockRisk string, report *analysis.Report) error {
+	for _, finding := range report.Findings {
+		ctx.Logger.Warn("Code analysis finding", ctx.Logger.Args(
+			"rule", finding.Rule,
+			"severity", finding.Severity,
+			"match", finding.Match,
+			"line", finding.Line,
+			"count", finding.Count,
+			"reason", finding.Description,
+		))
 	}
 
+	summary := report.Summary()
+	ctx.Logger.Info("Code analysis results", ctx.Logger.Args(
+		"high", summary[analysis.SeverityHigh],
+		"medium", summary[analysis.SeverityMedium],
+		"low", summary[analysis.SeverityLow],
+	))
+
+	if blocking := report.Exceeds(analysis.Severity(blockRisk)); len(blocking) > 0 {
+		return fmt.Errorf("%w: %d findings at or above %s severity (first: %s)", dgerrors.ErrRiskBlocked, len(blocking), blockRisk, blocking[0])
+	}
 	return nil
 }
+
+// NewAttestationVerifier creates an attestation verifier for ctx's trusted builder using
+// the GitHub token from the flag or environment, falling back to stored credentials
+func NewAttestationVerifier(ctx *cmd.CommandContext) (*attestation.AttestationVerifier, error) {
+	ctx.Logger.Debug("Getting authentication token")
+	token := ctx.GitHubToken
+	if token == "" {
+		var err error
+		if token, err = auth.GetToken(); err != nil {
+			return nil, fmt.Errorf("failed to get authentication token for attestation verification: %w", err)
+		}
+	}
+
+	verifier, err := attestation.NewAttestationVerifier(token, ctx.TrustedBuilder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attestation verifier: %w", err)
+	}
+	return verifier, nil
+}
@@ -0,0 +1,79 @@
+package verify
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
+	"github.com/gillisandrew/dragonglass-poc/internal/output"
+	"github.com/gillisandrew/dragonglass-poc/internal/warnings"
+)
+
+func TestVersionMatches(t *testing.T) {
+	tests := []struct {
+		expected string
+		actual   string
+		want     bool
+	}{
+		{expected: "1.2.3", actual: "1.2.3", want: true},
+		{expected: "v1.2.3", actual: "1.2.3", want: true},
+		{expected: "1.2.3", actual: "v1.2.3", want: true},
+		{expected: "1.2.3", actual: "1.2.4"},
+		{expected: "1.2", actual: "1.2.3"},
+	}
+
+	for _, tt := range tests {
+		if got := versionMatches(tt.expected, tt.actual); got != tt.want {
+			t.Errorf("versionMatches(%q, %q) = %t, want %t", tt.expected, tt.actual, got, tt.want)
+		}
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	const imageRef = "ghcr.io/owner/repo:plugin-v1.0.0"
+	tests := []struct {
+		name   string
+		result *Result
+		err    error
+		want   string
+	}{
+		{
+			name:   "verified",
+			result: &Result{ImageRef: imageRef, ManifestDigest: "sha256:abc"},
+			want:   "true " + imageRef + "  0",
+		},
+		{
+			name: "failed before a result",
+			err:  errors.New("failed to fetch manifest"),
+			want: "false " + imageRef + " failed to fetch manifest 0",
+		},
+	}
+
+	renderer, err := output.New("go-template={{.verified}} {{.image_ref}} {{if .error}}{{.error}}{{end}} {{len .warnings}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			if err := renderer.Render(&out, newReport(imageRef, tt.result, tt.err)); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.TrimSuffix(out.String(), "\n"); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAttestationWarningsIncludeProvenanceFindings(t *testing.T) {
+	result := &attestation.VerificationResult{Found: true, Valid: true}
+	result.Findings.Warnf(attestation.CheckProvenance, "provenance uses the older SLSA v0.2 format")
+	result.Findings.Warnf(attestation.CheckAttestations, "unknown predicate type: https://example.com/custom/v1")
+
+	found := AttestationWarnings(result)
+	if len(found) != 1 || found[0].Category != warnings.CategoryAttestation || found[0].Message != "provenance uses the older SLSA v0.2 format" {
+		t.Errorf("expected only the provenance warning, got %v", found)
+	}
+}
@@ -0,0 +1,284 @@
+// ABOUTME: Watch command for periodically re-verifying installed plugins
+// ABOUTME: Records fresh verification state in the lockfile and alerts notify targets on regressions
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/install"
+	notifycmd "github.com/gillisandrew/dragonglass-poc/internal/cmd/notify"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/verify"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/notify"
+)
+
+const (
+	DefaultInterval = 24 * time.Hour
+
+	// MinInterval keeps a misconfigured watch from hammering the registry
+	MinInterval = time.Minute
+)
+
+// WatchOptions configures the watch command
+type WatchOptions struct {
+	// Time between re-verification passes
+	Interval time.Duration
+
+	// Run a single pass and exit, e.g. from a systemd timer
+	Once bool
+}
+
+func NewWatchCommand(ctx *cmd.CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Periodically re-verify installed plugins and alert on regressions",
+		Long: `Re-verify every plugin in the lockfile at a fixed interval against the current
+Sigstore trusted root and vulnerability data. Each pass records the fresh
+verification state in the lockfile. A plugin whose provenance or SBOM no longer
+verifies, whose vulnerability scan no longer passes, or that has new
+vulnerabilities is logged and reported to the targets under notify.targets.
+
+Registry outages are retried on the next pass without touching the recorded state.
+
+Example:
+  dragonglass watch
+  dragonglass watch --interval 6h
+  dragonglass watch --once`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := WatchOptions{}
+			opts.Interval, _ = cmd.Flags().GetDuration("interval")
+			opts.Once, _ = cmd.Flags().GetBool("once")
+
+			if err := runWatch(cmd.Context(), ctx, opts); err != nil {
+				ctx.Fail("Watch failed", err)
+			}
+		},
+	}
+
+	cmd.Flags().Duration("interval", DefaultInterval, "Time between re-verification passes")
+	cmd.Flags().Bool("once", false, "Run a single pass and exit (for systemd timers and cron)")
+	return cmd
+}
+
+func runWatch(opCtx context.Context, ctx *cmd.CommandContext, opts WatchOptions) error {
+	if !opts.Once && opts.Interval < MinInterval {
+		return fmt.Errorf("--interval must be at least %s, got %s", MinInterval, opts.Interval)
+	}
+
+	for {
+		err := Recheck(opCtx, ctx)
+		if errors.Is(err, context.Canceled) {
+			ctx.Logger.Info("Watch stopped")
+			return nil
+		}
+		if opts.Once {
+			return err
+		}
+		if err != nil {
+			ctx.Logger.Warn("Re-verification pass incomplete", ctx.Logger.Args("error", err))
+		}
+
+		next := time.Now().Add(opts.Interval)
+		ctx.Logger.Info("Next re-verification", ctx.Logger.Args("at", next.Format(time.RFC3339)))
+
+		timer := time.NewTimer(opts.Interval)
+		select {
+		case <-opCtx.Done():
+			timer.Stop()
+			ctx.Logger.Info("Watch stopped")
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// Recheck re-verifies every plugin in the lockfile once, records the new verification
+// state, and reports regressions and new vulnerabilities. Plugins that could not be
+// checked are listed in the returned error.
+func Recheck(opCtx context.Context, ctx *cmd.CommandContext) error {
+	lockfilePath, err := ctx.ResolveLockfilePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+	lockfileData, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load lockfile: %w", err)
+	}
+
+	// Dev builds have no attestations to re-verify
+	pluginIDs := make([]string, 0, len(lockfileData.Plugins))
+	for pluginID, entry := range lockfileData.Plugins {
+		if !entry.IsDev() {
+			pluginIDs = append(pluginIDs, pluginID)
+		}
+	}
+	sort.Strings(pluginIDs)
+	if len(pluginIDs) == 0 {
+		ctx.Logger.Info("No plugins to re-verify")
+		return nil
+	}
+
+	// A new verifier per pass fetches the current trusted root, so rotated or revoked
+	// signing material is picked up
+	verifier, err := verify.NewAttestationVerifier(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	states := make([]lockfile.VerificationState, len(pluginIDs))
+	scheduler := attestation.NewScheduler(ctx.Config.Verification.Concurrency)
+	errs := scheduler.Run(opCtx, len(pluginIDs), func(jobCtx context.Context, i int) error {
+		entry := lockfileData.Plugins[pluginIDs[i]]
+		ctx.Logger.Info("Re-verifying plugin", ctx.Logger.Args("id", pluginIDs[i], "version", entry.Version))
+
+		verifyOpts := verify.DefaultVerifyOpts().
+			WithExpectedDigest(entry.OCIDigest).
+			WithVerifier(verifier)
+		result, err := verify.Verify(jobCtx, ctx, entry.LockedReference(), verifyOpts)
+
+		state, checked := recheckedState(entry.VerificationState, result, err, ctx.Config.Verification, now)
+		if !checked {
+			return err
+		}
+		states[i] = state
+		return nil
+	})
+
+	// Reload so plugins installed or removed during the pass are not overwritten
+	current, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to reload lockfile: %w", err)
+	}
+	tx := current.Begin(lockfilePath)
+	defer tx.Rollback()
+
+	var (
+		alerts    []notify.Alert
+		failures  []error
+		rechecked []string
+	)
+	for i, pluginID := range pluginIDs {
+		if err := errs[i]; err != nil {
+			if errors.Is(err, context.Canceled) {
+				return err
+			}
+			ctx.Logger.Warn("Could not re-verify plugin", ctx.Logger.Args("id", pluginID, "error", err))
+			failures = append(failures, fmt.Errorf("%s: %w", pluginID, err))
+			continue
+		}
+
+		entry := lockfileData.Plugins[pluginID]
+		if latest, ok := current.Plugins[pluginID]; !ok || latest.OCIDigest != entry.OCIDigest {
+			ctx.Logger.Debug("Plugin changed during re-verification", ctx.Logger.Args("id", pluginID))
+			continue
+		}
+
+		state := states[i]
+		for _, alert := range regressionAlerts(pluginID, entry, state) {
+			ctx.Logger.Warn("Verification regression", ctx.Logger.Args("id", pluginID, "alert", alert.Message()))
+			alerts = append(alerts, alert)
+		}
+		if err := tx.UpdatePluginVerification(pluginID, state); err != nil {
+			return fmt.Errorf("failed to update lockfile: %w", err)
+		}
+		rechecked = append(rechecked, pluginID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save lockfile: %w", err)
+	}
+	v := ctx.VaultAt(lockfile.VaultRoot(lockfilePath))
+	for _, pluginID := range rechecked {
+		if err := install.AuditVerification(v, pluginID, current.Plugins[pluginID]); err != nil {
+			ctx.Logger.Warn("Could not record verification in the audit log", ctx.Logger.Args("id", pluginID, "error", err))
+		}
+	}
+	ctx.Logger.Info("Re-verification complete", ctx.Logger.Args("plugins", len(pluginIDs), "regressions", len(alerts), "unchecked", len(failures)))
+
+	// The lockfile already holds the new state, so a failed delivery is not retried; the
+	// alerts above remain in the log
+	var deliverErr error
+	if len(alerts) > 0 && len(ctx.Config.Notify.Targets) > 0 {
+		report := &notify.Report{Vault: current.Metadata.VaultPath, Time: now, Alerts: alerts}
+		if report.Vault == "" {
+			report.Vault = lockfile.VaultRoot(lockfilePath)
+		}
+		deliverErr = notifycmd.Deliver(opCtx, ctx, report)
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d plugins could not be re-verified: %w", len(failures), len(pluginIDs), errors.Join(append(failures, deliverErr)...))
+	}
+	return deliverErr
+}
+
+// recheckedState returns the verification state to record after re-verifying an artifact
+// whose recorded state is previous. checked is false when verification could not run for
+// reasons unrelated to the plugin, such as a registry outage or missing credentials, and
+// previous should be kept.
+func recheckedState(previous lockfile.VerificationState, result *verify.Result, err error, policy config.VerificationConfig, now time.Time) (state lockfile.VerificationState, checked bool) {
+	if errors.Is(err, dgerrors.ErrRegistryUnavailable) || errors.Is(err, dgerrors.ErrNotAuthenticated) ||
+		errors.Is(err, dgerrors.ErrTransientVerification) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return previous, false
+	}
+	if result != nil && result.Attestation != nil && result.Attestation.Transient {
+		return previous, false
+	}
+
+	if result != nil && result.Attestation != nil {
+		state = install.VerificationState(result.Attestation, policy, now)
+	} else {
+		// Verification stopped before the attestations were checked, e.g. because the tag
+		// was re-pushed; keep what is known and record the failure
+		state = previous
+		state.Errors = slices.Clone(previous.Errors)
+		state.VerifiedAt = &now
+		state.PolicyHash = policy.PolicyHash()
+	}
+
+	if err != nil && !slices.Contains(state.Errors, err.Error()) {
+		state.Errors = append(state.Errors, err.Error())
+	}
+	return state, true
+}
+
+// regressionAlerts compares the recorded state of entry with the re-verified state
+func regressionAlerts(pluginID string, entry lockfile.PluginEntry, state lockfile.VerificationState) []notify.Alert {
+	base := notify.Alert{
+		PluginID:     pluginID,
+		Name:         entry.Name,
+		Version:      entry.Version,
+		OCIReference: entry.OCIReference,
+		OCIDigest:    entry.OCIDigest,
+	}
+
+	var alerts []notify.Alert
+	if reasons := entry.VerificationState.Regressions(state); len(reasons) > 0 {
+		alert := base
+		alert.Kind = notify.KindRegression
+		alert.Reasons = reasons
+		alerts = append(alerts, alert)
+	}
+	if added := notify.NewVulnerabilities(entry.VerificationState.Vulnerabilities, state.Vulnerabilities); added != nil {
+		alert := base
+		alert.Kind = notify.KindVulnerability
+		alert.Vulnerabilities = state.Vulnerabilities
+		alert.NewVulnerabilities = added
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
@@ -0,0 +1,138 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/attestation"
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/verify"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/notify"
+)
+
+func TestRecheckedState(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	policy := config.DefaultConfig().Verification
+	previous := lockfile.VerificationState{
+		ProvenanceVerified: true,
+		SBOMVerified:       true,
+		VulnScanPassed:     true,
+		SLSALevel:          2,
+		Vulnerabilities:    &lockfile.VulnerabilityCounts{},
+	}
+
+	verified := &verify.Result{Attestation: &attestation.VerificationResult{
+		Found: true,
+		Valid: true,
+		SLSA:  &attestation.SLSAResult{Valid: true, Builder: "builder"},
+		SBOM: &attestation.SBOMResult{Valid: true, Vulnerabilities: []attestation.Vulnerability{
+			{ID: "CVE-2026-0001", Severity: "HIGH"},
+		}},
+	}}
+
+	tests := []struct {
+		name     string
+		result   *verify.Result
+		err      error
+		checked  bool
+		validate func(t *testing.T, state lockfile.VerificationState)
+	}{
+		{
+			name:    "registry outage keeps the recorded state",
+			result:  &verify.Result{},
+			err:     dgerrors.Mark(fmt.Errorf("connection refused"), dgerrors.ErrRegistryUnavailable),
+			checked: false,
+		},
+		{
+			name:    "timeout keeps the recorded state",
+			result:  &verify.Result{},
+			err:     fmt.Errorf("failed to fetch manifest: %w", context.DeadlineExceeded),
+			checked: false,
+		},
+		{
+			name:    "unreachable attestations keep the recorded state",
+			result:  &verify.Result{Attestation: &attestation.VerificationResult{Transient: true}},
+			checked: false,
+		},
+		{
+			name:    "fresh attestation result replaces the state",
+			result:  verified,
+			err:     fmt.Errorf("%w: 1 high/critical vulnerabilities found (strict mode)", dgerrors.ErrVulnBlocked),
+			checked: true,
+			validate: func(t *testing.T, state lockfile.VerificationState) {
+				if state.VulnScanPassed || state.Vulnerabilities == nil || state.Vulnerabilities.High != 1 {
+					t.Errorf("expected the new vulnerability to be recorded, got %+v", state)
+				}
+				if state.VerifiedAt == nil || !state.VerifiedAt.Equal(now) {
+					t.Errorf("expected verified_at %s, got %v", now, state.VerifiedAt)
+				}
+				if len(state.Errors) != 1 || !strings.Contains(state.Errors[0], "strict mode") {
+					t.Errorf("expected the verification error to be recorded, got %q", state.Errors)
+				}
+			},
+		},
+		{
+			name:    "failure before attestations keeps flags and records the error",
+			result:  &verify.Result{},
+			err:     dgerrors.Mark(fmt.Errorf("tag 1.0.0 now points to sha256:other"), dgerrors.ErrTagMoved),
+			checked: true,
+			validate: func(t *testing.T, state lockfile.VerificationState) {
+				if !state.ProvenanceVerified || state.SLSALevel != 2 {
+					t.Errorf("expected recorded flags to be kept, got %+v", state)
+				}
+				if len(state.Errors) != 1 || !strings.Contains(state.Errors[0], "now points to") {
+					t.Errorf("expected the error to be recorded, got %q", state.Errors)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, checked := recheckedState(previous, tt.result, tt.err, policy, now)
+			if checked != tt.checked {
+				t.Fatalf("expected checked %v, got %v", tt.checked, checked)
+			}
+			if tt.validate != nil {
+				tt.validate(t, state)
+			}
+		})
+	}
+}
+
+func TestRegressionAlerts(t *testing.T) {
+	entry := lockfile.PluginEntry{
+		Name:      "Calendar",
+		Version:   "1.5.10",
+		OCIDigest: "sha256:abc",
+		VerificationState: lockfile.VerificationState{
+			ProvenanceVerified: true,
+			VulnScanPassed:     true,
+			Vulnerabilities:    &lockfile.VulnerabilityCounts{Low: 1},
+		},
+	}
+
+	if alerts := regressionAlerts("calendar", entry, entry.VerificationState); len(alerts) != 0 {
+		t.Errorf("expected no alerts for an unchanged state, got %+v", alerts)
+	}
+
+	state := entry.VerificationState
+	state.VulnScanPassed = false
+	state.Vulnerabilities = &lockfile.VulnerabilityCounts{Critical: 1, Low: 1}
+
+	alerts := regressionAlerts("calendar", entry, state)
+	if len(alerts) != 2 {
+		t.Fatalf("expected a regression and a vulnerability alert, got %+v", alerts)
+	}
+	if alerts[0].Kind != notify.KindRegression || alerts[0].Reasons[0] != "vulnerability scan no longer passes" {
+		t.Errorf("unexpected regression alert: %+v", alerts[0])
+	}
+	if alerts[1].Kind != notify.KindVulnerability || alerts[1].NewVulnerabilities.Critical != 1 || alerts[1].NewVulnerabilities.Low != 0 {
+		t.Errorf("unexpected vulnerability alert: %+v", alerts[1])
+	}
+}
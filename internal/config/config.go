@@ -3,16 +3,42 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/userdirs"
 )
 
 const (
 	ConfigFileName     = "dragonglass-config.json"
 	ObsidianDirName    = ".obsidian"
 	DefaultConfigPerms = 0644
+
+	// User-level config lives alongside stored credentials in the user config directory
+	UserConfigFileName = "config.json"
+
+	DefaultTrustedBuilder      = "https://github.com/gillisandrew/dragonglass-poc/.github/workflows/build.yml@refs/heads/main"
+	DefaultAnnotationNamespace = "md.obsidian.plugin.v0"
+
+	// Catalog of the plugins index, published by its catalog workflow
+	DefaultCatalogReference      = "ghcr.io/gillisandrew/dragonglass-poc/catalog:latest"
+	DefaultCatalogTrustedBuilder = "https://github.com/gillisandrew/dragonglass-poc/.github/workflows/catalog.yml@refs/heads/main"
+
+	// Plugins verified at once; Sigstore verification is CPU and network bound
+	DefaultVerificationConcurrency = 4
+
+	// Times a plugin is written before giving up on files a sync service keeps changing
+	DefaultInstallWriteAttempts = 3
+
+	// Directory holding the lockfile, caches, and other dragonglass state, relative to the
+	// vault root
+	DefaultStateDir = ".dragonglass"
 )
 
 // ConfigOpts configures how configuration is loaded and managed
@@ -68,6 +94,9 @@ func NewConfigManager(opts *ConfigOpts) *ConfigManager {
 type Config struct {
 	Version string `json:"version"`
 
+	// Vault layout
+	Vault VaultConfig `json:"vault"`
+
 	// Verification settings
 	Verification VerificationConfig `json:"verification"`
 
@@ -76,41 +105,231 @@ type Config struct {
 
 	// Registry settings
 	Registry RegistryConfig `json:"registry"`
+
+	// Install settings
+	Install InstallConfig `json:"install"`
+
+	// Authentication settings
+	Auth AuthConfig `json:"auth"`
+
+	// Commands run around plugin installs and removals
+	Hooks HooksConfig `json:"hooks"`
+
+	// Webhook targets for the notify command
+	Notify NotifyConfig `json:"notify"`
+
+	// Plugin catalog for search and add by name
+	Catalog CatalogConfig `json:"catalog"`
+}
+
+type VaultConfig struct {
+	// Directory holding the lockfile and other dragonglass state, relative to the vault
+	// root, e.g. ".obsidian/dragonglass" to sync it along with the vault
+	StateDir string `json:"state_dir,omitempty"`
 }
 
 type VerificationConfig struct {
-	StrictMode        bool `json:"strict_mode"`
-	SkipVulnScan      bool `json:"skip_vuln_scan"`
-	AllowHighSeverity bool `json:"allow_high_severity"`
+	StrictMode        bool   `json:"strict_mode"`
+	SkipVulnScan      bool   `json:"skip_vuln_scan"`
+	AllowHighSeverity bool   `json:"allow_high_severity"`
+	TrustedBuilder    string `json:"trusted_builder,omitempty"`
+
+	// Maximum time to verify a plugin's attestations
+	Timeout Duration `json:"timeout,omitempty"`
+
+	// Plugins verified at once by commands that verify several
+	Concurrency int `json:"concurrency,omitempty"`
+
+	// Lowest code analysis finding severity that fails verify --analyze: "off", "low",
+	// "medium" or "high"
+	BlockRisk string `json:"block_risk,omitempty"`
+
+	// Refuse to install from a lockfile without a valid signature (see lock sign)
+	RequireSignedLockfile bool `json:"require_signed_lockfile"`
+
+	// PEM public key of the vault admin key lockfile signatures are checked against
+	LockfileKey string `json:"lockfile_key,omitempty"`
+
+	// Workflow identity keyless lockfile signatures must carry, e.g.
+	// "https://github.com/acme/vault/.github/workflows/sign.yml@refs/heads/main"
+	LockfileSigner string `json:"lockfile_signer,omitempty"`
+}
+
+// PolicyHash identifies the verification policy, so lockfile entries accepted under a
+// different policy can be detected. Timeouts do not affect the outcome and are excluded.
+func (v VerificationConfig) PolicyHash() string {
+	policy := struct {
+		StrictMode        bool   `json:"strict_mode"`
+		SkipVulnScan      bool   `json:"skip_vuln_scan"`
+		AllowHighSeverity bool   `json:"allow_high_severity"`
+		TrustedBuilder    string `json:"trusted_builder"`
+	}{v.StrictMode, v.SkipVulnScan, v.AllowHighSeverity, v.TrustedBuilder}
+
+	data, _ := json.Marshal(policy)
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
 }
 
 type OutputConfig struct {
-	Format  string `json:"format"` // "text", "json"
+	Format  string `json:"format"` // "text", "json", "yaml"
 	Verbose bool   `json:"verbose"`
 	Color   bool   `json:"color"`
+
+	// Plain text without spinners or box drawing. Unset, it is enabled when NO_COLOR is
+	// set or stdout is not a terminal.
+	Plain bool `json:"plain"`
+
+	// Prefix status messages with emoji; plain output never shows them
+	Emoji bool `json:"emoji"`
+
+	// Only warnings and errors on stderr: no status messages, progress, or info logs
+	Quiet bool `json:"quiet"`
+
+	// Language of prompts and status messages: "auto" (from LC_ALL, LC_MESSAGES or LANG) or
+	// a locale such as "en" or "de". Log lines and JSON output are never translated.
+	Language string `json:"language,omitempty"`
 }
 
 type RegistryConfig struct {
-	DefaultRegistry string            `json:"default_registry"`
-	Mirrors         map[string]string `json:"mirrors,omitempty"`
+	DefaultRegistry     string            `json:"default_registry"`
+	AnnotationNamespace string            `json:"annotation_namespace,omitempty"`
+	Mirrors             map[string]string `json:"mirrors,omitempty"`
+
+	// Tokens for private repositories, keyed by repository pattern such as "ghcr.io/acme/*"
+	Credentials map[string]RegistryCredential `json:"credentials,omitempty"`
+
+	// Use logins from the Docker config and docker-credential-* helpers
+	DockerCredentials bool `json:"docker_credentials"`
+
+	// Maximum time for a single registry request
+	Timeout Duration `json:"timeout,omitempty"`
+}
+
+type InstallConfig struct {
+	// Maximum time to download, verify and install a plugin
+	Timeout Duration `json:"timeout,omitempty"`
+
+	// Write .dragonglass/syncignore listing paths sync services should not upload
+	SyncIgnore bool `json:"sync_ignore"`
+
+	// Show the release notes of the version a plugin is updated to
+	Changelog bool `json:"changelog"`
+
+	// Times plugin files are rewritten when they change between write and verify
+	WriteAttempts int `json:"write_attempts,omitempty"`
+
+	// Sign an in-toto attestation for every install into .dragonglass/attestations
+	Attestations bool `json:"attestations"`
+
+	// PEM private key signing install attestations; keyless signing with the Actions
+	// OIDC identity is used when unset
+	AttestationKey string `json:"attestation_key,omitempty"`
+}
+
+type CatalogConfig struct {
+	// OCI reference of the catalog artifact; empty disables search and add by name
+	Reference string `json:"reference"`
+
+	// How long a fetched catalog is used before the reference is resolved again
+	TTL Duration `json:"ttl,omitempty"`
+
+	// Workflow identity that must have signed the catalog's provenance
+	TrustedBuilder string `json:"trusted_builder,omitempty"`
+}
+
+// HooksConfig lists shell commands run with a JSON event on stdin. A file layer that sets
+// a hook replaces the commands from lower layers rather than adding to them.
+type HooksConfig struct {
+	// Run after verification and before files are written; a failure blocks the install
+	PreInstall []string `json:"pre_install,omitempty"`
+
+	// Run after a plugin is installed or removed; failures are reported as warnings
+	PostInstall []string `json:"post_install,omitempty"`
+	PostRemove  []string `json:"post_remove,omitempty"`
+
+	// Skip all hooks, e.g. when installing into a vault whose config is not trusted
+	Disabled bool `json:"disabled"`
+}
+
+// Commands returns the commands configured for hook name ("pre_install", "post_install"
+// or "post_remove"), or nil when hooks are disabled
+func (h HooksConfig) Commands(name string) []string {
+	if h.Disabled {
+		return nil
+	}
+	switch name {
+	case "pre_install":
+		return h.PreInstall
+	case "post_install":
+		return h.PostInstall
+	case "post_remove":
+		return h.PostRemove
+	default:
+		return nil
+	}
+}
+
+type AuthConfig struct {
+	// Where the GitHub token is persisted: "auto", "keyring", "file", "env" or "none"
+	CredentialStore string `json:"credential_store,omitempty"`
+
+	// RFC 8693 endpoint that exchanges a GitHub Actions OIDC token for a registry token
+	OIDCExchangeURL string `json:"oidc_exchange_url,omitempty"`
+
+	// Audience requested for the Actions OIDC token
+	OIDCAudience string `json:"oidc_audience,omitempty"`
+
+	// Client ID of the GitHub App or OAuth app used for device flow login (empty means
+	// the dragonglass GitHub App)
+	OAuthClientID string `json:"oauth_client_id,omitempty"`
 }
 
 func DefaultConfig() *Config {
 	return &Config{
 		Version: "1",
+		Vault: VaultConfig{
+			StateDir: DefaultStateDir,
+		},
 		Verification: VerificationConfig{
 			StrictMode:        false,
 			SkipVulnScan:      false,
 			AllowHighSeverity: false,
+			TrustedBuilder:    DefaultTrustedBuilder,
+			Timeout:           Duration(DefaultVerificationTimeout),
+			Concurrency:       DefaultVerificationConcurrency,
+			BlockRisk:         "off",
 		},
 		Output: OutputConfig{
-			Format:  "text",
-			Verbose: false,
-			Color:   true,
+			Format:   "text",
+			Verbose:  false,
+			Color:    true,
+			Emoji:    true,
+			Language: "auto",
 		},
 		Registry: RegistryConfig{
-			DefaultRegistry: "ghcr.io",
-			Mirrors:         make(map[string]string),
+			DefaultRegistry:     "ghcr.io",
+			AnnotationNamespace: DefaultAnnotationNamespace,
+			Mirrors:             make(map[string]string),
+			Credentials:         make(map[string]RegistryCredential),
+			DockerCredentials:   true,
+			Timeout:             Duration(DefaultRegistryTimeout),
+		},
+		Install: InstallConfig{
+			Timeout:       Duration(DefaultInstallTimeout),
+			WriteAttempts: DefaultInstallWriteAttempts,
+			Changelog:     true,
+		},
+		Auth: AuthConfig{
+			CredentialStore: "auto",
+			OIDCAudience:    "dragonglass",
+		},
+		Notify: NotifyConfig{
+			Targets: make(map[string]NotifyTarget),
+		},
+		Catalog: CatalogConfig{
+			Reference:      DefaultCatalogReference,
+			TTL:            Duration(DefaultCatalogTTL),
+			TrustedBuilder: DefaultCatalogTrustedBuilder,
 		},
 	}
 }
@@ -120,14 +339,83 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("config version is required")
 	}
 
-	if c.Output.Format != "text" && c.Output.Format != "json" {
-		return fmt.Errorf("invalid output format: %s (must be 'text' or 'json')", c.Output.Format)
+	if c.Output.Format != "text" && c.Output.Format != "json" && c.Output.Format != "yaml" {
+		return fmt.Errorf("invalid output format: %s (must be 'text', 'json' or 'yaml')", c.Output.Format)
 	}
 
 	if c.Registry.DefaultRegistry == "" {
 		return fmt.Errorf("default registry is required")
 	}
 
+	if err := c.Vault.validateStateDir(); err != nil {
+		return err
+	}
+
+	if err := c.Registry.validateCredentials(); err != nil {
+		return err
+	}
+
+	if err := c.Notify.validateTargets(); err != nil {
+		return err
+	}
+
+	if c.Auth.OIDCExchangeURL != "" && !strings.HasPrefix(c.Auth.OIDCExchangeURL, "https://") {
+		return fmt.Errorf("auth.oidc_exchange_url must be an https URL, got %s", c.Auth.OIDCExchangeURL)
+	}
+
+	if strings.ContainsFunc(c.Auth.OAuthClientID, unicode.IsSpace) {
+		return fmt.Errorf("auth.oauth_client_id must not contain whitespace, got %q", c.Auth.OAuthClientID)
+	}
+
+	if c.Verification.Concurrency < 1 {
+		return fmt.Errorf("verification.concurrency must be at least 1, got %d", c.Verification.Concurrency)
+	}
+
+	if c.Install.WriteAttempts < 1 {
+		return fmt.Errorf("install.write_attempts must be at least 1, got %d", c.Install.WriteAttempts)
+	}
+
+	timeouts := []struct {
+		key   string
+		value Duration
+	}{
+		{"registry.timeout", c.Registry.Timeout},
+		{"verification.timeout", c.Verification.Timeout},
+		{"install.timeout", c.Install.Timeout},
+		{"catalog.ttl", c.Catalog.TTL},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value <= 0 {
+			return fmt.Errorf("%s must be greater than zero", timeout.key)
+		}
+	}
+
+	return nil
+}
+
+// obsidianContentDirs are the directories in .obsidian whose entries Obsidian loads as
+// plugins, themes, or snippets, so dragonglass state must not be kept in them
+var obsidianContentDirs = []string{"plugins", "themes", "snippets"}
+
+// validateStateDir checks that the state directory stays inside the vault and apart from
+// what Obsidian loads. An empty state directory is the default.
+func (v VaultConfig) validateStateDir() error {
+	if v.StateDir == "" {
+		return nil
+	}
+	dir := filepath.Clean(filepath.FromSlash(v.StateDir))
+	if !filepath.IsLocal(dir) || dir == "." {
+		return fmt.Errorf("vault.state_dir must be a directory inside the vault, relative to its root, got %s", v.StateDir)
+	}
+	if dir == ObsidianDirName {
+		return fmt.Errorf("vault.state_dir must not be %s itself; use a directory in it such as %s/dragonglass", ObsidianDirName, ObsidianDirName)
+	}
+	for _, content := range obsidianContentDirs {
+		contentDir := filepath.Join(ObsidianDirName, content)
+		if dir == contentDir || strings.HasPrefix(dir, contentDir+string(filepath.Separator)) {
+			return fmt.Errorf("vault.state_dir must not be in %s, where Obsidian would load it", filepath.ToSlash(contentDir))
+		}
+	}
 	return nil
 }
 
@@ -156,6 +444,17 @@ func GetConfigPath(obsidianDir string) string {
 	return filepath.Join(obsidianDir, ConfigFileName)
 }
 
+// GetUserConfigPath returns the path of the user-level config shared by all vaults, in
+// the OS config directory (e.g. ~/.config/dragonglass/config.json on Linux)
+func GetUserConfigPath() (string, error) {
+	dirs, err := userdirs.Resolve()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dirs.Config, UserConfigFileName), nil
+}
+
 func LoadConfig(configPath string) (*Config, error) {
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		return DefaultConfig(), nil
@@ -166,8 +465,14 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
+	data, err = prepareConfigData(configPath, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Settings absent from the file keep their defaults
+	config := DefaultConfig()
+	if err := json.Unmarshal(data, config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
@@ -175,7 +480,7 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	return &config, nil
+	return config, nil
 }
 
 func SaveConfig(config *Config, configPath string) error {
@@ -70,6 +70,72 @@ func TestConfigValidation(t *testing.T) {
 			expectError: true,
 			errorMsg:    "default registry is required",
 		},
+		{
+			name: "plain http OIDC exchange",
+			config: Config{
+				Version:  "1",
+				Output:   OutputConfig{Format: "text"},
+				Registry: RegistryConfig{DefaultRegistry: "ghcr.io"},
+				Auth:     AuthConfig{OIDCExchangeURL: "http://exchange.example.com/token"},
+			},
+			expectError: true,
+			errorMsg:    "auth.oidc_exchange_url must be an https URL",
+		},
+		{
+			name: "OAuth client ID with whitespace",
+			config: Config{
+				Version:  "1",
+				Output:   OutputConfig{Format: "text"},
+				Registry: RegistryConfig{DefaultRegistry: "ghcr.io"},
+				Auth:     AuthConfig{OAuthClientID: "Iv23li example"},
+			},
+			expectError: true,
+			errorMsg:    "auth.oauth_client_id must not contain whitespace",
+		},
+		{
+			name: "state dir outside the vault",
+			config: Config{
+				Version:  "1",
+				Vault:    VaultConfig{StateDir: "../shared"},
+				Output:   OutputConfig{Format: "text"},
+				Registry: RegistryConfig{DefaultRegistry: "ghcr.io"},
+			},
+			expectError: true,
+			errorMsg:    "vault.state_dir must be a directory inside the vault",
+		},
+		{
+			name: "absolute state dir",
+			config: Config{
+				Version:  "1",
+				Vault:    VaultConfig{StateDir: "/var/dragonglass"},
+				Output:   OutputConfig{Format: "text"},
+				Registry: RegistryConfig{DefaultRegistry: "ghcr.io"},
+			},
+			expectError: true,
+			errorMsg:    "vault.state_dir must be a directory inside the vault",
+		},
+		{
+			name: "state dir is .obsidian",
+			config: Config{
+				Version:  "1",
+				Vault:    VaultConfig{StateDir: ".obsidian/"},
+				Output:   OutputConfig{Format: "text"},
+				Registry: RegistryConfig{DefaultRegistry: "ghcr.io"},
+			},
+			expectError: true,
+			errorMsg:    "vault.state_dir must not be .obsidian itself",
+		},
+		{
+			name: "state dir among plugins",
+			config: Config{
+				Version:  "1",
+				Vault:    VaultConfig{StateDir: ".obsidian/plugins/dragonglass"},
+				Output:   OutputConfig{Format: "text"},
+				Registry: RegistryConfig{DefaultRegistry: "ghcr.io"},
+			},
+			expectError: true,
+			errorMsg:    "vault.state_dir must not be in .obsidian/plugins",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,96 @@
+// ABOUTME: Per-repository registry credentials selected by longest-prefix pattern match
+// ABOUTME: Lets one vault mix public plugins with private ones published under other accounts
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+)
+
+// RegistryCredential supplies the token for registry repositories matching a pattern
+type RegistryCredential struct {
+	// Environment variable that holds the token
+	TokenEnv string `json:"token_env"`
+}
+
+// CredentialFor returns the credential whose pattern best matches repository, e.g.
+// "ghcr.io/acme/notes/plugin-id". A pattern ending in "*" matches any repository starting
+// with the text before it ("ghcr.io/acme/*"); other patterns match that repository and the
+// repositories nested under it. The longest matching pattern wins.
+func (r RegistryConfig) CredentialFor(repository string) (string, RegistryCredential, bool) {
+	repository = strings.ToLower(repository)
+
+	bestPattern, bestLength := "", -1
+	for pattern := range r.Credentials {
+		prefix, ok := matchCredentialPattern(strings.ToLower(pattern), repository)
+		if !ok {
+			continue
+		}
+		// Ties between equally long patterns resolve alphabetically so results are stable
+		if len(prefix) > bestLength || (len(prefix) == bestLength && pattern < bestPattern) {
+			bestPattern, bestLength = pattern, len(prefix)
+		}
+	}
+
+	if bestLength < 0 {
+		return "", RegistryCredential{}, false
+	}
+	return bestPattern, r.Credentials[bestPattern], true
+}
+
+// matchCredentialPattern reports whether pattern matches repository and returns the
+// literal prefix that was compared, which ranks competing matches
+func matchCredentialPattern(pattern, repository string) (string, bool) {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return prefix, strings.HasPrefix(repository, prefix)
+	}
+
+	pattern = strings.TrimSuffix(pattern, "/")
+	return pattern, repository == pattern || strings.HasPrefix(repository, pattern+"/")
+}
+
+// TokenFor returns the configured token for repository. ok is false when no credential
+// pattern matches, in which case the default GitHub token applies.
+func (r RegistryConfig) TokenFor(repository string, lookupEnv func(string) (string, bool)) (token string, ok bool, err error) {
+	pattern, cred, ok := r.CredentialFor(repository)
+	if !ok {
+		return "", false, nil
+	}
+
+	token, _ = lookupEnv(cred.TokenEnv)
+	if token == "" {
+		return "", true, dgerrors.Mark(fmt.Errorf("registry credential %q for %s: environment variable %s is not set", pattern, repository, cred.TokenEnv), dgerrors.ErrNotAuthenticated)
+	}
+	return token, true, nil
+}
+
+// CredentialLookup returns TokenFor bound to the process environment, in the form
+// registry.RegistryOpts.WithCredentials expects. It is nil when no credentials are
+// configured, so registry clients keep requiring the default token.
+func (r RegistryConfig) CredentialLookup() func(repository string) (string, bool, error) {
+	if len(r.Credentials) == 0 {
+		return nil
+	}
+	return func(repository string) (string, bool, error) {
+		return r.TokenFor(repository, os.LookupEnv)
+	}
+}
+
+// validateCredentials checks that every pattern is usable and names a token variable
+func (r RegistryConfig) validateCredentials() error {
+	for pattern, cred := range r.Credentials {
+		if pattern == "" || pattern == "*" {
+			return fmt.Errorf("registry.credentials: pattern %q must name a registry host", pattern)
+		}
+		if strings.Contains(strings.TrimSuffix(pattern, "*"), "*") {
+			return fmt.Errorf("registry.credentials: pattern %q may only use * at the end", pattern)
+		}
+		if cred.TokenEnv == "" {
+			return fmt.Errorf("registry.credentials: pattern %q is missing token_env", pattern)
+		}
+	}
+	return nil
+}
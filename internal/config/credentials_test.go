@@ -0,0 +1,142 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+)
+
+func TestCredentialFor(t *testing.T) {
+	registry := RegistryConfig{
+		Credentials: map[string]RegistryCredential{
+			"ghcr.io/*":               {TokenEnv: "GHCR_TOKEN"},
+			"ghcr.io/acme/*":          {TokenEnv: "ACME_TOKEN"},
+			"ghcr.io/acme/notes":      {TokenEnv: "NOTES_TOKEN"},
+			"registry.example.com/ab": {TokenEnv: "AB_TOKEN"},
+		},
+	}
+
+	tests := []struct {
+		repository string
+		pattern    string
+	}{
+		{repository: "ghcr.io/acme/notes/notes-plugin", pattern: "ghcr.io/acme/notes"},
+		{repository: "ghcr.io/acme/notes", pattern: "ghcr.io/acme/notes"},
+		{repository: "ghcr.io/acme/notes-extra/plugin", pattern: "ghcr.io/acme/*"},
+		{repository: "ghcr.io/ACME/tasks/plugin", pattern: "ghcr.io/acme/*"},
+		{repository: "ghcr.io/someone/plugin", pattern: "ghcr.io/*"},
+		{repository: "registry.example.com/abc/plugin", pattern: ""},
+		{repository: "docker.io/library/plugin", pattern: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repository, func(t *testing.T) {
+			pattern, _, ok := registry.CredentialFor(tt.repository)
+			if tt.pattern == "" {
+				if ok {
+					t.Errorf("expected no match, got %s", pattern)
+				}
+				return
+			}
+			if !ok || pattern != tt.pattern {
+				t.Errorf("expected pattern %s, got %s (matched: %v)", tt.pattern, pattern, ok)
+			}
+		})
+	}
+}
+
+func TestTokenFor(t *testing.T) {
+	registry := RegistryConfig{
+		Credentials: map[string]RegistryCredential{
+			"ghcr.io/acme/*":  {TokenEnv: "ACME_TOKEN"},
+			"ghcr.io/other/*": {TokenEnv: "OTHER_TOKEN"},
+		},
+	}
+	env := envMap(map[string]string{"ACME_TOKEN": "acme-secret"})
+
+	token, ok, err := registry.TokenFor("ghcr.io/acme/notes/plugin", env)
+	if err != nil || !ok || token != "acme-secret" {
+		t.Errorf("expected acme token, got %q (ok %v, err %v)", token, ok, err)
+	}
+
+	if _, ok, err := registry.TokenFor("ghcr.io/public/plugin", env); ok || err != nil {
+		t.Errorf("expected no credential for public repository, got ok %v, err %v", ok, err)
+	}
+
+	_, ok, err = registry.TokenFor("ghcr.io/other/plugin", env)
+	if !ok || !errors.Is(err, dgerrors.ErrNotAuthenticated) || !strings.Contains(err.Error(), "OTHER_TOKEN") {
+		t.Errorf("expected ErrNotAuthenticated naming OTHER_TOKEN, got ok %v, err %v", ok, err)
+	}
+
+	if (RegistryConfig{}).CredentialLookup() != nil {
+		t.Error("expected no credential lookup without configured credentials")
+	}
+}
+
+func TestValidateCredentials(t *testing.T) {
+	tests := []struct {
+		name        string
+		credentials map[string]RegistryCredential
+		errorMsg    string
+	}{
+		{name: "valid", credentials: map[string]RegistryCredential{"ghcr.io/acme/*": {TokenEnv: "ACME_TOKEN"}}},
+		{name: "missing token_env", credentials: map[string]RegistryCredential{"ghcr.io/acme/*": {}}, errorMsg: "missing token_env"},
+		{name: "wildcard only", credentials: map[string]RegistryCredential{"*": {TokenEnv: "TOKEN"}}, errorMsg: "must name a registry host"},
+		{name: "inner wildcard", credentials: map[string]RegistryCredential{"ghcr.io/*/plugin": {TokenEnv: "TOKEN"}}, errorMsg: "only use * at the end"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Registry.Credentials = tt.credentials
+
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestResolveMergesCredentials(t *testing.T) {
+	tempDir := t.TempDir()
+	userPath := filepath.Join(tempDir, "user", UserConfigFileName)
+	vaultPath := filepath.Join(tempDir, "vault", ObsidianDirName, ConfigFileName)
+
+	writeConfigFile(t, userPath, `{
+		"version": "1",
+		"registry": {"credentials": {"ghcr.io/acme/*": {"token_env": "USER_ACME"}, "ghcr.io/personal/*": {"token_env": "PERSONAL"}}}
+	}`)
+	writeConfigFile(t, vaultPath, `{
+		"version": "1",
+		"registry": {"credentials": {"ghcr.io/acme/*": {"token_env": "VAULT_ACME"}}}
+	}`)
+
+	resolved, err := Resolve(DefaultResolverOpts().
+		WithUserConfigPath(userPath).
+		WithVaultConfigPath(vaultPath).
+		WithLookupEnv(envMap(nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	credentials := resolved.Config.Registry.Credentials
+	if credentials["ghcr.io/acme/*"].TokenEnv != "VAULT_ACME" {
+		t.Errorf("expected vault credential to override user credential, got %v", credentials)
+	}
+	if credentials["ghcr.io/personal/*"].TokenEnv != "PERSONAL" {
+		t.Errorf("expected user credential to be kept, got %v", credentials)
+	}
+	if resolved.Origin("registry.credentials") != SourceVault {
+		t.Errorf("expected vault origin, got %s", resolved.Origin("registry.credentials"))
+	}
+}
@@ -0,0 +1,102 @@
+// ABOUTME: In-place editing and validation of individual config files
+// ABOUTME: Updates single settings without materialising defaults into the file
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/schema"
+)
+
+// SetFileValue validates value against the setting and stores it in the config file at
+// path. Other settings already present in the file are preserved, and settings that are
+// absent stay absent so lower-precedence layers keep applying. The file is created if missing.
+func SetFileValue(path, key, value string) error {
+	s, ok := LookupSetting(key)
+	if !ok {
+		return fmt.Errorf("unknown configuration key: %s", key)
+	}
+
+	parsed, err := s.Parse(value)
+	if err != nil {
+		return err
+	}
+
+	contents := map[string]any{}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &contents); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+	if _, ok := contents["version"]; !ok {
+		contents["version"] = DefaultConfig().Version
+	}
+
+	section, field, _ := strings.Cut(key, ".")
+	fields, ok := contents[section].(map[string]any)
+	if !ok {
+		fields = map[string]any{}
+	}
+	fields[field] = parsed
+	contents[section] = fields
+
+	data, err = json.MarshalIndent(contents, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := ValidateData(data, path); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, DefaultConfigPerms); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// ValidateFile checks that the config file at path is well-formed and that every
+// setting it contains has a valid value
+func ValidateFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	return ValidateData(data, path)
+}
+
+// ValidateData checks raw config file contents; path is only used in error messages
+func ValidateData(data []byte, path string) error {
+	if err := schema.Config.Validate(data, path); err != nil {
+		return err
+	}
+
+	resolved := &Resolved{
+		Config:  DefaultConfig(),
+		Origins: make(map[string]Source),
+	}
+
+	if err := applyConfigData(resolved, data, path, SourceVault); err != nil {
+		return err
+	}
+
+	if err := resolved.Config.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetFileValue(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ObsidianDirName, ConfigFileName)
+
+	if err := SetFileValue(configPath, "verification.strict_mode", "true"); err != nil {
+		t.Fatalf("failed to set value: %v", err)
+	}
+	if err := SetFileValue(configPath, "registry.default_registry", "registry.example.com"); err != nil {
+		t.Fatalf("failed to set value: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to read config file: %v", err)
+	}
+
+	var contents map[string]any
+	if err := json.Unmarshal(data, &contents); err != nil {
+		t.Fatalf("failed to parse config file: %v", err)
+	}
+	verification, _ := contents["verification"].(map[string]any)
+	registry, _ := contents["registry"].(map[string]any)
+
+	if verification["strict_mode"] != true {
+		t.Errorf("expected strict_mode to be stored as a boolean, got %v", verification["strict_mode"])
+	}
+	if registry["default_registry"] != "registry.example.com" {
+		t.Errorf("expected default_registry to be stored, got %v", registry["default_registry"])
+	}
+	if _, ok := contents["output"]; ok {
+		t.Error("expected unset sections to stay absent from the file")
+	}
+	if _, ok := verification["skip_vuln_scan"]; ok {
+		t.Error("expected unset settings to stay absent from the file")
+	}
+}
+
+func TestSetFileValueValidation(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ConfigFileName)
+
+	tests := []struct {
+		name     string
+		key      string
+		value    string
+		errorMsg string
+	}{
+		{name: "unknown key", key: "verification.nope", value: "true", errorMsg: "unknown configuration key"},
+		{name: "wrong type", key: "output.color", value: "blue", errorMsg: "expects a boolean"},
+		{name: "value not allowed", key: "output.format", value: "xml", errorMsg: "must be one of"},
+		{name: "empty required value", key: "registry.default_registry", value: "", errorMsg: "/registry/default_registry: must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetFileValue(configPath, tt.key, tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+			}
+			if _, statErr := os.Stat(configPath); !os.IsNotExist(statErr) {
+				t.Error("expected invalid value not to be written")
+			}
+		})
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ConfigFileName)
+
+	writeConfigFile(t, configPath, `{"version": "1", "output": {"format": "json"}}`)
+	if err := ValidateFile(configPath); err != nil {
+		t.Errorf("expected valid config, got %v", err)
+	}
+
+	writeConfigFile(t, configPath, `{"version": "1", "output": {"format": 3}}`)
+	if err := ValidateFile(configPath); err == nil || !strings.Contains(err.Error(), "line 1, column 39: /output/format: expected string, got number") {
+		t.Errorf("expected type error, got %v", err)
+	}
+}
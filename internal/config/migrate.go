@@ -0,0 +1,73 @@
+// ABOUTME: Schema migrations and validation applied when config files are loaded
+// ABOUTME: Upgrades legacy config layouts to the current version before validation
+package config
+
+import (
+	"github.com/gillisandrew/dragonglass-poc/internal/migrate"
+	"github.com/gillisandrew/dragonglass-poc/internal/schema"
+)
+
+// configMigrator upgrades older config files to the current schema version
+var configMigrator = &migrate.Migrator{
+	Kind:    "config",
+	Current: "1",
+	Steps: []migrate.Step{
+		{
+			From:        "1.0",
+			To:          "1",
+			Description: "move camelCase top-level settings into sections and drop the plugins list",
+			Apply:       migrateLegacyConfig,
+		},
+	},
+}
+
+// migrateLegacyConfig converts the original flat layout:
+//
+//	{"version": "1.0", "trustedBuilder": "...", "annotationNamespace": "...", "plugins": []}
+//
+// Installed plugins are tracked by the lockfile, so the plugins list is discarded.
+func migrateLegacyConfig(doc map[string]any) error {
+	moves := []struct {
+		from    string
+		section string
+		field   string
+	}{
+		{from: "trustedBuilder", section: "verification", field: "trusted_builder"},
+		{from: "annotationNamespace", section: "registry", field: "annotation_namespace"},
+	}
+
+	for _, move := range moves {
+		value, ok := doc[move.from]
+		if !ok {
+			continue
+		}
+		delete(doc, move.from)
+
+		section, ok := doc[move.section].(map[string]any)
+		if !ok {
+			section = map[string]any{}
+			doc[move.section] = section
+		}
+		if _, exists := section[move.field]; !exists {
+			section[move.field] = value
+		}
+	}
+
+	delete(doc, "plugins")
+	return nil
+}
+
+// prepareConfigData migrates config file contents in place if needed and validates
+// them against the config schema
+func prepareConfigData(path string, data []byte) ([]byte, error) {
+	data, _, err := configMigrator.MigrateFile(path, data, DefaultConfigPerms)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := schema.Config.Validate(data, path); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
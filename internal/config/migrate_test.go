@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/migrate"
+)
+
+func TestLoadConfigMigratesLegacyFormat(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ConfigFileName)
+	legacy := `{
+  "version": "1.0",
+  "trustedBuilder": "https://example.com/builder",
+  "annotationNamespace": "md.example.plugin.v0",
+  "plugins": []
+}`
+	writeConfigFile(t, configPath, legacy)
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("failed to load legacy config: %v", err)
+	}
+
+	if cfg.Version != "1" {
+		t.Errorf("expected version 1, got %s", cfg.Version)
+	}
+	if cfg.Verification.TrustedBuilder != "https://example.com/builder" {
+		t.Errorf("expected trusted builder to be migrated, got %s", cfg.Verification.TrustedBuilder)
+	}
+	if cfg.Registry.AnnotationNamespace != "md.example.plugin.v0" {
+		t.Errorf("expected annotation namespace to be migrated, got %s", cfg.Registry.AnnotationNamespace)
+	}
+
+	backup, err := os.ReadFile(migrate.BackupPath(configPath, "1.0"))
+	if err != nil {
+		t.Fatalf("expected backup of legacy config: %v", err)
+	}
+	if string(backup) != legacy {
+		t.Error("expected backup to hold the legacy contents")
+	}
+
+	// The migrated file must load cleanly on its own
+	if err := ValidateFile(configPath); err != nil {
+		t.Errorf("expected migrated config to be valid, got %v", err)
+	}
+}
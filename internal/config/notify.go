@@ -0,0 +1,81 @@
+// ABOUTME: Webhook targets notified by the notify command about updates and vulnerabilities
+// ABOUTME: Webhook URLs can be read from environment variables so secrets stay out of config
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+)
+
+// Notification target types and the alert kinds a target can subscribe to
+var (
+	NotifyTargetTypes = []string{"slack", "discord", "generic"}
+	NotifyEvents      = []string{"update", "vulnerability", "regression"}
+)
+
+type NotifyConfig struct {
+	// Webhook targets keyed by name
+	Targets map[string]NotifyTarget `json:"targets,omitempty"`
+}
+
+// NotifyTarget is a webhook that receives notify reports
+type NotifyTarget struct {
+	// "slack", "discord" or "generic" (the report as JSON)
+	Type string `json:"type"`
+
+	// Webhook URL, or the environment variable that holds it; set exactly one
+	URL    string `json:"url,omitempty"`
+	URLEnv string `json:"url_env,omitempty"`
+
+	// Alert kinds to deliver: "update", "vulnerability" and "regression" (all when empty)
+	Events []string `json:"events,omitempty"`
+}
+
+// TargetNames returns the configured target names in alphabetical order
+func (n NotifyConfig) TargetNames() []string {
+	names := make([]string, 0, len(n.Targets))
+	for name := range n.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WebhookURL returns the target's URL, reading it from URLEnv when set
+func (t NotifyTarget) WebhookURL(name string, lookupEnv func(string) (string, bool)) (string, error) {
+	if t.URLEnv == "" {
+		return t.URL, nil
+	}
+	value, _ := lookupEnv(t.URLEnv)
+	if value == "" {
+		return "", fmt.Errorf("notify target %q: environment variable %s is not set", name, t.URLEnv)
+	}
+	return value, nil
+}
+
+// validateTargets checks each target's type, URL, and events
+func (n NotifyConfig) validateTargets() error {
+	for _, name := range n.TargetNames() {
+		target := n.Targets[name]
+		if !slices.Contains(NotifyTargetTypes, target.Type) {
+			return fmt.Errorf("notify.targets: %q has invalid type %q (must be one of %v)", name, target.Type, NotifyTargetTypes)
+		}
+		if (target.URL == "") == (target.URLEnv == "") {
+			return fmt.Errorf("notify.targets: %q must set exactly one of url and url_env", name)
+		}
+		if target.URL != "" {
+			u, err := url.Parse(target.URL)
+			if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				return fmt.Errorf("notify.targets: %q url must be an http or https URL", name)
+			}
+		}
+		for _, event := range target.Events {
+			if !slices.Contains(NotifyEvents, event) {
+				return fmt.Errorf("notify.targets: %q has invalid event %q (must be one of %v)", name, event, NotifyEvents)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveNotifyTargets(t *testing.T) {
+	tempDir := t.TempDir()
+	userPath := filepath.Join(tempDir, "user", UserConfigFileName)
+	vaultPath := filepath.Join(tempDir, "vault", ConfigFileName)
+
+	writeConfigFile(t, userPath, `{
+		"version": "1",
+		"notify": {"targets": {"me": {"type": "discord", "url_env": "DISCORD_WEBHOOK"}, "team": {"type": "slack", "url_env": "USER_SLACK"}}}
+	}`)
+	writeConfigFile(t, vaultPath, `{
+		"version": "1",
+		"notify": {"targets": {"team": {"type": "slack", "url_env": "VAULT_SLACK", "events": ["vulnerability"]}}}
+	}`)
+
+	opts := DefaultResolverOpts().
+		WithUserConfigPath(userPath).
+		WithVaultConfigPath(vaultPath).
+		WithLookupEnv(envMap(nil))
+	resolved, err := Resolve(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	notify := resolved.Config.Notify
+	if got := strings.Join(notify.TargetNames(), ","); got != "me,team" {
+		t.Errorf("expected targets from both layers, got %q", got)
+	}
+	if team := notify.Targets["team"]; team.URLEnv != "VAULT_SLACK" || len(team.Events) != 1 {
+		t.Errorf("expected the vault to replace the user's team target, got %+v", team)
+	}
+	if resolved.Origin("notify.targets") != SourceVault {
+		t.Errorf("expected notify.targets origin vault, got %s", resolved.Origin("notify.targets"))
+	}
+}
+
+func TestValidateNotifyTargets(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   NotifyTarget
+		errorMsg string
+	}{
+		{name: "url", target: NotifyTarget{Type: "generic", URL: "https://hooks.example.com/dragonglass"}},
+		{name: "url from env", target: NotifyTarget{Type: "slack", URLEnv: "SLACK_WEBHOOK", Events: []string{"update"}}},
+		{name: "unknown type", target: NotifyTarget{Type: "teams", URLEnv: "X"}, errorMsg: "invalid type"},
+		{name: "no url", target: NotifyTarget{Type: "slack"}, errorMsg: "exactly one of url and url_env"},
+		{name: "both urls", target: NotifyTarget{Type: "slack", URL: "https://a.example", URLEnv: "X"}, errorMsg: "exactly one of url and url_env"},
+		{name: "not http", target: NotifyTarget{Type: "generic", URL: "file:///tmp/x"}, errorMsg: "http or https URL"},
+		{name: "unknown event", target: NotifyTarget{Type: "discord", URLEnv: "X", Events: []string{"install"}}, errorMsg: "invalid event"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NotifyConfig{Targets: map[string]NotifyTarget{"target": tt.target}}.validateTargets()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestWebhookURL(t *testing.T) {
+	env := envMap(map[string]string{"SLACK_WEBHOOK": "https://hooks.slack.com/services/T/B/x"})
+
+	url, err := NotifyTarget{Type: "slack", URLEnv: "SLACK_WEBHOOK"}.WebhookURL("team", env)
+	if err != nil || url != "https://hooks.slack.com/services/T/B/x" {
+		t.Errorf("expected URL from the environment, got %q (%v)", url, err)
+	}
+
+	if _, err := (NotifyTarget{Type: "slack", URLEnv: "MISSING"}).WebhookURL("team", env); err == nil || !strings.Contains(err.Error(), "MISSING is not set") {
+		t.Errorf("expected missing variable error, got %v", err)
+	}
+}
@@ -0,0 +1,523 @@
+// ABOUTME: Layered configuration resolver combining defaults, config files, env vars, and flags
+// ABOUTME: Precedence is flag > env > vault config > user config > defaults, tracked per setting
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix is the prefix shared by all environment variable overrides
+const EnvPrefix = "DRAGONGLASS_"
+
+// Source identifies which layer supplied the effective value of a setting
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceUser    Source = "user"
+	SourceVault   Source = "vault"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Setting describes a single configurable value addressable by key and environment variable
+type Setting struct {
+	// Dotted JSON path within the config file, e.g. "verification.strict_mode"
+	Key string
+
+	// Environment variable that overrides the setting
+	EnvVar string
+
+	// Accepted values for enumerated settings (empty means any value)
+	Allowed []string
+
+	field func(*Config) any
+}
+
+var settings = []Setting{
+	{Key: "vault.state_dir", EnvVar: EnvPrefix + "STATE_DIR", field: func(c *Config) any { return &c.Vault.StateDir }},
+	{Key: "verification.strict_mode", EnvVar: EnvPrefix + "STRICT", field: func(c *Config) any { return &c.Verification.StrictMode }},
+	{Key: "verification.skip_vuln_scan", EnvVar: EnvPrefix + "SKIP_VULN_SCAN", field: func(c *Config) any { return &c.Verification.SkipVulnScan }},
+	{Key: "verification.allow_high_severity", EnvVar: EnvPrefix + "ALLOW_HIGH_SEVERITY", field: func(c *Config) any { return &c.Verification.AllowHighSeverity }},
+	{Key: "verification.trusted_builder", EnvVar: EnvPrefix + "TRUSTED_BUILDER", field: func(c *Config) any { return &c.Verification.TrustedBuilder }},
+	{Key: "verification.timeout", EnvVar: EnvPrefix + "VERIFICATION_TIMEOUT", field: func(c *Config) any { return &c.Verification.Timeout }},
+	{Key: "verification.concurrency", EnvVar: EnvPrefix + "VERIFICATION_CONCURRENCY", field: func(c *Config) any { return &c.Verification.Concurrency }},
+	{Key: "verification.block_risk", EnvVar: EnvPrefix + "BLOCK_RISK", Allowed: []string{"off", "low", "medium", "high"}, field: func(c *Config) any { return &c.Verification.BlockRisk }},
+	{Key: "verification.require_signed_lockfile", EnvVar: EnvPrefix + "REQUIRE_SIGNED_LOCKFILE", field: func(c *Config) any { return &c.Verification.RequireSignedLockfile }},
+	{Key: "verification.lockfile_key", EnvVar: EnvPrefix + "LOCKFILE_KEY", field: func(c *Config) any { return &c.Verification.LockfileKey }},
+	{Key: "verification.lockfile_signer", EnvVar: EnvPrefix + "LOCKFILE_SIGNER", field: func(c *Config) any { return &c.Verification.LockfileSigner }},
+	{Key: "output.format", EnvVar: EnvPrefix + "OUTPUT_FORMAT", Allowed: []string{"text", "json", "yaml"}, field: func(c *Config) any { return &c.Output.Format }},
+	{Key: "output.verbose", EnvVar: EnvPrefix + "VERBOSE", field: func(c *Config) any { return &c.Output.Verbose }},
+	{Key: "output.color", EnvVar: EnvPrefix + "COLOR", field: func(c *Config) any { return &c.Output.Color }},
+	{Key: "output.plain", EnvVar: EnvPrefix + "PLAIN", field: func(c *Config) any { return &c.Output.Plain }},
+	{Key: "output.emoji", EnvVar: EnvPrefix + "EMOJI", field: func(c *Config) any { return &c.Output.Emoji }},
+	{Key: "output.quiet", EnvVar: EnvPrefix + "QUIET", field: func(c *Config) any { return &c.Output.Quiet }},
+	{Key: "output.language", EnvVar: EnvPrefix + "LANG", Allowed: []string{"auto", "en", "de"}, field: func(c *Config) any { return &c.Output.Language }},
+	{Key: "registry.default_registry", EnvVar: EnvPrefix + "REGISTRY", field: func(c *Config) any { return &c.Registry.DefaultRegistry }},
+	{Key: "registry.annotation_namespace", EnvVar: EnvPrefix + "ANNOTATION_NAMESPACE", field: func(c *Config) any { return &c.Registry.AnnotationNamespace }},
+	{Key: "registry.docker_credentials", EnvVar: EnvPrefix + "DOCKER_CREDENTIALS", field: func(c *Config) any { return &c.Registry.DockerCredentials }},
+	{Key: "registry.timeout", EnvVar: EnvPrefix + "REGISTRY_TIMEOUT", field: func(c *Config) any { return &c.Registry.Timeout }},
+	{Key: "install.timeout", EnvVar: EnvPrefix + "INSTALL_TIMEOUT", field: func(c *Config) any { return &c.Install.Timeout }},
+	{Key: "install.sync_ignore", EnvVar: EnvPrefix + "SYNC_IGNORE", field: func(c *Config) any { return &c.Install.SyncIgnore }},
+	{Key: "install.changelog", EnvVar: EnvPrefix + "CHANGELOG", field: func(c *Config) any { return &c.Install.Changelog }},
+	{Key: "install.write_attempts", EnvVar: EnvPrefix + "INSTALL_WRITE_ATTEMPTS", field: func(c *Config) any { return &c.Install.WriteAttempts }},
+	{Key: "install.attestations", EnvVar: EnvPrefix + "INSTALL_ATTESTATIONS", field: func(c *Config) any { return &c.Install.Attestations }},
+	{Key: "install.attestation_key", EnvVar: EnvPrefix + "INSTALL_ATTESTATION_KEY", field: func(c *Config) any { return &c.Install.AttestationKey }},
+	{Key: "auth.credential_store", EnvVar: EnvPrefix + "CREDENTIAL_STORE", Allowed: []string{"auto", "keyring", "file", "env", "none"}, field: func(c *Config) any { return &c.Auth.CredentialStore }},
+	{Key: "auth.oidc_exchange_url", EnvVar: EnvPrefix + "OIDC_EXCHANGE_URL", field: func(c *Config) any { return &c.Auth.OIDCExchangeURL }},
+	{Key: "auth.oidc_audience", EnvVar: EnvPrefix + "OIDC_AUDIENCE", field: func(c *Config) any { return &c.Auth.OIDCAudience }},
+	{Key: "auth.oauth_client_id", EnvVar: EnvPrefix + "OAUTH_CLIENT_ID", field: func(c *Config) any { return &c.Auth.OAuthClientID }},
+	{Key: "catalog.reference", EnvVar: EnvPrefix + "CATALOG", field: func(c *Config) any { return &c.Catalog.Reference }},
+	{Key: "catalog.ttl", EnvVar: EnvPrefix + "CATALOG_TTL", field: func(c *Config) any { return &c.Catalog.TTL }},
+	{Key: "catalog.trusted_builder", EnvVar: EnvPrefix + "CATALOG_TRUSTED_BUILDER", field: func(c *Config) any { return &c.Catalog.TrustedBuilder }},
+	{Key: "hooks.disabled", EnvVar: EnvPrefix + "NO_HOOKS", field: func(c *Config) any { return &c.Hooks.Disabled }},
+}
+
+// Settings returns every setting that can be overridden, in display order
+func Settings() []Setting {
+	return append([]Setting(nil), settings...)
+}
+
+// LookupSetting finds a setting by its dotted key
+func LookupSetting(key string) (Setting, bool) {
+	for _, s := range settings {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+// Get returns the setting's value in cfg formatted as a string
+func (s Setting) Get(cfg *Config) string {
+	switch v := s.field(cfg).(type) {
+	case *bool:
+		return strconv.FormatBool(*v)
+	case *string:
+		return *v
+	case *int:
+		return strconv.Itoa(*v)
+	case *Duration:
+		return v.String()
+	default:
+		return ""
+	}
+}
+
+// Set parses value according to the setting's type and stores it in cfg
+func (s Setting) Set(cfg *Config, value string) error {
+	parsed, err := s.Parse(value)
+	if err != nil {
+		return err
+	}
+	switch v := s.field(cfg).(type) {
+	case *bool:
+		*v = parsed.(bool)
+	case *string:
+		*v = parsed.(string)
+	case *int:
+		*v = parsed.(int)
+	case *Duration:
+		*v = parsed.(Duration)
+	}
+	return nil
+}
+
+// Parse converts a string into the setting's typed value, validating it against the schema
+func (s Setting) Parse(value string) (any, error) {
+	switch s.field(&Config{}).(type) {
+	case *bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s expects a boolean, got %q", s.Key, value)
+		}
+		return parsed, nil
+	case *int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s expects an integer, got %q", s.Key, value)
+		}
+		return parsed, nil
+	case *Duration:
+		parsed, err := ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.Key, err)
+		}
+		return parsed, nil
+	default:
+		if err := s.checkAllowed(value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+}
+
+// setJSON stores a raw JSON value read from a config file
+func (s Setting) setJSON(cfg *Config, raw json.RawMessage) error {
+	switch v := s.field(cfg).(type) {
+	case *bool:
+		if err := json.Unmarshal(raw, v); err != nil {
+			return fmt.Errorf("%s expects a boolean, got %s", s.Key, raw)
+		}
+	case *string:
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return fmt.Errorf("%s expects a string, got %s", s.Key, raw)
+		}
+		if err := s.checkAllowed(value); err != nil {
+			return err
+		}
+		*v = value
+	case *int:
+		if err := json.Unmarshal(raw, v); err != nil {
+			return fmt.Errorf("%s expects an integer, got %s", s.Key, raw)
+		}
+	case *Duration:
+		if err := json.Unmarshal(raw, v); err != nil {
+			return fmt.Errorf("%s: %w", s.Key, err)
+		}
+	}
+	return nil
+}
+
+func (s Setting) checkAllowed(value string) error {
+	if len(s.Allowed) == 0 {
+		return nil
+	}
+	for _, allowed := range s.Allowed {
+		if value == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid %s: %s (must be one of %s)", s.Key, value, strings.Join(s.Allowed, ", "))
+}
+
+// ResolverOpts configures which layers contribute to the effective configuration
+type ResolverOpts struct {
+	// User-level config path (default: config.json in the OS config directory, empty disables)
+	UserConfigPath string
+
+	// Vault config path (default: auto-discover from WorkingDir). Resolve fails when an
+	// explicit path does not exist, unless CreateVaultConfig is set.
+	VaultConfigPath string
+
+	// Whether an explicit VaultConfigPath may not exist yet, for commands that create it
+	CreateVaultConfig bool
+
+	// Override working directory for vault auto-discovery
+	WorkingDir string
+
+	// Explicitly set command line flags, keyed by setting key
+	Flags map[string]string
+
+	// GitHub token passed on the command line
+	GitHubToken string
+
+	// Environment lookup (default: os.LookupEnv)
+	LookupEnv func(string) (string, bool)
+}
+
+// DefaultResolverOpts returns options that read the user config and process environment
+func DefaultResolverOpts() *ResolverOpts {
+	userConfigPath, _ := GetUserConfigPath()
+	return &ResolverOpts{
+		UserConfigPath: userConfigPath,
+		Flags:          make(map[string]string),
+		LookupEnv:      os.LookupEnv,
+	}
+}
+
+// WithUserConfigPath sets the user-level config path
+func (opts *ResolverOpts) WithUserConfigPath(path string) *ResolverOpts {
+	opts.UserConfigPath = path
+	return opts
+}
+
+// WithVaultConfigPath sets an explicit vault config path
+func (opts *ResolverOpts) WithVaultConfigPath(path string) *ResolverOpts {
+	opts.VaultConfigPath = path
+	return opts
+}
+
+// WithCreateVaultConfig allows an explicit vault config path that does not exist yet, for
+// commands that create the file
+func (opts *ResolverOpts) WithCreateVaultConfig(create bool) *ResolverOpts {
+	opts.CreateVaultConfig = create
+	return opts
+}
+
+// WithWorkingDir sets the working directory used to discover the vault config
+func (opts *ResolverOpts) WithWorkingDir(dir string) *ResolverOpts {
+	opts.WorkingDir = dir
+	return opts
+}
+
+// WithFlag records a command line override for the given setting key
+func (opts *ResolverOpts) WithFlag(key, value string) *ResolverOpts {
+	if opts.Flags == nil {
+		opts.Flags = make(map[string]string)
+	}
+	opts.Flags[key] = value
+	return opts
+}
+
+// WithGitHubToken sets the GitHub token passed on the command line
+func (opts *ResolverOpts) WithGitHubToken(token string) *ResolverOpts {
+	opts.GitHubToken = token
+	return opts
+}
+
+// WithLookupEnv sets the function used to read environment variables
+func (opts *ResolverOpts) WithLookupEnv(lookup func(string) (string, bool)) *ResolverOpts {
+	opts.LookupEnv = lookup
+	return opts
+}
+
+// Resolved is the effective configuration along with where each value came from
+type Resolved struct {
+	Config *Config
+
+	// Layer that supplied each setting, keyed by setting key
+	Origins map[string]Source
+
+	// Config files that were consulted (empty when not present)
+	UserConfigPath  string
+	VaultConfigPath string
+
+	GitHubToken string
+}
+
+// Origin returns the layer that supplied the effective value of key
+func (r *Resolved) Origin(key string) Source {
+	if source, ok := r.Origins[key]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// Resolve builds the effective configuration by applying each layer in precedence order
+func Resolve(opts *ResolverOpts) (*Resolved, error) {
+	if opts == nil {
+		opts = DefaultResolverOpts()
+	}
+	lookupEnv := opts.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	resolved := &Resolved{
+		Config:  DefaultConfig(),
+		Origins: make(map[string]Source),
+	}
+
+	// Layer 1: user config
+	if opts.UserConfigPath != "" {
+		found, err := applyConfigFile(resolved, opts.UserConfigPath, SourceUser)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			resolved.UserConfigPath = opts.UserConfigPath
+		}
+	}
+
+	// Layer 2: vault config
+	vaultConfigPath := opts.VaultConfigPath
+	if vaultConfigPath == "" {
+		vaultConfigPath = discoverVaultConfig(opts.WorkingDir)
+	}
+	if vaultConfigPath != "" {
+		found, err := applyConfigFile(resolved, vaultConfigPath, SourceVault)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			resolved.VaultConfigPath = vaultConfigPath
+		} else if opts.VaultConfigPath != "" && !opts.CreateVaultConfig {
+			return nil, fmt.Errorf("config file not found: %s", opts.VaultConfigPath)
+		}
+	}
+
+	// Layer 3: environment variables
+	for _, s := range settings {
+		value, ok := lookupEnv(s.EnvVar)
+		if !ok || value == "" {
+			continue
+		}
+		if err := s.Set(resolved.Config, value); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", s.EnvVar, err)
+		}
+		resolved.Origins[s.Key] = SourceEnv
+	}
+
+	// Layer 4: command line flags
+	flagKeys := make([]string, 0, len(opts.Flags))
+	for key := range opts.Flags {
+		flagKeys = append(flagKeys, key)
+	}
+	sort.Strings(flagKeys)
+	for _, key := range flagKeys {
+		s, ok := LookupSetting(key)
+		if !ok {
+			return nil, fmt.Errorf("unknown configuration key: %s", key)
+		}
+		if err := s.Set(resolved.Config, opts.Flags[key]); err != nil {
+			return nil, fmt.Errorf("invalid flag value: %w", err)
+		}
+		resolved.Origins[key] = SourceFlag
+	}
+
+	// The "none" store ignores ambient token variables so only an explicit flag applies
+	if resolved.Config.Auth.CredentialStore == "none" {
+		resolved.GitHubToken = opts.GitHubToken
+	} else {
+		resolved.GitHubToken = resolveGitHubToken(opts.GitHubToken, lookupEnv)
+	}
+
+	if err := resolved.Config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return resolved, nil
+}
+
+// resolveGitHubToken returns the token from the flag or the first populated environment variable
+func resolveGitHubToken(flagToken string, lookupEnv func(string) (string, bool)) string {
+	if flagToken != "" {
+		return flagToken
+	}
+	for _, name := range []string{EnvPrefix + "GITHUB_TOKEN", "GITHUB_TOKEN", "GH_TOKEN"} {
+		if token, ok := lookupEnv(name); ok && token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// discoverVaultConfig returns the config path of the vault containing workingDir, if any
+func discoverVaultConfig(workingDir string) string {
+	if workingDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return ""
+		}
+		workingDir = wd
+	}
+
+	obsidianDir, err := FindObsidianDirectory(workingDir)
+	if err != nil {
+		return ""
+	}
+	return GetConfigPath(obsidianDir)
+}
+
+// applyConfigFile overlays the settings present in a config file. Settings absent from
+// the file keep the value from lower layers. It reports whether the file exists.
+func applyConfigFile(resolved *Resolved, path string, source Source) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	data, err = prepareConfigData(path, data)
+	if err != nil {
+		return false, err
+	}
+
+	return true, applyConfigData(resolved, data, path, source)
+}
+
+// applyConfigData overlays the settings present in raw config file contents
+func applyConfigData(resolved *Resolved, data []byte, path string, source Source) error {
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	for section, raw := range sections {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			// Scalar top-level fields such as "version" are not overridable settings
+			continue
+		}
+
+		for field, value := range fields {
+			key := section + "." + field
+			if key == "registry.mirrors" {
+				var mirrors map[string]string
+				if err := json.Unmarshal(value, &mirrors); err != nil {
+					return fmt.Errorf("%s: registry.mirrors expects an object of strings", path)
+				}
+				for from, to := range mirrors {
+					resolved.Config.Registry.Mirrors[from] = to
+				}
+				resolved.Origins[key] = source
+				continue
+			}
+			if key == "registry.credentials" {
+				var credentials map[string]RegistryCredential
+				if err := json.Unmarshal(value, &credentials); err != nil {
+					return fmt.Errorf("%s: registry.credentials expects an object of {\"token_env\": ...} entries", path)
+				}
+				for pattern, cred := range credentials {
+					resolved.Config.Registry.Credentials[pattern] = cred
+				}
+				resolved.Origins[key] = source
+				continue
+			}
+
+			if key == "notify.targets" {
+				var targets map[string]NotifyTarget
+				if err := json.Unmarshal(value, &targets); err != nil {
+					return fmt.Errorf("%s: notify.targets expects an object of {\"type\": ..., \"url_env\": ...} entries", path)
+				}
+				for name, target := range targets {
+					resolved.Config.Notify.Targets[name] = target
+				}
+				resolved.Origins[key] = source
+				continue
+			}
+
+			if commands := hookCommands(resolved.Config, key); commands != nil {
+				if err := json.Unmarshal(value, commands); err != nil {
+					return fmt.Errorf("%s: %s expects an array of commands", path, key)
+				}
+				resolved.Origins[key] = source
+				continue
+			}
+
+			s, ok := LookupSetting(key)
+			if !ok {
+				continue
+			}
+			if err := s.setJSON(resolved.Config, value); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			resolved.Origins[key] = source
+		}
+	}
+
+	return nil
+}
+
+// hookCommands returns the command list for a hooks key, or nil for other keys
+func hookCommands(c *Config, key string) *[]string {
+	switch key {
+	case "hooks.pre_install":
+		return &c.Hooks.PreInstall
+	case "hooks.post_install":
+		return &c.Hooks.PostInstall
+	case "hooks.post_remove":
+		return &c.Hooks.PostRemove
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,270 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+}
+
+func envMap(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
+
+func TestResolvePrecedence(t *testing.T) {
+	tempDir := t.TempDir()
+	userPath := filepath.Join(tempDir, "user", UserConfigFileName)
+	vaultPath := filepath.Join(tempDir, "vault", ObsidianDirName, ConfigFileName)
+
+	writeConfigFile(t, userPath, `{
+		"version": "1",
+		"verification": {"strict_mode": true, "trusted_builder": "https://example.com/user"},
+		"output": {"format": "json"},
+		"registry": {"default_registry": "user.example.com", "mirrors": {"ghcr.io": "user-mirror"}}
+	}`)
+	writeConfigFile(t, vaultPath, `{
+		"version": "1",
+		"verification": {"strict_mode": false},
+		"registry": {"default_registry": "vault.example.com"}
+	}`)
+
+	opts := DefaultResolverOpts().
+		WithUserConfigPath(userPath).
+		WithVaultConfigPath(vaultPath).
+		WithLookupEnv(envMap(map[string]string{
+			"DRAGONGLASS_REGISTRY":        "env.example.com",
+			"DRAGONGLASS_TRUSTED_BUILDER": "https://example.com/env",
+		})).
+		WithFlag("verification.trusted_builder", "https://example.com/flag")
+
+	resolved, err := Resolve(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		value  string
+		origin Source
+	}{
+		{key: "verification.strict_mode", value: "false", origin: SourceVault},
+		{key: "verification.trusted_builder", value: "https://example.com/flag", origin: SourceFlag},
+		{key: "output.format", value: "json", origin: SourceUser},
+		{key: "registry.default_registry", value: "env.example.com", origin: SourceEnv},
+		{key: "output.color", value: "true", origin: SourceDefault},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			s, ok := LookupSetting(tt.key)
+			if !ok {
+				t.Fatalf("unknown setting %s", tt.key)
+			}
+			if got := s.Get(resolved.Config); got != tt.value {
+				t.Errorf("expected value %s, got %s", tt.value, got)
+			}
+			if got := resolved.Origin(tt.key); got != tt.origin {
+				t.Errorf("expected origin %s, got %s", tt.origin, got)
+			}
+		})
+	}
+
+	if resolved.Config.Registry.Mirrors["ghcr.io"] != "user-mirror" {
+		t.Errorf("expected user mirror to be kept, got %v", resolved.Config.Registry.Mirrors)
+	}
+	if resolved.UserConfigPath != userPath || resolved.VaultConfigPath != vaultPath {
+		t.Errorf("expected consulted paths to be recorded, got %s and %s", resolved.UserConfigPath, resolved.VaultConfigPath)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tempDir := t.TempDir()
+	vaultPath := filepath.Join(tempDir, ConfigFileName)
+
+	tests := []struct {
+		name     string
+		vault    string
+		env      map[string]string
+		flags    map[string]string
+		errorMsg string
+	}{
+		{
+			name:     "invalid boolean in environment",
+			env:      map[string]string{"DRAGONGLASS_STRICT": "maybe"},
+			errorMsg: "DRAGONGLASS_STRICT",
+		},
+		{
+			name:     "invalid enum in environment",
+			env:      map[string]string{"DRAGONGLASS_OUTPUT_FORMAT": "xml"},
+			errorMsg: "must be one of text, json, yaml",
+		},
+		{
+			name:     "wrong type in config file",
+			vault:    `{"version": "1", "verification": {"strict_mode": "yes"}}`,
+			errorMsg: "/verification/strict_mode: expected boolean, got string",
+		},
+		{
+			name:     "non-integer concurrency in environment",
+			env:      map[string]string{"DRAGONGLASS_VERIFICATION_CONCURRENCY": "many"},
+			errorMsg: "verification.concurrency expects an integer",
+		},
+		{
+			name:     "zero concurrency in config file",
+			vault:    `{"version": "1", "verification": {"concurrency": 0}}`,
+			errorMsg: "verification.concurrency must be at least 1",
+		},
+		{
+			name:     "zero write attempts in environment",
+			env:      map[string]string{"DRAGONGLASS_INSTALL_WRITE_ATTEMPTS": "0"},
+			errorMsg: "install.write_attempts must be at least 1",
+		},
+		{
+			name:     "unknown credential store",
+			env:      map[string]string{"DRAGONGLASS_CREDENTIAL_STORE": "vault"},
+			errorMsg: "must be one of auto, keyring, file, env, none",
+		},
+		{
+			name:     "unknown flag key",
+			flags:    map[string]string{"verification.unknown": "true"},
+			errorMsg: "unknown configuration key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultResolverOpts().
+				WithUserConfigPath("").
+				WithWorkingDir(tempDir).
+				WithLookupEnv(envMap(tt.env))
+			if tt.vault != "" {
+				writeConfigFile(t, vaultPath, tt.vault)
+				opts = opts.WithVaultConfigPath(vaultPath)
+			}
+			for key, value := range tt.flags {
+				opts = opts.WithFlag(key, value)
+			}
+
+			_, err := Resolve(opts)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestResolveMissingVaultConfig(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), ConfigFileName)
+
+	_, err := Resolve(DefaultResolverOpts().WithUserConfigPath("").WithVaultConfigPath(missing).WithLookupEnv(envMap(nil)))
+	if err == nil || !strings.Contains(err.Error(), "config file not found: "+missing) {
+		t.Errorf("expected an explicit config path that does not exist to fail, got %v", err)
+	}
+
+	// Commands that create the config, such as config set, resolve without it
+	resolved, err := Resolve(DefaultResolverOpts().WithUserConfigPath("").WithVaultConfigPath(missing).WithCreateVaultConfig(true).WithLookupEnv(envMap(nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved.VaultConfigPath != "" {
+		t.Errorf("expected no vault config to be applied, got %s", resolved.VaultConfigPath)
+	}
+}
+
+func TestResolveGitHubToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		flag     string
+		env      map[string]string
+		expected string
+	}{
+		{name: "flag wins", flag: "flag-token", env: map[string]string{"GITHUB_TOKEN": "env-token"}, expected: "flag-token"},
+		{name: "dragonglass variable before github", env: map[string]string{"DRAGONGLASS_GITHUB_TOKEN": "dg", "GITHUB_TOKEN": "gh"}, expected: "dg"},
+		{name: "GITHUB_TOKEN before GH_TOKEN", env: map[string]string{"GITHUB_TOKEN": "gh", "GH_TOKEN": "cli"}, expected: "gh"},
+		{name: "GH_TOKEN fallback", env: map[string]string{"GH_TOKEN": "cli"}, expected: "cli"},
+		{name: "none", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveGitHubToken(tt.flag, envMap(tt.env)); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestResolveCredentialStoreNone(t *testing.T) {
+	env := envMap(map[string]string{"DRAGONGLASS_CREDENTIAL_STORE": "none", "GITHUB_TOKEN": "ambient"})
+
+	resolved, err := Resolve(DefaultResolverOpts().WithUserConfigPath("").WithVaultConfigPath("").WithWorkingDir(t.TempDir()).WithLookupEnv(env))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved.GitHubToken != "" {
+		t.Errorf("expected the none store to ignore GITHUB_TOKEN, got %q", resolved.GitHubToken)
+	}
+
+	resolved, err = Resolve(DefaultResolverOpts().WithUserConfigPath("").WithVaultConfigPath("").WithWorkingDir(t.TempDir()).WithLookupEnv(env).WithGitHubToken("explicit"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved.GitHubToken != "explicit" {
+		t.Errorf("expected --github-token to apply, got %q", resolved.GitHubToken)
+	}
+}
+
+func TestResolveHooks(t *testing.T) {
+	tempDir := t.TempDir()
+	userPath := filepath.Join(tempDir, "user", UserConfigFileName)
+	vaultPath := filepath.Join(tempDir, "vault", ConfigFileName)
+
+	writeConfigFile(t, userPath, `{
+		"version": "1",
+		"hooks": {"pre_install": ["user-gate"], "post_install": ["notify-user"]}
+	}`)
+	writeConfigFile(t, vaultPath, `{
+		"version": "1",
+		"hooks": {"post_install": ["notify-vault", "log-install"]}
+	}`)
+
+	opts := DefaultResolverOpts().
+		WithUserConfigPath(userPath).
+		WithVaultConfigPath(vaultPath).
+		WithLookupEnv(envMap(nil))
+	resolved, err := Resolve(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hooks := resolved.Config.Hooks
+	if got := strings.Join(hooks.Commands("pre_install"), ","); got != "user-gate" {
+		t.Errorf("expected user pre_install hook, got %q", got)
+	}
+	if got := strings.Join(hooks.Commands("post_install"), ","); got != "notify-vault,log-install" {
+		t.Errorf("expected vault post_install hooks to replace the user's, got %q", got)
+	}
+	if resolved.Origin("hooks.post_install") != SourceVault {
+		t.Errorf("expected post_install origin vault, got %s", resolved.Origin("hooks.post_install"))
+	}
+
+	resolved, err = Resolve(opts.WithLookupEnv(envMap(map[string]string{"DRAGONGLASS_NO_HOOKS": "true"})))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if commands := resolved.Config.Hooks.Commands("post_install"); commands != nil {
+		t.Errorf("expected disabled hooks to return no commands, got %v", commands)
+	}
+}
@@ -0,0 +1,112 @@
+// ABOUTME: Duration settings for per-operation timeouts and errors naming the knob to raise
+// ABOUTME: Durations are stored in config files as Go duration strings such as "30s" or "5m"
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultRegistryTimeout     = 30 * time.Second
+	DefaultVerificationTimeout = 30 * time.Second
+	DefaultInstallTimeout      = 5 * time.Minute
+
+	// A fetched catalog is used this long before the reference is resolved again
+	DefaultCatalogTTL = 6 * time.Hour
+)
+
+// Duration is a positive time.Duration serialized as a duration string, e.g. "90s"
+type Duration time.Duration
+
+// ParseDuration parses a duration string such as "30s", "2m" or "1h30m"
+func ParseDuration(value string) (Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q (expected a value like 30s, 2m or 1h30m)", value)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q (must be greater than zero)", value)
+	}
+	return Duration(d), nil
+}
+
+// Duration returns d as a time.Duration
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("expected a duration string, got %s", data)
+	}
+	parsed, err := ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// TimeoutError reports an operation that ran out of time, naming the setting that bounds it
+type TimeoutError struct {
+	// Setting key whose value was exceeded, e.g. "install.timeout"
+	Key     string
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *TimeoutError) Error() string {
+	hint := e.Key
+	if s, ok := LookupSetting(e.Key); ok {
+		hint = fmt.Sprintf("%s or %s", s.Key, s.EnvVar)
+	}
+	return fmt.Sprintf("%v: timed out after %s (raise %s)", e.Err, e.Timeout, hint)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// WrapTimeout attributes a deadline failure to the setting that caused it. When ctx, bounded
+// by the setting key, has expired the error names key; a deadline hit while ctx is still live
+// came from a single registry request and names registry.timeout. Other errors, and errors
+// already attributed, are returned unchanged.
+func (c *Config) WrapTimeout(ctx context.Context, key string, err error) error {
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return err
+	}
+
+	if ctx.Err() == nil {
+		key = "registry.timeout"
+	}
+
+	s, ok := LookupSetting(key)
+	if !ok {
+		return err
+	}
+
+	timeout, _ := s.field(c).(*Duration)
+	if timeout == nil {
+		return err
+	}
+
+	return &TimeoutError{Key: key, Timeout: timeout.Duration(), Err: err}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		errorMsg string
+	}{
+		{value: "30s", expected: 30 * time.Second},
+		{value: "1h30m", expected: 90 * time.Minute},
+		{value: "soon", errorMsg: "expected a value like 30s"},
+		{value: "0s", errorMsg: "must be greater than zero"},
+		{value: "-5m", errorMsg: "must be greater than zero"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			d, err := ParseDuration(tt.value)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d.Duration() != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, d)
+			}
+		})
+	}
+}
+
+func TestResolveTimeouts(t *testing.T) {
+	vaultPath := filepath.Join(t.TempDir(), ConfigFileName)
+	writeConfigFile(t, vaultPath, `{"version": "1", "registry": {"timeout": "45s"}, "install": {"timeout": "10m"}}`)
+
+	opts := DefaultResolverOpts().
+		WithUserConfigPath("").
+		WithVaultConfigPath(vaultPath).
+		WithLookupEnv(envMap(map[string]string{"DRAGONGLASS_INSTALL_TIMEOUT": "15m"})).
+		WithFlag("verification.timeout", "2m")
+
+	resolved, err := Resolve(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg := resolved.Config
+	if cfg.Registry.Timeout.Duration() != 45*time.Second {
+		t.Errorf("expected registry timeout from vault config, got %s", cfg.Registry.Timeout)
+	}
+	if cfg.Install.Timeout.Duration() != 15*time.Minute {
+		t.Errorf("expected install timeout from environment, got %s", cfg.Install.Timeout)
+	}
+	if cfg.Verification.Timeout.Duration() != 2*time.Minute {
+		t.Errorf("expected verification timeout from flag, got %s", cfg.Verification.Timeout)
+	}
+
+	writeConfigFile(t, vaultPath, `{"version": "1", "install": {"timeout": "forever"}}`)
+	if _, err := Resolve(opts); err == nil || !strings.Contains(err.Error(), "install.timeout") {
+		t.Errorf("expected invalid install.timeout error, got %v", err)
+	}
+}
+
+func TestWrapTimeout(t *testing.T) {
+	cfg := DefaultConfig()
+
+	expired, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-expired.Done()
+
+	deadlineErr := fmt.Errorf("failed to fetch manifest: %w", context.DeadlineExceeded)
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		err      error
+		expected string
+	}{
+		{name: "operation deadline", ctx: expired, err: deadlineErr, expected: "raise install.timeout or DRAGONGLASS_INSTALL_TIMEOUT"},
+		{name: "request deadline", ctx: context.Background(), err: deadlineErr, expected: "raise registry.timeout or DRAGONGLASS_REGISTRY_TIMEOUT"},
+		{name: "other errors unchanged", ctx: expired, err: errors.New("boom"), expected: "boom"},
+		{
+			name:     "already attributed",
+			ctx:      expired,
+			err:      fmt.Errorf("outer: %w", &TimeoutError{Key: "verification.timeout", Timeout: time.Second, Err: context.DeadlineExceeded}),
+			expected: "raise verification.timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cfg.WrapTimeout(tt.ctx, "install.timeout", tt.err)
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing %q, got %v", tt.expected, err)
+			}
+			if errors.Is(tt.err, context.DeadlineExceeded) && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected wrapped error to still match context.DeadlineExceeded")
+			}
+		})
+	}
+
+	if cfg.WrapTimeout(expired, "install.timeout", nil) != nil {
+		t.Errorf("expected nil error to stay nil")
+	}
+}
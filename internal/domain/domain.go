@@ -3,10 +3,13 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	v1 "github.com/in-toto/attestation/go/predicates/provenance/v1"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
 )
 
 // Plugin represents the complete metadata for an Obsidian plugin
@@ -20,52 +23,38 @@ type Plugin struct {
 	Author        string `json:"author,omitempty"`
 	AuthorURL     string `json:"authorUrl,omitempty"`
 	IsDesktopOnly bool   `json:"isDesktopOnly,omitempty"`
-}
 
-// Lockfile represents the plugin lockfile structure
-type Lockfile struct {
-	SchemaVersion string                       `json:"schemaVersion"`
-	VaultName     string                       `json:"vaultName"`
-	VaultPath     string                       `json:"vaultPath"`
-	Plugins       map[string]PluginEntry       `json:"plugins"`
-	Metadata      LockfileMetadata             `json:"metadata"`
-	Verification  map[string]VerificationState `json:"verification"`
+	// Kind of artifact: plugin, theme, or snippet (empty for plugins)
+	Kind string `json:"kind,omitempty"`
 }
 
-// PluginEntry represents a single plugin entry in the lockfile
-type PluginEntry struct {
-	Version     string         `json:"version"`
-	Registry    string         `json:"registry"`
-	Resolved    string         `json:"resolved"`
-	Integrity   string         `json:"integrity"`
-	Metadata    PluginMetadata `json:"metadata"`
-	InstallTime time.Time      `json:"installTime"`
-}
+// The lockfile model is defined once, in internal/lockfile; these aliases keep older
+// callers of the domain package compiling.
 
-// VerificationState tracks the verification status of a plugin
-type VerificationState struct {
-	Verified         bool      `json:"verified"`
-	AttestationValid bool      `json:"attestationValid"`
-	SBOMValid        bool      `json:"sbomValid"`
-	LastVerified     time.Time `json:"lastVerified"`
-	Errors           []string  `json:"errors,omitempty"`
-}
+// Lockfile is the vault lockfile.
+//
+// Deprecated: use lockfile.Lockfile.
+type Lockfile = lockfile.Lockfile
 
-// PluginMetadata contains resolved plugin metadata from the registry
-type PluginMetadata struct {
-	Name          string `json:"name"`
-	Version       string `json:"version"`
-	Author        string `json:"author"`
-	Description   string `json:"description"`
-	MinAppVersion string `json:"minAppVersion,omitempty"`
-}
+// PluginEntry is a plugin pinned in the lockfile.
+//
+// Deprecated: use lockfile.PluginEntry.
+type PluginEntry = lockfile.PluginEntry
 
-// LockfileMetadata contains metadata about the lockfile itself
-type LockfileMetadata struct {
-	CreatedAt   time.Time `json:"createdAt"`
-	LastUpdated time.Time `json:"lastUpdated"`
-	Version     string    `json:"version"`
-}
+// VerificationState is the recorded verification outcome of a pinned plugin.
+//
+// Deprecated: use lockfile.VerificationState.
+type VerificationState = lockfile.VerificationState
+
+// PluginMetadata is the registry metadata recorded for a pinned plugin.
+//
+// Deprecated: use lockfile.PluginMetadata.
+type PluginMetadata = lockfile.PluginMetadata
+
+// LockfileMetadata describes the lockfile itself.
+//
+// Deprecated: use lockfile.LockfileMetadata.
+type LockfileMetadata = lockfile.LockfileMetadata
 
 // VerificationResult contains comprehensive verification results for all attestation types
 type VerificationResult struct {
@@ -104,6 +93,46 @@ type Vulnerability struct {
 	Summary  string `json:"summary"`
 }
 
+// VerificationState converts the result into the verification state recorded in the
+// lockfile for a plugin verified at verifiedAt
+func (r *VerificationResult) VerificationState(verifiedAt time.Time) lockfile.VerificationState {
+	state := lockfile.VerificationState{
+		ProvenanceVerified: r.Found && r.Valid,
+		VerifiedAt:         &verifiedAt,
+		Warnings:           r.Warnings,
+		Errors:             r.Errors,
+	}
+
+	if r.SLSA != nil {
+		state.BuilderID = r.SLSA.BuilderID
+		state.SLSALevel = r.SLSA.Level
+	}
+
+	if r.SBOM != nil {
+		state.SBOMVerified = r.Found && r.Valid
+
+		counts := &lockfile.VulnerabilityCounts{}
+		for _, vuln := range r.SBOM.Vulnerabilities {
+			switch strings.ToUpper(vuln.Severity) {
+			case "CRITICAL":
+				counts.Critical++
+			case "HIGH":
+				counts.High++
+			case "MEDIUM":
+				counts.Medium++
+			case "LOW":
+				counts.Low++
+			default:
+				counts.Unknown++
+			}
+		}
+		state.Vulnerabilities = counts
+		state.VulnScanPassed = state.SBOMVerified && counts.Critical == 0 && counts.High == 0
+	}
+
+	return state
+}
+
 // AttestationData contains the raw attestation data and metadata
 type AttestationData struct {
 	Attestations []ocispec.Descriptor `json:"attestations"`
@@ -129,6 +158,7 @@ type Credential struct {
 	Username  string    `json:"username,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	Source    string    `json:"source"`
+	Store     string    `json:"store"`
 	Host      string    `json:"host"`
 }
 
@@ -177,6 +207,10 @@ type AuthService interface {
 	// Authenticate starts the authentication flow
 	Authenticate() error
 
+	// RequestScopes signs in again asking for scopes in addition to those already
+	// granted; the stored credential is kept until the new one is issued
+	RequestScopes(scopes []string) error
+
 	// IsAuthenticated checks if user has valid credentials
 	IsAuthenticated() bool
 
@@ -214,19 +248,19 @@ type AttestationService interface {
 // LockfileService handles lockfile operations
 type LockfileService interface {
 	// Load reads the lockfile from the vault directory
-	Load() (*Lockfile, error)
+	Load() (*lockfile.Lockfile, error)
 
 	// Save writes the lockfile to the vault directory
-	Save(lockfile *Lockfile) error
+	Save(data *lockfile.Lockfile) error
 
 	// AddPlugin adds a plugin entry to the lockfile
-	AddPlugin(id string, entry PluginEntry) error
+	AddPlugin(id string, entry lockfile.PluginEntry) error
 
 	// RemovePlugin removes a plugin from the lockfile
 	RemovePlugin(id string) error
 
 	// UpdateVerification updates verification status for a plugin
-	UpdateVerification(id string, verification VerificationState) error
+	UpdateVerification(id string, verification lockfile.VerificationState) error
 }
 
 // ConfigService handles application configuration
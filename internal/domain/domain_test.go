@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerificationResultState(t *testing.T) {
+	verifiedAt := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		result           VerificationResult
+		expectProvenance bool
+		expectSBOM       bool
+		expectVulnScan   bool
+		expectCritical   int
+	}{
+		{
+			name:   "not found",
+			result: VerificationResult{},
+		},
+		{
+			name: "verified without vulnerabilities",
+			result: VerificationResult{
+				Found: true, Valid: true,
+				SLSA: &SLSAResult{Level: 3, BuilderID: "https://github.com/actions/runner"},
+				SBOM: &SBOMResult{DocumentName: "test-sbom"},
+			},
+			expectProvenance: true,
+			expectSBOM:       true,
+			expectVulnScan:   true,
+		},
+		{
+			name: "critical vulnerability",
+			result: VerificationResult{
+				Found: true, Valid: true,
+				SBOM: &SBOMResult{Vulnerabilities: []Vulnerability{{ID: "CVE-1", Severity: "critical"}, {ID: "CVE-2", Severity: "LOW"}}},
+			},
+			expectProvenance: true,
+			expectSBOM:       true,
+			expectCritical:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := tt.result.VerificationState(verifiedAt)
+			if state.ProvenanceVerified != tt.expectProvenance || state.SBOMVerified != tt.expectSBOM || state.VulnScanPassed != tt.expectVulnScan {
+				t.Errorf("expected provenance=%v sbom=%v vulnScan=%v, got %+v", tt.expectProvenance, tt.expectSBOM, tt.expectVulnScan, state)
+			}
+			if state.VerifiedAt == nil || !state.VerifiedAt.Equal(verifiedAt) {
+				t.Errorf("expected verified at %v, got %v", verifiedAt, state.VerifiedAt)
+			}
+			if tt.result.SLSA != nil && (state.BuilderID != tt.result.SLSA.BuilderID || state.SLSALevel != tt.result.SLSA.Level) {
+				t.Errorf("expected builder and SLSA level from the result, got %+v", state)
+			}
+			if tt.result.SBOM != nil && state.Vulnerabilities.Critical != tt.expectCritical {
+				t.Errorf("expected %d critical vulnerabilities, got %+v", tt.expectCritical, state.Vulnerabilities)
+			}
+		})
+	}
+}
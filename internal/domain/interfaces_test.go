@@ -12,6 +12,7 @@ func TestDomainInterfaces(t *testing.T) {
 	t.Run("AuthService", func(t *testing.T) {
 		t.Log("AuthService interface provides authentication methods:")
 		t.Log("  - Authenticate() error")
+		t.Log("  - RequestScopes(scopes []string) error")
 		t.Log("  - IsAuthenticated() bool")
 		t.Log("  - GetToken() (string, error)")
 		t.Log("  - GetUser() (string, error)")
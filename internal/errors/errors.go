@@ -0,0 +1,217 @@
+// ABOUTME: Error taxonomy shared by dragonglass packages and commands
+// ABOUTME: Sentinel and typed errors map failures to hints and process exit codes
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors. Packages wrap or Mark these so commands can match them with errors.Is
+// regardless of how much context was added along the way.
+var (
+	ErrNotAuthenticated    = errors.New("not authenticated")
+	ErrInsufficientScope   = errors.New("token lacks required scopes")
+	ErrAttestationNotFound = errors.New("attestations not found")
+	ErrAttestationInvalid  = errors.New("attestation verification failed")
+	ErrUntrustedBuilder    = errors.New("untrusted builder")
+	ErrDigestMismatch      = errors.New("digest mismatch")
+	ErrVersionMismatch     = errors.New("version mismatch")
+	ErrTagMoved            = errors.New("tag moved")
+	ErrVulnBlocked         = errors.New("blocked by vulnerability policy")
+	ErrRiskBlocked         = errors.New("blocked by code analysis policy")
+	ErrRegistryUnavailable = errors.New("registry unavailable")
+	ErrNotInLockfile       = errors.New("plugin not found in lockfile")
+	ErrHookRejected        = errors.New("rejected by pre_install hook")
+	ErrVaultFrozen         = errors.New("vault is frozen")
+	ErrDowngrade           = errors.New("downgrade refused")
+	ErrLockfileSignature   = errors.New("lockfile signature verification failed")
+	ErrWarnings            = errors.New("verification produced warnings")
+	ErrDeclined            = errors.New("declined at confirmation prompt")
+	ErrConfirmationNeeded  = errors.New("confirmation needed")
+
+	// Verification could not complete because Sigstore, TUF, or attestation endpoints kept
+	// failing; unlike the errors above it says nothing about whether the artifact is trusted
+	ErrTransientVerification = errors.New("verification infrastructure temporarily unavailable")
+)
+
+// Exit codes form the contract scripts and CI can rely on
+const (
+	ExitOK                  = 0
+	ExitFailure             = 1
+	ExitNotAuthenticated    = 3
+	ExitVerificationFailed  = 4
+	ExitVulnBlocked         = 5
+	ExitRegistryUnavailable = 6
+	ExitWarnings            = 7
+	ExitInterrupted         = 130
+)
+
+// DigestMismatchError reports content whose digest differs from the expected one
+type DigestMismatchError struct {
+	// What was checked, e.g. "manifest" or "attestation subject"
+	Subject  string
+	Expected string
+	Actual   string
+}
+
+func (e *DigestMismatchError) Error() string {
+	if e.Subject == "" {
+		return fmt.Sprintf("digest mismatch: expected %s, got %s", e.Expected, e.Actual)
+	}
+	return fmt.Sprintf("%s digest mismatch: expected %s, got %s", e.Subject, e.Expected, e.Actual)
+}
+
+func (e *DigestMismatchError) Is(target error) bool {
+	return target == ErrDigestMismatch
+}
+
+// TagMovedError reports a locked tag that now resolves to a different manifest, meaning
+// it was re-pushed after the lockfile was written. It is also a digest mismatch.
+type TagMovedError struct {
+	// Reference that was resolved, e.g. "ghcr.io/owner/repo/plugin:v1.2.3"
+	Reference string
+	Tag       string
+	Expected  string
+	Actual    string
+}
+
+func (e *TagMovedError) Error() string {
+	return fmt.Sprintf("tag %s has been re-pushed: %s now resolves to %s, lockfile records %s", e.Tag, e.Reference, e.Actual, e.Expected)
+}
+
+func (e *TagMovedError) Is(target error) bool {
+	return target == ErrTagMoved || target == ErrDigestMismatch
+}
+
+// UntrustedBuilderError reports provenance signed by a builder other than the trusted one
+type UntrustedBuilderError struct {
+	Builder        string
+	TrustedBuilder string
+}
+
+func (e *UntrustedBuilderError) Error() string {
+	return fmt.Sprintf("untrusted builder %s (expected %s)", e.Builder, e.TrustedBuilder)
+}
+
+func (e *UntrustedBuilderError) Is(target error) bool {
+	return target == ErrUntrustedBuilder
+}
+
+// InsufficientScopeError reports a stored GitHub token that was not granted the OAuth
+// scopes a command needs, e.g. write:packages for publishing
+type InsufficientScopeError struct {
+	Missing []string
+	Granted string
+}
+
+func (e *InsufficientScopeError) Error() string {
+	granted := e.Granted
+	if granted == "" {
+		granted = "none"
+	}
+	return fmt.Sprintf("token lacks required scopes %s (granted: %s)", strings.Join(e.Missing, ", "), granted)
+}
+
+func (e *InsufficientScopeError) Is(target error) bool {
+	return target == ErrInsufficientScope
+}
+
+// Mark attaches sentinel to err without changing its message, so errors.Is(err, sentinel)
+// holds while the original cause stays reachable through errors.Unwrap and errors.As
+func Mark(err, sentinel error) error {
+	if err == nil {
+		return nil
+	}
+	return &marked{err: err, sentinel: sentinel}
+}
+
+type marked struct {
+	err      error
+	sentinel error
+}
+
+func (m *marked) Error() string {
+	return m.err.Error()
+}
+
+func (m *marked) Unwrap() error {
+	return m.err
+}
+
+func (m *marked) Is(target error) bool {
+	return target == m.sentinel
+}
+
+// ExitCode maps err to the process exit code for the failure it represents
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	case errors.Is(err, ErrNotAuthenticated), errors.Is(err, ErrInsufficientScope):
+		return ExitNotAuthenticated
+	case errors.Is(err, ErrVulnBlocked):
+		return ExitVulnBlocked
+	case errors.Is(err, ErrTransientVerification):
+		return ExitRegistryUnavailable
+	case errors.Is(err, ErrAttestationNotFound), errors.Is(err, ErrAttestationInvalid),
+		errors.Is(err, ErrUntrustedBuilder), errors.Is(err, ErrDigestMismatch), errors.Is(err, ErrVersionMismatch),
+		errors.Is(err, ErrRiskBlocked), errors.Is(err, ErrLockfileSignature):
+		return ExitVerificationFailed
+	case errors.Is(err, ErrRegistryUnavailable):
+		return ExitRegistryUnavailable
+	case errors.Is(err, ErrWarnings):
+		return ExitWarnings
+	default:
+		return ExitFailure
+	}
+}
+
+// Hint returns a suggestion for resolving err, or "" when there is nothing specific to say
+func Hint(err error) string {
+	var scopeErr *InsufficientScopeError
+	switch {
+	case errors.As(err, &scopeErr):
+		return fmt.Sprintf("run 'dragonglass auth --scope %s' to add the missing scopes; your current sign-in is kept until the new one succeeds", strings.Join(scopeErr.Missing, ","))
+	case errors.Is(err, ErrNotAuthenticated):
+		return "run 'dragonglass auth' or set GITHUB_TOKEN"
+	case errors.Is(err, ErrUntrustedBuilder):
+		return "check verification.trusted_builder matches the workflow that built the plugin"
+	case errors.Is(err, ErrAttestationNotFound):
+		return "the plugin was not published with provenance attestations"
+	case errors.Is(err, ErrTagMoved):
+		return "the tag was re-pushed; review the new artifact, then run install or sync with --accept-retag to adopt it"
+	case errors.Is(err, ErrDigestMismatch):
+		return "the artifact changed since it was locked; re-add it if the change is expected"
+	case errors.Is(err, ErrVersionMismatch):
+		return "the reference serves a different release than expected; check which tag was pushed"
+	case errors.Is(err, ErrVulnBlocked):
+		return "set verification.allow_high_severity to install anyway"
+	case errors.Is(err, ErrRiskBlocked):
+		return "review the reported findings, then raise or turn off verification.block_risk to accept them"
+	case errors.Is(err, ErrTransientVerification):
+		return "Sigstore or the attestation API failed repeatedly; check https://status.sigstore.dev and network connectivity, then retry"
+	case errors.Is(err, ErrRegistryUnavailable):
+		return "check network connectivity and registry status, then retry"
+	case errors.Is(err, ErrNotInLockfile):
+		return "run 'dragonglass list' to see locked plugins"
+	case errors.Is(err, ErrHookRejected):
+		return "see the hook output above; hooks are configured under hooks in the vault or user config, and --no-hooks skips them"
+	case errors.Is(err, ErrVaultFrozen):
+		return "run 'dragonglass freeze off' after review, or pass --unfreeze-once for a single change"
+	case errors.Is(err, ErrDowngrade):
+		return "rolling a plugin back can reintroduce fixed vulnerabilities; pass --allow-downgrade if it is intended"
+	case errors.Is(err, ErrWarnings):
+		return "review the warnings, or pass --ignore-warning <category> for categories you accept"
+	case errors.Is(err, ErrConfirmationNeeded):
+		return "run it in a terminal to confirm, or pass --yes to confirm without asking"
+	case errors.Is(err, ErrLockfileSignature):
+		return "have a vault admin review the lockfile and run 'dragonglass lock sign', and check verification.lockfile_key or verification.lockfile_signer"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,99 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "nil", err: nil, expected: ExitOK},
+		{name: "generic", err: errors.New("boom"), expected: ExitFailure},
+		{name: "cancelled", err: fmt.Errorf("install: %w", context.Canceled), expected: ExitInterrupted},
+		{name: "not authenticated", err: fmt.Errorf("failed to get token: %w", Mark(errors.New("no stored credentials"), ErrNotAuthenticated)), expected: ExitNotAuthenticated},
+		{name: "insufficient scope", err: fmt.Errorf("push: %w", &InsufficientScopeError{Missing: []string{"write:packages"}, Granted: "read:packages"}), expected: ExitNotAuthenticated},
+		{name: "attestation not found", err: fmt.Errorf("%w (required in strict mode)", ErrAttestationNotFound), expected: ExitVerificationFailed},
+		{name: "untrusted builder", err: &UntrustedBuilderError{Builder: "a", TrustedBuilder: "b"}, expected: ExitVerificationFailed},
+		{name: "digest mismatch", err: fmt.Errorf("install: %w", &DigestMismatchError{Expected: "sha256:a", Actual: "sha256:b"}), expected: ExitVerificationFailed},
+		{name: "version mismatch", err: fmt.Errorf("%w: expected 1.0.0, got 1.0.1", ErrVersionMismatch), expected: ExitVerificationFailed},
+		{name: "vulnerabilities", err: fmt.Errorf("%w: 2 high/critical vulnerabilities", ErrVulnBlocked), expected: ExitVulnBlocked},
+		{name: "code analysis", err: fmt.Errorf("%w: 1 finding at or above high", ErrRiskBlocked), expected: ExitVerificationFailed},
+		{name: "registry", err: Mark(errors.New("connection refused"), ErrRegistryUnavailable), expected: ExitRegistryUnavailable},
+		{name: "transient verification", err: fmt.Errorf("%w: attestation API returned 503", ErrTransientVerification), expected: ExitRegistryUnavailable},
+		{name: "hook rejected", err: Mark(errors.New(`pre_install hook "./gate" failed: exit status 1`), ErrHookRejected), expected: ExitFailure},
+		{name: "vault frozen", err: fmt.Errorf("%w since 2026-01-02T03:04:05Z", ErrVaultFrozen), expected: ExitFailure},
+		{name: "downgrade", err: fmt.Errorf("%w: version 1.0.0 is lower than the installed 1.1.0", ErrDowngrade), expected: ExitFailure},
+		{name: "lockfile signature", err: fmt.Errorf("%w: lockfile is not signed", ErrLockfileSignature), expected: ExitVerificationFailed},
+		{name: "warnings as errors", err: fmt.Errorf("%w: 1 warning(s)", ErrWarnings), expected: ExitWarnings},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.expected {
+				t.Errorf("expected exit code %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMark(t *testing.T) {
+	cause := errors.New("401 unauthorized")
+	err := fmt.Errorf("failed to fetch manifest: %w", Mark(cause, ErrNotAuthenticated))
+
+	if err.Error() != "failed to fetch manifest: 401 unauthorized" {
+		t.Errorf("expected message to be unchanged, got %q", err.Error())
+	}
+	if !errors.Is(err, ErrNotAuthenticated) {
+		t.Errorf("expected sentinel to match")
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("expected cause to remain reachable")
+	}
+	if errors.Is(err, ErrRegistryUnavailable) {
+		t.Errorf("expected unrelated sentinel not to match")
+	}
+	if Mark(nil, ErrNotAuthenticated) != nil {
+		t.Errorf("expected Mark(nil) to return nil")
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	var mismatch *DigestMismatchError
+	err := fmt.Errorf("verify: %w", &DigestMismatchError{Subject: "manifest", Expected: "sha256:a", Actual: "sha256:b"})
+	if !errors.As(err, &mismatch) || mismatch.Actual != "sha256:b" {
+		t.Errorf("expected DigestMismatchError to be extractable, got %v", err)
+	}
+	if err.Error() != "verify: manifest digest mismatch: expected sha256:a, got sha256:b" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+
+	moved := fmt.Errorf("install: %w", &TagMovedError{Reference: "ghcr.io/o/r/p:v1", Tag: "v1", Expected: "sha256:a", Actual: "sha256:b"})
+	if !errors.Is(moved, ErrTagMoved) || !errors.Is(moved, ErrDigestMismatch) {
+		t.Errorf("expected TagMovedError to match ErrTagMoved and ErrDigestMismatch")
+	}
+	if ExitCode(moved) != ExitCode(ErrDigestMismatch) {
+		t.Errorf("expected tag moved to share the digest mismatch exit code, got %d", ExitCode(moved))
+	}
+	if !strings.Contains(Hint(moved), "--accept-retag") {
+		t.Errorf("expected hint to mention --accept-retag, got %q", Hint(moved))
+	}
+
+	scope := fmt.Errorf("push: %w", &InsufficientScopeError{Missing: []string{"write:packages"}, Granted: "read:packages"})
+	if !errors.Is(scope, ErrInsufficientScope) || !strings.Contains(Hint(scope), "dragonglass auth --scope write:packages") {
+		t.Errorf("expected scope error to match ErrInsufficientScope with an auth hint, got %q", Hint(scope))
+	}
+	if scope.Error() != "push: token lacks required scopes write:packages (granted: read:packages)" {
+		t.Errorf("unexpected message %q", scope.Error())
+	}
+
+	if Hint(&UntrustedBuilderError{}) == "" {
+		t.Errorf("expected a hint for untrusted builder")
+	}
+}
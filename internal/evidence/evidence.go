@@ -0,0 +1,211 @@
+// ABOUTME: Archive of the raw attestations a plugin was verified with, for security audits
+// ABOUTME: Writes Sigstore bundles, DSSE envelopes, and SPDX documents next to a summary.json
+package evidence
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/intoto"
+)
+
+const (
+	SummaryFileName = "summary.json"
+
+	// Extensions of in-toto statements and SPDX documents extracted from attestations
+	StatementExt = ".statement.json"
+	SPDXExt      = ".spdx.json"
+)
+
+// Kinds of attestation, by predicate type
+const (
+	KindProvenance = "provenance"
+	KindSBOM       = "sbom"
+	KindOther      = "attestation"
+)
+
+// Formats of archived files
+const (
+	FormatBundle    = "sigstore-bundle"
+	FormatEnvelope  = "dsse-envelope"
+	FormatStatement = "in-toto-statement"
+	FormatSPDX      = "spdx"
+	FormatRaw       = "raw"
+)
+
+// Summary describes the archived evidence and the verification outcome at download time
+type Summary struct {
+	Ref            string    `json:"ref"`
+	ArtifactDigest string    `json:"artifact_digest"`
+	DownloadedAt   time.Time `json:"downloaded_at"`
+
+	// Whether the attestations established trust under the policy in effect
+	Verified         bool   `json:"verified"`
+	TrustedBuilder   string `json:"trusted_builder,omitempty"`
+	Builder          string `json:"builder,omitempty"`
+	SourceRepository string `json:"source_repository,omitempty"`
+	Workflow         string `json:"workflow,omitempty"`
+
+	SBOMComponents  int `json:"sbom_components,omitempty"`
+	Vulnerabilities int `json:"vulnerabilities,omitempty"`
+
+	Errors   []string `json:"errors,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Archived files, filled in by Write
+	Files []File `json:"files"`
+}
+
+// File is one archived file, named relative to the archive directory
+type File struct {
+	Name          string `json:"name"`
+	Kind          string `json:"kind"`
+	Format        string `json:"format"`
+	PredicateType string `json:"predicate_type,omitempty"`
+	SHA256        string `json:"sha256"`
+}
+
+// Write archives blobs, the attestations as fetched from the registry or GitHub, into dir
+// and then writes summary with the list of files. Each attestation is kept byte for byte;
+// the DSSE envelope of a Sigstore bundle and the SPDX document of an SBOM statement are
+// also extracted so they can be read without Sigstore tooling.
+func Write(dir string, blobs [][]byte, summary *Summary) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	summary.Files = []File{}
+	for i, blob := range blobs {
+		files, err := archive(dir, i+1, blob)
+		if err != nil {
+			return err
+		}
+		summary.Files = append(summary.Files, files...)
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode summary: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, SummaryFileName), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+	return nil
+}
+
+// archive writes the attestation blob, numbered n, and whatever can be extracted from it
+func archive(dir string, n int, blob []byte) ([]File, error) {
+	var fields struct {
+		MediaType    string          `json:"mediaType"`
+		DSSEEnvelope json.RawMessage `json:"dsseEnvelope"`
+		PayloadType  string          `json:"payloadType"`
+		Payload      string          `json:"payload"`
+	}
+	if err := json.Unmarshal(blob, &fields); err != nil {
+		return writeFiles(dir, n, KindOther, "", []output{{ext: ".bin", format: FormatRaw, data: blob}})
+	}
+
+	switch {
+	case fields.MediaType != "":
+		outputs := []output{{ext: intoto.BundleExt, format: FormatBundle, data: blob}}
+		if len(fields.DSSEEnvelope) == 0 {
+			// Bundles signing a message digest carry no statement to extract
+			return writeFiles(dir, n, KindOther, "", outputs)
+		}
+		outputs = append(outputs, output{ext: intoto.EnvelopeExt, format: FormatEnvelope, data: indent(fields.DSSEEnvelope)})
+		return archiveEnvelope(dir, n, fields.DSSEEnvelope, outputs)
+	case fields.PayloadType != "":
+		outputs := []output{{ext: intoto.EnvelopeExt, format: FormatEnvelope, data: blob}}
+		return archiveEnvelope(dir, n, blob, outputs)
+	default:
+		outputs := []output{{ext: StatementExt, format: FormatStatement, data: blob}}
+		return archiveStatement(dir, n, blob, outputs)
+	}
+}
+
+// archiveEnvelope decodes the statement in a DSSE envelope and writes it with outputs
+func archiveEnvelope(dir string, n int, envelope []byte, outputs []output) ([]File, error) {
+	var env intoto.Envelope
+	if err := json.Unmarshal(envelope, &env); err != nil {
+		return writeFiles(dir, n, KindOther, "", outputs)
+	}
+	statement, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return writeFiles(dir, n, KindOther, "", outputs)
+	}
+	return archiveStatement(dir, n, statement, outputs)
+}
+
+// archiveStatement classifies an in-toto statement by its predicate and writes outputs,
+// adding the SPDX document of SBOM statements
+func archiveStatement(dir string, n int, statement []byte, outputs []output) ([]File, error) {
+	var fields struct {
+		PredicateType string          `json:"predicateType"`
+		Predicate     json.RawMessage `json:"predicate"`
+	}
+	if err := json.Unmarshal(statement, &fields); err != nil {
+		return writeFiles(dir, n, KindOther, "", outputs)
+	}
+
+	kind := Kind(fields.PredicateType)
+	if kind == KindSBOM && len(fields.Predicate) > 0 {
+		outputs = append(outputs, output{ext: SPDXExt, format: FormatSPDX, data: indent(fields.Predicate)})
+	}
+	return writeFiles(dir, n, kind, fields.PredicateType, outputs)
+}
+
+// Kind returns the kind of attestation a predicate type denotes
+func Kind(predicateType string) string {
+	switch {
+	case strings.HasPrefix(predicateType, "https://slsa.dev/provenance/"):
+		return KindProvenance
+	case strings.HasPrefix(predicateType, "https://spdx.dev/Document"):
+		return KindSBOM
+	default:
+		return KindOther
+	}
+}
+
+type output struct {
+	ext    string
+	format string
+	data   []byte
+}
+
+// writeFiles writes outputs as <n>-<kind><ext>, e.g. 01-provenance.sigstore.json
+func writeFiles(dir string, n int, kind, predicateType string, outputs []output) ([]File, error) {
+	files := make([]File, 0, len(outputs))
+	for _, out := range outputs {
+		name := fmt.Sprintf("%02d-%s%s", n, kind, out.ext)
+		if err := os.WriteFile(filepath.Join(dir, name), out.data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+
+		sum := sha256.Sum256(out.data)
+		files = append(files, File{
+			Name:          name,
+			Kind:          kind,
+			Format:        out.format,
+			PredicateType: predicateType,
+			SHA256:        hex.EncodeToString(sum[:]),
+		})
+	}
+	return files, nil
+}
+
+// indent pretty-prints extracted JSON, keeping it as is when it cannot be parsed
+func indent(data []byte) []byte {
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return data
+	}
+	return append(out.Bytes(), '\n')
+}
@@ -0,0 +1,110 @@
+package evidence
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func statement(predicateType, predicate string) string {
+	return `{"_type":"https://in-toto.io/Statement/v1","subject":[],"predicateType":"` + predicateType + `","predicate":` + predicate + `}`
+}
+
+func envelope(payload string) string {
+	return `{"payloadType":"application/vnd.in-toto+json","payload":"` + base64.StdEncoding.EncodeToString([]byte(payload)) + `","signatures":[{"keyid":"","sig":"c2ln"}]}`
+}
+
+func TestWrite(t *testing.T) {
+	provenance := statement("https://slsa.dev/provenance/v1", `{"buildDefinition":{}}`)
+	sbom := statement("https://spdx.dev/Document/v2.3", `{"spdxVersion":"SPDX-2.3","packages":[]}`)
+	bundle := `{"mediaType":"application/vnd.dev.sigstore.bundle.v0.3+json","verificationMaterial":{},"dsseEnvelope":` + envelope(provenance) + `}`
+
+	blobs := [][]byte{
+		[]byte(bundle),
+		[]byte(envelope(sbom)),
+		[]byte(statement("https://example.com/custom/v1", `{}`)),
+		[]byte("not json"),
+	}
+
+	dir := filepath.Join(t.TempDir(), "evidence")
+	summary := &Summary{Ref: "ghcr.io/owner/repo:1.0.0", ArtifactDigest: "sha256:abc", DownloadedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), Verified: true}
+	if err := Write(dir, blobs, summary); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		name   string
+		kind   string
+		format string
+	}{
+		{name: "01-provenance.sigstore.json", kind: KindProvenance, format: FormatBundle},
+		{name: "01-provenance.intoto.json", kind: KindProvenance, format: FormatEnvelope},
+		{name: "02-sbom.intoto.json", kind: KindSBOM, format: FormatEnvelope},
+		{name: "02-sbom.spdx.json", kind: KindSBOM, format: FormatSPDX},
+		{name: "03-attestation.statement.json", kind: KindOther, format: FormatStatement},
+		{name: "04-attestation.bin", kind: KindOther, format: FormatRaw},
+	}
+	if len(summary.Files) != len(expected) {
+		t.Fatalf("expected %d files, got %+v", len(expected), summary.Files)
+	}
+	for i, want := range expected {
+		got := summary.Files[i]
+		if got.Name != want.name || got.Kind != want.kind || got.Format != want.format || len(got.SHA256) != 64 {
+			t.Errorf("file %d: expected %s (%s, %s), got %+v", i, want.name, want.kind, want.format, got)
+		}
+		if _, err := os.Stat(filepath.Join(dir, want.name)); err != nil {
+			t.Errorf("expected %s to be written: %v", want.name, err)
+		}
+	}
+
+	// Attestations are kept byte for byte
+	raw, err := os.ReadFile(filepath.Join(dir, "01-provenance.sigstore.json"))
+	if err != nil || string(raw) != bundle {
+		t.Errorf("expected bundle to be archived unchanged, got %q (%v)", raw, err)
+	}
+
+	var spdx struct {
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "02-sbom.spdx.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &spdx); err != nil || spdx.SPDXVersion != "SPDX-2.3" {
+		t.Errorf("expected extracted SPDX document, got %s (%v)", data, err)
+	}
+
+	var written Summary
+	data, err = os.ReadFile(filepath.Join(dir, SummaryFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatal(err)
+	}
+	if written.Ref != summary.Ref || !written.Verified || len(written.Files) != len(expected) {
+		t.Errorf("unexpected summary %+v", written)
+	}
+}
+
+func TestKind(t *testing.T) {
+	tests := []struct {
+		predicateType string
+		want          string
+	}{
+		{predicateType: "https://slsa.dev/provenance/v1", want: KindProvenance},
+		{predicateType: "https://spdx.dev/Document/v2.3", want: KindSBOM},
+		{predicateType: "https://spdx.dev/Document/v3.0", want: KindSBOM},
+		{predicateType: "https://in-toto.io/attestation/vulns/v0.1", want: KindOther},
+		{predicateType: "", want: KindOther},
+	}
+
+	for _, tt := range tests {
+		if got := Kind(tt.predicateType); got != tt.want {
+			t.Errorf("Kind(%q): expected %q, got %q", tt.predicateType, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// ABOUTME: Thread-safe collector of verification errors and warnings, tagged by plugin and check
+// ABOUTME: Shared by concurrent verification jobs and read by exit-code logic and renderers
+package findings
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Severity says whether a finding failed verification
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is one error or warning raised while verifying a plugin
+type Finding struct {
+	// Plugin being verified, when known
+	PluginID string `json:"plugin_id,omitempty"`
+
+	// Verification check that raised the finding, e.g. "attestations" or "sbom"
+	Check string `json:"check,omitempty"`
+
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+func (f Finding) String() string {
+	s := f.Message
+	if f.Check != "" {
+		s = f.Check + ": " + s
+	}
+	if f.PluginID != "" {
+		s = f.PluginID + ": " + s
+	}
+	return s
+}
+
+// Collector accumulates findings in the order they were raised. The zero value is ready
+// to use, and a Collector is safe for concurrent use, so it must not be copied.
+type Collector struct {
+	mu       sync.Mutex
+	pluginID string
+	findings []Finding
+}
+
+// Add records f, tagging it with the collector's plugin ID when it has none
+func (c *Collector) Add(f Finding) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if f.PluginID == "" {
+		f.PluginID = c.pluginID
+	}
+	c.findings = append(c.findings, f)
+}
+
+// Errorf records an error raised by check with a formatted message
+func (c *Collector) Errorf(check, format string, args ...any) {
+	c.Add(Finding{Check: check, Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+}
+
+// Warnf records a warning raised by check with a formatted message
+func (c *Collector) Warnf(check, format string, args ...any) {
+	c.Add(Finding{Check: check, Severity: SeverityWarning, Message: fmt.Sprintf(format, args...)})
+}
+
+// SetPluginID tags the findings recorded so far, and those recorded later, that name no
+// plugin with pluginID. Verifiers often learn which plugin they verified only after
+// discovery has raised findings.
+func (c *Collector) SetPluginID(pluginID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pluginID = pluginID
+	for i := range c.findings {
+		if c.findings[i].PluginID == "" {
+			c.findings[i].PluginID = pluginID
+		}
+	}
+}
+
+// All returns a snapshot of the findings in the order they were recorded
+func (c *Collector) All() []Finding {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Finding{}, c.findings...)
+}
+
+// Errors returns a snapshot of the error findings
+func (c *Collector) Errors() []Finding {
+	return c.bySeverity(SeverityError)
+}
+
+// Warnings returns a snapshot of the warning findings
+func (c *Collector) Warnings() []Finding {
+	return c.bySeverity(SeverityWarning)
+}
+
+func (c *Collector) bySeverity(severity Severity) []Finding {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	found := []Finding{}
+	for _, f := range c.findings {
+		if f.Severity == severity {
+			found = append(found, f)
+		}
+	}
+	return found
+}
+
+// Messages returns the messages of findings, without their plugin and check tags
+func Messages(findings []Finding) []string {
+	messages := make([]string, 0, len(findings))
+	for _, f := range findings {
+		messages = append(messages, f.Message)
+	}
+	return messages
+}
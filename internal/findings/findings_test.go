@@ -0,0 +1,80 @@
+package findings
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestCollector(t *testing.T) {
+	var c Collector
+	c.Errorf("attestations", "failed to get attestations: %s", "503")
+	c.Warnf("signature", "failed to parse sigstore bundle %d", 0)
+	c.SetPluginID("obsidian-git")
+	c.Add(Finding{PluginID: "other-plugin", Check: "sbom", Severity: SeverityWarning, Message: "no SBOM"})
+	c.Errorf("provenance", "SLSA verification failed")
+
+	all := c.All()
+	want := []string{
+		"obsidian-git: attestations: failed to get attestations: 503",
+		"obsidian-git: signature: failed to parse sigstore bundle 0",
+		"other-plugin: sbom: no SBOM",
+		"obsidian-git: provenance: SLSA verification failed",
+	}
+	if len(all) != len(want) {
+		t.Fatalf("expected %d findings, got %v", len(want), all)
+	}
+	for i, f := range all {
+		if f.String() != want[i] {
+			t.Errorf("finding %d: expected %q, got %q", i, want[i], f.String())
+		}
+	}
+
+	if got := Messages(c.Errors()); !slices.Equal(got, []string{"failed to get attestations: 503", "SLSA verification failed"}) {
+		t.Errorf("unexpected errors %v", got)
+	}
+	if got := Messages(c.Warnings()); !slices.Equal(got, []string{"failed to parse sigstore bundle 0", "no SBOM"}) {
+		t.Errorf("unexpected warnings %v", got)
+	}
+
+	all[0].Message = "changed"
+	if c.All()[0].Message == "changed" {
+		t.Error("expected All to return a snapshot")
+	}
+}
+
+func TestCollectorEmpty(t *testing.T) {
+	var c Collector
+	if errs := c.Errors(); errs == nil || len(errs) != 0 {
+		t.Errorf("expected an empty, non-nil slice, got %#v", errs)
+	}
+	if got := (Finding{Severity: SeverityError, Message: "boom"}).String(); got != "boom" {
+		t.Errorf("expected an untagged finding to render as its message, got %q", got)
+	}
+}
+
+func TestCollectorConcurrent(t *testing.T) {
+	var c Collector
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			plugin := fmt.Sprintf("plugin-%d", i)
+			for range 50 {
+				c.Add(Finding{PluginID: plugin, Check: "sbom", Severity: SeverityWarning, Message: "no SBOM"})
+				c.Errorf("attestations", "not found")
+				_ = c.Warnings()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(c.All()); got != 2000 {
+		t.Errorf("expected 2000 findings, got %d", got)
+	}
+	if got := len(c.Errors()); got != 1000 {
+		t.Errorf("expected 1000 errors, got %d", got)
+	}
+}
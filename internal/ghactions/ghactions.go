@@ -0,0 +1,139 @@
+// ABOUTME: GitHub Actions workflow commands and job summaries
+// ABOUTME: Formats ::error/::warning annotations and appends markdown tables to the step summary
+package ghactions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SummaryEnv names the file Actions renders as the job summary
+	SummaryEnv = "GITHUB_STEP_SUMMARY"
+
+	// ActionsEnv is set to "true" when running inside GitHub Actions
+	ActionsEnv = "GITHUB_ACTIONS"
+)
+
+// Level is the severity of an annotation
+type Level string
+
+const (
+	LevelError   Level = "error"
+	LevelWarning Level = "warning"
+	LevelNotice  Level = "notice"
+)
+
+// Annotation is a workflow command that Actions shows on the run and, when File is set,
+// inline on the pull request diff
+type Annotation struct {
+	Level   Level
+	Title   string
+	File    string
+	Line    int
+	Message string
+}
+
+// String formats the annotation as a workflow command, e.g.
+// "::error file=dragonglass-lock.json,title=Verification failed::message"
+func (a Annotation) String() string {
+	var props []string
+	if a.File != "" {
+		props = append(props, "file="+escapeProperty(a.File))
+	}
+	if a.Line > 0 {
+		props = append(props, "line="+strconv.Itoa(a.Line))
+	}
+	if a.Title != "" {
+		props = append(props, "title="+escapeProperty(a.Title))
+	}
+
+	command := "::" + string(a.Level)
+	if len(props) > 0 {
+		command += " " + strings.Join(props, ",")
+	}
+	return command + "::" + escapeData(a.Message)
+}
+
+// Write prints the annotation to w on its own line
+func (a Annotation) Write(w io.Writer) error {
+	_, err := fmt.Fprintln(w, a.String())
+	return err
+}
+
+// InActions reports whether the process runs inside GitHub Actions
+func InActions() bool {
+	return os.Getenv(ActionsEnv) == "true"
+}
+
+// Table is a markdown table for the job summary
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Markdown renders the table; pipes and line breaks in cells are escaped so a cell
+// cannot break the table layout
+func (t Table) Markdown() string {
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, cell := range cells {
+			b.WriteString(" " + escapeCell(cell) + " |")
+		}
+		b.WriteString("\n")
+	}
+
+	writeRow(t.Header)
+	b.WriteString("|")
+	for range t.Header {
+		b.WriteString(" --- |")
+	}
+	b.WriteString("\n")
+	for _, row := range t.Rows {
+		writeRow(row)
+	}
+	return b.String()
+}
+
+// AppendSummary appends markdown to the job summary file at path
+func AppendSummary(path, markdown string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open job summary: %w", err)
+	}
+
+	if _, err := io.WriteString(f, markdown); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write job summary: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write job summary: %w", err)
+	}
+	return nil
+}
+
+// escapeData escapes an annotation message as the Actions runner expects
+func escapeData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeProperty escapes an annotation property value, which additionally may not
+// contain the ':' and ',' separators
+func escapeProperty(s string) string {
+	s = escapeData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
+
+func escapeCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\r\n", "<br>")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
@@ -0,0 +1,77 @@
+package ghactions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAnnotationString(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation Annotation
+		expected   string
+	}{
+		{
+			name:       "message only",
+			annotation: Annotation{Level: LevelWarning, Message: "no SBOM attestation"},
+			expected:   "::warning::no SBOM attestation",
+		},
+		{
+			name:       "properties",
+			annotation: Annotation{Level: LevelError, File: ".dragonglass/dragonglass-lock.json", Line: 3, Title: "Verification failed: dataview", Message: "untrusted builder"},
+			expected:   "::error file=.dragonglass/dragonglass-lock.json,line=3,title=Verification failed%3A dataview::untrusted builder",
+		},
+		{
+			name:       "escaped message",
+			annotation: Annotation{Level: LevelError, Title: "a,b", Message: "100% failed\nsecond line"},
+			expected:   "::error title=a%2Cb::100%25 failed%0Asecond line",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.annotation.String(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTableMarkdown(t *testing.T) {
+	table := Table{
+		Header: []string{"Plugin", "Result"},
+		Rows: [][]string{
+			{"dataview", "verified"},
+			{"a|b", "line one\nline two"},
+		},
+	}
+
+	expected := "| Plugin | Result |\n" +
+		"| --- | --- |\n" +
+		"| dataview | verified |\n" +
+		"| a\\|b | line one<br>line two |\n"
+
+	if got := table.Markdown(); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestAppendSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+
+	if err := AppendSummary(path, "first\n"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := AppendSummary(path, "second\n"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read summary: %v", err)
+	}
+	if string(data) != "first\nsecond\n" {
+		t.Errorf("expected appended summary, got %q", data)
+	}
+}
@@ -6,12 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
-
-	"github.com/pterm/pterm"
-	"github.com/zalando/go-keyring"
 
+	"github.com/gillisandrew/dragonglass-poc/internal/auth"
 	"github.com/gillisandrew/dragonglass-poc/internal/domain"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/httpclient"
 )
 
 // Service implements domain.AuthService using GitHub OAuth
@@ -26,33 +25,47 @@ func NewService() *Service {
 	return &Service{
 		gitHubHost:     "github.com",
 		requiredScopes: "read:packages",
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		httpClient:     httpclient.New(httpclient.DefaultOpts()),
 	}
 }
 
 // Authenticate implements domain.AuthService.Authenticate
 func (s *Service) Authenticate() error {
-	return s.authenticateWithDeviceFlow()
+	return s.authenticateWithDeviceFlow(s.requiredScopes)
+}
+
+// RequestScopes implements domain.AuthService.RequestScopes. The device flow asks for
+// the union of the stored credential's scopes and scopes, so nothing already granted is
+// lost, and the stored credential is only replaced once the new token is issued.
+func (s *Service) RequestScopes(scopes []string) error {
+	granted := s.requiredScopes
+	if cred, err := s.getStoredCredential(); err == nil && cred.Scopes != "" {
+		granted = cred.Scopes
+	}
+	return s.authenticateWithDeviceFlow(auth.UnionScopes(granted, scopes))
 }
 
 // IsAuthenticated implements domain.AuthService.IsAuthenticated
 func (s *Service) IsAuthenticated() bool {
-	token, err := s.GetToken()
-	if err != nil || token == "" {
+	cred, err := s.getStoredCredential()
+	if err != nil || cred.Token == "" {
 		return false
 	}
 
+	// Session credentials such as exchanged Actions tokens cannot read /user
+	if cred.Store == auth.StoreSession {
+		return true
+	}
+
 	// Validate the token by making a test request
-	return s.validateToken(token) == nil
+	return s.validateToken(cred.Token) == nil
 }
 
 // GetToken implements domain.AuthService.GetToken
 func (s *Service) GetToken() (string, error) {
 	cred, err := s.getStoredCredential()
 	if err != nil {
-		return "", fmt.Errorf("no stored credentials found: %w", err)
+		return "", dgerrors.Mark(fmt.Errorf("no stored credentials found: %w", err), dgerrors.ErrNotAuthenticated)
 	}
 	return cred.Token, nil
 }
@@ -61,7 +74,7 @@ func (s *Service) GetToken() (string, error) {
 func (s *Service) GetUser() (string, error) {
 	cred, err := s.getStoredCredential()
 	if err != nil {
-		return "", fmt.Errorf("not authenticated: %w", err)
+		return "", fmt.Errorf("%w: %w", dgerrors.ErrNotAuthenticated, err)
 	}
 
 	if cred.Username != "" {
@@ -77,7 +90,7 @@ func (s *Service) GetUser() (string, error) {
 func (s *Service) GetCredential() (*domain.Credential, error) {
 	cred, err := s.getStoredCredential()
 	if err != nil {
-		return nil, fmt.Errorf("not authenticated: %w", err)
+		return nil, fmt.Errorf("%w: %w", dgerrors.ErrNotAuthenticated, err)
 	}
 
 	// Convert internal credential to domain credential
@@ -87,6 +100,7 @@ func (s *Service) GetCredential() (*domain.Credential, error) {
 		Username:  cred.Username,
 		CreatedAt: cred.CreatedAt,
 		Source:    cred.Source,
+		Store:     string(cred.Store),
 		Host:      s.gitHubHost,
 	}, nil
 }
@@ -98,56 +112,14 @@ func (s *Service) Logout() error {
 
 // Internal types and methods
 
-type storedCredential struct {
-	Token     string    `json:"token"`
-	Scopes    string    `json:"scopes"`
-	Username  string    `json:"username,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	Source    string    `json:"source"`
-}
-
-type deviceCodeResponse struct {
-	DeviceCode      string `json:"device_code"`
-	UserCode        string `json:"user_code"`
-	VerificationURI string `json:"verification_uri"`
-	ExpiresIn       int    `json:"expires_in"`
-	Interval        int    `json:"interval"`
+// getStoredCredential retrieves credentials from the active credential store
+func (s *Service) getStoredCredential() (*auth.StoredCredential, error) {
+	return auth.GetStoredCredential()
 }
 
-type accessTokenResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	Scope       string `json:"scope"`
-	Error       string `json:"error"`
-}
-
-// Constants for keychain storage
-const (
-	keyringService = "dragonglass-cli"
-	keyringAccount = "github-token"
-)
-
-// getStoredCredential retrieves stored credentials from keychain or file fallback
-func (s *Service) getStoredCredential() (*storedCredential, error) {
-	// Try keychain first
-	data, err := keyring.Get(keyringService, keyringAccount)
-	if err == nil {
-		var cred storedCredential
-		if err := json.Unmarshal([]byte(data), &cred); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal credential: %w", err)
-		}
-		return &cred, nil
-	}
-
-	// TODO: Add file fallback implementation
-	return nil, fmt.Errorf("failed to get credentials from keychain: %w", err)
-}
-
-// clearStoredToken removes stored credentials
+// clearStoredToken removes stored credentials from the active credential store
 func (s *Service) clearStoredToken() error {
-	_ = keyring.Delete(keyringService, keyringAccount)
-	// TODO: Also clear file fallback
-	return nil
+	return auth.ClearStoredToken()
 }
 
 // validateToken checks if the token is valid by making a test request
@@ -205,66 +177,23 @@ func (s *Service) fetchAuthenticatedUser(token string) (string, error) {
 }
 
 // authenticateWithDeviceFlow implements GitHub OAuth device flow
-func (s *Service) authenticateWithDeviceFlow() error {
-	pterm.Info.Println("Starting GitHub device flow authentication")
-
-	// Step 1: Request device code
-	deviceCode, err := s.requestDeviceCode()
-	if err != nil {
-		return fmt.Errorf("failed to request device code: %w", err)
-	}
-
-	// Step 2: Display user code and verification URL
-	pterm.Info.Printf("Please visit: %s\n", deviceCode.VerificationURI)
-	pterm.Info.Printf("And enter code: %s\n", deviceCode.UserCode)
-
-	// Step 3: Poll for access token
-	token, err := s.pollForAccessToken(deviceCode)
+func (s *Service) authenticateWithDeviceFlow(requestedScopes string) error {
+	// Step 1: Run the device flow, which opens the browser and waits for approval
+	token, err := auth.RunDeviceFlow(requestedScopes)
 	if err != nil {
 		return fmt.Errorf("failed to get access token: %w", err)
 	}
 
-	// Step 4: Get user information
+	// Step 2: Get user information
 	username, err := s.fetchAuthenticatedUser(token.AccessToken)
 	if err != nil {
 		return fmt.Errorf("failed to get user info: %w", err)
 	}
 
-	// Step 5: Store credentials
-	cred := storedCredential{
-		Token:     token.AccessToken,
-		Scopes:    token.Scope,
-		Username:  username,
-		CreatedAt: time.Now(),
-		Source:    "device-flow",
-	}
-
-	return s.storeCredential(cred)
-}
-
-// requestDeviceCode requests a device code from GitHub
-func (s *Service) requestDeviceCode() (*deviceCodeResponse, error) {
-	// Implementation would go here - simplified for now
-	return nil, fmt.Errorf("device flow not implemented yet")
-}
-
-// pollForAccessToken polls GitHub for the access token
-func (s *Service) pollForAccessToken(deviceCode *deviceCodeResponse) (*accessTokenResponse, error) {
-	// Implementation would go here - simplified for now
-	return nil, fmt.Errorf("token polling not implemented yet")
-}
-
-// storeCredential saves credentials to keychain
-func (s *Service) storeCredential(cred storedCredential) error {
-	data, err := json.Marshal(cred)
-	if err != nil {
-		return fmt.Errorf("failed to marshal credential: %w", err)
-	}
-
-	if err := keyring.Set(keyringService, keyringAccount, string(data)); err != nil {
-		return fmt.Errorf("failed to store credential: %w", err)
+	// Step 3: Store credentials (GitHub may omit scopes, so fall back to those requested)
+	scopes := token.Scope
+	if scopes == "" {
+		scopes = requestedScopes
 	}
-
-	pterm.Success.Println("🔐 Token stored securely in OS keychain")
-	return nil
+	return auth.StoreToken(token.AccessToken, scopes, username)
 }
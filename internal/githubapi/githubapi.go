@@ -0,0 +1,103 @@
+// ABOUTME: Client for the GitHub REST API shared by the lookups of releases, commits, packages and attestations
+// ABOUTME: Sends requests with the API version headers and token, and reports unexpected statuses
+package githubapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/httpclient"
+)
+
+// APIURL is the public GitHub REST API
+const APIURL = "https://api.github.com"
+
+// DefaultTimeout bounds a single request of a client created without an HTTP client
+const DefaultTimeout = 15 * time.Second
+
+// Opts configures a Client
+type Opts struct {
+	// GitHub REST API base URL (default APIURL)
+	APIURL string
+
+	// GitHub token; public resources need none, but it raises the rate limit
+	Token string
+
+	HTTPClient *http.Client
+}
+
+// DefaultOpts returns options for the public GitHub API without a token
+func DefaultOpts() *Opts {
+	return &Opts{APIURL: APIURL}
+}
+
+// WithAPIURL sets the GitHub REST API base URL
+func (opts *Opts) WithAPIURL(apiURL string) *Opts {
+	opts.APIURL = apiURL
+	return opts
+}
+
+// WithToken sets the GitHub token sent with requests
+func (opts *Opts) WithToken(token string) *Opts {
+	opts.Token = token
+	return opts
+}
+
+// WithHTTPClient sets the HTTP client requests are sent with
+func (opts *Opts) WithHTTPClient(client *http.Client) *Opts {
+	opts.HTTPClient = client
+	return opts
+}
+
+// Client sends requests to the GitHub REST API
+type Client struct {
+	opts *Opts
+}
+
+// NewClient returns a client configured by opts
+func NewClient(opts *Opts) *Client {
+	if opts == nil {
+		opts = DefaultOpts()
+	}
+	// Later changes to the caller's options must not reach the client
+	copied := *opts
+	opts = &copied
+
+	if opts.APIURL == "" {
+		opts.APIURL = APIURL
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = httpclient.NewWithTimeout(DefaultTimeout)
+	}
+	return &Client{opts: opts}
+}
+
+// Get requests path, e.g. "/repos/owner/name/commits/<sha>", from the API. The caller
+// closes the response body.
+func (c *Client) Get(ctx context.Context, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(c.opts.APIURL, "/")+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub API request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.opts.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.opts.Token)
+	}
+	return c.opts.HTTPClient.Do(req)
+}
+
+// StatusError returns the error for an unexpected response from the named API about
+// subject, e.g. "commits API returned 500 Internal Server Error for owner/name: <body>".
+// It is an httpclient.StatusError, so transient statuses can be retried.
+func StatusError(resp *http.Response, api, subject string) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return &httpclient.StatusError{
+		StatusCode: resp.StatusCode,
+		Err:        fmt.Errorf("%s API returned %s for %s: %s", api, resp.Status, subject, strings.TrimSpace(string(body))),
+	}
+}
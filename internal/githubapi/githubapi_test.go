@@ -0,0 +1,61 @@
+package githubapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/httpclient"
+)
+
+func TestGet(t *testing.T) {
+	var headers http.Header
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers, path = r.Header, r.URL.Path
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(" try later \n"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+	}{
+		{name: "with token", token: "secret", authorization: "Bearer secret"},
+		{name: "without token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(DefaultOpts().WithAPIURL(server.URL + "/").WithToken(tt.token))
+			resp, err := client.Get(context.Background(), "/repos/owner/name")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if path != "/repos/owner/name" {
+				t.Errorf("expected path relative to the API URL, got %q", path)
+			}
+			if headers.Get("Accept") != "application/vnd.github+json" || headers.Get("X-GitHub-Api-Version") == "" {
+				t.Errorf("expected GitHub API headers, got %v", headers)
+			}
+			if headers.Get("Authorization") != tt.authorization {
+				t.Errorf("expected Authorization %q, got %q", tt.authorization, headers.Get("Authorization"))
+			}
+
+			err = StatusError(resp, "commits", "owner/name")
+			if err.Error() != "commits API returned 503 Service Unavailable for owner/name: try later" {
+				t.Errorf("unexpected error %q", err)
+			}
+			var statusErr *httpclient.StatusError
+			if !errors.As(err, &statusErr) || !httpclient.Transient(err) {
+				t.Errorf("expected a transient StatusError, got %v", err)
+			}
+		})
+	}
+}
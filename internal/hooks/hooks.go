@@ -0,0 +1,184 @@
+// ABOUTME: User-defined commands run around plugin installs and removals
+// ABOUTME: Each command receives a JSON event on stdin; a failing pre_install hook blocks the install
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+)
+
+// Hook names, as used for the keys under "hooks" in the config
+const (
+	PreInstall  = "pre_install"
+	PostInstall = "post_install"
+	PostRemove  = "post_remove"
+)
+
+// Event is the JSON payload a hook command reads from stdin
+type Event struct {
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+
+	// Vault root directory, also the hook's working directory
+	Vault string `json:"vault"`
+
+	Plugin       Plugin       `json:"plugin"`
+	Verification Verification `json:"verification"`
+}
+
+// Plugin identifies the plugin an event is about
+type Plugin struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	OCIReference string `json:"oci_reference"`
+	OCIDigest    string `json:"oci_digest"`
+}
+
+// Verification summarizes the plugin's verification state: the state being installed for
+// install events, and the state recorded in the lockfile for post_remove
+type Verification struct {
+	ProvenanceVerified bool                          `json:"provenance_verified"`
+	SBOMVerified       bool                          `json:"sbom_verified"`
+	VulnScanPassed     bool                          `json:"vuln_scan_passed"`
+	BuilderID          string                        `json:"builder_id,omitempty"`
+	SLSALevel          int                           `json:"slsa_level"`
+	Vulnerabilities    *lockfile.VulnerabilityCounts `json:"vulnerabilities,omitempty"`
+	Warnings           []string                      `json:"warnings,omitempty"`
+	Errors             []string                      `json:"errors,omitempty"`
+}
+
+// NewEvent describes hook name for pluginID, installed or removed as entry, in the vault
+// rooted at vaultRoot
+func NewEvent(name, vaultRoot, pluginID string, entry lockfile.PluginEntry, at time.Time) *Event {
+	state := entry.VerificationState
+	return &Event{
+		Event: name,
+		Time:  at,
+		Vault: vaultRoot,
+		Plugin: Plugin{
+			ID:           pluginID,
+			Name:         entry.Name,
+			Version:      entry.Version,
+			OCIReference: entry.OCIReference,
+			OCIDigest:    entry.OCIDigest,
+		},
+		Verification: Verification{
+			ProvenanceVerified: state.ProvenanceVerified,
+			SBOMVerified:       state.SBOMVerified,
+			VulnScanPassed:     state.VulnScanPassed,
+			BuilderID:          state.BuilderID,
+			SLSALevel:          state.SLSALevel,
+			Vulnerabilities:    state.Vulnerabilities,
+			Warnings:           state.Warnings,
+			Errors:             state.Errors,
+		},
+	}
+}
+
+// RunnerOpts configures where hook commands run and where their output goes
+type RunnerOpts struct {
+	// Working directory (default: the event's vault)
+	Dir string
+
+	// Hook output; stdout defaults to os.Stderr so command output stays clean
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// DefaultRunnerOpts returns options that send hook output to stderr
+func DefaultRunnerOpts() *RunnerOpts {
+	return &RunnerOpts{
+		Stdout: os.Stderr,
+		Stderr: os.Stderr,
+	}
+}
+
+// WithDir sets the working directory of hook commands
+func (opts *RunnerOpts) WithDir(dir string) *RunnerOpts {
+	opts.Dir = dir
+	return opts
+}
+
+// WithOutput sets where hook commands write stdout and stderr
+func (opts *RunnerOpts) WithOutput(stdout, stderr io.Writer) *RunnerOpts {
+	opts.Stdout = stdout
+	opts.Stderr = stderr
+	return opts
+}
+
+// HookError reports a hook command that could not run or exited non-zero
+type HookError struct {
+	Event   string
+	Command string
+	Err     error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("%s hook %q failed: %v", e.Event, e.Command, e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// Run runs commands in order through the system shell, each with event as JSON on stdin
+// and DRAGONGLASS_HOOK_EVENT, DRAGONGLASS_PLUGIN_ID and DRAGONGLASS_VAULT in its
+// environment. It stops at the first command that fails.
+func Run(ctx context.Context, commands []string, event *Event, opts *RunnerOpts) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	if opts == nil {
+		opts = DefaultRunnerOpts()
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event.Event, err)
+	}
+
+	dir := opts.Dir
+	if dir == "" {
+		dir = event.Vault
+	}
+
+	for _, command := range commands {
+		c := shellCommand(ctx, command)
+		c.Dir = dir
+		c.Stdin = bytes.NewReader(payload)
+		c.Stdout = opts.Stdout
+		c.Stderr = opts.Stderr
+		c.Env = append(os.Environ(),
+			"DRAGONGLASS_HOOK_EVENT="+event.Event,
+			"DRAGONGLASS_PLUGIN_ID="+event.Plugin.ID,
+			"DRAGONGLASS_VAULT="+event.Vault,
+		)
+
+		if err := c.Run(); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				err = ctxErr
+			}
+			return &HookError{Event: event.Event, Command: command, Err: err}
+		}
+	}
+	return nil
+}
+
+// shellCommand runs command through sh, or cmd on Windows, so hooks can use pipes and
+// quoting
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
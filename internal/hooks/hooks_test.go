@@ -0,0 +1,101 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+)
+
+func testEvent(vault string) *Event {
+	entry := lockfile.PluginEntry{
+		Name:         "Dataview",
+		Version:      "1.9.0",
+		OCIReference: "ghcr.io/owner/dataview:1.9.0",
+		OCIDigest:    "sha256:abc",
+		VerificationState: lockfile.VerificationState{
+			ProvenanceVerified: true,
+			BuilderID:          "https://github.com/owner/repo/.github/workflows/build.yml@refs/heads/main",
+			SLSALevel:          3,
+			Warnings:           []string{"no SBOM"},
+		},
+	}
+	return NewEvent(PreInstall, vault, "dataview", entry, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in this test use sh")
+	}
+
+	vault := t.TempDir()
+	var stdout, stderr strings.Builder
+	opts := DefaultRunnerOpts().WithOutput(&stdout, &stderr)
+
+	commands := []string{
+		"cat > event.json",
+		`echo "$DRAGONGLASS_HOOK_EVENT $DRAGONGLASS_PLUGIN_ID"`,
+		"echo to-stderr >&2",
+	}
+	if err := Run(context.Background(), commands, testEvent(vault), opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(vault, "event.json"))
+	if err != nil {
+		t.Fatalf("expected the hook to run in the vault: %v", err)
+	}
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		t.Fatalf("hook received invalid JSON: %v", err)
+	}
+	if event.Event != PreInstall || event.Plugin.ID != "dataview" || event.Plugin.OCIDigest != "sha256:abc" ||
+		!event.Verification.ProvenanceVerified || event.Verification.SLSALevel != 3 {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	if stdout.String() != "pre_install dataview\n" {
+		t.Errorf("unexpected stdout %q", stdout.String())
+	}
+	if stderr.String() != "to-stderr\n" {
+		t.Errorf("unexpected stderr %q", stderr.String())
+	}
+}
+
+func TestRunStopsAtFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in this test use sh")
+	}
+
+	vault := t.TempDir()
+	commands := []string{"exit 3", "touch ran"}
+	err := Run(context.Background(), commands, testEvent(vault), DefaultRunnerOpts().WithOutput(nil, nil))
+
+	var hookErr *HookError
+	if !errors.As(err, &hookErr) || hookErr.Command != "exit 3" || hookErr.Event != PreInstall {
+		t.Fatalf("expected HookError for the failing command, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(vault, "ran")); !os.IsNotExist(err) {
+		t.Error("expected later hooks to be skipped after a failure")
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in this test use sh")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := Run(ctx, []string{"sleep 5"}, testEvent(t.TempDir()), DefaultRunnerOpts().WithOutput(nil, nil))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+// ABOUTME: Shared construction of the HTTP clients used for every outbound request
+// ABOUTME: Applies timeouts, environment proxy settings, a versioned User-Agent, and debug request logging
+package httpclient
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pterm/pterm"
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+// DefaultTimeout bounds requests of clients created without a timeout of their own
+const DefaultTimeout = 30 * time.Second
+
+var (
+	mu      sync.RWMutex
+	version = "dev"
+	logger  *pterm.Logger
+)
+
+// SetVersion sets the version reported in the User-Agent of every request
+func SetVersion(v string) {
+	if v == "" {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	version = v
+}
+
+// SetLogger logs every request at debug level to logger; nil turns request logging off
+func SetLogger(l *pterm.Logger) {
+	mu.Lock()
+	defer mu.Unlock()
+	logger = l
+}
+
+// UserAgent returns the User-Agent sent with every request, e.g. "dragonglass/1.2.0"
+func UserAgent() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return "dragonglass/" + version
+}
+
+// Opts configures a client
+type Opts struct {
+	// Timeout of each request, including reading the response body
+	Timeout time.Duration
+
+	// Proxy selects the proxy for a request (default: HTTPS_PROXY, HTTP_PROXY and NO_PROXY
+	// from the environment)
+	Proxy func(*http.Request) (*url.URL, error)
+}
+
+// DefaultOpts returns options with DefaultTimeout and the proxy from the environment
+func DefaultOpts() *Opts {
+	return &Opts{
+		Timeout: DefaultTimeout,
+		Proxy:   http.ProxyFromEnvironment,
+	}
+}
+
+// WithTimeout sets the request timeout
+func (opts *Opts) WithTimeout(timeout time.Duration) *Opts {
+	opts.Timeout = timeout
+	return opts
+}
+
+// WithProxy sends every request through proxyURL
+func (opts *Opts) WithProxy(proxyURL *url.URL) *Opts {
+	opts.Proxy = http.ProxyURL(proxyURL)
+	return opts
+}
+
+// New returns a client configured by opts
+func New(opts *Opts) *http.Client {
+	if opts == nil {
+		opts = DefaultOpts()
+	}
+	return &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: NewTransport(opts),
+	}
+}
+
+// NewWithTimeout returns a client with the default options and timeout
+func NewWithTimeout(timeout time.Duration) *http.Client {
+	return New(DefaultOpts().WithTimeout(timeout))
+}
+
+// NewRetrying returns a client for registry requests that retries on rate limiting and
+// server errors as ORAS does by default. Registry transfers can be large, so there is no
+// overall timeout; callers bound them with their context.
+func NewRetrying() *http.Client {
+	return &http.Client{Transport: retry.NewTransport(NewTransport(DefaultOpts()))}
+}
+
+// NewTransport returns a transport applying the proxy of opts, the User-Agent, and
+// request logging
+func NewTransport(opts *Opts) http.RoundTripper {
+	if opts == nil {
+		opts = DefaultOpts()
+	}
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.Proxy = opts.Proxy
+	return &transport{base: base}
+}
+
+// Wrap adds the User-Agent and request logging to base, for transports that are
+// configured elsewhere
+func Wrap(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+// transport sets the User-Agent of requests that have none and logs them
+type transport struct {
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		// Clone request to avoid modifying original
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", UserAgent())
+	}
+
+	mu.RLock()
+	l := logger
+	mu.RUnlock()
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if l != nil {
+		// Only the host is logged: webhook and token URLs often carry secrets in their path
+		// or query
+		args := []any{"method", req.Method, "host", req.URL.Host, "duration", time.Since(start).Round(time.Millisecond)}
+		if err != nil {
+			args = append(args, "error", err)
+		} else {
+			args = append(args, "status", resp.StatusCode)
+		}
+		l.Debug("HTTP request", l.Args(args...))
+	}
+	return resp, err
+}
@@ -0,0 +1,69 @@
+package httpclient
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+func TestUserAgentAndLogging(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	SetVersion("1.2.3")
+	defer SetVersion("dev")
+
+	var logs bytes.Buffer
+	SetLogger(pterm.DefaultLogger.WithWriter(&logs).WithLevel(pterm.LogLevelDebug))
+	defer SetLogger(nil)
+
+	client := NewWithTimeout(5 * time.Second)
+	if client.Timeout != 5*time.Second {
+		t.Errorf("expected 5s timeout, got %s", client.Timeout)
+	}
+
+	resp, err := client.Get(server.URL + "/hooks/secret-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("User-Agent", "custom")
+	resp, err = NewRetrying().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if len(userAgents) != 2 || userAgents[0] != "dragonglass/1.2.3" || userAgents[1] != "custom" {
+		t.Errorf("expected versioned User-Agent unless one is set, got %v", userAgents)
+	}
+
+	out := logs.String()
+	if !strings.Contains(out, "HTTP request") || !strings.Contains(out, "418") {
+		t.Errorf("expected request to be logged with its status, got %q", out)
+	}
+	if strings.Contains(out, "secret-token") {
+		t.Errorf("expected request path to be left out of logs, got %q", out)
+	}
+}
+
+func TestSetVersionIgnoresEmpty(t *testing.T) {
+	SetVersion("")
+	if got := UserAgent(); got != "dragonglass/dev" {
+		t.Errorf("expected dragonglass/dev, got %q", got)
+	}
+}
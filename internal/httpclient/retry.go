@@ -0,0 +1,177 @@
+// ABOUTME: Bounded retries with jittered exponential backoff for flaky network calls
+// ABOUTME: Retries only transient failures such as timeouts, resets, 429 and 5xx responses
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// Defaults for RetryOpts
+const (
+	DefaultRetryAttempts  = 3
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	DefaultRetryMaxDelay  = 5 * time.Second
+)
+
+// RetryOpts configures Retry
+type RetryOpts struct {
+	// Calls made in total, including the first (at least one)
+	Attempts int
+
+	// The delay before the nth retry is drawn uniformly from [0, BaseDelay*2^(n-1)],
+	// capped at MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// Reports whether a failed call may succeed if repeated (default: Transient)
+	Retryable func(error) bool
+}
+
+// DefaultRetryOpts returns options retrying transient failures DefaultRetryAttempts times
+func DefaultRetryOpts() *RetryOpts {
+	return &RetryOpts{
+		Attempts:  DefaultRetryAttempts,
+		BaseDelay: DefaultRetryBaseDelay,
+		MaxDelay:  DefaultRetryMaxDelay,
+		Retryable: Transient,
+	}
+}
+
+// WithAttempts sets the number of calls made in total
+func (opts *RetryOpts) WithAttempts(attempts int) *RetryOpts {
+	opts.Attempts = attempts
+	return opts
+}
+
+// WithDelay sets the base and maximum delay between calls
+func (opts *RetryOpts) WithDelay(base, maxDelay time.Duration) *RetryOpts {
+	opts.BaseDelay = base
+	opts.MaxDelay = maxDelay
+	return opts
+}
+
+// WithRetryable sets the classifier of failures worth repeating
+func (opts *RetryOpts) WithRetryable(retryable func(error) bool) *RetryOpts {
+	opts.Retryable = retryable
+	return opts
+}
+
+// RetryError is returned by Retry when every attempt failed with a retryable error
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%v (after %d attempts)", e.Err, e.Attempts)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// Retry calls op until it succeeds, fails with an error that is not retryable, or has been
+// called opts.Attempts times, waiting a jittered, exponentially growing delay between
+// calls. It stops waiting when ctx is done and returns the last error of op.
+func Retry(ctx context.Context, opts *RetryOpts, op func(ctx context.Context) error) error {
+	if opts == nil {
+		opts = DefaultRetryOpts()
+	}
+	retryable := opts.Retryable
+	if retryable == nil {
+		retryable = Transient
+	}
+	attempts := max(opts.Attempts, 1)
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = op(ctx); err == nil || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+		if attempt == attempts {
+			if attempts == 1 {
+				return err
+			}
+			return &RetryError{Attempts: attempts, Err: err}
+		}
+
+		timer := time.NewTimer(opts.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay after the given failed attempt, with full jitter
+func (opts *RetryOpts) backoff(attempt int) time.Duration {
+	ceiling := opts.BaseDelay << min(attempt-1, 30)
+	if ceiling <= 0 || (opts.MaxDelay > 0 && ceiling > opts.MaxDelay) {
+		ceiling = opts.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling + 1)
+}
+
+// StatusError is a request that completed with an HTTP error status. Err carries the
+// message reported to the user.
+type StatusError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *StatusError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// TransientStatus reports whether a response with the HTTP status code may succeed when
+// repeated: rate limiting and server errors
+func TransientStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// Transient reports whether err is a failure of the network or the server rather than of
+// the request: timeouts, refused or reset connections, truncated responses, and
+// StatusErrors with a transient status. Cancellation of the caller's context is not.
+func Transient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return TransientStatus(statusErr.StatusCode)
+	}
+
+	var netErr net.Error
+	var opErr *net.OpError
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	case errors.Is(err, context.DeadlineExceeded):
+		return true
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE):
+		return true
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return true
+	case errors.As(err, &opErr):
+		return true
+	}
+	return false
+}
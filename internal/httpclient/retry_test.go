@@ -0,0 +1,114 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	transient := &StatusError{StatusCode: 503, Err: errors.New("service unavailable")}
+	permanent := &StatusError{StatusCode: 404, Err: errors.New("not found")}
+
+	tests := []struct {
+		name          string
+		failures      []error
+		expectCalls   int
+		expectErr     error
+		expectRetried bool
+	}{
+		{name: "first call succeeds", expectCalls: 1},
+		{name: "transient failure then success", failures: []error{transient}, expectCalls: 2},
+		{name: "permanent failure is not retried", failures: []error{permanent}, expectCalls: 1, expectErr: permanent},
+		{name: "gives up after the attempts", failures: []error{transient, transient, transient, transient}, expectCalls: 3, expectErr: transient, expectRetried: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			opts := DefaultRetryOpts().WithDelay(time.Millisecond, 2*time.Millisecond)
+			err := Retry(context.Background(), opts, func(context.Context) error {
+				calls++
+				if calls <= len(tt.failures) {
+					return tt.failures[calls-1]
+				}
+				return nil
+			})
+
+			if calls != tt.expectCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectCalls, calls)
+			}
+			if !errors.Is(err, tt.expectErr) || (tt.expectErr == nil && err != nil) {
+				t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+			}
+			var retryErr *RetryError
+			if errors.As(err, &retryErr) != tt.expectRetried {
+				t.Errorf("expected RetryError=%v, got %v", tt.expectRetried, err)
+			}
+		})
+	}
+}
+
+func TestRetryStopsWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	start := time.Now()
+	err := Retry(ctx, DefaultRetryOpts().WithDelay(time.Hour, time.Hour), func(context.Context) error {
+		calls++
+		cancel()
+		return syscall.ECONNRESET
+	})
+
+	if !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("expected the last error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected no retry after cancellation, got %d calls", calls)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("expected Retry not to wait out the delay")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	opts := DefaultRetryOpts().WithDelay(100*time.Millisecond, 300*time.Millisecond)
+	for attempt, ceiling := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 40: 300 * time.Millisecond} {
+		for range 50 {
+			if delay := opts.backoff(attempt); delay < 0 || delay > ceiling {
+				t.Fatalf("attempt %d: expected a delay within [0, %v], got %v", attempt, ceiling, delay)
+			}
+		}
+	}
+}
+
+func TestTransient(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil", err: nil},
+		{name: "server error", err: &StatusError{StatusCode: 502, Err: errors.New("bad gateway")}, expected: true},
+		{name: "rate limited", err: &StatusError{StatusCode: 429, Err: errors.New("slow down")}, expected: true},
+		{name: "client error", err: &StatusError{StatusCode: 401, Err: errors.New("unauthorized")}},
+		{name: "connection reset", err: fmt.Errorf("fetch: %w", syscall.ECONNRESET), expected: true},
+		{name: "dial failure", err: &net.OpError{Op: "dial", Err: errors.New("no route to host")}, expected: true},
+		{name: "truncated body", err: fmt.Errorf("read: %w", io.ErrUnexpectedEOF), expected: true},
+		{name: "timeout", err: context.DeadlineExceeded, expected: true},
+		{name: "cancelled", err: fmt.Errorf("fetch: %w", context.Canceled)},
+		{name: "other", err: errors.New("invalid signature")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Transient(tt.err); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// ABOUTME: Message catalog for user-facing prompts and status messages with locale selection
+// ABOUTME: Picks the locale from --lang or the environment; log lines and JSON output stay English
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Locale is a language with a message catalog
+type Locale string
+
+const (
+	English Locale = "en"
+	German  Locale = "de"
+
+	// Auto selects the locale from LC_ALL, LC_MESSAGES or LANG
+	Auto = "auto"
+)
+
+// Locales lists every shipped locale, in the order shown to users
+var Locales = []Locale{English, German}
+
+// catalogs maps each locale to its messages, keyed by message ID. English is complete;
+// other locales fall back to it for missing messages.
+var catalogs = map[Locale]map[string]string{
+	English: english,
+	German:  german,
+}
+
+var activeLocale = English
+
+// ParseLocale validates a locale name. Auto (or empty) detects the locale from getenv.
+func ParseLocale(name string, getenv func(string) string) (Locale, error) {
+	if name == "" || name == Auto {
+		return Detect(getenv), nil
+	}
+	if locale, ok := match(name); ok {
+		return locale, nil
+	}
+
+	names := []string{Auto}
+	for _, locale := range Locales {
+		names = append(names, string(locale))
+	}
+	return "", fmt.Errorf("invalid language: %s (must be one of %s)", name, strings.Join(names, ", "))
+}
+
+// Detect returns the locale named by the first set of LC_ALL, LC_MESSAGES and LANG,
+// falling back to English when it is unset, "C", "POSIX" or has no catalog
+func Detect(getenv func(string) string) Locale {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := getenv(name); value != "" {
+			locale, _ := match(value)
+			return locale
+		}
+	}
+	return English
+}
+
+// match maps a POSIX locale such as "de_DE.UTF-8" or a tag such as "de-AT" to a locale
+// with a catalog, returning English and false when there is none
+func match(value string) (Locale, bool) {
+	language := strings.ToLower(value)
+	if i := strings.IndexAny(language, "_-.@"); i >= 0 {
+		language = language[:i]
+	}
+	if _, ok := catalogs[Locale(language)]; ok {
+		return Locale(language), true
+	}
+	return English, false
+}
+
+// SetLocale selects the locale used by T
+func SetLocale(locale Locale) {
+	activeLocale = locale
+}
+
+// ActiveLocale returns the locale selected for this process
+func ActiveLocale() Locale {
+	return activeLocale
+}
+
+// T returns the message id in the active locale, formatted with args like fmt.Sprintf.
+// Messages missing from the locale fall back to English, and unknown IDs are returned
+// as-is so a missing entry is visible rather than fatal.
+func T(id string, args ...any) string {
+	format, ok := catalogs[activeLocale][id]
+	if !ok {
+		if format, ok = english[id]; !ok {
+			format = id
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
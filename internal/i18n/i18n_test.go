@@ -0,0 +1,94 @@
+package i18n
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseLocale(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(name string) string { return vars[name] }
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		env      map[string]string
+		expected Locale
+		errorMsg string
+	}{
+		{name: "explicit", value: "de", expected: German},
+		{name: "explicit ignores environment", value: "en", env: map[string]string{"LANG": "de_DE.UTF-8"}, expected: English},
+		{name: "explicit region tag", value: "de-AT", expected: German},
+		{name: "unsupported", value: "fr", errorMsg: "invalid language: fr (must be one of auto, en, de)"},
+		{name: "auto from LANG", value: "auto", env: map[string]string{"LANG": "de_DE.UTF-8"}, expected: German},
+		{name: "empty means auto", env: map[string]string{"LANG": "de_CH"}, expected: German},
+		{name: "LC_ALL wins", env: map[string]string{"LC_ALL": "en_US.UTF-8", "LC_MESSAGES": "de_DE", "LANG": "de_DE"}, expected: English},
+		{name: "LC_MESSAGES before LANG", env: map[string]string{"LC_MESSAGES": "de_DE@euro", "LANG": "en_GB"}, expected: German},
+		{name: "C locale", env: map[string]string{"LANG": "C"}, expected: English},
+		{name: "no catalog falls back to English", env: map[string]string{"LANG": "fr_FR.UTF-8"}, expected: English},
+		{name: "unset", expected: English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locale, err := ParseLocale(tt.value, env(tt.env))
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Errorf("expected error %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if locale != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, locale)
+			}
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	t.Cleanup(func() { SetLocale(English) })
+
+	SetLocale(German)
+	if got := T("auth.already", "octocat"); got != "Bereits angemeldet als octocat" {
+		t.Errorf("unexpected German message %q", got)
+	}
+	if got := T("no.such.message"); got != "no.such.message" {
+		t.Errorf("expected unknown IDs to be returned as-is, got %q", got)
+	}
+
+	SetLocale(English)
+	if got := T("auth.device.waiting", 4, 5); got != "Waiting for authorization... code expires in 4:05" {
+		t.Errorf("unexpected English message %q", got)
+	}
+}
+
+var verbPattern = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+// TestCatalogsComplete checks every locale translates every English message with the
+// same format verbs, so arguments never end up in the wrong place
+func TestCatalogsComplete(t *testing.T) {
+	for _, locale := range Locales {
+		catalog := catalogs[locale]
+		for id, message := range english {
+			translated, ok := catalog[id]
+			if !ok {
+				t.Errorf("%s: missing message %s", locale, id)
+				continue
+			}
+			expected := strings.Join(verbPattern.FindAllString(message, -1), " ")
+			if got := strings.Join(verbPattern.FindAllString(translated, -1), " "); got != expected {
+				t.Errorf("%s: message %s has verbs %q, expected %q", locale, id, got, expected)
+			}
+		}
+		for id := range catalog {
+			if _, ok := english[id]; !ok {
+				t.Errorf("%s: message %s has no English original", locale, id)
+			}
+		}
+	}
+}
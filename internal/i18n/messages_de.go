@@ -0,0 +1,190 @@
+// ABOUTME: German message catalog
+// ABOUTME: Format verbs must match the English messages in number and order
+package i18n
+
+var german = map[string]string{
+	"auth.starting":              "Dragonglass-Anmeldung wird gestartet...",
+	"auth.complete":              "Anmeldung abgeschlossen! Sie können jetzt auf die GitHub Container Registry zugreifen.",
+	"auth.required":              "Für den Zugriff auf die GitHub Container Registry ist eine Anmeldung erforderlich",
+	"auth.required_detail":       "Dragonglass benötigt die Berechtigung, Pakete von ghcr.io zu lesen",
+	"auth.run_auth":              "Bitte ausführen: dragonglass auth",
+	"auth.stored_keychain":       "Token sicher im Schlüsselbund des Betriebssystems gespeichert",
+	"auth.stored_file":           "Token in der Anmeldedatendatei gespeichert",
+	"auth.stored_file_fallback":  "Token in der Anmeldedatendatei gespeichert (Schlüsselbund nicht verfügbar)",
+	"auth.device.start":          "GitHub-Geräteanmeldung wird gestartet",
+	"auth.device.scopes":         "Angeforderte Berechtigungen: %s",
+	"auth.device.steps":          "Bitte führen Sie die folgenden Schritte aus:",
+	"auth.device.code_title":     "Code",
+	"auth.device.visit":          "Öffnen Sie: %s",
+	"auth.device.confirm_code":   "Bestätigen Sie, dass der angezeigte Code übereinstimmt: %s",
+	"auth.device.copy_code":      "Kopieren Sie den obigen Code: %s",
+	"auth.device.enter_code":     "Geben Sie den Code ein, wenn Sie dazu aufgefordert werden",
+	"auth.device.browser_failed": "Browser konnte nicht geöffnet werden (%v); öffnen Sie die obige URL manuell",
+	"auth.device.browser_opened": "Die Bestätigungsseite wurde in Ihrem Browser geöffnet",
+	"auth.device.waiting":        "Warte auf Autorisierung... Code läuft ab in %d:%02d",
+	"auth.device.token_failed":   "Zugriffstoken konnte nicht abgerufen werden",
+	"auth.device.success":        "Anmeldung erfolgreich!",
+	"auth.device.token_scopes":   "Token mit folgenden Berechtigungen erhalten: %s",
+	"auth.unknown_user":          "angemeldeter Benutzer",
+	"auth.already":               "Bereits angemeldet als %s",
+	"auth.registry":              "Konfigurierte Registry: %s",
+	"auth.status_hint":           "Details mit 'dragonglass auth status' anzeigen",
+	"auth.not_authenticated":     "Nicht bei GitHub angemeldet (Anmeldedatenspeicher: %s)",
+	"auth.run_auth_hint":         "Führen Sie 'dragonglass auth' aus, um sich anzumelden",
+	"auth.set_env_hint":          "Setzen Sie %s, um sich anzumelden",
+	"auth.logout_not_persistent": "Der Anmeldedatenspeicher %q speichert keine Anmeldedaten; entfernen Sie die Token-Umgebungsvariablen, um sich abzumelden",
+	"auth.not_currently":         "Derzeit nicht angemeldet",
+	"auth.logged_out":            "%s erfolgreich abgemeldet",
+	"auth.credentials_removed":   "Alle gespeicherten Anmeldedaten wurden entfernt",
+	"auth.scope.missing":         "Ihrer GitHub-Anmeldung fehlt der für diesen Befehl nötige Scope %s",
+	"auth.scope.prompt":          "Erneut anmelden, um ihn hinzuzufügen? Die aktuelle Anmeldung bleibt bis zum Erfolg der neuen erhalten",
+	"auth.scope.adding":          "Erneute Anmeldung, um %s hinzuzufügen; die aktuelle Anmeldung bleibt bis zum Erfolg der neuen erhalten",
+
+	"config.invalid":       "Ungültige Konfiguration: %v",
+	"config.reopen":        "Editor erneut öffnen, um sie zu korrigieren?",
+	"config.header.key":    "SCHLÜSSEL",
+	"config.header.value":  "WERT",
+	"config.header.origin": "HERKUNFT",
+
+	"deps.section.tree":      "Abhängigkeiten von %s %s",
+	"deps.section.unrelated": "Pakete ohne erfassten Abhängigkeitspfad",
+	"deps.no_relationships":  "Die SBOM erfasst keine Abhängigkeitsbeziehungen; Pakete werden ohne Unterscheidung zwischen direkt und transitiv aufgeführt",
+	"deps.repeated":          "(Abhängigkeiten siehe oben)",
+	"deps.no_license":        "Lizenz unbekannt",
+	"deps.vulnerable":        "verwundbar: %s",
+	"deps.summary":           "%d Pakete: %d direkt, %d transitiv, %d mit bekannten Schwachstellen",
+
+	"explain.section.artifact":     "Artefakt %s %s",
+	"explain.section.attestation":  "Attestierung %d: %s",
+	"explain.section.provenance":   "Herkunftsnachweis",
+	"explain.section.policy":       "Richtlinie",
+	"explain.reference":            "Referenz",
+	"explain.digest":               "Digest",
+	"explain.integrity":            "Integrität",
+	"explain.installed":            "Installiert",
+	"explain.no_bundles":           "Für dieses Artefakt wurden keine signierten Attestierungs-Bundles verifiziert",
+	"explain.predicate_type":       "Prädikattyp",
+	"explain.bundle_digest":        "Bundle-Digest",
+	"explain.certificate_identity": "Zertifikatsidentität",
+	"explain.certificate_issuer":   "Zertifikatsaussteller",
+	"explain.run":                  "Workflow-Lauf",
+	"explain.source":               "Quelle",
+	"explain.rekor_entry":          "Rekor-Eintrag",
+	"explain.rekor_logged":         "Log-Index %d, protokolliert %s",
+	"explain.rekor_url":            "Rekor-URL",
+	"explain.builder":              "Builder",
+	"explain.workflow":             "Workflow",
+	"explain.built":                "Gebaut",
+	"explain.strict_mode":          "Strikter Modus",
+	"explain.trusted_builder":      "Vertrauenswürdiger Builder",
+	"explain.pinned_builder":       "Festgelegter Builder",
+	"explain.pinned_repository":    "Festgelegtes Repository",
+	"explain.policy_hash":          "Richtlinien-Hash",
+	"explain.locked_policy_hash":   "Akzeptiert unter Richtlinie",
+	"explain.check":                "Prüfung %s",
+	"explain.verified":             "Die Vertrauenskette ist unter der aktuellen Richtlinie gültig",
+
+	"install.confirm.replace":       "Die oben aufgeführten installierten Plugins ersetzen?",
+	"install.confirm.replace_item":  "%s %s in %s",
+	"install.confirm.replace_one":   "Installiertes Plugin %s %s durch %s ersetzen?",
+	"install.confirm.adopt":         "%s in %s wurde nicht von dragonglass installiert. Durch die verifizierte Version %s ersetzen und im Lockfile festschreiben?",
+	"install.confirm.warnings":      "%s %s trotz %d Verifizierungswarnung(en) installieren?",
+	"install.confirm.ownership":     "%s aus %s hinzufügen, obwohl es möglicherweise nicht zu %s gehört?",
+	"install.confirm.retag":         "Das neu gepushte Artefakt von %s verifizieren und festschreiben?",
+	"install.confirm.retag_locked":  "Festgeschrieben: %s",
+	"install.confirm.retag_current": "Jetzt: %s",
+	"install.changelog.title":       "%s %s → %s",
+	"install.changelog.link":        "Vollständige Versionshinweise: %s",
+
+	"list.header.id":                   "ID",
+	"list.header.name":                 "NAME",
+	"list.header.version":              "VERSION",
+	"list.header.verified":             "VERIFIZIERT",
+	"list.header.status":               "STATUS",
+	"list.header.oci_reference":        "OCI-REFERENZ",
+	"list.header.trust":                "VERTRAUEN",
+	"list.header.repository":           "REPOSITORY",
+	"list.header.tag":                  "TAG",
+	"list.header.digest":               "DIGEST",
+	"list.header.provenance":           "HERKUNFT",
+	"list.header.sbom":                 "SBOM",
+	"list.remote.error":                "Fehler",
+	"list.yes":                         "Ja",
+	"list.no":                          "Nein",
+	"list.trust.vulns":                 "%d Schwachstellen",
+	"list.trust.not_scanned":           "nicht gescannt",
+	"list.detail.id":                   "ID",
+	"list.detail.name":                 "Name",
+	"list.detail.version":              "Version",
+	"list.detail.status":               "Status",
+	"list.detail.enabled":              "Aktiviert",
+	"list.detail.oci_reference":        "OCI-Referenz",
+	"list.detail.oci_digest":           "OCI-Digest",
+	"list.detail.config_digest":        "Config-Digest",
+	"list.detail.layer_digest":         "Layer-%d-Digest",
+	"list.detail.first_installed":      "Erstinstallation",
+	"list.detail.installed":            "Installiert",
+	"list.detail.provenance_verified":  "Herkunft verifiziert",
+	"list.detail.builder":              "Builder",
+	"list.detail.slsa_level":           "SLSA-Stufe",
+	"list.detail.source_repository":    "Quell-Repository",
+	"list.detail.source_commit":        "Quell-Commit",
+	"list.detail.source_tree":          "Quellcode",
+	"list.detail.sbom_verified":        "SBOM verifiziert",
+	"list.detail.sbom_format":          "SBOM-Format",
+	"list.detail.vuln_scan_passed":     "Schwachstellenprüfung bestanden",
+	"list.detail.vulnerabilities":      "Schwachstellen",
+	"list.detail.vulnerability_counts": "kritisch %d, hoch %d, mittel %d, niedrig %d",
+	"list.detail.verified":             "Verifiziert",
+	"list.detail.policy_hash":          "Richtlinien-Hash",
+	"list.detail.history":              "Verifizierung %s",
+	"list.history.passed":              "%s: bestanden",
+	"list.history.failed":              "%s: fehlgeschlagen",
+	"list.detail.file":                 "Datei %s",
+	"list.detail.warning":              "Warnung",
+	"list.detail.error":                "Fehler",
+	"list.detail.author":               "Autor",
+	"list.detail.description":          "Beschreibung",
+
+	"search.header.id":          "ID",
+	"search.header.name":        "NAME",
+	"search.header.latest":      "AKTUELL",
+	"search.header.repository":  "REPOSITORY",
+	"search.header.description": "BESCHREIBUNG",
+
+	"env.config_dir":       "Konfigurationsverzeichnis",
+	"env.user_config":      "Benutzerkonfiguration",
+	"env.cache_dir":        "Cache-Verzeichnis",
+	"env.tuf_cache":        "TUF-Cache",
+	"env.credential_store": "Anmeldedatenspeicher",
+	"env.keyring_service":  "Schlüsselbunddienst %s",
+	"env.credentials_file": "Datei %s",
+	"env.legacy_dir":       "Altes Verzeichnis",
+	"env.vault":            "Vault",
+	"env.no_vault":         "keiner (nicht in einem Obsidian-Vault)",
+	"env.vault_config":     "Vault-Konfiguration",
+	"env.lockfile":         "Lockfile",
+	"env.dragonglass_dir":  "Dragonglass-Verzeichnis",
+
+	"lint.header.code":     "CODE",
+	"lint.header.severity": "SCHWEREGRAD",
+	"lint.header.file":     "DATEI",
+	"lint.header.message":  "MELDUNG",
+	"lint.no_findings":     "%s ist installierbar, keine Befunde",
+
+	"stats.since":           "Seit",
+	"stats.verified":        "Verifiziert",
+	"stats.failed":          "Richtlinie verletzt",
+	"stats.errors":          "Fehler",
+	"stats.mean_latency":    "Mittlere Verifizierungsdauer",
+	"stats.downloaded":      "Heruntergeladen",
+	"stats.cache_hit_ratio": "Cache-Trefferquote",
+	"stats.cache_lookups":   "%.0f%% von %d Abfragen",
+	"stats.none":            "Noch keine Statistiken erfasst",
+	"stats.reset":           "Statistiken zurückgesetzt",
+
+	"prompt.choices":     "[j/N]",
+	"prompt.yes":         "ja",
+	"prompt.no_terminal": "kein Terminal für die Bestätigung",
+	"prompt.declined":    "abgelehnt",
+}
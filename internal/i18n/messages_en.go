@@ -0,0 +1,190 @@
+// ABOUTME: English message catalog, the reference every other locale is checked against
+// ABOUTME: Message IDs are grouped by the command or package that shows them
+package i18n
+
+var english = map[string]string{
+	"auth.starting":              "Starting dragonglass authentication...",
+	"auth.complete":              "Authentication complete! You can now access GitHub Container Registry.",
+	"auth.required":              "Authentication required to access GitHub Container Registry",
+	"auth.required_detail":       "Dragonglass needs permission to read packages from ghcr.io",
+	"auth.run_auth":              "Please run: dragonglass auth",
+	"auth.stored_keychain":       "Token stored securely in OS keychain",
+	"auth.stored_file":           "Token stored in credentials file",
+	"auth.stored_file_fallback":  "Token stored in credentials file (keychain unavailable)",
+	"auth.device.start":          "Starting GitHub device flow authentication",
+	"auth.device.scopes":         "Requesting scopes: %s",
+	"auth.device.steps":          "Please complete the following steps:",
+	"auth.device.code_title":     "Code",
+	"auth.device.visit":          "Visit: %s",
+	"auth.device.confirm_code":   "Confirm the code shown matches: %s",
+	"auth.device.copy_code":      "Copy the code above: %s",
+	"auth.device.enter_code":     "Enter the code when prompted",
+	"auth.device.browser_failed": "Could not open a browser (%v); open the URL above manually",
+	"auth.device.browser_opened": "Opened the verification page in your browser",
+	"auth.device.waiting":        "Waiting for authorization... code expires in %d:%02d",
+	"auth.device.token_failed":   "Failed to get access token",
+	"auth.device.success":        "Authentication successful!",
+	"auth.device.token_scopes":   "Received token with scopes: %s",
+	"auth.unknown_user":          "authenticated user",
+	"auth.already":               "Already authenticated as %s",
+	"auth.registry":              "Registry configured: %s",
+	"auth.status_hint":           "Use 'dragonglass auth status' to view details",
+	"auth.not_authenticated":     "Not authenticated with GitHub (credential store: %s)",
+	"auth.run_auth_hint":         "Run 'dragonglass auth' to authenticate",
+	"auth.set_env_hint":          "Set %s to authenticate",
+	"auth.logout_not_persistent": "Credential store %q keeps no credentials; unset the token environment variables to sign out",
+	"auth.not_currently":         "Not currently authenticated",
+	"auth.logged_out":            "Successfully logged out %s",
+	"auth.credentials_removed":   "All stored credentials have been removed",
+	"auth.scope.missing":         "Your GitHub sign-in lacks the %s scope needed for this command",
+	"auth.scope.prompt":          "Sign in again to add it? Your current sign-in is kept until the new one succeeds",
+	"auth.scope.adding":          "Signing in again to add %s; your current sign-in is kept until the new one succeeds",
+
+	"config.invalid":       "Invalid configuration: %v",
+	"config.reopen":        "Re-open the editor to fix it?",
+	"config.header.key":    "KEY",
+	"config.header.value":  "VALUE",
+	"config.header.origin": "ORIGIN",
+
+	"deps.section.tree":      "Dependencies of %s %s",
+	"deps.section.unrelated": "Packages without a recorded dependency path",
+	"deps.no_relationships":  "The SBOM records no dependency relationships; packages are listed without telling direct from transitive",
+	"deps.repeated":          "(dependencies listed above)",
+	"deps.no_license":        "license unknown",
+	"deps.vulnerable":        "vulnerable: %s",
+	"deps.summary":           "%d packages: %d direct, %d transitive, %d with known vulnerabilities",
+
+	"explain.section.artifact":     "Artifact %s %s",
+	"explain.section.attestation":  "Attestation %d: %s",
+	"explain.section.provenance":   "Provenance",
+	"explain.section.policy":       "Policy",
+	"explain.reference":            "Reference",
+	"explain.digest":               "Digest",
+	"explain.integrity":            "Integrity",
+	"explain.installed":            "Installed",
+	"explain.no_bundles":           "No signed attestation bundles were verified for this artifact",
+	"explain.predicate_type":       "Predicate type",
+	"explain.bundle_digest":        "Bundle digest",
+	"explain.certificate_identity": "Certificate identity",
+	"explain.certificate_issuer":   "Certificate issuer",
+	"explain.run":                  "Workflow run",
+	"explain.source":               "Source",
+	"explain.rekor_entry":          "Rekor entry",
+	"explain.rekor_logged":         "log index %d, logged %s",
+	"explain.rekor_url":            "Rekor URL",
+	"explain.builder":              "Builder",
+	"explain.workflow":             "Workflow",
+	"explain.built":                "Built",
+	"explain.strict_mode":          "Strict mode",
+	"explain.trusted_builder":      "Trusted builder",
+	"explain.pinned_builder":       "Pinned builder",
+	"explain.pinned_repository":    "Pinned repository",
+	"explain.policy_hash":          "Policy hash",
+	"explain.locked_policy_hash":   "Accepted under policy",
+	"explain.check":                "Check %s",
+	"explain.verified":             "The trust chain holds under the current policy",
+
+	"install.confirm.replace":       "Replace the installed plugins listed above?",
+	"install.confirm.replace_item":  "%s %s in %s",
+	"install.confirm.replace_one":   "Replace installed plugin %s %s with %s?",
+	"install.confirm.adopt":         "%s in %s was not installed by dragonglass. Replace it with verified version %s and pin it in the lockfile?",
+	"install.confirm.warnings":      "Install %s %s despite %d verification warning(s)?",
+	"install.confirm.ownership":     "Add %s from %s, which may not belong to %s?",
+	"install.confirm.retag":         "Verify and pin the re-pushed artifact of %s?",
+	"install.confirm.retag_locked":  "Locked: %s",
+	"install.confirm.retag_current": "Now: %s",
+	"install.changelog.title":       "%s %s → %s",
+	"install.changelog.link":        "Full release notes: %s",
+
+	"list.header.id":                   "ID",
+	"list.header.name":                 "NAME",
+	"list.header.version":              "VERSION",
+	"list.header.verified":             "VERIFIED",
+	"list.header.status":               "STATUS",
+	"list.header.oci_reference":        "OCI REFERENCE",
+	"list.header.trust":                "TRUST",
+	"list.header.repository":           "REPOSITORY",
+	"list.header.tag":                  "TAG",
+	"list.header.digest":               "DIGEST",
+	"list.header.provenance":           "PROVENANCE",
+	"list.header.sbom":                 "SBOM",
+	"list.remote.error":                "error",
+	"list.yes":                         "Yes",
+	"list.no":                          "No",
+	"list.trust.vulns":                 "%d vulns",
+	"list.trust.not_scanned":           "not scanned",
+	"list.detail.id":                   "ID",
+	"list.detail.name":                 "Name",
+	"list.detail.version":              "Version",
+	"list.detail.status":               "Status",
+	"list.detail.enabled":              "Enabled",
+	"list.detail.oci_reference":        "OCI reference",
+	"list.detail.oci_digest":           "OCI digest",
+	"list.detail.config_digest":        "Config digest",
+	"list.detail.layer_digest":         "Layer %d digest",
+	"list.detail.first_installed":      "First installed",
+	"list.detail.installed":            "Installed",
+	"list.detail.provenance_verified":  "Provenance verified",
+	"list.detail.builder":              "Builder",
+	"list.detail.slsa_level":           "SLSA level",
+	"list.detail.source_repository":    "Source repository",
+	"list.detail.source_commit":        "Source commit",
+	"list.detail.source_tree":          "Source tree",
+	"list.detail.sbom_verified":        "SBOM verified",
+	"list.detail.sbom_format":          "SBOM format",
+	"list.detail.vuln_scan_passed":     "Vulnerability scan passed",
+	"list.detail.vulnerabilities":      "Vulnerabilities",
+	"list.detail.vulnerability_counts": "critical %d, high %d, medium %d, low %d",
+	"list.detail.verified":             "Verified",
+	"list.detail.policy_hash":          "Policy hash",
+	"list.detail.history":              "Verification %s",
+	"list.history.passed":              "%s: passed",
+	"list.history.failed":              "%s: failed",
+	"list.detail.file":                 "File %s",
+	"list.detail.warning":              "Warning",
+	"list.detail.error":                "Error",
+	"list.detail.author":               "Author",
+	"list.detail.description":          "Description",
+
+	"search.header.id":          "ID",
+	"search.header.name":        "NAME",
+	"search.header.latest":      "LATEST",
+	"search.header.repository":  "REPOSITORY",
+	"search.header.description": "DESCRIPTION",
+
+	"env.config_dir":       "Config dir",
+	"env.user_config":      "User config",
+	"env.cache_dir":        "Cache dir",
+	"env.tuf_cache":        "TUF cache",
+	"env.credential_store": "Credential store",
+	"env.keyring_service":  "keyring service %s",
+	"env.credentials_file": "file %s",
+	"env.legacy_dir":       "Legacy dir",
+	"env.vault":            "Vault",
+	"env.no_vault":         "none (not inside an Obsidian vault)",
+	"env.vault_config":     "Vault config",
+	"env.lockfile":         "Lockfile",
+	"env.dragonglass_dir":  "Dragonglass dir",
+
+	"lint.header.code":     "CODE",
+	"lint.header.severity": "SEVERITY",
+	"lint.header.file":     "FILE",
+	"lint.header.message":  "MESSAGE",
+	"lint.no_findings":     "%s is installable, no findings",
+
+	"stats.since":           "Since",
+	"stats.verified":        "Verified",
+	"stats.failed":          "Failed policy",
+	"stats.errors":          "Errors",
+	"stats.mean_latency":    "Mean verification time",
+	"stats.downloaded":      "Downloaded",
+	"stats.cache_hit_ratio": "Cache hit ratio",
+	"stats.cache_lookups":   "%.0f%% of %d lookups",
+	"stats.none":            "No statistics recorded yet",
+	"stats.reset":           "Statistics reset",
+
+	"prompt.choices":     "[y/N]",
+	"prompt.yes":         "yes",
+	"prompt.no_terminal": "no terminal to confirm on",
+	"prompt.declined":    "declined",
+}
@@ -0,0 +1,40 @@
+// ABOUTME: CommitChecker backed by the GitHub REST API
+// ABOUTME: Looks up a pinned commit in the upstream repository of an index entry
+package index
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/githubapi"
+)
+
+// GitHubChecker looks up commits with the GitHub REST API
+type GitHubChecker struct {
+	github *githubapi.Client
+}
+
+// NewGitHubChecker returns a checker looking up commits with github
+func NewGitHubChecker(github *githubapi.Client) *GitHubChecker {
+	return &GitHubChecker{github: github}
+}
+
+// CommitExists implements CommitChecker. A repository that does not exist has no commits.
+func (c *GitHubChecker) CommitExists(ctx context.Context, repository, commit string) (bool, error) {
+	resp, err := c.github.Get(ctx, fmt.Sprintf("/repos/%s/commits/%s", repository, commit))
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch commit %s of %s: %w", commit, repository, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	// 422 is returned for a SHA the repository does not contain
+	case http.StatusNotFound, http.StatusUnprocessableEntity:
+		return false, nil
+	default:
+		return false, githubapi.StatusError(resp, "commits", repository)
+	}
+}
@@ -0,0 +1,293 @@
+// ABOUTME: Plugins index of build configs at plugins/<owner>/<repo>/tags/<tag>/build.json
+// ABOUTME: Loads and validates the configs: schema, layout, upstream commits, and conflicting tags
+package index
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// Dir is the index directory, relative to the repository root
+	Dir = "plugins"
+
+	// ConfigFile is the build config of a tag
+	ConfigFile = "build.json"
+
+	// ConfigVersion is the schemas/build.v1.json version
+	ConfigVersion = "1"
+)
+
+var commitPattern = regexp.MustCompile(`^[a-f0-9]{40}$`)
+
+// Severity of a problem; errors fail validation, warnings do not
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Checks a problem can come from
+const (
+	CheckLayout   = "layout"
+	CheckSchema   = "schema"
+	CheckCommit   = "commit"
+	CheckConflict = "conflict"
+)
+
+// BuildConfig is a build config (schemas/build.v1.json)
+type BuildConfig struct {
+	Version         string `json:"version"`
+	Commit          string `json:"commit"`
+	PluginDirectory string `json:"pluginDirectory,omitempty"`
+	BuildDirectory  string `json:"buildDirectory,omitempty"`
+	OutputDirectory string `json:"outputDirectory,omitempty"`
+}
+
+// Entry is a build config and the repository and tag its path names
+type Entry struct {
+	// Path of the config relative to the repository root, with forward slashes
+	Path string `json:"path"`
+
+	// Repository as owner/repo
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+
+	Config BuildConfig `json:"config"`
+}
+
+// Problem is a finding of validation
+type Problem struct {
+	Path     string   `json:"path"`
+	Severity Severity `json:"severity"`
+	Check    string   `json:"check"`
+	Message  string   `json:"message"`
+}
+
+// Result is the outcome of validating an index
+type Result struct {
+	// Build configs that parsed and passed the schema
+	Entries []Entry `json:"entries"`
+
+	Problems []Problem `json:"problems"`
+}
+
+// Valid reports whether validation found no errors
+func (r *Result) Valid() bool {
+	return r.Count(SeverityError) == 0
+}
+
+// Count returns the number of problems of severity
+func (r *Result) Count(severity Severity) int {
+	count := 0
+	for _, problem := range r.Problems {
+		if problem.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *Result) add(path string, severity Severity, check, format string, args ...any) {
+	r.Problems = append(r.Problems, Problem{Path: path, Severity: severity, Check: check, Message: fmt.Sprintf(format, args...)})
+}
+
+// CommitChecker looks up commits in upstream repositories
+type CommitChecker interface {
+	// CommitExists reports whether commit exists in repository (owner/repo)
+	CommitExists(ctx context.Context, repository, commit string) (bool, error)
+}
+
+// Opts configures validation
+type Opts struct {
+	// Checks that pinned commits exist upstream; nil skips the check
+	Checker CommitChecker
+}
+
+// DefaultOpts returns options that validate the index offline
+func DefaultOpts() *Opts {
+	return &Opts{}
+}
+
+// WithCommitChecker sets the checker pinned commits are looked up with
+func (opts *Opts) WithCommitChecker(checker CommitChecker) *Opts {
+	opts.Checker = checker
+	return opts
+}
+
+// Validate validates the index in the repository at root
+func Validate(ctx context.Context, root string, opts *Opts) (*Result, error) {
+	if opts == nil {
+		opts = DefaultOpts()
+	}
+
+	result, err := Load(root)
+	if err != nil {
+		return nil, err
+	}
+	checkConflicts(result)
+
+	if opts.Checker != nil {
+		for _, entry := range result.Entries {
+			exists, err := opts.Checker.CommitExists(ctx, entry.Repository, entry.Config.Commit)
+			switch {
+			case err != nil:
+				result.add(entry.Path, SeverityError, CheckCommit, "failed to look up commit %s in %s: %v", entry.Config.Commit, entry.Repository, err)
+			case !exists:
+				result.add(entry.Path, SeverityError, CheckCommit, "commit %s does not exist in %s", entry.Config.Commit, entry.Repository)
+			}
+		}
+	}
+	return result, nil
+}
+
+// Load reads every build config in the index at root, reporting files out of place, tag
+// directories without a config, and configs that do not match the schema. Only configs
+// that do are returned as entries.
+func Load(root string) (*Result, error) {
+	result := &Result{Entries: []Entry{}, Problems: []Problem{}}
+	indexDir := filepath.Join(root, Dir)
+	if info, err := os.Stat(indexDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("no %s directory in %s", Dir, root)
+	}
+
+	err := filepath.WalkDir(indexDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, file)
+		rel = filepath.ToSlash(rel)
+		parts := strings.Split(rel, "/")
+
+		if d.IsDir() {
+			// plugins/<owner>/<repo> must contain tags, and each tag directory a config
+			switch {
+			case len(parts) == 3:
+				if _, err := os.Stat(filepath.Join(file, "tags")); err != nil {
+					result.add(rel, SeverityError, CheckLayout, "repository directory has no tags directory")
+				}
+			case len(parts) == 5 && parts[3] == "tags":
+				if _, err := os.Stat(filepath.Join(file, ConfigFile)); err != nil {
+					result.add(rel, SeverityError, CheckLayout, "tag directory has no %s", ConfigFile)
+				}
+			}
+			return nil
+		}
+
+		if d.Name() != ConfigFile {
+			// READMEs and the like may live alongside the index
+			return nil
+		}
+		if len(parts) != 6 || parts[3] != "tags" {
+			result.add(rel, SeverityError, CheckLayout, "expected %s at %s/<owner>/<repo>/tags/<tag>/%s", ConfigFile, Dir, ConfigFile)
+			return nil
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		config, problems := parseConfig(data)
+		for _, problem := range problems {
+			result.add(rel, SeverityError, CheckSchema, "%s", problem)
+		}
+		if len(problems) == 0 {
+			result.Entries = append(result.Entries, Entry{
+				Path:       rel,
+				Repository: parts[1] + "/" + parts[2],
+				Tag:        parts[4],
+				Config:     config,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", indexDir, err)
+	}
+	return result, nil
+}
+
+// parseConfig parses a build config and returns every way it breaks the schema
+func parseConfig(data []byte) (BuildConfig, []string) {
+	var config BuildConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return config, []string{fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return config, []string{"invalid JSON: unexpected data after the config object"}
+	}
+
+	var problems []string
+	if config.Version != ConfigVersion {
+		problems = append(problems, fmt.Sprintf("version must be %q, got %q", ConfigVersion, config.Version))
+	}
+	if !commitPattern.MatchString(config.Commit) {
+		problems = append(problems, fmt.Sprintf("commit must be a 40 character lowercase hex SHA, got %q", config.Commit))
+	}
+	for _, dir := range []struct{ name, value string }{
+		{"pluginDirectory", config.PluginDirectory},
+		{"buildDirectory", config.BuildDirectory},
+		{"outputDirectory", config.OutputDirectory},
+	} {
+		if dir.value == "" {
+			continue
+		}
+		clean := path.Clean(strings.ReplaceAll(dir.value, `\`, "/"))
+		if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+			problems = append(problems, fmt.Sprintf("%s must be a relative path inside the repository, got %q", dir.name, dir.value))
+		}
+	}
+	return config, problems
+}
+
+// checkConflicts reports repositories whose directories differ only in case, which the
+// registry lowercases to the same name, and tags of one repository naming the same
+// version: with different commits they conflict, with the same commit they duplicate
+func checkConflicts(result *Result) {
+	byRepository := make(map[string][]Entry)
+	for _, entry := range result.Entries {
+		key := strings.ToLower(entry.Repository)
+		byRepository[key] = append(byRepository[key], entry)
+	}
+
+	keys := make([]string, 0, len(byRepository))
+	for key := range byRepository {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		entries := byRepository[key]
+		for i, entry := range entries {
+			if entry.Repository != entries[0].Repository {
+				result.add(entry.Path, SeverityError, CheckConflict, "repository %s differs from %s only in case; registry names are lowercase, so both publish to the same place", entry.Repository, entries[0].Repository)
+			}
+
+			version := strings.TrimPrefix(entry.Tag, "v")
+			for _, other := range entries[:i] {
+				if strings.TrimPrefix(other.Tag, "v") != version {
+					continue
+				}
+				if other.Config.Commit != entry.Config.Commit {
+					result.add(entry.Path, SeverityError, CheckConflict, "tag %s names the same version as %s but pins commit %s instead of %s", entry.Tag, other.Path, entry.Config.Commit, other.Config.Commit)
+				} else {
+					result.add(entry.Path, SeverityWarning, CheckConflict, "tag %s duplicates %s", entry.Tag, other.Path)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/githubapi"
+)
+
+const (
+	commitA = "38d5d3fa799e1eec8f10b137eee8b2cbdc73b534"
+	commitB = "7a04a26631376a505929d44bf238646bb5c20f5d"
+)
+
+func writeIndex(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if content == "" {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func config(commit string) string {
+	return `{"version": "1", "commit": "` + commit + `"}`
+}
+
+type fakeChecker map[string]error
+
+func (f fakeChecker) CommitExists(_ context.Context, repository, commit string) (bool, error) {
+	err, ok := f[repository+"@"+commit]
+	if !ok {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		checker  CommitChecker
+		entries  int
+		problems []string
+	}{
+		{
+			name: "valid",
+			files: map[string]string{
+				"plugins/owner/repo/tags/1.0.0/build.json": config(commitA),
+				"plugins/owner/repo/tags/1.1.0/build.json": `{"version": "1", "commit": "` + commitB + `", "buildDirectory": "build"}`,
+			},
+			entries: 2,
+		},
+		{
+			name: "schema",
+			files: map[string]string{
+				"plugins/owner/repo/tags/1.0.0/build.json": `{"version": "2", "commit": "abc123"}`,
+				"plugins/owner/repo/tags/1.1.0/build.json": `{"version": "1", "commit": "` + commitA + `", "extra": true}`,
+				"plugins/owner/repo/tags/1.2.0/build.json": `{"version": "1", "commit": "` + commitA + `", "pluginDirectory": "../elsewhere"}`,
+			},
+			problems: []string{
+				"error schema plugins/owner/repo/tags/1.0.0/build.json",
+				"error schema plugins/owner/repo/tags/1.0.0/build.json",
+				"error schema plugins/owner/repo/tags/1.1.0/build.json",
+				"error schema plugins/owner/repo/tags/1.2.0/build.json",
+			},
+		},
+		{
+			name: "layout",
+			files: map[string]string{
+				"plugins/owner/repo/build.json":     config(commitA),
+				"plugins/owner/repo/tags/1.0.0/x":   "",
+				"plugins/owner/other/README.md":     "docs",
+				"plugins/owner/repo/tags/README.md": "docs",
+			},
+			problems: []string{
+				"error layout plugins/owner/other",
+				"error layout plugins/owner/repo/build.json",
+				"error layout plugins/owner/repo/tags/1.0.0",
+			},
+		},
+		{
+			name: "conflicting and duplicate tags",
+			files: map[string]string{
+				"plugins/owner/repo/tags/1.0.0/build.json":  config(commitA),
+				"plugins/owner/repo/tags/v1.0.0/build.json": config(commitB),
+				"plugins/owner/repo/tags/2.0.0/build.json":  config(commitB),
+				"plugins/owner/repo/tags/v2.0.0/build.json": config(commitB),
+			},
+			entries: 4,
+			problems: []string{
+				"error conflict plugins/owner/repo/tags/v1.0.0/build.json",
+				"warning conflict plugins/owner/repo/tags/v2.0.0/build.json",
+			},
+		},
+		{
+			name: "upstream commits",
+			files: map[string]string{
+				"plugins/owner/repo/tags/1.0.0/build.json": config(commitA),
+				"plugins/owner/repo/tags/1.1.0/build.json": config(commitB),
+				"plugins/owner/gone/tags/1.0.0/build.json": config(commitA),
+			},
+			checker: fakeChecker{
+				"owner/repo@" + commitA: nil,
+				"owner/gone@" + commitA: errors.New("rate limited"),
+			},
+			entries: 3,
+			problems: []string{
+				"error commit plugins/owner/gone/tags/1.0.0/build.json",
+				"error commit plugins/owner/repo/tags/1.1.0/build.json",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeIndex(t, tt.files)
+			result, err := Validate(context.Background(), root, DefaultOpts().WithCommitChecker(tt.checker))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result.Entries) != tt.entries {
+				t.Errorf("expected %d entries, got %d", tt.entries, len(result.Entries))
+			}
+			var problems []string
+			for _, problem := range result.Problems {
+				problems = append(problems, string(problem.Severity)+" "+problem.Check+" "+problem.Path)
+			}
+			slices.Sort(problems)
+			if !slices.Equal(problems, tt.problems) {
+				t.Errorf("expected problems %v, got %v", tt.problems, result.Problems)
+			}
+			if valid := !slices.ContainsFunc(tt.problems, func(p string) bool { return strings.HasPrefix(p, "error") }); result.Valid() != valid {
+				t.Errorf("expected valid %v", valid)
+			}
+		})
+	}
+}
+
+func TestCheckConflictsCase(t *testing.T) {
+	// Built in memory, as case-insensitive file systems cannot hold both directories
+	result := &Result{Entries: []Entry{
+		{Path: "plugins/Owner/Repo/tags/1.0.0/build.json", Repository: "Owner/Repo", Tag: "1.0.0", Config: BuildConfig{Commit: commitA}},
+		{Path: "plugins/owner/repo/tags/1.1.0/build.json", Repository: "owner/repo", Tag: "1.1.0", Config: BuildConfig{Commit: commitB}},
+	}}
+	checkConflicts(result)
+
+	if len(result.Problems) != 1 || result.Problems[0].Path != "plugins/owner/repo/tags/1.1.0/build.json" || result.Problems[0].Check != CheckConflict {
+		t.Errorf("expected a conflict for the second repository, got %v", result.Problems)
+	}
+}
+
+func TestValidateWithoutIndex(t *testing.T) {
+	if _, err := Validate(context.Background(), t.TempDir(), nil); err == nil {
+		t.Error("expected an error without a plugins directory")
+	}
+}
+
+func TestGitHubChecker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("expected token to be sent, got %q", r.Header.Get("Authorization"))
+		}
+		switch r.URL.Path {
+		case "/repos/owner/repo/commits/" + commitA:
+			w.WriteHeader(http.StatusOK)
+		case "/repos/owner/repo/commits/" + commitB:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		case "/repos/owner/limited/commits/" + commitA:
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	checker := NewGitHubChecker(githubapi.NewClient(githubapi.DefaultOpts().WithAPIURL(server.URL).WithToken("secret")))
+
+	tests := []struct {
+		repository string
+		commit     string
+		exists     bool
+		wantErr    bool
+	}{
+		{repository: "owner/repo", commit: commitA, exists: true},
+		{repository: "owner/repo", commit: commitB},
+		{repository: "owner/missing", commit: commitA},
+		{repository: "owner/limited", commit: commitA, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repository+"@"+tt.commit[:7], func(t *testing.T) {
+			exists, err := checker.CommitExists(context.Background(), tt.repository, tt.commit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if exists != tt.exists {
+				t.Errorf("expected exists %v, got %v", tt.exists, exists)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// ABOUTME: DSSE envelopes signing in-toto statements with a local key
+// ABOUTME: Loads PEM private keys and verifies envelopes against the matching public key
+package intoto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// PayloadType is the DSSE payload type of in-toto statements
+const PayloadType = "application/vnd.in-toto+json"
+
+// Envelope is a DSSE envelope carrying a signed statement
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is one signature over the envelope's pre-authentication encoding
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// Signer signs envelopes with a private key
+type Signer struct {
+	key   crypto.Signer
+	keyID string
+}
+
+// LoadSigner reads a PEM encoded ECDSA or Ed25519 private key (PKCS #8 or SEC 1)
+func LoadSigner(path string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not PEM encoded", path)
+	}
+
+	var key any
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %q (want an ECDSA or Ed25519 private key)", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		return NewSigner(key)
+	case ed25519.PrivateKey:
+		return NewSigner(key)
+	default:
+		return nil, fmt.Errorf("unsupported signing key algorithm %T (want ECDSA or Ed25519)", key)
+	}
+}
+
+// LoadPublicKey reads a PEM encoded ECDSA or Ed25519 public key (PKIX)
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("public key %s is not a PEM encoded PUBLIC KEY", path)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	switch key := key.(type) {
+	case *ecdsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key algorithm %T (want ECDSA or Ed25519)", key)
+	}
+}
+
+// NewSigner creates a signer for an ECDSA or Ed25519 private key. The key ID is the
+// SHA-256 of the DER encoded public key.
+func NewSigner(key crypto.Signer) (*Signer, error) {
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return &Signer{key: key, keyID: hex.EncodeToString(sum[:])}, nil
+}
+
+// KeyID identifies the signer's public key
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// Sign wraps statement in a DSSE envelope signed by s
+func (s *Signer) Sign(statement *Statement) (*Envelope, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode statement: %w", err)
+	}
+	return s.SignPayload(payload)
+}
+
+// SignPayload wraps an encoded statement in a DSSE envelope signed by s
+func (s *Signer) SignPayload(payload []byte) (*Envelope, error) {
+	sig, err := signMessage(s.key, PAE(PayloadType, payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign statement: %w", err)
+	}
+
+	return &Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []Signature{{KeyID: s.keyID, Sig: base64.StdEncoding.EncodeToString(sig)}},
+	}, nil
+}
+
+// Verify checks the envelope is signed by publicKey and returns its statement
+func (e *Envelope) Verify(publicKey crypto.PublicKey) (*Statement, error) {
+	payload, err := e.VerifyPayload(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var statement Statement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, fmt.Errorf("failed to decode statement: %w", err)
+	}
+	return &statement, nil
+}
+
+// VerifyPayload checks the envelope is signed by publicKey and returns the encoded
+// statement, for statements with predicates other than installs
+func (e *Envelope) VerifyPayload(publicKey crypto.PublicKey) ([]byte, error) {
+	if e.PayloadType != PayloadType {
+		return nil, fmt.Errorf("unexpected payload type %q", e.PayloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(e.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+	message := PAE(e.PayloadType, payload)
+
+	verified := false
+	for _, signature := range e.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(signature.Sig)
+		if err == nil && verifyMessage(publicKey, message, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("no valid signature for the given public key")
+	}
+	return payload, nil
+}
+
+// PAE is the DSSE pre-authentication encoding of a payload, which is what gets signed
+func PAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+func signMessage(key crypto.Signer, message []byte) ([]byte, error) {
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		return key.Sign(rand.Reader, message, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(message)
+	return key.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+func verifyMessage(publicKey crypto.PublicKey, message, sig []byte) bool {
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, message, sig)
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(message)
+		return ecdsa.VerifyASN1(key, digest[:], sig)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,278 @@
+package intoto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testStatement(t *testing.T) *Statement {
+	t.Helper()
+	statement, err := NewInstallStatement("sha256:abc123", InstallPredicate{
+		Vault:       "notes",
+		Plugin:      InstallPlugin{ID: "test-plugin", Name: "Test", Version: "1.0.0", OCIReference: "ghcr.io/owner/test-plugin:1.0.0"},
+		InstalledAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Policy:      InstallPolicy{Hash: "sha256:policy", StrictMode: true},
+		Files:       map[string]string{"main.js": "sha256:main"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return statement
+}
+
+func TestNewInstallStatement(t *testing.T) {
+	statement := testStatement(t)
+
+	if statement.Type != StatementType || statement.PredicateType != InstallPredicateType {
+		t.Errorf("unexpected statement types: %q, %q", statement.Type, statement.PredicateType)
+	}
+	if len(statement.Subject) != 1 || statement.Subject[0].Name != "ghcr.io/owner/test-plugin:1.0.0" || statement.Subject[0].Digest["sha256"] != "abc123" {
+		t.Errorf("unexpected subject: %+v", statement.Subject)
+	}
+
+	if _, err := NewInstallStatement("abc123", InstallPredicate{}); err == nil {
+		t.Error("expected error for digest without algorithm")
+	}
+}
+
+func TestPAE(t *testing.T) {
+	got := string(PAE("http://example.com/HelloWorld", []byte("hello world")))
+	expected := "DSSEv1 29 http://example.com/HelloWorld 11 hello world"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, key := range map[string]crypto.Signer{"ecdsa": ecKey, "ed25519": edKey} {
+		t.Run(name, func(t *testing.T) {
+			signer, err := NewSigner(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			envelope, err := signer.Sign(testStatement(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if envelope.Signatures[0].KeyID != signer.KeyID() {
+				t.Errorf("expected key id %s, got %s", signer.KeyID(), envelope.Signatures[0].KeyID)
+			}
+
+			statement, err := envelope.Verify(key.Public())
+			if err != nil {
+				t.Fatalf("unexpected verification error: %v", err)
+			}
+			if statement.Predicate.Plugin.ID != "test-plugin" {
+				t.Errorf("unexpected statement: %+v", statement)
+			}
+
+			// Tampering with the payload must invalidate the signature
+			tampered := *envelope
+			tampered.Payload = strings.Replace(envelope.Payload, "A", "B", 1)
+			if _, err := tampered.Verify(key.Public()); err == nil {
+				t.Error("expected tampered envelope to fail verification")
+			}
+		})
+	}
+}
+
+func TestLoadSigner(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		pem      string
+		errorMsg string
+	}{
+		{name: "SEC 1", pem: string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}))},
+		{name: "PKCS 8", pem: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))},
+		{name: "not PEM", pem: "not a key", errorMsg: "is not PEM encoded"},
+		{name: "public key", pem: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte{1}})), errorMsg: `unsupported signing key type "PUBLIC KEY"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "key.pem")
+			if err := os.WriteFile(path, []byte(tt.pem), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			signer, err := LoadSigner(path)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			envelope, err := signer.Sign(testStatement(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := envelope.Verify(ecKey.Public()); err != nil {
+				t.Errorf("expected envelope to verify with the loaded key: %v", err)
+			}
+		})
+	}
+}
+
+func TestSave(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "attestations")
+	installedAt := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	path, err := Save(dir, "test-plugin", installedAt, []byte(`{}`), EnvelopeExt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := filepath.Join(dir, "test-plugin-20250102T030405.000000006Z.intoto.json"); path != expected {
+		t.Errorf("expected %s, got %s", expected, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || !json.Valid(data) {
+		t.Errorf("expected saved attestation, got %q (%v)", data, err)
+	}
+}
+
+func TestNewSBOMStatement(t *testing.T) {
+	tests := []struct {
+		name     string
+		sbom     string
+		digest   string
+		errorMsg string
+	}{
+		{name: "spdx 2.3", sbom: `{"spdxVersion": "SPDX-2.3", "packages": [{"name": "left-pad"}]}`, digest: "sha256:abc123"},
+		{name: "spdx 2.2", sbom: `{"spdxVersion": "SPDX-2.2"}`, digest: "sha256:abc123", errorMsg: "unsupported SBOM version"},
+		{name: "cyclonedx", sbom: `{"bomFormat": "CycloneDX"}`, digest: "sha256:abc123", errorMsg: "want an SPDX-2.3 JSON document"},
+		{name: "not json", sbom: `sbom`, digest: "sha256:abc123", errorMsg: "not valid JSON"},
+		{name: "bad digest", sbom: `{"spdxVersion": "SPDX-2.3"}`, digest: "abc123", errorMsg: "invalid OCI digest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement, err := NewSBOMStatement("ghcr.io/owner/repo/test-plugin", tt.digest, []byte(tt.sbom))
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			data, err := json.Marshal(statement)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded struct {
+				PredicateType string    `json:"predicateType"`
+				Subject       []Subject `json:"subject"`
+				Predicate     struct {
+					SPDXVersion string `json:"spdxVersion"`
+				} `json:"predicate"`
+			}
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if decoded.PredicateType != SPDXPredicateType || decoded.Predicate.SPDXVersion != "SPDX-2.3" {
+				t.Errorf("unexpected statement: %s", data)
+			}
+			if decoded.Subject[0].Name != "ghcr.io/owner/repo/test-plugin" || decoded.Subject[0].Digest["sha256"] != "abc123" {
+				t.Errorf("unexpected subject: %+v", decoded.Subject)
+			}
+		})
+	}
+}
+
+func TestLoadPublicKey(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkix, err := x509.MarshalPKIXPublicKey(ecKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		pem      string
+		errorMsg string
+	}{
+		{name: "PKIX", pem: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}))},
+		{name: "not PEM", pem: "not a key", errorMsg: "is not a PEM encoded PUBLIC KEY"},
+		{name: "private key", pem: string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1})), errorMsg: "is not a PEM encoded PUBLIC KEY"},
+		{name: "malformed", pem: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte{1}})), errorMsg: "failed to parse public key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "key.pub")
+			if err := os.WriteFile(path, []byte(tt.pem), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			publicKey, err := LoadPublicKey(path)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			signer, err := NewSigner(ecKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			envelope, err := signer.Sign(testStatement(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := envelope.Verify(publicKey); err != nil {
+				t.Errorf("expected envelope to verify with the loaded public key: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package intoto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// FuzzEnvelopeVerify decodes and verifies DSSE envelopes. Only the seeded payload is
+// signed by the key, so any other payload verifying would be a forgery.
+func FuzzEnvelopeVerify(f *testing.F) {
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	signer, err := NewSigner(key)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	statement, err := NewInstallStatement("sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", InstallPredicate{
+		Vault:       "notes",
+		Plugin:      InstallPlugin{ID: "templater-obsidian", Name: "Templater", Version: "2.15.2", OCIReference: "ghcr.io/silentvoid13/templater/templater-obsidian:v2.15.2"},
+		InstalledAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Policy:      InstallPolicy{Hash: "sha256:0123", StrictMode: true},
+		Files:       map[string]string{"main.js": "sha256:60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		f.Fatal(err)
+	}
+	envelope, err := signer.SignPayload(payload)
+	if err != nil {
+		f.Fatal(err)
+	}
+	signed, err := json.Marshal(envelope)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Add(signed)
+	f.Add([]byte(`{"payloadType": "application/vnd.in-toto+json", "payload": "e30=", "signatures": []}`))
+	f.Add([]byte(`{"payloadType": "application/vnd.in-toto+json", "payload": "!", "signatures": [{"sig": "AAAA"}]}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var fuzzed Envelope
+		if err := json.Unmarshal(data, &fuzzed); err != nil {
+			return
+		}
+
+		if _, err := fuzzed.Verify(key.Public()); err != nil {
+			return
+		}
+		verified, err := base64.StdEncoding.DecodeString(fuzzed.Payload)
+		if err != nil || fuzzed.PayloadType != PayloadType || !bytes.Equal(verified, payload) {
+			t.Fatalf("envelope with payload %q verified", fuzzed.Payload)
+		}
+	})
+}
+
+// FuzzNewSBOMStatement wraps registry or build supplied SPDX documents in statements
+func FuzzNewSBOMStatement(f *testing.F) {
+	f.Add([]byte(`{
+  "spdxVersion": "SPDX-2.3",
+  "dataLicense": "CC0-1.0",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "name": "templater-obsidian",
+  "documentNamespace": "https://spdx.org/spdxdocs/templater-obsidian-2.15.2",
+  "creationInfo": {"created": "2026-01-02T03:04:05Z", "creators": ["Tool: npm/cli-10.8.2"]},
+  "packages": [{"name": "obsidian", "SPDXID": "SPDXRef-Package-obsidian-1.4.11", "versionInfo": "1.4.11", "downloadLocation": "NOASSERTION"}]
+}`))
+	f.Add([]byte(`{"spdxVersion": "SPDX-3.0"}`))
+	f.Add([]byte(`[]`))
+
+	f.Fuzz(func(t *testing.T, spdx []byte) {
+		statement, err := NewSBOMStatement("ghcr.io/silentvoid13/templater/templater-obsidian", "sha256:abc123", spdx)
+		if err != nil {
+			return
+		}
+		if _, err := json.Marshal(statement); err != nil {
+			t.Fatalf("statement does not encode: %v", err)
+		}
+	})
+}
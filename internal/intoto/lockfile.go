@@ -0,0 +1,68 @@
+// ABOUTME: in-toto statements signing a vault's lockfile as the reviewed plugin set
+// ABOUTME: Binds the signature to the exact lockfile bytes through the subject digest
+package intoto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// LockfilePredicateType identifies the lockfile signature predicate
+const LockfilePredicateType = "https://github.com/gillisandrew/dragonglass-poc/lockfile/v1"
+
+// LockfileStatement is an in-toto v1 statement that a lockfile was reviewed and signed
+type LockfileStatement struct {
+	Type          string            `json:"_type"`
+	Subject       []Subject         `json:"subject"`
+	PredicateType string            `json:"predicateType"`
+	Predicate     LockfilePredicate `json:"predicate"`
+}
+
+// LockfilePredicate records which vault's plugin set was signed and when
+type LockfilePredicate struct {
+	// Vault the lockfile belongs to
+	Vault string `json:"vault"`
+
+	// Number of plugins the lockfile pins
+	Plugins  int       `json:"plugins"`
+	SignedAt time.Time `json:"signedAt"`
+}
+
+// NewLockfileStatement creates a statement whose subject is the lockfile named name with
+// contents data
+func NewLockfileStatement(name string, data []byte, predicate LockfilePredicate) *LockfileStatement {
+	sum := sha256.Sum256(data)
+	return &LockfileStatement{
+		Type: StatementType,
+		Subject: []Subject{{
+			Name:   name,
+			Digest: map[string]string{"sha256": hex.EncodeToString(sum[:])},
+		}},
+		PredicateType: LockfilePredicateType,
+		Predicate:     predicate,
+	}
+}
+
+// CheckLockfileStatement decodes a verified statement payload and checks it signs the
+// lockfile contents data, so a signature over another lockfile or statement is rejected
+func CheckLockfileStatement(payload, data []byte) (*LockfileStatement, error) {
+	var statement LockfileStatement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, fmt.Errorf("failed to decode statement: %w", err)
+	}
+	if statement.PredicateType != LockfilePredicateType {
+		return nil, fmt.Errorf("statement has predicate type %q, not a lockfile signature", statement.PredicateType)
+	}
+
+	sum := sha256.Sum256(data)
+	expected := hex.EncodeToString(sum[:])
+	for _, subject := range statement.Subject {
+		if subject.Digest["sha256"] == expected {
+			return &statement, nil
+		}
+	}
+	return nil, fmt.Errorf("signature does not cover this lockfile (sha256:%s); it was changed after signing", expected)
+}
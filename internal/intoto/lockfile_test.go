@@ -0,0 +1,74 @@
+package intoto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockfileStatement(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := NewSigner(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lockfile := []byte(`{"version": "1", "plugins": {}}`)
+	statement := NewLockfileStatement("dragonglass-lock.json", lockfile, LockfilePredicate{
+		Vault:    "notes",
+		SignedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := signer.SignPayload(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verified, err := envelope.VerifyPayload(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	installPayload, err := json.Marshal(testStatement(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		payload  []byte
+		data     []byte
+		errorMsg string
+	}{
+		{name: "signed lockfile", payload: verified, data: lockfile},
+		{name: "changed lockfile", payload: verified, data: []byte(`{"version": "1", "plugins": {"evil": {}}}`), errorMsg: "changed after signing"},
+		{name: "install statement", payload: installPayload, data: lockfile, errorMsg: "not a lockfile signature"},
+		{name: "not json", payload: []byte("statement"), data: lockfile, errorMsg: "failed to decode statement"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement, err := CheckLockfileStatement(tt.payload, tt.data)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if statement.Predicate.Vault != "notes" || statement.Subject[0].Name != "dragonglass-lock.json" {
+				t.Errorf("unexpected statement: %+v", statement)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// ABOUTME: in-toto statements carrying the SPDX SBOM of a published plugin artifact
+// ABOUTME: Shaped like the statements actions/attest-sbom creates, so verification reads both
+package intoto
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SPDXPredicateType identifies an SPDX 2.3 document predicate
+const SPDXPredicateType = "https://spdx.dev/Document/v2.3"
+
+// SBOMStatement is an in-toto v1 statement whose predicate is an SPDX document
+type SBOMStatement struct {
+	Type          string          `json:"_type"`
+	Subject       []Subject       `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// NewSBOMStatement creates a statement about the OCI manifest ociDigest in repository
+// name (e.g. "ghcr.io/owner/repo/plugin-id") with spdx, an SPDX 2.3 JSON document, as
+// its predicate
+func NewSBOMStatement(name, ociDigest string, spdx []byte) (*SBOMStatement, error) {
+	var doc struct {
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(spdx, &doc); err != nil {
+		return nil, fmt.Errorf("SBOM is not valid JSON: %w", err)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" {
+		return nil, fmt.Errorf("unsupported SBOM version %q (want an SPDX-2.3 JSON document)", doc.SPDXVersion)
+	}
+
+	digest, err := subjectDigest(ociDigest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SBOMStatement{
+		Type:          StatementType,
+		Subject:       []Subject{{Name: name, Digest: digest}},
+		PredicateType: SPDXPredicateType,
+		Predicate:     spdx,
+	}, nil
+}
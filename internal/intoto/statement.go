@@ -0,0 +1,96 @@
+// ABOUTME: in-toto statements recording that a verified plugin was installed into a vault
+// ABOUTME: Complements build provenance with auditable install provenance
+package intoto
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	StatementType = "https://in-toto.io/Statement/v1"
+
+	// InstallPredicateType identifies the install event predicate
+	InstallPredicateType = "https://github.com/gillisandrew/dragonglass-poc/install/v1"
+)
+
+// Statement is an in-toto v1 statement about the subject artifacts
+type Statement struct {
+	Type          string           `json:"_type"`
+	Subject       []Subject        `json:"subject"`
+	PredicateType string           `json:"predicateType"`
+	Predicate     InstallPredicate `json:"predicate"`
+}
+
+// Subject identifies an artifact by name and digests keyed by algorithm
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// InstallPredicate records which plugin was installed where, when, and under what policy
+type InstallPredicate struct {
+	// Vault the plugin was installed into
+	Vault string `json:"vault"`
+
+	Plugin      InstallPlugin `json:"plugin"`
+	InstalledAt time.Time     `json:"installedAt"`
+
+	// Verification policy the plugin passed
+	Policy InstallPolicy `json:"policy"`
+
+	// Outcome of attestation verification at install
+	Verification InstallVerification `json:"verification"`
+
+	// SHA-256 digests of the installed plugin files, keyed by file name
+	Files map[string]string `json:"files,omitempty"`
+}
+
+type InstallPlugin struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	OCIReference string `json:"ociReference"`
+}
+
+type InstallPolicy struct {
+	Hash           string `json:"hash"`
+	StrictMode     bool   `json:"strictMode"`
+	TrustedBuilder string `json:"trustedBuilder,omitempty"`
+}
+
+type InstallVerification struct {
+	ProvenanceVerified bool   `json:"provenanceVerified"`
+	SBOMVerified       bool   `json:"sbomVerified"`
+	BuilderID          string `json:"builderId,omitempty"`
+	SLSALevel          int    `json:"slsaLevel,omitempty"`
+}
+
+// NewInstallStatement creates a statement whose subject is the installed OCI manifest,
+// identified by ociDigest (e.g. "sha256:abc...")
+func NewInstallStatement(ociDigest string, predicate InstallPredicate) (*Statement, error) {
+	digest, err := subjectDigest(ociDigest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Statement{
+		Type: StatementType,
+		Subject: []Subject{{
+			Name:   predicate.Plugin.OCIReference,
+			Digest: digest,
+		}},
+		PredicateType: InstallPredicateType,
+		Predicate:     predicate,
+	}, nil
+}
+
+// subjectDigest splits an OCI digest into the digest set of a subject
+func subjectDigest(ociDigest string) (map[string]string, error) {
+	algorithm, value, ok := strings.Cut(ociDigest, ":")
+	if !ok || value == "" {
+		return nil, fmt.Errorf("invalid OCI digest %q", ociDigest)
+	}
+	return map[string]string{algorithm: value}, nil
+}
@@ -0,0 +1,31 @@
+// ABOUTME: Storage of install attestations under the vault's .dragonglass directory
+// ABOUTME: Names each attestation after its plugin and install time so none are overwritten
+package intoto
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// Extensions of DSSE envelopes signed with a local key and Sigstore bundles
+	EnvelopeExt = ".intoto.json"
+	BundleExt   = ".sigstore.json"
+)
+
+// Save writes an attestation for pluginID installed at installedAt into dir, returning
+// the file path. ext is EnvelopeExt or BundleExt.
+func Save(dir, pluginID string, installedAt time.Time, data []byte, ext string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create attestations directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s%s", pluginID, installedAt.UTC().Format("20060102T150405.000000000Z"), ext)
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write attestation: %w", err)
+	}
+	return path, nil
+}
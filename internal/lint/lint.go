@@ -0,0 +1,232 @@
+// ABOUTME: Pre-publish checks of plugin artifacts against what dragonglass needs to install them
+// ABOUTME: Reports findings with stable codes for built dist directories and pushed manifests
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/oci"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+	"github.com/gillisandrew/dragonglass-poc/internal/registry"
+)
+
+// Severity of a finding. Errors make an artifact incompatible; warnings do not.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding codes. They are stable, so pipelines can match on them.
+const (
+	CodeMissingFile          = "DG101" // main.js or manifest.json is missing
+	CodeInvalidManifest      = "DG102" // manifest.json is not valid JSON
+	CodeMissingField         = "DG103" // id, name, or version is empty
+	CodeInvalidVersion       = "DG104" // version is not a semantic version
+	CodeInvalidID            = "DG105" // id has characters other than a-z, 0-9 and -
+	CodeInvalidMinAppVersion = "DG106" // minAppVersion is not a semantic version
+	CodeInvalidAuthorURL     = "DG107" // authorUrl is not an http(s) URL
+	CodeAnnotationNamespace  = "DG201" // no plugin annotations under the configured namespace
+	CodeTagMismatch          = "DG202" // tag names neither the version nor v<version>
+	CodeArtifactType         = "DG203" // artifact type is not the dragonglass plugin type
+	CodeMediaType            = "DG301" // layer media type differs from the build workflow's
+	CodeUntitledLayer        = "DG302" // layer has no file name annotation
+	CodeUnexpectedLayer      = "DG303" // layer is not a file dragonglass installs or escapes the plugin directory
+	CodeSizeLimit            = "DG401" // file exceeds its size limit
+)
+
+// SizeLimits caps the size of each plugin file in bytes
+var SizeLimits = map[string]int64{
+	plugin.MainFile:     10 << 20,
+	plugin.StylesFile:   2 << 20,
+	plugin.ManifestFile: 64 << 10,
+}
+
+// layerMediaTypes are the media types the build workflow pushes each file with
+var layerMediaTypes = map[string]string{
+	plugin.MainFile:   registry.MediaTypeJavaScript,
+	plugin.StylesFile: registry.MediaTypeCSS,
+}
+
+// Finding is one problem with an artifact
+type Finding struct {
+	Code     string   `json:"code"`
+	Severity Severity `json:"severity"`
+	File     string   `json:"file,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// Report lists the findings for one artifact, errors first
+type Report struct {
+	Target   string    `json:"target"`
+	Findings []Finding `json:"findings"`
+}
+
+// Errors counts the findings that make the artifact incompatible
+func (r *Report) Errors() int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// Compatible reports whether dragonglass can install the artifact
+func (r *Report) Compatible() bool {
+	return r.Errors() == 0
+}
+
+func (r *Report) add(code string, severity Severity, file, format string, args ...any) {
+	r.Findings = append(r.Findings, Finding{Code: code, Severity: severity, File: file, Message: fmt.Sprintf(format, args...)})
+}
+
+// sort orders findings by severity, then code
+func (r *Report) sort() {
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		a, b := r.Findings[i], r.Findings[j]
+		if a.Severity != b.Severity {
+			return a.Severity == SeverityError
+		}
+		return a.Code < b.Code
+	})
+}
+
+// CheckDist checks a built plugin directory before it is pushed
+func CheckDist(dir string) *Report {
+	r := &Report{Target: dir, Findings: []Finding{}}
+
+	for _, name := range []string{plugin.MainFile, plugin.ManifestFile, plugin.StylesFile} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		switch {
+		case errors.Is(err, os.ErrNotExist) && name != plugin.StylesFile:
+			r.add(CodeMissingFile, SeverityError, name, "%s is missing", name)
+		case err != nil:
+			// styles.css is optional
+		default:
+			checkSize(r, name, info.Size())
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, plugin.ManifestFile))
+	if err == nil {
+		metadata := &plugin.Metadata{}
+		if err := json.Unmarshal(data, metadata); err != nil {
+			r.add(CodeInvalidManifest, SeverityError, plugin.ManifestFile, "manifest.json is not valid JSON: %v", err)
+		} else {
+			checkMetadata(r, plugin.ManifestFile, metadata)
+		}
+	}
+
+	r.sort()
+	return r
+}
+
+// CheckManifest checks the manifest of a pushed artifact. namespace is the annotation
+// namespace installs read, and tag the tag the artifact was fetched by (empty for a
+// digest).
+func CheckManifest(target string, manifest *ocispec.Manifest, namespace, tag string) *Report {
+	r := &Report{Target: target, Findings: []Finding{}}
+
+	artifactType := manifest.ArtifactType
+	if artifactType == "" {
+		artifactType = manifest.Config.MediaType
+	}
+	if artifactType != registry.ArtifactType {
+		r.add(CodeArtifactType, SeverityWarning, "", "artifact type is %q, expected %q", artifactType, registry.ArtifactType)
+	}
+
+	parser := plugin.NewManifestParser(plugin.DefaultPluginOpts().WithAnnotationNamespace(namespace))
+	metadata, err := parser.ParseMetadata(manifest, manifest.Annotations)
+	if err != nil {
+		if other := otherNamespace(manifest.Annotations, namespace); other != "" {
+			r.add(CodeAnnotationNamespace, SeverityError, "", "plugin annotations use namespace %q, but installs read %q (see registry.annotation_namespace)", other, namespace)
+		} else {
+			r.add(CodeAnnotationNamespace, SeverityError, "", "no plugin annotations under namespace %q: %v", namespace, err)
+		}
+	} else {
+		checkMetadata(r, "", metadata)
+		if tag != "" && tag != metadata.Version && tag != "v"+metadata.Version {
+			r.add(CodeTagMismatch, SeverityWarning, "", "tag %q does not name version %s", tag, metadata.Version)
+		}
+	}
+
+	hasMain := false
+	for i, layer := range manifest.Layers {
+		title := layer.Annotations[ocispec.AnnotationTitle]
+		expected, known := layerMediaTypes[title]
+		switch {
+		case title == "":
+			r.add(CodeUntitledLayer, SeverityWarning, "", "layer %d (%s) has no %s annotation and is not installed", i, layer.MediaType, ocispec.AnnotationTitle)
+			continue
+		case strings.Contains(title, "/"):
+			// Assets in subdirectories are installed as they are
+			if _, err := oci.LayerPath(title); err != nil {
+				r.add(CodeUnexpectedLayer, SeverityError, title, "%s escapes the plugin directory and fails the install", title)
+			}
+			continue
+		case !known:
+			r.add(CodeUnexpectedLayer, SeverityWarning, title, "%s is not a plugin file and is not installed", title)
+			continue
+		case layer.MediaType != expected:
+			r.add(CodeMediaType, SeverityWarning, title, "%s has media type %q, expected %q", title, layer.MediaType, expected)
+		}
+		hasMain = hasMain || title == plugin.MainFile
+		checkSize(r, title, layer.Size)
+	}
+	if !hasMain {
+		r.add(CodeMissingFile, SeverityError, plugin.MainFile, "no layer holds main.js")
+	}
+
+	r.sort()
+	return r
+}
+
+// checkMetadata reports the manifest fields install validation rejects
+func checkMetadata(r *Report, file string, metadata *plugin.Metadata) {
+	parser := plugin.NewManifestParser(plugin.DefaultPluginOpts().WithStrictValidation(true))
+	result := parser.ValidateMetadata(metadata)
+
+	for _, e := range result.Errors {
+		code := CodeMissingField
+		switch {
+		case e.Field == "id" && metadata.ID != "":
+			code = CodeInvalidID
+		case e.Field == "version" && metadata.Version != "":
+			code = CodeInvalidVersion
+		case e.Field == "minAppVersion":
+			code = CodeInvalidMinAppVersion
+		case e.Field == "authorUrl":
+			code = CodeInvalidAuthorURL
+		}
+		r.add(code, SeverityError, file, "%s: %s", e.Field, e.Message)
+	}
+}
+
+func checkSize(r *Report, name string, size int64) {
+	if limit, ok := SizeLimits[name]; ok && size > limit {
+		r.add(CodeSizeLimit, SeverityError, name, "%s is %d bytes, over the %d byte limit", name, size, limit)
+	}
+}
+
+// otherNamespace returns the namespace of plugin annotations that are not under
+// namespace, if any
+func otherNamespace(annotations map[string]string, namespace string) string {
+	suffix := "." + plugin.AnnotationID
+	for key := range annotations {
+		if other, ok := strings.CutSuffix(key, suffix); ok && other != namespace && annotations[other+"."+plugin.AnnotationVersion] != "" {
+			return other
+		}
+	}
+	return ""
+}
@@ -0,0 +1,182 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+	"github.com/gillisandrew/dragonglass-poc/internal/registry"
+)
+
+const testNamespace = "md.obsidian.plugin.v0"
+
+func codes(r *Report) string {
+	found := make([]string, len(r.Findings))
+	for i, f := range r.Findings {
+		found[i] = f.Code
+	}
+	return strings.Join(found, ",")
+}
+
+func TestCheckDist(t *testing.T) {
+	const manifest = `{"id": "my-plugin", "name": "My Plugin", "version": "1.2.3"}`
+
+	tests := []struct {
+		name       string
+		files      map[string]string
+		codes      string
+		compatible bool
+	}{
+		{
+			name:       "valid",
+			files:      map[string]string{"main.js": "module.exports = {}", "manifest.json": manifest},
+			compatible: true,
+		},
+		{
+			name:  "missing main.js",
+			files: map[string]string{"manifest.json": manifest},
+			codes: CodeMissingFile,
+		},
+		{
+			name:  "invalid JSON",
+			files: map[string]string{"main.js": "", "manifest.json": "{"},
+			codes: CodeInvalidManifest,
+		},
+		{
+			name:  "invalid fields",
+			files: map[string]string{"main.js": "", "manifest.json": `{"id": "My Plugin", "version": "1.2", "minAppVersion": "x", "authorUrl": "nope"}`},
+			codes: strings.Join([]string{CodeMissingField, CodeInvalidVersion, CodeInvalidID, CodeInvalidMinAppVersion, CodeInvalidAuthorURL}, ","),
+		},
+		{
+			name:  "oversized styles.css",
+			files: map[string]string{"main.js": "", "manifest.json": manifest, "styles.css": strings.Repeat("a", int(SizeLimits[plugin.StylesFile])+1)},
+			codes: CodeSizeLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			report := CheckDist(dir)
+			if got := codes(report); got != tt.codes {
+				t.Errorf("expected codes %q, got %q (%+v)", tt.codes, got, report.Findings)
+			}
+			if report.Compatible() != tt.compatible {
+				t.Errorf("expected compatible %v, got %v", tt.compatible, report.Compatible())
+			}
+		})
+	}
+}
+
+func TestCheckManifest(t *testing.T) {
+	annotations := (&plugin.Metadata{ID: "my-plugin", Name: "My Plugin", Version: "1.2.3"}).Annotations(testNamespace)
+	layer := func(title, mediaType string, size int64) ocispec.Descriptor {
+		desc := ocispec.Descriptor{MediaType: mediaType, Size: size}
+		if title != "" {
+			desc.Annotations = map[string]string{ocispec.AnnotationTitle: title}
+		}
+		return desc
+	}
+	mainLayer := layer(plugin.MainFile, registry.MediaTypeJavaScript, 100)
+
+	tests := []struct {
+		name       string
+		manifest   ocispec.Manifest
+		namespace  string
+		tag        string
+		codes      string
+		compatible bool
+	}{
+		{
+			name: "valid",
+			manifest: ocispec.Manifest{
+				ArtifactType: registry.ArtifactType,
+				Layers:       []ocispec.Descriptor{mainLayer, layer(plugin.StylesFile, registry.MediaTypeCSS, 10)},
+				Annotations:  annotations,
+			},
+			tag:        "v1.2.3",
+			compatible: true,
+		},
+		{
+			name: "subdirectory assets",
+			manifest: ocispec.Manifest{
+				ArtifactType: registry.ArtifactType,
+				Layers:       []ocispec.Descriptor{mainLayer, layer("lang/en.json", "application/json", 10), layer("lang/../../evil.js", "application/javascript", 10)},
+				Annotations:  annotations,
+			},
+			tag:   "v1.2.3",
+			codes: CodeUnexpectedLayer,
+		},
+		{
+			name: "warnings only",
+			manifest: ocispec.Manifest{
+				ArtifactType: "application/vnd.oci.image.config.v1+json",
+				Layers:       []ocispec.Descriptor{layer(plugin.MainFile, "application/octet-stream", 100), layer("", "text/plain", 1), layer("README.md", "text/markdown", 1)},
+				Annotations:  annotations,
+			},
+			tag:        "latest",
+			codes:      strings.Join([]string{CodeTagMismatch, CodeArtifactType, CodeMediaType, CodeUntitledLayer, CodeUnexpectedLayer}, ","),
+			compatible: true,
+		},
+		{
+			name: "other namespace",
+			manifest: ocispec.Manifest{
+				ArtifactType: registry.ArtifactType,
+				Layers:       []ocispec.Descriptor{mainLayer},
+				Annotations:  annotations,
+			},
+			namespace: "vnd.obsidian.plugin",
+			codes:     CodeAnnotationNamespace,
+		},
+		{
+			name: "missing and oversized main.js",
+			manifest: ocispec.Manifest{
+				ArtifactType: registry.ArtifactType,
+				Layers:       []ocispec.Descriptor{layer(plugin.StylesFile, registry.MediaTypeCSS, SizeLimits[plugin.StylesFile]+1)},
+				Annotations:  annotations,
+			},
+			codes: strings.Join([]string{CodeMissingFile, CodeSizeLimit}, ","),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namespace := tt.namespace
+			if namespace == "" {
+				namespace = testNamespace
+			}
+
+			report := CheckManifest("ghcr.io/me/my-plugin", &tt.manifest, namespace, tt.tag)
+			if got := codes(report); got != tt.codes {
+				t.Errorf("expected codes %q, got %q (%+v)", tt.codes, got, report.Findings)
+			}
+			if report.Compatible() != tt.compatible {
+				t.Errorf("expected compatible %v, got %v", tt.compatible, report.Compatible())
+			}
+		})
+	}
+}
+
+func TestOtherNamespaceHint(t *testing.T) {
+	annotations := (&plugin.Metadata{ID: "my-plugin", Name: "My Plugin", Version: "1.2.3"}).Annotations(testNamespace)
+	manifest := &ocispec.Manifest{
+		ArtifactType: registry.ArtifactType,
+		Layers:       []ocispec.Descriptor{{MediaType: registry.MediaTypeJavaScript, Annotations: map[string]string{ocispec.AnnotationTitle: plugin.MainFile}}},
+		Annotations:  annotations,
+	}
+
+	report := CheckManifest("ghcr.io/me/my-plugin", manifest, "vnd.obsidian.plugin", "")
+	if len(report.Findings) != 1 || !strings.Contains(report.Findings[0].Message, testNamespace) {
+		t.Errorf("expected a hint naming %s, got %+v", testNamespace, report.Findings)
+	}
+}
@@ -0,0 +1,89 @@
+package lockfile
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// benchmarkPlugins is the size of a large vault's lockfile
+const benchmarkPlugins = 500
+
+func newBenchmarkLockfile(plugins int) *Lockfile {
+	lockfile := NewLockfile("/vault")
+	installedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < plugins; i++ {
+		id := fmt.Sprintf("plugin-%03d", i)
+		lockfile.Plugins[id] = PluginEntry{
+			Name:         fmt.Sprintf("Plugin %03d", i),
+			Version:      "1.0.0",
+			OCIReference: fmt.Sprintf("ghcr.io/owner/repo-%03d/%s:v1.0.0", i, id),
+			OCIDigest:    fmt.Sprintf("sha256:%064x", i),
+			VerificationState: VerificationState{
+				ProvenanceVerified: true,
+				SBOMVerified:       true,
+				VulnScanPassed:     true,
+				BuilderID:          "https://github.com/gillisandrew/dragonglass-poc/.github/workflows/build.yml@refs/heads/main",
+				SLSALevel:          3,
+				SBOMFormat:         "SPDX-2.3",
+				VerifiedAt:         &installedAt,
+			},
+			Metadata:    PluginMetadata{Author: "Author", Description: "Benchmark plugin"},
+			InstalledAt: &installedAt,
+			Files: map[string]string{
+				"main.js":       fmt.Sprintf("sha256:%064x", i+1),
+				"manifest.json": fmt.Sprintf("sha256:%064x", i+2),
+			},
+			OCIBlobs: &BlobDigests{
+				Config: fmt.Sprintf("sha256:%064x", i+3),
+				Layers: []string{fmt.Sprintf("sha256:%064x", i+4)},
+			},
+		}
+	}
+	return lockfile
+}
+
+func BenchmarkSaveLockfile(b *testing.B) {
+	lockfile := newBenchmarkLockfile(benchmarkPlugins)
+	path := filepath.Join(b.TempDir(), LockfileName)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := SaveLockfile(lockfile, path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadLockfile(b *testing.B) {
+	path := filepath.Join(b.TempDir(), LockfileName)
+	if err := SaveLockfile(newBenchmarkLockfile(benchmarkPlugins), path); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lockfile, err := LoadLockfile(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(lockfile.Plugins) != benchmarkPlugins {
+			b.Fatalf("expected %d plugins, got %d", benchmarkPlugins, len(lockfile.Plugins))
+		}
+	}
+}
+
+func BenchmarkFindPluginByName(b *testing.B) {
+	lockfile := newBenchmarkLockfile(benchmarkPlugins)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if lockfile.FindPluginByName(fmt.Sprintf("Plugin %03d", i%benchmarkPlugins)) == nil {
+			b.Fatal("plugin not found")
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package lockfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// FuzzLoadLockfile feeds lockfile bytes through migration, schema validation, and
+// parsing. Lockfiles are committed to vaults, so their contents are untrusted.
+func FuzzLoadLockfile(f *testing.F) {
+	// Lockfile written after installing Templater 2.15.2
+	f.Add([]byte(`{
+  "version": "1",
+  "generated_at": "2026-01-01T00:00:00Z",
+  "updated_at": "2026-01-02T03:04:05Z",
+  "plugins": {
+    "templater-obsidian": {
+      "name": "Templater",
+      "version": "2.15.2",
+      "oci_reference": "ghcr.io/silentvoid13/templater/templater-obsidian:v2.15.2",
+      "oci_digest": "sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+      "verification_state": {
+        "provenance_verified": true,
+        "sbom_verified": true,
+        "vuln_scan_passed": true,
+        "builder_id": "https://github.com/gillisandrew/dragonglass-poc/.github/workflows/build.yml@refs/heads/main",
+        "slsa_level": 3,
+        "sbom_format": "SPDX-2.3",
+        "vulnerabilities": {"critical": 0, "high": 0, "medium": 1, "low": 2},
+        "verified_at": "2026-01-02T03:04:05Z",
+        "policy_hash": "sha256:0123"
+      },
+      "metadata": {"author": "SilentVoid", "description": "Create and use templates"},
+      "installed_at": "2026-01-02T03:04:05Z",
+      "files": {
+        "main.js": "sha256:60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752",
+        "manifest.json": "sha256:fd61a03af4f77d870fc21e05e7e80678095c92d808cfb3b5c279ee04c74aca13"
+      },
+      "oci_blobs": {
+        "config": "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a",
+        "layers": ["sha256:5f70bf18a086007016e948b04aed3b82103a36bea41755b6cddfaf10ace3c6ef"]
+      }
+    }
+  },
+  "metadata": {"vault_path": "/vault", "dragonglass_version": "dev", "schema_version": "1"}
+}`))
+	f.Add([]byte(`{"version": "1", "plugins": {}, "metadata": {}}`))
+	f.Add([]byte(`{"version": "99", "plugins": {}}`))
+	f.Add([]byte(`{"plugins": {"": {}}}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		path := filepath.Join(t.TempDir(), LockfileName)
+		if err := os.WriteFile(path, data, DefaultLockfilePerms); err != nil {
+			t.Fatal(err)
+		}
+
+		lockfile, err := LoadLockfile(path)
+		if err != nil {
+			return
+		}
+
+		// Whatever loads must survive a save and reload unchanged in size
+		if err := SaveLockfile(lockfile, path); err != nil {
+			t.Fatalf("loaded lockfile does not save: %v", err)
+		}
+		reloaded, err := LoadLockfile(path)
+		if err != nil {
+			t.Fatalf("saved lockfile does not load: %v", err)
+		}
+		if len(reloaded.Plugins) != len(lockfile.Plugins) {
+			t.Fatalf("reload has %d plugins, expected %d", len(reloaded.Plugins), len(lockfile.Plugins))
+		}
+	})
+}
@@ -0,0 +1,73 @@
+// ABOUTME: Whole-plugin integrity values in Subresource Integrity format (sha256-<base64>)
+// ABOUTME: Computed over the installed file digests and checked by install and lock verify
+package lockfile
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+)
+
+// IntegrityPrefix is the Subresource Integrity algorithm prefix of integrity values
+const IntegrityPrefix = "sha256-"
+
+// Integrity returns the integrity value of a plugin whose installed files have the given
+// digests, keyed by slash-separated file name. It is the SHA-256 of one "<digest>  <name>"
+// line per file in name order, in SRI format. manifest.json is left out because it is
+// written from lockfile metadata rather than extracted from the artifact.
+func Integrity(files map[string]string) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if name != plugin.ManifestFile {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonical, "%s  %s\n", files[name], name)
+	}
+
+	sum := sha256.Sum256([]byte(canonical.String()))
+	return IntegrityPrefix + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// CheckIntegrity compares the digests of a plugin's installed files against the integrity
+// value recorded at install. Entries written before integrity was recorded pass unchecked.
+func (e PluginEntry) CheckIntegrity(files map[string]string) error {
+	if e.Integrity == "" {
+		return nil
+	}
+	if !strings.HasPrefix(e.Integrity, IntegrityPrefix) {
+		return fmt.Errorf("unsupported integrity %q: only %s values are supported", e.Integrity, strings.TrimSuffix(IntegrityPrefix, "-"))
+	}
+
+	if actual := Integrity(files); actual != e.Integrity {
+		return &dgerrors.DigestMismatchError{Subject: "plugin files", Expected: e.Integrity, Actual: actual}
+	}
+	return nil
+}
+
+// ChangedFiles returns the sorted names of files whose digests differ between recorded
+// and actual, including files present in only one of them
+func ChangedFiles(recorded, actual map[string]string) []string {
+	var changed []string
+	for name, digest := range recorded {
+		if actual[name] != digest {
+			changed = append(changed, name)
+		}
+	}
+	for name := range actual {
+		if _, ok := recorded[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
@@ -0,0 +1,89 @@
+package lockfile
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+)
+
+func TestIntegrity(t *testing.T) {
+	files := map[string]string{
+		"main.js":      "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+		"lang/en.json": "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a",
+	}
+
+	integrity := Integrity(files)
+	if !strings.HasPrefix(integrity, IntegrityPrefix) || len(integrity) != len(IntegrityPrefix)+44 {
+		t.Fatalf("expected sha256-<base64> integrity, got %q", integrity)
+	}
+
+	// manifest.json is generated at install and does not contribute
+	withManifest := map[string]string{"manifest.json": "sha256:generated"}
+	for name, digest := range files {
+		withManifest[name] = digest
+	}
+	if got := Integrity(withManifest); got != integrity {
+		t.Errorf("expected manifest.json to be ignored, got %q and %q", got, integrity)
+	}
+
+	entry := PluginEntry{Integrity: integrity}
+	tests := []struct {
+		name     string
+		entry    PluginEntry
+		files    map[string]string
+		mismatch bool
+		errorMsg string
+	}{
+		{name: "matching files", entry: entry, files: files},
+		{name: "modified file", entry: entry, files: map[string]string{"main.js": "sha256:other", "lang/en.json": files["lang/en.json"]}, mismatch: true},
+		{name: "added file", entry: entry, files: map[string]string{"main.js": files["main.js"], "lang/en.json": files["lang/en.json"], "evil.js": "sha256:evil"}, mismatch: true},
+		{name: "removed file", entry: entry, files: map[string]string{"main.js": files["main.js"]}, mismatch: true},
+		{name: "legacy entry", entry: PluginEntry{}, files: map[string]string{"anything": "sha256:x"}},
+		{name: "unsupported algorithm", entry: PluginEntry{Integrity: "sha512-abc"}, files: files, errorMsg: "unsupported integrity"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.entry.CheckIntegrity(tt.files)
+			switch {
+			case tt.mismatch:
+				if !errors.Is(err, dgerrors.ErrDigestMismatch) || !strings.Contains(err.Error(), "plugin files digest mismatch") {
+					t.Errorf("expected plugin files digest mismatch, got %v", err)
+				}
+			case tt.errorMsg != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	written := map[string]string{"main.js": "sha256:a", "manifest.json": "sha256:b"}
+
+	tests := []struct {
+		name      string
+		installed map[string]string
+		expected  []string
+	}{
+		{name: "unchanged", installed: map[string]string{"main.js": "sha256:a", "manifest.json": "sha256:b"}},
+		{name: "overwritten", installed: map[string]string{"main.js": "sha256:old", "manifest.json": "sha256:b"}, expected: []string{"main.js"}},
+		{name: "removed", installed: map[string]string{"manifest.json": "sha256:b"}, expected: []string{"main.js"}},
+		{name: "conflict copy added", installed: map[string]string{"main.js": "sha256:a", "manifest.json": "sha256:b", "main (conflicted copy).js": "sha256:c"}, expected: []string{"main (conflicted copy).js"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ChangedFiles(written, tt.installed)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
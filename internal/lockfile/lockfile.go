@@ -9,13 +9,28 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
+
+	"oras.land/oras-go/v2/registry"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+	"github.com/gillisandrew/dragonglass-poc/internal/semver"
 )
 
 const (
 	LockfileName         = "dragonglass-lock.json"
+	DragonglassDirName   = ".dragonglass"
 	LockfileVersion      = "1"
 	DefaultLockfilePerms = 0644
+
+	// Extension of the signature written next to the lockfile by lock sign
+	SignatureExt = ".sig"
+
+	// Directory that marks a vault root
+	obsidianDirName = ".obsidian"
 )
 
 // LockfileOpts configures how lockfiles are loaded and managed
@@ -83,6 +98,13 @@ type Lockfile struct {
 	UpdatedAt   time.Time              `json:"updated_at"`
 	Plugins     map[string]PluginEntry `json:"plugins"`
 	Metadata    LockfileMetadata       `json:"metadata"`
+
+	// Plugin IDs by name, built on first lookup and dropped by every mutation
+	byName         map[string]string
+	indexedPlugins int
+
+	// Whether the lockfile was mutated since it was loaded or saved
+	dirty bool
 }
 
 type PluginEntry struct {
@@ -92,14 +114,290 @@ type PluginEntry struct {
 	OCIDigest         string            `json:"oci_digest"`
 	VerificationState VerificationState `json:"verification_state"`
 	Metadata          PluginMetadata    `json:"metadata"`
+
+	// When the plugin was last installed from the registry
+	InstalledAt *time.Time `json:"installed_at,omitempty"`
+
+	// When the plugin was first installed in the vault; kept when it is updated or reinstalled
+	FirstInstalledAt *time.Time `json:"first_installed_at,omitempty"`
+
+	// Outcomes of the most recent verifications, oldest first, at most
+	// MaxVerificationHistory. AddPlugin and UpdatePluginVerification append to it.
+	VerificationHistory []VerificationRecord `json:"verification_history,omitempty"`
+
+	// SHA-256 digests of the installed plugin files, keyed by file name
+	Files map[string]string `json:"files,omitempty"`
+
+	// Integrity of the installed plugin files in SRI format (sha256-<base64>)
+	Integrity string `json:"integrity,omitempty"`
+
+	// Digests of the blobs referenced by the manifest at OCIDigest
+	OCIBlobs *BlobDigests `json:"oci_blobs,omitempty"`
+
+	// OCIReference names a tag such as latest that is expected to move. Installs restore
+	// OCIDigest, while sync and notify always re-resolve the tag.
+	MutableTag bool `json:"mutable_tag,omitempty"`
+
+	// The plugin stays installed and verified but is switched off in Obsidian
+	// (dragonglass disable)
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Kind of artifact, theme or snippet; empty for plugins
+	Kind plugin.Kind `json:"kind,omitempty"`
+
+	// Set for plugins built locally from source with dev install. Such entries are never
+	// verified: OCIReference holds the git source and OCIDigest the digest of the built
+	// files, and no command installs them from a registry.
+	Dev *DevSource `json:"dev,omitempty"`
+}
+
+// DevSource records the source a dev build was made from
+type DevSource struct {
+	// Git URL or local path of the source, and the branch, tag, or commit built
+	Repository string `json:"repository"`
+	Ref        string `json:"ref,omitempty"`
+
+	// Directory of the plugin within the repository, if not its root
+	Directory string `json:"directory,omitempty"`
+
+	BuiltAt time.Time `json:"built_at"`
+}
+
+// IsDev reports whether the entry is an unverified dev build
+func (e PluginEntry) IsDev() bool {
+	return e.Dev != nil
+}
+
+// LockedReference returns the reference that fetches exactly the locked artifact. For a
+// mutable tag it is the repository at OCIDigest, since the tag is expected to have moved;
+// otherwise it is OCIReference, so a re-pushed version tag is still detected.
+func (e PluginEntry) LockedReference() string {
+	if !e.MutableTag || e.OCIDigest == "" {
+		return e.OCIReference
+	}
+	ref, err := registry.ParseReference(e.OCIReference)
+	if err != nil {
+		return e.OCIReference
+	}
+	return ref.Registry + "/" + ref.Repository + "@" + e.OCIDigest
+}
+
+// BlobDigests pins the config and layer blobs a plugin's OCI manifest references, so a
+// registry serving swapped blobs is caught even before content addressing catches it
+type BlobDigests struct {
+	Config string   `json:"config"`
+	Layers []string `json:"layers"`
+}
+
+// CheckBlobs compares the blobs a fetched manifest references against those recorded at
+// install. Entries written before blob digests were recorded pass unchecked.
+func (e PluginEntry) CheckBlobs(actual BlobDigests) error {
+	if e.OCIBlobs == nil {
+		return nil
+	}
+
+	if actual.Config != e.OCIBlobs.Config {
+		return &dgerrors.DigestMismatchError{Subject: "config", Expected: e.OCIBlobs.Config, Actual: actual.Config}
+	}
+
+	if len(actual.Layers) != len(e.OCIBlobs.Layers) {
+		return dgerrors.Mark(fmt.Errorf("manifest references %d layers, lockfile records %d", len(actual.Layers), len(e.OCIBlobs.Layers)), dgerrors.ErrDigestMismatch)
+	}
+	for i, layer := range actual.Layers {
+		if layer != e.OCIBlobs.Layers[i] {
+			return &dgerrors.DigestMismatchError{Subject: fmt.Sprintf("layer %d", i), Expected: e.OCIBlobs.Layers[i], Actual: layer}
+		}
+	}
+
+	return nil
+}
+
+// Verified reports whether both the provenance and the SBOM of the entry were verified.
+// Dev builds are never verified.
+func (e PluginEntry) Verified() bool {
+	return !e.IsDev() && e.VerificationState.ProvenanceVerified && e.VerificationState.SBOMVerified
 }
 
 type VerificationState struct {
-	ProvenanceVerified bool     `json:"provenance_verified"`
-	SBOMVerified       bool     `json:"sbom_verified"`
-	VulnScanPassed     bool     `json:"vuln_scan_passed"`
-	Warnings           []string `json:"warnings,omitempty"`
-	Errors             []string `json:"errors,omitempty"`
+	ProvenanceVerified bool `json:"provenance_verified"`
+	SBOMVerified       bool `json:"sbom_verified"`
+	VulnScanPassed     bool `json:"vuln_scan_passed"`
+
+	// Workflow identity recorded in the SLSA provenance
+	BuilderID string `json:"builder_id,omitempty"`
+
+	// Repository URL and commit SHA of the source the provenance says was built
+	SourceRepository string `json:"source_repository,omitempty"`
+	SourceCommit     string `json:"source_commit,omitempty"`
+
+	// When the build that produced the artifact started, per its provenance
+	BuiltAt *time.Time `json:"built_at,omitempty"`
+
+	// SLSA build level established by the provenance (0 when none was verified)
+	SLSALevel int `json:"slsa_level,omitempty"`
+
+	// Format of the SBOM attestation, e.g. "SPDX-2.3"
+	SBOMFormat string `json:"sbom_format,omitempty"`
+
+	// Vulnerabilities found in the SBOM, by severity
+	Vulnerabilities *VulnerabilityCounts `json:"vulnerabilities,omitempty"`
+
+	// When verification ran and the hash of the verification policy it ran under
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	PolicyHash string     `json:"policy_hash,omitempty"`
+
+	Warnings []string `json:"warnings,omitempty"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// MaxVerificationHistory is the number of verification outcomes kept per plugin
+const MaxVerificationHistory = 10
+
+// VerificationRecord is the outcome of one verification of a plugin, as kept in its
+// verification history
+type VerificationRecord struct {
+	VerifiedAt         time.Time            `json:"verified_at"`
+	Version            string               `json:"version,omitempty"`
+	OCIDigest          string               `json:"oci_digest"`
+	ProvenanceVerified bool                 `json:"provenance_verified"`
+	SBOMVerified       bool                 `json:"sbom_verified"`
+	VulnScanPassed     bool                 `json:"vuln_scan_passed"`
+	Vulnerabilities    *VulnerabilityCounts `json:"vulnerabilities,omitempty"`
+	PolicyHash         string               `json:"policy_hash,omitempty"`
+
+	// Number of warnings and errors verification reported
+	Warnings int `json:"warnings,omitempty"`
+	Errors   int `json:"errors,omitempty"`
+}
+
+// Passed reports whether the provenance and the SBOM were verified without errors
+func (r VerificationRecord) Passed() bool {
+	return r.ProvenanceVerified && r.SBOMVerified && r.Errors == 0
+}
+
+// LastVerified returns when the plugin was last verified, or nil if it never was
+func (e PluginEntry) LastVerified() *time.Time {
+	return e.VerificationState.VerifiedAt
+}
+
+// recordVerification appends the current verification state to the history, unless it
+// was not verified or is already the latest record, keeping the newest
+// MaxVerificationHistory records
+func (e *PluginEntry) recordVerification() {
+	state := e.VerificationState
+	if state.VerifiedAt == nil {
+		return
+	}
+	if n := len(e.VerificationHistory); n > 0 {
+		last := e.VerificationHistory[n-1]
+		if last.VerifiedAt.Equal(*state.VerifiedAt) && last.OCIDigest == e.OCIDigest {
+			return
+		}
+	}
+
+	// Copy so entries sharing the history, such as a transaction's snapshot, are untouched
+	history := append(slices.Clone(e.VerificationHistory), VerificationRecord{
+		VerifiedAt:         *state.VerifiedAt,
+		Version:            e.Version,
+		OCIDigest:          e.OCIDigest,
+		ProvenanceVerified: state.ProvenanceVerified,
+		SBOMVerified:       state.SBOMVerified,
+		VulnScanPassed:     state.VulnScanPassed,
+		Vulnerabilities:    state.Vulnerabilities,
+		PolicyHash:         state.PolicyHash,
+		Warnings:           len(state.Warnings),
+		Errors:             len(state.Errors),
+	})
+	if len(history) > MaxVerificationHistory {
+		history = history[len(history)-MaxVerificationHistory:]
+	}
+	e.VerificationHistory = history
+}
+
+// VulnerabilityCounts tallies SBOM vulnerabilities by severity
+type VulnerabilityCounts struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown,omitempty"`
+}
+
+// Total returns the number of vulnerabilities across all severities
+func (c VulnerabilityCounts) Total() int {
+	return c.Critical + c.High + c.Medium + c.Low + c.Unknown
+}
+
+// Downgrade reports why replacing installed with next would roll the plugin back, or ""
+// when it would not: next has a lower version, or the same version (or versions that are
+// not semver) from a build that started before installed's. A higher version built earlier
+// is not a downgrade, as maintenance releases of older lines are.
+func Downgrade(installed, next PluginEntry) string {
+	installedVersion, errInstalled := semver.Parse(installed.Version)
+	nextVersion, errNext := semver.Parse(next.Version)
+	if errInstalled == nil && errNext == nil {
+		switch cmp := nextVersion.Compare(installedVersion); {
+		case cmp < 0:
+			return fmt.Sprintf("version %s is lower than the installed %s", next.Version, installed.Version)
+		case cmp > 0:
+			return ""
+		}
+	} else if installed.Version != next.Version {
+		return ""
+	}
+
+	installedBuild, nextBuild := installed.VerificationState.BuiltAt, next.VerificationState.BuiltAt
+	if installedBuild != nil && nextBuild != nil && nextBuild.Before(*installedBuild) {
+		return fmt.Sprintf("build of %s started %s, before the installed build of %s", next.Version, nextBuild.UTC().Format(time.RFC3339), installedBuild.UTC().Format(time.RFC3339))
+	}
+	return ""
+}
+
+// SourceTreeURL returns a link to the exact source tree the plugin was built from, or ""
+// when the source is not a GitHub repository or was not recorded
+func (s VerificationState) SourceTreeURL() string {
+	if s.SourceCommit == "" || !strings.HasPrefix(s.SourceRepository, "https://github.com/") {
+		return ""
+	}
+	return s.SourceRepository + "/tree/" + s.SourceCommit
+}
+
+// SourceRepositoryURL returns the repository URL of a provenance source URI such as
+// "git+https://github.com/owner/repo@refs/heads/main", without the VCS prefix, revision,
+// or .git suffix
+func SourceRepositoryURL(uri string) string {
+	repo := strings.TrimPrefix(uri, "git+")
+	if i := strings.LastIndex(repo, "@"); i > strings.Index(repo, "://")+2 {
+		repo = repo[:i]
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(repo, "/"), ".git")
+}
+
+// Regressions lists what got worse between this recorded state and current, the state
+// from re-verifying the same artifact. Errors already recorded are not repeated.
+func (s VerificationState) Regressions(current VerificationState) []string {
+	var reasons []string
+	if s.ProvenanceVerified && !current.ProvenanceVerified {
+		reasons = append(reasons, "provenance no longer verifies")
+	}
+	if s.SBOMVerified && !current.SBOMVerified {
+		reasons = append(reasons, "SBOM attestation no longer verifies")
+	}
+	if s.VulnScanPassed && !current.VulnScanPassed {
+		reasons = append(reasons, "vulnerability scan no longer passes")
+	}
+	if current.SLSALevel < s.SLSALevel {
+		reasons = append(reasons, fmt.Sprintf("SLSA level dropped from %d to %d", s.SLSALevel, current.SLSALevel))
+	}
+	if s.BuilderID != "" && current.BuilderID != "" && current.BuilderID != s.BuilderID {
+		reasons = append(reasons, fmt.Sprintf("builder changed from %s to %s", s.BuilderID, current.BuilderID))
+	}
+	for _, err := range current.Errors {
+		if !slices.Contains(s.Errors, err) {
+			reasons = append(reasons, err)
+		}
+	}
+	return reasons
 }
 
 type PluginMetadata struct {
@@ -116,6 +414,9 @@ type LockfileMetadata struct {
 	VaultPath          string `json:"vault_path"`
 	DragongrassVersion string `json:"dragonglass_version"`
 	SchemaVersion      string `json:"schema_version"`
+
+	// When the vault was frozen; while set, commands refuse to add, remove, or upgrade plugins
+	FrozenAt *time.Time `json:"frozen_at,omitempty"`
 }
 
 func NewLockfile(vaultPath string) *Lockfile {
@@ -152,6 +453,9 @@ func (l *Lockfile) Validate() error {
 		if plugin.OCIDigest == "" {
 			return fmt.Errorf("plugin %s: OCI digest is required", pluginID)
 		}
+		if plugin.IsDev() && (plugin.VerificationState.ProvenanceVerified || plugin.VerificationState.SBOMVerified) {
+			return fmt.Errorf("plugin %s: dev builds cannot be marked verified", pluginID)
+		}
 	}
 
 	return nil
@@ -165,19 +469,34 @@ func (l *Lockfile) AddPlugin(pluginID string, plugin PluginEntry) error {
 		return fmt.Errorf("plugin name is required")
 	}
 
+	// Replacing a plugin, e.g. on update, keeps when it was first installed and how it
+	// verified before
+	if existing, exists := l.Plugins[pluginID]; exists {
+		if plugin.FirstInstalledAt == nil {
+			plugin.FirstInstalledAt = existing.FirstInstalledAt
+		}
+		if plugin.VerificationHistory == nil {
+			plugin.VerificationHistory = existing.VerificationHistory
+		}
+	}
+	if plugin.FirstInstalledAt == nil {
+		plugin.FirstInstalledAt = plugin.InstalledAt
+	}
+	plugin.recordVerification()
+
 	l.Plugins[pluginID] = plugin
-	l.UpdatedAt = time.Now().UTC()
+	l.touch()
 
 	return nil
 }
 
 func (l *Lockfile) RemovePlugin(pluginID string) error {
 	if _, exists := l.Plugins[pluginID]; !exists {
-		return fmt.Errorf("plugin %s not found in lockfile", pluginID)
+		return dgerrors.Mark(fmt.Errorf("plugin %s not found in lockfile", pluginID), dgerrors.ErrNotInLockfile)
 	}
 
 	delete(l.Plugins, pluginID)
-	l.UpdatedAt = time.Now().UTC()
+	l.touch()
 
 	return nil
 }
@@ -185,12 +504,30 @@ func (l *Lockfile) RemovePlugin(pluginID string) error {
 func (l *Lockfile) UpdatePluginVerification(pluginID string, verification VerificationState) error {
 	plugin, exists := l.Plugins[pluginID]
 	if !exists {
-		return fmt.Errorf("plugin %s not found in lockfile", pluginID)
+		return dgerrors.Mark(fmt.Errorf("plugin %s not found in lockfile", pluginID), dgerrors.ErrNotInLockfile)
 	}
 
 	plugin.VerificationState = verification
+	plugin.recordVerification()
 	l.Plugins[pluginID] = plugin
-	l.UpdatedAt = time.Now().UTC()
+	l.touch()
+
+	return nil
+}
+
+// SetPluginDisabled records whether a plugin is switched off in Obsidian
+func (l *Lockfile) SetPluginDisabled(pluginID string, disabled bool) error {
+	plugin, exists := l.Plugins[pluginID]
+	if !exists {
+		return dgerrors.Mark(fmt.Errorf("plugin %s not found in lockfile", pluginID), dgerrors.ErrNotInLockfile)
+	}
+	if plugin.Disabled == disabled {
+		return nil
+	}
+
+	plugin.Disabled = disabled
+	l.Plugins[pluginID] = plugin
+	l.touch()
 
 	return nil
 }
@@ -200,13 +537,77 @@ func (l *Lockfile) GetPlugin(pluginID string) (PluginEntry, bool) {
 	return plugin, exists
 }
 
+// FindPluginByName returns the plugin with the given display name, or nil. Lookups use an
+// index built on first use; when several plugins share a name the lowest ID wins.
 func (l *Lockfile) FindPluginByName(name string) *PluginEntry {
-	for _, plugin := range l.Plugins {
-		if plugin.Name == name {
-			return &plugin
+	pluginID, ok := l.nameIndex()[name]
+	if !ok {
+		return nil
+	}
+
+	plugin, exists := l.Plugins[pluginID]
+	if !exists || plugin.Name != name {
+		// Plugins was modified directly rather than through the mutation methods
+		l.byName = nil
+		if pluginID, ok = l.nameIndex()[name]; !ok {
+			return nil
 		}
+		plugin = l.Plugins[pluginID]
 	}
-	return nil
+	return &plugin
+}
+
+// nameIndex returns the name index, rebuilding it if a mutation dropped it or the number
+// of plugins changed since it was built
+func (l *Lockfile) nameIndex() map[string]string {
+	if l.byName != nil && l.indexedPlugins == len(l.Plugins) {
+		return l.byName
+	}
+
+	l.byName = make(map[string]string, len(l.Plugins))
+	for pluginID, plugin := range l.Plugins {
+		if existing, ok := l.byName[plugin.Name]; !ok || pluginID < existing {
+			l.byName[plugin.Name] = pluginID
+		}
+	}
+	l.indexedPlugins = len(l.Plugins)
+	return l.byName
+}
+
+// Frozen reports whether the vault is frozen
+func (l *Lockfile) Frozen() bool {
+	return l.Metadata.FrozenAt != nil
+}
+
+// Freeze marks the vault frozen from at. Freezing a frozen vault keeps the original time.
+func (l *Lockfile) Freeze(at time.Time) {
+	if l.Frozen() {
+		return
+	}
+	at = at.UTC()
+	l.Metadata.FrozenAt = &at
+	l.touch()
+}
+
+// Unfreeze lifts a freeze
+func (l *Lockfile) Unfreeze() {
+	if !l.Frozen() {
+		return
+	}
+	l.Metadata.FrozenAt = nil
+	l.touch()
+}
+
+// touch records a mutation: the name index is dropped and the lockfile must be saved
+func (l *Lockfile) touch() {
+	l.UpdatedAt = time.Now().UTC()
+	l.byName = nil
+	l.dirty = true
+}
+
+// Dirty reports whether the lockfile was mutated since it was loaded or last saved
+func (l *Lockfile) Dirty() bool {
+	return l.dirty
 }
 
 func (l *Lockfile) ListPlugins() []PluginEntry {
@@ -223,19 +624,64 @@ func generatePluginID(name, ociReference string) string {
 	return hex.EncodeToString(hash[:8])
 }
 
+// GetLockfilePath returns the canonical lockfile location for the vault owning obsidianDir.
+// Lockfiles live in .dragonglass next to .obsidian; use vault.ResolveLockfile to also
+// pick up lockfiles left in .obsidian by older releases.
 func GetLockfilePath(obsidianDir string) string {
-	return filepath.Join(obsidianDir, LockfileName)
+	return filepath.Join(filepath.Dir(obsidianDir), DragonglassDirName, LockfileName)
 }
 
-func LoadLockfile(lockfilePath string) (*Lockfile, error) {
-	if _, err := os.Stat(lockfilePath); os.IsNotExist(err) {
-		vaultPath := filepath.Dir(filepath.Dir(lockfilePath))
-		return NewLockfile(vaultPath), nil
+// SignaturePath returns where the signature of the lockfile at lockfilePath is stored
+func SignaturePath(lockfilePath string) string {
+	return lockfilePath + SignatureExt
+}
+
+// VaultRoot returns the root of the vault owning the lockfile at lockfilePath: the closest
+// parent directory containing .obsidian, or the parent of the lockfile's directory when
+// there is none. The state directory holding the lockfile can be nested, e.g. in
+// .obsidian/dragonglass.
+func VaultRoot(lockfilePath string) string {
+	for dir := filepath.Dir(lockfilePath); ; {
+		if info, err := os.Stat(filepath.Join(dir, obsidianDirName)); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return filepath.Dir(filepath.Dir(lockfilePath))
+		}
+		dir = parent
 	}
+}
+
+func LoadLockfile(lockfilePath string) (*Lockfile, error) {
+	lockfile, _, err := ReadLockfile(lockfilePath)
+	return lockfile, err
+}
 
+// ReadLockfile loads the lockfile at lockfilePath as LoadLockfile does, and also returns
+// the bytes it was parsed from, e.g. to check a signature over exactly the plugin set
+// loaded. The bytes are nil when there is no lockfile yet.
+func ReadLockfile(lockfilePath string) (*Lockfile, []byte, error) {
 	data, err := os.ReadFile(lockfilePath)
+	if os.IsNotExist(err) {
+		return NewLockfile(VaultRoot(lockfilePath)), nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+
+	lockfile, err := parseLockfile(lockfilePath, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return lockfile, data, nil
+}
+
+// parseLockfile migrates, parses, and validates the lockfile data read from lockfilePath
+func parseLockfile(lockfilePath string, data []byte) (*Lockfile, error) {
+	data, err := prepareLockfileData(lockfilePath, data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+		return nil, err
 	}
 
 	var lockfile Lockfile
@@ -250,6 +696,9 @@ func LoadLockfile(lockfilePath string) (*Lockfile, error) {
 	return &lockfile, nil
 }
 
+// SaveLockfile writes the lockfile as indented JSON. Output is deterministic so vaults that
+// commit the lockfile get minimal diffs: struct fields keep their declared order and
+// encoding/json writes map keys (plugin IDs, file names) in sorted order.
 func SaveLockfile(lockfile *Lockfile, lockfilePath string) error {
 	if err := lockfile.Validate(); err != nil {
 		return fmt.Errorf("invalid lockfile: %w", err)
@@ -264,10 +713,11 @@ func SaveLockfile(lockfile *Lockfile, lockfilePath string) error {
 		return fmt.Errorf("failed to marshal lockfile: %w", err)
 	}
 
-	if err := os.WriteFile(lockfilePath, data, DefaultLockfilePerms); err != nil {
+	if err := os.WriteFile(lockfilePath, append(data, '\n'), DefaultLockfilePerms); err != nil {
 		return fmt.Errorf("failed to write lockfile: %w", err)
 	}
 
+	lockfile.dirty = false
 	return nil
 }
 
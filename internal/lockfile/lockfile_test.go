@@ -1,12 +1,16 @@
 package lockfile
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
 )
 
 func TestNewLockfile(t *testing.T) {
@@ -98,6 +102,23 @@ func TestLockfileValidation(t *testing.T) {
 			expectError: true,
 			errorMsg:    "OCI reference is required",
 		},
+		{
+			name: "dev build marked verified",
+			lockfile: Lockfile{
+				Version: "1",
+				Plugins: map[string]PluginEntry{
+					"test": {
+						Name:              "test-plugin",
+						OCIReference:      "https://github.com/test/plugin#main",
+						OCIDigest:         "sha256:abc123",
+						VerificationState: VerificationState{ProvenanceVerified: true, SBOMVerified: true},
+						Dev:               &DevSource{Repository: "https://github.com/test/plugin", Ref: "main"},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "dev builds cannot be marked verified",
+		},
 	}
 
 	for _, tt := range tests {
@@ -218,6 +239,45 @@ func TestRemovePlugin(t *testing.T) {
 	}
 }
 
+func TestFreeze(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockfileName)
+	lockfile := NewLockfile("/test/vault")
+	if err := SaveLockfile(lockfile, path); err != nil {
+		t.Fatal(err)
+	}
+
+	frozenAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	lockfile.Freeze(frozenAt)
+	if !lockfile.Frozen() || !lockfile.Dirty() {
+		t.Fatalf("expected a frozen, dirty lockfile")
+	}
+
+	// Freezing again keeps the original time
+	lockfile.Freeze(frozenAt.Add(time.Hour))
+	if err := SaveLockfile(lockfile, path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadLockfile(path)
+	if err != nil {
+		t.Fatalf("failed to load lockfile: %v", err)
+	}
+	if !loaded.Frozen() || !loaded.Metadata.FrozenAt.Equal(frozenAt) || loaded.Metadata.FrozenAt.Location() != time.UTC {
+		t.Errorf("expected frozen at %v in UTC, got %v", frozenAt, loaded.Metadata.FrozenAt)
+	}
+
+	loaded.Unfreeze()
+	if loaded.Frozen() || !loaded.Dirty() {
+		t.Errorf("expected an unfrozen, dirty lockfile")
+	}
+
+	unfrozen := NewLockfile("/test/vault")
+	unfrozen.Unfreeze()
+	if unfrozen.Dirty() {
+		t.Errorf("expected unfreezing an unfrozen lockfile to be a no-op")
+	}
+}
+
 func TestUpdatePluginVerification(t *testing.T) {
 	lockfile := NewLockfile("/test/vault")
 
@@ -271,6 +331,86 @@ func TestUpdatePluginVerification(t *testing.T) {
 	}
 }
 
+func TestVerificationHistory(t *testing.T) {
+	lockfile := NewLockfile("/test/vault")
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(i int) *time.Time {
+		t := base.Add(time.Duration(i) * time.Hour)
+		return &t
+	}
+
+	first := PluginEntry{
+		Name:              "test-plugin",
+		Version:           "1.0.0",
+		OCIReference:      "ghcr.io/test/plugin:v1.0.0",
+		OCIDigest:         "sha256:abc123",
+		InstalledAt:       at(0),
+		VerificationState: VerificationState{ProvenanceVerified: true, SBOMVerified: true, VerifiedAt: at(0)},
+	}
+	if err := lockfile.AddPlugin("test-plugin", first); err != nil {
+		t.Fatalf("failed to add plugin: %v", err)
+	}
+	entry := lockfile.Plugins["test-plugin"]
+	if entry.FirstInstalledAt == nil || !entry.FirstInstalledAt.Equal(*at(0)) {
+		t.Errorf("expected first install at %v, got %v", at(0), entry.FirstInstalledAt)
+	}
+	if len(entry.VerificationHistory) != 1 || !entry.VerificationHistory[0].Passed() {
+		t.Fatalf("expected one passing record, got %+v", entry.VerificationHistory)
+	}
+
+	// An update keeps the first install time and the history
+	second := first
+	second.Version = "1.1.0"
+	second.OCIDigest = "sha256:def456"
+	second.InstalledAt = at(1)
+	second.VerificationState = VerificationState{ProvenanceVerified: true, Errors: []string{"no SBOM"}, VerifiedAt: at(1)}
+	if err := lockfile.AddPlugin("test-plugin", second); err != nil {
+		t.Fatalf("failed to update plugin: %v", err)
+	}
+	entry = lockfile.Plugins["test-plugin"]
+	if !entry.FirstInstalledAt.Equal(*at(0)) {
+		t.Errorf("expected first install time to be kept, got %v", entry.FirstInstalledAt)
+	}
+	if !entry.LastVerified().Equal(*at(1)) {
+		t.Errorf("expected last verified at %v, got %v", at(1), entry.LastVerified())
+	}
+	if len(entry.VerificationHistory) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(entry.VerificationHistory))
+	}
+	if last := entry.VerificationHistory[1]; last.Passed() || last.Version != "1.1.0" || last.Errors != 1 {
+		t.Errorf("expected a failing record for 1.1.0, got %+v", last)
+	}
+
+	// Recording the same verification again adds nothing
+	if err := lockfile.UpdatePluginVerification("test-plugin", entry.VerificationState); err != nil {
+		t.Fatalf("failed to update verification: %v", err)
+	}
+	if n := len(lockfile.Plugins["test-plugin"].VerificationHistory); n != 2 {
+		t.Errorf("expected a repeated verification not to be recorded, got %d records", n)
+	}
+
+	// Only the newest MaxVerificationHistory records are kept
+	tx := lockfile.Begin(filepath.Join(t.TempDir(), LockfileName))
+	for i := 2; i < MaxVerificationHistory+5; i++ {
+		if err := tx.UpdatePluginVerification("test-plugin", VerificationState{VerifiedAt: at(i)}); err != nil {
+			t.Fatalf("failed to update verification: %v", err)
+		}
+	}
+	history := lockfile.Plugins["test-plugin"].VerificationHistory
+	if len(history) != MaxVerificationHistory {
+		t.Fatalf("expected %d records, got %d", MaxVerificationHistory, len(history))
+	}
+	if want := at(MaxVerificationHistory + 4); !history[len(history)-1].VerifiedAt.Equal(*want) {
+		t.Errorf("expected the newest record last, got %v", history[len(history)-1].VerifiedAt)
+	}
+
+	// Rolling back restores the history from Begin
+	tx.Rollback()
+	if n := len(lockfile.Plugins["test-plugin"].VerificationHistory); n != 2 {
+		t.Errorf("expected rollback to restore 2 records, got %d", n)
+	}
+}
+
 func TestGetAndFindPlugin(t *testing.T) {
 	lockfile := NewLockfile("/test/vault")
 
@@ -404,6 +544,7 @@ func TestLoadSaveLockfile(t *testing.T) {
 	}
 
 	// Add a plugin
+	verifiedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
 	plugin := PluginEntry{
 		Name:         "test-plugin",
 		Version:      "1.0.0",
@@ -413,6 +554,12 @@ func TestLoadSaveLockfile(t *testing.T) {
 			ProvenanceVerified: true,
 			SBOMVerified:       true,
 			VulnScanPassed:     true,
+			BuilderID:          "https://github.com/test/plugin/.github/workflows/build.yml@refs/heads/main",
+			SLSALevel:          2,
+			SBOMFormat:         "SPDX-2.3",
+			Vulnerabilities:    &VulnerabilityCounts{Low: 2},
+			VerifiedAt:         &verifiedAt,
+			PolicyHash:         "sha256:0123",
 		},
 		Metadata: PluginMetadata{
 			Author:      "Test Author",
@@ -458,6 +605,16 @@ func TestLoadSaveLockfile(t *testing.T) {
 			if !p.VerificationState.ProvenanceVerified {
 				t.Error("expected provenance to be verified in loaded lockfile")
 			}
+			state := p.VerificationState
+			if state.SLSALevel != 2 || state.SBOMFormat != "SPDX-2.3" || state.PolicyHash != "sha256:0123" {
+				t.Errorf("expected verification details to round-trip, got %+v", state)
+			}
+			if state.Vulnerabilities == nil || state.Vulnerabilities.Total() != 2 {
+				t.Errorf("expected vulnerability counts to round-trip, got %+v", state.Vulnerabilities)
+			}
+			if state.VerifiedAt == nil || !state.VerifiedAt.Equal(verifiedAt) {
+				t.Errorf("expected verification time to round-trip, got %v", state.VerifiedAt)
+			}
 			if len(p.Metadata.Tags) != 2 {
 				t.Errorf("expected 2 tags, got %d", len(p.Metadata.Tags))
 			}
@@ -492,6 +649,37 @@ func TestLoadSaveLockfile(t *testing.T) {
 	}
 }
 
+func TestReadLockfile(t *testing.T) {
+	lockfilePath := filepath.Join(t.TempDir(), LockfileName)
+
+	lockfile, data, err := ReadLockfile(lockfilePath)
+	if err != nil || data != nil || lockfile.Version != LockfileVersion {
+		t.Fatalf("expected a new lockfile and no data when there is no lockfile, got %+v, %q, %v", lockfile, data, err)
+	}
+
+	if err := lockfile.AddPlugin("test-plugin", PluginEntry{Name: "test-plugin", Version: "1.0.0", OCIReference: "ghcr.io/test/plugin:v1.0.0", OCIDigest: "sha256:abc123def456"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveLockfile(lockfile, lockfilePath); err != nil {
+		t.Fatal(err)
+	}
+	written, err := os.ReadFile(lockfilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, data, err := ReadLockfile(lockfilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, written) {
+		t.Errorf("expected the bytes the lockfile was parsed from, got %q", data)
+	}
+	if _, ok := loaded.Plugins["test-plugin"]; !ok {
+		t.Errorf("expected the saved plugin, got %v", loaded.Plugins)
+	}
+}
+
 func TestLoadFromObsidianDirectory(t *testing.T) {
 	tempDir := t.TempDir()
 	obsidianDir := filepath.Join(tempDir, ".obsidian")
@@ -549,10 +737,260 @@ func TestLoadFromObsidianDirectory(t *testing.T) {
 
 func TestGetLockfilePath(t *testing.T) {
 	obsidianDir := "/path/to/.obsidian"
-	expected := filepath.Join(obsidianDir, LockfileName)
+	expected := filepath.Join("/path/to", DragonglassDirName, LockfileName)
 	result := GetLockfilePath(obsidianDir)
 
 	if result != expected {
 		t.Errorf("expected %s, got %s", expected, result)
 	}
 }
+
+func TestCheckBlobs(t *testing.T) {
+	entry := PluginEntry{
+		OCIBlobs: &BlobDigests{
+			Config: "sha256:config",
+			Layers: []string{"sha256:main", "sha256:styles"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		actual   BlobDigests
+		errorMsg string
+	}{
+		{name: "matching blobs", actual: BlobDigests{Config: "sha256:config", Layers: []string{"sha256:main", "sha256:styles"}}},
+		{name: "swapped config", actual: BlobDigests{Config: "sha256:other", Layers: []string{"sha256:main", "sha256:styles"}}, errorMsg: "config digest mismatch"},
+		{name: "swapped layer", actual: BlobDigests{Config: "sha256:config", Layers: []string{"sha256:main", "sha256:evil"}}, errorMsg: "layer 1 digest mismatch"},
+		{name: "extra layer", actual: BlobDigests{Config: "sha256:config", Layers: []string{"sha256:main", "sha256:styles", "sha256:extra"}}, errorMsg: "references 3 layers, lockfile records 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := entry.CheckBlobs(tt.actual)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, dgerrors.ErrDigestMismatch) || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("expected digest mismatch containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+
+	// Entries recorded before blob digests existed are not checked
+	if err := (PluginEntry{}).CheckBlobs(BlobDigests{Config: "sha256:anything"}); err != nil {
+		t.Errorf("expected legacy entry to pass, got %v", err)
+	}
+}
+
+func TestLockedReference(t *testing.T) {
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name     string
+		entry    PluginEntry
+		expected string
+	}{
+		{name: "version tag", entry: PluginEntry{OCIReference: "ghcr.io/owner/plugin:1.2.3", OCIDigest: digest}, expected: "ghcr.io/owner/plugin:1.2.3"},
+		{name: "mutable tag", entry: PluginEntry{OCIReference: "ghcr.io/owner/plugin:latest", OCIDigest: digest, MutableTag: true}, expected: "ghcr.io/owner/plugin@" + digest},
+		{name: "mutable tag without digest", entry: PluginEntry{OCIReference: "ghcr.io/owner/plugin:latest", MutableTag: true}, expected: "ghcr.io/owner/plugin:latest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.LockedReference(); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDowngrade(t *testing.T) {
+	earlier := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := earlier.Add(24 * time.Hour)
+	entry := func(version string, builtAt *time.Time) PluginEntry {
+		return PluginEntry{Version: version, VerificationState: VerificationState{BuiltAt: builtAt}}
+	}
+
+	tests := []struct {
+		name      string
+		installed PluginEntry
+		next      PluginEntry
+		want      string
+	}{
+		{name: "upgrade", installed: entry("1.2.0", nil), next: entry("1.3.0", nil)},
+		{name: "lower version", installed: entry("1.2.0", nil), next: entry("1.1.9", nil), want: "version 1.1.9 is lower than the installed 1.2.0"},
+		{name: "v prefix", installed: entry("v1.2.0", nil), next: entry("1.2.0", nil)},
+		{name: "prerelease of installed version", installed: entry("1.2.0", nil), next: entry("1.2.0-beta.1", nil), want: "lower than the installed"},
+		{name: "same version older build", installed: entry("1.2.0", &later), next: entry("1.2.0", &earlier), want: "before the installed build"},
+		{name: "same version newer build", installed: entry("1.2.0", &earlier), next: entry("1.2.0", &later)},
+		{name: "higher version older build", installed: entry("2.0.0", &later), next: entry("2.1.0", &earlier)},
+		{name: "same version unknown build time", installed: entry("1.2.0", &later), next: entry("1.2.0", nil)},
+		{name: "non-semver older build", installed: entry("nightly", &later), next: entry("nightly", &earlier), want: "before the installed build"},
+		{name: "non-semver versions differ", installed: entry("nightly", &later), next: entry("edge", &earlier)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Downgrade(tt.installed, tt.next)
+			if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+				t.Errorf("expected downgrade %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSourceTreeURL(t *testing.T) {
+	const commit = "77ab745aee787d519642a87ed8f68be12fdc4b0d"
+	tests := []struct {
+		uri  string
+		repo string
+		tree string
+	}{
+		{
+			uri:  "git+https://github.com/owner/repo@refs/heads/main",
+			repo: "https://github.com/owner/repo",
+			tree: "https://github.com/owner/repo/tree/" + commit,
+		},
+		{
+			uri:  "git+https://github.com/owner/repo.git@refs/tags/v1.0.0",
+			repo: "https://github.com/owner/repo",
+			tree: "https://github.com/owner/repo/tree/" + commit,
+		},
+		{
+			uri:  "https://github.com/owner/repo",
+			repo: "https://github.com/owner/repo",
+			tree: "https://github.com/owner/repo/tree/" + commit,
+		},
+		{
+			uri:  "git+https://gitlab.com/owner/repo@refs/heads/main",
+			repo: "https://gitlab.com/owner/repo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			repo := SourceRepositoryURL(tt.uri)
+			if repo != tt.repo {
+				t.Errorf("expected repository %s, got %s", tt.repo, repo)
+			}
+			state := VerificationState{SourceRepository: repo, SourceCommit: commit}
+			if got := state.SourceTreeURL(); got != tt.tree {
+				t.Errorf("expected tree URL %q, got %q", tt.tree, got)
+			}
+		})
+	}
+
+	if got := (VerificationState{SourceRepository: "https://github.com/owner/repo"}).SourceTreeURL(); got != "" {
+		t.Errorf("expected no tree URL without a commit, got %s", got)
+	}
+}
+
+func TestVerificationStateRegressions(t *testing.T) {
+	verified := VerificationState{
+		ProvenanceVerified: true,
+		SBOMVerified:       true,
+		VulnScanPassed:     true,
+		BuilderID:          "https://github.com/owner/repo/.github/workflows/build.yml@refs/tags/1.0.0",
+		SLSALevel:          2,
+		Errors:             []string{"known issue"},
+	}
+
+	tests := []struct {
+		name     string
+		current  func(s VerificationState) VerificationState
+		expected []string
+	}{
+		{name: "unchanged", current: func(s VerificationState) VerificationState { return s }},
+		{
+			name: "vulnerability scan fails",
+			current: func(s VerificationState) VerificationState {
+				s.VulnScanPassed = false
+				return s
+			},
+			expected: []string{"vulnerability scan no longer passes"},
+		},
+		{
+			name: "signature no longer verifies",
+			current: func(s VerificationState) VerificationState {
+				s.ProvenanceVerified, s.SLSALevel = false, 1
+				s.Errors = append(s.Errors, "certificate not trusted")
+				return s
+			},
+			expected: []string{"provenance no longer verifies", "SLSA level dropped from 2 to 1", "certificate not trusted"},
+		},
+		{
+			name: "builder changed",
+			current: func(s VerificationState) VerificationState {
+				s.BuilderID = "https://github.com/other/repo/.github/workflows/build.yml@refs/tags/1.0.0"
+				return s
+			},
+			expected: []string{"builder changed from https://github.com/owner/repo/.github/workflows/build.yml@refs/tags/1.0.0 to https://github.com/other/repo/.github/workflows/build.yml@refs/tags/1.0.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := verified.Regressions(tt.current(verified))
+			if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("expected regressions %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	// Improvements are not regressions
+	improved := verified
+	improved.Errors = nil
+	if got := (VerificationState{}).Regressions(improved); len(got) != 0 {
+		t.Errorf("expected no regressions for a newly verified plugin, got %q", got)
+	}
+}
+
+func TestSaveLockfileDeterministic(t *testing.T) {
+	updated := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	ids := []string{"zeta", "alpha", "mid", "beta", "omega"}
+
+	build := func(order []string) *Lockfile {
+		lf := NewLockfile("/vault")
+		lf.GeneratedAt, lf.UpdatedAt = updated, updated
+		for _, id := range order {
+			lf.Plugins[id] = PluginEntry{
+				Name:         id,
+				OCIReference: "ghcr.io/owner/" + id + ":v1.0.0",
+				OCIDigest:    "sha256:" + id,
+				Files:        map[string]string{"styles.css": "sha256:c", "main.js": "sha256:a", "manifest.json": "sha256:b"},
+			}
+		}
+		return lf
+	}
+
+	reversed := make([]string, len(ids))
+	for i, id := range ids {
+		reversed[len(ids)-1-i] = id
+	}
+
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.json"), filepath.Join(dir, "second.json")
+	if err := SaveLockfile(build(ids), first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := SaveLockfile(build(reversed), second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a, _ := os.ReadFile(first)
+	b, _ := os.ReadFile(second)
+	if string(a) != string(b) {
+		t.Fatalf("expected identical output regardless of insertion order:\n%s\n---\n%s", a, b)
+	}
+	if !strings.HasSuffix(string(a), "}\n") {
+		t.Error("expected lockfile to end with a newline")
+	}
+	if strings.Index(string(a), `"alpha"`) > strings.Index(string(a), `"zeta"`) {
+		t.Error("expected plugins in sorted order")
+	}
+	if strings.Index(string(a), `"main.js"`) > strings.Index(string(a), `"styles.css"`) {
+		t.Error("expected files in sorted order")
+	}
+}
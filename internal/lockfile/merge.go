@@ -0,0 +1,141 @@
+// ABOUTME: Semantic three-way merge of lockfiles changed on diverging git branches
+// ABOUTME: Unions plugins and resolves conflicting pins to the highest verified version
+package lockfile
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/semver"
+)
+
+// MergeConflict is a plugin both sides pinned differently with no rule to pick one. The
+// merged lockfile keeps our entry for it.
+type MergeConflict struct {
+	PluginID string
+	Ours     PluginEntry
+	Theirs   PluginEntry
+}
+
+func (c MergeConflict) String() string {
+	return fmt.Sprintf("%s: ours pins %s@%s, theirs pins %s@%s", c.PluginID, c.Ours.Version, c.Ours.OCIDigest, c.Theirs.Version, c.Theirs.OCIDigest)
+}
+
+// Merge combines lockfiles changed independently from a common base (nil when the file
+// did not exist there). Plugins added on either side are kept. A plugin removed on one
+// side stays removed unless the other side changed it. When both sides pin a plugin
+// differently, a verified entry beats an unverified one and then the higher version wins.
+// Entries are taken whole, so the verification state always describes the chosen digest.
+// A freeze set or lifted on either side is kept.
+func Merge(base, ours, theirs *Lockfile) (*Lockfile, []MergeConflict) {
+	if base == nil {
+		base = &Lockfile{Plugins: map[string]PluginEntry{}}
+	}
+
+	merged := *ours
+	merged.Plugins = make(map[string]PluginEntry, len(ours.Plugins)+len(theirs.Plugins))
+	merged.byName = nil
+	if theirs.UpdatedAt.After(merged.UpdatedAt) {
+		merged.UpdatedAt = theirs.UpdatedAt
+	}
+
+	// A freeze, or lifting it, on their side carries over unless we left it unchanged
+	if ours.Frozen() == base.Frozen() {
+		merged.Metadata.FrozenAt = theirs.Metadata.FrozenAt
+	}
+
+	var conflicts []MergeConflict
+	for _, pluginID := range unionIDs(base, ours, theirs) {
+		baseEntry, inBase := base.Plugins[pluginID]
+		ourEntry, inOurs := ours.Plugins[pluginID]
+		theirEntry, inTheirs := theirs.Plugins[pluginID]
+
+		switch {
+		case inOurs && inTheirs:
+			entry, ok := pickEntry(baseEntry, inBase, ourEntry, theirEntry)
+			if !ok {
+				conflicts = append(conflicts, MergeConflict{PluginID: pluginID, Ours: ourEntry, Theirs: theirEntry})
+			}
+			merged.Plugins[pluginID] = entry
+		case inOurs:
+			// Removed by them: keep only if we changed it since the base
+			if !inBase || !samePin(ourEntry, baseEntry) {
+				merged.Plugins[pluginID] = ourEntry
+			}
+		case inTheirs:
+			if !inBase || !samePin(theirEntry, baseEntry) {
+				merged.Plugins[pluginID] = theirEntry
+			}
+		}
+	}
+
+	merged.dirty = true
+	return &merged, conflicts
+}
+
+// pickEntry chooses between two entries for the same plugin. ok is false when neither
+// rule applies, in which case ours is returned.
+func pickEntry(base PluginEntry, inBase bool, ours, theirs PluginEntry) (entry PluginEntry, ok bool) {
+	switch {
+	case samePin(ours, theirs):
+		// Same artifact; keep the more recently verified state
+		if verifiedAt(theirs).After(verifiedAt(ours)) {
+			return theirs, true
+		}
+		return ours, true
+	case inBase && samePin(ours, base):
+		return theirs, true
+	case inBase && samePin(theirs, base):
+		return ours, true
+	case ours.Verified() != theirs.Verified():
+		if theirs.Verified() {
+			return theirs, true
+		}
+		return ours, true
+	}
+
+	ourVersion, ourErr := semver.Parse(ours.Version)
+	theirVersion, theirErr := semver.Parse(theirs.Version)
+	if ourErr != nil || theirErr != nil {
+		return ours, false
+	}
+	switch ourVersion.Compare(theirVersion) {
+	case 1:
+		return ours, true
+	case -1:
+		return theirs, true
+	default:
+		// Same version, different artifacts: a retag only a person can judge
+		return ours, false
+	}
+}
+
+// samePin reports whether two entries pin the same artifact
+func samePin(a, b PluginEntry) bool {
+	return a.OCIReference == b.OCIReference && a.OCIDigest == b.OCIDigest
+}
+
+// verifiedAt returns when the entry was verified, or the zero time
+func verifiedAt(e PluginEntry) time.Time {
+	if e.VerificationState.VerifiedAt == nil {
+		return time.Time{}
+	}
+	return *e.VerificationState.VerifiedAt
+}
+
+// unionIDs returns every plugin ID in any of the lockfiles, sorted
+func unionIDs(lockfiles ...*Lockfile) []string {
+	seen := map[string]bool{}
+	var ids []string
+	for _, l := range lockfiles {
+		for pluginID := range l.Plugins {
+			if !seen[pluginID] {
+				seen[pluginID] = true
+				ids = append(ids, pluginID)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
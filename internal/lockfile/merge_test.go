@@ -0,0 +1,160 @@
+package lockfile
+
+import (
+	"testing"
+	"time"
+)
+
+func mergeEntry(version, digest string, verified bool) PluginEntry {
+	return PluginEntry{
+		Name:              "Plugin",
+		Version:           version,
+		OCIReference:      "ghcr.io/owner/repo/plugin:v" + version,
+		OCIDigest:         digest,
+		VerificationState: VerificationState{ProvenanceVerified: verified, SBOMVerified: verified},
+	}
+}
+
+func lockfileWith(entries map[string]PluginEntry) *Lockfile {
+	l := NewLockfile("/vault")
+	for id, entry := range entries {
+		l.Plugins[id] = entry
+	}
+	return l
+}
+
+func TestMerge(t *testing.T) {
+	v1 := mergeEntry("1.0.0", "sha256:v1", true)
+	v2 := mergeEntry("1.1.0", "sha256:v2", true)
+	v3 := mergeEntry("2.0.0", "sha256:v3", true)
+	v3Unverified := mergeEntry("2.0.0", "sha256:v3", false)
+	retag := mergeEntry("1.1.0", "sha256:retag", true)
+
+	tests := []struct {
+		name      string
+		base      map[string]PluginEntry
+		ours      map[string]PluginEntry
+		theirs    map[string]PluginEntry
+		expected  map[string]PluginEntry
+		conflicts int
+	}{
+		{
+			name:     "additions on both sides",
+			ours:     map[string]PluginEntry{"a": v1},
+			theirs:   map[string]PluginEntry{"b": v2},
+			expected: map[string]PluginEntry{"a": v1, "b": v2},
+		},
+		{
+			name:     "change on one side only",
+			base:     map[string]PluginEntry{"a": v1},
+			ours:     map[string]PluginEntry{"a": v1},
+			theirs:   map[string]PluginEntry{"a": v2},
+			expected: map[string]PluginEntry{"a": v2},
+		},
+		{
+			name:     "both upgraded, higher version wins",
+			base:     map[string]PluginEntry{"a": v1},
+			ours:     map[string]PluginEntry{"a": v3},
+			theirs:   map[string]PluginEntry{"a": v2},
+			expected: map[string]PluginEntry{"a": v3},
+		},
+		{
+			name:     "verified beats higher unverified",
+			base:     map[string]PluginEntry{"a": v1},
+			ours:     map[string]PluginEntry{"a": v3Unverified},
+			theirs:   map[string]PluginEntry{"a": v2},
+			expected: map[string]PluginEntry{"a": v2},
+		},
+		{
+			name:     "removed on one side, untouched on the other",
+			base:     map[string]PluginEntry{"a": v1, "b": v1},
+			ours:     map[string]PluginEntry{"a": v1},
+			theirs:   map[string]PluginEntry{"a": v1, "b": v1},
+			expected: map[string]PluginEntry{"a": v1},
+		},
+		{
+			name:     "removed on one side, changed on the other",
+			base:     map[string]PluginEntry{"a": v1},
+			ours:     map[string]PluginEntry{},
+			theirs:   map[string]PluginEntry{"a": v2},
+			expected: map[string]PluginEntry{"a": v2},
+		},
+		{
+			name:      "same version, different artifacts",
+			base:      map[string]PluginEntry{"a": v1},
+			ours:      map[string]PluginEntry{"a": v2},
+			theirs:    map[string]PluginEntry{"a": retag},
+			expected:  map[string]PluginEntry{"a": v2},
+			conflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var base *Lockfile
+			if tt.base != nil {
+				base = lockfileWith(tt.base)
+			}
+
+			merged, conflicts := Merge(base, lockfileWith(tt.ours), lockfileWith(tt.theirs))
+			if len(conflicts) != tt.conflicts {
+				t.Errorf("expected %d conflicts, got %v", tt.conflicts, conflicts)
+			}
+			if len(merged.Plugins) != len(tt.expected) {
+				t.Fatalf("expected plugins %v, got %v", tt.expected, merged.Plugins)
+			}
+			for id, want := range tt.expected {
+				if got := merged.Plugins[id]; got.OCIDigest != want.OCIDigest || got.Verified() != want.Verified() {
+					t.Errorf("plugin %s: expected %s (verified %v), got %s (verified %v)", id, want.OCIDigest, want.Verified(), got.OCIDigest, got.Verified())
+				}
+			}
+		})
+	}
+}
+
+func TestMergeKeepsLatestVerification(t *testing.T) {
+	older := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(24 * time.Hour)
+
+	ours := mergeEntry("1.0.0", "sha256:v1", true)
+	ours.VerificationState.VerifiedAt = &older
+	theirs := ours
+	theirs.VerificationState.VerifiedAt = &newer
+
+	merged, _ := Merge(nil, lockfileWith(map[string]PluginEntry{"a": ours}), lockfileWith(map[string]PluginEntry{"a": theirs}))
+	if got := merged.Plugins["a"].VerificationState.VerifiedAt; got == nil || !got.Equal(newer) {
+		t.Errorf("expected the more recent verification to be kept, got %v", got)
+	}
+}
+
+func TestMergeFreeze(t *testing.T) {
+	frozenAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	frozen := func() *Lockfile {
+		l := lockfileWith(nil)
+		l.Freeze(frozenAt)
+		return l
+	}
+
+	tests := []struct {
+		name     string
+		base     *Lockfile
+		ours     *Lockfile
+		theirs   *Lockfile
+		expected bool
+	}{
+		{name: "frozen by them", base: lockfileWith(nil), ours: lockfileWith(nil), theirs: frozen(), expected: true},
+		{name: "frozen by us", base: lockfileWith(nil), ours: frozen(), theirs: lockfileWith(nil), expected: true},
+		{name: "lifted by them", base: frozen(), ours: frozen(), theirs: lockfileWith(nil), expected: false},
+		{name: "lifted by us", base: frozen(), ours: lockfileWith(nil), theirs: frozen(), expected: false},
+		{name: "no base, frozen by them", ours: lockfileWith(nil), theirs: frozen(), expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, _ := Merge(tt.base, tt.ours, tt.theirs)
+			if merged.Frozen() != tt.expected {
+				t.Errorf("expected frozen %v, got %v", tt.expected, merged.Frozen())
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+// ABOUTME: Schema migrations and validation applied when lockfiles are loaded
+// ABOUTME: Rejects lockfiles written by newer dragonglass releases with a clear error
+package lockfile
+
+import (
+	"github.com/gillisandrew/dragonglass-poc/internal/migrate"
+	"github.com/gillisandrew/dragonglass-poc/internal/schema"
+)
+
+// lockfileMigrator upgrades older lockfiles to the current schema version. Register a
+// Step here whenever LockfileVersion is bumped.
+var lockfileMigrator = &migrate.Migrator{
+	Kind:    "lockfile",
+	Current: LockfileVersion,
+}
+
+// prepareLockfileData migrates lockfile contents in place if needed and validates them
+// against the lockfile schema
+func prepareLockfileData(path string, data []byte) ([]byte, error) {
+	data, _, err := lockfileMigrator.MigrateFile(path, data, DefaultLockfilePerms)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := schema.Lockfile.Validate(data, path); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,100 @@
+// ABOUTME: Batched lockfile mutations written to disk once, and only when something changed
+// ABOUTME: Keeps large lockfiles from being rewritten for every plugin a command touches
+package lockfile
+
+import (
+	"fmt"
+	"maps"
+	"time"
+)
+
+// Transaction groups lockfile mutations so they are saved with a single write. Mutations
+// apply to the lockfile immediately; Rollback restores the state from Begin.
+type Transaction struct {
+	lockfile *Lockfile
+	path     string
+
+	// State at Begin, restored by Rollback
+	plugins   map[string]PluginEntry
+	updatedAt time.Time
+	dirty     bool
+
+	done bool
+}
+
+// Begin starts a transaction that will save the lockfile to lockfilePath on Commit
+func (l *Lockfile) Begin(lockfilePath string) *Transaction {
+	return &Transaction{
+		lockfile:  l,
+		path:      lockfilePath,
+		plugins:   maps.Clone(l.Plugins),
+		updatedAt: l.UpdatedAt,
+		dirty:     l.dirty,
+	}
+}
+
+// AddPlugin adds or replaces a plugin within the transaction
+func (tx *Transaction) AddPlugin(pluginID string, plugin PluginEntry) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	return tx.lockfile.AddPlugin(pluginID, plugin)
+}
+
+// RemovePlugin removes a plugin within the transaction
+func (tx *Transaction) RemovePlugin(pluginID string) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	return tx.lockfile.RemovePlugin(pluginID)
+}
+
+// UpdatePluginVerification replaces a plugin's verification state within the transaction
+func (tx *Transaction) UpdatePluginVerification(pluginID string, verification VerificationState) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	return tx.lockfile.UpdatePluginVerification(pluginID, verification)
+}
+
+// SetPluginDisabled records whether a plugin is switched off within the transaction
+func (tx *Transaction) SetPluginDisabled(pluginID string, disabled bool) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	return tx.lockfile.SetPluginDisabled(pluginID, disabled)
+}
+
+// Commit writes the lockfile if any mutation changed it and ends the transaction
+func (tx *Transaction) Commit() error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	tx.done = true
+
+	if !tx.lockfile.Dirty() {
+		return nil
+	}
+	return SaveLockfile(tx.lockfile, tx.path)
+}
+
+// Rollback discards the transaction's mutations. It is a no-op after Commit, so it can
+// be deferred.
+func (tx *Transaction) Rollback() {
+	if tx.done {
+		return
+	}
+	tx.done = true
+
+	tx.lockfile.Plugins = tx.plugins
+	tx.lockfile.UpdatedAt = tx.updatedAt
+	tx.lockfile.dirty = tx.dirty
+	tx.lockfile.byName = nil
+}
+
+func (tx *Transaction) check() error {
+	if tx.done {
+		return fmt.Errorf("lockfile transaction already finished")
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package lockfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockfileName)
+	lockfile := NewLockfile("/vault")
+	entry := PluginEntry{Name: "Calendar", OCIReference: "ghcr.io/owner/calendar:v1.0.0", OCIDigest: "sha256:abc"}
+
+	// A transaction without mutations does not write the file
+	if err := lockfile.Begin(path).Commit(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected no lockfile to be written, got %v", err)
+	}
+
+	tx := lockfile.Begin(path)
+	if err := tx.AddPlugin("calendar", entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := tx.AddPlugin("dataview", PluginEntry{Name: "Dataview", OCIReference: "ghcr.io/owner/dataview:v1.0.0", OCIDigest: "sha256:def"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lockfile.Dirty() {
+		t.Error("expected lockfile to be clean after commit")
+	}
+	if err := tx.AddPlugin("late", entry); err == nil {
+		t.Error("expected error for a mutation after commit")
+	}
+
+	loaded, err := LoadLockfile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(loaded.Plugins) != 2 {
+		t.Errorf("expected 2 committed plugins, got %d", len(loaded.Plugins))
+	}
+
+	// Rolled back mutations are discarded from memory and never written
+	tx = lockfile.Begin(path)
+	if err := tx.RemovePlugin("calendar"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tx.Rollback()
+	if _, ok := lockfile.GetPlugin("calendar"); !ok || lockfile.Dirty() {
+		t.Errorf("expected rollback to restore calendar and leave the lockfile clean")
+	}
+	if lockfile.FindPluginByName("Calendar") == nil {
+		t.Error("expected rolled back plugin to be found by name")
+	}
+}
+
+func TestFindPluginByNameIndex(t *testing.T) {
+	lockfile := NewLockfile("/vault")
+	lockfile.Plugins["b"] = PluginEntry{Name: "Shared"}
+	lockfile.Plugins["a"] = PluginEntry{Name: "Shared"}
+
+	if found := lockfile.FindPluginByName("Shared"); found == nil || lockfile.byName["Shared"] != "a" {
+		t.Fatalf("expected the lowest ID to win for a shared name, got %v", lockfile.byName)
+	}
+
+	// Mutations through methods drop the index
+	if err := lockfile.RemovePlugin("a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found := lockfile.FindPluginByName("Shared"); found == nil || lockfile.byName["Shared"] != "b" {
+		t.Errorf("expected the index to be rebuilt after removal, got %v", lockfile.byName)
+	}
+
+	// Direct writes to Plugins are picked up too
+	lockfile.Plugins["c"] = PluginEntry{Name: "Direct"}
+	if lockfile.FindPluginByName("Direct") == nil {
+		t.Error("expected a plugin added directly to the map to be found")
+	}
+	lockfile.Plugins["c"] = PluginEntry{Name: "Renamed"}
+	if lockfile.FindPluginByName("Direct") != nil || lockfile.FindPluginByName("Renamed") == nil {
+		t.Error("expected a plugin renamed directly in the map to be found under its new name")
+	}
+}
+
+func TestSetPluginDisabled(t *testing.T) {
+	lockfile := NewLockfile("/vault")
+	if err := lockfile.AddPlugin("calendar", PluginEntry{Name: "Calendar", OCIReference: "ghcr.io/owner/calendar:v1.0.0", OCIDigest: "sha256:abc"}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), LockfileName)
+	if err := SaveLockfile(lockfile, path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Enabling an enabled plugin changes nothing
+	if err := lockfile.SetPluginDisabled("calendar", false); err != nil || lockfile.Dirty() {
+		t.Fatalf("expected no change, got dirty=%v (%v)", lockfile.Dirty(), err)
+	}
+
+	tx := lockfile.Begin(path)
+	if err := tx.SetPluginDisabled("calendar", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadLockfile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := loaded.GetPlugin("calendar"); !entry.Disabled {
+		t.Error("expected calendar to be saved as disabled")
+	}
+
+	if err := lockfile.SetPluginDisabled("missing", true); err == nil {
+		t.Error("expected an error for a plugin not in the lockfile")
+	}
+}
@@ -0,0 +1,189 @@
+// ABOUTME: Project manifest (dragonglass.json) declaring desired plugins, version ranges, and policy
+// ABOUTME: Separates user intent (manifest) from resolved, pinned state (lockfile)
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/semver"
+)
+
+const (
+	ManifestFileName     = "dragonglass.json"
+	ManifestVersion      = "1"
+	DefaultManifestPerms = 0644
+)
+
+// Manifest declares the plugins a vault should have installed
+type Manifest struct {
+	Version string                `json:"version"`
+	Plugins map[string]PluginSpec `json:"plugins"`
+}
+
+// PluginSpec declares where a plugin is published and which versions are acceptable
+type PluginSpec struct {
+	// OCI repository without tag, e.g. "ghcr.io/owner/repo/plugin-id"
+	Repository string `json:"repository"`
+
+	// npm-style version range, e.g. "^1.2.0" (empty means any release)
+	Version string `json:"version,omitempty"`
+
+	// Verification policy overrides for this plugin only (optional)
+	Policy *PolicyOverride `json:"policy,omitempty"`
+}
+
+// PolicyOverride adjusts verification and update policy for a single declared plugin.
+// Its settings take precedence over the vault configuration.
+type PolicyOverride struct {
+	// Require valid attestations and metadata regardless of the vault configuration
+	StrictMode *bool `json:"strict_mode,omitempty"`
+
+	// Workflow identity that must have built this plugin
+	TrustedBuilder string `json:"trusted_builder,omitempty"`
+
+	// Repository, as owner/name, the provenance must name as the plugin's source
+	SourceRepository string `json:"source_repository,omitempty"`
+
+	// Shortest time between updates, e.g. "168h" for at most weekly
+	MinUpdateInterval config.Duration `json:"min_update_interval,omitempty"`
+
+	// Never update the plugin once it is locked
+	Hold bool `json:"hold,omitempty"`
+}
+
+// UpdateDue reports whether a plugin last installed at installedAt may be updated at now.
+// Held plugins are never updated, and updates wait for MinUpdateInterval to pass.
+func (p *PolicyOverride) UpdateDue(installedAt *time.Time, now time.Time) bool {
+	if p == nil {
+		return true
+	}
+	if p.Hold {
+		return false
+	}
+	if p.MinUpdateInterval == 0 || installedAt == nil {
+		return true
+	}
+	return !now.Before(installedAt.Add(p.MinUpdateInterval.Duration()))
+}
+
+// Constraint parses the declared version range
+func (s PluginSpec) Constraint() (*semver.Constraint, error) {
+	return semver.ParseConstraint(s.Version)
+}
+
+func NewManifest() *Manifest {
+	return &Manifest{
+		Version: ManifestVersion,
+		Plugins: make(map[string]PluginSpec),
+	}
+}
+
+func (m *Manifest) Validate() error {
+	if m.Version == "" {
+		return fmt.Errorf("manifest version is required")
+	}
+
+	if m.Plugins == nil {
+		return fmt.Errorf("plugins map cannot be nil")
+	}
+
+	for pluginID, spec := range m.Plugins {
+		if spec.Repository == "" {
+			return fmt.Errorf("plugin %s: repository is required", pluginID)
+		}
+		if hasTagOrDigest(spec.Repository) {
+			return fmt.Errorf("plugin %s: repository must not include a tag or digest", pluginID)
+		}
+		if _, err := spec.Constraint(); err != nil {
+			return fmt.Errorf("plugin %s: %w", pluginID, err)
+		}
+		if err := spec.Policy.validate(); err != nil {
+			return fmt.Errorf("plugin %s: %w", pluginID, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *PolicyOverride) validate() error {
+	if p == nil {
+		return nil
+	}
+	if p.SourceRepository != "" && strings.Count(p.SourceRepository, "/") != 1 {
+		return fmt.Errorf("policy source_repository %q must be owner/name", p.SourceRepository)
+	}
+	return nil
+}
+
+// SetPlugin adds or replaces a plugin declaration
+func (m *Manifest) SetPlugin(pluginID string, spec PluginSpec) error {
+	if pluginID == "" {
+		return fmt.Errorf("plugin ID is required")
+	}
+	if spec.Repository == "" {
+		return fmt.Errorf("plugin repository is required")
+	}
+	if _, err := spec.Constraint(); err != nil {
+		return err
+	}
+
+	m.Plugins[pluginID] = spec
+	return nil
+}
+
+func GetManifestPath(vaultDir string) string {
+	return filepath.Join(vaultDir, ManifestFileName)
+}
+
+// LoadManifest reads a manifest from disk. The returned error wraps os.ErrNotExist
+// when no manifest is present so callers can treat it as optional.
+func LoadManifest(manifestPath string) (*Manifest, error) {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	if manifest.Plugins == nil {
+		manifest.Plugins = make(map[string]PluginSpec)
+	}
+
+	if err := manifest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+func SaveManifest(manifest *Manifest, manifestPath string) error {
+	if err := manifest.Validate(); err != nil {
+		return fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	if err := os.WriteFile(manifestPath, append(data, '\n'), DefaultManifestPerms); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// hasTagOrDigest reports whether a repository reference already pins a tag or digest
+func hasTagOrDigest(repository string) bool {
+	lastSegment := repository[strings.LastIndex(repository, "/")+1:]
+	return strings.Contains(lastSegment, ":") || strings.Contains(repository, "@")
+}
@@ -0,0 +1,179 @@
+package manifest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+)
+
+func TestManifestValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		manifest    Manifest
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:     "valid empty manifest",
+			manifest: *NewManifest(),
+		},
+		{
+			name: "valid plugin with range",
+			manifest: Manifest{
+				Version: ManifestVersion,
+				Plugins: map[string]PluginSpec{
+					"tasknotes": {Repository: "ghcr.io/owner/repo/tasknotes", Version: "^3.23.0"},
+				},
+			},
+		},
+		{
+			name:        "missing version",
+			manifest:    Manifest{Plugins: map[string]PluginSpec{}},
+			expectError: true,
+			errorMsg:    "manifest version is required",
+		},
+		{
+			name: "missing repository",
+			manifest: Manifest{
+				Version: ManifestVersion,
+				Plugins: map[string]PluginSpec{"tasknotes": {Version: "^1.0.0"}},
+			},
+			expectError: true,
+			errorMsg:    "repository is required",
+		},
+		{
+			name: "repository with tag",
+			manifest: Manifest{
+				Version: ManifestVersion,
+				Plugins: map[string]PluginSpec{"tasknotes": {Repository: "ghcr.io/owner/repo/tasknotes:v1.0.0"}},
+			},
+			expectError: true,
+			errorMsg:    "must not include a tag or digest",
+		},
+		{
+			name: "invalid range",
+			manifest: Manifest{
+				Version: ManifestVersion,
+				Plugins: map[string]PluginSpec{"tasknotes": {Repository: "ghcr.io/owner/repo/tasknotes", Version: "^one"}},
+			},
+			expectError: true,
+			errorMsg:    "invalid version constraint",
+		},
+		{
+			name: "source repository without owner",
+			manifest: Manifest{
+				Version: ManifestVersion,
+				Plugins: map[string]PluginSpec{"tasknotes": {Repository: "ghcr.io/owner/repo/tasknotes", Policy: &PolicyOverride{SourceRepository: "tasknotes"}}},
+			},
+			expectError: true,
+			errorMsg:    "must be owner/name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.manifest.Validate()
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("expected error containing %q, got %q", tt.errorMsg, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSaveAndLoadManifest(t *testing.T) {
+	tempDir := t.TempDir()
+	manifestPath := GetManifestPath(tempDir)
+
+	strict := false
+	m := NewManifest()
+	spec := PluginSpec{
+		Repository: "ghcr.io/owner/repo/tasknotes",
+		Version:    "~3.23.0",
+		Policy:     &PolicyOverride{StrictMode: &strict, TrustedBuilder: "https://example.com/builder", MinUpdateInterval: config.Duration(time.Hour), Hold: true},
+	}
+	if err := m.SetPlugin("tasknotes", spec); err != nil {
+		t.Fatalf("failed to set plugin: %v", err)
+	}
+
+	if err := SaveManifest(m, manifestPath); err != nil {
+		t.Fatalf("failed to save manifest: %v", err)
+	}
+
+	loaded, err := LoadManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("failed to load manifest: %v", err)
+	}
+
+	spec, ok := loaded.Plugins["tasknotes"]
+	if !ok {
+		t.Fatal("expected tasknotes to be declared")
+	}
+	if spec.Version != "~3.23.0" {
+		t.Errorf("expected version range ~3.23.0, got %s", spec.Version)
+	}
+	if spec.Policy == nil || spec.Policy.StrictMode == nil || *spec.Policy.StrictMode {
+		t.Errorf("expected strict mode override to round-trip as false, got %+v", spec.Policy)
+	}
+	if spec.Policy != nil && spec.Policy.TrustedBuilder != "https://example.com/builder" {
+		t.Errorf("expected trusted builder override, got %s", spec.Policy.TrustedBuilder)
+	}
+	if spec.Policy != nil && (spec.Policy.MinUpdateInterval.Duration() != time.Hour || !spec.Policy.Hold) {
+		t.Errorf("expected update policy to round-trip, got %+v", spec.Policy)
+	}
+
+	constraint, err := spec.Constraint()
+	if err != nil {
+		t.Fatalf("failed to parse constraint: %v", err)
+	}
+	if !constraint.CheckString("3.23.4") || constraint.CheckString("3.24.0") {
+		t.Error("constraint did not match expected versions")
+	}
+}
+
+func TestUpdateDue(t *testing.T) {
+	installedAt := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	week := config.Duration(7 * 24 * time.Hour)
+
+	tests := []struct {
+		name        string
+		policy      *PolicyOverride
+		installedAt *time.Time
+		now         time.Time
+		expected    bool
+	}{
+		{name: "no policy", now: installedAt, installedAt: &installedAt, expected: true},
+		{name: "held", policy: &PolicyOverride{Hold: true}, installedAt: &installedAt, now: installedAt.AddDate(1, 0, 0)},
+		{name: "within interval", policy: &PolicyOverride{MinUpdateInterval: week}, installedAt: &installedAt, now: installedAt.AddDate(0, 0, 6)},
+		{name: "interval passed", policy: &PolicyOverride{MinUpdateInterval: week}, installedAt: &installedAt, now: installedAt.AddDate(0, 0, 7), expected: true},
+		{name: "never installed", policy: &PolicyOverride{MinUpdateInterval: week}, now: installedAt, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.UpdateDue(tt.installedAt, tt.now); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLoadManifestMissing(t *testing.T) {
+	_, err := LoadManifest(filepath.Join(t.TempDir(), ManifestFileName))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected os.ErrNotExist, got %v", err)
+	}
+}
@@ -0,0 +1,333 @@
+// ABOUTME: Process metrics for verifications, downloads, and the verification cache
+// ABOUTME: Counters and histograms written in the Prometheus text exposition format
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+)
+
+// Verification outcomes
+const (
+	OutcomeVerified = "verified"
+	OutcomeFailed   = "failed"
+	OutcomeError    = "error"
+)
+
+// Verification cache lookup results
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+// LatencyBuckets are the upper bounds, in seconds, of the verification latency histogram
+var LatencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Registry holds the metrics of one process. It is safe for concurrent use.
+type Registry struct {
+	mu sync.Mutex
+
+	verifications map[string]uint64
+	latency       histogram
+	downloadBytes uint64
+	cacheLookups  map[string]uint64
+}
+
+// NewRegistry returns a registry with every metric at zero
+func NewRegistry() *Registry {
+	return &Registry{
+		verifications: map[string]uint64{},
+		latency:       newHistogram(LatencyBuckets),
+		cacheLookups:  map[string]uint64{},
+	}
+}
+
+// Default is the registry the Record functions update
+var Default = NewRegistry()
+
+// RecordVerification counts a verification ending with err after elapsed
+func RecordVerification(err error, elapsed time.Duration) {
+	Default.RecordVerification(err, elapsed)
+}
+
+// RecordDownload counts n bytes downloaded from a registry
+func RecordDownload(n int) {
+	Default.RecordDownload(n)
+}
+
+// RecordCacheLookup counts a verification cache lookup
+func RecordCacheLookup(hit bool) {
+	Default.RecordCacheLookup(hit)
+}
+
+// RecordVerification counts a verification ending with err after elapsed. A nil err is
+// verified, a policy failure is failed, and anything else is an error. Cancelled
+// verifications are not counted.
+func (r *Registry) RecordVerification(err error, elapsed time.Duration) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.verifications[Outcome(err)]++
+	r.latency.observe(elapsed.Seconds())
+}
+
+// RecordDownload counts n bytes downloaded from a registry
+func (r *Registry) RecordDownload(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.downloadBytes += uint64(n)
+}
+
+// RecordCacheLookup counts a verification cache lookup
+func (r *Registry) RecordCacheLookup(hit bool) {
+	result := CacheMiss
+	if hit {
+		result = CacheHit
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cacheLookups[result]++
+}
+
+// Outcome classifies a verification error as the CLI exit code does
+func Outcome(err error) string {
+	switch dgerrors.ExitCode(err) {
+	case dgerrors.ExitOK:
+		return OutcomeVerified
+	case dgerrors.ExitVerificationFailed, dgerrors.ExitVulnBlocked:
+		return OutcomeFailed
+	default:
+		return OutcomeError
+	}
+}
+
+// Snapshot returns a copy of the current values
+func (r *Registry) Snapshot() Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return Snapshot{
+		Verifications:       copyCounts(r.verifications),
+		VerificationSeconds: r.latency.snapshot(),
+		DownloadBytes:       r.downloadBytes,
+		CacheLookups:        copyCounts(r.cacheLookups),
+	}
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	return r.Snapshot().WritePrometheus(w)
+}
+
+// histogram counts observations per bucket; counts[i] holds observations at most
+// buckets[i] and above buckets[i-1], and the last count those above every bucket
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+}
+
+func newHistogram(buckets []float64) histogram {
+	return histogram{buckets: buckets, counts: make([]uint64, len(buckets)+1)}
+}
+
+func (h *histogram) observe(value float64) {
+	i := sort.SearchFloat64s(h.buckets, value)
+	h.counts[i]++
+	h.sum += value
+}
+
+func (h *histogram) snapshot() HistogramSnapshot {
+	return HistogramSnapshot{
+		Buckets: append([]float64(nil), h.buckets...),
+		Counts:  append([]uint64(nil), h.counts...),
+		Sum:     h.sum,
+	}
+}
+
+func copyCounts(counts map[string]uint64) map[string]uint64 {
+	out := make(map[string]uint64, len(counts))
+	for k, v := range counts {
+		out[k] = v
+	}
+	return out
+}
+
+// Snapshot is a point-in-time copy of a registry's metrics. Snapshots of CLI runs are
+// added up in the stats file shown by dragonglass stats.
+type Snapshot struct {
+	// When the first of the added snapshots was recorded (stats file only)
+	Since *time.Time `json:"since,omitempty"`
+
+	// Verifications by outcome
+	Verifications       map[string]uint64 `json:"verifications"`
+	VerificationSeconds HistogramSnapshot `json:"verification_seconds"`
+
+	DownloadBytes uint64 `json:"download_bytes"`
+
+	// Verification cache lookups by result
+	CacheLookups map[string]uint64 `json:"cache_lookups"`
+}
+
+// HistogramSnapshot holds per-bucket counts as described on histogram
+type HistogramSnapshot struct {
+	Buckets []float64 `json:"buckets"`
+	Counts  []uint64  `json:"counts"`
+	Sum     float64   `json:"sum"`
+}
+
+// Count returns the number of observations
+func (h HistogramSnapshot) Count() uint64 {
+	var total uint64
+	for _, c := range h.Counts {
+		total += c
+	}
+	return total
+}
+
+// Mean returns the average observation, or 0 without observations
+func (h HistogramSnapshot) Mean() float64 {
+	if count := h.Count(); count > 0 {
+		return h.Sum / float64(count)
+	}
+	return 0
+}
+
+// Empty reports whether nothing was recorded
+func (s Snapshot) Empty() bool {
+	return len(s.Verifications) == 0 && s.DownloadBytes == 0 && len(s.CacheLookups) == 0
+}
+
+// CacheHitRatio returns the share of cache lookups that were hits, or 0 without lookups
+func (s Snapshot) CacheHitRatio() float64 {
+	hits, misses := s.CacheLookups[CacheHit], s.CacheLookups[CacheMiss]
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// Add returns the sum of s and other. Histograms with different buckets cannot be added;
+// other's latency observations are dropped in that case.
+func (s Snapshot) Add(other Snapshot) Snapshot {
+	sum := Snapshot{
+		Since:               s.Since,
+		Verifications:       addCounts(s.Verifications, other.Verifications),
+		VerificationSeconds: s.VerificationSeconds,
+		DownloadBytes:       s.DownloadBytes + other.DownloadBytes,
+		CacheLookups:        addCounts(s.CacheLookups, other.CacheLookups),
+	}
+	if sum.Since == nil {
+		sum.Since = other.Since
+	}
+
+	h := other.VerificationSeconds
+	switch {
+	case len(sum.VerificationSeconds.Counts) == 0:
+		sum.VerificationSeconds = h
+	case equalBuckets(sum.VerificationSeconds.Buckets, h.Buckets):
+		counts := append([]uint64(nil), sum.VerificationSeconds.Counts...)
+		for i, c := range h.Counts {
+			counts[i] += c
+		}
+		sum.VerificationSeconds = HistogramSnapshot{Buckets: h.Buckets, Counts: counts, Sum: sum.VerificationSeconds.Sum + h.Sum}
+	}
+	return sum
+}
+
+func addCounts(a, b map[string]uint64) map[string]uint64 {
+	out := copyCounts(a)
+	for k, v := range b {
+		out[k] += v
+	}
+	return out
+}
+
+func equalBuckets(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// WritePrometheus writes the snapshot in the Prometheus text exposition format
+func (s Snapshot) WritePrometheus(w io.Writer) error {
+	p := &promWriter{w: w}
+
+	p.header("dragonglass_verifications_total", "Plugin verifications by outcome.", "counter")
+	for _, outcome := range []string{OutcomeVerified, OutcomeFailed, OutcomeError} {
+		p.sample("dragonglass_verifications_total", `outcome="`+outcome+`"`, float64(s.Verifications[outcome]))
+	}
+
+	h := s.VerificationSeconds
+	p.header("dragonglass_verification_duration_seconds", "Time taken to verify a plugin.", "histogram")
+	var cumulative uint64
+	for i, c := range h.Counts {
+		cumulative += c
+		le := "+Inf"
+		if i < len(h.Buckets) {
+			le = strconv.FormatFloat(h.Buckets[i], 'g', -1, 64)
+		}
+		p.sample("dragonglass_verification_duration_seconds_bucket", `le="`+le+`"`, float64(cumulative))
+	}
+	p.sample("dragonglass_verification_duration_seconds_sum", "", h.Sum)
+	p.sample("dragonglass_verification_duration_seconds_count", "", float64(h.Count()))
+
+	p.header("dragonglass_download_bytes_total", "Bytes of plugin content downloaded from registries.", "counter")
+	p.sample("dragonglass_download_bytes_total", "", float64(s.DownloadBytes))
+
+	p.header("dragonglass_cache_lookups_total", "Verification result cache lookups by result.", "counter")
+	for _, result := range []string{CacheHit, CacheMiss} {
+		p.sample("dragonglass_cache_lookups_total", `result="`+result+`"`, float64(s.CacheLookups[result]))
+	}
+
+	return p.err
+}
+
+// promWriter writes exposition lines, keeping the first write error
+type promWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (p *promWriter) header(name, help, kind string) {
+	p.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (p *promWriter) sample(name, labels string, value float64) {
+	if labels != "" {
+		name += "{" + labels + "}"
+	}
+	p.printf("%s %s\n", name, formatValue(value))
+}
+
+func (p *promWriter) printf(format string, args ...any) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, args...)
+	}
+}
+
+func formatValue(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+)
+
+func TestOutcome(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "verified", err: nil, want: OutcomeVerified},
+		{name: "untrusted builder", err: &dgerrors.UntrustedBuilderError{Builder: "other", TrustedBuilder: "trusted"}, want: OutcomeFailed},
+		{name: "registry unavailable", err: dgerrors.Mark(fmt.Errorf("connection refused"), dgerrors.ErrRegistryUnavailable), want: OutcomeError},
+		{name: "unclassified", err: fmt.Errorf("boom"), want: OutcomeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Outcome(tt.err); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	r := NewRegistry()
+	r.RecordVerification(nil, 200*time.Millisecond)
+	r.RecordVerification(nil, 3*time.Second)
+	r.RecordVerification(fmt.Errorf("boom"), 90*time.Second)
+	r.RecordVerification(context.Canceled, time.Second)
+	r.RecordDownload(1024)
+	r.RecordDownload(512)
+	r.RecordCacheLookup(true)
+	r.RecordCacheLookup(false)
+
+	var out strings.Builder
+	if err := r.WritePrometheus(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		"# TYPE dragonglass_verifications_total counter",
+		`dragonglass_verifications_total{outcome="verified"} 2`,
+		`dragonglass_verifications_total{outcome="failed"} 0`,
+		`dragonglass_verifications_total{outcome="error"} 1`,
+		"# TYPE dragonglass_verification_duration_seconds histogram",
+		`dragonglass_verification_duration_seconds_bucket{le="0.1"} 0`,
+		`dragonglass_verification_duration_seconds_bucket{le="0.25"} 1`,
+		`dragonglass_verification_duration_seconds_bucket{le="5"} 2`,
+		`dragonglass_verification_duration_seconds_bucket{le="60"} 2`,
+		`dragonglass_verification_duration_seconds_bucket{le="+Inf"} 3`,
+		"dragonglass_verification_duration_seconds_sum 93.2",
+		"dragonglass_verification_duration_seconds_count 3",
+		"dragonglass_download_bytes_total 1536",
+		`dragonglass_cache_lookups_total{result="hit"} 1`,
+		`dragonglass_cache_lookups_total{result="miss"} 1`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("expected %q in:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestSnapshotAdd(t *testing.T) {
+	a := NewRegistry()
+	a.RecordVerification(nil, time.Second)
+	a.RecordCacheLookup(true)
+	b := NewRegistry()
+	b.RecordVerification(nil, 3*time.Second)
+	b.RecordDownload(10)
+	b.RecordCacheLookup(true)
+	b.RecordCacheLookup(false)
+	b.RecordCacheLookup(true)
+
+	sum := Snapshot{}.Add(a.Snapshot()).Add(b.Snapshot())
+	if got := sum.Verifications[OutcomeVerified]; got != 2 {
+		t.Errorf("expected 2 verifications, got %d", got)
+	}
+	if got := sum.VerificationSeconds.Mean(); got != 2 {
+		t.Errorf("expected mean latency 2s, got %v", got)
+	}
+	if sum.DownloadBytes != 10 {
+		t.Errorf("expected 10 bytes, got %d", sum.DownloadBytes)
+	}
+	if got := sum.CacheHitRatio(); got != 0.75 {
+		t.Errorf("expected cache hit ratio 0.75, got %v", got)
+	}
+}
+
+func TestRecordStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "stats.json")
+	first := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	if err := RecordStats(path, NewRegistry().Snapshot(), first); err != nil {
+		t.Fatal(err)
+	}
+	if stats, err := LoadStats(path); err != nil || !stats.Empty() {
+		t.Fatalf("expected no stats for an empty run, got %+v (%v)", stats, err)
+	}
+
+	run := NewRegistry()
+	run.RecordVerification(nil, time.Second)
+	run.RecordDownload(100)
+	if err := RecordStats(path, run.Snapshot(), first); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordStats(path, run.Snapshot(), first.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := LoadStats(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Verifications[OutcomeVerified] != 2 || stats.DownloadBytes != 200 {
+		t.Errorf("expected two runs added up, got %+v", stats)
+	}
+	if stats.Since == nil || !stats.Since.Equal(first) {
+		t.Errorf("expected stats since %v, got %v", first, stats.Since)
+	}
+
+	if err := ResetStats(path); err != nil {
+		t.Fatal(err)
+	}
+	if stats, err := LoadStats(path); err != nil || !stats.Empty() {
+		t.Errorf("expected no stats after reset, got %+v (%v)", stats, err)
+	}
+}
@@ -0,0 +1,72 @@
+// ABOUTME: Stats file adding up the metrics of CLI runs for dragonglass stats
+// ABOUTME: Each run merges its snapshot into the file when it exits
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LoadStats reads the stats file at path. A missing file is an empty snapshot.
+func LoadStats(path string) (Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Snapshot{}, nil
+	}
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read stats file: %w", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to parse stats file: %w", err)
+	}
+	return snapshot, nil
+}
+
+// RecordStats adds snapshot, taken at now, to the stats file at path. Empty snapshots
+// leave the file alone.
+func RecordStats(path string, snapshot Snapshot, now time.Time) error {
+	if snapshot.Empty() {
+		return nil
+	}
+
+	stats, err := LoadStats(path)
+	if err != nil {
+		return err
+	}
+	if stats.Since == nil {
+		stats.Since = &now
+	}
+	stats = stats.Add(snapshot)
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode stats: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create stats directory: %w", err)
+	}
+
+	// Write through a temporary file so concurrent runs never read a partial file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write stats file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write stats file: %w", err)
+	}
+	return nil
+}
+
+// ResetStats removes the stats file at path
+func ResetStats(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove stats file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+// ABOUTME: Versioned schema migration framework for dragonglass JSON documents
+// ABOUTME: Upgrades older documents step by step and backs up the original before rewriting
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// VersionField is the top-level field that records a document's schema version
+const VersionField = "version"
+
+// Step upgrades a document from one schema version to the next
+type Step struct {
+	From        string
+	To          string
+	Description string
+	Apply       func(doc map[string]any) error
+}
+
+// Migrator upgrades documents of one kind (config, lockfile) to the current version
+type Migrator struct {
+	// Document kind used in error messages, e.g. "config"
+	Kind string
+
+	// Version produced by the last step
+	Current string
+
+	Steps []Step
+}
+
+// Result describes a migration that was applied
+type Result struct {
+	From       string
+	To         string
+	Steps      []Step
+	BackupPath string
+}
+
+// Migrate upgrades doc in place and returns the steps that were applied. Documents
+// already at the current version are left untouched.
+func (m *Migrator) Migrate(doc map[string]any) ([]Step, error) {
+	version, _ := doc[VersionField].(string)
+
+	var applied []Step
+	for version != m.Current {
+		step, ok := m.stepFrom(version)
+		if !ok {
+			return nil, fmt.Errorf("unsupported %s version %q (this dragonglass supports %s)", m.Kind, version, m.supportedVersions())
+		}
+
+		if err := step.Apply(doc); err != nil {
+			return nil, fmt.Errorf("failed to migrate %s from version %s to %s: %w", m.Kind, step.From, step.To, err)
+		}
+		doc[VersionField] = step.To
+		version = step.To
+		applied = append(applied, step)
+	}
+
+	return applied, nil
+}
+
+// MigrateFile upgrades the document read from path. When a migration is needed the
+// original contents are first copied to a backup next to the file, then the migrated
+// document is written back. It returns the (possibly migrated) contents and a nil
+// Result when the document was already current.
+func (m *Migrator) MigrateFile(path string, data []byte, perm os.FileMode) ([]byte, *Result, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		// Leave syntax errors for schema validation to report with a position
+		return data, nil, nil
+	}
+
+	// Documents without a version are left for schema validation to reject
+	rawVersion, ok := doc[VersionField]
+	if !ok {
+		return data, nil, nil
+	}
+	from, _ := rawVersion.(string)
+	if from == m.Current {
+		return data, nil, nil
+	}
+
+	steps, err := m.Migrate(doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	migrated, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal migrated %s: %w", m.Kind, err)
+	}
+
+	backupPath := BackupPath(path, from)
+	if err := os.WriteFile(backupPath, data, perm); err != nil {
+		return nil, nil, fmt.Errorf("failed to write %s backup: %w", m.Kind, err)
+	}
+
+	if err := os.WriteFile(path, migrated, perm); err != nil {
+		return nil, nil, fmt.Errorf("failed to write migrated %s: %w", m.Kind, err)
+	}
+
+	return migrated, &Result{From: from, To: m.Current, Steps: steps, BackupPath: backupPath}, nil
+}
+
+// BackupPath returns where the pre-migration copy of path is stored
+func BackupPath(path, fromVersion string) string {
+	if fromVersion == "" {
+		return path + ".bak"
+	}
+	return fmt.Sprintf("%s.v%s.bak", path, fromVersion)
+}
+
+func (m *Migrator) stepFrom(version string) (Step, bool) {
+	for _, step := range m.Steps {
+		if step.From == version {
+			return step, true
+		}
+	}
+	return Step{}, false
+}
+
+func (m *Migrator) supportedVersions() string {
+	versions := []string{m.Current}
+	for _, step := range m.Steps {
+		versions = append(versions, step.From)
+	}
+	return strings.Join(versions, ", ")
+}
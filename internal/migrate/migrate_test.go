@@ -0,0 +1,96 @@
+package migrate
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testMigrator() *Migrator {
+	return &Migrator{
+		Kind:    "test",
+		Current: "3",
+		Steps: []Step{
+			{From: "1", To: "2", Apply: func(doc map[string]any) error {
+				doc["renamed"] = doc["old"]
+				delete(doc, "old")
+				return nil
+			}},
+			{From: "2", To: "3", Apply: func(doc map[string]any) error {
+				doc["added"] = true
+				return nil
+			}},
+		},
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	doc := map[string]any{"version": "1", "old": "value"}
+
+	steps, err := testMigrator().Migrate(doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(steps) != 2 {
+		t.Errorf("expected 2 steps, got %d", len(steps))
+	}
+	if doc["version"] != "3" || doc["renamed"] != "value" || doc["added"] != true {
+		t.Errorf("unexpected migrated document: %v", doc)
+	}
+	if _, ok := doc["old"]; ok {
+		t.Error("expected old field to be removed")
+	}
+
+	_, err = testMigrator().Migrate(map[string]any{"version": "9"})
+	if err == nil || !strings.Contains(err.Error(), `unsupported test version "9"`) {
+		t.Errorf("expected unsupported version error, got %v", err)
+	}
+}
+
+func TestMigrateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.json")
+	original := []byte(`{"version": "2"}`)
+	if err := os.WriteFile(path, original, 0644); err != nil {
+		t.Fatalf("failed to write document: %v", err)
+	}
+
+	migrated, result, err := testMigrator().MigrateFile(path, original, 0644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result == nil || result.From != "2" || result.To != "3" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	backup, err := os.ReadFile(BackupPath(path, "2"))
+	if err != nil {
+		t.Fatalf("expected backup to be written: %v", err)
+	}
+	if string(backup) != string(original) {
+		t.Errorf("expected backup to hold the original contents, got %s", backup)
+	}
+
+	onDisk, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read migrated document: %v", err)
+	}
+	if string(onDisk) != string(migrated) {
+		t.Error("expected migrated contents to be written back")
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(migrated, &doc); err != nil {
+		t.Fatalf("failed to parse migrated document: %v", err)
+	}
+	if doc["version"] != "3" {
+		t.Errorf("expected version 3, got %v", doc["version"])
+	}
+
+	// Current documents are returned unchanged without a result
+	_, result, err = testMigrator().MigrateFile(path, migrated, 0644)
+	if err != nil || result != nil {
+		t.Errorf("expected no migration for current document, got %+v, %v", result, err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/gillisandrew/dragonglass-poc/internal/domain"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
 )
 
 // AuthProvider provides mock authentication for testing
@@ -98,43 +99,33 @@ func Plugin() domain.Plugin {
 	}
 }
 
-// Lockfile creates a mock lockfile for testing
-func Lockfile() domain.Lockfile {
-	now := time.Now()
-	return domain.Lockfile{
-		SchemaVersion: "1.0.0",
-		VaultName:     "Test Vault",
-		VaultPath:     "/path/to/vault",
-		Plugins: map[string]domain.PluginEntry{
-			"test-plugin": {
-				Version:   "1.0.0",
-				Registry:  "ghcr.io",
-				Resolved:  "ghcr.io/test/plugin@sha256:abc123",
-				Integrity: "sha256:abc123",
-				Metadata: domain.PluginMetadata{
-					Name:        "Test Plugin",
-					Version:     "1.0.0",
-					Author:      "Test Author",
-					Description: "A test plugin",
-				},
-				InstallTime: now,
-			},
+// Lockfile creates a mock lockfile with one verified plugin for testing
+func Lockfile() *lockfile.Lockfile {
+	now := time.Now().UTC()
+	lf := lockfile.NewLockfile("/path/to/vault")
+	entry := lockfile.PluginEntry{
+		Name:         "Test Plugin",
+		Version:      "1.0.0",
+		OCIReference: "ghcr.io/test/plugin:1.0.0",
+		OCIDigest:    "sha256:abc123",
+		VerificationState: lockfile.VerificationState{
+			ProvenanceVerified: true,
+			SBOMVerified:       true,
+			VulnScanPassed:     true,
+			VerifiedAt:         &now,
 		},
-		Metadata: domain.LockfileMetadata{
-			CreatedAt:   now,
-			LastUpdated: now,
-			Version:     "1.0.0",
-		},
-		Verification: map[string]domain.VerificationState{
-			"test-plugin": {
-				Verified:         true,
-				AttestationValid: true,
-				SBOMValid:        true,
-				LastVerified:     now,
-				Errors:           nil,
-			},
+		Metadata: lockfile.PluginMetadata{
+			Author:      "Test Author",
+			Description: "A test plugin",
 		},
+		InstalledAt: &now,
+		Files:       map[string]string{"main.js": "sha256:def456"},
+	}
+	entry.Integrity = lockfile.Integrity(entry.Files)
+	if err := lf.AddPlugin("test-plugin", entry); err != nil {
+		panic(err)
 	}
+	return lf
 }
 
 // Config creates a mock config for testing
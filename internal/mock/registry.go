@@ -0,0 +1,123 @@
+// ABOUTME: In-memory OCI registry served over HTTP for tests of registry clients
+// ABOUTME: Serves pushed manifests and blobs, and lists referrers through the referrers API
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// Registry is an OCI registry holding content pushed by a test. Repositories share one
+// content store, as content is only looked up by digest.
+type Registry struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	content   map[digest.Digest]registryContent
+	referrers map[digest.Digest][]ocispec.Descriptor
+}
+
+type registryContent struct {
+	mediaType string
+	data      []byte
+}
+
+// NewRegistry starts a registry that is shut down when the test ends
+func NewRegistry(t testing.TB) *Registry {
+	r := &Registry{
+		content:   make(map[digest.Digest]registryContent),
+		referrers: make(map[digest.Digest][]ocispec.Descriptor),
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serve))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+// Host returns the host:port the registry listens on
+func (r *Registry) Host() string {
+	return strings.TrimPrefix(r.server.URL, "http://")
+}
+
+// Repository returns a client for the named repository of the registry
+func (r *Registry) Repository(name string) *remote.Repository {
+	return &remote.Repository{
+		Client:    r.server.Client(),
+		Reference: registry.Reference{Registry: r.Host(), Repository: name},
+		PlainHTTP: true,
+	}
+}
+
+// Push stores data with mediaType and returns its descriptor
+func (r *Registry) Push(mediaType string, data []byte) ocispec.Descriptor {
+	desc := ocispec.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(data), Size: int64(len(data))}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.content[desc.Digest] = registryContent{mediaType: mediaType, data: data}
+	return desc
+}
+
+// PushManifest stores manifest and returns its descriptor. A manifest with a subject is
+// listed as a referrer of it, with its artifact type and annotations.
+func (r *Registry) PushManifest(manifest ocispec.Manifest) (ocispec.Descriptor, error) {
+	manifest.SchemaVersion = 2
+	manifest.MediaType = ocispec.MediaTypeImageManifest
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc := r.Push(manifest.MediaType, data)
+
+	if manifest.Subject != nil {
+		referrer := desc
+		referrer.ArtifactType = manifest.ArtifactType
+		referrer.Annotations = manifest.Annotations
+		r.mu.Lock()
+		r.referrers[manifest.Subject.Digest] = append(r.referrers[manifest.Subject.Digest], referrer)
+		r.mu.Unlock()
+	}
+	return desc, nil
+}
+
+// serve answers GET /v2/<name>/{manifests,blobs,referrers}/<digest>
+func (r *Registry) serve(w http.ResponseWriter, req *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/v2/"), "/")
+	if req.Method != http.MethodGet && req.Method != http.MethodHead || len(parts) < 3 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	kind, dgst := parts[len(parts)-2], digest.Digest(parts[len(parts)-1])
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if kind == "referrers" {
+		index := ocispec.Index{
+			MediaType: ocispec.MediaTypeImageIndex,
+			Manifests: append([]ocispec.Descriptor{}, r.referrers[dgst]...),
+		}
+		index.SchemaVersion = 2
+		w.Header().Set("Content-Type", ocispec.MediaTypeImageIndex)
+		_ = json.NewEncoder(w).Encode(index)
+		return
+	}
+
+	stored, ok := r.content[dgst]
+	if !ok || (kind != "manifests" && kind != "blobs") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", stored.mediaType)
+	w.Header().Set("Docker-Content-Digest", dgst.String())
+	http.ServeContent(w, req, "", time.Time{}, bytes.NewReader(stored.data))
+}
@@ -0,0 +1,256 @@
+// ABOUTME: Webhook notifications for plugin updates, new vulnerabilities, and verification regressions
+// ABOUTME: Renders alerts as Slack, Discord, or generic JSON payloads and posts them to targets
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/changelog"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/semver"
+)
+
+// Alert kinds, also the event names a target can subscribe to
+const (
+	KindUpdate        = "update"
+	KindVulnerability = "vulnerability"
+	KindRegression    = "regression"
+)
+
+// Target types
+const (
+	TypeSlack   = "slack"
+	TypeDiscord = "discord"
+	TypeGeneric = "generic"
+)
+
+// Types lists the supported target types
+var Types = []string{TypeSlack, TypeDiscord, TypeGeneric}
+
+// Kinds lists the alert kinds a target can subscribe to
+var Kinds = []string{KindUpdate, KindVulnerability, KindRegression}
+
+// discordLimit is the maximum length of a Discord message
+const discordLimit = 2000
+
+// Alert is a single finding about an installed plugin
+type Alert struct {
+	Kind         string `json:"kind"`
+	PluginID     string `json:"plugin_id"`
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	OCIReference string `json:"oci_reference"`
+	OCIDigest    string `json:"oci_digest"`
+
+	// Highest published version and its release notes, when the source repository
+	// publishes them (update alerts)
+	LatestVersion string             `json:"latest_version,omitempty"`
+	Release       *changelog.Release `json:"release,omitempty"`
+
+	// Vulnerabilities found now, and the increase over what was last known (vulnerability
+	// alerts)
+	Vulnerabilities    *lockfile.VulnerabilityCounts `json:"vulnerabilities,omitempty"`
+	NewVulnerabilities *lockfile.VulnerabilityCounts `json:"new_vulnerabilities,omitempty"`
+
+	// What got worse when the plugin was re-verified (regression alerts)
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Message describes the alert in one line
+func (a Alert) Message() string {
+	name := a.PluginID
+	if a.Name != "" && a.Name != a.PluginID {
+		name = fmt.Sprintf("%s (%s)", a.Name, a.PluginID)
+	}
+
+	switch a.Kind {
+	case KindUpdate:
+		return fmt.Sprintf("%s %s is available (installed %s)", name, a.LatestVersion, a.Version)
+	case KindVulnerability:
+		if a.NewVulnerabilities == nil {
+			return fmt.Sprintf("%s %s has new vulnerabilities", name, a.Version)
+		}
+		return fmt.Sprintf("%s %s has %d new vulnerabilities: %s", name, a.Version, a.NewVulnerabilities.Total(), severities(*a.NewVulnerabilities))
+	case KindRegression:
+		return fmt.Sprintf("%s %s failed re-verification: %s", name, a.Version, strings.Join(a.Reasons, "; "))
+	default:
+		return fmt.Sprintf("%s %s: %s", name, a.Version, a.Kind)
+	}
+}
+
+// severities lists the non-zero counts, most severe first, e.g. "1 critical, 2 high"
+func severities(c lockfile.VulnerabilityCounts) string {
+	var parts []string
+	for _, s := range []struct {
+		name  string
+		count int
+	}{{"critical", c.Critical}, {"high", c.High}, {"medium", c.Medium}, {"low", c.Low}, {"unknown", c.Unknown}} {
+		if s.count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", s.count, s.name))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Report is the set of alerts for one vault, sent to every target in a single message
+type Report struct {
+	Vault  string    `json:"vault"`
+	Time   time.Time `json:"time"`
+	Alerts []Alert   `json:"alerts"`
+}
+
+// Text renders the report as a short plain text message
+func (r *Report) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "dragonglass: %d plugin alerts in %s", len(r.Alerts), r.Vault)
+	for _, alert := range r.Alerts {
+		fmt.Fprintf(&b, "\n• %s", alert.Message())
+		if alert.Release != nil {
+			for _, line := range strings.Split(alert.Release.Notes, "\n") {
+				if line != "" {
+					fmt.Fprintf(&b, "\n    %s", line)
+				}
+			}
+			fmt.Fprintf(&b, "\n    %s", alert.Release.URL)
+		}
+	}
+	return b.String()
+}
+
+// Filter returns a copy of the report holding only alerts of the given kinds; all alerts
+// are kept when kinds is empty
+func (r *Report) Filter(kinds []string) *Report {
+	filtered := *r
+	if len(kinds) == 0 {
+		return &filtered
+	}
+	filtered.Alerts = nil
+	for _, alert := range r.Alerts {
+		if slices.Contains(kinds, alert.Kind) {
+			filtered.Alerts = append(filtered.Alerts, alert)
+		}
+	}
+	return &filtered
+}
+
+// Target is a webhook that receives reports
+type Target struct {
+	Name string
+	Type string
+	URL  string
+
+	// Alert kinds delivered to this target (all kinds when empty)
+	Events []string
+}
+
+// Payload renders r as the request body for a target of the given type
+func Payload(targetType string, r *Report) ([]byte, error) {
+	switch targetType {
+	case TypeSlack:
+		return json.Marshal(map[string]string{"text": r.Text()})
+	case TypeDiscord:
+		text := r.Text()
+		if runes := []rune(text); len(runes) > discordLimit {
+			text = string(runes[:discordLimit-1]) + "…"
+		}
+		return json.Marshal(map[string]string{"content": text})
+	case TypeGeneric:
+		return json.Marshal(r)
+	default:
+		return nil, fmt.Errorf("unsupported notification target type %q (must be one of %s)", targetType, strings.Join(Types, ", "))
+	}
+}
+
+// Send posts the alerts target subscribes to. Nothing is sent when none apply.
+func Send(ctx context.Context, client *http.Client, target Target, r *Report) error {
+	r = r.Filter(target.Events)
+	if len(r.Alerts) == 0 {
+		return nil
+	}
+
+	body, err := Payload(target.Type, r)
+	if err != nil {
+		return fmt.Errorf("notify target %s: %w", target.Name, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(body))
+	if err != nil {
+		// The URL often embeds a secret, so it is left out of errors
+		return fmt.Errorf("notify target %s: invalid webhook URL", target.Name)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("notify target %s: %w", target.Name, ctxErr)
+		}
+		return fmt.Errorf("notify target %s: webhook request failed", target.Name)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notify target %s: webhook returned %s", target.Name, resp.Status)
+	}
+	return nil
+}
+
+// LatestVersion returns the highest semantic version tag newer than current. Pre-releases
+// are only considered when current is itself a pre-release.
+func LatestVersion(tags []string, current string) (string, bool) {
+	installed, err := semver.Parse(current)
+	if err != nil {
+		return "", false
+	}
+
+	var latest *semver.Version
+	for _, tag := range tags {
+		v, err := semver.Parse(tag)
+		if err != nil || (v.Prerelease != "" && installed.Prerelease == "") {
+			continue
+		}
+		if !installed.LessThan(v) {
+			continue
+		}
+		if latest == nil || latest.LessThan(v) {
+			latest = v
+		}
+	}
+	if latest == nil {
+		return "", false
+	}
+	return latest.String(), true
+}
+
+// NewVulnerabilities returns how many more vulnerabilities of each severity current has
+// than baseline, or nil when there are none
+func NewVulnerabilities(baseline, current *lockfile.VulnerabilityCounts) *lockfile.VulnerabilityCounts {
+	if current == nil {
+		return nil
+	}
+	var base lockfile.VulnerabilityCounts
+	if baseline != nil {
+		base = *baseline
+	}
+
+	added := lockfile.VulnerabilityCounts{
+		Critical: max(current.Critical-base.Critical, 0),
+		High:     max(current.High-base.High, 0),
+		Medium:   max(current.Medium-base.Medium, 0),
+		Low:      max(current.Low-base.Low, 0),
+		Unknown:  max(current.Unknown-base.Unknown, 0),
+	}
+	if added.Total() == 0 {
+		return nil
+	}
+	return &added
+}
@@ -0,0 +1,254 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/changelog"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+)
+
+func testReport() *Report {
+	return &Report{
+		Vault: "/vaults/notes",
+		Time:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Alerts: []Alert{
+			{Kind: KindUpdate, PluginID: "dataview", Name: "Dataview", Version: "0.5.67", LatestVersion: "0.5.70"},
+			{
+				Kind:               KindVulnerability,
+				PluginID:           "tasks",
+				Name:               "tasks",
+				Version:            "7.0.0",
+				OCIDigest:          "sha256:abc",
+				Vulnerabilities:    &lockfile.VulnerabilityCounts{Critical: 1, High: 2, Low: 1},
+				NewVulnerabilities: &lockfile.VulnerabilityCounts{Critical: 1, High: 1},
+			},
+			{Kind: KindRegression, PluginID: "calendar", Version: "1.5.10", Reasons: []string{"provenance no longer verifies", "SLSA level dropped from 2 to 1"}},
+		},
+	}
+}
+
+func TestPayload(t *testing.T) {
+	tests := []struct {
+		targetType string
+		field      string
+		expected   string
+		errorMsg   string
+	}{
+		{
+			targetType: TypeSlack,
+			field:      "text",
+			expected:   "dragonglass: 3 plugin alerts in /vaults/notes\n• Dataview (dataview) 0.5.70 is available (installed 0.5.67)\n• tasks 7.0.0 has 2 new vulnerabilities: 1 critical, 1 high\n• calendar 1.5.10 failed re-verification: provenance no longer verifies; SLSA level dropped from 2 to 1",
+		},
+		{
+			targetType: TypeDiscord,
+			field:      "content",
+			expected:   "dragonglass: 3 plugin alerts in /vaults/notes\n• Dataview (dataview) 0.5.70 is available (installed 0.5.67)\n• tasks 7.0.0 has 2 new vulnerabilities: 1 critical, 1 high\n• calendar 1.5.10 failed re-verification: provenance no longer verifies; SLSA level dropped from 2 to 1",
+		},
+		{
+			targetType: TypeGeneric,
+			field:      "vault",
+			expected:   "/vaults/notes",
+		},
+		{
+			targetType: "teams",
+			errorMsg:   "unsupported notification target type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.targetType, func(t *testing.T) {
+			body, err := Payload(tt.targetType, testReport())
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var decoded map[string]any
+			if err := json.Unmarshal(body, &decoded); err != nil {
+				t.Fatalf("payload is not JSON: %v", err)
+			}
+			if got := decoded[tt.field]; got != tt.expected {
+				t.Errorf("expected %s %q, got %q", tt.field, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestReportTextReleaseNotes(t *testing.T) {
+	report := &Report{
+		Vault: "/vaults/notes",
+		Alerts: []Alert{{
+			Kind:          KindUpdate,
+			PluginID:      "dataview",
+			Version:       "0.5.67",
+			LatestVersion: "0.5.70",
+			Release:       &changelog.Release{Tag: "0.5.70", URL: "https://github.com/blacksmithgu/obsidian-dataview/releases/tag/0.5.70", Notes: "## Fixes\n\n- Faster queries"},
+		}},
+	}
+
+	expected := "dragonglass: 1 plugin alerts in /vaults/notes\n• dataview 0.5.70 is available (installed 0.5.67)\n    ## Fixes\n    - Faster queries\n    https://github.com/blacksmithgu/obsidian-dataview/releases/tag/0.5.70"
+	if got := report.Text(); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestPayloadDiscordLimit(t *testing.T) {
+	report := testReport()
+	for range 100 {
+		report.Alerts = append(report.Alerts, report.Alerts[0])
+	}
+
+	body, err := Payload(TypeDiscord, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]string
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if n := len([]rune(decoded["content"])); n != discordLimit {
+		t.Errorf("expected content truncated to %d characters, got %d", discordLimit, n)
+	}
+}
+
+func TestSend(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+		if strings.HasSuffix(r.URL.Path, "/broken") {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	report := testReport()
+
+	if err := Send(ctx, server.Client(), Target{Name: "all", Type: TypeGeneric, URL: server.URL}, report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(received) != 1 || !strings.Contains(received[0], `"kind":"update"`) || !strings.Contains(received[0], `"kind":"vulnerability"`) {
+		t.Errorf("expected both alerts delivered, got %v", received)
+	}
+
+	// A target subscribed to updates only is not sent vulnerability alerts
+	received = nil
+	if err := Send(ctx, server.Client(), Target{Name: "updates", Type: TypeGeneric, URL: server.URL, Events: []string{KindUpdate}}, report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(received) != 1 || strings.Contains(received[0], `"kind":"vulnerability"`) {
+		t.Errorf("expected only the update alert, got %v", received)
+	}
+
+	// Nothing is posted when no alert applies
+	received = nil
+	onlyUpdates := report.Filter([]string{KindUpdate})
+	if err := Send(ctx, server.Client(), Target{Name: "vulns", Type: TypeSlack, URL: server.URL, Events: []string{KindVulnerability}}, onlyUpdates); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(received) != 0 {
+		t.Errorf("expected no request, got %v", received)
+	}
+
+	err := Send(ctx, server.Client(), Target{Name: "broken", Type: TypeSlack, URL: server.URL + "/broken"}, report)
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected status error, got %v", err)
+	}
+	if strings.Contains(err.Error(), server.URL) {
+		t.Errorf("expected the webhook URL to be left out of the error, got %v", err)
+	}
+}
+
+func TestLatestVersion(t *testing.T) {
+	tags := []string{"latest", "commit-abc", "0.5.67", "v0.5.70", "0.6.0-beta.1", "0.5.69"}
+
+	tests := []struct {
+		current  string
+		expected string
+	}{
+		{current: "0.5.67", expected: "0.5.70"},
+		{current: "0.5.70", expected: ""},
+		{current: "0.6.0-alpha.1", expected: "0.6.0-beta.1"},
+		{current: "unversioned", expected: ""},
+	}
+
+	for _, tt := range tests {
+		got, ok := LatestVersion(tags, tt.current)
+		if got != tt.expected || ok != (tt.expected != "") {
+			t.Errorf("LatestVersion(%q): expected %q, got %q (%v)", tt.current, tt.expected, got, ok)
+		}
+	}
+}
+
+func TestNewVulnerabilities(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseline *lockfile.VulnerabilityCounts
+		current  *lockfile.VulnerabilityCounts
+		expected *lockfile.VulnerabilityCounts
+	}{
+		{name: "no scan", baseline: nil, current: nil},
+		{name: "first scan", current: &lockfile.VulnerabilityCounts{High: 1}, expected: &lockfile.VulnerabilityCounts{High: 1}},
+		{name: "unchanged", baseline: &lockfile.VulnerabilityCounts{High: 1}, current: &lockfile.VulnerabilityCounts{High: 1}},
+		{name: "fixed", baseline: &lockfile.VulnerabilityCounts{High: 2}, current: &lockfile.VulnerabilityCounts{High: 1}},
+		{
+			name:     "new critical",
+			baseline: &lockfile.VulnerabilityCounts{High: 2, Low: 1},
+			current:  &lockfile.VulnerabilityCounts{Critical: 1, High: 1, Low: 1},
+			expected: &lockfile.VulnerabilityCounts{Critical: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewVulnerabilities(tt.baseline, tt.current)
+			if (got == nil) != (tt.expected == nil) || (got != nil && *got != *tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".dragonglass", StateFileName)
+
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("expected empty state for a missing file, got %v", err)
+	}
+
+	recorded := &lockfile.VulnerabilityCounts{High: 2}
+	if got := state.Baseline("sha256:abc", recorded); got != recorded {
+		t.Errorf("expected the lockfile counts as baseline before any alert, got %+v", got)
+	}
+
+	state.Record(testReport().Alerts)
+	if err := state.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Announced("dataview", "0.5.70") || loaded.Announced("dataview", "0.5.71") {
+		t.Errorf("unexpected announced updates: %v", loaded.Updates)
+	}
+	if got := loaded.Baseline("sha256:abc", recorded); got == nil || got.Critical != 1 || got.High != 2 {
+		t.Errorf("expected the announced counts as baseline, got %+v", got)
+	}
+}
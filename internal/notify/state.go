@@ -0,0 +1,93 @@
+// ABOUTME: Record of alerts already delivered, so scheduled runs only announce changes
+// ABOUTME: Stored per vault in .dragonglass/notify-state.json
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+)
+
+const StateFileName = "notify-state.json"
+
+// State remembers what has been announced
+type State struct {
+	// Latest version announced, by plugin ID
+	Updates map[string]string `json:"updates"`
+
+	// Vulnerability counts announced, by OCI digest
+	Vulnerabilities map[string]lockfile.VulnerabilityCounts `json:"vulnerabilities"`
+}
+
+// LoadState reads the state at path, returning an empty state when the file does not exist
+func LoadState(path string) (*State, error) {
+	state := &State{
+		Updates:         make(map[string]string),
+		Vulnerabilities: make(map[string]lockfile.VulnerabilityCounts),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse notification state %s: %w", path, err)
+	}
+	if state.Updates == nil {
+		state.Updates = make(map[string]string)
+	}
+	if state.Vulnerabilities == nil {
+		state.Vulnerabilities = make(map[string]lockfile.VulnerabilityCounts)
+	}
+	return state, nil
+}
+
+// Save writes the state to path
+func (s *State) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create notification state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification state: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write notification state: %w", err)
+	}
+	return nil
+}
+
+// Announced reports whether version was already announced as the update for pluginID
+func (s *State) Announced(pluginID, version string) bool {
+	return s.Updates[pluginID] == version
+}
+
+// Baseline returns the vulnerability counts new findings for digest are measured against:
+// the last announced counts, or the counts recorded in the lockfile at install
+func (s *State) Baseline(digest string, recorded *lockfile.VulnerabilityCounts) *lockfile.VulnerabilityCounts {
+	if counts, ok := s.Vulnerabilities[digest]; ok {
+		return &counts
+	}
+	return recorded
+}
+
+// Record marks alerts as announced
+func (s *State) Record(alerts []Alert) {
+	for _, alert := range alerts {
+		switch alert.Kind {
+		case KindUpdate:
+			s.Updates[alert.PluginID] = alert.LatestVersion
+		case KindVulnerability:
+			if alert.Vulnerabilities != nil {
+				s.Vulnerabilities[alert.OCIDigest] = *alert.Vulnerabilities
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// ABOUTME: Artifact session resolving a plugin reference once per operation
+// ABOUTME: Shares the resolved descriptors, manifest, and repository client across verification and extraction
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// Artifact is a plugin reference resolved for one operation. Verifying and installing a
+// plugin read it through the same repository client, so the registry is asked to resolve
+// the reference and serve the manifest only once.
+type Artifact struct {
+	// Repository the artifact was resolved in, authenticated for reading it
+	Repository *Repository
+
+	// Plugin manifest, selected from Index when the reference names an image index
+	Descriptor ocispec.Descriptor
+	Index      *ocispec.Descriptor
+	Manifest   *ocispec.Manifest
+}
+
+// OpenArtifact resolves reference in repo and fetches its plugin manifest
+func OpenArtifact(ctx context.Context, repo *Repository, reference string) (*Artifact, error) {
+	artifact, err := resolveArtifact(ctx, repo, reference)
+	if err != nil {
+		return nil, err
+	}
+	artifact.Repository = repo
+	return artifact, nil
+}
+
+// resolveArtifact resolves reference and fetches its plugin manifest through repo
+func resolveArtifact(ctx context.Context, repo Resolver, reference string) (*Artifact, error) {
+	desc, index, err := ResolveManifest(ctx, repo, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := repo.Fetch(ctx, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	data, err := content.ReadAll(rc, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	return &Artifact{
+		Descriptor: desc,
+		Index:      index,
+		Manifest:   &manifest,
+	}, nil
+}
+
+// Digest returns the digest the reference resolved to: the image index for a multi-arch
+// artifact, so pinning it selects the same manifest again
+func (a *Artifact) Digest() string {
+	if a.Index != nil {
+		return a.Index.Digest.String()
+	}
+	return a.Descriptor.Digest.String()
+}
+
+// Subjects returns the descriptors attestations may name: the plugin manifest and, for a
+// multi-arch artifact, the index
+func (a *Artifact) Subjects() []ocispec.Descriptor {
+	subjects := []ocispec.Descriptor{a.Descriptor}
+	if a.Index != nil {
+		subjects = append(subjects, *a.Index)
+	}
+	return subjects
+}
+
+// ExtractPluginFiles extracts the plugin files of the artifact into targetDir
+func (a *Artifact) ExtractPluginFiles(ctx context.Context, targetDir string) error {
+	return a.Repository.ExtractPluginFiles(ctx, a.Manifest, targetDir)
+}
@@ -0,0 +1,76 @@
+// ABOUTME: Unit tests for the artifact session shared by verification and extraction
+// ABOUTME: Covers resolving references to a manifest once, directly and through an image index
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestResolveArtifact(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	push := func(mediaType string, v any) ocispec.Descriptor {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		desc := ocispec.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(data), Size: int64(len(data))}
+		if err := store.Push(ctx, desc, bytes.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+
+	manifest := push(ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType:   ocispec.MediaTypeImageManifest,
+		Annotations: map[string]string{"vnd.obsidian.plugin.id": "sample"},
+	})
+	index := push(ocispec.MediaTypeImageIndex, ocispec.Index{MediaType: ocispec.MediaTypeImageIndex, Manifests: []ocispec.Descriptor{manifest}})
+	for tag, desc := range map[string]ocispec.Descriptor{"single": manifest, "multi": index} {
+		if err := store.Tag(ctx, desc, tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		reference    string
+		wantDigest   digest.Digest
+		wantSubjects int
+	}{
+		{reference: "single", wantDigest: manifest.Digest, wantSubjects: 1},
+		{reference: "multi", wantDigest: index.Digest, wantSubjects: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			artifact, err := resolveArtifact(ctx, store, tt.reference)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if artifact.Descriptor.Digest != manifest.Digest {
+				t.Errorf("expected manifest %s, got %s", manifest.Digest, artifact.Descriptor.Digest)
+			}
+			if artifact.Digest() != tt.wantDigest.String() {
+				t.Errorf("expected digest %s, got %s", tt.wantDigest, artifact.Digest())
+			}
+			if got := artifact.Manifest.Annotations["vnd.obsidian.plugin.id"]; got != "sample" {
+				t.Errorf("expected manifest annotations to be parsed, got %v", artifact.Manifest.Annotations)
+			}
+			if subjects := artifact.Subjects(); len(subjects) != tt.wantSubjects || subjects[0].Digest != manifest.Digest {
+				t.Errorf("expected %d subjects starting with the manifest, got %v", tt.wantSubjects, subjects)
+			}
+		})
+	}
+
+	if _, err := resolveArtifact(ctx, store, "missing"); err == nil {
+		t.Error("expected error resolving an unknown tag")
+	}
+}
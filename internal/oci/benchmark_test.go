@@ -0,0 +1,69 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+func BenchmarkExtractPluginFiles(b *testing.B) {
+	mainJS := bytes.Repeat([]byte("console.log('dragonglass');\n"), 2<<20/28)
+	stylesCSS := bytes.Repeat([]byte(".dragonglass { color: red; }\n"), 64<<10/29)
+	blobs := map[string][]byte{
+		digest.FromBytes(mainJS).String():    mainJS,
+		digest.FromBytes(stylesCSS).String(): stylesCSS,
+	}
+
+	// A minimal registry serving the two layer blobs
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		blob, ok := blobs[r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(blob)
+	}))
+	defer server.Close()
+
+	remoteRepo, err := remote.NewRepository(strings.TrimPrefix(server.URL, "http://") + "/owner/repo/plugin")
+	if err != nil {
+		b.Fatal(err)
+	}
+	remoteRepo.PlainHTTP = true
+	repo := &Repository{remoteRepo}
+
+	manifest := &ocispec.Manifest{
+		Layers: []ocispec.Descriptor{
+			{
+				MediaType:   "application/javascript",
+				Digest:      digest.FromBytes(mainJS),
+				Size:        int64(len(mainJS)),
+				Annotations: map[string]string{ocispec.AnnotationTitle: "main.js"},
+			},
+			{
+				MediaType:   "text/css",
+				Digest:      digest.FromBytes(stylesCSS),
+				Size:        int64(len(stylesCSS)),
+				Annotations: map[string]string{ocispec.AnnotationTitle: "styles.css"},
+			},
+		},
+	}
+	targetDir := b.TempDir()
+
+	b.SetBytes(int64(len(mainJS) + len(stylesCSS)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := repo.ExtractPluginFiles(context.Background(), manifest, targetDir); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// ABOUTME: Unit tests for extracting plugin layers into a target directory
+// ABOUTME: Covers subdirectory titles, declared file modes, and rejection of escaping paths
+package oci
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestExtractLayers(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	layer := func(title, data string, annotations ...string) ocispec.Descriptor {
+		desc := ocispec.Descriptor{
+			MediaType:   "application/octet-stream",
+			Digest:      digest.FromString(data),
+			Size:        int64(len(data)),
+			Annotations: map[string]string{ocispec.AnnotationTitle: title},
+		}
+		for i := 0; i+1 < len(annotations); i += 2 {
+			desc.Annotations[annotations[i]] = annotations[i+1]
+		}
+		if exists, _ := store.Exists(ctx, desc); !exists {
+			if err := store.Push(ctx, desc, bytes.NewReader([]byte(data))); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return desc
+	}
+
+	tests := []struct {
+		name     string
+		layers   []ocispec.Descriptor
+		files    map[string]os.FileMode
+		errorMsg string
+	}{
+		{
+			name: "subdirectories and modes",
+			layers: []ocispec.Descriptor{
+				layer("main.js", "console.log(1)"),
+				layer("README.md", "# readme"),
+				layer("lang/en.json", `{"hello":"Hello"}`),
+				layer("bin/helper", "#!/bin/sh", AnnotationFileMode, "0755"),
+			},
+			files: map[string]os.FileMode{"main.js": 0644, "lang/en.json": 0644, "bin/helper": 0755},
+		},
+		{
+			name:     "parent directory rejected",
+			layers:   []ocispec.Descriptor{layer("lang/../../evil.js", "evil")},
+			errorMsg: "not a relative path inside the plugin directory",
+		},
+		{
+			name:     "absolute path rejected",
+			layers:   []ocispec.Descriptor{layer("/etc/evil.js", "evil")},
+			errorMsg: "not a relative path inside the plugin directory",
+		},
+		{
+			name:     "invalid mode",
+			layers:   []ocispec.Descriptor{layer("main.js", "console.log(1)", AnnotationFileMode, "rwx")},
+			errorMsg: "invalid " + AnnotationFileMode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "plugin")
+			err := extractLayers(ctx, store, &ocispec.Manifest{Layers: tt.layers}, dir)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			_ = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					rel, _ := filepath.Rel(dir, path)
+					got = append(got, filepath.ToSlash(rel))
+				}
+				return err
+			})
+			if len(got) != len(tt.files) {
+				t.Fatalf("expected files %v, got %v", tt.files, got)
+			}
+			for name, mode := range tt.files {
+				info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+				if err != nil {
+					t.Fatalf("expected %s to be extracted: %v", name, err)
+				}
+				if info.Mode().Perm() != mode {
+					t.Errorf("%s: expected mode %o, got %o", name, mode, info.Mode().Perm())
+				}
+			}
+		})
+	}
+}
+
+func TestLayerPathAndMode(t *testing.T) {
+	paths := []struct {
+		title string
+		want  string
+		valid bool
+	}{
+		{title: "main.js", want: "main.js", valid: true},
+		{title: "lang/en.json", want: "lang/en.json", valid: true},
+		{title: "lang/./en.json", want: "lang/en.json", valid: true},
+		{title: "../main.js", valid: false},
+		{title: "lang/../main.js", valid: false},
+		{title: "/main.js", valid: false},
+		{title: `lang\en.json`, valid: false},
+		{title: "", valid: false},
+	}
+	for _, tt := range paths {
+		got, err := LayerPath(tt.title)
+		if (err == nil) != tt.valid || got != tt.want {
+			t.Errorf("LayerPath(%q): expected %q (valid %v), got %q (%v)", tt.title, tt.want, tt.valid, got, err)
+		}
+	}
+
+	modes := []struct {
+		value string
+		want  os.FileMode
+		valid bool
+	}{
+		{value: "", want: DefaultFileMode, valid: true},
+		{value: "0755", want: 0755, valid: true},
+		{value: "0777", want: 0755, valid: true},
+		{value: "600", want: 0600, valid: true},
+		{value: "1777", valid: false},
+		{value: "0x1ff", valid: false},
+	}
+	for _, tt := range modes {
+		layer := ocispec.Descriptor{}
+		if tt.value != "" {
+			layer.Annotations = map[string]string{AnnotationFileMode: tt.value}
+		}
+		got, err := LayerMode(layer)
+		if (err == nil) != tt.valid || got != tt.want {
+			t.Errorf("LayerMode(%q): expected %o (valid %v), got %o (%v)", tt.value, tt.want, tt.valid, got, err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// ABOUTME: Resolution of references naming an OCI image index to a single plugin manifest
+// ABOUTME: Selects the only manifest, or the one for the current platform, of multi-arch artifacts
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+const (
+	// MediaTypeDockerManifestList is the Docker equivalent of an OCI image index
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// Annotation buildx sets on the attestation manifests it adds to an index
+	dockerReferenceTypeAnnotation = "vnd.docker.reference.type"
+	dockerAttestationManifest     = "attestation-manifest"
+)
+
+// IsIndex reports whether mediaType is an OCI image index or a Docker manifest list
+func IsIndex(mediaType string) bool {
+	return mediaType == ocispec.MediaTypeImageIndex || mediaType == MediaTypeDockerManifestList
+}
+
+// CurrentPlatform is the platform a manifest is selected for when an index has several
+func CurrentPlatform() ocispec.Platform {
+	return ocispec.Platform{OS: runtime.GOOS, Architecture: runtime.GOARCH}
+}
+
+// Resolver resolves references and fetches content, as a remote repository does
+type Resolver interface {
+	Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error)
+	Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error)
+}
+
+// ResolveManifest resolves reference to the descriptor of a plugin manifest. When the
+// reference names an image index, the manifest SelectManifest picks for the current
+// platform is returned along with the index descriptor; otherwise index is nil.
+func ResolveManifest(ctx context.Context, repo Resolver, reference string) (manifest ocispec.Descriptor, index *ocispec.Descriptor, err error) {
+	desc, err := repo.Resolve(ctx, reference)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	if !IsIndex(desc.MediaType) {
+		return desc, nil, nil
+	}
+
+	rc, err := repo.Fetch(ctx, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, fmt.Errorf("failed to fetch image index: %w", err)
+	}
+	defer rc.Close()
+
+	data, err := content.ReadAll(rc, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, fmt.Errorf("failed to read image index: %w", err)
+	}
+
+	var idx ocispec.Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return ocispec.Descriptor{}, nil, fmt.Errorf("failed to parse image index: %w", err)
+	}
+
+	manifest, err = SelectManifest(idx, CurrentPlatform())
+	if err != nil {
+		return ocispec.Descriptor{}, nil, fmt.Errorf("image index %s: %w", desc.Digest, err)
+	}
+	return manifest, &desc, nil
+}
+
+// SelectManifest picks the plugin manifest of an image index: the only manifest when
+// there is one, otherwise the first whose platform matches platform. Attestation
+// manifests added by buildx and nested indexes are not candidates.
+func SelectManifest(index ocispec.Index, platform ocispec.Platform) (ocispec.Descriptor, error) {
+	var candidates []ocispec.Descriptor
+	for _, desc := range index.Manifests {
+		if IsIndex(desc.MediaType) || desc.Annotations[dockerReferenceTypeAnnotation] == dockerAttestationManifest {
+			continue
+		}
+		candidates = append(candidates, desc)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return ocispec.Descriptor{}, fmt.Errorf("index lists no manifests")
+	case 1:
+		return candidates[0], nil
+	}
+
+	for _, desc := range candidates {
+		if desc.Platform != nil && desc.Platform.OS == platform.OS && desc.Platform.Architecture == platform.Architecture {
+			return desc, nil
+		}
+	}
+
+	platforms := make([]string, 0, len(candidates))
+	for _, desc := range candidates {
+		name := "unknown"
+		if desc.Platform != nil {
+			name = desc.Platform.OS + "/" + desc.Platform.Architecture
+		}
+		platforms = append(platforms, name+" "+desc.Digest.String())
+	}
+	return ocispec.Descriptor{}, fmt.Errorf("no manifest for %s/%s among %s; reference a manifest by digest", platform.OS, platform.Architecture, strings.Join(platforms, ", "))
+}
@@ -0,0 +1,100 @@
+// ABOUTME: Unit tests for resolving image indexes to a plugin manifest
+// ABOUTME: Covers manifest selection by platform and resolution through an in-memory store
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestSelectManifest(t *testing.T) {
+	linux := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: "sha256:linux", Platform: &ocispec.Platform{OS: "linux", Architecture: "amd64"}}
+	darwin := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: "sha256:darwin", Platform: &ocispec.Platform{OS: "darwin", Architecture: "arm64"}}
+	attestation := ocispec.Descriptor{
+		MediaType:   ocispec.MediaTypeImageManifest,
+		Digest:      "sha256:attestation",
+		Platform:    &ocispec.Platform{OS: "unknown", Architecture: "unknown"},
+		Annotations: map[string]string{dockerReferenceTypeAnnotation: dockerAttestationManifest},
+	}
+	nested := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageIndex, Digest: "sha256:nested"}
+
+	tests := []struct {
+		name      string
+		manifests []ocispec.Descriptor
+		platform  ocispec.Platform
+		want      digest.Digest
+		errorMsg  string
+	}{
+		{name: "only manifest", manifests: []ocispec.Descriptor{darwin}, platform: ocispec.Platform{OS: "linux", Architecture: "amd64"}, want: "sha256:darwin"},
+		{name: "attestation manifests ignored", manifests: []ocispec.Descriptor{attestation, linux, nested}, platform: ocispec.Platform{OS: "windows", Architecture: "amd64"}, want: "sha256:linux"},
+		{name: "current platform", manifests: []ocispec.Descriptor{linux, darwin}, platform: ocispec.Platform{OS: "darwin", Architecture: "arm64"}, want: "sha256:darwin"},
+		{name: "no matching platform", manifests: []ocispec.Descriptor{linux, darwin}, platform: ocispec.Platform{OS: "windows", Architecture: "amd64"}, errorMsg: "no manifest for windows/amd64"},
+		{name: "empty index", manifests: []ocispec.Descriptor{attestation}, errorMsg: "index lists no manifests"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectManifest(ocispec.Index{Manifests: tt.manifests}, tt.platform)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Digest != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got.Digest)
+			}
+		})
+	}
+}
+
+func TestResolveManifest(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	push := func(mediaType string, v any) ocispec.Descriptor {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		desc := ocispec.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(data), Size: int64(len(data))}
+		if err := store.Push(ctx, desc, bytes.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+
+	manifest := push(ocispec.MediaTypeImageManifest, ocispec.Manifest{MediaType: ocispec.MediaTypeImageManifest})
+	index := push(ocispec.MediaTypeImageIndex, ocispec.Index{MediaType: ocispec.MediaTypeImageIndex, Manifests: []ocispec.Descriptor{manifest}})
+	for tag, desc := range map[string]ocispec.Descriptor{"single": manifest, "multi": index} {
+		if err := store.Tag(ctx, desc, tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, gotIndex, err := ResolveManifest(ctx, store, "single")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Digest != manifest.Digest || gotIndex != nil {
+		t.Errorf("expected manifest %s without index, got %s (index %v)", manifest.Digest, got.Digest, gotIndex)
+	}
+
+	got, gotIndex, err = ResolveManifest(ctx, store, "multi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Digest != manifest.Digest || gotIndex == nil || gotIndex.Digest != index.Digest {
+		t.Errorf("expected manifest %s from index %s, got %s (index %v)", manifest.Digest, index.Digest, got.Digest, gotIndex)
+	}
+}
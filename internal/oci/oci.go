@@ -6,18 +6,27 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
-	"oras.land/oras-go/v2/registry/remote/retry"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/httpclient"
+	"github.com/gillisandrew/dragonglass-poc/internal/metrics"
 )
 
 type GHCRRegistry struct {
 	Token string
+
+	// Credential to present instead of Token, e.g. a Docker login (optional)
+	Credential auth.Credential
 }
 
 func (r *GHCRRegistry) GetRepositoryFromRef(imageRef string) (*Repository, error) {
@@ -33,13 +42,17 @@ func (r *GHCRRegistry) GetRepositoryFromRef(imageRef string) (*Repository, error
 	}
 
 	// Configure authentication
-	repo.Client = &auth.Client{
-		Client: retry.DefaultClient,
-		Cache:  auth.NewCache(),
-		Credential: auth.StaticCredential(ref.Registry, auth.Credential{
+	cred := r.Credential
+	if cred == auth.EmptyCredential {
+		cred = auth.Credential{
 			Username: "token",
 			Password: r.Token,
-		}),
+		}
+	}
+	repo.Client = &auth.Client{
+		Client:     httpclient.NewRetrying(),
+		Cache:      auth.NewCache(),
+		Credential: auth.StaticCredential(ref.Registry, cred),
 	}
 	return &Repository{repo}, nil
 }
@@ -49,8 +62,7 @@ type Repository struct {
 }
 
 func (r *Repository) FetchManifest(ctx context.Context, reference string) (*ocispec.Manifest, error) {
-
-	descriptor, err := r.Resolve(ctx, reference)
+	descriptor, _, err := ResolveManifest(ctx, r, reference)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve reference %s: %w", reference, err)
 	}
@@ -76,24 +88,36 @@ func (r *Repository) FetchManifest(ctx context.Context, reference string) (*ocis
 	return manifest, nil
 }
 
-func (r *Repository) GetSLSAAttestations(ctx context.Context, subjectDesc ocispec.Descriptor) (*ocispec.Descriptor, []io.ReadCloser, error) {
+// attestationPredicateTypes are the predicates read from Sigstore bundle referrers: SLSA
+// provenance, in the v1 or older v0.2 format, and SPDX SBOMs
+var attestationPredicateTypes = []string{
+	"https://slsa.dev/provenance/v1",
+	"https://slsa.dev/provenance/v0.2",
+	"https://spdx.dev/Document/v2.3",
+	"https://spdx.dev/Document/v3.0",
+}
+
+// GetAttestations returns the Sigstore bundles attached to subjectDesc that carry
+// provenance or SBOM statements
+func (r *Repository) GetAttestations(ctx context.Context, subjectDesc ocispec.Descriptor) (*ocispec.Descriptor, []io.ReadCloser, error) {
 	attestations := []io.ReadCloser{}
 	if err := r.Referrers(ctx, subjectDesc, "application/vnd.dev.sigstore.bundle.v0.3+json", func(referrers []ocispec.Descriptor) error {
 		// for each page of the results, do the following:
 		for _, referrer := range referrers {
-			// Check if this referrer has the SLSA provenance predicate type annotation
-			if predicateType, exists := referrer.Annotations["dev.sigstore.bundle.predicateType"]; exists {
-				if predicateType == "https://slsa.dev/provenance/v1" {
-					// This is a SLSA provenance attestation - we need to extract the bundle from the manifest's layer
-					bundleReader, err := r.extractBundleFromManifest(ctx, referrer)
-					if err != nil {
-						return fmt.Errorf("failed to extract bundle from SLSA referrer %s: %w", referrer.Digest, err)
-					}
-
-					// Note: caller is responsible for closing the readers
-					attestations = append(attestations, bundleReader)
-				}
+			// Only bundles with a known predicate type annotation are read
+			predicateType, exists := referrer.Annotations["dev.sigstore.bundle.predicateType"]
+			if !exists || !slices.Contains(attestationPredicateTypes, predicateType) {
+				continue
 			}
+
+			// The bundle is the layer of the referrer manifest
+			bundleReader, err := r.extractBundleFromManifest(ctx, referrer)
+			if err != nil {
+				return fmt.Errorf("failed to extract bundle from referrer %s: %w", referrer.Digest, err)
+			}
+
+			// Note: caller is responsible for closing the readers
+			attestations = append(attestations, bundleReader)
 		}
 		return nil
 	}); err != nil {
@@ -143,49 +167,116 @@ func (r *Repository) extractBundleFromManifest(ctx context.Context, manifestDesc
 	return bundleReader, nil
 }
 
-// ExtractPluginFiles extracts main.js and styles.css from OCI layers to target directory
+// ExtractPluginFiles extracts the plugin's files from OCI layers to target directory
 func (r *Repository) ExtractPluginFiles(ctx context.Context, manifest *ocispec.Manifest, targetDir string) error {
+	return extractLayers(ctx, r, manifest, targetDir)
+}
+
+// extractLayers writes the titled layers of manifest that belong to the plugin into
+// targetDir: main.js and styles.css (or theme.css and snippet.css for themes and
+// snippets), and assets in subdirectories such as lang/en.json.
+// Titles are relative slash-separated paths; one escaping targetDir fails the extraction.
+// Files get the mode declared by AnnotationFileMode, 0644 otherwise.
+func extractLayers(ctx context.Context, fetcher content.Fetcher, manifest *ocispec.Manifest, targetDir string) error {
 	// Create target directory if it doesn't exist
 	if err := os.MkdirAll(targetDir, 0755); err != nil {
 		return fmt.Errorf("failed to create target directory: %w", err)
 	}
 
-	// Process each layer (expecting main.js and styles.css)
+	// Writes go through a root so symlinks cannot redirect them outside targetDir
+	root, err := os.OpenRoot(targetDir)
+	if err != nil {
+		return fmt.Errorf("failed to open target directory: %w", err)
+	}
+	defer root.Close()
+
 	for _, layer := range manifest.Layers {
 		// Get filename from layer annotations
-		filename, ok := layer.Annotations["org.opencontainers.image.title"]
-		if !ok || filename == "" {
+		title, ok := layer.Annotations[ocispec.AnnotationTitle]
+		if !ok || title == "" {
 			continue // Skip layers without filename annotation
 		}
 
-		// Only process main.js and styles.css
-		if filename != "main.js" && filename != "styles.css" {
+		filename, err := LayerPath(title)
+		if err != nil {
+			return err
+		}
+		if !isPluginFile(filename) {
 			continue
 		}
 
-		// Fetch layer content
-		layerReader, err := r.Fetch(ctx, layer)
+		mode, err := LayerMode(layer)
 		if err != nil {
-			return fmt.Errorf("failed to fetch %s: %w", filename, err)
+			return fmt.Errorf("%s: %w", filename, err)
 		}
-		defer layerReader.Close()
 
-		// Read layer content
-		layerData, err := content.ReadAll(layerReader, layer)
+		// Fetch layer content
+		layerData, err := content.FetchAll(ctx, fetcher, layer)
 		if err != nil {
-			return fmt.Errorf("failed to read %s: %w", filename, err)
+			return fmt.Errorf("failed to fetch %s: %w", filename, err)
 		}
+		metrics.RecordDownload(len(layerData))
 
 		// Write file to target directory
-		filePath := filepath.Join(targetDir, filename)
-		if err := os.WriteFile(filePath, layerData, 0644); err != nil {
+		name := filepath.FromSlash(filename)
+		if dir := filepath.Dir(name); dir != "." {
+			if err := root.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("failed to create directory for %s: %w", filename, err)
+			}
+		}
+		if err := root.WriteFile(name, layerData, mode); err != nil {
 			return fmt.Errorf("failed to write %s: %w", filename, err)
 		}
+		// WriteFile applies the umask; the declared mode is set exactly
+		if err := root.Chmod(name, mode); err != nil {
+			return fmt.Errorf("failed to set mode of %s: %w", filename, err)
+		}
 	}
 
 	return nil
 }
 
+// AnnotationFileMode declares the permissions of a layer's file as an octal string, e.g.
+// "0755" for an executable helper
+const AnnotationFileMode = "vnd.dragonglass.file.mode"
+
+// DefaultFileMode is the mode of extracted files without AnnotationFileMode
+const DefaultFileMode os.FileMode = 0644
+
+// LayerPath validates a layer title as a relative slash-separated path inside the plugin
+// directory and returns it cleaned. Absolute paths and ".." elements are rejected.
+func LayerPath(title string) (string, error) {
+	if strings.Contains(title, `\`) || slices.Contains(strings.Split(title, "/"), "..") || !filepath.IsLocal(filepath.FromSlash(title)) {
+		return "", fmt.Errorf("layer title %q is not a relative path inside the plugin directory", title)
+	}
+	return path.Clean(title), nil
+}
+
+// LayerMode returns the permissions AnnotationFileMode declares for layer, or
+// DefaultFileMode. Group and other write bits are dropped.
+func LayerMode(layer ocispec.Descriptor) (os.FileMode, error) {
+	value, ok := layer.Annotations[AnnotationFileMode]
+	if !ok {
+		return DefaultFileMode, nil
+	}
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("invalid %s annotation %q, expected octal permissions such as 0755", AnnotationFileMode, value)
+	}
+	return os.FileMode(mode) & 0o755, nil
+}
+
+// isPluginFile reports whether a layer path is installed: main.js and styles.css, the
+// theme.css of a theme and snippet.css of a CSS snippet, and anything in a subdirectory.
+// Other files at the top level, such as a README, are not.
+func isPluginFile(name string) bool {
+	switch name {
+	case "main.js", "styles.css", "theme.css", "snippet.css":
+		return true
+	}
+	return strings.Contains(name, "/")
+}
+
 // FileInfo represents information about a file in a layer
 type FileInfo struct {
 	Name string
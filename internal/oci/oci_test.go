@@ -4,11 +4,15 @@ package oci
 
 import (
 	"context"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/mock"
 )
 
 func TestExtractPluginFiles(t *testing.T) {
@@ -237,3 +241,52 @@ func TestGHCRRegistry_GetRepositoryFromRef(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAttestations(t *testing.T) {
+	const bundleMediaType = "application/vnd.dev.sigstore.bundle.v0.3+json"
+	registry := mock.NewRegistry(t)
+	emptyConfig := registry.Push(ocispec.MediaTypeEmptyJSON, []byte("{}"))
+	subject, err := registry.PushManifest(ocispec.Manifest{Config: emptyConfig})
+	if err != nil {
+		t.Fatalf("failed to push subject: %v", err)
+	}
+
+	predicateTypes := []string{
+		"https://slsa.dev/provenance/v1",
+		"https://slsa.dev/provenance/v0.2",
+		"https://spdx.dev/Document/v2.3",
+		"https://cyclonedx.org/bom",
+	}
+	for _, predicateType := range predicateTypes {
+		if _, err := registry.PushManifest(ocispec.Manifest{
+			ArtifactType: bundleMediaType,
+			Config:       emptyConfig,
+			Layers:       []ocispec.Descriptor{registry.Push(bundleMediaType, []byte(predicateType))},
+			Subject:      &subject,
+			Annotations:  map[string]string{"dev.sigstore.bundle.predicateType": predicateType},
+		}); err != nil {
+			t.Fatalf("failed to push referrer: %v", err)
+		}
+	}
+
+	repo := &Repository{Repository: registry.Repository("owner/plugin")}
+	_, readers, err := repo.GetAttestations(context.Background(), subject)
+	if err != nil {
+		t.Fatalf("GetAttestations() error = %v", err)
+	}
+
+	var got []string
+	for _, reader := range readers {
+		data, err := io.ReadAll(reader)
+		_ = reader.Close()
+		if err != nil {
+			t.Fatalf("failed to read bundle: %v", err)
+		}
+		got = append(got, string(data))
+	}
+	slices.Sort(got)
+	want := []string{"https://slsa.dev/provenance/v0.2", "https://slsa.dev/provenance/v1", "https://spdx.dev/Document/v2.3"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected the provenance and SBOM bundles %v, got %v", want, got)
+	}
+}
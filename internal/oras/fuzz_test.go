@@ -0,0 +1,49 @@
+package oras
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// FuzzParseImageReference checks that references the service accepts name a repository in
+// its registry and round-trip
+func FuzzParseImageReference(f *testing.F) {
+	for _, ref := range []string{
+		"ghcr.io/silentvoid13/templater/templater-obsidian:v2.15.2",
+		"ghcr.io/blacksmithgu/obsidian-dataview/dataview@sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		"ghcr.io/owner/repo/plugin:1.0.0@sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		"localhost:5000/plugins/example-plugin",
+		"plugin",
+	} {
+		f.Add(ref)
+	}
+
+	service, err := NewService("ghcr.io", staticToken("token"))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, imageRef string) {
+		repo, tag, err := service.repository(context.Background(), imageRef)
+		if err != nil {
+			return
+		}
+		ref := repo.(*remote.Repository).Reference
+		if ref.Registry != "ghcr.io" || ref.Repository == "" || tag == "" {
+			t.Fatalf("%q opened %q with tag %q", imageRef, ref, tag)
+		}
+		if strings.ContainsAny(ref.Repository, ":@") {
+			t.Fatalf("%q parsed to repository %q", imageRef, ref.Repository)
+		}
+
+		ref.Reference = tag
+		reparsed, err := registry.ParseReference(ref.String())
+		if err != nil || reparsed.Repository != ref.Repository || reparsed.Reference != tag {
+			t.Fatalf("%q does not round-trip: got %+v, %v", imageRef, reparsed, err)
+		}
+	})
+}
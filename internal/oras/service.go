@@ -8,16 +8,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 	"time"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
-	"oras.land/oras-go/v2/registry/remote/retry"
 
 	"github.com/gillisandrew/dragonglass-poc/internal/domain"
+	"github.com/gillisandrew/dragonglass-poc/internal/httpclient"
 )
 
 // Service implements domain.RegistryService using ORAS
@@ -41,9 +40,7 @@ func NewService(registryHost string, authProvider AuthProvider) (*Service, error
 	}
 
 	// Create HTTP client with timeout
-	httpClient := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	httpClient := httpclient.New(httpclient.DefaultOpts())
 
 	// Create ORAS registry client
 	registry, err := remote.NewRegistry(registryHost)
@@ -53,7 +50,7 @@ func NewService(registryHost string, authProvider AuthProvider) (*Service, error
 
 	// Configure ORAS authentication
 	registry.Client = &auth.Client{
-		Client: retry.DefaultClient,
+		Client: httpclient.NewRetrying(),
 		Cache:  auth.NewCache(),
 		Credential: auth.StaticCredential(registryHost, auth.Credential{
 			Username: "token",
@@ -73,21 +70,9 @@ func (s *Service) Pull(imageRef string) (*domain.PullResult, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 
-	// Parse image reference
-	registryHost, repository, tag, err := parseImageReference(imageRef)
+	repo, tag, err := s.repository(ctx, imageRef)
 	if err != nil {
-		return nil, fmt.Errorf("invalid image reference: %w", err)
-	}
-
-	// Create repository client
-	repo, err := s.registry.Repository(ctx, repository)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create repository client: %w", err)
-	}
-
-	// Setup authentication for repository
-	if remoteRepo, ok := repo.(*remote.Repository); ok {
-		s.setupRepositoryAuth(remoteRepo, registryHost)
+		return nil, err
 	}
 
 	// Get manifest
@@ -127,16 +112,9 @@ func (s *Service) ValidateAccess(imageRef string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 
-	// Parse image reference
-	_, repository, tag, err := parseImageReference(imageRef)
-	if err != nil {
-		return fmt.Errorf("invalid image reference: %w", err)
-	}
-
-	// Create repository client
-	repo, err := s.registry.Repository(ctx, repository)
+	repo, tag, err := s.repository(ctx, imageRef)
 	if err != nil {
-		return fmt.Errorf("failed to create repository client: %w", err)
+		return err
 	}
 
 	// Try to resolve the manifest to test access
@@ -153,21 +131,9 @@ func (s *Service) GetManifest(imageRef string) (*ocispec.Manifest, map[string]st
 	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 
-	// Parse image reference
-	registryHost, repository, tag, err := parseImageReference(imageRef)
-	if err != nil {
-		return nil, nil, fmt.Errorf("invalid image reference: %w", err)
-	}
-
-	// Create repository client
-	repo, err := s.registry.Repository(ctx, repository)
+	repo, tag, err := s.repository(ctx, imageRef)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create repository client: %w", err)
-	}
-
-	// Setup authentication
-	if remoteRepo, ok := repo.(*remote.Repository); ok {
-		s.setupRepositoryAuth(remoteRepo, registryHost)
+		return nil, nil, err
 	}
 
 	// Get manifest
@@ -190,6 +156,30 @@ func (s *Service) GetManifest(imageRef string) (*ocispec.Manifest, map[string]st
 
 // Helper methods
 
+// repository opens the repository imageRef names and returns the tag or digest to resolve
+// in it, latest when imageRef has neither. imageRef must be in the service's registry.
+func (s *Service) repository(ctx context.Context, imageRef string) (registry.Repository, string, error) {
+	ref, err := registry.ParseReference(imageRef)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid image reference: %w", err)
+	}
+	if ref.Registry != s.registry.Reference.Registry {
+		return nil, "", fmt.Errorf("image reference %s is not in registry %s", imageRef, s.registry.Reference.Registry)
+	}
+
+	repo, err := s.registry.Repository(ctx, ref.Repository)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create repository client: %w", err)
+	}
+
+	// Setup authentication for repository
+	if remoteRepo, ok := repo.(*remote.Repository); ok {
+		s.setupRepositoryAuth(remoteRepo, ref.Registry)
+	}
+
+	return repo, ref.ReferenceOrDefault(), nil
+}
+
 func (s *Service) setupRepositoryAuth(repo *remote.Repository, registryHost string) {
 	// The repository inherits auth from the registry, but we can override if needed
 	// For now, the registry-level auth should be sufficient
@@ -246,30 +236,3 @@ func (s *Service) pullLayers(ctx context.Context, repo registry.Repository, laye
 
 	return layers, nil
 }
-
-// parseImageReference parses an OCI image reference into components
-func parseImageReference(imageRef string) (registryHost, repository, tag string, err error) {
-	// Simple parsing - in production would use a proper OCI reference parser
-	parts := strings.Split(imageRef, "/")
-	if len(parts) < 2 {
-		return "", "", "", fmt.Errorf("invalid image reference format")
-	}
-
-	registryHost = parts[0]
-
-	repoAndTag := strings.Join(parts[1:], "/")
-	if strings.Contains(repoAndTag, ":") {
-		repoParts := strings.Split(repoAndTag, ":")
-		repository = repoParts[0]
-		tag = repoParts[1]
-	} else if strings.Contains(repoAndTag, "@") {
-		repoParts := strings.Split(repoAndTag, "@")
-		repository = repoParts[0]
-		tag = repoParts[1] // digest
-	} else {
-		repository = repoAndTag
-		tag = "latest"
-	}
-
-	return registryHost, repository, tag, nil
-}
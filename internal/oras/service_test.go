@@ -0,0 +1,126 @@
+package oras
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type staticToken string
+
+func (t staticToken) GetToken() (string, error) {
+	return string(t), nil
+}
+
+// newTestRegistry serves one plugin manifest from a nested repository, tagged v1.0.0 and
+// latest, and returns a service for it with the manifest digest
+func newTestRegistry(t *testing.T) (*Service, string, string) {
+	t.Helper()
+
+	mainJS := []byte("module.exports = {}")
+	layer := ocispec.Descriptor{
+		MediaType:   "application/javascript",
+		Digest:      digest.FromBytes(mainJS),
+		Size:        int64(len(mainJS)),
+		Annotations: map[string]string{ocispec.AnnotationTitle: "main.js"},
+	}
+	manifest, err := json.Marshal(ocispec.Manifest{
+		MediaType:   ocispec.MediaTypeImageManifest,
+		Config:      ocispec.DescriptorEmptyJSON,
+		Layers:      []ocispec.Descriptor{layer},
+		Annotations: map[string]string{"vnd.obsidian.plugin.id": "example-plugin"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestDigest := digest.FromBytes(manifest).String()
+
+	const repository = "/v2/owner/repo/plugin"
+	content := map[string][]byte{
+		repository + "/manifests/v1.0.0":               manifest,
+		repository + "/manifests/latest":               manifest,
+		repository + "/manifests/" + manifestDigest:    manifest,
+		repository + "/blobs/" + layer.Digest.String(): mainJS,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := content[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if strings.Contains(r.URL.Path, "/manifests/") {
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+			w.Header().Set("Docker-Content-Digest", manifestDigest)
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method != http.MethodHead {
+			_, _ = w.Write(data)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	service, err := NewService(host, staticToken("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.registry.PlainHTTP = true
+	return service, host, manifestDigest
+}
+
+func TestServiceReferences(t *testing.T) {
+	service, host, manifestDigest := newTestRegistry(t)
+
+	tests := []struct {
+		name     string
+		imageRef string
+		errorMsg string
+	}{
+		{name: "tag", imageRef: host + "/owner/repo/plugin:v1.0.0"},
+		{name: "digest", imageRef: host + "/owner/repo/plugin@" + manifestDigest},
+		{name: "tag and digest", imageRef: host + "/owner/repo/plugin:v9.9.9@" + manifestDigest},
+		{name: "default tag", imageRef: host + "/owner/repo/plugin"},
+		{name: "unknown tag", imageRef: host + "/owner/repo/plugin:v2.0.0", errorMsg: "access validation failed"},
+		{name: "other registry", imageRef: "ghcr.io/owner/repo/plugin:v1.0.0", errorMsg: "is not in registry " + host},
+		{name: "no registry", imageRef: "plugin:v1.0.0", errorMsg: "invalid image reference"},
+		{name: "uppercase repository", imageRef: host + "/Owner/Plugin:v1.0.0", errorMsg: "invalid image reference"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.ValidateAccess(tt.imageRef)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			manifest, annotations, err := service.GetManifest(tt.imageRef)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(manifest.Layers) != 1 || annotations["vnd.obsidian.plugin.id"] != "example-plugin" {
+				t.Errorf("unexpected manifest %+v with annotations %v", manifest, annotations)
+			}
+
+			result, err := service.Pull(tt.imageRef)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Layers) != 1 || string(result.Layers[0].Content) != "module.exports = {}" {
+				t.Errorf("unexpected layers %+v", result.Layers)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// ABOUTME: Structured command output rendered as JSON, YAML, or a Go template
+// ABOUTME: Commands keep their own text rendering and hand structured values to a Renderer
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatTemplate = "go-template"
+)
+
+// templatePrefix introduces an inline template, as in --output go-template='{{.id}}'
+const templatePrefix = FormatTemplate + "="
+
+// AddFlag registers --output (-o) on command
+func AddFlag(command *cobra.Command) {
+	command.Flags().StringP("output", "o", "", "Output format: text, json, yaml, or go-template='{{.field}}' (default from output.format)")
+}
+
+// Renderer writes a command's result in the format chosen with --output or output.format
+type Renderer struct {
+	format   string
+	template *template.Template
+}
+
+// New returns the renderer for spec: "text", "json", "yaml", or "go-template=<template>".
+// An empty spec is text.
+func New(spec string) (*Renderer, error) {
+	switch spec {
+	case "", FormatText:
+		return &Renderer{format: FormatText}, nil
+	case FormatJSON, FormatYAML:
+		return &Renderer{format: spec}, nil
+	}
+
+	if text, ok := strings.CutPrefix(spec, templatePrefix); ok {
+		if text == "" {
+			return nil, fmt.Errorf("go-template output needs a template, e.g. go-template='{{.id}}'")
+		}
+		tmpl, err := template.New("output").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output template: %w", err)
+		}
+		return &Renderer{format: FormatTemplate, template: tmpl}, nil
+	}
+
+	return nil, fmt.Errorf("invalid output format %q (must be text, json, yaml, or go-template=<template>)", spec)
+}
+
+// Text reports whether the command should write its own human-readable output
+func (r *Renderer) Text() bool {
+	return r.format == FormatText
+}
+
+// Format returns the renderer's format name
+func (r *Renderer) Format() string {
+	return r.format
+}
+
+// Render writes v to w. Field names are the value's JSON names in every format, so
+// templates address fields as they appear in --output json, e.g. {{.id}}; a field that is
+// absent renders as "<no value>". A template is executed once per element when v is a
+// list, and each result ends with a newline.
+func (r *Renderer) Render(w io.Writer, v any) error {
+	switch r.format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case FormatYAML:
+		return renderYAML(w, v)
+	case FormatTemplate:
+		return r.renderTemplate(w, v)
+	default:
+		return fmt.Errorf("%s output has no structured rendering", r.format)
+	}
+}
+
+// renderYAML converts v through JSON so the YAML uses the same field names and order
+func renderYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	// JSON is YAML; parsing it as YAML keeps the field order
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	blockStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	return encoder.Close()
+}
+
+// blockStyle clears the flow style JSON input leaves on every collection and the quotes
+// on strings that do not need them
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+func (r *Renderer) renderTemplate(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic any
+	if err := decoder.Decode(&generic); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	items, ok := generic.([]any)
+	if !ok {
+		items = []any{generic}
+	}
+	for _, item := range items {
+		var buf bytes.Buffer
+		if err := r.template.Execute(&buf, item); err != nil {
+			return fmt.Errorf("failed to execute output template: %w", err)
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+type sample struct {
+	ID       string   `json:"id"`
+	Version  string   `json:"version"`
+	Verified bool     `json:"verified"`
+	Size     int64    `json:"size"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		spec       string
+		wantFormat string
+		wantErr    string
+	}{
+		{spec: "", wantFormat: FormatText},
+		{spec: "text", wantFormat: FormatText},
+		{spec: "json", wantFormat: FormatJSON},
+		{spec: "yaml", wantFormat: FormatYAML},
+		{spec: "go-template={{.id}}", wantFormat: FormatTemplate},
+		{spec: "go-template=", wantErr: "needs a template"},
+		{spec: "go-template={{.id", wantErr: "invalid output template"},
+		{spec: "xml", wantErr: "invalid output format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			r, err := New(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Format() != tt.wantFormat || r.Text() != (tt.wantFormat == FormatText) {
+				t.Errorf("expected format %s, got %s", tt.wantFormat, r.Format())
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	items := []sample{
+		{ID: "tasknotes", Version: "1.2.0", Verified: true, Size: 1500000, Tags: []string{"tasks", "gtd"}},
+		{ID: "calendar", Version: "0.9.1"},
+	}
+
+	tests := []struct {
+		spec  string
+		value any
+		want  string
+	}{
+		{
+			spec:  "json",
+			value: items[1],
+			want:  "{\n  \"id\": \"calendar\",\n  \"version\": \"0.9.1\",\n  \"verified\": false,\n  \"size\": 0\n}\n",
+		},
+		{
+			spec:  "yaml",
+			value: items,
+			want: `- id: tasknotes
+  version: 1.2.0
+  verified: true
+  size: 1500000
+  tags:
+    - tasks
+    - gtd
+- id: calendar
+  version: 0.9.1
+  verified: false
+  size: 0
+`,
+		},
+		{
+			spec:  "go-template={{.id}}@{{.version}} {{.size}}",
+			value: items,
+			want:  "tasknotes@1.2.0 1500000\ncalendar@0.9.1 0\n",
+		},
+		{
+			spec:  "go-template={{range .tags}}{{.}},{{end}}",
+			value: items[0],
+			want:  "tasks,gtd,\n",
+		},
+		{
+			spec:  "go-template={{.tags}}",
+			value: items[1],
+			want:  "<no value>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			r, err := New(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out strings.Builder
+			if err := r.Render(&out, tt.value); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.want, out.String())
+			}
+		})
+	}
+}
+
+func TestRenderTemplateErrors(t *testing.T) {
+	r, err := New("go-template={{.id.name}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Render(&strings.Builder{}, sample{ID: "calendar"}); err == nil || !strings.Contains(err.Error(), "failed to execute output template") {
+		t.Errorf("expected a template execution error, got %v", err)
+	}
+}
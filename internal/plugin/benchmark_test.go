@@ -0,0 +1,34 @@
+package plugin
+
+import (
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func BenchmarkParseMetadata(b *testing.B) {
+	parser := NewManifestParser(nil)
+	annotations := map[string]string{
+		GetAnnotationKey(AnnotationID):            "benchmark-plugin",
+		GetAnnotationKey(AnnotationName):          "Benchmark Plugin",
+		GetAnnotationKey(AnnotationVersion):       "1.2.3",
+		GetAnnotationKey(AnnotationMinAppVersion): "1.4.0",
+		GetAnnotationKey(AnnotationDescription):   "A plugin used to benchmark manifest parsing",
+		GetAnnotationKey(AnnotationAuthor):        "Dragonglass",
+		GetAnnotationKey(AnnotationAuthorURL):     "https://github.com/gillisandrew",
+		GetAnnotationKey(AnnotationIsDesktopOnly): "false",
+	}
+	manifest := &ocispec.Manifest{Annotations: annotations}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		metadata, err := parser.ParseMetadata(manifest, annotations)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if result := parser.ValidateMetadata(metadata); !result.Valid {
+			b.Fatalf("unexpected validation errors: %v", result.Errors)
+		}
+	}
+}
@@ -74,4 +74,7 @@ const (
 	AnnotationAuthor        = "author"
 	AnnotationAuthorURL     = "authorUrl"
 	AnnotationIsDesktopOnly = "isDesktopOnly"
+
+	// Kind of artifact: plugin (the default), theme, or snippet
+	AnnotationKind = "kind"
 )
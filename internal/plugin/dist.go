@@ -0,0 +1,81 @@
+// ABOUTME: Reads a built plugin directory (main.js, manifest.json, styles.css) for publishing
+// ABOUTME: Maps manifest.json fields to the OCI annotations ParseMetadata reads back
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Files of a built plugin
+const (
+	MainFile     = "main.js"
+	ManifestFile = "manifest.json"
+	StylesFile   = "styles.css"
+)
+
+// Dist is a built plugin ready to be published
+type Dist struct {
+	Dir      string
+	Metadata *Metadata
+
+	Main   []byte
+	Styles []byte // nil when the plugin has no stylesheet
+}
+
+// LoadDist reads the plugin built into dir. main.js and manifest.json are required.
+func LoadDist(dir string) (*Dist, error) {
+	dist := &Dist{Dir: dir}
+
+	manifestData, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ManifestFile, err)
+	}
+	dist.Metadata = &Metadata{}
+	if err := json.Unmarshal(manifestData, dist.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ManifestFile, err)
+	}
+
+	if dist.Main, err = os.ReadFile(filepath.Join(dir, MainFile)); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", MainFile, err)
+	}
+
+	dist.Styles, err = os.ReadFile(filepath.Join(dir, StylesFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", StylesFile, err)
+	}
+
+	return dist, nil
+}
+
+// Annotations returns the manifest annotations describing m under namespace. Empty
+// optional fields are left out.
+func (m *Metadata) Annotations(namespace string) map[string]string {
+	annotations := map[string]string{
+		GetAnnotationKeyWithNamespace(namespace, AnnotationID):            m.ID,
+		GetAnnotationKeyWithNamespace(namespace, AnnotationName):          m.Name,
+		GetAnnotationKeyWithNamespace(namespace, AnnotationVersion):       m.Version,
+		GetAnnotationKeyWithNamespace(namespace, AnnotationIsDesktopOnly): strconv.FormatBool(m.IsDesktopOnly),
+	}
+
+	optional := map[string]string{
+		AnnotationMinAppVersion: m.MinAppVersion,
+		AnnotationDescription:   m.Description,
+		AnnotationAuthor:        m.Author,
+		AnnotationAuthorURL:     m.AuthorURL,
+	}
+	for field, value := range optional {
+		if value != "" {
+			annotations[GetAnnotationKeyWithNamespace(namespace, field)] = value
+		}
+	}
+	if m.Kind != "" {
+		annotations[GetAnnotationKeyWithNamespace(namespace, AnnotationKind)] = string(m.Kind)
+	}
+
+	return annotations
+}
@@ -0,0 +1,116 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeDist(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadDist(t *testing.T) {
+	manifest := `{"id": "sample", "name": "Sample", "version": "1.2.3", "minAppVersion": "1.0.0", "author": "Me", "isDesktopOnly": true}`
+
+	tests := []struct {
+		name       string
+		files      map[string]string
+		wantStyles bool
+		errorMsg   string
+	}{
+		{
+			name:       "with styles",
+			files:      map[string]string{ManifestFile: manifest, MainFile: "module.exports = {}", StylesFile: ".x {}"},
+			wantStyles: true,
+		},
+		{
+			name:  "without styles",
+			files: map[string]string{ManifestFile: manifest, MainFile: "module.exports = {}"},
+		},
+		{
+			name:     "missing main.js",
+			files:    map[string]string{ManifestFile: manifest},
+			errorMsg: "failed to read main.js",
+		},
+		{
+			name:     "missing manifest",
+			files:    map[string]string{MainFile: "module.exports = {}"},
+			errorMsg: "failed to read manifest.json",
+		},
+		{
+			name:     "invalid manifest",
+			files:    map[string]string{ManifestFile: "{", MainFile: "module.exports = {}"},
+			errorMsg: "failed to parse manifest.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dist, err := LoadDist(writeDist(t, tt.files))
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if dist.Metadata.ID != "sample" || dist.Metadata.Version != "1.2.3" || !dist.Metadata.IsDesktopOnly {
+				t.Errorf("unexpected metadata: %+v", dist.Metadata)
+			}
+			if string(dist.Main) != "module.exports = {}" {
+				t.Errorf("unexpected main.js: %q", dist.Main)
+			}
+			if (dist.Styles != nil) != tt.wantStyles {
+				t.Errorf("expected styles present %v, got %q", tt.wantStyles, dist.Styles)
+			}
+		})
+	}
+}
+
+func TestMetadataAnnotations(t *testing.T) {
+	metadata := &Metadata{
+		ID:            "sample",
+		Name:          "Sample",
+		Version:       "1.2.3",
+		MinAppVersion: "1.0.0",
+		Author:        "Me",
+	}
+
+	annotations := metadata.Annotations("md.obsidian.plugin.v0")
+	if _, ok := annotations["md.obsidian.plugin.v0.description"]; ok {
+		t.Error("expected empty optional fields to be left out")
+	}
+	if got := annotations["md.obsidian.plugin.v0.isDesktopOnly"]; got != "false" {
+		t.Errorf("expected isDesktopOnly false, got %q", got)
+	}
+
+	// The annotations parse back into the same metadata
+	parser := NewManifestParser(DefaultPluginOpts().WithAnnotationNamespace("md.obsidian.plugin.v0"))
+	parsed, err := parser.ParseMetadata(nil, annotations)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *parsed != *metadata {
+		t.Errorf("expected %+v, got %+v", metadata, parsed)
+	}
+	// Themes and snippets are marked with the kind annotation
+	metadata.Kind = KindTheme
+	annotations = metadata.Annotations("md.obsidian.plugin.v0")
+	if got := annotations["md.obsidian.plugin.v0.kind"]; got != "theme" {
+		t.Errorf("expected kind theme, got %q", got)
+	}
+	if parsed, err := parser.ParseMetadata(nil, annotations); err != nil || *parsed != *metadata {
+		t.Errorf("expected %+v, got %+v (%v)", metadata, parsed, err)
+	}
+}
@@ -0,0 +1,63 @@
+// ABOUTME: Digests of the files of a plugin installed in a vault, as the lockfile records them
+// ABOUTME: Leaves out the user's settings file, which is not part of the artifact
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// SettingsFileName is the file Obsidian stores a plugin's user settings in. It belongs to
+// the user, not the artifact, so it is never hashed, replaced, or removed without --purge.
+const SettingsFileName = "data.json"
+
+// HashFiles returns the SHA-256 digest of every file in an installed plugin directory,
+// keyed by slash-separated path relative to dir
+func HashFiles(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if name == SettingsFileName {
+			// User settings change as the plugin is used and are not part of the artifact
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to hash %s: %w", name, err)
+		}
+		files[name] = digest.FromBytes(data).String()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin directory: %w", err)
+	}
+
+	return files, nil
+}
+
+// HashInstalled returns the digests of an artifact of kind installed at path: the files of
+// a plugin or theme directory as HashFiles returns them, or a snippet's single stylesheet
+// keyed by SnippetFile, the name it has in the artifact
+func HashInstalled(kind Kind, path string) (map[string]string, error) {
+	if kind != KindSnippet {
+		return HashFiles(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snippet: %w", err)
+	}
+	return map[string]string{SnippetFile: digest.FromBytes(data).String()}, nil
+}
@@ -0,0 +1,64 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+func TestHashFiles(t *testing.T) {
+	pluginDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(pluginDir, "main.js"), []byte("console.log('hi')"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(pluginDir, "lang", "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pluginDir, "lang", "en.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(pluginDir, SettingsFileName), []byte(`{"theme":"dark"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := HashFiles(pluginDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"main.js":      digest.FromString("console.log('hi')").String(),
+		"lang/en.json": digest.FromString("{}").String(),
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, files)
+	}
+	for name, want := range expected {
+		if files[name] != want {
+			t.Errorf("expected %s with digest %s, got %q", name, want, files[name])
+		}
+	}
+}
+
+func TestHashInstalledSnippet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wide-tables.css")
+	if err := os.WriteFile(path, []byte("table { width: 100%; }"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := HashInstalled(KindSnippet, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[SnippetFile] != digest.FromString("table { width: 100%; }").String() {
+		t.Errorf("expected the snippet keyed by %s, got %v", SnippetFile, files)
+	}
+
+	if _, err := HashInstalled(KindSnippet, filepath.Join(t.TempDir(), "missing.css")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a missing snippet to be reported as not existing, got %v", err)
+	}
+}
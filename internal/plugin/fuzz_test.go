@@ -0,0 +1,58 @@
+package plugin
+
+import (
+	"encoding/json"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// FuzzParseMetadata feeds registry-supplied manifest bytes through metadata parsing and
+// validation, as install does after fetching a manifest
+func FuzzParseMetadata(f *testing.F) {
+	// Manifest as pushed by the build workflow for the example plugin
+	f.Add([]byte(`{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.oci.image.manifest.v1+json",
+  "artifactType": "application/vnd.dragonglass.plugin",
+  "config": {"mediaType": "application/vnd.oci.empty.v1+json", "digest": "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a", "size": 2},
+  "layers": [
+    {"mediaType": "application/javascript", "digest": "sha256:5f70bf18a086007016e948b04aed3b82103a36bea41755b6cddfaf10ace3c6ef", "size": 1024, "annotations": {"org.opencontainers.image.title": "main.js"}},
+    {"mediaType": "text/css", "digest": "sha256:7d865e959b2466918c9863afca942d0fb89d7c9ac0c99bafc3749504ded97730", "size": 64, "annotations": {"org.opencontainers.image.title": "styles.css"}}
+  ],
+  "annotations": {
+    "vnd.obsidian.plugin.id": "example-plugin",
+    "vnd.obsidian.plugin.name": "Example Plugin",
+    "vnd.obsidian.plugin.version": "1.0.0",
+    "vnd.obsidian.plugin.minAppVersion": "0.15.0",
+    "vnd.obsidian.plugin.description": "An example plugin demonstrating the build workflow",
+    "vnd.obsidian.plugin.author": "Example Author",
+    "vnd.obsidian.plugin.authorUrl": "https://github.com/example/example-plugin",
+    "vnd.obsidian.plugin.isDesktopOnly": "false"
+  }
+}`))
+	f.Add([]byte(`{"annotations": {"vnd.obsidian.plugin.id": "", "vnd.obsidian.plugin.name": "", "vnd.obsidian.plugin.version": "1.2"}}`))
+	f.Add([]byte(`{"annotations": null}`))
+	f.Add([]byte(`{}`))
+
+	parser := NewManifestParser(DefaultPluginOpts().WithAnnotationNamespace(AnnotationPrefix).WithStrictValidation(true))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return
+		}
+
+		metadata, err := parser.ParseMetadata(&manifest, manifest.Annotations)
+		if err != nil {
+			return
+		}
+		if metadata.ID != manifest.Annotations[GetAnnotationKeyWithNamespace(AnnotationPrefix, AnnotationID)] {
+			t.Fatalf("parsed ID %q does not match its annotation", metadata.ID)
+		}
+
+		result := parser.ValidateMetadata(metadata)
+		if result.Valid != (len(result.Errors) == 0) {
+			t.Fatalf("validity %v disagrees with errors %v", result.Valid, result.Errors)
+		}
+	})
+}
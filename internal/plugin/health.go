@@ -0,0 +1,344 @@
+// ABOUTME: Post-install health check for plugin files before Obsidian loads them
+// ABOUTME: Catches truncated or corrupted main.js, mismatched manifest ids, and invalid styles
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// CheckHealth validates the files of a plugin extracted to dir that will be loaded as
+// pluginID. main.js must be non-empty JavaScript with balanced brackets, strings and
+// comments, manifest.json must declare pluginID, and styles.css, when present, must be
+// valid UTF-8. It is a corruption check, not a parser: code that Obsidian would reject for
+// other reasons can pass.
+func CheckHealth(dir, pluginID string) *ValidationResult {
+	result := &ValidationResult{
+		Valid:    true,
+		Errors:   []ValidationError{},
+		Warnings: []string{},
+	}
+
+	fail := func(field, format string, args ...any) {
+		result.Errors = append(result.Errors, ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch main, err := os.ReadFile(filepath.Join(dir, "main.js")); {
+	case err != nil:
+		fail("main.js", "cannot be read: %v", err)
+	case len(bytes.TrimSpace(main)) == 0:
+		fail("main.js", "is empty")
+	case bytes.IndexByte(main, 0) >= 0:
+		fail("main.js", "contains binary data")
+	default:
+		if err := scanJavaScript(main); err != nil {
+			fail("main.js", "is truncated or not JavaScript: %v", err)
+		}
+	}
+
+	var manifest struct {
+		ID string `json:"id"`
+	}
+	switch data, err := os.ReadFile(filepath.Join(dir, "manifest.json")); {
+	case err != nil:
+		fail("manifest.json", "cannot be read: %v", err)
+	case json.Unmarshal(data, &manifest) != nil:
+		fail("manifest.json", "is not valid JSON")
+	case manifest.ID != pluginID:
+		fail("manifest.json", "declares id %q but the plugin is installed as %q", manifest.ID, pluginID)
+	}
+
+	switch styles, err := os.ReadFile(filepath.Join(dir, "styles.css")); {
+	case errors.Is(err, os.ErrNotExist):
+		// styles.css is optional
+	case err != nil:
+		fail("styles.css", "cannot be read: %v", err)
+	case !utf8.Valid(styles):
+		fail("styles.css", "is not valid UTF-8")
+	}
+
+	result.Valid = len(result.Errors) == 0
+	return result
+}
+
+// CheckArtifactHealth validates the files of an artifact of kind extracted to dir that
+// will be installed as id, or for themes as name
+func CheckArtifactHealth(kind Kind, dir, id, name string) *ValidationResult {
+	switch kind {
+	case KindTheme:
+		return CheckThemeHealth(dir, name)
+	case KindSnippet:
+		return CheckSnippetHealth(dir)
+	default:
+		return CheckHealth(dir, id)
+	}
+}
+
+// CheckThemeHealth validates a theme extracted to dir that will be installed as name.
+// theme.css must be non-empty UTF-8 and manifest.json must declare name. Themes are
+// styles only, so a theme carrying JavaScript fails.
+func CheckThemeHealth(dir, name string) *ValidationResult {
+	result := newHealthResult()
+	checkStylesheet(result, dir, ThemeFile)
+
+	var manifest struct {
+		Name string `json:"name"`
+	}
+	switch data, err := os.ReadFile(filepath.Join(dir, ManifestFile)); {
+	case err != nil:
+		result.fail(ManifestFile, "cannot be read: %v", err)
+	case json.Unmarshal(data, &manifest) != nil:
+		result.fail(ManifestFile, "is not valid JSON")
+	case manifest.Name != name:
+		result.fail(ManifestFile, "declares name %q but the theme is installed as %q", manifest.Name, name)
+	}
+
+	checkNoScripts(result, dir)
+	result.Valid = len(result.Errors) == 0
+	return result
+}
+
+// CheckSnippetHealth validates a CSS snippet extracted to dir: snippet.css must be
+// non-empty UTF-8 and the only file, since a snippet is installed as a single stylesheet
+func CheckSnippetHealth(dir string) *ValidationResult {
+	result := newHealthResult()
+	checkStylesheet(result, dir, SnippetFile)
+
+	files, err := HashFiles(dir)
+	if err != nil {
+		result.fail("snippet", "cannot be read: %v", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if name != SnippetFile {
+			result.fail(name, "is not part of a snippet, which is a single %s", SnippetFile)
+		}
+	}
+
+	result.Valid = len(result.Errors) == 0
+	return result
+}
+
+func newHealthResult() *ValidationResult {
+	return &ValidationResult{
+		Valid:    true,
+		Errors:   []ValidationError{},
+		Warnings: []string{},
+	}
+}
+
+func (r *ValidationResult) fail(field, format string, args ...any) {
+	r.Errors = append(r.Errors, ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkStylesheet requires the stylesheet name in dir to be non-empty UTF-8
+func checkStylesheet(result *ValidationResult, dir, name string) {
+	switch styles, err := os.ReadFile(filepath.Join(dir, name)); {
+	case err != nil:
+		result.fail(name, "cannot be read: %v", err)
+	case len(bytes.TrimSpace(styles)) == 0:
+		result.fail(name, "is empty")
+	case !utf8.Valid(styles):
+		result.fail(name, "is not valid UTF-8")
+	}
+}
+
+// checkNoScripts fails for every JavaScript file in dir
+func checkNoScripts(result *ValidationResult, dir string) {
+	files, err := HashFiles(dir)
+	if err != nil {
+		result.fail("files", "cannot be read: %v", err)
+		return
+	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if strings.HasSuffix(strings.ToLower(name), ".js") {
+			result.fail(name, "is JavaScript, which themes cannot carry")
+		}
+	}
+}
+
+// Err returns the validation errors joined into one error, or nil when the result is valid
+func (r *ValidationResult) Err() error {
+	if r.Valid {
+		return nil
+	}
+	messages := make([]string, len(r.Errors))
+	for i, e := range r.Errors {
+		messages[i] = e.Field + " " + e.Message
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
+
+// jsOpener is an unclosed bracket, template literal or template substitution
+type jsOpener struct {
+	char byte // '(', '[', '{', '`', or '$' for a "${" substitution
+	line int
+}
+
+// scanJavaScript walks src skipping strings, comments, template literals and regular
+// expression literals, and reports the first unbalanced bracket or unterminated token.
+// A download cut short almost always ends inside one of these.
+func scanJavaScript(src []byte) error {
+	var stack []jsOpener
+	line := 1
+	regexAllowed := true // a '/' here starts a regular expression rather than a division
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if c == '\n' {
+			line++
+		}
+
+		// Inside a template literal only "`", "${" and escapes matter
+		if n := len(stack); n > 0 && stack[n-1].char == '`' {
+			switch {
+			case c == '\\':
+				i++
+			case c == '`':
+				stack = stack[:n-1]
+				regexAllowed = false
+			case c == '$' && i+1 < len(src) && src[i+1] == '{':
+				stack = append(stack, jsOpener{'$', line})
+				i++
+				regexAllowed = true
+			}
+			continue
+		}
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			continue
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			line++
+			continue
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				return fmt.Errorf("unterminated comment at line %d", line)
+			}
+			line += bytes.Count(src[i:i+2+end], []byte("\n"))
+			i += end + 3
+			continue
+		case c == '/' && regexAllowed:
+			end, err := skipRegex(src, i, line)
+			if err != nil {
+				return err
+			}
+			i = end
+			regexAllowed = false
+			continue
+		case c == '"' || c == '\'':
+			end, err := skipString(src, i, line)
+			if err != nil {
+				return err
+			}
+			i = end
+			regexAllowed = false
+			continue
+		case c == '`' || c == '(' || c == '[' || c == '{':
+			stack = append(stack, jsOpener{c, line})
+			regexAllowed = c != '`'
+			continue
+		case c == ')' || c == ']' || c == '}':
+			n := len(stack)
+			if n == 0 || closerOf(stack[n-1].char) != c {
+				return fmt.Errorf("unexpected '%c' at line %d", c, line)
+			}
+			stack = stack[:n-1]
+			// A block can be followed by a regular expression statement; a call or index cannot
+			regexAllowed = c == '}'
+			continue
+		case isIdentByte(c):
+			start := i
+			for i+1 < len(src) && isIdentByte(src[i+1]) {
+				i++
+			}
+			regexAllowed = regexKeywords[string(src[start:i+1])]
+			continue
+		}
+
+		// Operators and other punctuation; only postfix increments end an operand
+		regexAllowed = !(c == '+' || c == '-') || i == 0 || src[i-1] != c
+	}
+
+	if n := len(stack); n > 0 {
+		opener := stack[n-1]
+		if opener.char == '$' {
+			return fmt.Errorf("unclosed '${' opened at line %d", opener.line)
+		}
+		return fmt.Errorf("unclosed '%c' opened at line %d", opener.char, opener.line)
+	}
+	return nil
+}
+
+// regexKeywords are keywords after which a '/' starts a regular expression
+var regexKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true, "new": true,
+	"delete": true, "void": true, "throw": true, "case": true, "do": true, "else": true,
+	"yield": true, "await": true,
+}
+
+// skipString returns the index of the quote closing the string literal starting at start
+func skipString(src []byte, start, line int) (int, error) {
+	quote := src[start]
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '\n':
+			return 0, fmt.Errorf("unterminated string at line %d", line)
+		case quote:
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated string at line %d", line)
+}
+
+// skipRegex returns the index of the '/' closing the regular expression starting at start,
+// honouring escapes and character classes, which may contain an unescaped '/'
+func skipRegex(src []byte, start, line int) (int, error) {
+	inClass := false
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '\n':
+			return 0, fmt.Errorf("unterminated regular expression at line %d", line)
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '/':
+			if !inClass {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unterminated regular expression at line %d", line)
+}
+
+func closerOf(opener byte) byte {
+	switch opener {
+	case '(':
+		return ')'
+	case '[':
+		return ']'
+	default: // '{' and '$'
+		return '}'
+	}
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,154 @@
+package plugin
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanJavaScript(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		errorMsg string
+	}{
+		{name: "bundled plugin", src: `"use strict";var o=require("obsidian");module.exports=class extends o.Plugin{onload(){this.addCommand({id:"x",callback:()=>{}})}};`},
+		{name: "brackets in strings and comments", src: "// ({[\n/* ) */ const s = \"}\" + ')' ;"},
+		{name: "template substitution", src: "const s = `a ${fn({ b: `c ${d}` })} e`;"},
+		{name: "regular expressions", src: "const r = /[/(]+/g; if (x) { return /\\)/.test(s) }\ny = a / b / c;"},
+		{name: "division after postfix increment", src: "i++ / 2; j-- / (k)"},
+		{name: "truncated mid-block", src: "class P { onload() { this.x = 1;\n", errorMsg: "unclosed '{' opened at line 1"},
+		{name: "truncated mid-string", src: `const s = "abc`, errorMsg: "unterminated string at line 1"},
+		{name: "truncated mid-template", src: "const s = `abc ${x", errorMsg: "unclosed '${' opened at line 1"},
+		{name: "truncated comment", src: "x();\n/* trailing", errorMsg: "unterminated comment at line 2"},
+		{name: "mismatched bracket", src: "f(a]\n", errorMsg: "unexpected ']' at line 1"},
+		{name: "line numbers count comments and templates", src: "/*\n*/ `\n`\n)", errorMsg: "unexpected ')' at line 4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := scanJavaScript([]byte(tt.src))
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	valid := map[string]string{
+		"main.js":       "module.exports = class {};\n",
+		"manifest.json": `{"id": "test-plugin", "name": "Test"}`,
+		"styles.css":    ".test { color: red; }\n",
+	}
+
+	tests := []struct {
+		name     string
+		override map[string]string
+		remove   string
+		errorMsg string
+	}{
+		{name: "healthy plugin"},
+		{name: "no styles", remove: "styles.css"},
+		{name: "empty main.js", override: map[string]string{"main.js": " \n"}, errorMsg: "main.js is empty"},
+		{name: "missing main.js", remove: "main.js", errorMsg: "main.js cannot be read"},
+		{name: "binary main.js", override: map[string]string{"main.js": "abc\x00def"}, errorMsg: "main.js contains binary data"},
+		{name: "truncated main.js", override: map[string]string{"main.js": "module.exports = class {"}, errorMsg: "main.js is truncated or not JavaScript: unclosed '{'"},
+		{name: "manifest id mismatch", override: map[string]string{"manifest.json": `{"id": "other-plugin"}`}, errorMsg: `manifest.json declares id "other-plugin" but the plugin is installed as "test-plugin"`},
+		{name: "invalid manifest", override: map[string]string{"manifest.json": `{"id": `}, errorMsg: "manifest.json is not valid JSON"},
+		{name: "styles not UTF-8", override: map[string]string{"styles.css": "a { content: \"\xff\" }"}, errorMsg: "styles.css is not valid UTF-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range valid {
+				if override, ok := tt.override[name]; ok {
+					content = override
+				}
+				if name == tt.remove {
+					continue
+				}
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := CheckHealth(dir, "test-plugin").Err()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestCheckArtifactHealth(t *testing.T) {
+	theme := map[string]string{
+		"theme.css":     "body { --accent: red; }\n",
+		"manifest.json": `{"name": "Minimal", "version": "1.0.0"}`,
+	}
+	snippet := map[string]string{"snippet.css": "table { width: 100%; }\n"}
+
+	tests := []struct {
+		name     string
+		kind     Kind
+		files    map[string]string
+		errorMsg string
+	}{
+		{name: "healthy theme", kind: KindTheme, files: theme},
+		{name: "theme with scripts", kind: KindTheme, files: with(theme, "main.js", "alert(1)"), errorMsg: "main.js is JavaScript, which themes cannot carry"},
+		{name: "theme name mismatch", kind: KindTheme, files: with(theme, "manifest.json", `{"name": "Other"}`), errorMsg: `manifest.json declares name "Other" but the theme is installed as "Minimal"`},
+		{name: "empty theme.css", kind: KindTheme, files: with(theme, "theme.css", "\n"), errorMsg: "theme.css is empty"},
+		{name: "healthy snippet", kind: KindSnippet, files: snippet},
+		{name: "snippet with extra files", kind: KindSnippet, files: with(snippet, "assets/font.woff", "x"), errorMsg: "assets/font.woff is not part of a snippet"},
+		{name: "snippet not UTF-8", kind: KindSnippet, files: map[string]string{"snippet.css": "a { content: \"\xff\" }"}, errorMsg: "snippet.css is not valid UTF-8"},
+		{name: "missing snippet.css", kind: KindSnippet, files: map[string]string{}, errorMsg: "snippet.css cannot be read"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := CheckArtifactHealth(tt.kind, dir, "sample", "Minimal").Err()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+// with returns a copy of files with name set to content
+func with(files map[string]string, name, content string) map[string]string {
+	copied := maps.Clone(files)
+	copied[name] = content
+	return copied
+}
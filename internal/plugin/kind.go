@@ -0,0 +1,47 @@
+// ABOUTME: Kinds of artifact dragonglass installs: plugins, themes, and CSS snippets
+// ABOUTME: Selected by the kind annotation; each kind has its own files and install location
+package plugin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind is the kind of Obsidian artifact an OCI artifact carries
+type Kind string
+
+const (
+	// A community plugin, installed to .obsidian/plugins/<id>
+	KindPlugin Kind = "plugin"
+	// A theme (theme.css and manifest.json), installed to .obsidian/themes/<name>
+	KindTheme Kind = "theme"
+	// A CSS snippet (snippet.css), installed as .obsidian/snippets/<id>.css
+	KindSnippet Kind = "snippet"
+)
+
+// Files of themes and snippets. A snippet artifact carries its stylesheet as snippet.css,
+// whatever name it is installed under.
+const (
+	ThemeFile   = "theme.css"
+	SnippetFile = "snippet.css"
+)
+
+// ParseKind returns the kind an annotation value names. An empty value is a plugin, so
+// artifacts published before kinds existed keep installing as plugins.
+func ParseKind(value string) (Kind, error) {
+	switch kind := Kind(value); kind {
+	case "":
+		return KindPlugin, nil
+	case KindPlugin, KindTheme, KindSnippet:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("unknown artifact kind %q (must be %s, %s, or %s)", value, KindPlugin, KindTheme, KindSnippet)
+	}
+}
+
+// ValidThemeName reports whether name can be used as a theme's directory name. Obsidian
+// identifies themes by name, so it is the install location and must stay inside the
+// themes directory.
+func ValidThemeName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`) && len(name) <= 100
+}
@@ -21,6 +21,9 @@ type Metadata struct {
 	Author        string `json:"author,omitempty"`
 	AuthorURL     string `json:"authorUrl,omitempty"`
 	IsDesktopOnly bool   `json:"isDesktopOnly,omitempty"`
+
+	// Kind of artifact from the kind annotation, empty for plugins
+	Kind Kind `json:"kind,omitempty"`
 }
 
 // ValidationError represents a plugin validation error
@@ -85,6 +88,19 @@ func (p *ManifestParser) ParseMetadata(manifest *ocispec.Manifest, annotations m
 	metadata.Author = annotations[GetAnnotationKeyWithNamespace(p.opts.AnnotationNamespace, AnnotationAuthor)]
 	metadata.AuthorURL = annotations[GetAnnotationKeyWithNamespace(p.opts.AnnotationNamespace, AnnotationAuthorURL)]
 
+	kind, err := ParseKind(annotations[GetAnnotationKeyWithNamespace(p.opts.AnnotationNamespace, AnnotationKind)])
+	if err != nil {
+		return nil, err
+	}
+	// A theme installs to a directory named after it, which must not escape the themes
+	// directory whatever the validation mode
+	if kind == KindTheme && !ValidThemeName(metadata.Name) {
+		return nil, fmt.Errorf("theme name %q cannot be used as a directory name", metadata.Name)
+	}
+	if kind != KindPlugin {
+		metadata.Kind = kind
+	}
+
 	// Parse boolean flags
 	if desktopOnlyStr := annotations[GetAnnotationKeyWithNamespace(p.opts.AnnotationNamespace, AnnotationIsDesktopOnly)]; desktopOnlyStr == "true" {
 		metadata.IsDesktopOnly = true
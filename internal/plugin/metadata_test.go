@@ -1,6 +1,7 @@
 package plugin
 
 import (
+	"strings"
 	"testing"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -173,3 +174,49 @@ func TestManifestParser_ValidateMetadata_New(t *testing.T) {
 		})
 	}
 }
+
+func TestParseMetadataKind(t *testing.T) {
+	parser := NewManifestParser(nil)
+
+	tests := []struct {
+		name     string
+		kind     string
+		itemName string
+		expected Kind
+		errorMsg string
+	}{
+		{name: "no annotation is a plugin", itemName: "Sample"},
+		{name: "explicit plugin", kind: "plugin", itemName: "Sample"},
+		{name: "theme", kind: "theme", itemName: "Minimal", expected: KindTheme},
+		{name: "snippet", kind: "snippet", itemName: "Wide tables", expected: KindSnippet},
+		{name: "unknown kind", kind: "widget", itemName: "Sample", errorMsg: `unknown artifact kind "widget"`},
+		{name: "theme name escaping themes directory", kind: "theme", itemName: "../plugins", errorMsg: `theme name "../plugins" cannot be used as a directory name`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{
+				GetAnnotationKey(AnnotationID):      "sample",
+				GetAnnotationKey(AnnotationName):    tt.itemName,
+				GetAnnotationKey(AnnotationVersion): "1.0.0",
+			}
+			if tt.kind != "" {
+				annotations[GetAnnotationKey(AnnotationKind)] = tt.kind
+			}
+
+			metadata, err := parser.ParseMetadata(nil, annotations)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if metadata.Kind != tt.expected {
+				t.Errorf("expected kind %q, got %q", tt.expected, metadata.Kind)
+			}
+		})
+	}
+}
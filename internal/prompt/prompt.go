@@ -0,0 +1,81 @@
+// ABOUTME: Confirmation prompts before destructive or trust-impacting operations
+// ABOUTME: Summarizes what will happen and asks yes or no; --yes answers for automation
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pterm/pterm"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
+	"github.com/gillisandrew/dragonglass-poc/internal/ui"
+)
+
+// Prompter asks the user at the terminal to confirm operations
+type Prompter struct {
+	assumeYes   bool
+	interactive bool
+	in          *bufio.Reader
+	out         io.Writer
+}
+
+// New returns a prompter asking on stdin and stderr. With assumeYes (--yes) every
+// confirmation is given without asking.
+func New(assumeYes bool) *Prompter {
+	return &Prompter{
+		assumeYes:   assumeYes,
+		interactive: ui.IsTerminal(os.Stdin),
+		in:          bufio.NewReader(os.Stdin),
+		out:         os.Stderr,
+	}
+}
+
+// Confirm prints summary, one line per item, and asks question, returning nil when the
+// user agrees. The answer defaults to no, and declining returns an error marked
+// dgerrors.ErrDeclined. Without a terminal to ask on it fails with an error marked
+// dgerrors.ErrConfirmationNeeded rather than waiting for an answer that never comes.
+// The summary is left out in quiet mode, and plain mode asks with a line of text instead
+// of an interactive selector.
+func (p *Prompter) Confirm(summary []string, question string) error {
+	if p.assumeYes {
+		return nil
+	}
+	if !p.interactive {
+		return dgerrors.Mark(fmt.Errorf("%s: %s", question, i18n.T("prompt.no_terminal")), dgerrors.ErrConfirmationNeeded)
+	}
+
+	if !ui.Quiet() {
+		for _, line := range summary {
+			fmt.Fprintln(p.out, "  - "+line)
+		}
+	}
+
+	var confirmed bool
+	if ui.Plain() {
+		confirmed = p.readAnswer(question)
+	} else {
+		confirmed, _ = pterm.DefaultInteractiveConfirm.WithDefaultValue(false).Show(question)
+	}
+	if !confirmed {
+		return dgerrors.Mark(fmt.Errorf("%s: %s", question, i18n.T("prompt.declined")), dgerrors.ErrDeclined)
+	}
+	return nil
+}
+
+// readAnswer asks question as a line of text and reports whether the answer is yes, in
+// English or the active language
+func (p *Prompter) readAnswer(question string) bool {
+	fmt.Fprintf(p.out, "%s %s ", question, i18n.T("prompt.choices"))
+	answer, _ := p.in.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "" {
+		return false
+	}
+	yes := strings.ToLower(i18n.T("prompt.yes"))
+	return strings.HasPrefix("yes", answer) || strings.HasPrefix(yes, answer)
+}
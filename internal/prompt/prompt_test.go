@@ -0,0 +1,74 @@
+package prompt
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/i18n"
+	"github.com/gillisandrew/dragonglass-poc/internal/ui"
+)
+
+func TestConfirm(t *testing.T) {
+	ui.SetPlain(true)
+	defer ui.SetPlain(false)
+
+	tests := []struct {
+		name        string
+		assumeYes   bool
+		interactive bool
+		quiet       bool
+		locale      i18n.Locale
+		input       string
+		expected    error
+		asked       bool
+	}{
+		{name: "yes", interactive: true, input: "y\n", asked: true},
+		{name: "full word", interactive: true, input: " YES \n", asked: true},
+		{name: "localized yes", interactive: true, locale: i18n.German, input: "j\n", asked: true},
+		{name: "no", interactive: true, input: "n\n", expected: dgerrors.ErrDeclined, asked: true},
+		{name: "default is no", interactive: true, input: "\n", expected: dgerrors.ErrDeclined, asked: true},
+		{name: "end of input", interactive: true, expected: dgerrors.ErrDeclined, asked: true},
+		{name: "quiet still asks", interactive: true, quiet: true, input: "y\n", asked: true},
+		{name: "assume yes", assumeYes: true},
+		{name: "assume yes without terminal", assumeYes: true, interactive: false},
+		{name: "no terminal", expected: dgerrors.ErrConfirmationNeeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.locale != "" {
+				i18n.SetLocale(tt.locale)
+				defer i18n.SetLocale(i18n.English)
+			}
+			ui.SetQuiet(tt.quiet)
+			defer ui.SetQuiet(false)
+
+			var out bytes.Buffer
+			p := &Prompter{
+				assumeYes:   tt.assumeYes,
+				interactive: tt.interactive,
+				in:          bufio.NewReader(strings.NewReader(tt.input)),
+				out:         &out,
+			}
+
+			err := p.Confirm([]string{"sample 1.0.0 in .obsidian/plugins/sample"}, "Replace it?")
+			if tt.expected == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.expected != nil && !errors.Is(err, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, err)
+			}
+
+			if asked := strings.Contains(out.String(), "Replace it?"); asked != tt.asked {
+				t.Errorf("expected asked=%v, got output %q", tt.asked, out.String())
+			}
+			if summary := strings.Contains(out.String(), "sample 1.0.0"); summary != (tt.asked && !tt.quiet) {
+				t.Errorf("expected summary only when asking outside quiet mode, got output %q", out.String())
+			}
+		})
+	}
+}
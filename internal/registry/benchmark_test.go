@@ -0,0 +1,22 @@
+package registry
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+func BenchmarkVerifyDigest(b *testing.B) {
+	// Roughly the size of a large bundled main.js
+	content := bytes.Repeat([]byte("console.log('dragonglass');\n"), 4<<20/28)
+	expected := digest.FromBytes(content)
+
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := verifyDigest(content, expected); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
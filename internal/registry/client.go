@@ -6,11 +6,14 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -19,9 +22,15 @@ import (
 	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
-	"oras.land/oras-go/v2/registry/remote/retry"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+	"oras.land/oras-go/v2/registry/remote/errcode"
 
 	internalAuth "github.com/gillisandrew/dragonglass-poc/internal/auth"
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/githubapi"
+	"github.com/gillisandrew/dragonglass-poc/internal/httpclient"
+	"github.com/gillisandrew/dragonglass-poc/internal/metrics"
+	"github.com/gillisandrew/dragonglass-poc/internal/oci"
 	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
 )
 
@@ -38,8 +47,21 @@ type RegistryOpts struct {
 
 	// PluginOpts for plugin metadata parsing (optional)
 	PluginOpts *plugin.PluginOpts
+
+	// Credentials selects per-repository tokens (optional)
+	Credentials CredentialFunc
+
+	// DockerCredentials supplies logins from the Docker config and credential helpers (optional)
+	DockerCredentials credentials.Store
+
+	// Connect over plain HTTP instead of HTTPS, for local registries (default: false)
+	PlainHTTP bool
 }
 
+// CredentialFunc returns the token for a repository such as "ghcr.io/owner/repo/plugin-id".
+// ok is false when the repository should use the client's default token.
+type CredentialFunc func(repository string) (token string, ok bool, err error)
+
 // AuthProvider interface for authentication token management
 type AuthProvider interface {
 	GetToken() (string, error)
@@ -72,17 +94,44 @@ func (opts *RegistryOpts) WithAuthProvider(provider AuthProvider) *RegistryOpts
 	return opts
 }
 
+// WithCredentials sets the per-repository token lookup
+func (opts *RegistryOpts) WithCredentials(credentials CredentialFunc) *RegistryOpts {
+	opts.Credentials = credentials
+	return opts
+}
+
+// WithDockerCredentials sets the Docker credential store; nil disables it
+func (opts *RegistryOpts) WithDockerCredentials(store credentials.Store) *RegistryOpts {
+	opts.DockerCredentials = store
+	return opts
+}
+
+// WithPlainHTTP connects to registries over plain HTTP
+func (opts *RegistryOpts) WithPlainHTTP(plain bool) *RegistryOpts {
+	opts.PlainHTTP = plain
+	return opts
+}
+
 // WithPluginOpts sets plugin parsing options
 func (opts *RegistryOpts) WithPluginOpts(pluginOpts *plugin.PluginOpts) *RegistryOpts {
 	opts.PluginOpts = pluginOpts
 	return opts
 }
 
+// Client pulls and pushes plugin artifacts. A Client is immutable once created and safe
+// for concurrent use by multiple goroutines; each call opens its own repository handle.
+// Derive clients for other registries with WithRegistry instead of reconfiguring one.
 type Client struct {
 	opts       *RegistryOpts
 	httpClient *http.Client
 	registry   *remote.Registry
 	token      string
+
+	// GitHub REST API client the packages of a GHCR namespace are listed with
+	github *githubapi.Client
+
+	// Why no default token is available; repositories with their own credential still work
+	tokenErr error
 }
 
 // getPluginOpts safely returns plugin options, using default if not configured
@@ -119,39 +168,38 @@ func NewClient(opts *RegistryOpts) (*Client, error) {
 	if opts == nil {
 		opts = DefaultRegistryOpts()
 	}
+	// Later changes to the caller's options must not reach the client
+	copied := *opts
+	opts = &copied
 
 	var authProvider AuthProvider = &githubAuthAdapter{}
 	if opts.AuthClient != nil {
 		authProvider = opts.AuthClient
 	}
 
-	// Get authentication token
+	// Get authentication token. When per-repository or Docker credentials are configured
+	// the default token is only needed for repositories they do not cover.
+	var tokenErr error
 	token, err := authProvider.GetToken()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get authentication token: %w", err)
+		if opts.Credentials == nil && opts.DockerCredentials == nil {
+			return nil, fmt.Errorf("failed to get authentication token: %w", err)
+		}
+		tokenErr = fmt.Errorf("failed to get authentication token: %w", err)
 	}
 
 	// Create ORAS remote registry
-	reg, err := remote.NewRegistry(opts.RegistryHost)
+	reg, err := newRemoteRegistry(opts.RegistryHost, token, opts.PlainHTTP)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create registry client: %w", err)
-	}
-
-	// Configure ORAS auth client with token
-	// For GHCR, username can be anything when using token authentication
-	reg.Client = &auth.Client{
-		Client: retry.DefaultClient,
-		Cache:  auth.NewCache(),
-		Credential: auth.StaticCredential(opts.RegistryHost, auth.Credential{
-			Username: "token",
-			Password: token,
-		}),
+		return nil, err
 	}
 
 	// Create regular HTTP client
-	httpClient, err := authProvider.GetHTTPClient()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	httpClient := httpclient.New(httpclient.DefaultOpts())
+	if tokenErr == nil {
+		if httpClient, err = authProvider.GetHTTPClient(); err != nil {
+			return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+		}
 	}
 	httpClient.Timeout = opts.Timeout
 
@@ -160,6 +208,8 @@ func NewClient(opts *RegistryOpts) (*Client, error) {
 		httpClient: httpClient,
 		registry:   reg,
 		token:      token,
+		github:     githubapi.NewClient(githubapi.DefaultOpts().WithAPIURL(packagesAPIURL).WithToken(token).WithHTTPClient(httpClient)),
+		tokenErr:   tokenErr,
 	}, nil
 }
 
@@ -174,41 +224,84 @@ func (g *githubAuthAdapter) GetHTTPClient() (*http.Client, error) {
 	return internalAuth.GetHTTPClient()
 }
 
-// setupRepositoryAuth configures ORAS authentication for a repository
-func (c *Client) setupRepositoryAuth(repo *remote.Repository) {
+// setupRepositoryAuth configures ORAS authentication for a repository, using the
+// repository's own credential when one is configured and the default token otherwise
+func (c *Client) setupRepositoryAuth(repo *remote.Repository) error {
+	cred, err := c.repositoryCredential(repo.Reference.Registry + "/" + repo.Reference.Repository)
+	if err != nil {
+		return err
+	}
+
 	repo.Client = &auth.Client{
-		Client: retry.DefaultClient,
-		Cache:  auth.NewCache(),
-		Credential: auth.StaticCredential(repo.Reference.Registry, auth.Credential{
-			Username: "token",
-			Password: c.token,
-		}),
+		Client:     httpclient.NewRetrying(),
+		Cache:      auth.NewCache(),
+		Credential: auth.StaticCredential(repo.Reference.Registry, cred),
 	}
+	return nil
 }
 
-// SetRegistry allows changing the target registry (useful for testing)
-func (c *Client) SetRegistry(hostname string) error {
+// repositoryCredential returns the credential to present for repository
+func (c *Client) repositoryCredential(repository string) (auth.Credential, error) {
+	return resolveCredential(c.opts, repository, func() (string, error) {
+		return c.token, c.tokenErr
+	})
+}
+
+// newRemoteRegistry creates the ORAS registry for hostname, authenticated with token
+func newRemoteRegistry(hostname, token string, plainHTTP bool) (*remote.Registry, error) {
 	reg, err := remote.NewRegistry(hostname)
 	if err != nil {
-		return fmt.Errorf("failed to create registry for %s: %w", hostname, err)
+		return nil, fmt.Errorf("failed to create registry for %s: %w", hostname, err)
 	}
+	reg.PlainHTTP = plainHTTP
 
 	// Configure ORAS auth client with token
+	// For GHCR, username can be anything when using token authentication
 	reg.Client = &auth.Client{
-		Client: retry.DefaultClient,
+		Client: httpclient.NewRetrying(),
 		Cache:  auth.NewCache(),
 		Credential: auth.StaticCredential(hostname, auth.Credential{
 			Username: "token",
-			Password: c.token,
+			Password: token,
 		}),
 	}
+	return reg, nil
+}
 
-	c.registry = reg
-	c.opts.RegistryHost = hostname
-	return nil
+// WithRegistry returns a copy of the client targeting hostname. The client itself is left
+// unchanged, so goroutines sharing it are unaffected.
+func (c *Client) WithRegistry(hostname string) (*Client, error) {
+	reg, err := newRemoteRegistry(hostname, c.token, c.opts.PlainHTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := *c.opts
+	opts.RegistryHost = hostname
+
+	derived := *c
+	derived.opts = &opts
+	derived.registry = reg
+	return &derived, nil
+}
+
+// repository returns the authenticated repository ref names
+func (c *Client) repository(ref registry.Reference) (*remote.Repository, error) {
+	repo, err := remote.NewRepository(ref.Registry + "/" + ref.Repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create repository: %w", err)
+	}
+	repo.PlainHTTP = c.opts.PlainHTTP
+
+	// Configure ORAS authentication
+	if err := c.setupRepositoryAuth(repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
 }
 
-// Pull downloads an OCI artifact and returns the manifest and layer contents
+// Pull downloads an OCI artifact and returns the manifest and layer contents. Layers are
+// saved into destDir as layer-<digest>.tar.
 func (c *Client) Pull(ctx context.Context, imageRef string, destDir string, progress ProgressCallback) (*PullResult, error) {
 	// Parse image reference (e.g., "ghcr.io/owner/repo:tag")
 	ref, err := registry.ParseReference(imageRef)
@@ -216,25 +309,21 @@ func (c *Client) Pull(ctx context.Context, imageRef string, destDir string, prog
 		return nil, fmt.Errorf("invalid image reference %s: %w", imageRef, err)
 	}
 
-	// Create repository
-	repo, err := remote.NewRepository(ref.Registry + "/" + ref.Repository)
+	repo, err := c.repository(ref)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create repository: %w", err)
+		return nil, err
 	}
 
-	// Configure ORAS authentication
-	c.setupRepositoryAuth(repo)
-
-	// Resolve the reference to get the manifest descriptor
-	manifestDesc, err := repo.Resolve(ctx, ref.Reference)
+	// Resolve the reference to get the manifest descriptor, selecting one from an image index
+	manifestDesc, _, err := oci.ResolveManifest(ctx, repo, ref.Reference)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve %s: %w", imageRef, err)
+		return nil, fmt.Errorf("failed to resolve %s: %w", imageRef, classifyError(err))
 	}
 
 	// Fetch the manifest
 	manifestReader, err := repo.Fetch(ctx, manifestDesc)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
+		return nil, fmt.Errorf("failed to fetch manifest: %w", classifyError(err))
 	}
 	defer manifestReader.Close()
 
@@ -270,7 +359,7 @@ func (c *Client) Pull(ctx context.Context, imageRef string, destDir string, prog
 		// Fetch layer content
 		layerReader, err := repo.Fetch(ctx, layerDesc)
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch layer %d: %w", i, err)
+			return nil, fmt.Errorf("failed to fetch layer %d: %w", i, classifyError(err))
 		}
 
 		// Read layer content
@@ -280,15 +369,17 @@ func (c *Client) Pull(ctx context.Context, imageRef string, destDir string, prog
 		if err != nil {
 			return nil, fmt.Errorf("failed to read layer %d: %w", i, err)
 		}
+		metrics.RecordDownload(len(layerContent))
 
 		// Verify digest
 		if err := verifyDigest(layerContent, layerDesc.Digest); err != nil {
 			return nil, fmt.Errorf("layer %d digest verification failed: %w", i, err)
 		}
 
-		// Save layer to file
-		layerPath := filepath.Join(destDir, fmt.Sprintf("layer-%d.tar", i))
-		if err := os.WriteFile(layerPath, layerContent, 0644); err != nil {
+		// Save layer to file, named by its digest so concurrent pulls into destDir never
+		// overwrite each other's layers
+		layerPath, err := saveLayer(destDir, layerDesc, layerContent)
+		if err != nil {
 			return nil, fmt.Errorf("failed to save layer %d: %w", i, err)
 		}
 
@@ -316,44 +407,106 @@ func (c *Client) Pull(ctx context.Context, imageRef string, destDir string, prog
 	return result, nil
 }
 
-// GetManifest fetches just the manifest for an image reference
+// saveLayer writes content to destDir as layer-<digest>.tar. It is written to a temporary
+// file first and renamed into place, so the layer file is either absent or complete.
+func saveLayer(destDir string, desc ocispec.Descriptor, content []byte) (string, error) {
+	layerPath := filepath.Join(destDir, "layer-"+desc.Digest.Encoded()+".tar")
+
+	tmp, err := os.CreateTemp(destDir, ".layer-*.partial")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed into place
+
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), layerPath); err != nil {
+		return "", err
+	}
+	return layerPath, nil
+}
+
+// GetManifest fetches just the manifest for an image reference. The digest returned is
+// the one the reference resolves to: for a multi-arch artifact that is the image index, so
+// pinning it selects the same manifest again.
 func (c *Client) GetManifest(ctx context.Context, imageRef string) (*ocispec.Manifest, map[string]string, string, error) {
+	artifact, err := c.OpenArtifact(ctx, imageRef)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return artifact.Manifest, artifact.Manifest.Annotations, artifact.Digest(), nil
+}
+
+// OpenArtifact resolves imageRef and fetches its plugin manifest once, returning a session
+// that attestation verification and file extraction share instead of resolving the
+// reference again
+func (c *Client) OpenArtifact(ctx context.Context, imageRef string) (*oci.Artifact, error) {
 	ref, err := registry.ParseReference(imageRef)
 	if err != nil {
-		return nil, nil, "", fmt.Errorf("invalid image reference %s: %w", imageRef, err)
+		return nil, fmt.Errorf("invalid image reference %s: %w", imageRef, err)
 	}
 
-	repo, err := remote.NewRepository(ref.Registry + "/" + ref.Repository)
+	repo, err := c.repository(ref)
 	if err != nil {
-		return nil, nil, "", fmt.Errorf("failed to create repository: %w", err)
+		return nil, err
 	}
 
-	// Configure ORAS authentication
-	c.setupRepositoryAuth(repo)
+	artifact, err := oci.OpenArtifact(ctx, &oci.Repository{Repository: repo}, ref.Reference)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", imageRef, classifyError(err))
+	}
+	return artifact, nil
+}
+
+// FetchLayer downloads the layer titled filename from a manifest fetched for imageRef and
+// checks it against the layer digest
+func (c *Client) FetchLayer(ctx context.Context, imageRef string, manifest *ocispec.Manifest, filename string) ([]byte, error) {
+	var layerDesc *ocispec.Descriptor
+	for i, layer := range manifest.Layers {
+		if layer.Annotations[ocispec.AnnotationTitle] == filename {
+			layerDesc = &manifest.Layers[i]
+			break
+		}
+	}
+	if layerDesc == nil {
+		return nil, fmt.Errorf("manifest has no %s layer", filename)
+	}
 
-	// Resolve and fetch manifest
-	manifestDesc, err := repo.Resolve(ctx, ref.Reference)
+	ref, err := registry.ParseReference(imageRef)
 	if err != nil {
-		return nil, nil, "", fmt.Errorf("failed to resolve %s: %w", imageRef, err)
+		return nil, fmt.Errorf("invalid image reference %s: %w", imageRef, err)
 	}
 
-	manifestReader, err := repo.Fetch(ctx, manifestDesc)
+	repo, err := c.repository(ref)
 	if err != nil {
-		return nil, nil, "", fmt.Errorf("failed to fetch manifest: %w", err)
+		return nil, err
 	}
-	defer manifestReader.Close()
 
-	manifestData, err := io.ReadAll(manifestReader)
+	layerReader, err := repo.Fetch(ctx, *layerDesc)
 	if err != nil {
-		return nil, nil, "", fmt.Errorf("failed to read manifest: %w", err)
+		return nil, fmt.Errorf("failed to fetch %s: %w", filename, classifyError(err))
 	}
+	defer layerReader.Close()
 
-	var manifest ocispec.Manifest
-	if err := json.Unmarshal(manifestData, &manifest); err != nil {
-		return nil, nil, "", fmt.Errorf("failed to parse manifest: %w", err)
+	layerContent, err := io.ReadAll(layerReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
 	}
+	metrics.RecordDownload(len(layerContent))
 
-	return &manifest, manifest.Annotations, manifestDesc.Digest.String(), nil
+	if err := verifyDigest(layerContent, layerDesc.Digest); err != nil {
+		return nil, fmt.Errorf("%s digest verification failed: %w", filename, err)
+	}
+
+	return layerContent, nil
 }
 
 // ValidateAccess checks if we can access the registry and a specific repository
@@ -363,32 +516,74 @@ func (c *Client) ValidateAccess(ctx context.Context, imageRef string) error {
 		return fmt.Errorf("invalid image reference %s: %w", imageRef, err)
 	}
 
-	repo, err := remote.NewRepository(ref.Registry + "/" + ref.Repository)
+	repo, err := c.repository(ref)
 	if err != nil {
-		return fmt.Errorf("failed to create repository: %w", err)
+		return err
 	}
 
-	// Configure ORAS authentication
-	c.setupRepositoryAuth(repo)
-
 	// Try to resolve the reference
 	_, err = repo.Resolve(ctx, ref.Reference)
 	if err != nil {
-		return fmt.Errorf("access validation failed for %s: %w", imageRef, err)
+		return fmt.Errorf("access validation failed for %s: %w", imageRef, classifyError(err))
 	}
 
 	return nil
 }
 
+// ListTags returns every tag published to a repository (e.g. "ghcr.io/owner/repo/plugin-id")
+func (c *Client) ListTags(ctx context.Context, repository string) ([]string, error) {
+	ref, err := registry.ParseReference(repository)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository %s: %w", repository, err)
+	}
+	repo, err := c.repository(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	if err := repo.Tags(ctx, "", func(page []string) error {
+		tags = append(tags, page...)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list tags for %s: %w", repository, classifyError(err))
+	}
+
+	return tags, nil
+}
+
 // verifyDigest checks if content matches the expected digest
 func verifyDigest(content []byte, expected digest.Digest) error {
 	actual := digest.FromBytes(content)
 	if actual != expected {
-		return fmt.Errorf("digest mismatch: expected %s, got %s", expected, actual)
+		return &dgerrors.DigestMismatchError{Expected: expected.String(), Actual: actual.String()}
 	}
 	return nil
 }
 
+// classifyError marks registry failures with the matching sentinel: rejected credentials
+// as ErrNotAuthenticated, and server errors, throttling or unreachable hosts as
+// ErrRegistryUnavailable. The message is left unchanged.
+func classifyError(err error) error {
+	var errResp *errcode.ErrorResponse
+	if errors.As(err, &errResp) {
+		switch {
+		case errResp.StatusCode == http.StatusUnauthorized || errResp.StatusCode == http.StatusForbidden:
+			return dgerrors.Mark(err, dgerrors.ErrNotAuthenticated)
+		case errResp.StatusCode == http.StatusTooManyRequests || errResp.StatusCode >= http.StatusInternalServerError:
+			return dgerrors.Mark(err, dgerrors.ErrRegistryUnavailable)
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+		return dgerrors.Mark(err, dgerrors.ErrRegistryUnavailable)
+	}
+
+	return err
+}
+
 // ParseImageReference extracts components from an OCI image reference
 func ParseImageReference(imageRef string) (registryHost, repository, tag string, err error) {
 	ref, err := registry.ParseReference(imageRef)
@@ -399,6 +594,16 @@ func ParseImageReference(imageRef string) (registryHost, repository, tag string,
 	return ref.Registry, ref.Repository, ref.Reference, nil
 }
 
+// ManifestMismatch describes imageRef resolving to actual instead of the expected
+// manifest digest. A tagged reference yields a TagMovedError, since the tag was re-pushed.
+func ManifestMismatch(imageRef, expected, actual string) error {
+	ref, err := registry.ParseReference(imageRef)
+	if err == nil && ref.ValidateReferenceAsDigest() != nil && ref.Reference != "" {
+		return &dgerrors.TagMovedError{Reference: imageRef, Tag: ref.Reference, Expected: expected, Actual: actual}
+	}
+	return &dgerrors.DigestMismatchError{Subject: "manifest", Expected: expected, Actual: actual}
+}
+
 // GenerateBasicAuthHeader creates a basic auth header for registry authentication
 // This is used internally by the HTTP client but exposed for testing
 func GenerateBasicAuthHeader(username, password string) string {
@@ -407,6 +612,20 @@ func GenerateBasicAuthHeader(username, password string) string {
 	return "Basic " + encoded
 }
 
+// MutableTags are tags conventionally re-pushed to follow a branch or channel rather
+// than naming one release
+var MutableTags = []string{"latest", "main", "master", "nightly", "edge"}
+
+// IsMutableReference reports whether imageRef names one of the MutableTags. References
+// pinned by digest are never mutable.
+func IsMutableReference(imageRef string) bool {
+	ref, err := registry.ParseReference(imageRef)
+	if err != nil || ref.ValidateReferenceAsDigest() == nil {
+		return false
+	}
+	return slices.Contains(MutableTags, strings.ToLower(ref.Reference))
+}
+
 // IsGitHubRegistry checks if the registry is GitHub Container Registry
 func IsGitHubRegistry(registryHost string) bool {
 	return strings.Contains(strings.ToLower(registryHost), "ghcr.io") ||
@@ -4,13 +4,25 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote/errcode"
 
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
 	"github.com/gillisandrew/dragonglass-poc/internal/mock"
 	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
 )
@@ -98,6 +110,19 @@ func TestParseImageReference(t *testing.T) {
 	}
 }
 
+func TestManifestMismatch(t *testing.T) {
+	err := ManifestMismatch("ghcr.io/owner/repo/plugin:v1.0.0", "sha256:a", "sha256:b")
+	var moved *dgerrors.TagMovedError
+	if !errors.As(err, &moved) || moved.Tag != "v1.0.0" {
+		t.Errorf("expected TagMovedError for a tagged reference, got %v", err)
+	}
+
+	err = ManifestMismatch("ghcr.io/owner/repo/plugin@sha256:"+strings.Repeat("a", 64), "sha256:a", "sha256:b")
+	if errors.Is(err, dgerrors.ErrTagMoved) || !errors.Is(err, dgerrors.ErrDigestMismatch) {
+		t.Errorf("expected plain digest mismatch for a digest reference, got %v", err)
+	}
+}
+
 func TestGenerateBasicAuthHeader(t *testing.T) {
 	username := "testuser"
 	password := "testpass"
@@ -152,6 +177,29 @@ func TestIsGitHubRegistry(t *testing.T) {
 	}
 }
 
+func TestIsMutableReference(t *testing.T) {
+	tests := []struct {
+		imageRef string
+		expected bool
+	}{
+		{"ghcr.io/owner/repo/plugin:latest", true},
+		{"ghcr.io/owner/repo/plugin:Nightly", true},
+		{"ghcr.io/owner/repo/plugin:main", true},
+		{"ghcr.io/owner/repo/plugin:1.2.3", false},
+		{"ghcr.io/owner/repo/plugin:latest-stable", false},
+		{"ghcr.io/owner/repo/plugin@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", false},
+		{"not a reference", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.imageRef, func(t *testing.T) {
+			if got := IsMutableReference(tt.imageRef); got != tt.expected {
+				t.Errorf("IsMutableReference(%s) = %v; expected %v", tt.imageRef, got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestVerifyDigest(t *testing.T) {
 	content := []byte("test content")
 	correctDigest := digest.FromBytes(content)
@@ -168,6 +216,39 @@ func TestVerifyDigest(t *testing.T) {
 	if err == nil {
 		t.Error("expected error for incorrect digest")
 	}
+	if !errors.Is(err, dgerrors.ErrDigestMismatch) {
+		t.Errorf("expected ErrDigestMismatch, got: %v", err)
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		sentinel error
+	}{
+		{name: "unauthorized", err: &errcode.ErrorResponse{StatusCode: http.StatusUnauthorized}, sentinel: dgerrors.ErrNotAuthenticated},
+		{name: "forbidden", err: &errcode.ErrorResponse{StatusCode: http.StatusForbidden}, sentinel: dgerrors.ErrNotAuthenticated},
+		{name: "server error", err: &errcode.ErrorResponse{StatusCode: http.StatusBadGateway}, sentinel: dgerrors.ErrRegistryUnavailable},
+		{name: "throttled", err: &errcode.ErrorResponse{StatusCode: http.StatusTooManyRequests}, sentinel: dgerrors.ErrRegistryUnavailable},
+		{name: "unreachable", err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, sentinel: dgerrors.ErrRegistryUnavailable},
+		{name: "not found", err: &errcode.ErrorResponse{StatusCode: http.StatusNotFound}},
+		{name: "cancelled", err: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyError(tt.err)
+			if err.Error() != tt.err.Error() {
+				t.Errorf("expected message to be unchanged, got %q", err.Error())
+			}
+			for _, sentinel := range []error{dgerrors.ErrNotAuthenticated, dgerrors.ErrRegistryUnavailable} {
+				if got := errors.Is(err, sentinel); got != (sentinel == tt.sentinel) {
+					t.Errorf("errors.Is(%v) = %v", sentinel, got)
+				}
+			}
+		})
+	}
 }
 
 func TestNewClient(t *testing.T) {
@@ -199,7 +280,7 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
-func TestSetRegistry(t *testing.T) {
+func TestWithRegistry(t *testing.T) {
 	// Create a client with mock authentication for testing
 	opts := DefaultRegistryOpts().WithAuthProvider(mock.NewAuthProvider("test-token", false))
 	client, err := NewClient(opts)
@@ -207,22 +288,151 @@ func TestSetRegistry(t *testing.T) {
 		t.Fatalf("failed to create client: %v", err)
 	}
 
-	err = client.SetRegistry("localhost:5000")
+	derived, err := client.WithRegistry("localhost:5000")
 	if err != nil {
-		t.Errorf("failed to set registry: %v", err)
+		t.Fatalf("failed to derive client: %v", err)
 	}
-
-	if client.registry == nil {
-		t.Error("registry should be set")
+	if derived.opts.RegistryHost != "localhost:5000" || derived.registry.Reference.Registry != "localhost:5000" || derived.token != "test-token" {
+		t.Errorf("unexpected derived client: %+v", derived.opts)
+	}
+	if client.opts.RegistryHost != DefaultRegistry || client.registry.Reference.Registry != DefaultRegistry {
+		t.Errorf("original client changed to %s", client.opts.RegistryHost)
 	}
 
 	// Test invalid registry
-	err = client.SetRegistry("invalid://registry")
-	if err == nil {
+	if _, err := client.WithRegistry("invalid://registry"); err == nil {
 		t.Error("expected error for invalid registry format")
 	}
 }
 
+func TestNewClientCopiesOpts(t *testing.T) {
+	opts := DefaultRegistryOpts().WithAuthProvider(mock.NewAuthProvider("test-token", false))
+	client, err := NewClient(opts)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	opts.WithRegistryHost("localhost:5000").WithPlainHTTP(true)
+	if client.opts.RegistryHost != DefaultRegistry || client.opts.PlainHTTP {
+		t.Errorf("client picked up options changed after construction: %+v", client.opts)
+	}
+}
+
+// newTestRegistry serves the example plugin from owner/plugin:v1.0.0 over plain HTTP and
+// returns its reference
+func newTestRegistry(t *testing.T) string {
+	t.Helper()
+
+	mainJS := []byte("module.exports = {}")
+	layer := ocispec.Descriptor{
+		MediaType:   MediaTypeJavaScript,
+		Digest:      digest.FromBytes(mainJS),
+		Size:        int64(len(mainJS)),
+		Annotations: map[string]string{ocispec.AnnotationTitle: plugin.MainFile},
+	}
+	metadata := &plugin.Metadata{ID: "example-plugin", Name: "Example Plugin", Version: "1.0.0"}
+	manifest, err := json.Marshal(ocispec.Manifest{
+		Versioned:   specs.Versioned{SchemaVersion: 2},
+		MediaType:   ocispec.MediaTypeImageManifest,
+		Config:      ocispec.DescriptorEmptyJSON,
+		Layers:      []ocispec.Descriptor{layer},
+		Annotations: metadata.Annotations(plugin.AnnotationPrefix),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestDigest := digest.FromBytes(manifest).String()
+
+	content := map[string][]byte{
+		"/v2/owner/plugin/manifests/v1.0.0":               manifest,
+		"/v2/owner/plugin/manifests/" + manifestDigest:    manifest,
+		"/v2/owner/plugin/blobs/" + layer.Digest.String(): mainJS,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := content[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if strings.Contains(r.URL.Path, "/manifests/") {
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+			w.Header().Set("Docker-Content-Digest", manifestDigest)
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method != http.MethodHead {
+			_, _ = w.Write(data)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return strings.TrimPrefix(server.URL, "http://") + "/owner/plugin:v1.0.0"
+}
+
+// TestConcurrentPulls shares one client across goroutines; run with -race
+func TestConcurrentPulls(t *testing.T) {
+	imageRef := newTestRegistry(t)
+	host, _, _, err := ParseImageReference(imageRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultRegistryOpts().
+		WithRegistryHost(host).
+		WithPlainHTTP(true).
+		WithAuthProvider(mock.NewAuthProvider("test-token", false)).
+		WithPluginOpts(plugin.DefaultPluginOpts().WithAnnotationNamespace(plugin.AnnotationPrefix))
+	client, err := NewClient(opts)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// Every worker pulls into the same directory
+	destDir := t.TempDir()
+
+	const workers = 8
+	errs := make(chan error, workers*3)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := context.Background()
+
+			result, err := client.Pull(ctx, imageRef, destDir, nil)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if result.Plugin.ID != "example-plugin" || string(result.Layers[0].Content) != "module.exports = {}" {
+				errs <- fmt.Errorf("unexpected pull result %+v", result.Plugin)
+			}
+			if saved, err := os.ReadFile(result.Layers[0].SavedPath); err != nil || string(saved) != "module.exports = {}" {
+				errs <- fmt.Errorf("unexpected saved layer %q (%v)", saved, err)
+			}
+
+			manifest, _, _, err := client.GetManifest(ctx, imageRef)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if _, err := client.FetchLayer(ctx, imageRef, manifest, plugin.MainFile); err != nil {
+				errs <- err
+			}
+
+			// Deriving a client must not disturb pulls through the shared one
+			if _, err := client.WithRegistry("localhost:5000"); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
 // TestNewClientWithMockAuth tests client creation with mock authentication
 func TestNewClientWithMockAuth(t *testing.T) {
 	// Test successful mock authentication
@@ -249,6 +459,65 @@ func TestNewClientWithMockAuth(t *testing.T) {
 	}
 }
 
+// TestRepositoryCredentials tests per-repository token selection
+func TestRepositoryCredentials(t *testing.T) {
+	credentials := func(repository string) (string, bool, error) {
+		switch {
+		case strings.HasPrefix(repository, "ghcr.io/acme/"):
+			return "acme-token", true, nil
+		case strings.HasPrefix(repository, "ghcr.io/broken/"):
+			return "", true, fmt.Errorf("ACME_TOKEN is not set")
+		default:
+			return "", false, nil
+		}
+	}
+
+	// A missing default token does not prevent access to repositories with their own credential
+	opts := DefaultRegistryOpts().
+		WithAuthProvider(mock.NewAuthProvider("", true)).
+		WithCredentials(credentials)
+	client, err := NewClient(opts)
+	if err != nil {
+		t.Fatalf("expected client without default token when credentials are configured, got %v", err)
+	}
+
+	tests := []struct {
+		repository string
+		token      string
+		errorMsg   string
+	}{
+		{repository: "ghcr.io/acme/notes/plugin", token: "acme-token"},
+		{repository: "ghcr.io/broken/plugin", errorMsg: "ACME_TOKEN is not set"},
+		{repository: "ghcr.io/public/plugin", errorMsg: "failed to get authentication token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repository, func(t *testing.T) {
+			cred, err := client.repositoryCredential(tt.repository)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil || cred.Password != tt.token {
+				t.Errorf("expected token %q, got %q (err %v)", tt.token, cred.Password, err)
+			}
+		})
+	}
+
+	// Repositories without a credential fall back to the default token
+	client, err = NewClient(DefaultRegistryOpts().
+		WithAuthProvider(mock.NewAuthProvider("default-token", false)).
+		WithCredentials(credentials))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cred, err := client.repositoryCredential("ghcr.io/public/plugin"); err != nil || cred.Password != "default-token" {
+		t.Errorf("expected default token, got %q (err %v)", cred.Password, err)
+	}
+}
+
 // TestNewClientInCIEnvironment tests client creation in CI environments
 func TestNewClientInCIEnvironment(t *testing.T) {
 	if !isRunningInCI() {
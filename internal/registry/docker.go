@@ -0,0 +1,103 @@
+// ABOUTME: Docker credential sources for registries other than the GitHub Container Registry
+// ABOUTME: Reads ~/.docker/config.json and docker-credential-* helpers such as ECR and Artifact Registry
+package registry
+
+import (
+	"context"
+	"strings"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+// DockerCredentialStore returns the credentials from the Docker config ($DOCKER_CONFIG or
+// ~/.docker/config.json), including configured credsStore and credHelpers. It returns nil
+// when disabled or when the config cannot be read, so Docker logins stay optional.
+func DockerCredentialStore(enabled bool) credentials.Store {
+	if !enabled {
+		return nil
+	}
+	store, err := credentials.NewStoreFromDocker(credentials.StoreOptions{})
+	if err != nil || !store.IsAuthConfigured() {
+		return nil
+	}
+	return store
+}
+
+// dockerCredential returns the Docker credential for a registry host, if there is one
+func dockerCredential(store credentials.Store, host string) (auth.Credential, bool) {
+	if store == nil {
+		return auth.EmptyCredential, false
+	}
+	cred, err := credentials.Credential(store)(context.Background(), host)
+	if err != nil || cred == auth.EmptyCredential {
+		return auth.EmptyCredential, false
+	}
+	return cred, true
+}
+
+// resolveCredential picks the credential to present for repository, e.g.
+// "ghcr.io/owner/repo/plugin-id":
+//  1. a matching per-repository credential from opts.Credentials
+//  2. for registries other than ghcr.io, a Docker credential for the host
+//  3. the default GitHub token
+//  4. for ghcr.io without a GitHub token, a Docker credential
+//
+// defaultToken is only called when the GitHub token is needed.
+func resolveCredential(opts *RegistryOpts, repository string, defaultToken func() (string, error)) (auth.Credential, error) {
+	if opts != nil && opts.Credentials != nil {
+		token, ok, err := opts.Credentials(repository)
+		if err != nil {
+			return auth.EmptyCredential, err
+		}
+		if ok {
+			return tokenCredential(token), nil
+		}
+	}
+
+	var docker credentials.Store
+	if opts != nil {
+		docker = opts.DockerCredentials
+	}
+	host, _, _ := strings.Cut(repository, "/")
+	gitHubRegistry := strings.EqualFold(host, DefaultRegistry)
+
+	// The GitHub token is meaningless to other registries, so their own login wins
+	if !gitHubRegistry {
+		if cred, ok := dockerCredential(docker, host); ok {
+			return cred, nil
+		}
+	}
+
+	token, err := defaultToken()
+	if err == nil {
+		return tokenCredential(token), nil
+	}
+
+	if gitHubRegistry {
+		if cred, ok := dockerCredential(docker, host); ok {
+			return cred, nil
+		}
+	}
+	return auth.EmptyCredential, err
+}
+
+// LookupCredential returns the credential for repository from opts, consulting the auth
+// provider only when no per-repository or Docker credential applies
+func LookupCredential(opts *RegistryOpts, repository string) (auth.Credential, error) {
+	return resolveCredential(opts, repository, func() (string, error) {
+		var provider AuthProvider = &githubAuthAdapter{}
+		if opts != nil && opts.AuthClient != nil {
+			provider = opts.AuthClient
+		}
+		return provider.GetToken()
+	})
+}
+
+// tokenCredential presents token the way GHCR expects; the username is ignored
+func tokenCredential(token string) auth.Credential {
+	return auth.Credential{
+		Username: "token",
+		Password: token,
+	}
+}
@@ -0,0 +1,94 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+const ecrHost = "123456789012.dkr.ecr.us-east-1.amazonaws.com"
+
+func TestResolveCredential(t *testing.T) {
+	docker := credentials.NewMemoryStore()
+	ecrLogin := auth.Credential{Username: "AWS", Password: "ecr-password"}
+	ghcrLogin := auth.Credential{Username: "octocat", Password: "docker-ghcr-token"}
+	if err := docker.Put(context.Background(), ecrHost, ecrLogin); err != nil {
+		t.Fatal(err)
+	}
+	if err := docker.Put(context.Background(), "ghcr.io", ghcrLogin); err != nil {
+		t.Fatal(err)
+	}
+
+	perRepository := func(repository string) (string, bool, error) {
+		if repository == ecrHost+"/team/plugin" {
+			return "team-token", true, nil
+		}
+		return "", false, nil
+	}
+	opts := DefaultRegistryOpts().WithCredentials(perRepository).WithDockerCredentials(docker)
+
+	withToken := func() (string, error) { return "github-token", nil }
+	withoutToken := func() (string, error) { return "", errors.New("not authenticated") }
+
+	tests := []struct {
+		name         string
+		repository   string
+		defaultToken func() (string, error)
+		expected     auth.Credential
+		wantErr      bool
+	}{
+		{name: "per-repository credential wins", repository: ecrHost + "/team/plugin", defaultToken: withToken, expected: tokenCredential("team-token")},
+		{name: "docker login for other registries", repository: ecrHost + "/plugins/notes", defaultToken: withToken, expected: ecrLogin},
+		{name: "github token for ghcr.io", repository: "ghcr.io/owner/repo/plugin", defaultToken: withToken, expected: tokenCredential("github-token")},
+		{name: "docker login for ghcr.io without token", repository: "ghcr.io/owner/repo/plugin", defaultToken: withoutToken, expected: ghcrLogin},
+		{name: "github token for unknown registry", repository: "registry.example.com/plugin", defaultToken: withToken, expected: tokenCredential("github-token")},
+		{name: "no credential at all", repository: "registry.example.com/plugin", defaultToken: withoutToken, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cred, err := resolveCredential(opts, tt.repository, tt.defaultToken)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", cred)
+				}
+				return
+			}
+			if err != nil || cred != tt.expected {
+				t.Errorf("expected %+v, got %+v (err %v)", tt.expected, cred, err)
+			}
+		})
+	}
+}
+
+func TestDockerCredentialStore(t *testing.T) {
+	if DockerCredentialStore(false) != nil {
+		t.Error("expected no store when disabled")
+	}
+
+	dockerConfig := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", dockerConfig)
+
+	if DockerCredentialStore(true) != nil {
+		t.Error("expected no store without a Docker config")
+	}
+
+	config := `{"auths": {"` + ecrHost + `": {"auth": "QVdTOmVjci1wYXNzd29yZA=="}}}`
+	if err := os.WriteFile(filepath.Join(dockerConfig, "config.json"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	store := DockerCredentialStore(true)
+	if store == nil {
+		t.Fatal("expected a store for a Docker config with logins")
+	}
+	cred, ok := dockerCredential(store, ecrHost)
+	if !ok || cred.Username != "AWS" || cred.Password != "ecr-password" {
+		t.Errorf("unexpected credential %+v (found %v)", cred, ok)
+	}
+}
@@ -0,0 +1,248 @@
+// ABOUTME: Pushes plugin artifacts to OCI registries in the layout the build workflow produces
+// ABOUTME: Uploads main.js and styles.css layers, tags the manifest, and attaches SBOM referrers
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+)
+
+// Media types of plugin artifacts, matching the build workflow
+const (
+	ArtifactType        = "application/vnd.dragonglass.plugin"
+	MediaTypeJavaScript = "application/javascript"
+	MediaTypeCSS        = "text/css"
+	MediaTypeSPDX       = "application/spdx+json"
+)
+
+// SBOMFileName is the title of the layer holding an attached SBOM
+const SBOMFileName = "sbom.spdx.json"
+
+// Media types and annotations of attestation referrers, matching those GitHub artifact
+// attestations push
+const (
+	MediaTypeSigstoreBundle = "application/vnd.dev.sigstore.bundle.v0.3+json"
+	MediaTypeDSSEEnvelope   = "application/vnd.dsse.envelope.v1+json"
+
+	AnnotationBundleContent       = "dev.sigstore.bundle.content"
+	AnnotationBundlePredicateType = "dev.sigstore.bundle.predicateType"
+)
+
+// Layer is a file pushed as a single artifact layer
+type Layer struct {
+	Title     string // file name, empty for untitled content such as attestations
+	MediaType string
+	Content   []byte
+}
+
+// Artifact is an OCI artifact to push
+type Artifact struct {
+	ArtifactType string
+	Layers       []Layer
+	Annotations  map[string]string
+}
+
+// NewPluginArtifact lays out dist the way the build workflow publishes it: main.js and
+// styles.css as layers, and manifest.json as annotations under namespace. extra
+// annotations are applied last.
+func NewPluginArtifact(dist *plugin.Dist, namespace string, extra map[string]string) *Artifact {
+	metadata := dist.Metadata
+	artifact := &Artifact{
+		ArtifactType: ArtifactType,
+		Layers:       []Layer{{Title: plugin.MainFile, MediaType: MediaTypeJavaScript, Content: dist.Main}},
+		Annotations:  metadata.Annotations(namespace),
+	}
+	if dist.Styles != nil {
+		artifact.Layers = append(artifact.Layers, Layer{Title: plugin.StylesFile, MediaType: MediaTypeCSS, Content: dist.Styles})
+	}
+
+	standard := map[string]string{
+		ocispec.AnnotationTitle:       metadata.Name,
+		ocispec.AnnotationVersion:     metadata.Version,
+		ocispec.AnnotationDescription: metadata.Description,
+		ocispec.AnnotationAuthors:     metadata.Author,
+		ocispec.AnnotationURL:         metadata.AuthorURL,
+	}
+	for key, value := range standard {
+		if value != "" {
+			artifact.Annotations[key] = value
+		}
+	}
+	maps.Copy(artifact.Annotations, extra)
+
+	return artifact
+}
+
+// Push uploads artifact and tags it with the tag in imageRef, returning the manifest
+// descriptor
+func (c *Client) Push(ctx context.Context, imageRef string, artifact *Artifact) (ocispec.Descriptor, error) {
+	ref, err := registry.ParseReference(imageRef)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("invalid image reference %s: %w", imageRef, err)
+	}
+	if ref.Reference == "" || ref.ValidateReferenceAsDigest() == nil {
+		return ocispec.Descriptor{}, fmt.Errorf("image reference %s must include a tag", imageRef)
+	}
+
+	repo, err := c.repository(ref)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	desc, err := pushArtifact(ctx, repo, ref.Reference, artifact)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to push %s: %w", imageRef, classifyError(err))
+	}
+	return desc, nil
+}
+
+// AttachSBOM uploads an SPDX document as a referrer of subject in the repository of
+// imageRef
+func (c *Client) AttachSBOM(ctx context.Context, imageRef string, subject ocispec.Descriptor, sbom []byte) (ocispec.Descriptor, error) {
+	if err := validateSPDX(sbom); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	ref, err := registry.ParseReference(imageRef)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("invalid image reference %s: %w", imageRef, err)
+	}
+	repo, err := c.repository(ref)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	desc, err := attachReferrer(ctx, repo, subject, Layer{Title: SBOMFileName, MediaType: MediaTypeSPDX, Content: sbom}, nil)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to attach SBOM to %s: %w", imageRef, classifyError(err))
+	}
+	return desc, nil
+}
+
+// AttachAttestation uploads a signed statement as a referrer of subject in the repository
+// of imageRef. mediaType is MediaTypeSigstoreBundle or MediaTypeDSSEEnvelope.
+func (c *Client) AttachAttestation(ctx context.Context, imageRef string, subject ocispec.Descriptor, mediaType, predicateType string, data []byte) (ocispec.Descriptor, error) {
+	ref, err := registry.ParseReference(imageRef)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("invalid image reference %s: %w", imageRef, err)
+	}
+	repo, err := c.repository(ref)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	annotations := map[string]string{AnnotationBundlePredicateType: predicateType}
+	if mediaType == MediaTypeSigstoreBundle {
+		annotations[AnnotationBundleContent] = "dsse-envelope"
+	}
+	desc, err := attachReferrer(ctx, repo, subject, Layer{MediaType: mediaType, Content: data}, annotations)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to attach attestation to %s: %w", imageRef, classifyError(err))
+	}
+	return desc, nil
+}
+
+// Resolve returns the manifest descriptor imageRef points to
+func (c *Client) Resolve(ctx context.Context, imageRef string) (ocispec.Descriptor, error) {
+	ref, err := registry.ParseReference(imageRef)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("invalid image reference %s: %w", imageRef, err)
+	}
+	repo, err := c.repository(ref)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	desc, err := repo.Resolve(ctx, ref.Reference)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to resolve %s: %w", imageRef, classifyError(err))
+	}
+	return desc, nil
+}
+
+// pushArtifact uploads the layers of artifact, then packs, pushes and tags its manifest
+func pushArtifact(ctx context.Context, target oras.Target, tag string, artifact *Artifact) (ocispec.Descriptor, error) {
+	layers, err := pushLayers(ctx, target, artifact.Layers)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	desc, err := oras.PackManifest(ctx, target, oras.PackManifestVersion1_1, artifact.ArtifactType, oras.PackManifestOptions{
+		Layers:              layers,
+		ManifestAnnotations: artifact.Annotations,
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to push manifest: %w", err)
+	}
+
+	if err := target.Tag(ctx, desc, tag); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to tag manifest as %s: %w", tag, err)
+	}
+	return desc, nil
+}
+
+// attachReferrer pushes layer as an artifact whose subject is subject, so it is listed by
+// the referrers API with annotations
+func attachReferrer(ctx context.Context, target oras.Target, subject ocispec.Descriptor, layer Layer, annotations map[string]string) (ocispec.Descriptor, error) {
+	layers, err := pushLayers(ctx, target, []Layer{layer})
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	desc, err := oras.PackManifest(ctx, target, oras.PackManifestVersion1_1, layer.MediaType, oras.PackManifestOptions{
+		Subject:             &subject,
+		Layers:              layers,
+		ManifestAnnotations: annotations,
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to push manifest: %w", err)
+	}
+	return desc, nil
+}
+
+// pushLayers uploads each layer unless the registry already has it
+func pushLayers(ctx context.Context, target oras.Target, layers []Layer) ([]ocispec.Descriptor, error) {
+	descs := make([]ocispec.Descriptor, 0, len(layers))
+	for _, layer := range layers {
+		desc := content.NewDescriptorFromBytes(layer.MediaType, layer.Content)
+		if layer.Title != "" {
+			desc.Annotations = map[string]string{ocispec.AnnotationTitle: layer.Title}
+		}
+
+		exists, err := target.Exists(ctx, desc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for %s: %w", layer.Title, err)
+		}
+		if !exists {
+			if err := target.Push(ctx, desc, bytes.NewReader(layer.Content)); err != nil {
+				return nil, fmt.Errorf("failed to push %s: %w", layer.Title, err)
+			}
+		}
+		descs = append(descs, desc)
+	}
+	return descs, nil
+}
+
+// validateSPDX checks that data is an SPDX JSON document
+func validateSPDX(data []byte) error {
+	var doc struct {
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("SBOM is not valid JSON: %w", err)
+	}
+	if doc.SPDXVersion == "" {
+		return fmt.Errorf("SBOM is not an SPDX document (no spdxVersion)")
+	}
+	return nil
+}
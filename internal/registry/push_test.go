@@ -0,0 +1,205 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+)
+
+func testDist(styles []byte) *plugin.Dist {
+	return &plugin.Dist{
+		Metadata: &plugin.Metadata{ID: "sample", Name: "Sample", Version: "1.2.3", Author: "Me"},
+		Main:     []byte("module.exports = {}"),
+		Styles:   styles,
+	}
+}
+
+func TestNewPluginArtifact(t *testing.T) {
+	tests := []struct {
+		name       string
+		styles     []byte
+		wantLayers []string
+	}{
+		{name: "with styles", styles: []byte(".x {}"), wantLayers: []string{MediaTypeJavaScript, MediaTypeCSS}},
+		{name: "without styles", wantLayers: []string{MediaTypeJavaScript}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artifact := NewPluginArtifact(testDist(tt.styles), "md.obsidian.plugin.v0", map[string]string{
+				ocispec.AnnotationSource:  "https://github.com/me/sample",
+				ocispec.AnnotationAuthors: "Someone Else",
+			})
+
+			if len(artifact.Layers) != len(tt.wantLayers) {
+				t.Fatalf("expected %d layers, got %d", len(tt.wantLayers), len(artifact.Layers))
+			}
+			for i, mediaType := range tt.wantLayers {
+				if artifact.Layers[i].MediaType != mediaType {
+					t.Errorf("layer %d: expected %s, got %s", i, mediaType, artifact.Layers[i].MediaType)
+				}
+			}
+
+			expected := map[string]string{
+				"md.obsidian.plugin.v0.id":      "sample",
+				"md.obsidian.plugin.v0.version": "1.2.3",
+				ocispec.AnnotationTitle:         "Sample",
+				ocispec.AnnotationSource:        "https://github.com/me/sample",
+				ocispec.AnnotationAuthors:       "Someone Else",
+			}
+			for key, value := range expected {
+				if got := artifact.Annotations[key]; got != value {
+					t.Errorf("annotation %s: expected %q, got %q", key, value, got)
+				}
+			}
+			if _, ok := artifact.Annotations[ocispec.AnnotationDescription]; ok {
+				t.Error("expected empty description to be left out")
+			}
+		})
+	}
+}
+
+func TestPushArtifact(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	artifact := NewPluginArtifact(testDist([]byte(".x {}")), "md.obsidian.plugin.v0", nil)
+
+	desc, err := pushArtifact(ctx, store, "1.2.3", artifact)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tagged, err := store.Resolve(ctx, "1.2.3")
+	if err != nil || tagged.Digest != desc.Digest {
+		t.Fatalf("expected tag to resolve to %s, got %v (%v)", desc.Digest, tagged.Digest, err)
+	}
+
+	data, err := content.FetchAll(ctx, store, desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.ArtifactType != ArtifactType {
+		t.Errorf("expected artifact type %s, got %s", ArtifactType, manifest.ArtifactType)
+	}
+
+	// The manifest parses like a pulled plugin, and its layers extract by title
+	parser := plugin.NewManifestParser(plugin.DefaultPluginOpts().WithAnnotationNamespace("md.obsidian.plugin.v0"))
+	metadata, err := parser.ParseMetadata(&manifest, manifest.Annotations)
+	if err != nil || metadata.ID != "sample" {
+		t.Errorf("expected plugin metadata, got %+v (%v)", metadata, err)
+	}
+	for _, layer := range manifest.Layers {
+		layerData, err := content.FetchAll(ctx, store, layer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if layer.Annotations[ocispec.AnnotationTitle] == plugin.MainFile && string(layerData) != "module.exports = {}" {
+			t.Errorf("unexpected main.js layer: %q", layerData)
+		}
+	}
+
+	// Pushing again reuses the layers already present
+	again, err := pushArtifact(ctx, store, "latest", artifact)
+	if err != nil {
+		t.Fatalf("unexpected error re-pushing: %v", err)
+	}
+	if len(again.Digest) == 0 {
+		t.Error("expected a manifest descriptor")
+	}
+}
+
+func TestAttachReferrer(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	subject, err := pushArtifact(ctx, store, "1.2.3", NewPluginArtifact(testDist(nil), "md.obsidian.plugin.v0", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sbom := []byte(`{"spdxVersion": "SPDX-2.3", "packages": []}`)
+	desc, err := attachReferrer(ctx, store, subject, Layer{Title: SBOMFileName, MediaType: MediaTypeSPDX, Content: sbom}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	referrers, err := store.Predecessors(ctx, subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(referrers) != 1 || referrers[0].Digest != desc.Digest || referrers[0].ArtifactType != MediaTypeSPDX {
+		t.Errorf("expected the SBOM as the only referrer, got %+v", referrers)
+	}
+
+	// Attestations are listed with the predicate type annotation the verifier filters on
+	bundle := []byte(`{"mediaType": "application/vnd.dev.sigstore.bundle.v0.3+json"}`)
+	desc, err = attachReferrer(ctx, store, subject, Layer{MediaType: MediaTypeSigstoreBundle, Content: bundle}, map[string]string{
+		AnnotationBundlePredicateType: "https://spdx.dev/Document/v2.3",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	referrers, err = store.Predecessors(ctx, subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, referrer := range referrers {
+		if referrer.Digest == desc.Digest {
+			found = referrer.ArtifactType == MediaTypeSigstoreBundle && referrer.Annotations[AnnotationBundlePredicateType] == "https://spdx.dev/Document/v2.3"
+		}
+	}
+	if !found {
+		t.Errorf("expected the attestation referrer with its predicate type, got %+v", referrers)
+	}
+
+	data, err := content.FetchAll(ctx, store, desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := manifest.Layers[0].Annotations[ocispec.AnnotationTitle]; ok {
+		t.Error("expected the attestation layer to be untitled")
+	}
+}
+
+func TestValidateSPDX(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		errorMsg string
+	}{
+		{name: "spdx", data: `{"spdxVersion": "SPDX-2.3"}`},
+		{name: "cyclonedx", data: `{"bomFormat": "CycloneDX"}`, errorMsg: "not an SPDX document"},
+		{name: "not json", data: `spdx`, errorMsg: "not valid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSPDX([]byte(tt.data))
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// ABOUTME: Enumeration of the plugin repositories published under a GHCR namespace
+// ABOUTME: Reports which tags carry provenance and SBOM referrers without verifying them
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/githubapi"
+)
+
+// packagesAPIURL is the GitHub REST API that lists the container packages of an owner
+var packagesAPIURL = githubapi.APIURL
+
+// Packages returned per request; the API maximum
+const packagesPageSize = 100
+
+// errOwnerNotFound reports a packages API request for an owner of the wrong kind or none
+var errOwnerNotFound = errors.New("owner not found")
+
+// ReferrerStatus records which supply-chain referrers are attached to a manifest
+type ReferrerStatus struct {
+	Digest     string `json:"digest"`
+	Provenance bool   `json:"provenance"`
+	SBOM       bool   `json:"sbom"`
+}
+
+// ListRepositories returns the repositories published under namespace, e.g.
+// "ghcr.io/owner" or "ghcr.io/owner/repo" for the plugins a repository publishes, sorted
+// by name. GHCR has no catalog endpoint, so only ghcr.io namespaces are supported; the
+// packages are listed through the GitHub API with the client's token.
+func (c *Client) ListRepositories(ctx context.Context, namespace string) ([]string, error) {
+	host, owner, prefix, err := parseNamespace(namespace)
+	if err != nil {
+		return nil, err
+	}
+	if host != DefaultRegistry {
+		return nil, fmt.Errorf("listing repositories is only supported on %s, not %s", DefaultRegistry, host)
+	}
+
+	// The owner may be an organization or a user; try the organization first
+	names, err := c.listPackages(ctx, "orgs", owner)
+	if errors.Is(err, errOwnerNotFound) {
+		names, err = c.listPackages(ctx, "users", owner)
+	}
+	if errors.Is(err, errOwnerNotFound) {
+		return nil, fmt.Errorf("no GitHub user or organization named %s", owner)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var repositories []string
+	for _, name := range names {
+		if prefix != "" && !strings.HasPrefix(name, prefix+"/") {
+			continue
+		}
+		repositories = append(repositories, host+"/"+owner+"/"+name)
+	}
+	sort.Strings(repositories)
+	return repositories, nil
+}
+
+// parseNamespace splits "ghcr.io/owner/prefix" into its registry host, owner, and the
+// optional package name prefix
+func parseNamespace(namespace string) (host, owner, prefix string, err error) {
+	parts := strings.SplitN(strings.Trim(namespace, "/"), "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" || strings.ContainsAny(namespace, ":@") {
+		return "", "", "", fmt.Errorf("invalid namespace %q, expected <registry>/<owner>[/<path>]", namespace)
+	}
+	if len(parts) == 3 {
+		prefix = strings.Trim(parts[2], "/")
+	}
+	return parts[0], parts[1], prefix, nil
+}
+
+// listPackages returns the names of the container packages of owner, where kind is
+// "orgs" or "users"
+func (c *Client) listPackages(ctx context.Context, kind, owner string) ([]string, error) {
+	if c.tokenErr != nil {
+		return nil, c.tokenErr
+	}
+
+	var names []string
+	for page := 1; ; page++ {
+		path := fmt.Sprintf("/%s/%s/packages?package_type=container&per_page=%d&page=%d", kind, url.PathEscape(owner), packagesPageSize, page)
+		resp, err := c.github.Get(ctx, path)
+		if err != nil {
+			return nil, classifyError(fmt.Errorf("failed to list packages of %s: %w", owner, err))
+		}
+
+		var packages []struct {
+			Name string `json:"name"`
+		}
+		err = decodePackages(resp, &packages)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list packages of %s: %w", owner, err)
+		}
+
+		for _, p := range packages {
+			names = append(names, p.Name)
+		}
+		if len(packages) < packagesPageSize {
+			return names, nil
+		}
+	}
+}
+
+// decodePackages decodes a packages API response into packages, returning
+// errOwnerNotFound for an unknown owner
+func decodePackages(resp *http.Response, packages any) error {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return json.NewDecoder(resp.Body).Decode(packages)
+	case http.StatusNotFound:
+		return errOwnerNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return dgerrors.Mark(fmt.Errorf("packages API returned %s (the token needs read:packages)", resp.Status), dgerrors.ErrNotAuthenticated)
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("packages API returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+}
+
+// ReferrerStatus resolves imageRef and reports whether provenance and SBOM referrers are
+// attached to it. The referrers are neither fetched nor verified.
+func (c *Client) ReferrerStatus(ctx context.Context, imageRef string) (ReferrerStatus, error) {
+	ref, err := registry.ParseReference(imageRef)
+	if err != nil {
+		return ReferrerStatus{}, fmt.Errorf("invalid image reference %s: %w", imageRef, err)
+	}
+	repo, err := c.repository(ref)
+	if err != nil {
+		return ReferrerStatus{}, err
+	}
+
+	desc, err := repo.Resolve(ctx, ref.Reference)
+	if err != nil {
+		return ReferrerStatus{}, fmt.Errorf("failed to resolve %s: %w", imageRef, classifyError(err))
+	}
+	status, err := referrerStatus(ctx, repo, desc)
+	if err != nil {
+		return ReferrerStatus{}, fmt.Errorf("failed to list referrers of %s: %w", imageRef, classifyError(err))
+	}
+	return status, nil
+}
+
+// referrerStatus classifies the referrers of desc in store by their artifact type and
+// predicate type annotation
+func referrerStatus(ctx context.Context, store content.ReadOnlyGraphStorage, desc ocispec.Descriptor) (ReferrerStatus, error) {
+	status := ReferrerStatus{Digest: desc.Digest.String()}
+
+	referrers, err := registry.Referrers(ctx, store, desc, "")
+	if err != nil {
+		return status, err
+	}
+	for _, referrer := range referrers {
+		predicateType := referrer.Annotations[AnnotationBundlePredicateType]
+		switch {
+		case strings.HasPrefix(predicateType, "https://slsa.dev/provenance/"):
+			status.Provenance = true
+		case referrer.ArtifactType == MediaTypeSPDX,
+			strings.HasPrefix(predicateType, "https://spdx.dev/Document"),
+			strings.HasPrefix(predicateType, "https://cyclonedx.org/bom"):
+			status.SBOM = true
+		}
+	}
+	return status, nil
+}
@@ -0,0 +1,115 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"oras.land/oras-go/v2/content/memory"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/mock"
+)
+
+func TestListRepositories(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/orgs/"):
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/users/someone/packages" && r.URL.Query().Get("page") == "1":
+			packages := make([]map[string]string, 0, packagesPageSize)
+			for i := 0; i < packagesPageSize-1; i++ {
+				packages = append(packages, map[string]string{"name": fmt.Sprintf("other/package-%02d", i)})
+			}
+			packages = append(packages, map[string]string{"name": "plugins/zeta"})
+			_ = json.NewEncoder(w).Encode(packages)
+		case r.URL.Path == "/users/someone/packages":
+			_ = json.NewEncoder(w).Encode([]map[string]string{{"name": "plugins/alpha"}})
+		case r.URL.Path == "/users/private/packages":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	defer func(original string) { packagesAPIURL = original }(packagesAPIURL)
+	packagesAPIURL = server.URL
+
+	client, err := NewClient(DefaultRegistryOpts().WithAuthProvider(mock.NewAuthProvider("test-token", false)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	repositories, err := client.ListRepositories(ctx, "ghcr.io/someone/plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(repositories, ",") != "ghcr.io/someone/plugins/alpha,ghcr.io/someone/plugins/zeta" {
+		t.Errorf("expected both pages filtered to the namespace, got %v", repositories)
+	}
+	if authorization != "Bearer test-token" {
+		t.Errorf("expected the token to be sent, got %q", authorization)
+	}
+
+	all, err := client.ListRepositories(ctx, "ghcr.io/someone")
+	if err != nil || len(all) != packagesPageSize+1 {
+		t.Errorf("expected every package of the owner, got %d (%v)", len(all), err)
+	}
+
+	if _, err := client.ListRepositories(ctx, "ghcr.io/nobody"); err == nil || !strings.Contains(err.Error(), "no GitHub user or organization") {
+		t.Errorf("expected an unknown owner to fail, got %v", err)
+	}
+	if _, err := client.ListRepositories(ctx, "ghcr.io/private"); !errors.Is(err, dgerrors.ErrNotAuthenticated) {
+		t.Errorf("expected a forbidden listing to be an authentication error, got %v", err)
+	}
+	for _, namespace := range []string{"ghcr.io", "docker.io/someone", "ghcr.io/someone/plugins:1.0.0"} {
+		if _, err := client.ListRepositories(ctx, namespace); err == nil {
+			t.Errorf("expected namespace %q to be rejected", namespace)
+		}
+	}
+}
+
+func TestReferrerStatus(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	subject, err := pushArtifact(ctx, store, "1.2.3", NewPluginArtifact(testDist(nil), "md.obsidian.plugin.v0", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := referrerStatus(ctx, store, subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Digest != subject.Digest.String() || status.Provenance || status.SBOM {
+		t.Errorf("expected no referrers, got %+v", status)
+	}
+
+	bundle := []byte(`{"mediaType": "application/vnd.dev.sigstore.bundle.v0.3+json"}`)
+	if _, err := attachReferrer(ctx, store, subject, Layer{MediaType: MediaTypeSigstoreBundle, Content: bundle}, map[string]string{
+		AnnotationBundlePredicateType: "https://slsa.dev/provenance/v1",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if status, err = referrerStatus(ctx, store, subject); err != nil || !status.Provenance || status.SBOM {
+		t.Errorf("expected provenance only, got %+v (%v)", status, err)
+	}
+
+	sbom := []byte(`{"spdxVersion": "SPDX-2.3", "packages": []}`)
+	if _, err := attachReferrer(ctx, store, subject, Layer{Title: SBOMFileName, MediaType: MediaTypeSPDX, Content: sbom}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if status, err = referrerStatus(ctx, store, subject); err != nil || !status.Provenance || !status.SBOM {
+		t.Errorf("expected provenance and SBOM, got %+v (%v)", status, err)
+	}
+}
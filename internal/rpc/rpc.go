@@ -0,0 +1,251 @@
+// ABOUTME: JSON-RPC 2.0 server over newline-delimited JSON streams such as unix sockets
+// ABOUTME: Runs requests concurrently and streams progress notifications while they run
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+const (
+	Version = "2.0"
+
+	// ProgressMethod is the notification sent for each progress event of a running request
+	ProgressMethod = "progress"
+
+	// maxMessageSize bounds a single request line
+	maxMessageSize = 1 << 20
+)
+
+// Standard JSON-RPC error codes, and CodeFailed for requests whose operation failed
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeFailed         = -32000
+)
+
+// Error is a JSON-RPC error object. Handlers return it to choose the code and data sent
+// to the client; other errors are sent as CodeFailed with the error text.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// InvalidParams reports request parameters the handler cannot use
+func InvalidParams(format string, args ...any) *Error {
+	return &Error{Code: CodeInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// Handler runs one method call. progress sends an event to the client as a "progress"
+// notification tagged with the request id; it is safe to call from any goroutine until
+// the handler returns. ctx is cancelled when the client disconnects.
+type Handler func(ctx context.Context, params json.RawMessage, progress func(event any)) (any, error)
+
+// Request is a JSON-RPC request, or a notification when ID is empty
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC response carrying either Result or Error
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Notification is a server-to-client message without an id
+type Notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+// ProgressParams are the params of a progress notification
+type ProgressParams struct {
+	// ID of the request the event belongs to
+	ID    json.RawMessage `json:"id"`
+	Event any             `json:"event"`
+}
+
+// Server dispatches requests to registered handlers
+type Server struct {
+	handlers map[string]Handler
+}
+
+// NewServer creates a server without any methods
+func NewServer() *Server {
+	return &Server{handlers: map[string]Handler{}}
+}
+
+// Register adds a method, replacing any handler already registered under that name
+func (s *Server) Register(method string, handler Handler) {
+	s.handlers[method] = handler
+}
+
+// Serve accepts connections from listener until ctx is cancelled or the listener fails,
+// serving each on its own goroutine
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = s.ServeConn(ctx, conn)
+		}()
+	}
+}
+
+// ServeConn reads requests from conn, one JSON object per line, until it is closed or ctx
+// is cancelled. Requests run concurrently and their responses are written as they
+// complete; in-flight requests are cancelled when the connection ends.
+func (s *Server) ServeConn(ctx context.Context, conn io.ReadWriteCloser) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	out := &writer{encoder: json.NewEncoder(conn)}
+	var wg sync.WaitGroup
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req Request
+		if err := json.Unmarshal(line, &req); err != nil {
+			out.write(Response{JSONRPC: Version, ID: json.RawMessage("null"), Error: &Error{Code: CodeParseError, Message: "invalid JSON: " + err.Error()}})
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.handle(ctx, out, req)
+		}()
+	}
+
+	err := scanner.Err()
+	closed := ctx.Err() != nil
+
+	// The client is gone; stop its requests rather than finishing work nobody will see
+	cancel()
+	wg.Wait()
+
+	if err != nil && !closed && !errors.Is(err, net.ErrClosed) {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+	return nil
+}
+
+// handle runs req and writes its response. Notifications (requests without an id) run
+// without a response or progress events.
+func (s *Server) handle(ctx context.Context, out *writer, req Request) {
+	notification := len(req.ID) == 0
+	reply := func(result any, err *Error) {
+		if !notification {
+			out.write(Response{JSONRPC: Version, ID: req.ID, Result: result, Error: err})
+		}
+	}
+
+	if req.JSONRPC != Version || req.Method == "" {
+		reply(nil, &Error{Code: CodeInvalidRequest, Message: `request must have "jsonrpc": "2.0" and a method`})
+		return
+	}
+	handler, ok := s.handlers[req.Method]
+	if !ok {
+		reply(nil, &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)})
+		return
+	}
+
+	var mu sync.Mutex
+	done := false
+	progress := func(event any) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !notification && !done {
+			out.write(Notification{JSONRPC: Version, Method: ProgressMethod, Params: ProgressParams{ID: req.ID, Event: event}})
+		}
+	}
+
+	result, err := handler(ctx, req.Params, progress)
+
+	// Progress sent after the handler returns would arrive after the response
+	mu.Lock()
+	done = true
+	mu.Unlock()
+
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeFailed, Message: err.Error()}
+		}
+		reply(nil, rpcErr)
+		return
+	}
+	if result == nil {
+		result = struct{}{}
+	}
+	reply(result, nil)
+}
+
+// DecodeParams unmarshals params into v, reporting failures as invalid params
+func DecodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 {
+		return InvalidParams("missing params")
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return InvalidParams("invalid params: %v", err)
+	}
+	return nil
+}
+
+// writer serializes messages from concurrent requests onto the connection
+type writer struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func (w *writer) write(message any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// A failed write means the client is gone; the read loop ends the connection
+	_ = w.encoder.Encode(message)
+}
@@ -0,0 +1,167 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// startConn serves a connection with s and returns the client end
+func startConn(t *testing.T, s *Server) (net.Conn, *bufio.Scanner) {
+	t.Helper()
+	client, conn := net.Pipe()
+	go func() { _ = s.ServeConn(context.Background(), conn) }()
+	t.Cleanup(func() { client.Close() })
+	return client, bufio.NewScanner(client)
+}
+
+func send(t *testing.T, conn net.Conn, message string) {
+	t.Helper()
+	if _, err := conn.Write([]byte(message + "\n")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func receive(t *testing.T, scanner *bufio.Scanner) map[string]json.RawMessage {
+	t.Helper()
+	if !scanner.Scan() {
+		t.Fatalf("connection closed: %v", scanner.Err())
+	}
+	var message map[string]json.RawMessage
+	if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+		t.Fatalf("invalid message %s: %v", scanner.Bytes(), err)
+	}
+	return message
+}
+
+func TestProgressThenResponse(t *testing.T) {
+	s := NewServer()
+	s.Register("count", func(ctx context.Context, params json.RawMessage, progress func(any)) (any, error) {
+		var p struct {
+			To int `json:"to"`
+		}
+		if err := DecodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		for i := 1; i <= p.To; i++ {
+			progress(map[string]int{"step": i})
+		}
+		return map[string]int{"total": p.To}, nil
+	})
+	conn, scanner := startConn(t, s)
+
+	send(t, conn, `{"jsonrpc": "2.0", "id": 7, "method": "count", "params": {"to": 2}}`)
+
+	for step := 1; step <= 2; step++ {
+		message := receive(t, scanner)
+		if string(message["method"]) != `"progress"` {
+			t.Fatalf("expected progress notification, got %v", message)
+		}
+		var params struct {
+			ID    int            `json:"id"`
+			Event map[string]int `json:"event"`
+		}
+		if err := json.Unmarshal(message["params"], &params); err != nil {
+			t.Fatal(err)
+		}
+		if params.ID != 7 || params.Event["step"] != step {
+			t.Errorf("unexpected progress %s", message["params"])
+		}
+	}
+
+	response := receive(t, scanner)
+	if string(response["id"]) != "7" || string(response["result"]) != `{"total":2}` {
+		t.Errorf("unexpected response %v", response)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	s := NewServer()
+	s.Register("fail", func(ctx context.Context, params json.RawMessage, progress func(any)) (any, error) {
+		return nil, errors.New("plugin not found")
+	})
+	s.Register("reject", func(ctx context.Context, params json.RawMessage, progress func(any)) (any, error) {
+		return nil, &Error{Code: -32001, Message: "blocked", Data: map[string]int{"exit_code": 4}}
+	})
+	s.Register("params", func(ctx context.Context, params json.RawMessage, progress func(any)) (any, error) {
+		var p struct{}
+		return nil, DecodeParams(params, &p)
+	})
+	conn, scanner := startConn(t, s)
+
+	tests := []struct {
+		name     string
+		request  string
+		expected string
+	}{
+		{name: "parse error", request: `{"jsonrpc": `, expected: `{"code":-32700`},
+		{name: "wrong version", request: `{"jsonrpc": "1.0", "id": 1, "method": "fail"}`, expected: `{"code":-32600`},
+		{name: "unknown method", request: `{"jsonrpc": "2.0", "id": 1, "method": "missing"}`, expected: `{"code":-32601,"message":"method \"missing\" not found"}`},
+		{name: "missing params", request: `{"jsonrpc": "2.0", "id": 1, "method": "params"}`, expected: `{"code":-32602,"message":"missing params"}`},
+		{name: "operation failed", request: `{"jsonrpc": "2.0", "id": 1, "method": "fail"}`, expected: `{"code":-32000,"message":"plugin not found"}`},
+		{name: "handler error", request: `{"jsonrpc": "2.0", "id": 1, "method": "reject"}`, expected: `{"code":-32001,"message":"blocked","data":{"exit_code":4}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			send(t, conn, tt.request)
+			response := receive(t, scanner)
+			if got := string(response["error"]); len(got) < len(tt.expected) || got[:len(tt.expected)] != tt.expected {
+				t.Errorf("expected error starting with %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNotificationsGetNoResponse(t *testing.T) {
+	called := make(chan struct{}, 1)
+	s := NewServer()
+	s.Register("ping", func(ctx context.Context, params json.RawMessage, progress func(any)) (any, error) {
+		called <- struct{}{}
+		progress("ignored")
+		return nil, nil
+	})
+	conn, scanner := startConn(t, s)
+
+	send(t, conn, `{"jsonrpc": "2.0", "method": "ping"}`)
+	<-called
+	send(t, conn, `{"jsonrpc": "2.0", "id": "b", "method": "ping"}`)
+	<-called
+
+	// Only the request reports progress and gets a response
+	progress := receive(t, scanner)
+	if string(progress["params"]) != `{"id":"b","event":"ignored"}` {
+		t.Errorf("expected progress for the request, got %v", progress)
+	}
+	response := receive(t, scanner)
+	if string(response["id"]) != `"b"` || string(response["result"]) != "{}" {
+		t.Errorf("expected response to the request, got %v", response)
+	}
+}
+
+func TestDisconnectCancelsRequests(t *testing.T) {
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	s := NewServer()
+	s.Register("wait", func(ctx context.Context, params json.RawMessage, progress func(any)) (any, error) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	})
+	conn, _ := startConn(t, s)
+
+	send(t, conn, `{"jsonrpc": "2.0", "id": 1, "method": "wait"}`)
+	<-started
+	conn.Close()
+
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected in-flight request to be cancelled when the client disconnects")
+	}
+}
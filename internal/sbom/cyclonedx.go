@@ -0,0 +1,136 @@
+// ABOUTME: CycloneDX 1.5 JSON rendering of a vault SBOM
+// ABOUTME: The vault is the root component and each plugin nests the components of its SBOM
+package sbom
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type cdxBOM struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type       string         `json:"type"`
+	BOMRef     string         `json:"bom-ref,omitempty"`
+	Name       string         `json:"name"`
+	Version    string         `json:"version,omitempty"`
+	PURL       string         `json:"purl,omitempty"`
+	Hashes     []cdxHash      `json:"hashes,omitempty"`
+	Licenses   []cdxLicense   `json:"licenses,omitempty"`
+	Properties []cdxProperty  `json:"properties,omitempty"`
+	Components []cdxComponent `json:"components,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxLicense struct {
+	Expression string `json:"expression"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+func cycloneDXDocument(doc *Document) *cdxBOM {
+	out := &cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: serialNumber(contentHash(doc)),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: doc.Created.UTC().Format(time.RFC3339),
+			Tools:     cdxTools{Components: []cdxComponent{{Type: "application", Name: "dragonglass"}}},
+			Component: cdxComponent{Type: "application", BOMRef: "vault", Name: doc.Name},
+		},
+		Components:   []cdxComponent{},
+		Dependencies: []cdxDependency{},
+	}
+
+	vault := cdxDependency{Ref: "vault", DependsOn: []string{}}
+	for _, p := range doc.Plugins {
+		pluginRef := "plugin:" + p.ID
+		vault.DependsOn = append(vault.DependsOn, pluginRef)
+
+		plugin := cdxComponent{
+			Type:    "application",
+			BOMRef:  pluginRef,
+			Name:    p.Name,
+			Version: p.Version,
+			PURL:    PluginPURL(p),
+			Properties: []cdxProperty{
+				{"dragonglass:plugin_id", p.ID},
+				{"dragonglass:oci_reference", p.OCIReference},
+				{"dragonglass:oci_digest", p.OCIDigest},
+				{"dragonglass:provenance_verified", strconv.FormatBool(p.ProvenanceVerified)},
+				{"dragonglass:sbom_attested", strconv.FormatBool(p.Components != nil)},
+			},
+		}
+		if p.BuilderID != "" {
+			plugin.Properties = append(plugin.Properties, cdxProperty{"dragonglass:builder_id", p.BuilderID})
+		}
+		if algorithm, value, ok := strings.Cut(p.OCIDigest, ":"); ok && algorithm == "sha256" {
+			plugin.Hashes = []cdxHash{{"SHA-256", value}}
+		}
+
+		dependency := cdxDependency{Ref: pluginRef, DependsOn: []string{}}
+		for _, c := range p.Components {
+			componentRef := fmt.Sprintf("%s:%s@%s", pluginRef, c.Name, c.Version)
+			component := cdxComponent{
+				Type:    "library",
+				BOMRef:  componentRef,
+				Name:    c.Name,
+				Version: c.Version,
+				PURL:    c.PURL,
+			}
+			if c.License != "" {
+				component.Licenses = []cdxLicense{{c.License}}
+			}
+			plugin.Components = append(plugin.Components, component)
+			dependency.DependsOn = append(dependency.DependsOn, componentRef)
+		}
+
+		out.Components = append(out.Components, plugin)
+		out.Dependencies = append(out.Dependencies, dependency)
+	}
+	out.Dependencies = append([]cdxDependency{vault}, out.Dependencies...)
+
+	return out
+}
+
+// serialNumber derives a stable RFC 4122 URN from a hex content hash
+func serialNumber(hash string) string {
+	b, _ := hex.DecodeString(hash[:32])
+	b[6] = b[6]&0x0f | 0x50 // version 5 (name-based)
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
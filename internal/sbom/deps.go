@@ -0,0 +1,204 @@
+// ABOUTME: Dependency graph of a plugin from the relationships in its SPDX SBOM
+// ABOUTME: Classifies each package as a direct or transitive dependency of the plugin
+package sbom
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// How a plugin depends on a package
+const (
+	RelationshipDirect     = "direct"
+	RelationshipTransitive = "transitive"
+)
+
+// spdxDependencyEdges maps the SPDX relationship types that make one package depend on
+// another to whether the edge points from the dependent to the dependency (false for
+// the inverse relationships, e.g. "A DEPENDENCY_OF B")
+var spdxDependencyEdges = map[string]bool{
+	"DEPENDS_ON":            true,
+	"CONTAINS":              true,
+	"DEPENDENCY_OF":         false,
+	"RUNTIME_DEPENDENCY_OF": false,
+	"CONTAINED_BY":          false,
+}
+
+// Dependency is a package in a plugin's dependency graph
+type Dependency struct {
+	Component
+
+	// RelationshipDirect or RelationshipTransitive; empty when the SBOM records no
+	// path from the plugin to the package
+	Relationship string
+
+	// Keys of the packages this one depends on, sorted
+	DependsOn []string
+}
+
+// Graph is the dependency graph of a plugin as recorded in its SBOM
+type Graph struct {
+	// Packages the SBOM describes, i.e. the plugin itself, by key
+	Roots []string
+
+	// Every other package, sorted by name and version
+	Dependencies []Dependency
+
+	// Whether the SBOM records dependency relationships; without them every package is
+	// listed flat and none is classified
+	Relationships bool
+}
+
+// Key identifies a component within a graph as name@version
+func (c Component) Key() string {
+	if c.Version == "" {
+		return c.Name
+	}
+	return c.Name + "@" + c.Version
+}
+
+// Direct returns the direct dependencies of the plugin
+func (g *Graph) Direct() []Dependency {
+	var direct []Dependency
+	for _, dep := range g.Dependencies {
+		if dep.Relationship == RelationshipDirect {
+			direct = append(direct, dep)
+		}
+	}
+	return direct
+}
+
+// Lookup returns the dependency with key
+func (g *Graph) Lookup(key string) (Dependency, bool) {
+	for _, dep := range g.Dependencies {
+		if dep.Key() == key {
+			return dep, true
+		}
+	}
+	return Dependency{}, false
+}
+
+// ParseSPDXGraph builds the dependency graph recorded in an SPDX SBOM predicate. The
+// packages the document describes are the plugin; packages they depend on or contain are
+// its direct dependencies, and packages reachable only through those are transitive.
+// When the document describes nothing, packages no other package depends on are taken
+// as the plugin. Packages listed more than once are merged by name and version.
+func ParseSPDXGraph(predicate map[string]any) *Graph {
+	packages, _ := predicate["packages"].([]any)
+
+	components := make(map[string]Component)
+	keyOf := make(map[string]string)
+	for _, pkg := range packages {
+		component, ok := spdxComponent(pkg)
+		if !ok {
+			continue
+		}
+		key := component.Key()
+		if _, seen := components[key]; !seen {
+			components[key] = component
+		}
+		pkgMap, _ := pkg.(map[string]any)
+		if id, _ := pkgMap["SPDXID"].(string); id != "" {
+			keyOf[id] = key
+		}
+	}
+
+	documentID, _ := predicate["SPDXID"].(string)
+	roots := make(map[string]bool)
+	described, _ := predicate["documentDescribes"].([]any)
+	for _, id := range described {
+		id, _ := id.(string)
+		if key, ok := keyOf[id]; ok {
+			roots[key] = true
+		}
+	}
+
+	edges := make(map[string]map[string]bool)
+	dependents := make(map[string]bool)
+	relationships, _ := predicate["relationships"].([]any)
+	for _, rel := range relationships {
+		relMap, _ := rel.(map[string]any)
+		relType, _ := relMap["relationshipType"].(string)
+		fromID, _ := relMap["spdxElementId"].(string)
+		toID, _ := relMap["relatedSpdxElement"].(string)
+
+		if relType == "DESCRIBES" && (documentID == "" || fromID == documentID) {
+			if key, ok := keyOf[toID]; ok {
+				roots[key] = true
+			}
+			continue
+		}
+
+		forward, ok := spdxDependencyEdges[relType]
+		if !ok {
+			continue
+		}
+		if !forward {
+			fromID, toID = toID, fromID
+		}
+		from, fromOK := keyOf[fromID]
+		to, toOK := keyOf[toID]
+		if !fromOK || !toOK || from == to {
+			continue
+		}
+		if edges[from] == nil {
+			edges[from] = make(map[string]bool)
+		}
+		edges[from][to] = true
+		dependents[to] = true
+	}
+
+	graph := &Graph{Relationships: len(edges) > 0}
+	if graph.Relationships && len(roots) == 0 {
+		for key := range edges {
+			if !dependents[key] {
+				roots[key] = true
+			}
+		}
+	}
+
+	// Walk breadth-first from the plugin so each package is classified by its shortest path
+	relationship := make(map[string]string)
+	var frontier []string
+	for root := range roots {
+		frontier = append(frontier, root)
+	}
+	for depth := 1; len(frontier) > 0; depth++ {
+		var next []string
+		for _, key := range frontier {
+			for to := range edges[key] {
+				if roots[to] || relationship[to] != "" {
+					continue
+				}
+				relationship[to] = RelationshipTransitive
+				if depth == 1 {
+					relationship[to] = RelationshipDirect
+				}
+				next = append(next, to)
+			}
+		}
+		frontier = next
+	}
+
+	for key, component := range components {
+		if roots[key] {
+			graph.Roots = append(graph.Roots, key)
+			continue
+		}
+		dep := Dependency{Component: component, Relationship: relationship[key]}
+		for to := range edges[key] {
+			dep.DependsOn = append(dep.DependsOn, to)
+		}
+		sort.Strings(dep.DependsOn)
+		graph.Dependencies = append(graph.Dependencies, dep)
+	}
+	sort.Strings(graph.Roots)
+	slices.SortFunc(graph.Dependencies, func(a, b Dependency) int {
+		if a.Name != b.Name {
+			return strings.Compare(a.Name, b.Name)
+		}
+		return strings.Compare(a.Version, b.Version)
+	})
+	return graph
+}
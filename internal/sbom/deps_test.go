@@ -0,0 +1,122 @@
+package sbom
+
+import (
+	"strings"
+	"testing"
+)
+
+// spdxPredicate returns an SPDX predicate listing packages by SPDX ID ("id" -> "name@version")
+// with the given relationships ("from TYPE to")
+func spdxPredicate(packages map[string]string, relationships ...string) map[string]any {
+	predicate := map[string]any{"SPDXID": "SPDXRef-DOCUMENT"}
+
+	var pkgs []any
+	for id, key := range packages {
+		name, version, _ := strings.Cut(key, "@")
+		pkgs = append(pkgs, map[string]any{"SPDXID": id, "name": name, "versionInfo": version, "licenseConcluded": "MIT"})
+	}
+	predicate["packages"] = pkgs
+
+	var rels []any
+	for _, rel := range relationships {
+		fields := strings.Fields(rel)
+		rels = append(rels, map[string]any{"spdxElementId": fields[0], "relationshipType": fields[1], "relatedSpdxElement": fields[2]})
+	}
+	predicate["relationships"] = rels
+	return predicate
+}
+
+func TestParseSPDXGraph(t *testing.T) {
+	packages := map[string]string{
+		"SPDXRef-plugin": "sample-plugin@1.0.0",
+		"SPDXRef-a":      "moment@2.29.4",
+		"SPDXRef-b":      "dayjs@1.11.0",
+		"SPDXRef-c":      "tslib@2.6.0",
+		"SPDXRef-c2":     "tslib@2.6.0",
+		"SPDXRef-d":      "orphan@0.1.0",
+	}
+
+	tests := []struct {
+		name          string
+		relationships []string
+		roots         string
+		relationship  map[string]string
+		dependsOn     map[string]string
+		relations     bool
+	}{
+		{
+			name: "described root",
+			relationships: []string{
+				"SPDXRef-DOCUMENT DESCRIBES SPDXRef-plugin",
+				"SPDXRef-plugin DEPENDS_ON SPDXRef-a",
+				"SPDXRef-b DEPENDENCY_OF SPDXRef-plugin",
+				"SPDXRef-a DEPENDS_ON SPDXRef-c",
+				"SPDXRef-b DEPENDS_ON SPDXRef-c2",
+				"SPDXRef-c DEPENDS_ON SPDXRef-a",
+			},
+			roots: "sample-plugin@1.0.0",
+			relationship: map[string]string{
+				"moment@2.29.4": RelationshipDirect,
+				"dayjs@1.11.0":  RelationshipDirect,
+				"tslib@2.6.0":   RelationshipTransitive,
+				"orphan@0.1.0":  "",
+			},
+			dependsOn: map[string]string{"moment@2.29.4": "tslib@2.6.0", "dayjs@1.11.0": "tslib@2.6.0", "tslib@2.6.0": "moment@2.29.4"},
+			relations: true,
+		},
+		{
+			name: "undescribed root",
+			relationships: []string{
+				"SPDXRef-plugin CONTAINS SPDXRef-a",
+				"SPDXRef-a DEPENDS_ON SPDXRef-c",
+			},
+			roots: "sample-plugin@1.0.0",
+			relationship: map[string]string{
+				"moment@2.29.4": RelationshipDirect,
+				"tslib@2.6.0":   RelationshipTransitive,
+				"dayjs@1.11.0":  "",
+			},
+			relations: true,
+		},
+		{
+			name:          "no relationships",
+			relationships: []string{"SPDXRef-DOCUMENT DESCRIBES SPDXRef-plugin"},
+			roots:         "sample-plugin@1.0.0",
+			relationship:  map[string]string{"moment@2.29.4": "", "tslib@2.6.0": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := ParseSPDXGraph(spdxPredicate(packages, tt.relationships...))
+
+			if got := strings.Join(graph.Roots, ","); got != tt.roots {
+				t.Errorf("expected roots %s, got %s", tt.roots, got)
+			}
+			if graph.Relationships != tt.relations {
+				t.Errorf("expected relationships %v, got %v", tt.relations, graph.Relationships)
+			}
+			if len(graph.Dependencies) != 4 {
+				t.Fatalf("expected 4 merged dependencies, got %+v", graph.Dependencies)
+			}
+			for key, want := range tt.relationship {
+				dep, ok := graph.Lookup(key)
+				if !ok {
+					t.Fatalf("expected %s in graph", key)
+				}
+				if dep.Relationship != want {
+					t.Errorf("%s: expected relationship %q, got %q", key, want, dep.Relationship)
+				}
+				if dep.License != "MIT" {
+					t.Errorf("%s: expected license MIT, got %q", key, dep.License)
+				}
+			}
+			for key, want := range tt.dependsOn {
+				dep, _ := graph.Lookup(key)
+				if got := strings.Join(dep.DependsOn, ","); got != want {
+					t.Errorf("%s: expected to depend on %s, got %s", key, want, got)
+				}
+			}
+		})
+	}
+}
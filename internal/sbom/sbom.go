@@ -0,0 +1,173 @@
+// ABOUTME: Vault-level SBOM combining the SBOM attestations of every installed plugin
+// ABOUTME: Renders SPDX 2.3 or CycloneDX 1.5 JSON with OCI provenance for each plugin
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	FormatSPDX      = "spdx"
+	FormatCycloneDX = "cyclonedx"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{FormatSPDX, FormatCycloneDX}
+
+// Component is a package listed in a plugin's SBOM
+type Component struct {
+	Name    string
+	Version string
+
+	// Package URL, e.g. "pkg:npm/moment@2.29.4" (optional)
+	PURL string
+
+	// SPDX license expression (optional)
+	License string
+}
+
+// Plugin is an installed plugin and the components from its SBOM attestation
+type Plugin struct {
+	ID      string
+	Name    string
+	Version string
+
+	// Pinned artifact the plugin was installed from
+	OCIReference string
+	OCIDigest    string
+
+	// Provenance recorded in the lockfile
+	BuilderID          string
+	ProvenanceVerified bool
+
+	// Components is nil when the plugin has no SBOM attestation
+	Components []Component
+}
+
+// Document is the composition of a vault, ready to be rendered
+type Document struct {
+	// Vault name, used as the document name
+	Name    string
+	Created time.Time
+	Plugins []Plugin
+}
+
+// ParseSPDXPackages extracts the components listed in an SPDX SBOM predicate, sorted and
+// without duplicates. Packages without a name are skipped.
+func ParseSPDXPackages(predicate map[string]any) []Component {
+	packages, _ := predicate["packages"].([]any)
+
+	components := make([]Component, 0, len(packages))
+	for _, pkg := range packages {
+		if component, ok := spdxComponent(pkg); ok {
+			components = append(components, component)
+		}
+	}
+
+	// The same package can be listed once per path it was bundled from; keep the first
+	sortComponents(components)
+	return slices.CompactFunc(components, func(a, b Component) bool {
+		return a.Name == b.Name && a.Version == b.Version
+	})
+}
+
+// spdxComponent reads a package of an SPDX SBOM predicate; ok is false when pkg is not
+// a package or has no name
+func spdxComponent(pkg any) (component Component, ok bool) {
+	pkgMap, _ := pkg.(map[string]any)
+	name, _ := pkgMap["name"].(string)
+	if name == "" {
+		return Component{}, false
+	}
+
+	component = Component{Name: name}
+	component.Version, _ = pkgMap["versionInfo"].(string)
+	component.License = spdxLicense(pkgMap)
+
+	refs, _ := pkgMap["externalRefs"].([]any)
+	for _, ref := range refs {
+		refMap, _ := ref.(map[string]any)
+		if refType, _ := refMap["referenceType"].(string); refType == "purl" {
+			component.PURL, _ = refMap["referenceLocator"].(string)
+			break
+		}
+	}
+	return component, true
+}
+
+// spdxLicense returns the concluded license, falling back to the declared one, ignoring
+// the NOASSERTION and NONE placeholders
+func spdxLicense(pkg map[string]any) string {
+	for _, field := range []string{"licenseConcluded", "licenseDeclared"} {
+		if license, _ := pkg[field].(string); license != "" && license != "NOASSERTION" && license != "NONE" {
+			return license
+		}
+	}
+	return ""
+}
+
+// Write renders doc in format ("spdx" or "cyclonedx") to w as indented JSON
+func Write(w io.Writer, doc *Document, format string) error {
+	plugins := append([]Plugin(nil), doc.Plugins...)
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].ID < plugins[j].ID })
+	sorted := *doc
+	sorted.Plugins = plugins
+
+	var out any
+	switch format {
+	case FormatSPDX:
+		out = spdxDocument(&sorted)
+	case FormatCycloneDX:
+		out = cycloneDXDocument(&sorted)
+	default:
+		return fmt.Errorf("unsupported SBOM format %q (must be one of %s)", format, strings.Join(Formats, ", "))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return fmt.Errorf("failed to write SBOM: %w", err)
+	}
+	return nil
+}
+
+// PluginPURL identifies a plugin artifact as an OCI package URL
+func PluginPURL(p Plugin) string {
+	repository := p.OCIReference
+	if i := strings.LastIndex(repository, "@"); i >= 0 {
+		repository = repository[:i]
+	}
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+
+	name := repository[strings.LastIndex(repository, "/")+1:]
+	return fmt.Sprintf("pkg:oci/%s@%s?repository_url=%s", name, strings.Replace(p.OCIDigest, ":", "%3A", 1), repository)
+}
+
+// contentHash identifies the set of pinned plugins, so the same vault composition always
+// yields the same document identifiers
+func contentHash(doc *Document) string {
+	h := sha256.New()
+	for _, p := range doc.Plugins {
+		fmt.Fprintf(h, "%s@%s\n", p.ID, p.OCIDigest)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func sortComponents(components []Component) {
+	sort.SliceStable(components, func(i, j int) bool {
+		if components[i].Name != components[j].Name {
+			return components[i].Name < components[j].Name
+		}
+		return components[i].Version < components[j].Version
+	})
+}
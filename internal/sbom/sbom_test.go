@@ -0,0 +1,168 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSPDXPackages(t *testing.T) {
+	predicate := map[string]any{
+		"packages": []any{
+			map[string]any{
+				"name":             "moment",
+				"versionInfo":      "2.29.4",
+				"licenseConcluded": "NOASSERTION",
+				"licenseDeclared":  "MIT",
+				"externalRefs": []any{
+					map[string]any{"referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:momentjs:moment"},
+					map[string]any{"referenceType": "purl", "referenceLocator": "pkg:npm/moment@2.29.4"},
+				},
+			},
+			map[string]any{"name": "dayjs", "versionInfo": "1.11.0", "licenseConcluded": "MIT"},
+			map[string]any{"name": "moment", "versionInfo": "2.29.4"},
+			map[string]any{"versionInfo": "1.0.0"},
+			"not a package",
+		},
+	}
+
+	got := ParseSPDXPackages(predicate)
+	expected := []Component{
+		{Name: "dayjs", Version: "1.11.0", License: "MIT"},
+		{Name: "moment", Version: "2.29.4", PURL: "pkg:npm/moment@2.29.4", License: "MIT"},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("component %d: expected %+v, got %+v", i, expected[i], got[i])
+		}
+	}
+
+	if got := ParseSPDXPackages(map[string]any{}); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty component list for an SBOM without packages, got %v", got)
+	}
+}
+
+func TestPluginPURL(t *testing.T) {
+	tests := []struct {
+		reference string
+		expected  string
+	}{
+		{"ghcr.io/owner/repo/tasknotes:3.23.0", "pkg:oci/tasknotes@sha256%3Aabc?repository_url=ghcr.io/owner/repo/tasknotes"},
+		{"ghcr.io/owner/repo/tasknotes@sha256:abc", "pkg:oci/tasknotes@sha256%3Aabc?repository_url=ghcr.io/owner/repo/tasknotes"},
+		{"localhost:5000/tasknotes", "pkg:oci/tasknotes@sha256%3Aabc?repository_url=localhost:5000/tasknotes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			if got := PluginPURL(Plugin{OCIReference: tt.reference, OCIDigest: "sha256:abc"}); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func testDocument() *Document {
+	return &Document{
+		Name:    "notes",
+		Created: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Plugins: []Plugin{
+			{
+				ID: "tasknotes", Name: "TaskNotes", Version: "3.23.0",
+				OCIReference: "ghcr.io/owner/repo/tasknotes:3.23.0", OCIDigest: "sha256:abc",
+				BuilderID: "https://github.com/owner/repo/.github/workflows/build.yml@refs/heads/main", ProvenanceVerified: true,
+				Components: []Component{{Name: "moment", Version: "2.29.4", PURL: "pkg:npm/moment@2.29.4", License: "MIT"}},
+			},
+			{
+				ID: "calendar", Name: "Calendar", Version: "1.5.10",
+				OCIReference: "ghcr.io/owner/repo/calendar:1.5.10", OCIDigest: "sha256:def",
+			},
+		},
+	}
+}
+
+func TestWriteSPDX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testDocument(), FormatSPDX); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc spdxDoc
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if doc.SPDXVersion != "SPDX-2.3" || doc.CreationInfo.Created != "2025-01-02T03:04:05Z" {
+		t.Errorf("unexpected document header: %+v", doc)
+	}
+	if strings.Join(doc.DocumentDescribes, ",") != "SPDXRef-Plugin-calendar,SPDXRef-Plugin-tasknotes" {
+		t.Errorf("expected plugins described in ID order, got %v", doc.DocumentDescribes)
+	}
+	if len(doc.Packages) != 3 {
+		t.Fatalf("expected 2 plugins and 1 component, got %d packages", len(doc.Packages))
+	}
+
+	tasknotes := doc.Packages[1]
+	if tasknotes.Checksums[0].ChecksumValue != "abc" || !strings.Contains(tasknotes.Comment, "built by https://github.com/owner/repo") {
+		t.Errorf("expected plugin provenance on package, got %+v", tasknotes)
+	}
+	if !strings.Contains(doc.Packages[0].Comment, "No SBOM attestation") {
+		t.Errorf("expected missing SBOM to be noted, got %q", doc.Packages[0].Comment)
+	}
+
+	contains := spdxRelationship{"SPDXRef-Plugin-tasknotes", "CONTAINS", "SPDXRef-Package-tasknotes-1"}
+	found := false
+	for _, r := range doc.Relationships {
+		found = found || r == contains
+	}
+	if !found {
+		t.Errorf("expected %+v in relationships %+v", contains, doc.Relationships)
+	}
+}
+
+func TestWriteCycloneDX(t *testing.T) {
+	var first, second bytes.Buffer
+	if err := Write(&first, testDocument(), FormatCycloneDX); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Write(&second, testDocument(), FormatCycloneDX); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.String() != second.String() {
+		t.Error("expected identical output for the same vault")
+	}
+
+	var bom cdxBOM
+	if err := json.Unmarshal(first.Bytes(), &bom); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if bom.BOMFormat != "CycloneDX" || bom.SpecVersion != "1.5" || !strings.HasPrefix(bom.SerialNumber, "urn:uuid:") || len(bom.SerialNumber) != 45 {
+		t.Errorf("unexpected BOM header: %s %s %s", bom.BOMFormat, bom.SpecVersion, bom.SerialNumber)
+	}
+	if len(bom.Components) != 2 || bom.Components[1].BOMRef != "plugin:tasknotes" {
+		t.Fatalf("expected plugins in ID order, got %+v", bom.Components)
+	}
+
+	tasknotes := bom.Components[1]
+	if tasknotes.PURL != "pkg:oci/tasknotes@sha256%3Aabc?repository_url=ghcr.io/owner/repo/tasknotes" {
+		t.Errorf("unexpected plugin purl %s", tasknotes.PURL)
+	}
+	if len(tasknotes.Components) != 1 || tasknotes.Components[0].Licenses[0].Expression != "MIT" {
+		t.Errorf("expected nested SBOM component with license, got %+v", tasknotes.Components)
+	}
+	if bom.Dependencies[0].Ref != "vault" || len(bom.Dependencies[0].DependsOn) != 2 {
+		t.Errorf("expected vault to depend on every plugin, got %+v", bom.Dependencies[0])
+	}
+}
+
+func TestWriteUnsupportedFormat(t *testing.T) {
+	err := Write(&bytes.Buffer{}, testDocument(), "swid")
+	if err == nil || !strings.Contains(err.Error(), "must be one of spdx, cyclonedx") {
+		t.Errorf("expected unsupported format error, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+// ABOUTME: SPDX 2.3 JSON rendering of a vault SBOM
+// ABOUTME: Each plugin is a package described by the document and containing its components
+package sbom
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type spdxDoc struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	DocumentDescribes []string           `json:"documentDescribes"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	Checksums        []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+	Comment          string            `json:"comment,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+func spdxDocument(doc *Document) *spdxDoc {
+	out := &spdxDoc{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              doc.Name,
+		DocumentNamespace: fmt.Sprintf("https://github.com/gillisandrew/dragonglass-poc/spdx/%s-%s", doc.Name, contentHash(doc)),
+		CreationInfo: spdxCreationInfo{
+			Created:  doc.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: dragonglass"},
+		},
+		DocumentDescribes: []string{},
+		Packages:          []spdxPackage{},
+		Relationships:     []spdxRelationship{},
+	}
+
+	for _, p := range doc.Plugins {
+		pluginRef := "SPDXRef-Plugin-" + p.ID
+		out.DocumentDescribes = append(out.DocumentDescribes, pluginRef)
+		out.Relationships = append(out.Relationships, spdxRelationship{"SPDXRef-DOCUMENT", "DESCRIBES", pluginRef})
+
+		pkg := spdxPackage{
+			SPDXID:           pluginRef,
+			Name:             p.Name,
+			VersionInfo:      p.Version,
+			DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION",
+			ExternalRefs:     []spdxExternalRef{{"PACKAGE-MANAGER", "purl", PluginPURL(p)}},
+			Comment:          provenanceComment(p),
+		}
+		if algorithm, value, ok := strings.Cut(p.OCIDigest, ":"); ok && algorithm == "sha256" {
+			pkg.Checksums = []spdxChecksum{{"SHA256", value}}
+		}
+		out.Packages = append(out.Packages, pkg)
+
+		for i, c := range p.Components {
+			componentRef := fmt.Sprintf("SPDXRef-Package-%s-%d", p.ID, i+1)
+			component := spdxPackage{
+				SPDXID:           componentRef,
+				Name:             c.Name,
+				VersionInfo:      c.Version,
+				DownloadLocation: "NOASSERTION",
+				LicenseConcluded: "NOASSERTION",
+			}
+			if c.License != "" {
+				component.LicenseConcluded = c.License
+			}
+			if c.PURL != "" {
+				component.ExternalRefs = []spdxExternalRef{{"PACKAGE-MANAGER", "purl", c.PURL}}
+			}
+			out.Packages = append(out.Packages, component)
+			out.Relationships = append(out.Relationships, spdxRelationship{pluginRef, "CONTAINS", componentRef})
+		}
+	}
+
+	return out
+}
+
+// provenanceComment records where the plugin came from and how it was verified
+func provenanceComment(p Plugin) string {
+	comment := fmt.Sprintf("Installed from %s@%s.", p.OCIReference, p.OCIDigest)
+	switch {
+	case p.ProvenanceVerified && p.BuilderID != "":
+		comment += " SLSA provenance verified, built by " + p.BuilderID + "."
+	case p.ProvenanceVerified:
+		comment += " SLSA provenance verified."
+	default:
+		comment += " SLSA provenance not verified."
+	}
+	if p.Components == nil {
+		comment += " No SBOM attestation."
+	}
+	return comment
+}
@@ -0,0 +1,373 @@
+// ABOUTME: JSON Schema definitions for dragonglass config and lockfile documents
+// ABOUTME: Validates documents and reports the offending field with its line and column
+package schema
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed config.schema.json
+var configSchemaJSON []byte
+
+//go:embed lockfile.schema.json
+var lockfileSchemaJSON []byte
+
+var (
+	// Config validates dragonglass-config.json and the user config file
+	Config = MustCompile(configSchemaJSON)
+
+	// Lockfile validates dragonglass-lock.json
+	Lockfile = MustCompile(lockfileSchemaJSON)
+)
+
+// Schema is the subset of JSON Schema (draft-07) used by dragonglass documents:
+// type, properties, required, additionalProperties, items, enum, minLength, and the
+// date-time format.
+type Schema struct {
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Additional        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	Format               string             `json:"format,omitempty"`
+
+	raw []byte
+}
+
+// Additional models additionalProperties, which is either a boolean or a schema
+type Additional struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+func (a *Additional) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.Allowed); err == nil {
+		return nil
+	}
+	a.Allowed = true
+	return json.Unmarshal(data, &a.Schema)
+}
+
+// Compile parses a schema document
+func Compile(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	s.raw = data
+	return &s, nil
+}
+
+// MustCompile is like Compile but panics on error; used for the embedded schemas
+func MustCompile(data []byte) *Schema {
+	s, err := Compile(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// JSON returns the schema document as it was compiled
+func (s *Schema) JSON() []byte {
+	return s.raw
+}
+
+// FieldError describes a single schema violation
+type FieldError struct {
+	// JSON pointer to the offending value, e.g. "/output/format" (empty for the document root)
+	Pointer string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	message := e.Message
+	if e.Pointer != "" {
+		message = e.Pointer + ": " + message
+	}
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, message)
+	}
+	return message
+}
+
+// ValidationError collects every violation found in a document
+type ValidationError struct {
+	File   string
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fieldErr := range e.Errors {
+		messages[i] = fieldErr.Error()
+	}
+	prefix := "validation failed"
+	if e.File != "" {
+		prefix = e.File
+	}
+	return fmt.Sprintf("%s: %s", prefix, strings.Join(messages, "; "))
+}
+
+// Validate checks a JSON document against the schema. file is only used in error
+// messages. Syntax errors and schema violations are reported with line and column.
+func (s *Schema) Validate(data []byte, file string) error {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			// Offset points just past the offending character
+			line, column := position(data, max(syntaxErr.Offset-1, 0))
+			return &ValidationError{File: file, Errors: []*FieldError{{Line: line, Column: column, Message: syntaxErr.Error()}}}
+		}
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	var violations []*FieldError
+	s.validate(doc, "", &violations)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	offsets := valueOffsets(data)
+	for _, v := range violations {
+		if offset, ok := offsets[v.Pointer]; ok {
+			v.Line, v.Column = position(data, offset)
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Line != violations[j].Line {
+			return violations[i].Line < violations[j].Line
+		}
+		return violations[i].Column < violations[j].Column
+	})
+
+	return &ValidationError{File: file, Errors: violations}
+}
+
+func (s *Schema) validate(value any, pointer string, violations *[]*FieldError) {
+	fail := func(format string, args ...any) {
+		*violations = append(*violations, &FieldError{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Type != "" && !matchesType(s.Type, value) {
+		fail("expected %s, got %s", s.Type, typeName(value))
+		return
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, allowed := range s.Enum {
+			if allowed == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			options := make([]string, len(s.Enum))
+			for i, allowed := range s.Enum {
+				options[i] = fmt.Sprintf("%v", allowed)
+			}
+			fail("value %v is not one of: %s", formatValue(value), strings.Join(options, ", "))
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		if s.MinLength != nil && len(v) < *s.MinLength {
+			if *s.MinLength == 1 {
+				fail("must not be empty")
+			} else {
+				fail("must be at least %d characters", *s.MinLength)
+			}
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, v); err != nil {
+				fail("value %q is not an RFC 3339 date-time", v)
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(item, pointer+"/"+strconv.Itoa(i), violations)
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				fail("missing required field %q", name)
+			}
+		}
+
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			child := pointer + "/" + escapePointer(name)
+			if prop, ok := s.Properties[name]; ok {
+				prop.validate(v[name], child, violations)
+				continue
+			}
+			if s.AdditionalProperties == nil {
+				continue
+			}
+			if !s.AdditionalProperties.Allowed {
+				*violations = append(*violations, &FieldError{Pointer: child, Message: fmt.Sprintf("unknown field %q", name)})
+				continue
+			}
+			if s.AdditionalProperties.Schema != nil {
+				s.AdditionalProperties.Schema.validate(v[name], child, violations)
+			}
+		}
+	}
+}
+
+func matchesType(schemaType string, value any) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "null":
+		return value == nil
+	default:
+		return true
+	}
+}
+
+func typeName(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func formatValue(value any) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+// valueOffsets maps the JSON pointer of every value in data to its byte offset
+func valueOffsets(data []byte) map[string]int64 {
+	offsets := make(map[string]int64)
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	var walk func(pointer string) error
+	walk = func(pointer string) error {
+		start := skipSeparators(data, dec.InputOffset())
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		offsets[pointer] = start
+
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key, _ := keyTok.(string)
+				if err := walk(pointer + "/" + escapePointer(key)); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+			return err
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if err := walk(pointer + "/" + strconv.Itoa(i)); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+			return err
+		}
+		return nil
+	}
+
+	// Offsets are best effort; a partially walked document still locates earlier fields
+	_ = walk("")
+	return offsets
+}
+
+// skipSeparators advances past whitespace and the ':' or ',' preceding a value
+func skipSeparators(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) {
+		switch data[offset] {
+		case ' ', '\t', '\r', '\n', ':', ',':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// position converts a byte offset into a 1-based line and column
+func position(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	line, column := 1, 1
+	for _, b := range data[:offset] {
+		if b == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
@@ -0,0 +1,123 @@
+package schema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestConfigSchema(t *testing.T) {
+	tests := []struct {
+		name        string
+		document    string
+		expectError []string
+	}{
+		{
+			name:     "valid config",
+			document: `{"version": "1", "output": {"format": "json"}, "registry": {"mirrors": {"ghcr.io": "mirror.example.com"}}}`,
+		},
+		{
+			name:        "missing version",
+			document:    `{"output": {"format": "text"}}`,
+			expectError: []string{`line 1, column 1: missing required field "version"`},
+		},
+		{
+			name: "wrong type and unknown field are both reported",
+			document: `{
+  "version": "1",
+  "verification": {
+    "strict_mode": "yes",
+    "strict": true
+  }
+}`,
+			expectError: []string{
+				`line 4, column 20: /verification/strict_mode: expected boolean, got string`,
+				`line 5, column 15: /verification/strict: unknown field "strict"`,
+			},
+		},
+		{
+			name:        "value outside enum",
+			document:    `{"version": "1", "output": {"format": "xml"}}`,
+			expectError: []string{`/output/format: value "xml" is not one of: text, json, yaml`},
+		},
+		{
+			name:        "mirror values must be strings",
+			document:    `{"version": "1", "registry": {"mirrors": {"ghcr.io": 1}}}`,
+			expectError: []string{`/registry/mirrors/ghcr.io: expected string, got number`},
+		},
+		{
+			name:        "syntax error reports position",
+			document:    "{\n  \"version\": \"1\",\n}",
+			expectError: []string{"line 3, column 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Config.Validate([]byte(tt.document), "dragonglass-config.json")
+			if len(tt.expectError) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if len(validationErr.Errors) != len(tt.expectError) {
+				t.Errorf("expected %d violations, got %d: %v", len(tt.expectError), len(validationErr.Errors), err)
+			}
+			for _, expected := range tt.expectError {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected error containing %q, got %v", expected, err)
+				}
+			}
+			if !strings.HasPrefix(err.Error(), "dragonglass-config.json: ") {
+				t.Errorf("expected error to name the file, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLockfileSchema(t *testing.T) {
+	valid := `{
+  "version": "1",
+  "generated_at": "2025-01-01T00:00:00Z",
+  "updated_at": "2025-01-01T00:00:00Z",
+  "plugins": {
+    "tasknotes": {
+      "name": "TaskNotes",
+      "version": "3.23.4",
+      "oci_reference": "ghcr.io/owner/repo/tasknotes:v3.23.4",
+      "oci_digest": "sha256:abc",
+      "verification_state": {"provenance_verified": true, "sbom_verified": true, "vuln_scan_passed": false}
+    }
+  },
+  "metadata": {"vault_path": "/vault", "dragonglass_version": "dev", "schema_version": "1"}
+}`
+	if err := Lockfile.Validate([]byte(valid), "dragonglass-lock.json"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	invalid := `{
+  "version": "1",
+  "generated_at": "yesterday",
+  "plugins": {
+    "tasknotes": {"name": "TaskNotes", "oci_reference": "ghcr.io/owner/repo/tasknotes:v3.23.4"}
+  }
+}`
+	err := Lockfile.Validate([]byte(invalid), "dragonglass-lock.json")
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, expected := range []string{
+		`line 3, column 19: /generated_at: value "yesterday" is not an RFC 3339 date-time`,
+		`line 5, column 18: /plugins/tasknotes: missing required field "oci_digest"`,
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing %q, got %v", expected, err)
+		}
+	}
+}
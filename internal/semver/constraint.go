@@ -0,0 +1,340 @@
+// ABOUTME: npm-style version range constraints (^, ~, x-ranges, comparators, hyphen ranges)
+// ABOUTME: Supports "||" alternatives and space-separated intersections of comparators
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type operator string
+
+const (
+	opEQ  operator = "="
+	opGT  operator = ">"
+	opGTE operator = ">="
+	opLT  operator = "<"
+	opLTE operator = "<="
+)
+
+// comparator is a single bound such as ">=1.2.0"
+type comparator struct {
+	op      operator
+	version *Version
+}
+
+func (c comparator) matches(v *Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case opEQ:
+		return cmp == 0
+	case opGT:
+		return cmp > 0
+	case opGTE:
+		return cmp >= 0
+	case opLT:
+		return cmp < 0
+	case opLTE:
+		return cmp <= 0
+	}
+	return false
+}
+
+// Constraint is a parsed version range. A version satisfies the constraint when it
+// satisfies every comparator of at least one alternative.
+type Constraint struct {
+	alternatives [][]comparator
+	original     string
+}
+
+// ParseConstraint parses an npm-style range such as "^1.2.0", "~1.4", ">=1.0.0 <2.0.0",
+// "1.2.x", "1.0.0 - 1.5.0", or "^1.0.0 || ^2.0.0". An empty string or "*" matches any release.
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{original: s}
+
+	for _, alt := range strings.Split(s, "||") {
+		comparators, err := parseAlternative(strings.TrimSpace(alt))
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q: %w", s, err)
+		}
+		c.alternatives = append(c.alternatives, comparators)
+	}
+
+	return c, nil
+}
+
+// String returns the constraint as originally written
+func (c *Constraint) String() string {
+	return c.original
+}
+
+// Check reports whether the version satisfies the constraint. Prerelease versions only
+// match when an alternative explicitly names a prerelease of the same major.minor.patch.
+func (c *Constraint) Check(v *Version) bool {
+	for _, alt := range c.alternatives {
+		if matchesAll(alt, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckString parses the version and checks it against the constraint
+func (c *Constraint) CheckString(version string) bool {
+	v, err := Parse(version)
+	if err != nil {
+		return false
+	}
+	return c.Check(v)
+}
+
+// MaxSatisfying returns the highest version in the list that satisfies the constraint,
+// or nil when none do
+func (c *Constraint) MaxSatisfying(versions []*Version) *Version {
+	var best *Version
+	for _, v := range versions {
+		if c.Check(v) && (best == nil || best.LessThan(v)) {
+			best = v
+		}
+	}
+	return best
+}
+
+// Satisfying returns all versions that satisfy the constraint ordered from highest to lowest
+func (c *Constraint) Satisfying(versions []*Version) []*Version {
+	matches := make([]*Version, 0, len(versions))
+	for _, v := range versions {
+		if c.Check(v) {
+			matches = append(matches, v)
+		}
+	}
+	Sort(matches)
+	for i, j := 0, len(matches)-1; i < j; i, j = i+1, j-1 {
+		matches[i], matches[j] = matches[j], matches[i]
+	}
+	return matches
+}
+
+func matchesAll(comparators []comparator, v *Version) bool {
+	for _, cmp := range comparators {
+		if !cmp.matches(v) {
+			return false
+		}
+	}
+
+	if v.Prerelease == "" {
+		return true
+	}
+
+	// Prereleases are opt-in per version tuple, matching npm behaviour
+	for _, cmp := range comparators {
+		if cmp.version.Prerelease != "" &&
+			cmp.version.Major == v.Major &&
+			cmp.version.Minor == v.Minor &&
+			cmp.version.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+func parseAlternative(s string) ([]comparator, error) {
+	if s == "" || s == "*" || s == "x" || s == "X" {
+		return []comparator{{op: opGTE, version: &Version{}}}, nil
+	}
+
+	// Hyphen range: "1.2.3 - 2.3.4"
+	if parts := strings.Split(s, " - "); len(parts) == 2 {
+		lower, err := parsePartial(strings.TrimSpace(parts[0]))
+		if err != nil {
+			return nil, err
+		}
+		upper, err := parsePartial(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, err
+		}
+		comparators := []comparator{{op: opGTE, version: lower.floor()}}
+		if upper.complete() {
+			comparators = append(comparators, comparator{op: opLTE, version: upper.floor()})
+		} else if ceil := upper.ceiling(); ceil != nil {
+			comparators = append(comparators, comparator{op: opLT, version: ceil})
+		}
+		return comparators, nil
+	}
+
+	var comparators []comparator
+	for _, field := range strings.Fields(normalizeOperators(s)) {
+		parsed, err := parseComparator(field)
+		if err != nil {
+			return nil, err
+		}
+		comparators = append(comparators, parsed...)
+	}
+	return comparators, nil
+}
+
+// normalizeOperators removes whitespace between an operator and its version (">= 1.0.0")
+func normalizeOperators(s string) string {
+	for _, op := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		s = strings.ReplaceAll(s, op+" ", op)
+	}
+	return s
+}
+
+func parseComparator(s string) ([]comparator, error) {
+	switch {
+	case strings.HasPrefix(s, "^"):
+		p, err := parsePartial(s[1:])
+		if err != nil {
+			return nil, err
+		}
+		return caretRange(p), nil
+	case strings.HasPrefix(s, "~"):
+		p, err := parsePartial(strings.TrimPrefix(s[1:], ">"))
+		if err != nil {
+			return nil, err
+		}
+		return tildeRange(p), nil
+	}
+
+	op := opEQ
+	for _, candidate := range []operator{opGTE, opLTE, opGT, opLT, opEQ} {
+		if strings.HasPrefix(s, string(candidate)) {
+			op = candidate
+			s = s[len(candidate):]
+			break
+		}
+	}
+
+	p, err := parsePartial(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.complete() {
+		return []comparator{{op: op, version: p.floor()}}, nil
+	}
+
+	// Partial versions expand to ranges, e.g. "1.2" == ">=1.2.0 <1.3.0"
+	switch op {
+	case opEQ:
+		comparators := []comparator{{op: opGTE, version: p.floor()}}
+		if ceil := p.ceiling(); ceil != nil {
+			comparators = append(comparators, comparator{op: opLT, version: ceil})
+		}
+		return comparators, nil
+	case opGT:
+		ceil := p.ceiling()
+		if ceil == nil {
+			return []comparator{{op: opLT, version: &Version{}}}, nil // nothing is greater than "*"
+		}
+		return []comparator{{op: opGTE, version: ceil}}, nil
+	case opGTE:
+		return []comparator{{op: opGTE, version: p.floor()}}, nil
+	case opLT:
+		return []comparator{{op: opLT, version: p.floor()}}, nil
+	case opLTE:
+		ceil := p.ceiling()
+		if ceil == nil {
+			return []comparator{{op: opGTE, version: &Version{}}}, nil
+		}
+		return []comparator{{op: opLT, version: ceil}}, nil
+	}
+	return nil, fmt.Errorf("unsupported operator %q", op)
+}
+
+func caretRange(p *partial) []comparator {
+	lower := comparator{op: opGTE, version: p.floor()}
+	var upper *Version
+	switch {
+	case p.parts == 0:
+		return []comparator{lower}
+	case p.major > 0 || p.parts == 1:
+		upper = &Version{Major: p.major + 1}
+	case p.minor > 0 || p.parts == 2:
+		upper = &Version{Major: p.major, Minor: p.minor + 1}
+	default:
+		upper = &Version{Major: p.major, Minor: p.minor, Patch: p.patch + 1}
+	}
+	return []comparator{lower, {op: opLT, version: upper}}
+}
+
+func tildeRange(p *partial) []comparator {
+	lower := comparator{op: opGTE, version: p.floor()}
+	switch p.parts {
+	case 0:
+		return []comparator{lower}
+	case 1:
+		return []comparator{lower, {op: opLT, version: &Version{Major: p.major + 1}}}
+	default:
+		return []comparator{lower, {op: opLT, version: &Version{Major: p.major, Minor: p.minor + 1}}}
+	}
+}
+
+// partial is a version where trailing components may be omitted or wildcards
+type partial struct {
+	major, minor, patch uint64
+	prerelease          string
+	parts               int // number of concrete numeric components (0-3)
+}
+
+func parsePartial(s string) (*partial, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if s == "" {
+		return nil, fmt.Errorf("missing version")
+	}
+
+	p := &partial{}
+	if idx := strings.Index(s, "+"); idx >= 0 {
+		s = s[:idx]
+	}
+	if idx := strings.Index(s, "-"); idx >= 0 {
+		p.prerelease = s[idx+1:]
+		s = s[:idx]
+	}
+
+	fields := strings.Split(s, ".")
+	if len(fields) > 3 {
+		return nil, fmt.Errorf("invalid version %q", s)
+	}
+
+	values := []*uint64{&p.major, &p.minor, &p.patch}
+	for i, field := range fields {
+		if field == "*" || field == "x" || field == "X" {
+			break
+		}
+		n, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q: %w", s, err)
+		}
+		*values[i] = n
+		p.parts = i + 1
+	}
+
+	if p.prerelease != "" && p.parts < 3 {
+		return nil, fmt.Errorf("invalid version %q: prerelease requires major.minor.patch", s)
+	}
+
+	return p, nil
+}
+
+func (p *partial) complete() bool {
+	return p.parts == 3
+}
+
+// floor returns the lowest version matched by the partial
+func (p *partial) floor() *Version {
+	return &Version{Major: p.major, Minor: p.minor, Patch: p.patch, Prerelease: p.prerelease}
+}
+
+// ceiling returns the exclusive upper bound of the partial, or nil for a full wildcard
+func (p *partial) ceiling() *Version {
+	switch p.parts {
+	case 1:
+		return &Version{Major: p.major + 1}
+	case 2:
+		return &Version{Major: p.major, Minor: p.minor + 1}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+// ABOUTME: Semantic version parsing, comparison, and npm-style range constraints
+// ABOUTME: Used to resolve declared plugin version ranges against published registry tags
+package semver
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version represents a parsed semantic version (major.minor.patch[-prerelease][+build])
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease string
+	Build      string
+	original   string
+}
+
+// Parse parses a semantic version string, accepting an optional leading "v"
+func Parse(s string) (*Version, error) {
+	original := s
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if s == "" {
+		return nil, fmt.Errorf("empty version")
+	}
+
+	v := &Version{original: original}
+
+	if idx := strings.Index(s, "+"); idx >= 0 {
+		v.Build = s[idx+1:]
+		s = s[:idx]
+	}
+	if idx := strings.Index(s, "-"); idx >= 0 {
+		v.Prerelease = s[idx+1:]
+		s = s[:idx]
+		if v.Prerelease == "" {
+			return nil, fmt.Errorf("invalid version %q: empty prerelease", original)
+		}
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid version %q: expected major.minor.patch", original)
+	}
+
+	nums := make([]uint64, 3)
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q: %w", original, err)
+		}
+		nums[i] = n
+	}
+	v.Major, v.Minor, v.Patch = nums[0], nums[1], nums[2]
+
+	return v, nil
+}
+
+// MustParse parses a version and panics on error (for constants and tests)
+func MustParse(s string) *Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// String returns the canonical version string without a leading "v"
+func (v *Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Original returns the string the version was parsed from
+func (v *Version) Original() string {
+	return v.original
+}
+
+// Compare returns -1, 0, or 1 depending on whether v is less than, equal to, or greater than o.
+// Build metadata is ignored, as required by the semver specification.
+func (v *Version) Compare(o *Version) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+// LessThan reports whether v sorts before o
+func (v *Version) LessThan(o *Version) bool {
+	return v.Compare(o) < 0
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// comparePrerelease orders prerelease identifiers; a release sorts after any prerelease
+func comparePrerelease(a, b string) int {
+	if a == b {
+		return 0
+	}
+	if a == "" {
+		return 1
+	}
+	if b == "" {
+		return -1
+	}
+
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.ParseUint(as[i], 10, 64)
+		bn, bErr := strconv.ParseUint(bs[i], 10, 64)
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareUint(an, bn); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			return -1 // numeric identifiers sort before alphanumeric ones
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareUint(uint64(len(as)), uint64(len(bs)))
+}
+
+// Sort orders versions from lowest to highest
+func Sort(versions []*Version) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].LessThan(versions[j])
+	})
+}
@@ -0,0 +1,163 @@
+package semver
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectError bool
+	}{
+		{input: "1.2.3", expected: "1.2.3"},
+		{input: "v1.2.3", expected: "1.2.3"},
+		{input: "1.0.0-beta.1", expected: "1.0.0-beta.1"},
+		{input: "1.0.0+build.5", expected: "1.0.0+build.5"},
+		{input: "1.2", expectError: true},
+		{input: "1.2.x", expectError: true},
+		{input: "", expectError: true},
+		{input: "1.0.0-", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, err := Parse(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error parsing %q", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if v.String() != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, v.String())
+			}
+			if v.Original() != tt.input {
+				t.Errorf("expected original %s, got %s", tt.input, v.Original())
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0", "2.0.0", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0-beta.11", 1},
+		{"1.0.0+build.1", "1.0.0+build.2", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			got := MustParse(tt.a).Compare(MustParse(tt.b))
+			if got != tt.expected {
+				t.Errorf("Compare(%s, %s) = %d; expected %d", tt.a, tt.b, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConstraintCheck(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{"^1.2.0", "1.2.0", true},
+		{"^1.2.0", "1.9.9", true},
+		{"^1.2.0", "2.0.0", false},
+		{"^1.2.0", "1.1.9", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.4", false},
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"~1", "1.9.0", true},
+		{"1.2.x", "1.2.7", true},
+		{"1.2.x", "1.3.0", false},
+		{"1.x", "1.99.0", true},
+		{"*", "3.4.5", true},
+		{"", "0.0.1", true},
+		{"1.2.3", "1.2.3", true},
+		{"=1.2.3", "1.2.4", false},
+		{">=1.0.0 <2.0.0", "1.5.0", true},
+		{">=1.0.0 <2.0.0", "2.0.0", false},
+		{">= 1.0.0", "1.0.0", true},
+		{">1.2", "1.3.0", true},
+		{">1.2", "1.2.9", false},
+		{"<=1.2", "1.2.9", true},
+		{"<=1.2", "1.3.0", false},
+		{"1.0.0 - 1.5.0", "1.5.0", true},
+		{"1.0.0 - 1.5", "1.5.9", true},
+		{"1.0.0 - 1.5", "1.6.0", false},
+		{"^1.0.0 || ^3.0.0", "3.1.0", true},
+		{"^1.0.0 || ^3.0.0", "2.1.0", false},
+		{"^1.0.0", "1.5.0-beta.1", false},
+		{"^1.5.0-beta.1", "1.5.0-beta.2", true},
+		{"^1.5.0-beta.1", "1.6.0-beta.1", false},
+		{"*", "1.0.0-rc.1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+"_"+tt.version, func(t *testing.T) {
+			c, err := ParseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("failed to parse constraint: %v", err)
+			}
+			if got := c.CheckString(tt.version); got != tt.expected {
+				t.Errorf("%q.Check(%s) = %t; expected %t", tt.constraint, tt.version, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseConstraintErrors(t *testing.T) {
+	for _, input := range []string{"^abc", ">=1.2.3.4", "~", "1.x-beta"} {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseConstraint(input); err == nil {
+				t.Errorf("expected error parsing constraint %q", input)
+			}
+		})
+	}
+}
+
+func TestMaxSatisfying(t *testing.T) {
+	versions := []*Version{
+		MustParse("1.0.0"),
+		MustParse("1.4.2"),
+		MustParse("1.10.0"),
+		MustParse("2.0.0"),
+		MustParse("2.1.0-beta.1"),
+	}
+
+	c, err := ParseConstraint("^1.2.0")
+	if err != nil {
+		t.Fatalf("failed to parse constraint: %v", err)
+	}
+
+	best := c.MaxSatisfying(versions)
+	if best == nil || best.String() != "1.10.0" {
+		t.Errorf("expected 1.10.0, got %v", best)
+	}
+
+	matches := c.Satisfying(versions)
+	if len(matches) != 2 || matches[0].String() != "1.10.0" || matches[1].String() != "1.4.2" {
+		t.Errorf("unexpected satisfying versions: %v", matches)
+	}
+
+	none, _ := ParseConstraint("^3.0.0")
+	if got := none.MaxSatisfying(versions); got != nil {
+		t.Errorf("expected no match, got %s", got)
+	}
+}
@@ -0,0 +1,323 @@
+// ABOUTME: Read-only HTTP API exposing plugin verification and the vault lockfile
+// ABOUTME: Lets thin clients rely on a central verifier instead of running Sigstore locally
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/metrics"
+)
+
+const (
+	DefaultCacheTTL    = 5 * time.Minute
+	DefaultConcurrency = 4
+)
+
+// VerifyFunc verifies an OCI reference against the server's policy. The response
+// describes how far verification got and is returned alongside any error.
+type VerifyFunc func(ctx context.Context, ref string) (*VerifyResponse, error)
+
+// VerifyResponse is the body of GET /verify
+type VerifyResponse struct {
+	Ref            string `json:"ref"`
+	Verified       bool   `json:"verified"`
+	ManifestDigest string `json:"manifest_digest,omitempty"`
+
+	PluginID string `json:"plugin_id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Version  string `json:"version,omitempty"`
+
+	ProvenanceVerified bool   `json:"provenance_verified"`
+	SBOMVerified       bool   `json:"sbom_verified"`
+	BuilderID          string `json:"builder_id,omitempty"`
+
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Why verification failed, a suggested fix, and the matching CLI exit code
+	Error    string `json:"error,omitempty"`
+	Hint     string `json:"hint,omitempty"`
+	ExitCode int    `json:"exit_code,omitempty"`
+
+	// When the result was computed; older than the request when served from cache
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Plugin is one lockfile entry in the body of GET /plugins
+type Plugin struct {
+	ID                 string     `json:"id"`
+	Name               string     `json:"name"`
+	Version            string     `json:"version"`
+	OCIReference       string     `json:"oci_reference"`
+	OCIDigest          string     `json:"oci_digest"`
+	ProvenanceVerified bool       `json:"provenance_verified"`
+	SBOMVerified       bool       `json:"sbom_verified"`
+	VerifiedAt         *time.Time `json:"verified_at,omitempty"`
+	PolicyHash         string     `json:"policy_hash,omitempty"`
+
+	// An unverified build from source (dragonglass dev install)
+	Dev bool `json:"dev,omitempty"`
+}
+
+// ServerOpts configures the API server
+type ServerOpts struct {
+	// Verifies references for GET /verify
+	Verify VerifyFunc
+
+	// Lockfile served by GET /plugins; the endpoint returns 404 when empty
+	LockfilePath string
+
+	// How long /verify results are reused for the same reference (0 disables caching)
+	CacheTTL time.Duration
+
+	// Maximum verifications running at once; further requests wait
+	Concurrency int
+
+	// Clock (default: time.Now)
+	Now func() time.Time
+
+	// Registry served by GET /metrics and updated with cache lookups; the endpoint
+	// returns 404 when nil
+	Metrics *metrics.Registry
+}
+
+// DefaultServerOpts returns options with the default cache TTL and concurrency
+func DefaultServerOpts() *ServerOpts {
+	return &ServerOpts{
+		CacheTTL:    DefaultCacheTTL,
+		Concurrency: DefaultConcurrency,
+		Now:         time.Now,
+	}
+}
+
+// WithVerify sets the function verifying references
+func (opts *ServerOpts) WithVerify(verify VerifyFunc) *ServerOpts {
+	opts.Verify = verify
+	return opts
+}
+
+// WithLockfilePath sets the lockfile served by GET /plugins
+func (opts *ServerOpts) WithLockfilePath(path string) *ServerOpts {
+	opts.LockfilePath = path
+	return opts
+}
+
+// WithCacheTTL sets how long verification results are reused
+func (opts *ServerOpts) WithCacheTTL(ttl time.Duration) *ServerOpts {
+	opts.CacheTTL = ttl
+	return opts
+}
+
+// WithConcurrency sets the maximum number of verifications running at once
+func (opts *ServerOpts) WithConcurrency(concurrency int) *ServerOpts {
+	opts.Concurrency = concurrency
+	return opts
+}
+
+// WithNow sets the clock
+func (opts *ServerOpts) WithNow(now func() time.Time) *ServerOpts {
+	opts.Now = now
+	return opts
+}
+
+// WithMetrics sets the registry served by GET /metrics
+func (opts *ServerOpts) WithMetrics(registry *metrics.Registry) *ServerOpts {
+	opts.Metrics = registry
+	return opts
+}
+
+// Server serves the read-only verification API
+type Server struct {
+	opts  *ServerOpts
+	slots chan struct{}
+
+	mu    sync.Mutex
+	cache map[string]cachedResult
+}
+
+type cachedResult struct {
+	status   int
+	response *VerifyResponse
+}
+
+// New creates a server. opts.Verify is required.
+func New(opts *ServerOpts) *Server {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Server{
+		opts:  opts,
+		slots: make(chan struct{}, max(opts.Concurrency, 1)),
+		cache: map[string]cachedResult{},
+	}
+}
+
+// Handler routes the API endpoints. Only GET is accepted.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("GET /verify", s.handleVerify)
+	mux.HandleFunc("GET /plugins", s.handlePlugins)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	return mux
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	ref := r.URL.Query().Get("ref")
+	if ref == "" {
+		writeError(w, http.StatusBadRequest, "missing ref query parameter")
+		return
+	}
+
+	if cached, ok := s.cached(ref); ok {
+		writeJSON(w, cached.status, cached.response)
+		return
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-r.Context().Done():
+		return
+	}
+
+	response, err := s.opts.Verify(r.Context(), ref)
+	if errors.Is(err, context.Canceled) {
+		// The client went away; nothing to answer or cache
+		return
+	}
+	if response == nil {
+		response = &VerifyResponse{}
+	}
+	response.Ref = ref
+	response.Verified = err == nil
+	response.CheckedAt = s.opts.Now().UTC()
+	if err != nil {
+		response.Error = err.Error()
+		response.Hint = dgerrors.Hint(err)
+		response.ExitCode = dgerrors.ExitCode(err)
+	}
+
+	status := verifyStatus(err)
+	if status < http.StatusInternalServerError {
+		s.store(ref, cachedResult{status: status, response: response})
+	}
+	writeJSON(w, status, response)
+}
+
+// verifyStatus maps a verification error to an HTTP status: 200 when verified, 422 when
+// the plugin fails the policy, 502 when the registry is unreachable, and 500 otherwise
+func verifyStatus(err error) int {
+	switch dgerrors.ExitCode(err) {
+	case dgerrors.ExitOK:
+		return http.StatusOK
+	case dgerrors.ExitVerificationFailed, dgerrors.ExitVulnBlocked:
+		return http.StatusUnprocessableEntity
+	case dgerrors.ExitRegistryUnavailable:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (s *Server) handlePlugins(w http.ResponseWriter, r *http.Request) {
+	if s.opts.LockfilePath == "" {
+		writeError(w, http.StatusNotFound, "no lockfile configured")
+		return
+	}
+
+	// Loaded per request so installs made while the server runs are reflected
+	lockfileData, err := lockfile.LoadLockfile(s.opts.LockfilePath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load lockfile: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string][]Plugin{"plugins": LockedPlugins(lockfileData)})
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if s.opts.Metrics == nil {
+		writeError(w, http.StatusNotFound, "metrics are disabled")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = s.opts.Metrics.WritePrometheus(w)
+}
+
+// LockedPlugins lists the plugins pinned in lockfileData, sorted by id
+func LockedPlugins(lockfileData *lockfile.Lockfile) []Plugin {
+	plugins := make([]Plugin, 0, len(lockfileData.Plugins))
+	for id, entry := range lockfileData.Plugins {
+		state := entry.VerificationState
+		plugins = append(plugins, Plugin{
+			ID:                 id,
+			Name:               entry.Name,
+			Version:            entry.Version,
+			OCIReference:       entry.OCIReference,
+			OCIDigest:          entry.OCIDigest,
+			ProvenanceVerified: state.ProvenanceVerified,
+			SBOMVerified:       state.SBOMVerified,
+			VerifiedAt:         state.VerifiedAt,
+			PolicyHash:         state.PolicyHash,
+			Dev:                entry.IsDev(),
+		})
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].ID < plugins[j].ID })
+	return plugins
+}
+
+// cached returns the stored result for ref when it is younger than the cache TTL
+func (s *Server) cached(ref string) (cachedResult, bool) {
+	if s.opts.CacheTTL <= 0 {
+		return cachedResult{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, ok := s.cache[ref]
+	if !ok || s.opts.Now().Sub(result.response.CheckedAt) >= s.opts.CacheTTL {
+		delete(s.cache, ref)
+		s.recordCacheLookup(false)
+		return cachedResult{}, false
+	}
+	s.recordCacheLookup(true)
+	return result, true
+}
+
+func (s *Server) recordCacheLookup(hit bool) {
+	if s.opts.Metrics != nil {
+		s.opts.Metrics.RecordCacheLookup(hit)
+	}
+}
+
+func (s *Server) store(ref string, result cachedResult) {
+	if s.opts.CacheTTL <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache[ref] = result
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
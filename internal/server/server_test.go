@@ -0,0 +1,198 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/metrics"
+)
+
+func TestVerifyEndpoint(t *testing.T) {
+	verify := func(ctx context.Context, ref string) (*VerifyResponse, error) {
+		switch ref {
+		case "ghcr.io/owner/good:1.0.0":
+			return &VerifyResponse{ManifestDigest: "sha256:abc", ProvenanceVerified: true}, nil
+		case "ghcr.io/owner/untrusted:1.0.0":
+			return &VerifyResponse{ManifestDigest: "sha256:def"}, &dgerrors.UntrustedBuilderError{Builder: "other", TrustedBuilder: "trusted"}
+		case "ghcr.io/owner/offline:1.0.0":
+			return nil, dgerrors.Mark(fmt.Errorf("connection refused"), dgerrors.ErrRegistryUnavailable)
+		default:
+			return nil, fmt.Errorf("unexpected reference")
+		}
+	}
+	handler := New(DefaultServerOpts().WithVerify(verify)).Handler()
+
+	tests := []struct {
+		name     string
+		target   string
+		status   int
+		verified bool
+		exitCode int
+	}{
+		{name: "verified", target: "/verify?ref=ghcr.io/owner/good:1.0.0", status: http.StatusOK, verified: true},
+		{name: "policy failure", target: "/verify?ref=ghcr.io/owner/untrusted:1.0.0", status: http.StatusUnprocessableEntity, exitCode: dgerrors.ExitVerificationFailed},
+		{name: "registry unavailable", target: "/verify?ref=ghcr.io/owner/offline:1.0.0", status: http.StatusBadGateway, exitCode: dgerrors.ExitRegistryUnavailable},
+		{name: "missing ref", target: "/verify", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body)
+			}
+			if tt.status == http.StatusBadRequest {
+				return
+			}
+
+			var response VerifyResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Verified != tt.verified || response.ExitCode != tt.exitCode {
+				t.Errorf("unexpected response %+v", response)
+			}
+			if !tt.verified && (response.Error == "" || response.Hint == "") {
+				t.Errorf("expected error and hint for failed verification, got %+v", response)
+			}
+		})
+	}
+}
+
+func TestVerifyCache(t *testing.T) {
+	calls := 0
+	verify := func(ctx context.Context, ref string) (*VerifyResponse, error) {
+		calls++
+		if ref == "ghcr.io/owner/offline:1.0.0" {
+			return nil, dgerrors.ErrRegistryUnavailable
+		}
+		return &VerifyResponse{}, nil
+	}
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	handler := New(DefaultServerOpts().
+		WithVerify(verify).
+		WithCacheTTL(time.Minute).
+		WithNow(func() time.Time { return now })).Handler()
+
+	get := func(ref string) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/verify?ref="+ref, nil))
+	}
+
+	get("ghcr.io/owner/good:1.0.0")
+	get("ghcr.io/owner/good:1.0.0")
+	if calls != 1 {
+		t.Errorf("expected cached result within the TTL, got %d verifications", calls)
+	}
+
+	now = now.Add(time.Minute)
+	get("ghcr.io/owner/good:1.0.0")
+	if calls != 2 {
+		t.Errorf("expected expired result to be verified again, got %d verifications", calls)
+	}
+
+	// Transient failures are retried rather than cached
+	get("ghcr.io/owner/offline:1.0.0")
+	get("ghcr.io/owner/offline:1.0.0")
+	if calls != 4 {
+		t.Errorf("expected registry failures to be verified again, got %d verifications", calls)
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	registry := metrics.NewRegistry()
+	verify := func(ctx context.Context, ref string) (*VerifyResponse, error) {
+		return &VerifyResponse{}, nil
+	}
+	handler := New(DefaultServerOpts().WithVerify(verify).WithMetrics(registry)).Handler()
+
+	for range 3 {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/verify?ref=ghcr.io/owner/good:1.0.0", nil))
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body)
+	}
+	for _, line := range []string{
+		`dragonglass_cache_lookups_total{result="hit"} 2`,
+		`dragonglass_cache_lookups_total{result="miss"} 1`,
+	} {
+		if !strings.Contains(rec.Body.String(), line+"\n") {
+			t.Errorf("expected %q in:\n%s", line, rec.Body)
+		}
+	}
+}
+
+func TestPluginsEndpoint(t *testing.T) {
+	lockfilePath := filepath.Join(t.TempDir(), lockfile.LockfileName)
+	lockfileData := lockfile.NewLockfile("")
+	for _, id := range []string{"zeta", "alpha"} {
+		if err := lockfileData.AddPlugin(id, lockfile.PluginEntry{
+			Name:              id,
+			Version:           "1.0.0",
+			OCIReference:      "ghcr.io/owner/" + id + ":1.0.0",
+			OCIDigest:         "sha256:" + id,
+			VerificationState: lockfile.VerificationState{ProvenanceVerified: true},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lockfile.SaveLockfile(lockfileData, lockfilePath); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := New(DefaultServerOpts().WithLockfilePath(lockfilePath)).Handler()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/plugins", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body)
+	}
+
+	var body struct {
+		Plugins []Plugin `json:"plugins"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Plugins) != 2 || body.Plugins[0].ID != "alpha" || body.Plugins[1].OCIDigest != "sha256:zeta" || !body.Plugins[0].ProvenanceVerified {
+		t.Errorf("unexpected plugins %+v", body.Plugins)
+	}
+}
+
+func TestReadOnlyRoutes(t *testing.T) {
+	handler := New(DefaultServerOpts()).Handler()
+
+	tests := []struct {
+		method string
+		target string
+		status int
+	}{
+		{method: http.MethodGet, target: "/healthz", status: http.StatusOK},
+		{method: http.MethodPost, target: "/verify?ref=x", status: http.StatusMethodNotAllowed},
+		{method: http.MethodDelete, target: "/plugins", status: http.StatusMethodNotAllowed},
+		{method: http.MethodGet, target: "/plugins", status: http.StatusNotFound},
+		{method: http.MethodGet, target: "/metrics", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+		if rec.Code != tt.status {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.target, tt.status, rec.Code)
+		}
+	}
+}
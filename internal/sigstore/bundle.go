@@ -0,0 +1,47 @@
+// ABOUTME: Verification of keyless Sigstore bundles signed by a specific workflow identity
+// ABOUTME: Returns the signed DSSE payload once the certificate, log entry and digest check out
+package sigstore
+
+import (
+	"fmt"
+
+	"github.com/sigstore/sigstore-go/pkg/bundle"
+	"github.com/sigstore/sigstore-go/pkg/verify"
+)
+
+// ActionsIssuer is the OIDC issuer of GitHub Actions identities in Fulcio certificates
+const ActionsIssuer = "https://token.actions.githubusercontent.com"
+
+// VerifyBundle checks that data, a Sigstore bundle as JSON, was signed keyless by the
+// GitHub Actions workflow identity (the certificate SAN, matched exactly) over a statement
+// whose subject has the given SHA-256 digest, and returns the statement payload
+func VerifyBundle(data, sha256Digest []byte, identity string) ([]byte, error) {
+	var b bundle.Bundle
+	if err := b.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("failed to parse Sigstore bundle: %w", err)
+	}
+
+	verifier, err := SharedVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	certificateIdentity, err := verify.NewShortCertificateIdentity(ActionsIssuer, "", identity, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate identity: %w", err)
+	}
+	policy := verify.NewPolicy(verify.WithArtifactDigest("sha256", sha256Digest), verify.WithCertificateIdentity(certificateIdentity))
+	if _, err := verifier.Verify(&b, policy); err != nil {
+		return nil, fmt.Errorf("sigstore bundle verification failed: %w", err)
+	}
+
+	envelope, err := b.Envelope()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract envelope: %w", err)
+	}
+	payload, err := envelope.RawEnvelope().DecodeB64Payload()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+	return payload, nil
+}
@@ -10,17 +10,16 @@ import (
 	"io"
 	"net/http"
 	"strings"
-	"time"
 
 	v1 "github.com/in-toto/attestation/go/predicates/provenance/v1"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sigstore/sigstore-go/pkg/bundle"
 	"github.com/sigstore/sigstore-go/pkg/fulcio/certificate"
-	"github.com/sigstore/sigstore-go/pkg/root"
 	"github.com/sigstore/sigstore-go/pkg/verify"
 	"oras.land/oras-go/v2/registry"
 
 	"github.com/gillisandrew/dragonglass-poc/internal/domain"
+	"github.com/gillisandrew/dragonglass-poc/internal/httpclient"
 	"github.com/gillisandrew/dragonglass-poc/internal/oci"
 )
 
@@ -33,12 +32,10 @@ type Service struct {
 
 // NewService creates a new Sigstore attestation service
 func NewService(token string) (*Service, error) {
-	httpClient := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	httpClient := httpclient.New(httpclient.DefaultOpts())
 
 	// Initialize sigstore verifier with production trust roots
-	sigstoreVerifier, err := newSigstoreVerifier()
+	sigstoreVerifier, err := SharedVerifier()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create sigstore verifier: %w", err)
 	}
@@ -150,7 +147,7 @@ func (s *Service) discoverAttestations(ctx context.Context, imageRef string) ([]
 	}
 
 	// Get OCI attestations using existing OCI implementation
-	_, attestationReaders, err := repo.GetSLSAAttestations(ctx, desc)
+	_, attestationReaders, err := repo.GetAttestations(ctx, desc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get attestations: %v", err)
 	}
@@ -346,20 +343,3 @@ func (s *Service) verifySBOMAttestation(attestation AttestationData) (*domain.SB
 
 	return result, nil
 }
-
-// newSigstoreVerifier creates a new sigstore verifier with production trust roots
-func newSigstoreVerifier() (*verify.Verifier, error) {
-	// Get production trust roots
-	trustedRoot, err := root.FetchTrustedRoot()
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch trusted root: %w", err)
-	}
-
-	// Create verifier with production trust roots and timestamp validation
-	verifier, err := verify.NewVerifier(trustedRoot, verify.WithCurrentTime())
-	if err != nil {
-		return nil, fmt.Errorf("failed to create verifier: %w", err)
-	}
-
-	return verifier, nil
-}
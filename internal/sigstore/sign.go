@@ -0,0 +1,51 @@
+// ABOUTME: Keyless signing of in-toto statements with Fulcio certificates and Rekor entries
+// ABOUTME: Produces Sigstore bundles for attestations created with an ambient OIDC identity
+package sigstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sigstore/sigstore-go/pkg/sign"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	// Public-good Sigstore instances used for keyless signing
+	FulcioURL = "https://fulcio.sigstore.dev"
+	RekorURL  = "https://rekor.sigstore.dev"
+
+	// SigningAudience is the ID token audience Fulcio accepts
+	SigningAudience = "sigstore"
+)
+
+// SignKeyless signs a DSSE payload with an ephemeral key certified by Fulcio for the
+// identity in idToken, records the signature in Rekor, and returns the Sigstore bundle
+// as JSON
+func SignKeyless(ctx context.Context, payload []byte, payloadType, idToken string) ([]byte, error) {
+	keypair, err := sign.NewEphemeralKeypair(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ephemeral signing key: %w", err)
+	}
+
+	opts := sign.BundleOptions{
+		Context:                    ctx,
+		CertificateProvider:        sign.NewFulcio(&sign.FulcioOptions{BaseURL: FulcioURL, Timeout: 30 * time.Second, Retries: 1}),
+		CertificateProviderOptions: &sign.CertificateProviderOptions{IDToken: idToken},
+		TransparencyLogs: []sign.Transparency{
+			sign.NewRekor(&sign.RekorOptions{BaseURL: RekorURL, Timeout: 90 * time.Second, Retries: 1}),
+		},
+	}
+
+	bundle, err := sign.Bundle(&sign.DSSEData{Data: payload, PayloadType: payloadType}, keypair, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign with Sigstore: %w", err)
+	}
+
+	data, err := protojson.Marshal(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Sigstore bundle: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,127 @@
+// ABOUTME: Process-wide provider for the Sigstore verifier built from the production trusted root
+// ABOUTME: Fetches the TUF trusted root once and shares the verifier across packages and goroutines
+package sigstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sigstore/sigstore-go/pkg/root"
+	"github.com/sigstore/sigstore-go/pkg/tuf"
+	"github.com/sigstore/sigstore-go/pkg/verify"
+	"github.com/theupdateframework/go-tuf/v2/metadata"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+	"github.com/gillisandrew/dragonglass-poc/internal/httpclient"
+	"github.com/gillisandrew/dragonglass-poc/internal/userdirs"
+)
+
+// VerifierProvider lazily builds a Sigstore verifier and hands the same instance to every
+// caller. A failed build is not cached, so a later call retries the trusted root fetch.
+type VerifierProvider struct {
+	build func() (*verify.Verifier, error)
+
+	mu       sync.Mutex
+	verifier *verify.Verifier
+}
+
+// NewVerifierProvider returns a provider that builds its verifier with build
+func NewVerifierProvider(build func() (*verify.Verifier, error)) *VerifierProvider {
+	return &VerifierProvider{build: build}
+}
+
+// Verifier returns the shared verifier, building it on first use. It is safe for
+// concurrent use; callers arriving during the first build wait for its result.
+func (p *VerifierProvider) Verifier() (*verify.Verifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.verifier != nil {
+		return p.verifier, nil
+	}
+
+	verifier, err := p.build()
+	if err != nil {
+		return nil, err
+	}
+	p.verifier = verifier
+	return verifier, nil
+}
+
+var defaultProvider = NewVerifierProvider(newProductionVerifier)
+
+// SharedVerifier returns the process-wide verifier for the production Sigstore
+// infrastructure (Fulcio, Rekor), used by both attestation verification paths
+func SharedVerifier() (*verify.Verifier, error) {
+	return defaultProvider.Verifier()
+}
+
+// newProductionVerifier creates a sigstore verifier with production trust roots
+func newProductionVerifier() (*verify.Verifier, error) {
+	// Fetch the production trust root from the Sigstore TUF repository
+	// This includes Fulcio CA certificates and Rekor public keys
+	trustedMaterial, err := fetchTrustedRoot(context.Background(), httpclient.DefaultRetryOpts().WithRetryable(transientTUF))
+	if err != nil {
+		return nil, err
+	}
+
+	// Create verifier with production sigstore configuration
+	// This uses the public Fulcio CA and Rekor transparency log for GitHub Actions
+	verifierConfig := []verify.VerifierOption{
+		// Require SCT (certificate transparency) verification
+		verify.WithSignedCertificateTimestamps(1),
+		// Require transparency log verification
+		verify.WithTransparencyLog(1),
+		// Use integrated timestamps from Rekor for certificate validation
+		verify.WithIntegratedTimestamps(1),
+	}
+
+	verifier, err := verify.NewVerifier(trustedMaterial, verifierConfig...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sigstore verifier with production trust roots: %w", err)
+	}
+
+	return verifier, nil
+}
+
+// fetchTrustedRoot fetches the trusted root, retrying as opts allows while the TUF
+// repository fails transiently. A fetch that never succeeded for such reasons is marked
+// ErrTransientVerification.
+func fetchTrustedRoot(ctx context.Context, opts *httpclient.RetryOpts) (*root.TrustedRoot, error) {
+	var trustedRoot *root.TrustedRoot
+	err := httpclient.Retry(ctx, opts, func(context.Context) error {
+		var err error
+		trustedRoot, err = root.FetchTrustedRootWithOptions(tufOptions())
+		return err
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to fetch sigstore trusted root: %w", err)
+		if transientTUF(err) {
+			return nil, dgerrors.Mark(err, dgerrors.ErrTransientVerification)
+		}
+		return nil, err
+	}
+	return trustedRoot, nil
+}
+
+// transientTUF reports whether a failed TUF fetch may succeed when repeated, including
+// downloads the repository answered with a transient HTTP status
+func transientTUF(err error) bool {
+	var httpErr *metadata.ErrDownloadHTTP
+	if errors.As(err, &httpErr) {
+		return httpclient.TransientStatus(httpErr.StatusCode)
+	}
+	return httpclient.Transient(err)
+}
+
+// tufOptions caches TUF metadata in the dragonglass cache directory, falling back to the
+// Sigstore default (~/.sigstore/root) when it cannot be determined
+func tufOptions() *tuf.Options {
+	opts := tuf.DefaultOptions()
+	if dirs, err := userdirs.Resolve(); err == nil {
+		opts = opts.WithCachePath(dirs.TUFCacheDir())
+	}
+	return opts
+}
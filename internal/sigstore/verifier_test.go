@@ -0,0 +1,48 @@
+package sigstore
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sigstore/sigstore-go/pkg/verify"
+)
+
+func TestVerifierProvider(t *testing.T) {
+	var builds int32
+	fail := true
+	provider := NewVerifierProvider(func() (*verify.Verifier, error) {
+		atomic.AddInt32(&builds, 1)
+		if fail {
+			return nil, errors.New("trusted root unavailable")
+		}
+		return &verify.Verifier{}, nil
+	})
+
+	if _, err := provider.Verifier(); err == nil {
+		t.Fatal("expected build error")
+	}
+
+	// A failed build is retried, and the successful result is shared by every caller
+	fail = false
+	var wg sync.WaitGroup
+	verifiers := make([]*verify.Verifier, 8)
+	for i := range verifiers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			verifiers[i], _ = provider.Verifier()
+		}(i)
+	}
+	wg.Wait()
+
+	for i, v := range verifiers {
+		if v == nil || v != verifiers[0] {
+			t.Errorf("expected caller %d to receive the shared verifier", i)
+		}
+	}
+	if builds != 2 {
+		t.Errorf("expected 2 builds (one failed, one shared), got %d", builds)
+	}
+}
@@ -0,0 +1,76 @@
+// ABOUTME: Status of a long-running step, shown as a spinner or as plain progress lines
+// ABOUTME: Plain lines are rate limited so screen readers are not flooded with updates
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+// PlainProgressInterval is the minimum time between progress lines in plain mode
+const PlainProgressInterval = 30 * time.Second
+
+// Progress shows the status of a step while it runs: an animated spinner normally, and
+// a line per update, at most one per PlainProgressInterval, in plain mode
+type Progress struct {
+	spinner *pterm.SpinnerPrinter
+
+	// Plain mode
+	writer  io.Writer
+	now     func() time.Time
+	mu      sync.Mutex
+	printed time.Time
+}
+
+// StartProgress shows text as the status of a step that has started. Quiet mode shows
+// nothing.
+func StartProgress(text string) *Progress {
+	if quiet {
+		return startPlainProgress(io.Discard, time.Now, text)
+	}
+	if plain {
+		return startPlainProgress(os.Stdout, time.Now, text)
+	}
+	spinner, _ := pterm.DefaultSpinner.Start(text)
+	return &Progress{spinner: spinner}
+}
+
+func startPlainProgress(w io.Writer, now func() time.Time, text string) *Progress {
+	p := &Progress{writer: w, now: now}
+	p.print(text)
+	return p
+}
+
+// Update replaces the status text
+func (p *Progress) Update(text string) {
+	if p.spinner != nil {
+		p.spinner.UpdateText(text)
+		return
+	}
+
+	p.mu.Lock()
+	due := p.now().Sub(p.printed) >= PlainProgressInterval
+	p.mu.Unlock()
+	if due {
+		p.print(text)
+	}
+}
+
+// Stop removes the spinner; in plain mode there is nothing to remove
+func (p *Progress) Stop() {
+	if p.spinner != nil {
+		_ = p.spinner.Stop()
+	}
+}
+
+func (p *Progress) print(text string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printed = p.now()
+	fmt.Fprintln(p.writer, text)
+}
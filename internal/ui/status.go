@@ -0,0 +1,81 @@
+// ABOUTME: Presenter for decorated messages such as auth progress, results, and warnings
+// ABOUTME: Output settings decide whether messages carry emoji and whether they appear at all
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pterm/pterm"
+)
+
+// Icons that prefix status messages when emoji are enabled
+const (
+	IconStart   = "🚀"
+	IconSuccess = "🎉"
+	IconLock    = "🔐"
+	IconPackage = "📦"
+)
+
+var (
+	emoji = true
+	quiet bool
+
+	// Status messages go to stderr with log lines, keeping stdout for command output
+	statusWriter io.Writer = os.Stderr
+)
+
+// SetEmoji switches the emoji prefix of status messages; plain mode never shows them
+func SetEmoji(enabled bool) {
+	emoji = enabled
+}
+
+// SetQuiet suppresses status messages and progress, so stderr carries only warnings and
+// errors
+func SetQuiet(enabled bool) {
+	quiet = enabled
+}
+
+// Quiet reports whether quiet mode is enabled for this process
+func Quiet() bool {
+	return quiet
+}
+
+// Status prints text as a status message, prefixed with icon when emoji are shown.
+// Nothing is printed in quiet mode.
+func Status(icon, text string) {
+	if quiet {
+		return
+	}
+	fmt.Fprintln(statusWriter, decorate(icon, text))
+}
+
+// Info prints an informational message; nothing is printed in quiet mode
+func Info(text string) {
+	if quiet {
+		return
+	}
+	pterm.Info.WithWriter(statusWriter).Println(text)
+}
+
+// Success prints the result of a completed action; nothing is printed in quiet mode
+func Success(text string) {
+	if quiet {
+		return
+	}
+	pterm.Success.WithWriter(statusWriter).Println(text)
+}
+
+// Warning prints a warning, which is shown in quiet mode too
+func Warning(text string) {
+	pterm.Warning.WithWriter(statusWriter).Println(text)
+}
+
+// decorate prefixes text with icon unless emoji are disabled or output is plain
+func decorate(icon, text string) string {
+	if !emoji || plain || icon == "" {
+		return text
+	}
+	return icon + " " + text
+}
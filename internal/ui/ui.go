@@ -0,0 +1,109 @@
+// ABOUTME: Terminal presentation shared by commands, either styled or plain
+// ABOUTME: Plain mode drops colors, spinners, and box drawing for screen readers and dumb terminals
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/pterm/pterm/putils"
+)
+
+// PlainWidth is the terminal width assumed in plain mode. It is wide enough that pterm
+// never wraps a log line's arguments onto tree-drawn continuation lines.
+const PlainWidth = 1 << 16
+
+var plain bool
+
+// Detect reports whether plain output suits the environment when it was not chosen
+// explicitly: NO_COLOR is set (https://no-color.org) or stdout is not a terminal
+func Detect(getenv func(string) string, stdoutIsTerminal bool) bool {
+	return getenv("NO_COLOR") != "" || !stdoutIsTerminal
+}
+
+// IsTerminal reports whether f is a character device such as a terminal rather than a
+// pipe or file
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// SetPlain switches all pterm output between styled and plain text
+func SetPlain(enabled bool) {
+	plain = enabled
+	if enabled {
+		pterm.DisableStyling()
+		pterm.SetForcedTerminalSize(PlainWidth, pterm.GetTerminalHeight())
+	} else {
+		pterm.EnableStyling()
+		pterm.SetForcedTerminalSize(0, 0)
+	}
+}
+
+// Plain reports whether plain output is enabled for this process
+func Plain() bool {
+	return plain
+}
+
+// Logger adapts logger to the output mode. In plain mode each message and its arguments
+// stay on one line, so screen readers read them in order without tree characters.
+func Logger(logger *pterm.Logger) *pterm.Logger {
+	if plain {
+		return logger.WithMaxWidth(PlainWidth)
+	}
+	return logger
+}
+
+// Box prints text framed under title, or as a "title: text" line in plain mode
+func Box(title, text string) {
+	if plain {
+		pterm.Println(title + ": " + text)
+		return
+	}
+	pterm.DefaultBox.WithTitle(title).WithTitleTopCenter().Println(text)
+}
+
+// Steps prints instructions to follow in order, as a bullet list or, in plain mode, as
+// numbered lines
+func Steps(steps []string) {
+	if plain {
+		for i, step := range steps {
+			pterm.Printfln("%d. %s", i+1, step)
+		}
+		return
+	}
+
+	items := make([]pterm.BulletListItem, len(steps))
+	for i, step := range steps {
+		items[i] = pterm.BulletListItem{Level: 0, Text: step}
+	}
+	_ = pterm.DefaultBulletList.WithItems(items).Render()
+}
+
+// Tree prints items as a tree, or in plain mode as lines indented by two spaces per
+// level
+func Tree(items pterm.LeveledList) {
+	if plain {
+		for _, item := range items {
+			pterm.Println(strings.Repeat("  ", item.Level) + item.Text)
+		}
+		return
+	}
+	_ = pterm.DefaultTree.WithRoot(putils.TreeFromLeveledList(items)).Render()
+}
+
+// FormatBytes formats n with a binary unit, e.g. 1.5 MiB
+func FormatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		noColor  string
+		terminal bool
+		expected bool
+	}{
+		{name: "terminal", terminal: true, expected: false},
+		{name: "NO_COLOR on a terminal", noColor: "1", terminal: true, expected: true},
+		{name: "piped", terminal: false, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(name string) string {
+				if name == "NO_COLOR" {
+					return tt.noColor
+				}
+				return ""
+			}
+			if got := Detect(getenv, tt.terminal); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPlainProgress(t *testing.T) {
+	var out strings.Builder
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	p := startPlainProgress(&out, func() time.Time { return now }, "waiting 5:00")
+	for i := 1; i <= 60; i++ {
+		now = now.Add(time.Second)
+		p.Update("tick")
+	}
+	p.Stop()
+
+	expected := "waiting 5:00\ntick\ntick\n"
+	if out.String() != expected {
+		t.Errorf("expected one line per %s, got:\n%s", PlainProgressInterval, out.String())
+	}
+}
+
+func TestStatus(t *testing.T) {
+	defer func(w io.Writer) { statusWriter = w }(statusWriter)
+	defer SetEmoji(true)
+	defer SetQuiet(false)
+	defer SetPlain(false)
+
+	tests := []struct {
+		name     string
+		emoji    bool
+		quiet    bool
+		plain    bool
+		expected string
+	}{
+		{name: "emoji", emoji: true, expected: "🔐 Token stored\n"},
+		{name: "emoji disabled", emoji: false, expected: "Token stored\n"},
+		{name: "plain drops emoji", emoji: true, plain: true, expected: "Token stored\n"},
+		{name: "quiet", emoji: true, quiet: true, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			statusWriter = &out
+			SetEmoji(tt.emoji)
+			SetQuiet(tt.quiet)
+			SetPlain(tt.plain)
+
+			Status(IconLock, "Token stored")
+			if out.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestQuietKeepsWarnings(t *testing.T) {
+	defer func(w io.Writer) { statusWriter = w }(statusWriter)
+	defer SetQuiet(false)
+
+	var out strings.Builder
+	statusWriter = &out
+	SetQuiet(true)
+
+	Info("informational")
+	Success("done")
+	Warning("careful")
+	if got := out.String(); strings.Contains(got, "informational") || strings.Contains(got, "done") || !strings.Contains(got, "careful") {
+		t.Errorf("expected only the warning in quiet mode, got %q", got)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    uint64
+		want string
+	}{
+		{n: 0, want: "0 B"},
+		{n: 1023, want: "1023 B"},
+		{n: 1536, want: "1.5 KiB"},
+		{n: 5 << 20, want: "5.0 MiB"},
+		{n: 3 << 30, want: "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatBytes(tt.n); got != tt.want {
+			t.Errorf("FormatBytes(%d): expected %q, got %q", tt.n, tt.want, got)
+		}
+	}
+}
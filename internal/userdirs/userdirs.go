@@ -0,0 +1,172 @@
+// ABOUTME: OS-appropriate user-level directories for dragonglass config, credentials, and caches
+// ABOUTME: Follows XDG on Linux and Library conventions on macOS, migrating the legacy ~/.dragonglass
+package userdirs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	AppName = "dragonglass"
+
+	// LegacyDirName is the directory in $HOME used by releases before OS-specific directories
+	LegacyDirName = ".dragonglass"
+
+	TUFCacheDirName = "tuf"
+
+	StatsFileName = "stats.json"
+
+	CatalogCacheDirName = "catalog"
+)
+
+// Dirs are the user-level directories dragonglass keeps state in
+type Dirs struct {
+	// User config and the credentials file
+	Config string
+
+	// Data that can be re-created, such as the Sigstore TUF cache
+	Cache string
+
+	// Directory used by older releases, migrated by Migrate
+	Legacy string
+}
+
+// Resolve returns the directories for the current user and OS
+func Resolve() (*Dirs, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return ResolveFor(runtime.GOOS, os.Getenv, home)
+}
+
+// ResolveFor returns the directories for goos given the environment and home directory:
+//
+//	linux and other unix: $XDG_CONFIG_HOME/dragonglass (~/.config) and $XDG_CACHE_HOME/dragonglass (~/.cache)
+//	darwin:               ~/Library/Application Support/dragonglass and ~/Library/Caches/dragonglass
+//	windows:              %AppData%\dragonglass and %LocalAppData%\dragonglass
+func ResolveFor(goos string, getenv func(string) string, home string) (*Dirs, error) {
+	dirs := &Dirs{Legacy: filepath.Join(home, LegacyDirName)}
+
+	switch goos {
+	case "darwin", "ios":
+		dirs.Config = filepath.Join(home, "Library", "Application Support", AppName)
+		dirs.Cache = filepath.Join(home, "Library", "Caches", AppName)
+	case "windows":
+		appData, localAppData := getenv("AppData"), getenv("LocalAppData")
+		if appData == "" || localAppData == "" {
+			return nil, errors.New("%AppData% and %LocalAppData% must be set")
+		}
+		dirs.Config = filepath.Join(appData, AppName)
+		dirs.Cache = filepath.Join(localAppData, AppName)
+	default:
+		dirs.Config = filepath.Join(xdgDir(getenv("XDG_CONFIG_HOME"), filepath.Join(home, ".config")), AppName)
+		dirs.Cache = filepath.Join(xdgDir(getenv("XDG_CACHE_HOME"), filepath.Join(home, ".cache")), AppName)
+	}
+	return dirs, nil
+}
+
+// xdgDir returns value when it is an absolute path, as the XDG spec requires, and
+// fallback otherwise
+func xdgDir(value, fallback string) string {
+	if filepath.IsAbs(value) {
+		return value
+	}
+	return fallback
+}
+
+// TUFCacheDir returns the cache of Sigstore trusted root metadata
+func (d *Dirs) TUFCacheDir() string {
+	return filepath.Join(d.Cache, TUFCacheDirName)
+}
+
+// StatsFile returns the file dragonglass stats reads metrics of past runs from
+func (d *Dirs) StatsFile() string {
+	return filepath.Join(d.Cache, StatsFileName)
+}
+
+// CatalogCacheDir returns the cache of plugin catalogs fetched by search and add by name
+func (d *Dirs) CatalogCacheDir() string {
+	return filepath.Join(d.Cache, CatalogCacheDirName)
+}
+
+// Migration is a file moved out of the legacy directory
+type Migration struct {
+	From string
+	To   string
+
+	// The file already existed at To, so the legacy copy was left in place
+	Conflict bool
+}
+
+// Migrate moves the named files from the legacy directory into d.Config. A file that
+// already exists in d.Config is kept and the legacy copy left alone. The legacy directory
+// is removed once empty; anything else in it, such as the .dragonglass directory of a vault
+// rooted at $HOME, is untouched.
+func (d *Dirs) Migrate(names ...string) ([]Migration, error) {
+	var migrations []Migration
+	for _, name := range names {
+		from := filepath.Join(d.Legacy, name)
+		if _, err := os.Stat(from); errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return migrations, fmt.Errorf("failed to check %s: %w", from, err)
+		}
+
+		to := filepath.Join(d.Config, name)
+		if _, err := os.Stat(to); err == nil {
+			migrations = append(migrations, Migration{From: from, To: to, Conflict: true})
+			continue
+		}
+
+		// The config directory holds credentials, so only the user may read it
+		if err := os.MkdirAll(d.Config, 0700); err != nil {
+			return migrations, fmt.Errorf("failed to create config directory: %w", err)
+		}
+		if err := moveFile(from, to); err != nil {
+			return migrations, fmt.Errorf("failed to move %s to %s: %w", from, to, err)
+		}
+		migrations = append(migrations, Migration{From: from, To: to})
+	}
+
+	// Fails harmlessly while the directory has other contents
+	_ = os.Remove(d.Legacy)
+	return migrations, nil
+}
+
+// moveFile renames from to to, copying when they are on different filesystems
+func moveFile(from, to string) error {
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(to)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(to)
+		return err
+	}
+	return os.Remove(from)
+}
@@ -0,0 +1,117 @@
+package userdirs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		goos   string
+		env    map[string]string
+		config string
+		cache  string
+	}{
+		{name: "linux defaults", goos: "linux", config: "/home/u/.config/dragonglass", cache: "/home/u/.cache/dragonglass"},
+		{
+			name:   "linux XDG",
+			goos:   "linux",
+			env:    map[string]string{"XDG_CONFIG_HOME": "/xdg/config", "XDG_CACHE_HOME": "/xdg/cache"},
+			config: "/xdg/config/dragonglass",
+			cache:  "/xdg/cache/dragonglass",
+		},
+		{
+			name:   "relative XDG ignored",
+			goos:   "freebsd",
+			env:    map[string]string{"XDG_CONFIG_HOME": "config"},
+			config: "/home/u/.config/dragonglass",
+			cache:  "/home/u/.cache/dragonglass",
+		},
+		{name: "macOS", goos: "darwin", config: "/home/u/Library/Application Support/dragonglass", cache: "/home/u/Library/Caches/dragonglass"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dirs, err := ResolveFor(tt.goos, func(name string) string { return tt.env[name] }, "/home/u")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if dirs.Config != tt.config || dirs.Cache != tt.cache || dirs.Legacy != "/home/u/.dragonglass" {
+				t.Errorf("unexpected dirs %+v", dirs)
+			}
+		})
+	}
+
+	if _, err := ResolveFor("windows", func(string) string { return "" }, "/home/u"); err == nil {
+		t.Error("expected error on windows without %AppData%")
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	home := t.TempDir()
+	dirs := &Dirs{
+		Config: filepath.Join(home, ".config", "dragonglass"),
+		Cache:  filepath.Join(home, ".cache", "dragonglass"),
+		Legacy: filepath.Join(home, LegacyDirName),
+	}
+	if err := os.MkdirAll(dirs.Legacy, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dirs.Legacy, "credentials.json"), []byte(`{"token": "x"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := dirs.Migrate("config.json", "credentials.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(migrations) != 1 || migrations[0].To != filepath.Join(dirs.Config, "credentials.json") || migrations[0].Conflict {
+		t.Errorf("unexpected migrations %+v", migrations)
+	}
+
+	info, err := os.Stat(filepath.Join(dirs.Config, "credentials.json"))
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected migrated credentials with mode 0600, got %v (%v)", info, err)
+	}
+	if _, err := os.Stat(dirs.Legacy); !os.IsNotExist(err) {
+		t.Errorf("expected empty legacy directory to be removed, got %v", err)
+	}
+
+	// Nothing left to migrate
+	if migrations, err := dirs.Migrate("config.json", "credentials.json"); err != nil || len(migrations) != 0 {
+		t.Errorf("expected no migrations, got %+v (%v)", migrations, err)
+	}
+}
+
+func TestMigrateKeepsExistingFiles(t *testing.T) {
+	home := t.TempDir()
+	dirs := &Dirs{Config: filepath.Join(home, "config"), Legacy: filepath.Join(home, LegacyDirName)}
+	for dir, content := range map[string]string{dirs.Legacy: "legacy", dirs.Config: "current"} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A vault rooted at $HOME keeps its lockfile in the same directory
+	if err := os.WriteFile(filepath.Join(dirs.Legacy, "dragonglass-lock.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := dirs.Migrate("config.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(migrations) != 1 || !migrations[0].Conflict {
+		t.Errorf("expected a conflict, got %+v", migrations)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dirs.Config, "config.json")); string(data) != "current" {
+		t.Errorf("expected current config to be kept, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dirs.Legacy, "dragonglass-lock.json")); err != nil {
+		t.Errorf("expected unrelated files to be left alone: %v", err)
+	}
+}
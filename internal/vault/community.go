@@ -0,0 +1,92 @@
+// ABOUTME: Obsidian's list of enabled community plugins, .obsidian/community-plugins.json
+// ABOUTME: Switches installed plugins on and off without touching their files
+package vault
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// EnabledPlugins returns the IDs of the community plugins Obsidian loads, in the order
+// community-plugins.json lists them. A vault without the file has none enabled.
+func (v *Vault) EnabledPlugins() ([]string, error) {
+	data, err := os.ReadFile(v.CommunityPluginsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", CommunityPluginsFileName, err)
+	}
+
+	var ids []string
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", CommunityPluginsFileName, err)
+	}
+	return ids, nil
+}
+
+// SetPluginEnabled adds pluginID to the end of community-plugins.json or removes it,
+// keeping the other plugins in order, and reports whether the file changed. Obsidian
+// reads the file when the vault is opened, so a running Obsidian applies the change once
+// the vault is reloaded.
+func (v *Vault) SetPluginEnabled(pluginID string, enabled bool) (bool, error) {
+	ids, err := v.EnabledPlugins()
+	if err != nil {
+		return false, err
+	}
+
+	if slices.Contains(ids, pluginID) == enabled {
+		return false, nil
+	}
+	if enabled {
+		ids = append(ids, pluginID)
+	} else {
+		ids = slices.DeleteFunc(ids, func(id string) bool { return id == pluginID })
+	}
+
+	if err := v.writeEnabledPlugins(ids); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// writeEnabledPlugins replaces community-plugins.json with ids, formatted as Obsidian
+// writes it. The file is renamed into place so Obsidian never reads a partial list.
+func (v *Vault) writeEnabledPlugins(ids []string) error {
+	if ids == nil {
+		ids = []string{}
+	}
+	data, err := json.MarshalIndent(ids, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", CommunityPluginsFileName, err)
+	}
+
+	path := v.CommunityPluginsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", ObsidianDirName, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+CommunityPluginsFileName+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", CommunityPluginsFileName, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", CommunityPluginsFileName, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", CommunityPluginsFileName, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", CommunityPluginsFileName, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", CommunityPluginsFileName, err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package vault
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestSetPluginEnabled(t *testing.T) {
+	v := New(t.TempDir())
+
+	if ids, err := v.EnabledPlugins(); err != nil || len(ids) != 0 {
+		t.Fatalf("expected no enabled plugins without the file, got %v (%v)", ids, err)
+	}
+
+	steps := []struct {
+		id      string
+		enabled bool
+		changed bool
+		want    []string
+	}{
+		{id: "calendar", enabled: true, changed: true, want: []string{"calendar"}},
+		{id: "dataview", enabled: true, changed: true, want: []string{"calendar", "dataview"}},
+		{id: "tasknotes", enabled: true, changed: true, want: []string{"calendar", "dataview", "tasknotes"}},
+		{id: "dataview", enabled: true, want: []string{"calendar", "dataview", "tasknotes"}},
+		{id: "dataview", enabled: false, changed: true, want: []string{"calendar", "tasknotes"}},
+		{id: "missing", enabled: false, want: []string{"calendar", "tasknotes"}},
+	}
+
+	for _, step := range steps {
+		changed, err := v.SetPluginEnabled(step.id, step.enabled)
+		if err != nil {
+			t.Fatalf("%s enabled=%v: %v", step.id, step.enabled, err)
+		}
+		if changed != step.changed {
+			t.Errorf("%s enabled=%v: expected changed=%v, got %v", step.id, step.enabled, step.changed, changed)
+		}
+		ids, err := v.EnabledPlugins()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(ids, step.want) {
+			t.Errorf("%s enabled=%v: expected %v, got %v", step.id, step.enabled, step.want, ids)
+		}
+	}
+
+	data, err := os.ReadFile(v.CommunityPluginsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "[\n  \"calendar\",\n  \"tasknotes\"\n]" {
+		t.Errorf("expected Obsidian's formatting, got %q", data)
+	}
+}
+
+func TestEnabledPluginsRejectsInvalidFile(t *testing.T) {
+	v := New(t.TempDir())
+	if err := os.MkdirAll(v.ObsidianDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(v.CommunityPluginsPath(), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.SetPluginEnabled("calendar", true); err == nil {
+		t.Error("expected an error for an unparseable community-plugins.json")
+	}
+}
@@ -0,0 +1,332 @@
+// ABOUTME: Vault discovery and on-disk layout shared by all commands
+// ABOUTME: Exposes typed paths for plugins, lockfile, config, cache, and store directories
+package vault
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+	"github.com/gillisandrew/dragonglass-poc/internal/manifest"
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+)
+
+const (
+	ObsidianDirName     = config.ObsidianDirName
+	DragonglassDirName  = lockfile.DragonglassDirName
+	PluginsDirName      = "plugins"
+	ThemesDirName       = "themes"
+	SnippetsDirName     = "snippets"
+	CacheDirName        = "cache"
+	StoreDirName        = "store"
+	TmpDirName          = "tmp"
+	SyncIgnoreFileName  = "syncignore"
+	AttestationsDirName = "attestations"
+	DaemonSocketName    = "daemon.sock"
+	AuditLogName        = "audit.jsonl"
+
+	CommunityPluginsFileName = "community-plugins.json"
+
+	// DefaultStateDir is where dragonglass keeps a vault's state, relative to its root,
+	// unless vault.state_dir says otherwise
+	DefaultStateDir = DragonglassDirName
+)
+
+// Vault describes the on-disk layout of an Obsidian vault managed by dragonglass:
+//
+//	<root>/
+//	  dragonglass.json                    project manifest
+//	  .obsidian/
+//	    dragonglass-config.json           vault config
+//	    community-plugins.json            plugins Obsidian loads
+//	    plugins/<id>/                     installed plugins
+//	    themes/<name>/                    installed themes
+//	    snippets/<id>.css                 installed CSS snippets
+//	  .dragonglass/                       state directory (vault.state_dir)
+//	    dragonglass-lock.json             lockfile
+//	    cache/                            downloaded artifacts
+//	    store/                            verified plugin store
+//	    attestations/                     signed install attestations
+//	    tmp/<uuid>/                       per-operation workspaces
+//	    audit.jsonl                       security decision log
+//
+// The state directory can be moved elsewhere in the vault, e.g. to .obsidian/dragonglass
+// so that sync services upload it along with the vault.
+type Vault struct {
+	Root string
+
+	// Directory holding dragonglass state, relative to Root
+	StateDir string
+}
+
+// New returns the layout for the vault rooted at root without checking that it exists
+func New(root string) *Vault {
+	return &Vault{Root: root, StateDir: DefaultStateDir}
+}
+
+// WithStateDir returns the layout of the vault with its state kept in dir, relative to
+// the root. An empty dir keeps DefaultStateDir.
+func (v *Vault) WithStateDir(dir string) *Vault {
+	if dir == "" {
+		dir = DefaultStateDir
+	}
+	return &Vault{Root: v.Root, StateDir: filepath.Clean(filepath.FromSlash(dir))}
+}
+
+// Discover finds the vault containing startDir, searching parent directories
+func Discover(startDir string) (*Vault, error) {
+	root, err := FindRoot(startDir)
+	if err != nil {
+		return nil, err
+	}
+	return New(root), nil
+}
+
+// DiscoverFromWorkingDir finds the vault containing the current working directory
+func DiscoverFromWorkingDir() (*Vault, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	return Discover(wd)
+}
+
+// ObsidianDir returns the vault's .obsidian directory
+func (v *Vault) ObsidianDir() string {
+	return filepath.Join(v.Root, ObsidianDirName)
+}
+
+// PluginsDir returns the directory Obsidian loads community plugins from
+func (v *Vault) PluginsDir() string {
+	return filepath.Join(v.ObsidianDir(), PluginsDirName)
+}
+
+// CommunityPluginsPath returns the file listing the community plugins Obsidian loads
+func (v *Vault) CommunityPluginsPath() string {
+	return filepath.Join(v.ObsidianDir(), CommunityPluginsFileName)
+}
+
+// PluginDir returns the install directory of a single plugin
+func (v *Vault) PluginDir(pluginID string) string {
+	return filepath.Join(v.PluginsDir(), pluginID)
+}
+
+// ThemeDir returns the install directory of a theme, which Obsidian names after the theme
+func (v *Vault) ThemeDir(name string) string {
+	return filepath.Join(v.ObsidianDir(), ThemesDirName, name)
+}
+
+// SnippetPath returns the stylesheet a CSS snippet is installed as
+func (v *Vault) SnippetPath(id string) string {
+	return filepath.Join(v.ObsidianDir(), SnippetsDirName, id+".css")
+}
+
+// InstallPath returns where an artifact of kind is installed: the plugin directory named
+// by its ID, the theme directory named by its name, or the snippet stylesheet named by
+// its ID
+func (v *Vault) InstallPath(kind plugin.Kind, id, name string) string {
+	switch kind {
+	case plugin.KindTheme:
+		return v.ThemeDir(name)
+	case plugin.KindSnippet:
+		return v.SnippetPath(id)
+	default:
+		return v.PluginDir(id)
+	}
+}
+
+// DragonglassDir returns the directory holding dragonglass state for the vault
+func (v *Vault) DragonglassDir() string {
+	stateDir := v.StateDir
+	if stateDir == "" {
+		stateDir = DefaultStateDir
+	}
+	return filepath.Join(v.Root, stateDir)
+}
+
+// StatePattern returns the path of name in the state directory relative to the root and
+// with forward slashes, as ignore files such as .gitignore list it
+func (v *Vault) StatePattern(name string) string {
+	rel, err := filepath.Rel(v.Root, filepath.Join(v.DragonglassDir(), name))
+	if err != nil {
+		rel = filepath.Join(DefaultStateDir, name)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// LockfilePath returns the canonical lockfile path
+func (v *Vault) LockfilePath() string {
+	return filepath.Join(v.DragonglassDir(), lockfile.LockfileName)
+}
+
+// ConfigPath returns the vault config file path
+func (v *Vault) ConfigPath() string {
+	return config.GetConfigPath(v.ObsidianDir())
+}
+
+// ManifestPath returns the project manifest path
+func (v *Vault) ManifestPath() string {
+	return manifest.GetManifestPath(v.Root)
+}
+
+// CacheDir returns the directory for cached downloads
+func (v *Vault) CacheDir() string {
+	return filepath.Join(v.DragonglassDir(), CacheDirName)
+}
+
+// StoreDir returns the directory for the verified plugin store
+func (v *Vault) StoreDir() string {
+	return filepath.Join(v.DragonglassDir(), StoreDirName)
+}
+
+// TmpDir returns the directory holding per-operation temporary workspaces
+func (v *Vault) TmpDir() string {
+	return filepath.Join(v.DragonglassDir(), TmpDirName)
+}
+
+// SyncIgnorePath returns the file listing paths sync services should not upload
+func (v *Vault) SyncIgnorePath() string {
+	return filepath.Join(v.DragonglassDir(), SyncIgnoreFileName)
+}
+
+// AttestationsDir returns the directory for signed install attestations
+func (v *Vault) AttestationsDir() string {
+	return filepath.Join(v.DragonglassDir(), AttestationsDirName)
+}
+
+// DaemonSocketPath returns the unix socket the daemon listens on by default
+func (v *Vault) DaemonSocketPath() string {
+	return filepath.Join(v.DragonglassDir(), DaemonSocketName)
+}
+
+// AuditLogPath returns the append-only log of security decisions such as allowed downgrades
+func (v *Vault) AuditLogPath() string {
+	return filepath.Join(v.DragonglassDir(), AuditLogName)
+}
+
+// FindRoot returns the vault root (the directory containing .obsidian) for startDir or
+// the closest parent directory
+func FindRoot(startDir string) (string, error) {
+	currentDir, err := filepath.Abs(startDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	for {
+		obsidianPath := filepath.Join(currentDir, ObsidianDirName)
+		if info, err := os.Stat(obsidianPath); err == nil && info.IsDir() {
+			return currentDir, nil
+		}
+
+		parent := filepath.Dir(currentDir)
+		if parent == currentDir {
+			return "", fmt.Errorf(".obsidian directory not found in current path or parent directories")
+		}
+		currentDir = parent
+	}
+}
+
+// LockfilePath returns the canonical lockfile location for the vault at root with the
+// default state directory
+func LockfilePath(root string) string {
+	return New(root).LockfilePath()
+}
+
+// LegacyLockfilePath returns where older releases stored the lockfile
+func LegacyLockfilePath(root string) string {
+	return filepath.Join(root, ObsidianDirName, lockfile.LockfileName)
+}
+
+// LockfileLocation is the outcome of lockfile discovery
+type LockfileLocation struct {
+	Path string
+
+	// Legacy lockfile that was moved to Path during discovery, if any
+	MigratedFrom string
+
+	// Legacy lockfile left in place because a canonical lockfile already exists
+	IgnoredLegacy string
+}
+
+// ResolveLockfile determines which lockfile the vault at root uses, with the default
+// state directory
+func ResolveLockfile(root, override string) (*LockfileLocation, error) {
+	return New(root).ResolveLockfile(override)
+}
+
+// ResolveLockfile determines which lockfile the vault uses. Precedence:
+//
+//  1. override (the --lockfile flag), used as-is
+//  2. dragonglass-lock.json in the state directory
+//  3. <root>/.dragonglass/dragonglass-lock.json when the state directory was moved
+//     elsewhere, moved to the canonical location
+//  4. <root>/.obsidian/dragonglass-lock.json, moved to the canonical location
+//
+// A lockfile is moved together with its signature. When no lockfile exists the canonical
+// path is returned so a new lockfile is created there.
+func (v *Vault) ResolveLockfile(override string) (*LockfileLocation, error) {
+	if override != "" {
+		return &LockfileLocation{Path: override}, nil
+	}
+
+	canonical := v.LockfilePath()
+	canonicalExists, err := fileExists(canonical)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, previous := range []string{New(v.Root).LockfilePath(), LegacyLockfilePath(v.Root)} {
+		if previous == canonical {
+			continue
+		}
+		previousExists, err := fileExists(previous)
+		if err != nil {
+			return nil, err
+		}
+		if !previousExists {
+			continue
+		}
+		if canonicalExists {
+			return &LockfileLocation{Path: canonical, IgnoredLegacy: previous}, nil
+		}
+		if err := moveLockfile(previous, canonical); err != nil {
+			return nil, err
+		}
+		return &LockfileLocation{Path: canonical, MigratedFrom: previous}, nil
+	}
+
+	return &LockfileLocation{Path: canonical}, nil
+}
+
+// moveLockfile moves the lockfile at from, and its signature if any, to to
+func moveLockfile(from, to string) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", filepath.Dir(to), err)
+	}
+	if err := os.Rename(from, to); err != nil {
+		return fmt.Errorf("failed to move lockfile to %s: %w", to, err)
+	}
+	signature := lockfile.SignaturePath(from)
+	if _, err := os.Stat(signature); err == nil {
+		if err := os.Rename(signature, lockfile.SignaturePath(to)); err != nil {
+			return fmt.Errorf("failed to move lockfile signature to %s: %w", filepath.Dir(to), err)
+		}
+	}
+	return nil
+}
+
+func fileExists(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err == nil {
+		if info.IsDir() {
+			return false, fmt.Errorf("%s is a directory, expected a lockfile", path)
+		}
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check %s: %w", path, err)
+}
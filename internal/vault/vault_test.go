@@ -0,0 +1,247 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/plugin"
+)
+
+func newVault(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ObsidianDirName), 0755); err != nil {
+		t.Fatalf("failed to create .obsidian directory: %v", err)
+	}
+	return root
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	tests := []struct {
+		name        string
+		setupDirs   []string
+		startDir    string
+		expectError bool
+	}{
+		{
+			name:      "obsidian directory in start dir",
+			setupDirs: []string{".obsidian"},
+		},
+		{
+			name:      "obsidian directory in parent",
+			setupDirs: []string{".obsidian", "subdir"},
+			startDir:  "subdir",
+		},
+		{
+			name:      "obsidian directory two levels up",
+			setupDirs: []string{".obsidian", "level1/level2"},
+			startDir:  "level1/level2",
+		},
+		{
+			name:        "no obsidian directory found",
+			setupDirs:   []string{"somedir"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for _, dir := range tt.setupDirs {
+				if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+					t.Fatalf("failed to create dir %s: %v", dir, err)
+				}
+			}
+
+			v, err := Discover(filepath.Join(root, tt.startDir))
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if v.Root != root {
+				t.Errorf("expected root %s, got %s", root, v.Root)
+			}
+		})
+	}
+}
+
+func TestLayout(t *testing.T) {
+	v := New("/vault")
+
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{name: "obsidian dir", got: v.ObsidianDir(), expected: "/vault/.obsidian"},
+		{name: "plugin dir", got: v.PluginDir("tasknotes"), expected: "/vault/.obsidian/plugins/tasknotes"},
+		{name: "community plugins", got: v.CommunityPluginsPath(), expected: "/vault/.obsidian/community-plugins.json"},
+		{name: "theme dir", got: v.ThemeDir("Minimal"), expected: "/vault/.obsidian/themes/Minimal"},
+		{name: "snippet path", got: v.SnippetPath("wide-tables"), expected: "/vault/.obsidian/snippets/wide-tables.css"},
+		{name: "install path of plugin", got: v.InstallPath(plugin.KindPlugin, "tasknotes", "TaskNotes"), expected: "/vault/.obsidian/plugins/tasknotes"},
+		{name: "install path of theme", got: v.InstallPath(plugin.KindTheme, "minimal", "Minimal"), expected: "/vault/.obsidian/themes/Minimal"},
+		{name: "install path of snippet", got: v.InstallPath(plugin.KindSnippet, "wide-tables", "Wide tables"), expected: "/vault/.obsidian/snippets/wide-tables.css"},
+		{name: "config", got: v.ConfigPath(), expected: "/vault/.obsidian/dragonglass-config.json"},
+		{name: "manifest", got: v.ManifestPath(), expected: "/vault/dragonglass.json"},
+		{name: "lockfile", got: v.LockfilePath(), expected: "/vault/.dragonglass/dragonglass-lock.json"},
+		{name: "cache", got: v.CacheDir(), expected: "/vault/.dragonglass/cache"},
+		{name: "store", got: v.StoreDir(), expected: "/vault/.dragonglass/store"},
+		{name: "tmp", got: v.TmpDir(), expected: "/vault/.dragonglass/tmp"},
+		{name: "syncignore", got: v.SyncIgnorePath(), expected: "/vault/.dragonglass/syncignore"},
+		{name: "attestations", got: v.AttestationsDir(), expected: "/vault/.dragonglass/attestations"},
+		{name: "daemon socket", got: v.DaemonSocketPath(), expected: "/vault/.dragonglass/daemon.sock"},
+		{name: "audit log", got: v.AuditLogPath(), expected: "/vault/.dragonglass/audit.jsonl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != filepath.FromSlash(tt.expected) {
+				t.Errorf("expected %s, got %s", tt.expected, tt.got)
+			}
+		})
+	}
+}
+
+func TestResolveLockfile(t *testing.T) {
+	tests := []struct {
+		name           string
+		override       string
+		canonical      bool
+		legacy         bool
+		expectMigrated bool
+		expectIgnored  bool
+	}{
+		{name: "new vault uses canonical path"},
+		{name: "canonical lockfile", canonical: true},
+		{name: "legacy lockfile is moved", legacy: true, expectMigrated: true},
+		{name: "canonical wins over legacy", canonical: true, legacy: true, expectIgnored: true},
+		{name: "override is used as-is", override: "custom-lock.json", legacy: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := newVault(t)
+			canonical := LockfilePath(root)
+			legacy := LegacyLockfilePath(root)
+			if tt.canonical {
+				writeFile(t, canonical, "canonical")
+			}
+			if tt.legacy {
+				writeFile(t, legacy, "legacy")
+			}
+
+			override := ""
+			if tt.override != "" {
+				override = filepath.Join(root, tt.override)
+			}
+
+			location, err := ResolveLockfile(root, override)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expectedPath := canonical
+			if override != "" {
+				expectedPath = override
+			}
+			if location.Path != expectedPath {
+				t.Errorf("expected path %s, got %s", expectedPath, location.Path)
+			}
+			if (location.MigratedFrom != "") != tt.expectMigrated {
+				t.Errorf("expected migrated=%t, got %q", tt.expectMigrated, location.MigratedFrom)
+			}
+			if (location.IgnoredLegacy != "") != tt.expectIgnored {
+				t.Errorf("expected ignored legacy=%t, got %q", tt.expectIgnored, location.IgnoredLegacy)
+			}
+
+			if tt.expectMigrated {
+				data, err := os.ReadFile(canonical)
+				if err != nil || string(data) != "legacy" {
+					t.Errorf("expected legacy contents at canonical path, got %q, %v", data, err)
+				}
+				if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+					t.Error("expected legacy lockfile to be removed after migration")
+				}
+			}
+		})
+	}
+}
+
+func TestStateDir(t *testing.T) {
+	v := New("/vault").WithStateDir(".obsidian/dragonglass")
+
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{name: "state dir", got: v.DragonglassDir(), expected: "/vault/.obsidian/dragonglass"},
+		{name: "lockfile", got: v.LockfilePath(), expected: "/vault/.obsidian/dragonglass/dragonglass-lock.json"},
+		{name: "cache", got: v.CacheDir(), expected: "/vault/.obsidian/dragonglass/cache"},
+		{name: "tmp", got: v.TmpDir(), expected: "/vault/.obsidian/dragonglass/tmp"},
+		{name: "audit log", got: v.AuditLogPath(), expected: "/vault/.obsidian/dragonglass/audit.jsonl"},
+		{name: "config stays in .obsidian", got: v.ConfigPath(), expected: "/vault/.obsidian/dragonglass-config.json"},
+		{name: "empty keeps the default", got: New("/vault").WithStateDir("").LockfilePath(), expected: "/vault/.dragonglass/dragonglass-lock.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != filepath.FromSlash(tt.expected) {
+				t.Errorf("expected %s, got %s", tt.expected, tt.got)
+			}
+		})
+	}
+}
+
+func TestStatePattern(t *testing.T) {
+	if got := New("/vault").StatePattern(TmpDirName); got != ".dragonglass/tmp" {
+		t.Errorf("expected .dragonglass/tmp, got %s", got)
+	}
+	if got := New("/vault").WithStateDir(".obsidian/dragonglass").StatePattern(AuditLogName); got != ".obsidian/dragonglass/audit.jsonl" {
+		t.Errorf("expected .obsidian/dragonglass/audit.jsonl, got %s", got)
+	}
+}
+
+func TestResolveLockfileMovedStateDir(t *testing.T) {
+	root := newVault(t)
+	previous := LockfilePath(root)
+	writeFile(t, previous, "previous")
+	writeFile(t, previous+".sig", "signature")
+
+	v := New(root).WithStateDir(".obsidian/dragonglass")
+	location, err := v.ResolveLockfile("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if location.Path != v.LockfilePath() || location.MigratedFrom != previous {
+		t.Fatalf("expected lockfile moved from %s to %s, got %+v", previous, v.LockfilePath(), location)
+	}
+	if data, err := os.ReadFile(v.LockfilePath() + ".sig"); err != nil || string(data) != "signature" {
+		t.Errorf("expected the signature to move with the lockfile, got %q, %v", data, err)
+	}
+
+	writeFile(t, previous, "stale")
+	location, err = v.ResolveLockfile("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if location.Path != v.LockfilePath() || location.IgnoredLegacy != previous {
+		t.Errorf("expected the lockfile in the state dir to win, got %+v", location)
+	}
+}
@@ -0,0 +1,112 @@
+// ABOUTME: Categorized verification warnings and the --warnings-as-errors policy
+// ABOUTME: Turns accumulated warnings into a failure with its own exit code, minus ignored categories
+package warnings
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+)
+
+// Warning categories, as named by --ignore-warning
+const (
+	// Plugin ID or version format, and metadata errors tolerated outside strict mode
+	CategoryMetadata = "metadata"
+
+	// Missing or invalid attestations tolerated outside strict mode
+	CategoryAttestation = "attestation"
+
+	// Provenance was found but no SBOM attestation
+	CategorySBOM = "sbom"
+
+	// High or critical vulnerabilities tolerated outside strict mode
+	CategoryVulnerability = "vulnerability"
+)
+
+// Categories lists every warning category
+var Categories = []string{CategoryMetadata, CategoryAttestation, CategorySBOM, CategoryVulnerability}
+
+// Warning is a finding that did not fail verification
+type Warning struct {
+	Category string `json:"category"`
+	Message  string `json:"message"`
+}
+
+func (w Warning) String() string {
+	return w.Category + ": " + w.Message
+}
+
+// New returns a warning of category with a formatted message
+func New(category, format string, args ...any) Warning {
+	return Warning{Category: category, Message: fmt.Sprintf(format, args...)}
+}
+
+// Messages returns the messages of warnings
+func Messages(warnings []Warning) []string {
+	messages := make([]string, 0, len(warnings))
+	for _, w := range warnings {
+		messages = append(messages, w.Message)
+	}
+	return messages
+}
+
+// Policy decides whether accumulated warnings fail a command
+type Policy struct {
+	// Fail when any warning outside Ignore was found
+	AsErrors bool
+
+	// Categories that never fail the command
+	Ignore []string
+}
+
+// Check returns an error marked dgerrors.ErrWarnings when the policy turns warnings into
+// errors and any warning is not in an ignored category
+func (p Policy) Check(warnings []Warning) error {
+	if !p.AsErrors {
+		return nil
+	}
+
+	counted := p.Counted(warnings)
+	if len(counted) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(counted))
+	for _, w := range counted {
+		messages = append(messages, w.String())
+	}
+	return fmt.Errorf("%w: %d warning(s) with --warnings-as-errors: %s", dgerrors.ErrWarnings, len(counted), strings.Join(messages, "; "))
+}
+
+// Counted returns the warnings outside the ignored categories, which fail the command
+// with AsErrors and must be confirmed without it
+func (p Policy) Counted(warnings []Warning) []Warning {
+	var counted []Warning
+	for _, w := range warnings {
+		if !slices.Contains(p.Ignore, w.Category) {
+			counted = append(counted, w)
+		}
+	}
+	return counted
+}
+
+// AddFlags registers --warnings-as-errors and --ignore-warning on cmd
+func AddFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("warnings-as-errors", false, "Fail with exit code 7 when verification produced warnings")
+	cmd.Flags().StringSlice("ignore-warning", nil, "Warning category accepted without failing --warnings-as-errors or asking: "+strings.Join(Categories, ", ")+" (repeatable)")
+}
+
+// PolicyFromFlags reads the policy from the flags AddFlags registered
+func PolicyFromFlags(cmd *cobra.Command) (Policy, error) {
+	asErrors, _ := cmd.Flags().GetBool("warnings-as-errors")
+	ignore, _ := cmd.Flags().GetStringSlice("ignore-warning")
+	for _, category := range ignore {
+		if !slices.Contains(Categories, category) {
+			return Policy{}, fmt.Errorf("invalid --ignore-warning %q (must be one of %s)", category, strings.Join(Categories, ", "))
+		}
+	}
+	return Policy{AsErrors: asErrors, Ignore: ignore}, nil
+}
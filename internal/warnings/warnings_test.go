@@ -0,0 +1,77 @@
+package warnings
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	found := []Warning{
+		New(CategorySBOM, "no SBOM attestation found"),
+		New(CategoryMetadata, "Plugin ID format: %s", "invalid"),
+	}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		warnings []Warning
+		errorMsg string
+	}{
+		{name: "warnings allowed", policy: Policy{}, warnings: found},
+		{name: "no warnings", policy: Policy{AsErrors: true}},
+		{name: "warnings as errors", policy: Policy{AsErrors: true}, warnings: found, errorMsg: "2 warning(s) with --warnings-as-errors: sbom: no SBOM attestation found; metadata: Plugin ID format: invalid"},
+		{name: "category ignored", policy: Policy{AsErrors: true, Ignore: []string{CategorySBOM}}, warnings: found, errorMsg: "1 warning(s)"},
+		{name: "all ignored", policy: Policy{AsErrors: true, Ignore: []string{CategorySBOM, CategoryMetadata}}, warnings: found},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.warnings)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Fatalf("expected error containing %q, got %v", tt.errorMsg, err)
+			}
+			if !errors.Is(err, dgerrors.ErrWarnings) || dgerrors.ExitCode(err) != dgerrors.ExitWarnings {
+				t.Errorf("expected ErrWarnings with exit code %d, got %d", dgerrors.ExitWarnings, dgerrors.ExitCode(err))
+			}
+		})
+	}
+
+	if counted := (Policy{Ignore: []string{CategorySBOM}}).Counted(found); len(counted) != 1 || counted[0].Category != CategoryMetadata {
+		t.Errorf("expected only the metadata warning to be counted, got %v", counted)
+	}
+}
+
+func TestPolicyFromFlags(t *testing.T) {
+	cmd := &cobra.Command{}
+	AddFlags(cmd)
+	if err := cmd.ParseFlags([]string{"--warnings-as-errors", "--ignore-warning", "sbom,metadata"}); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := PolicyFromFlags(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !policy.AsErrors || strings.Join(policy.Ignore, ",") != "sbom,metadata" {
+		t.Errorf("unexpected policy %+v", policy)
+	}
+
+	cmd = &cobra.Command{}
+	AddFlags(cmd)
+	if err := cmd.ParseFlags([]string{"--ignore-warning", "tuf"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PolicyFromFlags(cmd); err == nil || !strings.Contains(err.Error(), "invalid --ignore-warning") {
+		t.Errorf("expected unknown category to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+// ABOUTME: GitHub release and workflow_run webhook payloads for publish automation
+// ABOUTME: Derives the published OCI reference and the plugins index entry for a release
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	EventRelease     = "release"
+	EventWorkflowRun = "workflow_run"
+
+	// IndexEntryVersion is the schemas/build.v1.json version written to index entries
+	IndexEntryVersion = "1"
+)
+
+// ErrIgnored reports a well-formed payload that does not announce a published build,
+// such as a draft release or a failed workflow run
+var ErrIgnored = errors.New("event does not publish a plugin release")
+
+var commitPattern = regexp.MustCompile(`^[a-f0-9]{40}$`)
+
+// Event is the part of a webhook payload needed to locate a published plugin
+type Event struct {
+	// EventRelease or EventWorkflowRun
+	Name string
+
+	// Repository that published the release, as owner/repo
+	Repository string
+
+	// Git tag of the release, e.g. "v1.2.3" or "1.2.3"
+	Tag string
+
+	// Commit the tag points to (empty when the payload does not say)
+	Commit string
+}
+
+type payload struct {
+	Action  string `json:"action"`
+	Release *struct {
+		TagName         string `json:"tag_name"`
+		TargetCommitish string `json:"target_commitish"`
+		Draft           bool   `json:"draft"`
+	} `json:"release"`
+	WorkflowRun *struct {
+		HeadBranch string `json:"head_branch"`
+		HeadSHA    string `json:"head_sha"`
+		Conclusion string `json:"conclusion"`
+	} `json:"workflow_run"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// ParsePayload reads a release or workflow_run webhook payload. Payloads that do not
+// announce a successful publish return an error wrapping ErrIgnored.
+func ParsePayload(data []byte) (*Event, error) {
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook payload: %w", err)
+	}
+
+	if p.Repository.FullName == "" {
+		return nil, fmt.Errorf("webhook payload has no repository.full_name")
+	}
+
+	event := &Event{Repository: p.Repository.FullName}
+	switch {
+	case p.Release != nil:
+		event.Name = EventRelease
+		if p.Action != "published" && p.Action != "released" {
+			return nil, fmt.Errorf("%w: release action %q", ErrIgnored, p.Action)
+		}
+		if p.Release.Draft {
+			return nil, fmt.Errorf("%w: release %s is a draft", ErrIgnored, p.Release.TagName)
+		}
+		event.Tag = p.Release.TagName
+		// target_commitish is usually a branch name; only a full SHA identifies the build
+		if commitPattern.MatchString(p.Release.TargetCommitish) {
+			event.Commit = p.Release.TargetCommitish
+		}
+	case p.WorkflowRun != nil:
+		event.Name = EventWorkflowRun
+		if p.Action != "completed" || p.WorkflowRun.Conclusion != "success" {
+			return nil, fmt.Errorf("%w: workflow run %s with conclusion %q", ErrIgnored, p.Action, p.WorkflowRun.Conclusion)
+		}
+		event.Tag = p.WorkflowRun.HeadBranch
+		event.Commit = p.WorkflowRun.HeadSHA
+	default:
+		return nil, fmt.Errorf("unsupported webhook payload (expected a release or workflow_run event)")
+	}
+
+	if event.Tag == "" {
+		return nil, fmt.Errorf("webhook payload has no tag")
+	}
+
+	return event, nil
+}
+
+// Version returns the plugin version named by the tag, without a leading "v"
+func (e *Event) Version() string {
+	return strings.TrimPrefix(e.Tag, "v")
+}
+
+// ImageReference returns where the build workflow publishes the release:
+// <registry>/<owner>/<repo>/<plugin-id>:v<version>. Registries require lowercase
+// repository names, so the GitHub repository is lowercased.
+func (e *Event) ImageReference(registryHost, pluginID string) string {
+	return fmt.Sprintf("%s/%s/%s:v%s", registryHost, strings.ToLower(e.Repository), pluginID, e.Version())
+}
+
+// IndexPath returns the build config path for the release in the plugins index
+func (e *Event) IndexPath() string {
+	return path.Join("plugins", e.Repository, "tags", e.Tag, "build.json")
+}
+
+// IndexEntry is a plugins index build config (schemas/build.v1.json)
+type IndexEntry struct {
+	Version         string `json:"version"`
+	Commit          string `json:"commit"`
+	PluginDirectory string `json:"pluginDirectory,omitempty"`
+	BuildDirectory  string `json:"buildDirectory,omitempty"`
+	OutputDirectory string `json:"outputDirectory,omitempty"`
+}
+
+// NewIndexEntry returns the index entry that pins commit
+func NewIndexEntry(commit string) (IndexEntry, error) {
+	if !commitPattern.MatchString(commit) {
+		return IndexEntry{}, fmt.Errorf("invalid commit %q (expected a 40 character hex SHA)", commit)
+	}
+	return IndexEntry{Version: IndexEntryVersion, Commit: commit}, nil
+}
+
+// PullRequest is the branch, title, and body for a plugins index pull request, in the
+// form the Generate Build Config workflow produces
+type PullRequest struct {
+	Branch string `json:"branch"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+}
+
+// Notification is everything automation needs to update the plugins index for a release
+type Notification struct {
+	Event        string      `json:"event"`
+	Repository   string      `json:"repository"`
+	Tag          string      `json:"tag"`
+	OCIReference string      `json:"oci_reference"`
+	Digest       string      `json:"digest"`
+	Path         string      `json:"path"`
+	Entry        IndexEntry  `json:"entry"`
+	PullRequest  PullRequest `json:"pull_request"`
+}
+
+// NewNotification describes the index update for a verified release
+func NewNotification(e *Event, entry IndexEntry, imageRef, digest string) *Notification {
+	indexPath := e.IndexPath()
+	entryJSON, _ := json.MarshalIndent(entry, "", "  ")
+
+	body := fmt.Sprintf(`## Generated Build Configuration
+
+This PR adds a new build configuration for:
+
+- **Repository**: `+"`%s`"+`
+- **Reference**: `+"`%s`"+` (tags)
+- **Commit Hash**: `+"`%s`"+`
+- **Verified artifact**: `+"`%s@%s`"+`
+
+### Configuration Details
+
+`+"```json\n%s\n```"+`
+
+### File Location
+
+`+"`%s`"+`
+
+---
+
+*This PR was automatically generated by dragonglass publish-notify.*
+`, e.Repository, e.Tag, entry.Commit, imageRef, digest, entryJSON, indexPath)
+
+	return &Notification{
+		Event:        e.Name,
+		Repository:   e.Repository,
+		Tag:          e.Tag,
+		OCIReference: imageRef,
+		Digest:       digest,
+		Path:         indexPath,
+		Entry:        entry,
+		PullRequest: PullRequest{
+			Branch: fmt.Sprintf("build-config/%s/%s", e.Repository, e.Tag),
+			Title:  fmt.Sprintf("Add build config for %s@%s (tags)", e.Repository, e.Tag),
+			Body:   body,
+		},
+	}
+}
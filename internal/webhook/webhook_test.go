@@ -0,0 +1,139 @@
+package webhook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testCommit = "38d5d3fa799e1eec8f10b137eee8b2cbdc73b534"
+
+func TestParsePayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		expected *Event
+		ignored  bool
+		errorMsg string
+	}{
+		{
+			name:     "published release",
+			payload:  `{"action": "published", "release": {"tag_name": "2.15.2", "target_commitish": "main"}, "repository": {"full_name": "SilentVoid13/Templater"}}`,
+			expected: &Event{Name: EventRelease, Repository: "SilentVoid13/Templater", Tag: "2.15.2"},
+		},
+		{
+			name:     "release targeting a commit",
+			payload:  `{"action": "released", "release": {"tag_name": "v1.0.0", "target_commitish": "` + testCommit + `"}, "repository": {"full_name": "owner/repo"}}`,
+			expected: &Event{Name: EventRelease, Repository: "owner/repo", Tag: "v1.0.0", Commit: testCommit},
+		},
+		{
+			name:     "successful workflow run",
+			payload:  `{"action": "completed", "workflow_run": {"head_branch": "0.5.70", "head_sha": "` + testCommit + `", "conclusion": "success"}, "repository": {"full_name": "blacksmithgu/obsidian-dataview"}}`,
+			expected: &Event{Name: EventWorkflowRun, Repository: "blacksmithgu/obsidian-dataview", Tag: "0.5.70", Commit: testCommit},
+		},
+		{
+			name:    "draft release",
+			payload: `{"action": "published", "release": {"tag_name": "1.0.0", "draft": true}, "repository": {"full_name": "owner/repo"}}`,
+			ignored: true,
+		},
+		{
+			name:    "edited release",
+			payload: `{"action": "edited", "release": {"tag_name": "1.0.0"}, "repository": {"full_name": "owner/repo"}}`,
+			ignored: true,
+		},
+		{
+			name:    "failed workflow run",
+			payload: `{"action": "completed", "workflow_run": {"head_branch": "1.0.0", "conclusion": "failure"}, "repository": {"full_name": "owner/repo"}}`,
+			ignored: true,
+		},
+		{
+			name:     "other event",
+			payload:  `{"action": "opened", "issue": {}, "repository": {"full_name": "owner/repo"}}`,
+			errorMsg: "unsupported webhook payload",
+		},
+		{
+			name:     "missing repository",
+			payload:  `{"action": "published", "release": {"tag_name": "1.0.0"}}`,
+			errorMsg: "repository.full_name",
+		},
+		{
+			name:     "invalid JSON",
+			payload:  `{`,
+			errorMsg: "failed to parse webhook payload",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := ParsePayload([]byte(tt.payload))
+
+			switch {
+			case tt.ignored:
+				if !errors.Is(err, ErrIgnored) {
+					t.Errorf("expected ErrIgnored, got %v", err)
+				}
+			case tt.errorMsg != "":
+				if err == nil || errors.Is(err, ErrIgnored) || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if *event != *tt.expected {
+					t.Errorf("expected %+v, got %+v", tt.expected, event)
+				}
+			}
+		})
+	}
+}
+
+func TestEventConventions(t *testing.T) {
+	event := &Event{Name: EventRelease, Repository: "SilentVoid13/Templater", Tag: "v2.15.2"}
+
+	if got := event.Version(); got != "2.15.2" {
+		t.Errorf("expected version 2.15.2, got %s", got)
+	}
+	if got := event.ImageReference("ghcr.io", "templater-obsidian"); got != "ghcr.io/silentvoid13/templater/templater-obsidian:v2.15.2" {
+		t.Errorf("unexpected image reference %s", got)
+	}
+	if got := event.IndexPath(); got != "plugins/SilentVoid13/Templater/tags/v2.15.2/build.json" {
+		t.Errorf("unexpected index path %s", got)
+	}
+}
+
+func TestNewIndexEntry(t *testing.T) {
+	entry, err := NewIndexEntry(testCommit)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.Version != IndexEntryVersion || entry.Commit != testCommit {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+
+	if _, err := NewIndexEntry("main"); err == nil {
+		t.Error("expected error for a branch name")
+	}
+}
+
+func TestNewNotification(t *testing.T) {
+	event := &Event{Name: EventWorkflowRun, Repository: "owner/repo", Tag: "1.0.0", Commit: testCommit}
+	entry, _ := NewIndexEntry(testCommit)
+
+	n := NewNotification(event, entry, "ghcr.io/owner/repo/plugin:v1.0.0", "sha256:abc")
+
+	if n.Path != "plugins/owner/repo/tags/1.0.0/build.json" {
+		t.Errorf("unexpected path %s", n.Path)
+	}
+	if n.PullRequest.Branch != "build-config/owner/repo/1.0.0" {
+		t.Errorf("unexpected branch %s", n.PullRequest.Branch)
+	}
+	if n.PullRequest.Title != "Add build config for owner/repo@1.0.0 (tags)" {
+		t.Errorf("unexpected title %s", n.PullRequest.Title)
+	}
+	for _, expected := range []string{`"commit": "` + testCommit + `"`, "ghcr.io/owner/repo/plugin:v1.0.0@sha256:abc", n.Path} {
+		if !strings.Contains(n.PullRequest.Body, expected) {
+			t.Errorf("expected body to contain %q, got:\n%s", expected, n.PullRequest.Body)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// ABOUTME: Per-operation temporary workspaces under the vault's .dragonglass/tmp directory
+// ABOUTME: Promotes finished work into place atomically and sweeps workspaces left by crashes
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StaleAfter is how long a workspace may go unmodified before Sweep treats it as left
+// behind by a crashed or killed process. No operation runs anywhere near this long.
+const StaleAfter = 24 * time.Hour
+
+// Workspace is a private directory for one operation, so concurrent operations never
+// write to the same files
+type Workspace struct {
+	Dir string
+}
+
+// New creates a workspace named by a random UUID under root
+func New(root string) (*Workspace, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+
+	dir := filepath.Join(root, uuid.NewString())
+	if err := os.Mkdir(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	return &Workspace{Dir: dir}, nil
+}
+
+// Path returns the path of name inside the workspace
+func (w *Workspace) Path(name string) string {
+	return filepath.Join(w.Dir, name)
+}
+
+// Promote moves name from the workspace to dest, replacing whatever is there. The
+// existing dest is first moved aside into the workspace and restored if the move fails,
+// so dest is never left missing or half written. The workspace and dest must be on the
+// same filesystem.
+func (w *Workspace) Promote(name, dest string) error {
+	previous := w.Path(".previous-" + name)
+	replacing := true
+	if err := os.Rename(dest, previous); errors.Is(err, os.ErrNotExist) {
+		replacing = false
+	} else if err != nil {
+		return fmt.Errorf("failed to move existing %s aside: %w", filepath.Base(dest), err)
+	}
+
+	if err := os.Rename(w.Path(name), dest); err != nil {
+		if replacing {
+			_ = os.Rename(previous, dest)
+		}
+		return fmt.Errorf("failed to move %s into place: %w", filepath.Base(dest), err)
+	}
+	return nil
+}
+
+// Remove deletes the workspace and anything left in it
+func (w *Workspace) Remove() error {
+	return os.RemoveAll(w.Dir)
+}
+
+// Sweep removes the workspaces under root last modified before now minus olderThan and
+// returns their paths. A missing root has nothing to sweep.
+func Sweep(root string, olderThan time.Duration, now time.Time) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read temporary directory: %w", err)
+	}
+
+	var removed []string
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue // Removed by another process since it was listed
+		}
+		if now.Sub(info.ModTime()) < olderThan {
+			continue
+		}
+
+		path := filepath.Join(root, entry.Name())
+		if err := os.RemoveAll(path); err != nil {
+			return removed, fmt.Errorf("failed to remove stale workspace %s: %w", entry.Name(), err)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
@@ -0,0 +1,150 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWorkspacesAreDistinct(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "tmp")
+
+	a, err := New(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := New(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Dir == b.Dir {
+		t.Fatalf("expected distinct workspaces, got %s twice", a.Dir)
+	}
+
+	if err := a.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(a.Dir); !os.IsNotExist(err) {
+		t.Errorf("expected workspace to be removed, got %v", err)
+	}
+	if _, err := os.Stat(b.Dir); err != nil {
+		t.Errorf("expected other workspace to be kept, got %v", err)
+	}
+}
+
+func TestPromote(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing bool
+	}{
+		{name: "fresh destination"},
+		{name: "replaces existing destination", existing: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			dest := filepath.Join(dir, "plugins", "sample")
+			if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if tt.existing {
+				if err := os.MkdirAll(dest, 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dest, "old.js"), []byte("old"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			w, err := New(filepath.Join(dir, "tmp"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.Remove()
+
+			if err := os.Mkdir(w.Path("plugin"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(w.Path("plugin"), "main.js"), []byte("new"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := w.Promote("plugin", dest); err != nil {
+				t.Fatal(err)
+			}
+			if data, err := os.ReadFile(filepath.Join(dest, "main.js")); err != nil || string(data) != "new" {
+				t.Errorf("expected promoted main.js, got %q (%v)", data, err)
+			}
+			if _, err := os.Stat(filepath.Join(dest, "old.js")); !os.IsNotExist(err) {
+				t.Errorf("expected previous contents to be replaced, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPromoteFailureKeepsDestination(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "sample")
+	if err := os.Mkdir(dest, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dest, "main.js"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := New(filepath.Join(dir, "tmp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Remove()
+
+	// Nothing was staged, so the move fails
+	if err := w.Promote("plugin", dest); err == nil {
+		t.Fatal("expected promoting a missing entry to fail")
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "main.js")); err != nil || string(data) != "old" {
+		t.Errorf("expected destination to be restored, got %q (%v)", data, err)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+
+	stale, err := New(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale.Path("layer.tar"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := now.Add(-2 * StaleAfter)
+	if err := os.Chtimes(stale.Dir, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	live, err := New(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := Sweep(root, StaleAfter, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0] != stale.Dir {
+		t.Errorf("expected only %s to be swept, got %v", stale.Dir, removed)
+	}
+	if _, err := os.Stat(stale.Dir); !os.IsNotExist(err) {
+		t.Errorf("expected stale workspace to be removed, got %v", err)
+	}
+	if _, err := os.Stat(live.Dir); err != nil {
+		t.Errorf("expected live workspace to be kept, got %v", err)
+	}
+
+	if removed, err := Sweep(filepath.Join(root, "missing"), StaleAfter, now); err != nil || len(removed) != 0 {
+		t.Errorf("expected missing root to sweep nothing, got %v (%v)", removed, err)
+	}
+}
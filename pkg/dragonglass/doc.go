@@ -0,0 +1,16 @@
+// ABOUTME: Public Go API for embedding Dragonglass verification and installation
+// ABOUTME: The only package outside internal/ that carries a compatibility promise
+
+// Package dragonglass lets other programs verify plugin artifacts, install them into a
+// vault, and read vault lockfiles with the same checks and policy as the dragonglass CLI.
+//
+// The package follows semantic versioning: exported identifiers are not removed or changed
+// incompatibly within a major version. Everything under internal/ is an implementation
+// detail and may change in any release; this package never exposes its types.
+//
+//	result, err := dragonglass.VerifyArtifact(ctx, "ghcr.io/owner/repo/plugin:1.0.0",
+//		dragonglass.DefaultOptions().WithTokenSource(dragonglass.StaticToken(token)))
+//
+// Configuration is resolved as the CLI resolves it: defaults, the user config file, the
+// vault config, and DRAGONGLASS_* environment variables, with Options taking precedence.
+package dragonglass
@@ -0,0 +1,24 @@
+// ABOUTME: Errors the public API returns, for matching with errors.Is
+// ABOUTME: Aliases of the internal sentinels so wrapped failures keep matching
+package dragonglass
+
+import (
+	dgerrors "github.com/gillisandrew/dragonglass-poc/internal/errors"
+)
+
+// Sentinel errors. Failures wrap these, so match them with errors.Is.
+var (
+	ErrNotAuthenticated    = dgerrors.ErrNotAuthenticated
+	ErrAttestationNotFound = dgerrors.ErrAttestationNotFound
+	ErrAttestationInvalid  = dgerrors.ErrAttestationInvalid
+	ErrUntrustedBuilder    = dgerrors.ErrUntrustedBuilder
+	ErrDigestMismatch      = dgerrors.ErrDigestMismatch
+	ErrVulnBlocked         = dgerrors.ErrVulnBlocked
+	ErrRiskBlocked         = dgerrors.ErrRiskBlocked
+	ErrRegistryUnavailable = dgerrors.ErrRegistryUnavailable
+
+	// Sigstore, TUF, or attestation endpoints kept failing; retry later
+	ErrTransientVerification = dgerrors.ErrTransientVerification
+	ErrHookRejected          = dgerrors.ErrHookRejected
+	ErrVaultFrozen           = dgerrors.ErrVaultFrozen
+)
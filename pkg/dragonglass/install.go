@@ -0,0 +1,46 @@
+// ABOUTME: Plugin installation for the public API
+// ABOUTME: Verifies, installs and pins a plugin with the install command's pipeline
+package dragonglass
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/install"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+)
+
+// InstallPlugin verifies imageRef, installs it into the vault's plugins directory, and
+// pins it in the lockfile. It returns the plugin as pinned. A nil opts uses
+// DefaultOptions.
+//
+// Installs into the same vault must not run concurrently; they update one lockfile.
+func InstallPlugin(ctx context.Context, imageRef string, opts *Options) (*LockedPlugin, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	cmdCtx, err := opts.commandContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := install.Add(ctx, cmdCtx, imageRef, opts.Force); err != nil {
+		return nil, err
+	}
+
+	lockfilePath, err := cmdCtx.ResolveLockfilePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate lockfile: %w", err)
+	}
+	data, err := lockfile.LoadLockfile(lockfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lockfile: %w", err)
+	}
+	for id, entry := range data.Plugins {
+		if entry.OCIReference == imageRef {
+			locked := newLockedPlugin(id, entry)
+			return &locked, nil
+		}
+	}
+	return nil, fmt.Errorf("installed %s but it is missing from the lockfile", imageRef)
+}
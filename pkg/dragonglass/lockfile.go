@@ -0,0 +1,110 @@
+// ABOUTME: Read-only view of vault lockfiles for the public API
+// ABOUTME: Converts internal lockfile entries into stable LockedPlugin values
+package dragonglass
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+)
+
+// Lockfile is the set of plugins pinned in a vault
+type Lockfile struct {
+	// Lockfile schema version
+	Version   string
+	UpdatedAt time.Time
+
+	// Pinned plugins sorted by ID
+	Plugins []LockedPlugin
+}
+
+// LockedPlugin is a plugin as it was pinned and verified at install
+type LockedPlugin struct {
+	ID           string
+	Name         string
+	Version      string
+	OCIReference string
+	OCIDigest    string
+
+	ProvenanceVerified bool
+	SBOMVerified       bool
+	VulnScanPassed     bool
+
+	// Workflow identity recorded in the SLSA provenance
+	BuilderID string
+
+	// When verification ran and the hash of the verification policy it ran under
+	VerifiedAt *time.Time
+	PolicyHash string
+
+	// When the plugin was last installed from the registry, and when it was first
+	// installed in the vault
+	InstalledAt      *time.Time
+	FirstInstalledAt *time.Time
+
+	// SHA-256 digests of the installed plugin files, keyed by file name
+	Files map[string]string
+
+	// An unverified build from source (dragonglass dev install); OCIReference holds the
+	// source it was built from
+	Dev bool
+}
+
+// Plugin returns the plugin pinned under id
+func (l *Lockfile) Plugin(id string) (LockedPlugin, bool) {
+	for _, p := range l.Plugins {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return LockedPlugin{}, false
+}
+
+// LoadLockfile reads the lockfile at path. A missing lockfile is returned as one with no
+// plugins; lockfiles written by older releases are migrated in memory.
+func LoadLockfile(path string) (*Lockfile, error) {
+	data, err := lockfile.LoadLockfile(path)
+	if err != nil {
+		return nil, err
+	}
+	return newLockfile(data), nil
+}
+
+func newLockfile(data *lockfile.Lockfile) *Lockfile {
+	l := &Lockfile{
+		Version:   data.Version,
+		UpdatedAt: data.UpdatedAt,
+		Plugins:   make([]LockedPlugin, 0, len(data.Plugins)),
+	}
+	for id, entry := range data.Plugins {
+		l.Plugins = append(l.Plugins, newLockedPlugin(id, entry))
+	}
+	sort.Slice(l.Plugins, func(i, j int) bool { return l.Plugins[i].ID < l.Plugins[j].ID })
+	return l
+}
+
+func newLockedPlugin(id string, entry lockfile.PluginEntry) LockedPlugin {
+	state := entry.VerificationState
+	files := make(map[string]string, len(entry.Files))
+	for name, digest := range entry.Files {
+		files[name] = digest
+	}
+	return LockedPlugin{
+		ID:                 id,
+		Name:               entry.Name,
+		Version:            entry.Version,
+		OCIReference:       entry.OCIReference,
+		OCIDigest:          entry.OCIDigest,
+		ProvenanceVerified: state.ProvenanceVerified,
+		SBOMVerified:       state.SBOMVerified,
+		VulnScanPassed:     state.VulnScanPassed,
+		BuilderID:          state.BuilderID,
+		VerifiedAt:         state.VerifiedAt,
+		PolicyHash:         state.PolicyHash,
+		InstalledAt:        entry.InstalledAt,
+		FirstInstalledAt:   entry.FirstInstalledAt,
+		Files:              files,
+		Dev:                entry.IsDev(),
+	}
+}
@@ -0,0 +1,71 @@
+package dragonglass
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+)
+
+func TestLoadLockfile(t *testing.T) {
+	vaultDir := t.TempDir()
+	path := filepath.Join(vaultDir, lockfile.DragonglassDirName, lockfile.LockfileName)
+
+	t.Run("missing", func(t *testing.T) {
+		l, err := LoadLockfile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(l.Plugins) != 0 {
+			t.Errorf("expected no plugins, got %+v", l.Plugins)
+		}
+	})
+
+	installedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	data := lockfile.NewLockfile(vaultDir)
+	for _, id := range []string{"templater-obsidian", "dataview"} {
+		err := data.AddPlugin(id, lockfile.PluginEntry{
+			Name:         id,
+			Version:      "1.0.0",
+			OCIReference: "ghcr.io/owner/repo/" + id + ":1.0.0",
+			OCIDigest:    "sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			VerificationState: lockfile.VerificationState{
+				ProvenanceVerified: true,
+				BuilderID:          "https://github.com/owner/repo/.github/workflows/build.yml@refs/heads/main",
+			},
+			InstalledAt: &installedAt,
+			Files:       map[string]string{"main.js": "sha256:60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lockfile.SaveLockfile(data, path); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("plugins", func(t *testing.T) {
+		l, err := LoadLockfile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(l.Plugins) != 2 || l.Plugins[0].ID != "dataview" || l.Plugins[1].ID != "templater-obsidian" {
+			t.Fatalf("expected plugins sorted by ID, got %+v", l.Plugins)
+		}
+
+		p, ok := l.Plugin("templater-obsidian")
+		if !ok {
+			t.Fatal("expected templater-obsidian to be pinned")
+		}
+		if !p.ProvenanceVerified || p.SBOMVerified || p.BuilderID == "" {
+			t.Errorf("unexpected verification state %+v", p)
+		}
+		if p.InstalledAt == nil || !p.InstalledAt.Equal(installedAt) || p.Files["main.js"] == "" {
+			t.Errorf("unexpected install details %+v", p)
+		}
+		if _, ok := l.Plugin("calendar"); ok {
+			t.Error("expected calendar not to be pinned")
+		}
+	})
+}
@@ -0,0 +1,192 @@
+// ABOUTME: Options and dependency interfaces shared by the public API
+// ABOUTME: Builds the internal command context operations run with
+package dragonglass
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pterm/pterm"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd"
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+	"github.com/gillisandrew/dragonglass-poc/internal/lockfile"
+)
+
+// TokenSource supplies the GitHub token used for registry and attestation requests. It is
+// asked once per operation, so implementations may refresh short-lived tokens.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a TokenSource that always returns the same token
+type StaticToken string
+
+// Token returns the token
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// Options configures an operation. The zero value is usable; DefaultOptions returns it.
+type Options struct {
+	// Vault the operation works in (default: the vault containing the working directory)
+	VaultDir string
+
+	// Vault config file (default: discovered from VaultDir)
+	ConfigPath string
+
+	// Lockfile to read and update (default: the vault's lockfile)
+	LockfilePath string
+
+	// Source of the GitHub token (default: GITHUB_TOKEN or stored credentials)
+	Tokens TokenSource
+
+	// Workflow identity provenance must be signed by (default: from config)
+	TrustedBuilder string
+
+	// Prefix of the plugin metadata annotations (default: from config)
+	AnnotationNamespace string
+
+	// Enforce the strict verification policy regardless of config
+	StrictMode bool
+
+	// Digest the artifact's manifest must have (VerifyArtifact only)
+	ExpectedDigest string
+
+	// Replace an existing plugin directory (InstallPlugin only)
+	Force bool
+
+	// Destination for progress logs (default: discarded)
+	LogWriter io.Writer
+
+	// Include debug logs in LogWriter
+	Verbose bool
+}
+
+// DefaultOptions returns options that use the configuration of the vault containing the
+// working directory
+func DefaultOptions() *Options {
+	return &Options{}
+}
+
+// WithVaultDir sets the vault the operation works in
+func (opts *Options) WithVaultDir(dir string) *Options {
+	opts.VaultDir = dir
+	return opts
+}
+
+// WithConfigPath sets the vault config file
+func (opts *Options) WithConfigPath(path string) *Options {
+	opts.ConfigPath = path
+	return opts
+}
+
+// WithLockfilePath sets the lockfile to read and update
+func (opts *Options) WithLockfilePath(path string) *Options {
+	opts.LockfilePath = path
+	return opts
+}
+
+// WithTokenSource sets the source of the GitHub token
+func (opts *Options) WithTokenSource(tokens TokenSource) *Options {
+	opts.Tokens = tokens
+	return opts
+}
+
+// WithTrustedBuilder sets the workflow identity provenance must be signed by
+func (opts *Options) WithTrustedBuilder(builder string) *Options {
+	opts.TrustedBuilder = builder
+	return opts
+}
+
+// WithAnnotationNamespace sets the prefix of the plugin metadata annotations
+func (opts *Options) WithAnnotationNamespace(namespace string) *Options {
+	opts.AnnotationNamespace = namespace
+	return opts
+}
+
+// WithStrictMode enforces the strict verification policy
+func (opts *Options) WithStrictMode(strict bool) *Options {
+	opts.StrictMode = strict
+	return opts
+}
+
+// WithExpectedDigest sets the digest the artifact's manifest must have
+func (opts *Options) WithExpectedDigest(digest string) *Options {
+	opts.ExpectedDigest = digest
+	return opts
+}
+
+// WithForce replaces an existing plugin directory on install
+func (opts *Options) WithForce(force bool) *Options {
+	opts.Force = force
+	return opts
+}
+
+// WithLogWriter sets the destination for progress logs
+func (opts *Options) WithLogWriter(w io.Writer, verbose bool) *Options {
+	opts.LogWriter = w
+	opts.Verbose = verbose
+	return opts
+}
+
+// commandContext resolves the configuration opts selects and returns the context the
+// internal commands run with
+func (opts *Options) commandContext(ctx context.Context) (*cmd.CommandContext, error) {
+	var token string
+	if opts.Tokens != nil {
+		var err error
+		if token, err = opts.Tokens.Token(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get authentication token: %w", err)
+		}
+	}
+
+	workingDir := opts.VaultDir
+	if workingDir == "" && opts.LockfilePath != "" {
+		workingDir = lockfile.VaultRoot(opts.LockfilePath)
+	}
+	resolverOpts := config.DefaultResolverOpts().
+		WithWorkingDir(workingDir).
+		WithVaultConfigPath(opts.ConfigPath).
+		WithGitHubToken(token)
+	if opts.TrustedBuilder != "" {
+		resolverOpts = resolverOpts.WithFlag("verification.trusted_builder", opts.TrustedBuilder)
+	}
+	if opts.AnnotationNamespace != "" {
+		resolverOpts = resolverOpts.WithFlag("registry.annotation_namespace", opts.AnnotationNamespace)
+	}
+	if opts.StrictMode {
+		resolverOpts = resolverOpts.WithFlag("verification.strict_mode", "true")
+	}
+	resolved, err := config.Resolve(resolverOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve configuration: %w", err)
+	}
+	cfg := resolved.Config
+
+	return &cmd.CommandContext{
+		AnnotationNamespace: cfg.Registry.AnnotationNamespace,
+		TrustedBuilder:      cfg.Verification.TrustedBuilder,
+		ConfigPath:          opts.ConfigPath,
+		LockfilePath:        opts.LockfilePath,
+		VaultDir:            opts.VaultDir,
+		GitHubToken:         resolved.GitHubToken,
+		Config:              cfg,
+		ResolvedConfig:      resolved,
+		Logger:              opts.logger(),
+	}, nil
+}
+
+// logger writes to LogWriter at the level Verbose selects
+func (opts *Options) logger() *pterm.Logger {
+	w := opts.LogWriter
+	if w == nil {
+		w = io.Discard
+	}
+	level := pterm.LogLevelInfo
+	if opts.Verbose {
+		level = pterm.LogLevelDebug
+	}
+	return pterm.DefaultLogger.WithTime(false).WithLevel(level).WithWriter(w)
+}
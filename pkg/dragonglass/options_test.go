@@ -0,0 +1,78 @@
+package dragonglass
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/config"
+)
+
+type failingTokens struct{}
+
+func (failingTokens) Token(context.Context) (string, error) {
+	return "", errors.New("token expired")
+}
+
+// newTestVault creates an empty vault and isolates the user config and environment
+func newTestVault(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+
+	vaultDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(vaultDir, ".obsidian"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return vaultDir
+}
+
+func TestCommandContext(t *testing.T) {
+	vaultDir := newTestVault(t)
+
+	opts := DefaultOptions().
+		WithVaultDir(vaultDir).
+		WithTokenSource(StaticToken("ghp_example")).
+		WithTrustedBuilder("https://github.com/owner/repo/.github/workflows/build.yml@refs/heads/main").
+		WithStrictMode(true)
+	cmdCtx, err := opts.commandContext(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cmdCtx.GitHubToken != "ghp_example" {
+		t.Errorf("expected token from the token source, got %q", cmdCtx.GitHubToken)
+	}
+	if cmdCtx.TrustedBuilder != opts.TrustedBuilder || cmdCtx.Config.Verification.TrustedBuilder != opts.TrustedBuilder {
+		t.Errorf("expected trusted builder %q, got %q", opts.TrustedBuilder, cmdCtx.TrustedBuilder)
+	}
+	if !cmdCtx.Config.Verification.StrictMode {
+		t.Error("expected strict mode")
+	}
+	if cmdCtx.AnnotationNamespace != config.DefaultAnnotationNamespace {
+		t.Errorf("expected default annotation namespace, got %q", cmdCtx.AnnotationNamespace)
+	}
+
+	lockfilePath, err := cmdCtx.ResolveLockfilePath()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(lockfilePath, vaultDir+string(filepath.Separator)) {
+		t.Errorf("expected lockfile in %s, got %s", vaultDir, lockfilePath)
+	}
+}
+
+func TestCommandContextTokenError(t *testing.T) {
+	vaultDir := newTestVault(t)
+
+	_, err := DefaultOptions().WithVaultDir(vaultDir).WithTokenSource(failingTokens{}).commandContext(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "token expired") {
+		t.Fatalf("expected token source error, got %v", err)
+	}
+}
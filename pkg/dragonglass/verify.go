@@ -0,0 +1,86 @@
+// ABOUTME: Artifact verification for the public API
+// ABOUTME: Runs the verify command's metadata, attestation and vulnerability checks
+package dragonglass
+
+import (
+	"context"
+
+	"github.com/gillisandrew/dragonglass-poc/internal/cmd/verify"
+	"github.com/gillisandrew/dragonglass-poc/internal/warnings"
+)
+
+// Verification describes what VerifyArtifact found. It is returned even when verification
+// fails so callers can report how far verification got.
+type Verification struct {
+	ImageRef       string
+	ManifestDigest string
+
+	// Plugin metadata from the manifest annotations (nil when it could not be read)
+	Plugin *Plugin
+
+	ProvenanceVerified bool
+	SBOMVerified       bool
+
+	// Workflow identity recorded in the SLSA provenance
+	BuilderID string
+
+	// Findings that did not fail verification under the configured policy
+	Warnings []string
+}
+
+// Plugin is the metadata a plugin artifact declares
+type Plugin struct {
+	ID            string
+	Name          string
+	Version       string
+	Author        string
+	AuthorURL     string
+	Description   string
+	MinAppVersion string
+	IsDesktopOnly bool
+}
+
+// VerifyArtifact checks the metadata, attestations, and vulnerabilities of imageRef against
+// the verification policy. A nil opts uses DefaultOptions.
+func VerifyArtifact(ctx context.Context, imageRef string, opts *Options) (*Verification, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	cmdCtx, err := opts.commandContext(ctx)
+	if err != nil {
+		return &Verification{ImageRef: imageRef}, err
+	}
+
+	result, err := verify.Verify(ctx, cmdCtx, imageRef, verify.DefaultVerifyOpts().WithExpectedDigest(opts.ExpectedDigest))
+	return newVerification(imageRef, result), err
+}
+
+func newVerification(imageRef string, result *verify.Result) *Verification {
+	v := &Verification{ImageRef: imageRef}
+	if result == nil {
+		return v
+	}
+
+	v.ManifestDigest = result.ManifestDigest
+	v.Warnings = warnings.Messages(result.Warnings)
+	if m := result.Metadata; m != nil {
+		v.Plugin = &Plugin{
+			ID:            m.ID,
+			Name:          m.Name,
+			Version:       m.Version,
+			Author:        m.Author,
+			AuthorURL:     m.AuthorURL,
+			Description:   m.Description,
+			MinAppVersion: m.MinAppVersion,
+			IsDesktopOnly: m.IsDesktopOnly,
+		}
+	}
+	if a := result.Attestation; a != nil {
+		if a.SLSA != nil {
+			v.ProvenanceVerified = a.SLSA.Valid
+			v.BuilderID = a.SLSA.Builder
+		}
+		v.SBOMVerified = a.SBOM != nil && a.SBOM.Valid
+	}
+	return v
+}
@@ -0,0 +1,59 @@
+#!/bin/bash
+
+# Install Path Performance Budget Check
+# Usage: ./bench-budget.sh [budget-file]
+# Runs the install path benchmarks and fails if any exceeds its baseline by more
+# than BENCH_TOLERANCE percent (default 50).
+
+set -euo pipefail
+
+BUDGET_FILE="${1:-$(dirname "$0")/bench-budget.txt}"
+TOLERANCE="${BENCH_TOLERANCE:-50}"
+PACKAGES="${BENCH_PACKAGES:-./internal/plugin ./internal/registry ./internal/lockfile ./internal/oci ./internal/attestation}"
+
+if [[ ! -f "$BUDGET_FILE" ]]; then
+    echo "❌ Error: budget file not found: $BUDGET_FILE"
+    exit 1
+fi
+
+echo "⏱  Running benchmarks (tolerance ${TOLERANCE}%)"
+
+# shellcheck disable=SC2086
+RESULTS=$(go test -run '^$' -bench . -benchmem -count "${BENCH_COUNT:-3}" $PACKAGES)
+echo "$RESULTS" | grep '^Benchmark' || true
+
+# Compare the fastest run of each benchmark against its baseline
+echo "$RESULTS" | awk -v tolerance="$TOLERANCE" -v budget_file="$BUDGET_FILE" '
+    BEGIN {
+        while ((getline line < budget_file) > 0) {
+            if (line ~ /^#/ || line ~ /^[[:space:]]*$/) continue
+            split(line, fields)
+            baseline[fields[1]] = fields[2]
+        }
+    }
+    /^Benchmark/ {
+        name = $1
+        sub(/-[0-9]+$/, "", name)
+        for (i = 2; i <= NF; i++) {
+            if ($(i + 1) == "ns/op" && (!(name in best) || $i + 0 < best[name])) best[name] = $i + 0
+        }
+    }
+    END {
+        failed = 0
+        for (name in baseline) {
+            if (!(name in best)) {
+                printf "❌ %s: no result (benchmark missing or failed)\n", name
+                failed = 1
+                continue
+            }
+            limit = baseline[name] * (1 + tolerance / 100)
+            if (best[name] > limit) {
+                printf "❌ %s: %.0f ns/op exceeds budget of %.0f ns/op\n", name, best[name], limit
+                failed = 1
+            } else {
+                printf "✅ %s: %.0f ns/op (budget %.0f ns/op)\n", name, best[name], limit
+            }
+        }
+        exit failed
+    }
+'